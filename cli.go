@@ -0,0 +1,1361 @@
+package main
+
+import (
+	stderrors "errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+
+	"linkedin-automation-framework/internal/audience"
+	"linkedin-automation-framework/internal/companies"
+	"linkedin-automation-framework/internal/config"
+	"linkedin-automation-framework/internal/content"
+	"linkedin-automation-framework/internal/daemon"
+	"linkedin-automation-framework/internal/deadletters"
+	"linkedin-automation-framework/internal/engagers"
+	"linkedin-automation-framework/internal/funnel"
+	"linkedin-automation-framework/internal/savedsearch"
+	"linkedin-automation-framework/internal/viewers"
+)
+
+// cliOptions holds the flags shared by every command
+type cliOptions struct {
+	configPath string
+	headless   bool
+	verbose    bool
+	answers    promptAnswers
+	recordDir  string // Save a page snapshot at every workflow checkpoint into this directory
+	replayDir  string // Run offline against the snapshots recorded in this directory
+	seed       int64  // Seed for stealth randomness, overriding stealth.seed; 0 keeps the configured one
+	traceFile  string // Trace every stealth action to this file, overriding stealth.trace_file
+	tenant     string // Tenant to serve, overriding tenant and TENANT
+	video      bool   // Record a video of every page the run opens, overriding video.enabled
+}
+
+// legacyOptions holds the flags of the -mode interface, kept so existing scripts keep working
+type legacyOptions struct {
+	mode       string
+	campaign   string
+	query      string
+	interval   time.Duration
+	maxResults int
+	name       string
+}
+
+// newRootCommand builds the command tree. Run without a subcommand, it runs the legacy -mode.
+func newRootCommand() *cobra.Command {
+	opts := &cliOptions{}
+	legacy := &legacyOptions{}
+
+	root := &cobra.Command{
+		Use:   "linkedin-automation-framework",
+		Short: "LinkedIn automation framework for educational and technical evaluation",
+		Long: "LinkedIn automation framework built on the Rod browser automation library.\n" +
+			"For educational and technical evaluation purposes only.\n\n" +
+			"Every command runs without prompts where it can, so it can be scheduled with cron.",
+		Version:       "1.0.0",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return startApplication(opts, OperationMode(legacy.mode), func(app *Application) {
+				app.campaignPath = legacy.campaign
+				app.watch = savedsearch.WatchOptions{MaxResults: legacy.maxResults, Interval: legacy.interval}
+				if legacy.query != "" {
+					app.watch.Query = savedsearch.SearchURL(legacy.query)
+				}
+				app.searchName = legacy.name
+				app.location = legacy.query
+			})
+		},
+	}
+	// Every command loads its configuration itself, so the tenant reaches them through TENANT
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if opts.tenant == "" {
+			return nil
+		}
+		return os.Setenv("TENANT", opts.tenant)
+	}
+	root.SetVersionTemplate("LinkedIn Automation Framework v{{.Version}}\n" +
+		"Built with Rod browser automation library\n" +
+		"For educational and technical evaluation purposes only\n")
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&opts.configPath, "config", "config.yaml", "Path to configuration file")
+	flags.BoolVar(&opts.headless, "headless", false, "Run browser in headless mode")
+	flags.BoolVar(&opts.verbose, "verbose", false, "Enable verbose logging")
+	flags.BoolVarP(&opts.answers.yes, "yes", "y", false, "Answer yes to every prompt and restore the saved session instead of waiting for a manual login")
+	flags.StringVar(&opts.recordDir, "record", "", "Save a page snapshot at every workflow checkpoint into this directory")
+	flags.StringVar(&opts.replayDir, "replay", "", "Run offline against the page snapshots recorded in this directory")
+	flags.Int64Var(&opts.seed, "seed", 0, "Seed delays, mouse paths and typing to repeat an earlier run (overrides stealth.seed)")
+	flags.StringVar(&opts.tenant, "tenant", "", "Serve this tenant from the configuration's tenants (overrides tenant and TENANT)")
+	flags.StringVar(&opts.traceFile, "trace", "", "Trace every stealth delay, mouse path, keystroke pause and scroll to this file (overrides stealth.trace_file)")
+	flags.BoolVar(&opts.video, "video", false, "Record a video of every page the run opens into video.dir (overrides video.enabled)")
+
+	// The -mode interface, hidden from help in favour of the subcommands
+	legacyFlags := root.Flags()
+	legacyFlags.StringVar(&legacy.mode, "mode", string(ModeDemo), "Operation mode")
+	legacyFlags.StringVar(&legacy.campaign, "campaign", "campaign.yaml", "Path to campaign definition file")
+	legacyFlags.StringVar(&legacy.query, "search", "", "People search URL or keywords, or a location name")
+	legacyFlags.DurationVar(&legacy.interval, "interval", 0, "Time between search runs")
+	legacyFlags.IntVar(&legacy.maxResults, "max-results", 100, "Profiles read per search run")
+	legacyFlags.StringVar(&legacy.name, "name", "", "Saved search to run")
+	addConnectFlags(legacyFlags, &opts.answers)
+	for _, name := range []string{"mode", "campaign", "search", "interval", "max-results", "name", "max-connections", "keywords"} {
+		legacyFlags.MarkHidden(name)
+	}
+
+	root.AddCommand(
+		newDemoCommand(opts),
+		newSearchCommand(opts),
+		newConnectCommand(opts),
+		newMessageCommand(opts),
+		newCampaignCommand(opts),
+		newReportCommand(opts),
+		newExportCommand(opts),
+		newConfigCommand(opts),
+		newServeCommand(opts),
+		newDaemonCommand(opts),
+		newPauseCommand(opts),
+		newSearchesCommand(opts),
+		newConnectionsCommand(opts),
+		newHealthCommand(opts),
+		newRunsCommand(opts),
+		newControlCommand(opts),
+		newTokensCommand(opts),
+		newDraftsCommand(opts),
+		newDeadLettersCommand(opts),
+		newLeadsCommand(opts),
+		newViewersCommand(opts),
+		newEngagersCommand(opts),
+		newContentCommand(opts),
+		newAudienceCommand(opts),
+		newSuppressionCommand(opts),
+		newPhotosCommand(opts),
+		newCompaniesCommand(opts),
+		newEmailsCommand(opts),
+	)
+	return root
+}
+
+// legacyArgs rewrites single-dash long flags such as -mode to --mode, which is how the flag
+// package accepted them before the subcommands
+func legacyArgs(args []string) []string {
+	rewritten := make([]string, len(args))
+	for i, arg := range args {
+		if len(arg) > 2 && arg[0] == '-' && arg[1] != '-' && (arg[1] < '0' || arg[1] > '9') {
+			arg = "-" + arg
+		}
+		rewritten[i] = arg
+	}
+	return rewritten
+}
+
+// newDemoCommand runs the demonstrations
+func newDemoCommand(opts *cliOptions) *cobra.Command {
+	flows := map[string]OperationMode{
+		"basic":        ModeDemo,
+		"full":         ModeFullDemo,
+		"interactive":  ModeInteractive,
+		"manual-login": ModeManualLogin,
+	}
+	return &cobra.Command{
+		Use:       "demo [basic|full|interactive|manual-login]",
+		Short:     "Demonstrate the framework's capabilities",
+		Long:      "Run a demonstration. basic (the default) never logs in; manual-login waits for a login done by hand.",
+		ValidArgs: []string{"basic", "full", "interactive", "manual-login"},
+		Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mode := ModeDemo
+			if len(args) == 1 {
+				mode = flows[args[0]]
+			}
+			return startApplication(opts, mode, nil)
+		},
+	}
+}
+
+// newSearchCommand watches a search, runs a saved search or resolves a location
+func newSearchCommand(opts *cliOptions) *cobra.Command {
+	var (
+		interval   time.Duration
+		maxResults int
+	)
+	cmd := &cobra.Command{
+		Use:   "search <search URL or keywords>",
+		Short: "Run a people search and save the profiles no earlier run returned",
+		Long: "Run a people search and save only the profiles no earlier run of it returned.\n" +
+			"Without --interval the search runs once.",
+		Example: "  linkedin-automation-framework search \"platform engineer\" --interval 6h",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return startApplication(opts, ModeWatchSearch, func(app *Application) {
+				app.watch = savedsearch.WatchOptions{
+					Query:      savedsearch.SearchURL(strings.Join(args, " ")),
+					MaxResults: maxResults,
+					Interval:   interval,
+				}
+			})
+		},
+	}
+	cmd.Flags().DurationVar(&interval, "interval", 0, "Time between search runs, 0 runs once")
+	cmd.Flags().IntVar(&maxResults, "max-results", 100, "Profiles read per search run")
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "saved <name>",
+			Short: "Run one saved search now",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return startApplication(opts, ModeRunSearch, func(app *Application) {
+					app.searchName = args[0]
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "location <location name>",
+			Short: "Look up a location's geo ID on LinkedIn and cache it for search filters",
+			Args:  cobra.MinimumNArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return startApplication(opts, ModeResolveLocation, func(app *Application) {
+					app.location = strings.Join(args, " ")
+				})
+			},
+		},
+	)
+	return cmd
+}
+
+// newConnectCommand sends connection requests to search results
+func newConnectCommand(opts *cliOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "connect",
+		Short: "Send connection requests to people search results",
+		Long: "Send connection requests to people search results.\n" +
+			"Without --max-connections and --keywords it asks for them; --yes takes the defaults.",
+		Example: "  linkedin-automation-framework connect --yes --max-connections 5 --keywords \"platform engineer\"",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return startApplication(opts, ModeConnectOnly, nil)
+		},
+	}
+	addConnectFlags(cmd.Flags(), &opts.answers)
+	return cmd
+}
+
+// addConnectFlags adds the flags answering connect-only mode's prompts
+func addConnectFlags(flags *pflag.FlagSet, answers *promptAnswers) {
+	flags.IntVar(&answers.maxConnections, "max-connections", 0, "Connection requests to send, 1-10 (default 3)")
+	flags.StringVar(&answers.keywords, "keywords", "", "Search keywords (default \"software engineer\")")
+}
+
+// newMessageCommand sends follow-up messages
+func newMessageCommand(opts *cliOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "message",
+		Short: "Send follow-up messages to new connections",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return startApplication(opts, ModeMessage, nil)
+		},
+	}
+}
+
+// newCampaignCommand runs, lints and simulates campaign files
+func newCampaignCommand(opts *cliOptions) *cobra.Command {
+	var campaignPath string
+	cmd := &cobra.Command{
+		Use:   "campaign",
+		Short: "Run, lint, simulate or plan a campaign file",
+	}
+	cmd.PersistentFlags().StringVar(&campaignPath, "file", "campaign.yaml", "Path to campaign definition file")
+	cmd.PersistentFlags().StringVar(&campaignPath, "campaign", "campaign.yaml", "Path to campaign definition file")
+	cmd.PersistentFlags().MarkHidden("campaign")
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "run",
+			Short: "Run every stored lead through the campaign's steps",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return startApplication(opts, ModeCampaign, func(app *Application) {
+					app.campaignPath = campaignPath
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "lint",
+			Short: "Check the campaign file for errors without a browser",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runCampaignCommand(opts.configPath, campaignPath, []string{"lint"})
+			},
+		},
+		&cobra.Command{
+			Use:   "simulate",
+			Short: "Walk the stored leads through the campaign without running any step",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runCampaignCommand(opts.configPath, campaignPath, []string{"simulate"})
+			},
+		},
+		newCampaignPlanCommand(opts, &campaignPath),
+		newCampaignVariablesCommand(),
+	)
+	return cmd
+}
+
+// newCampaignPlanCommand forecasts when a campaign finishes under its caps
+func newCampaignPlanCommand(opts *cliOptions, campaignPath *string) *cobra.Command {
+	var options campaignPlanOptions
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Forecast the day the campaign reaches its target, with the leads run each day",
+		Long: "Forecast the campaign's schedule under its limits.daily_cap, limits.weekly_cap and\n" +
+			"limits.leads_per_hour, one run per campaign day, skipping days blackouts cover entirely.\n" +
+			"Leads already run this week count against the first week's cap.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return planCampaign(opts.configPath, *campaignPath, options)
+		},
+	}
+	cmd.Flags().IntVar(&options.target, "target", 0, "Leads to forecast for (default the campaign's target, or else the stored leads)")
+	cmd.Flags().StringVar(&options.start, "start", "", "First day of the forecast, as 2006-01-02 (default today)")
+	cmd.Flags().StringVar(&options.days, "days", "mon-fri", "Weekdays the campaign runs, e.g. mon-fri or mon,wed,fri")
+	cmd.Flags().BoolVar(&options.asJSON, "json", false, "Print the forecast as JSON")
+	return cmd
+}
+
+// newCampaignVariablesCommand lists the variables templates can use
+func newCampaignVariablesCommand() *cobra.Command {
+	var asJSON bool
+	cmd := &cobra.Command{
+		Use:   "variables",
+		Short: "List the variables templates can use, such as {{first_name}}",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return printTemplateVariables(asJSON)
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the catalog as JSON, e.g. for editor autocompletion")
+	return cmd
+}
+
+// newReportCommand reports on past runs and the account's health
+func newReportCommand(opts *cliOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report [run-id]",
+		Short: "List past runs, or show everything one run did",
+		Long: "Without a run ID, list every recorded run with its totals and the account's health.\n" +
+			"With one, show the run's summary and every invite, message, result, event and skip it stored.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				return runRunsCommand(opts.configPath, []string{"show", args[0]})
+			}
+			if err := runRunsCommand(opts.configPath, []string{"list"}); err != nil {
+				return err
+			}
+			fmt.Println()
+			return runHealthCommand(opts.configPath, nil)
+		},
+	}
+	cmd.AddCommand(newCohortsCommand(opts), newTemplatesReportCommand(opts), newAudienceReportCommand(opts))
+	return cmd
+}
+
+// newCohortsCommand reports the acceptance and reply rates of weekly invite cohorts
+func newCohortsCommand(opts *cliOptions) *cobra.Command {
+	var options cohortReportOptions
+	cmd := &cobra.Command{
+		Use:   "cohorts",
+		Short: "Show how the invites of each week were accepted and replied to over the following weeks",
+		Long: "Group sent invites by the week they were sent and show the share accepted and replied to\n" +
+			"by the end of each following week. Acceptances are dated by the imported connections and\n" +
+			"replies by connections reply or connections pipedrive.",
+		Example: "  linkedin-automation-framework report cohorts --weeks 6\n" +
+			"  linkedin-automation-framework report cohorts --csv cohorts.csv --html cohorts.html",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return reportCohorts(opts.configPath, options)
+		},
+	}
+	cmd.Flags().IntVar(&options.weeks, "weeks", funnel.DefaultWeeks, "Weeks after sending each cohort is followed for")
+	cmd.Flags().StringVar(&options.csvPath, "csv", "", "Write the cohorts as CSV to this file, or - for stdout")
+	cmd.Flags().StringVar(&options.htmlPath, "html", "", "Write an HTML report with charts to this file")
+	return cmd
+}
+
+// newAudienceReportCommand reports the account's audience growth beside the outreach sent
+func newAudienceReportCommand(opts *cliOptions) *cobra.Command {
+	var options audienceReportOptions
+	cmd := &cobra.Command{
+		Use:   "audience",
+		Short: "Show the account's followers, impressions and subscribers by week beside the outreach sent",
+		Long: "Show the follower count, post impressions and newsletter subscribers recorded by audience\n" +
+			"track, by week, beside the invites and messages sent that week, and how the weekly follower\n" +
+			"gain correlates with the outreach. A week shows its last recording.",
+		Example: "  linkedin-automation-framework report audience --weeks 26\n" +
+			"  linkedin-automation-framework report audience --csv audience.csv --html audience.html",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return reportAudience(opts.configPath, options)
+		},
+	}
+	cmd.Flags().IntVar(&options.weeks, "weeks", funnel.DefaultGrowthWeeks, "Weeks the report covers, up to the current one")
+	cmd.Flags().StringVar(&options.csvPath, "csv", "", "Write the weeks as CSV to this file, or - for stdout")
+	cmd.Flags().StringVar(&options.htmlPath, "html", "", "Write an HTML report with charts to this file")
+	return cmd
+}
+
+// newTemplatesReportCommand flags templates whose acceptance or reply rate is decaying
+func newTemplatesReportCommand(opts *cliOptions) *cobra.Command {
+	var options templateReportOptions
+	cmd := &cobra.Command{
+		Use:   "templates",
+		Short: "Compare each template's recent acceptance and reply rates with its baseline",
+		Long: "Compare the acceptance and reply rates of each template's recent invites and messages with\n" +
+			"those of everything it sent before, flagging the templates whose rates dropped significantly,\n" +
+			"as they do when LinkedIn starts filtering repeated text. A send counts once it had the settle\n" +
+			"time to be accepted or replied to. Campaign runs log the same warnings for their own templates.",
+		Example: "  linkedin-automation-framework report templates\n" +
+			"  linkedin-automation-framework report templates --window 336h --drop 0.5 --json",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return reportTemplates(opts.configPath, options)
+		},
+	}
+	cmd.Flags().DurationVar(&options.decay.Window, "window", funnel.DefaultDecayWindow, "Length of the recent period compared with the baseline")
+	cmd.Flags().DurationVar(&options.decay.Settle, "settle", funnel.DefaultDecaySettle, "Time a send is given to be accepted or replied to")
+	cmd.Flags().Float64Var(&options.decay.Drop, "drop", funnel.DefaultDecayDrop, "Relative drop from the baseline that flags a template")
+	cmd.Flags().IntVar(&options.decay.MinSamples, "min-sends", funnel.DefaultDecayMin, "Sends both periods need before they are compared")
+	cmd.Flags().BoolVar(&options.asJSON, "json", false, "Print the comparisons as JSON")
+	return cmd
+}
+
+// newExportCommand downloads and imports LinkedIn's connections export
+func newExportCommand(opts *cliOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "export",
+		Short: "Download LinkedIn's connections export and import it into storage",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return startApplication(opts, ModeExportConnections, nil)
+		},
+	}
+}
+
+// newConfigCommand shows and validates the configuration
+func newConfigCommand(opts *cliOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Show or validate the configuration",
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "show",
+			Short: "Print the configuration after defaults and environment overrides, with secrets redacted",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				cfg, err := config.NewManager().LoadWithEnvOverrides(opts.configPath)
+				if err != nil {
+					return fmt.Errorf("failed to load configuration: %w", err)
+				}
+				data, err := yaml.Marshal(config.Redacted(cfg))
+				if err != nil {
+					return fmt.Errorf("failed to marshal configuration: %w", err)
+				}
+				fmt.Print(string(data))
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "validate",
+			Short: "Check the configuration and exit non-zero if it is invalid",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if _, err := config.NewManager().LoadWithEnvOverrides(opts.configPath); err != nil {
+					return fmt.Errorf("failed to load configuration: %w", err)
+				}
+				fmt.Printf("%s: OK\n", opts.configPath)
+				return nil
+			},
+		},
+	)
+	return cmd
+}
+
+// newServeCommand stays running, running saved searches on their schedules
+func newServeCommand(opts *cliOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Run saved searches whenever their interval comes round, until stopped",
+		Long: "Stay running and run each saved search with an interval whenever it is due.\n" +
+			"The control channel, if control.address is configured, serves pause and resume meanwhile.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return startApplication(opts, ModeSearchScheduler, nil)
+		},
+	}
+}
+
+// newDaemonCommand starts, inspects and stops the daemon
+func newDaemonCommand(opts *cliOptions) *cobra.Command {
+	var timeout time.Duration
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Run the search scheduler as a long-lived daemon, e.g. under systemd",
+		Long: "Run the search scheduler in the foreground as a daemon, with a PID file at daemon.pid_file\n" +
+			"and a unix socket at daemon.socket that status and stop talk to. The saved session is restored\n" +
+			"at start and saved at stop, so a restart does not need a new login.",
+	}
+	start := func() error {
+		return startApplication(opts, ModeDaemon, nil)
+	}
+	stop := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the running daemon and wait for it to exit",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDaemonCommand(opts.configPath, []string{"stop"}, timeout)
+		},
+	}
+	restart := &cobra.Command{
+		Use:   "restart",
+		Short: "Stop the running daemon, if any, and start a new one in the foreground",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := runDaemonCommand(opts.configPath, []string{"stop"}, timeout)
+			if err != nil && !stderrors.Is(err, daemon.ErrNotRunning) {
+				return err
+			}
+			return start()
+		},
+	}
+	for _, sub := range []*cobra.Command{stop, restart} {
+		sub.Flags().DurationVar(&timeout, "timeout", 2*time.Minute, "How long to wait for the daemon to exit")
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "start",
+			Short: "Start the daemon in the foreground",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return start()
+			},
+		},
+		&cobra.Command{
+			Use:   "status",
+			Short: "Show whether the daemon is running, since when and whether it is paused",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runDaemonCommand(opts.configPath, []string{"status"}, 10*time.Second)
+			},
+		},
+		stop,
+		restart,
+	)
+	return cmd
+}
+
+// newPauseCommand adds, lists and clears ad-hoc pauses
+func newPauseCommand(opts *cliOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pause <duration> [reason]",
+		Short: "Pause all actions for a while, e.g. \"pause 48h offsite\"",
+		Long: "Add an ad-hoc blackout from now for the duration, such as 48h or 3d. Runs refuse to start\n" +
+			"during it and schedulers, including a running daemon, queue due actions until it ends.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPauseCommand(opts.configPath, args)
+		},
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "List current and upcoming blackouts and pauses",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runPauseCommand(opts.configPath, []string{"list"})
+			},
+		},
+		&cobra.Command{
+			Use:   "clear",
+			Short: "Lift every ad-hoc pause; configured blackouts still apply",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runPauseCommand(opts.configPath, []string{"clear"})
+			},
+		},
+	)
+	return cmd
+}
+
+// newSearchesCommand manages saved searches; its arguments are parsed by runSearchesCommand,
+// since filters follow the positional arguments
+func newSearchesCommand(opts *cliOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "searches add|update|list|show|delete",
+		Short: "Manage saved searches in storage",
+		Example: "  linkedin-automation-framework searches add sre-berlin site reliability engineer -every 6h\n" +
+			"  linkedin-automation-framework searches list",
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configPath, args := takeConfigFlag(opts.configPath, args)
+			if len(args) > 0 && (args[0] == "--help" || args[0] == "-h") {
+				return cmd.Help()
+			}
+			return runSearchesCommand(configPath, args)
+		},
+	}
+}
+
+// takeConfigFlag removes --config from arguments the command parses itself, returning its value or fallback
+func takeConfigFlag(fallback string, args []string) (string, []string) {
+	configPath := fallback
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--config" && i+1 < len(args):
+			configPath = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--config="):
+			configPath = strings.TrimPrefix(args[i], "--config=")
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+	return configPath, rest
+}
+
+// newConnectionsCommand imports and reconciles connections without a browser
+func newConnectionsCommand(opts *cliOptions) *cobra.Command {
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "connections",
+		Short: "Import LinkedIn's connections export, reconcile tracked requests and tag connections",
+	}
+	reconcile := &cobra.Command{
+		Use:   "reconcile",
+		Short: "Match tracked connection requests against imported connections",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if dryRun {
+				return runConnectionsCommand(opts.configPath, []string{"reconcile", "-dry-run"})
+			}
+			return runConnectionsCommand(opts.configPath, []string{"reconcile"})
+		},
+	}
+	reconcile.Flags().BoolVar(&dryRun, "dry-run", false, "Report the drift without updating storage")
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "import <Connections.csv or export .zip>",
+			Short: "Import a connections export downloaded by hand",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runConnectionsCommand(opts.configPath, []string{"import", args[0]})
+			},
+		},
+		reconcile,
+		&cobra.Command{
+			Use:   "tag <profile URL> <tag>...",
+			Short: "Tag an imported connection, for network campaigns to select by",
+			Args:  cobra.MinimumNArgs(2),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runConnectionsCommand(opts.configPath, append([]string{"tag"}, args...))
+			},
+		},
+		newContactsCommand(opts),
+		newSalesforceCommand(opts),
+		newPipedriveCommand(opts),
+		newReplyCommand(opts),
+		&cobra.Command{
+			Use:   "untag <profile URL> <tag>...",
+			Short: "Remove tags from an imported connection",
+			Args:  cobra.MinimumNArgs(2),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runConnectionsCommand(opts.configPath, append([]string{"untag"}, args...))
+			},
+		},
+	)
+	return cmd
+}
+
+// newContactsCommand exports the accepted connections for a CRM import
+func newContactsCommand(opts *cliOptions) *cobra.Command {
+	var format string
+	var columns []string
+	cmd := &cobra.Command{
+		Use:   "contacts <output file>",
+		Short: "Export accepted connections as CSV or vCards for a CRM import",
+		Example: "  linkedin-automation-framework connections contacts contacts.vcf\n" +
+			"  linkedin-automation-framework connections contacts leads.csv --column \"Full Name=name\" --column \"Account=company\"",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			commandArgs := []string{"contacts", args[0]}
+			if format != "" {
+				commandArgs = append(commandArgs, "-format", format)
+			}
+			for _, column := range columns {
+				commandArgs = append(commandArgs, "-column", column)
+			}
+			return runConnectionsCommand(opts.configPath, commandArgs)
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "", "csv or vcard (default: vcard for .vcf files, otherwise csv)")
+	cmd.Flags().StringArrayVar(&columns, "column", nil, "CSV column as Header=field, repeated in column order")
+	return cmd
+}
+
+// newSalesforceCommand upserts the accepted connections into Salesforce
+func newSalesforceCommand(opts *cliOptions) *cobra.Command {
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "salesforce",
+		Short: "Create or update Salesforce Leads or Contacts for accepted connections",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			commandArgs := []string{"salesforce"}
+			if dryRun {
+				commandArgs = append(commandArgs, "-dry-run")
+			}
+			return runConnectionsCommand(opts.configPath, commandArgs)
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the fields each record would get without calling Salesforce")
+	return cmd
+}
+
+// newPipedriveCommand records a positive reply as a Pipedrive deal
+func newPipedriveCommand(opts *cliOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:     "pipedrive <profile URL> <reply excerpt>",
+		Short:   "Create a Pipedrive person and deal for a lead who replied positively",
+		Example: "  linkedin-automation-framework connections pipedrive https://www.linkedin.com/in/jane-doe \"Sounds great, let's talk\"",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConnectionsCommand(opts.configPath, []string{"pipedrive", args[0], args[1]})
+		},
+	}
+}
+
+// newReplyCommand records a lead's reply for the cohort reply rates
+func newReplyCommand(opts *cliOptions) *cobra.Command {
+	var at string
+	cmd := &cobra.Command{
+		Use:     "reply <profile URL> [excerpt]",
+		Short:   "Record that a lead replied, for the reply rates of report cohorts",
+		Example: "  linkedin-automation-framework connections reply https://www.linkedin.com/in/jane-doe \"Thanks, happy to chat\" --at 2024-07-03",
+		Args:    cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			commandArgs := append([]string{"reply"}, args...)
+			if at != "" {
+				commandArgs = append(commandArgs, "-at", at)
+			}
+			return runConnectionsCommand(opts.configPath, commandArgs)
+		},
+	}
+	cmd.Flags().StringVar(&at, "at", "", "Day the lead replied, as 2006-01-02 (default today)")
+	return cmd
+}
+
+// newHealthCommand scores the account, records events and lifts kill-switch halts
+func newHealthCommand(opts *cliOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "health",
+		Short: "Show the account's health score",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHealthCommand(opts.configPath, nil)
+		},
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:       "record <challenge|warning|logout|restricted> [detail]",
+			Short:     "Record an account event seen outside the tool",
+			ValidArgs: []string{"challenge", "warning", "logout", "restricted"},
+			Args:      cobra.MinimumNArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runHealthCommand(opts.configPath, append([]string{"record"}, args...))
+			},
+		},
+		&cobra.Command{
+			Use:   "enable [note]",
+			Short: "Lift a kill-switch halt",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runHealthCommand(opts.configPath, append([]string{"enable"}, args...))
+			},
+		},
+	)
+	return cmd
+}
+
+// newRunsCommand looks up past runs by run ID
+func newRunsCommand(opts *cliOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "runs",
+		Short: "List past runs or show one by run ID",
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "List every recorded run with its totals",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runRunsCommand(opts.configPath, []string{"list"})
+			},
+		},
+		&cobra.Command{
+			Use:   "show <run-id>",
+			Short: "Show everything a run stored",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runRunsCommand(opts.configPath, []string{"show", args[0]})
+			},
+		},
+		&cobra.Command{
+			Use:   "errors",
+			Short: "List the ways runs failed, totaled across runs, the most frequent first",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runRunsCommand(opts.configPath, []string{"errors"})
+			},
+		},
+	)
+	return cmd
+}
+
+// newControlCommand talks to a running instance's control channel
+func newControlCommand(opts *cliOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "control",
+		Short: "Pause, resume or check a running instance",
+	}
+	for _, action := range []struct{ use, short string }{
+		{"pause [reason]", "Pause the running instance at its next checkpoint"},
+		{"resume", "Resume a paused instance"},
+		{"status", "Show whether the instance is running or paused"},
+	} {
+		name := strings.Fields(action.use)[0]
+		sub := &cobra.Command{
+			Use:   action.use,
+			Short: action.short,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runControlCommand(opts.configPath, append([]string{name}, args...))
+			},
+		}
+		if name != "pause" {
+			sub.Args = cobra.NoArgs
+		}
+		cmd.AddCommand(sub)
+	}
+	return cmd
+}
+
+// newTokensCommand manages the API tokens the commands endpoint accepts
+func newTokensCommand(opts *cliOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tokens",
+		Short: "Issue, list or revoke API tokens for the commands endpoint",
+	}
+	var scope string
+	var rate int
+	issue := &cobra.Command{
+		Use:     "issue <name>",
+		Short:   "Issue a token and print its secret once",
+		Example: "  linkedin-automation-framework tokens issue dashboard --scope read --rate 30",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTokensCommand(opts.configPath, []string{"issue", args[0], scope, strconv.Itoa(rate)})
+		},
+	}
+	issue.Flags().StringVar(&scope, "scope", "read", "read for status only, review to also approve or reject drafts, control for everything")
+	issue.Flags().IntVar(&rate, "rate", 0, "Requests per minute the token may make; 0 uses control.token_rate_per_minute")
+	cmd.AddCommand(
+		issue,
+		&cobra.Command{
+			Use:   "list",
+			Short: "List configured and issued tokens",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runTokensCommand(opts.configPath, []string{"list"})
+			},
+		},
+		&cobra.Command{
+			Use:   "revoke <name>",
+			Short: "Revoke an issued token",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runTokensCommand(opts.configPath, []string{"revoke", args[0]})
+			},
+		},
+	)
+	return cmd
+}
+
+// newPhotosCommand manages downloaded profile photos
+func newPhotosCommand(opts *cliOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "photos",
+		Short: "Delete downloaded profile photos",
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "prune",
+			Short: "Delete photos past search.photos.retention and files no profile uses",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runPhotosCommand(opts.configPath, []string{"prune"})
+			},
+		},
+		&cobra.Command{
+			Use:   "purge",
+			Short: "Delete every downloaded photo, e.g. after turning photos off",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runPhotosCommand(opts.configPath, []string{"purge"})
+			},
+		},
+	)
+	return cmd
+}
+
+// newCompaniesCommand looks up the companies leads work at and lists what was found
+func newCompaniesCommand(opts *cliOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "companies",
+		Short: "Look up the size, industry and headquarters of the companies leads work at",
+	}
+	var (
+		max    int
+		maxAge time.Duration
+		rescan bool
+	)
+	enrich := &cobra.Command{
+		Use:   "enrich",
+		Short: "Read the LinkedIn pages of stored leads' companies not looked up yet",
+		Long: "Search LinkedIn for each company stored search results name, those most leads work at first,\n" +
+			"and cache its size, industry, headquarters, website and follower count from its About page.\n" +
+			"Each lookup spends a search from rate_limit.searches_per_hour. With companies.website_scan enabled,\n" +
+			"each company's website is also scanned for the configured keywords.",
+		Example: "  linkedin-automation-framework companies enrich --max 20",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return startApplication(opts, ModeEnrichCompanies, func(app *Application) {
+				app.companyOptions = companies.Options{MaxAge: maxAge, Limit: max, Rescan: rescan}
+			})
+		},
+	}
+	enrich.Flags().IntVar(&max, "max", 0, "Companies to look up in this run, 0 for as many as the search quota allows")
+	enrich.Flags().DurationVar(&maxAge, "max-age", 90*24*time.Hour, "Look companies fetched longer ago up again, 0 never does")
+	enrich.Flags().BoolVar(&rescan, "rescan", false, "Scan every company website again, e.g. after changing companies.website_scan.keywords")
+
+	cmd.AddCommand(
+		enrich,
+		&cobra.Command{
+			Use:   "list",
+			Short: "List the companies looked up and how many stored leads work at each",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runCompaniesCommand(opts.configPath, []string{"list"})
+			},
+		},
+	)
+	return cmd
+}
+
+// newEmailsCommand guesses the work email addresses of leads for follow-up outside LinkedIn
+func newEmailsCommand(opts *cliOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "emails",
+		Short: "Guess the work email addresses of leads; off unless email_guess.enabled is set",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "guess <file.csv>",
+		Short: "Write likely addresses of stored leads, with confidence scores, to a CSV file",
+		Long: "Guess addresses from each lead's name and their company's website domain, as found by\n" +
+			"\"companies enrich\". Only domains with mail exchangers in DNS get guesses. Nothing is sent.\n" +
+			"Addresses from the connections export are exported as known and rank their domain's pattern first.",
+		Example: "  linkedin-automation-framework emails guess leads-emails.csv",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runEmailsCommand(opts.configPath, []string{"guess", args[0]})
+		},
+	})
+	return cmd
+}
+
+// newLeadsCommand attaches notes and custom attributes to leads
+func newLeadsCommand(opts *cliOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "leads",
+		Short: "Attach notes and custom attributes to leads, shown in drafts and contact exports, or snooze them",
+	}
+	var until string
+	snooze := &cobra.Command{
+		Use:   "snooze <profile-url> --until YYYY-MM-DD",
+		Short: "Keep campaigns and connect runs away from a lead until a date",
+		Long: "A snoozed lead is passed over by every automated step, including leads queued through the\n" +
+			"commands API, until the start of the --until date. Snoozing again replaces the date.",
+		Example: "  linkedin-automation-framework leads snooze https://www.linkedin.com/in/jane-doe --until 2024-08-01",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLeadsCommand(opts.configPath, []string{"snooze", args[0], until})
+		},
+	}
+	snooze.Flags().StringVar(&until, "until", "", "Date the lead is picked up again, as YYYY-MM-DD")
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "show [profile-url]",
+			Short: "Show the notes, attributes and snooze of one lead, or of every annotated or snoozed lead",
+			Args:  cobra.MaximumNArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runLeadsCommand(opts.configPath, append([]string{"show"}, args...))
+			},
+		},
+		&cobra.Command{
+			Use:     "note <profile-url> <text>",
+			Short:   "Attach a free-text note to a lead",
+			Example: "  linkedin-automation-framework leads note https://www.linkedin.com/in/jane-doe \"Met at GopherCon, ask about the migration\"",
+			Args:    cobra.ExactArgs(2),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runLeadsCommand(opts.configPath, []string{"note", args[0], args[1]})
+			},
+		},
+		&cobra.Command{
+			Use:   "set <profile-url> key=value...",
+			Short: "Set custom attributes on a lead; key= removes one",
+			Long: "Keys are lowercase letters, digits and '_', starting with a letter. Contact exports can map\n" +
+				"an attribute to a column with --column Header=attribute.<key>.",
+			Example: "  linkedin-automation-framework leads set https://www.linkedin.com/in/jane-doe deal_stage=demo budget=",
+			Args:    cobra.MinimumNArgs(2),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runLeadsCommand(opts.configPath, append([]string{"set"}, args...))
+			},
+		},
+		snooze,
+		&cobra.Command{
+			Use:   "unsnooze <profile-url>",
+			Short: "Lift a lead's snooze so campaigns pick it up again",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runLeadsCommand(opts.configPath, []string{"unsnooze", args[0]})
+			},
+		},
+	)
+	return cmd
+}
+
+// newViewersCommand turns people who viewed the profile into high-priority leads
+func newViewersCommand(opts *cliOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "viewers",
+		Short: "Queue recent profile viewers who match the lead filter for an invite",
+	}
+	var (
+		campaignPath string
+		within       time.Duration
+		interval     time.Duration
+	)
+	check := &cobra.Command{
+		Use:   "check",
+		Short: "Read who viewed the profile and queue the recent viewers the lead filter accepts",
+		Long: "Read the \"Who viewed your profile\" page and queue each viewer who visited within --within,\n" +
+			"passes the lead filter and is neither a connection nor suppressed. Queued viewers carry the\n" +
+			"viewed_profile signal, so campaigns take them first. With --campaign they are queued for that\n" +
+			"campaign, which runs right after a check that queued someone.",
+		Example: "  linkedin-automation-framework viewers check --campaign campaign.yaml --interval 2h",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return startApplication(opts, ModeProfileViewers, func(app *Application) {
+				app.campaignPath = campaignPath
+				app.viewerOptions = viewers.Options{Within: within}
+				app.viewerInterval = interval
+			})
+		},
+	}
+	check.Flags().StringVar(&campaignPath, "campaign", "", "Campaign file to queue viewers for and run; without one any campaign's next run takes them")
+	check.Flags().DurationVar(&within, "within", viewers.DefaultWithin, "How recent a visit must be to be reciprocated")
+	check.Flags().DurationVar(&interval, "interval", 0, "Time between checks, 0 checks once")
+
+	cmd.AddCommand(
+		check,
+		&cobra.Command{
+			Use:   "list",
+			Short: "List the recorded profile visits and what became of each",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runViewersCommand(opts.configPath, []string{"list"})
+			},
+		},
+	)
+	return cmd
+}
+
+// newEngagersCommand turns the people who engaged with a post into warm leads
+func newEngagersCommand(opts *cliOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "engagers",
+		Short: "Feed the people who reacted to or commented on a post as warm leads",
+	}
+	var (
+		topic    string
+		queue    bool
+		maxLoads int
+	)
+	harvest := &cobra.Command{
+		Use:   "harvest <post-url>...",
+		Short: "Read who reacted to or commented on posts and save them as leads tagged with the post's topic",
+		Long: "Open each post, yours or anyone's, and save the people who reacted to or commented on it as\n" +
+			"leads, leaving out its author, connections and suppressed prospects. Each lead is tagged\n" +
+			"with the post_topic and post_url attributes and carries the reacted_to_post or\n" +
+			"commented_on_post signal, so campaigns take them first.\n" +
+			"The topic is the post's hashtags, or else its first words, unless --topic names it.",
+		Example: "  linkedin-automation-framework engagers harvest https://www.linkedin.com/posts/acme_hiring-activity-7100000000000000000-abcd/ --topic \"platform hiring\"",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, postURL := range args {
+				if err := engagers.CheckPostURL(postURL); err != nil {
+					return err
+				}
+			}
+			return startApplication(opts, ModeHarvestEngagers, func(app *Application) {
+				app.postURLs = args
+				app.engagerOptions = engagers.Options{Topic: topic, Queue: queue}
+				app.engagerLoads = maxLoads
+			})
+		},
+	}
+	harvest.Flags().StringVar(&topic, "topic", "", "Topic to tag the leads with instead of the post's hashtags or first words")
+	harvest.Flags().BoolVar(&queue, "queue", true, "Queue the leads for the next run of any campaign; --queue=false only saves them")
+	harvest.Flags().IntVar(&maxLoads, "max-loads", engagers.DefaultMaxLoads, "How many times to load more comments, and more reactions, per post")
+
+	cmd.AddCommand(harvest)
+	return cmd
+}
+
+// newContentCommand finds prospects by what they post
+func newContentCommand(opts *cliOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "content",
+		Short: "Find prospects who post about a hashtag or keyword",
+	}
+	var (
+		posted     string
+		recordText bool
+		queue      bool
+		maxLoads   int
+	)
+	searchCmd := &cobra.Command{
+		Use:   "search <hashtag-or-keywords>...",
+		Short: "Search recent posts and save their authors as leads tagged with the search",
+		Long: "Search LinkedIn's posts, most recent first, for each hashtag or keywords and save their\n" +
+			"authors as leads, leaving out companies, connections and suppressed prospects. Each lead is\n" +
+			"tagged with the search in post_topic and the post in post_url. With --record-text the post's\n" +
+			"text is recorded in post_text, shown next to the lead's drafts for a personal mention.",
+		Example: "  linkedin-automation-framework content search \"#golang\" \"platform engineering\" --posted past-week --record-text",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := content.CheckPosted(posted); err != nil {
+				return err
+			}
+			return startApplication(opts, ModeContentSearch, func(app *Application) {
+				app.contentQueries = args
+				app.contentPosted = posted
+				app.contentOptions = content.Options{RecordText: recordText, Queue: queue}
+				app.contentLoads = maxLoads
+			})
+		},
+	}
+	searchCmd.Flags().StringVar(&posted, "posted", "past-week", "How recent posts must be: past-24h, past-week or past-month; \"\" for any time")
+	searchCmd.Flags().BoolVar(&recordText, "record-text", false, "Record each post's text in the author's post_text attribute")
+	searchCmd.Flags().BoolVar(&queue, "queue", true, "Queue the leads for the next run of any campaign; --queue=false only saves them")
+	searchCmd.Flags().IntVar(&maxLoads, "max-loads", content.DefaultMaxLoads, "How many times to load more results per search")
+
+	cmd.AddCommand(searchCmd)
+	return cmd
+}
+
+// newAudienceCommand records the account's own audience
+func newAudienceCommand(opts *cliOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audience",
+		Short: "Record the account's followers, post impressions and newsletter subscribers",
+	}
+	var newsletterURL string
+	track := &cobra.Command{
+		Use:   "track",
+		Short: "Read the account's analytics pages and record its audience",
+		Long: "Read the follower count and the last 7 days' post impressions from the account's analytics\n" +
+			"pages and, with --newsletter, the newsletter's subscribers, and record them for report\n" +
+			"audience. Run it on a schedule, e.g. daily from cron, to follow the audience over time.",
+		Example: "  linkedin-automation-framework audience track --newsletter https://www.linkedin.com/newsletters/platform-notes-7000000000000000000/",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := audience.CheckNewsletterURL(newsletterURL); err != nil {
+				return err
+			}
+			return startApplication(opts, ModeTrackAudience, func(app *Application) {
+				app.newsletterURL = newsletterURL
+			})
+		},
+	}
+	track.Flags().StringVar(&newsletterURL, "newsletter", "", "Newsletter whose subscribers to record")
+
+	cmd.AddCommand(track)
+	return cmd
+}
+
+// newSuppressionCommand manages the prospects no account of the deployment may contact
+func newSuppressionCommand(opts *cliOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "suppression",
+		Short: "Manage the prospects no account of the deployment invites or messages, such as opt-outs",
+		Long: "The suppression list lives in suppression.file, a text file synced between machines, and\n" +
+			"in the storage at suppression.path every account shares. Campaigns and connect runs skip\n" +
+			"anyone on it, whichever account they run.",
+	}
+	var reason string
+	add := &cobra.Command{
+		Use:     "add <profile-url>",
+		Short:   "Suppress a prospect for every account; added to the shared storage, else to the file",
+		Example: "  linkedin-automation-framework suppression add https://www.linkedin.com/in/jane-doe --reason \"asked not to be contacted\"",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSuppressionCommand(opts.configPath, []string{"add", args[0], reason})
+		},
+	}
+	add.Flags().StringVar(&reason, "reason", "", "Why the prospect is suppressed")
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "List the suppressed prospects",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runSuppressionCommand(opts.configPath, []string{"list"})
+			},
+		},
+		add,
+		&cobra.Command{
+			Use:   "remove <profile-url>",
+			Short: "Take a prospect off the shared storage and the file",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runSuppressionCommand(opts.configPath, []string{"remove", args[0]})
+			},
+		},
+	)
+	return cmd
+}
+
+// newDeadLettersCommand reviews the campaign leads their queue gave up on
+func newDeadLettersCommand(opts *cliOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dead-letters",
+		Short: "Review the campaign leads that failed too often, then correct, requeue or discard them",
+		Long: "A campaign lead that fails queue.max_attempts times is dead-lettered: no run takes it again\n" +
+			"until it is requeued. Each dead letter keeps its last error, the error's fingerprint, and the\n" +
+			"screenshots the gallery took when it failed.",
+	}
+	var filter deadletters.Filter
+	selection := func(command *cobra.Command, bulk bool) {
+		command.Flags().StringVar(&filter.Campaign, "campaign", "", "Only the dead letters of this campaign")
+		command.Flags().StringVar(&filter.Fingerprint, "fingerprint", "", "Only the dead letters whose last error has this fingerprint")
+		if bulk {
+			command.Flags().BoolVar(&filter.All, "all", false, "Every dead letter, when no IDs, campaign or fingerprint are given")
+		}
+	}
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List the dead letters, oldest first, with their errors, fingerprints and screenshots",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDeadLettersCommand(opts.configPath, []string{"list"}, filter)
+		},
+	}
+	selection(list, false)
+	requeue := &cobra.Command{
+		Use:   "requeue [id...]",
+		Short: "Requeue dead letters for their campaign's next run, which takes them first",
+		Long: "Requeue the dead letters with the given IDs, or those --campaign and --fingerprint select.\n" +
+			"A requeued lead gets queue.max_attempts attempts again.",
+		Example: "  linkedin-automation-framework dead-letters requeue --fingerprint 3f2a9c1b7d4e",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDeadLettersCommand(opts.configPath, append([]string{"requeue"}, args...), filter)
+		},
+	}
+	selection(requeue, true)
+	discard := &cobra.Command{
+		Use:     "discard [id...]",
+		Short:   "Discard dead letters for good",
+		Example: "  linkedin-automation-framework dead-letters discard 12 13 14",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDeadLettersCommand(opts.configPath, append([]string{"discard"}, args...), filter)
+		},
+	}
+	selection(discard, true)
+	cmd.AddCommand(
+		list,
+		&cobra.Command{
+			Use:   "set <id> key=value...",
+			Short: "Correct the attributes a dead-lettered lead runs with once requeued; key= removes one",
+			Long: "Keys are lowercase letters, digits and '_', starting with a letter, and are what the campaign's\n" +
+				"templates read as {{.Attributes.<key>}}.",
+			Example: "  linkedin-automation-framework dead-letters set 12 first_name=Jane",
+			Args:    cobra.MinimumNArgs(2),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runDeadLettersCommand(opts.configPath, append([]string{"set"}, args...), filter)
+			},
+		},
+		requeue,
+		discard,
+	)
+	return cmd
+}
+
+// newDraftsCommand reviews the invites and messages campaigns hold for approval
+func newDraftsCommand(opts *cliOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "drafts",
+		Short: "Review the invites and messages campaigns with approval: true hold",
+	}
+	var text, reason string
+	approve := &cobra.Command{
+		Use:     "approve <id>",
+		Short:   "Approve a draft, optionally with edited text, for the campaign's next run to send",
+		Example: "  linkedin-automation-framework drafts approve 3f2a9c1b7d4e --text \"Hi Jane, loved your talk at GopherCon\"",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDraftsCommand(opts.configPath, []string{"approve", args[0], text})
+		},
+	}
+	approve.Flags().StringVar(&text, "text", "", "Send this instead of the drafted note or message")
+	reject := &cobra.Command{
+		Use:   "reject <id>",
+		Short: "Reject a draft so it is never sent",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDraftsCommand(opts.configPath, []string{"reject", args[0], reason})
+		},
+	}
+	reject.Flags().StringVar(&reason, "reason", "", "Why the draft is rejected")
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "List the drafts waiting for approval, oldest first",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runDraftsCommand(opts.configPath, []string{"list"})
+			},
+		},
+		approve,
+		reject,
+	)
+	return cmd
+}
@@ -0,0 +1,239 @@
+// Package annotations keeps the notes and custom attributes users attach to leads by hand, such
+// as the context a sales rep jots down before approving a message. Exports and the draft review
+// show them next to the lead. Users can also snooze a lead, keeping every automated step away
+// from it until a given date.
+package annotations
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"linkedin-automation-framework/internal/campaign"
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// Store keeps lead notes and attributes
+type Store interface {
+	SaveLeadNote(note storage.LeadNote) error
+	GetLeadNotes() ([]storage.LeadNote, error)
+	SaveLeadAttribute(attribute storage.LeadAttribute) error
+	GetLeadAttributes() ([]storage.LeadAttribute, error)
+	DeleteLeadAttribute(profileURL, key string) error
+}
+
+// SnoozeStore keeps snoozes with the other deferred leads, which campaigns pass over until they
+// are due
+type SnoozeStore interface {
+	SaveDeferredLeads(leads []storage.DeferredLead) error
+	GetDeferredLeads() ([]storage.DeferredLead, error)
+	DeleteDeferredLead(profileURL string) error
+}
+
+// SnoozeReason is the reason of the deferral a snooze is kept as, and the skip reason recorded
+// for a snoozed lead
+const SnoozeReason = "snoozed"
+
+// ErrInvalid is returned for a note or attribute that cannot be attached as given
+var ErrInvalid = errors.New("invalid lead annotation")
+
+// validKey keeps attribute keys usable as export column fields
+var validKey = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Annotation is everything users attached to one lead
+type Annotation struct {
+	ProfileURL string             // The lead's profile URL, normalized
+	Notes      []storage.LeadNote // Oldest first
+	Attributes map[string]string
+}
+
+// NoteTexts returns the texts of the notes, oldest first
+func (a Annotation) NoteTexts() []string {
+	texts := make([]string, len(a.Notes))
+	for i, note := range a.Notes {
+		texts[i] = note.Text
+	}
+	return texts
+}
+
+// Load returns the annotations of every annotated lead, by identity.ProfileKey
+func Load(store Store) (map[string]Annotation, error) {
+	notes, err := store.GetLeadNotes()
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := store.GetLeadAttributes()
+	if err != nil {
+		return nil, err
+	}
+	annotations := make(map[string]Annotation)
+	for _, note := range notes {
+		key := identity.ProfileKey(note.ProfileURL)
+		annotation := annotations[key]
+		annotation.ProfileURL = note.ProfileURL
+		annotation.Notes = append(annotation.Notes, note)
+		annotations[key] = annotation
+	}
+	for _, attribute := range attributes {
+		key := identity.ProfileKey(attribute.ProfileURL)
+		annotation := annotations[key]
+		annotation.ProfileURL = attribute.ProfileURL
+		if annotation.Attributes == nil {
+			annotation.Attributes = make(map[string]string)
+		}
+		annotation.Attributes[attribute.Key] = attribute.Value
+		annotations[key] = annotation
+	}
+	return annotations, nil
+}
+
+// For returns the annotation of one lead, empty when it has none
+func For(store Store, profileURL string) (Annotation, error) {
+	annotations, err := Load(store)
+	if err != nil {
+		return Annotation{}, err
+	}
+	return annotations[identity.ProfileKey(profileURL)], nil
+}
+
+// AddNote attaches a note by author to the lead at now
+func AddNote(store Store, profileURL, text, author string, now time.Time) error {
+	if err := checkProfileURL(profileURL); err != nil {
+		return err
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("%w: note text cannot be empty", ErrInvalid)
+	}
+	return store.SaveLeadNote(storage.LeadNote{ProfileURL: profileURL, Text: text, Author: author, CreatedAt: now})
+}
+
+// SetAttributes sets the lead's attributes by author at now. Keys are lowercase letters, digits
+// and '_', starting with a letter; an empty value removes the attribute.
+func SetAttributes(store Store, profileURL string, attributes map[string]string, author string, now time.Time) error {
+	if err := checkProfileURL(profileURL); err != nil {
+		return err
+	}
+	for key := range attributes {
+		if err := CheckKey(key); err != nil {
+			return err
+		}
+	}
+	for key, value := range attributes {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			if err := store.DeleteLeadAttribute(profileURL, key); err != nil {
+				return err
+			}
+			continue
+		}
+		attribute := storage.LeadAttribute{ProfileURL: profileURL, Key: key, Value: value, Author: author, UpdatedAt: now}
+		if err := store.SaveLeadAttribute(attribute); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddSignal adds an inbound signal, such as viewed_profile, to the lead's signal attribute, which
+// campaigns order leads by
+func AddSignal(store Store, profileURL, signal, author string, now time.Time) error {
+	annotation, err := For(store, profileURL)
+	if err != nil {
+		return err
+	}
+	var signals []string
+	for _, existing := range strings.Split(annotation.Attributes[campaign.AttributeSignal], ",") {
+		if existing = strings.TrimSpace(existing); existing != "" && existing != signal {
+			signals = append(signals, existing)
+		}
+	}
+	signals = append(signals, signal)
+	return SetAttributes(store, profileURL, map[string]string{campaign.AttributeSignal: strings.Join(signals, ",")}, author, now)
+}
+
+// CheckKey refuses an attribute key that is not lowercase letters, digits and '_', starting with
+// a letter
+func CheckKey(key string) error {
+	if !validKey.MatchString(key) {
+		return fmt.Errorf("%w: attribute key %q must be lowercase letters, digits and '_', starting with a letter", ErrInvalid, key)
+	}
+	return nil
+}
+
+// ParseAttributes parses attributes given as "key=value" entries; "key=" removes the attribute
+func ParseAttributes(specs []string) (map[string]string, error) {
+	attributes := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		key, value, ok := strings.Cut(spec, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: attribute %q must be written as key=value", ErrInvalid, spec)
+		}
+		attributes[strings.TrimSpace(key)] = value
+	}
+	return attributes, nil
+}
+
+// Snooze keeps automated steps away from the lead until until, replacing an earlier snooze or
+// deferral of it
+func Snooze(store SnoozeStore, profileURL string, until, now time.Time) error {
+	if err := checkProfileURL(profileURL); err != nil {
+		return err
+	}
+	if !until.After(now) {
+		return fmt.Errorf("%w: snooze must end in the future, not %s", ErrInvalid, until.Format(time.RFC3339))
+	}
+	return store.SaveDeferredLeads([]storage.DeferredLead{{
+		ProfileURL: profileURL,
+		Reason:     SnoozeReason,
+		DeferredAt: now,
+		RetryAfter: until,
+	}})
+}
+
+// Unsnooze lifts the lead's snooze, reporting whether it had one. A deferral for another reason,
+// such as the invitation limit, is left alone.
+func Unsnooze(store SnoozeStore, profileURL string) (bool, error) {
+	key := identity.ProfileKey(profileURL)
+	deferred, err := store.GetDeferredLeads()
+	if err != nil {
+		return false, err
+	}
+	lifted := false
+	for _, lead := range deferred {
+		if lead.Reason != SnoozeReason || identity.ProfileKey(lead.ProfileURL) != key {
+			continue
+		}
+		if err := store.DeleteDeferredLead(lead.ProfileURL); err != nil {
+			return lifted, err
+		}
+		lifted = true
+	}
+	return lifted, nil
+}
+
+// Snoozes returns the snoozes still running at now, by identity.ProfileKey
+func Snoozes(store SnoozeStore, now time.Time) (map[string]storage.DeferredLead, error) {
+	deferred, err := store.GetDeferredLeads()
+	if err != nil {
+		return nil, err
+	}
+	snoozes := make(map[string]storage.DeferredLead)
+	for _, lead := range deferred {
+		if lead.Reason == SnoozeReason && now.Before(lead.RetryAfter) {
+			snoozes[identity.ProfileKey(lead.ProfileURL)] = lead
+		}
+	}
+	return snoozes, nil
+}
+
+// checkProfileURL refuses what is not a LinkedIn profile URL
+func checkProfileURL(profileURL string) error {
+	if !strings.HasPrefix(identity.NormalizeProfileURL(strings.TrimSpace(profileURL)), identity.CanonicalProfilePrefix) {
+		return fmt.Errorf("%w: not a LinkedIn profile URL: %q", ErrInvalid, profileURL)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package annotations
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// TestAnnotations tests that notes and attributes attach to a lead under any variant of its URL,
+// that an empty value removes an attribute and that invalid input is refused
+func TestAnnotations(t *testing.T) {
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "json", Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	if err := AddNote(store, "https://www.linkedin.com/in/jane-doe", "Met at GopherCon", "cli:ann", now); err != nil {
+		t.Fatalf("failed to add note: %v", err)
+	}
+	if err := AddNote(store, "https://linkedin.com/in/Jane-Doe/", "  Prefers email  ", "api:crm", now.Add(time.Hour)); err != nil {
+		t.Fatalf("failed to add note: %v", err)
+	}
+	attributes, err := ParseAttributes([]string{"deal_stage=demo", "budget=10k"})
+	if err != nil {
+		t.Fatalf("failed to parse attributes: %v", err)
+	}
+	if err := SetAttributes(store, "https://www.linkedin.com/in/jane-doe", attributes, "cli:ann", now); err != nil {
+		t.Fatalf("failed to set attributes: %v", err)
+	}
+	if err := SetAttributes(store, "https://www.linkedin.com/in/jane-doe", map[string]string{"budget": ""}, "cli:ann", now); err != nil {
+		t.Fatalf("failed to remove attribute: %v", err)
+	}
+
+	jane, err := For(store, "https://www.linkedin.com/in/jane-doe?trk=x")
+	if err != nil {
+		t.Fatalf("failed to load annotation: %v", err)
+	}
+	if texts := jane.NoteTexts(); len(texts) != 2 || texts[0] != "Met at GopherCon" || texts[1] != "Prefers email" {
+		t.Errorf("expected both notes, oldest first and trimmed, got %q", texts)
+	}
+	if len(jane.Attributes) != 1 || jane.Attributes["deal_stage"] != "demo" {
+		t.Errorf("expected the deal stage left after removing the budget, got %v", jane.Attributes)
+	}
+	if john, _ := For(store, "https://www.linkedin.com/in/john"); len(john.Notes) != 0 || john.Attributes != nil {
+		t.Errorf("expected no annotation for john, got %+v", john)
+	}
+
+	if err := AddNote(store, "https://www.linkedin.com/in/jane-doe", "  ", "cli", now); err == nil {
+		t.Error("expected an empty note to be refused")
+	}
+	if err := AddNote(store, "https://example.com/jane", "Hi", "cli", now); err == nil {
+		t.Error("expected a note on something other than a profile URL to be refused")
+	}
+	if err := SetAttributes(store, "https://www.linkedin.com/in/jane-doe", map[string]string{"Deal Stage": "demo"}, "cli", now); !errors.Is(err, ErrInvalid) {
+		t.Error("expected an invalid key to be refused")
+	}
+	if _, err := ParseAttributes([]string{"deal_stage"}); err == nil {
+		t.Error("expected an attribute without = to be refused")
+	}
+}
+
+// TestSnooze tests that a snooze holds a lead under any variant of its URL until it ends, and
+// that lifting it leaves other deferrals alone
+func TestSnooze(t *testing.T) {
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "sqlite", Path: t.TempDir(), Database: "test.db"})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	if err := Snooze(store, "https://linkedin.com/in/Jane-Doe/", until, now); err != nil {
+		t.Fatalf("failed to snooze: %v", err)
+	}
+	limited := storage.DeferredLead{ProfileURL: "https://www.linkedin.com/in/john", Reason: "invite_limit", DeferredAt: now, RetryAfter: until}
+	if err := store.SaveDeferredLeads([]storage.DeferredLead{limited}); err != nil {
+		t.Fatalf("failed to defer: %v", err)
+	}
+
+	snoozes, err := Snoozes(store, now)
+	if err != nil {
+		t.Fatalf("failed to load snoozes: %v", err)
+	}
+	if len(snoozes) != 1 || !snoozes[identity.ProfileKey("https://www.linkedin.com/in/jane-doe?trk=x")].RetryAfter.Equal(until) {
+		t.Errorf("expected only jane snoozed until April, got %+v", snoozes)
+	}
+	if snoozes, _ := Snoozes(store, until); len(snoozes) != 0 {
+		t.Errorf("expected the snooze over once it ends, got %+v", snoozes)
+	}
+
+	if err := Snooze(store, "https://www.linkedin.com/in/jane-doe", now.Add(-time.Hour), now); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected a snooze ending in the past to be refused, got %v", err)
+	}
+	if lifted, err := Unsnooze(store, "https://www.linkedin.com/in/john"); err != nil || lifted {
+		t.Errorf("expected john's invitation limit deferral left alone, got %v (%v)", lifted, err)
+	}
+	if lifted, err := Unsnooze(store, "https://www.linkedin.com/in/JANE-DOE"); err != nil || !lifted {
+		t.Errorf("expected jane's snooze lifted, got %v (%v)", lifted, err)
+	}
+	if deferred, _ := store.GetDeferredLeads(); len(deferred) != 1 || deferred[0].Reason != "invite_limit" {
+		t.Errorf("expected only john's deferral left, got %+v", deferred)
+	}
+}
@@ -0,0 +1,231 @@
+// Package apitoken issues, revokes and checks the bearer tokens of the commands API. Tokens
+// are stored as hashes, carry a scope and are rate limited each on their own.
+package apitoken
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"linkedin-automation-framework/internal/storage"
+)
+
+// Scopes, from least to most privileged; a scope includes the ones before it
+const (
+	ScopeRead    = "read"    // Status and metrics only
+	ScopeReview  = "review"  // Also approving, editing and rejecting drafts held for approval
+	ScopeControl = "control" // Everything: queueing leads, pausing and resuming
+)
+
+// scopes lists the scopes in order of privilege
+var scopes = []string{ScopeRead, ScopeReview, ScopeControl}
+
+// DefaultRatePerMinute limits tokens that set no rate of their own when none is configured
+const DefaultRatePerMinute = 60
+
+// secretPrefix marks issued secrets so they are recognizable, e.g. by secret scanners
+const secretPrefix = "lat_"
+
+// validName restricts token names to something safe to print and log
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// Store keeps issued tokens
+type Store interface {
+	SaveAPIToken(token storage.APIToken) error
+	GetAPITokens() ([]storage.APIToken, error)
+}
+
+// Hash returns the stored form of a secret
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidScope reports whether scope is ScopeRead, ScopeReview or ScopeControl
+func ValidScope(scope string) bool {
+	return slices.Contains(scopes, scope)
+}
+
+// Includes reports whether a token with scope have may do what needs scope need
+func Includes(have, need string) bool {
+	return ValidScope(have) && slices.Index(scopes, have) >= slices.Index(scopes, need)
+}
+
+// Issue creates a token and returns its secret, which is shown this once and never stored. A
+// revoked token's name can be issued again; a valid one's cannot.
+func Issue(store Store, name, scope string, ratePerMinute int, now time.Time) (string, error) {
+	if !validName.MatchString(name) {
+		return "", fmt.Errorf("token name %q must be letters, digits, '.', '_' or '-'", name)
+	}
+	if !ValidScope(scope) {
+		return "", fmt.Errorf("token scope must be one of %s, got %q", strings.Join(scopes, ", "), scope)
+	}
+	if ratePerMinute < 0 {
+		return "", fmt.Errorf("token rate cannot be negative")
+	}
+	tokens, err := store.GetAPITokens()
+	if err != nil {
+		return "", err
+	}
+	for _, token := range tokens {
+		if token.Name == name && token.RevokedAt.IsZero() {
+			return "", fmt.Errorf("token %s already exists; revoke it first", name)
+		}
+	}
+
+	random := make([]byte, 24)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	secret := secretPrefix + hex.EncodeToString(random)
+	token := storage.APIToken{Name: name, Hash: Hash(secret), Scope: scope, RatePerMinute: ratePerMinute, CreatedAt: now}
+	if err := store.SaveAPIToken(token); err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// Revoke invalidates an issued token at once, keeping its record
+func Revoke(store Store, name string, now time.Time) error {
+	tokens, err := store.GetAPITokens()
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if token.Name == name && token.RevokedAt.IsZero() {
+			token.RevokedAt = now
+			return store.SaveAPIToken(token)
+		}
+	}
+	return fmt.Errorf("no valid token named %s", name)
+}
+
+// Authenticator checks bearer tokens: the fixed ones it was created with and those issued to
+// the store, which are read on every request so a revocation applies to the next one
+type Authenticator struct {
+	static      []storage.APIToken
+	store       Store
+	defaultRate int
+
+	mutex   sync.Mutex
+	windows map[string]*window // Keyed by token hash
+}
+
+// window counts a token's requests in the current minute
+type window struct {
+	start time.Time
+	count int
+}
+
+// NewAuthenticator creates an authenticator; a defaultRate of 0 uses DefaultRatePerMinute
+func NewAuthenticator(static []storage.APIToken, store Store, defaultRate int) *Authenticator {
+	if defaultRate <= 0 {
+		defaultRate = DefaultRatePerMinute
+	}
+	return &Authenticator{static: static, store: store, defaultRate: defaultRate, windows: make(map[string]*window)}
+}
+
+// nameKey carries the authenticated token's name in the request context
+type nameKey struct{}
+
+// Name returns the name of the token a request was authenticated with
+func Name(ctx context.Context) string {
+	name, _ := ctx.Value(nameKey{}).(string)
+	return name
+}
+
+// Require serves next only for requests with a valid token of at least the scope, answering
+// 401 without one, 403 when its scope is too narrow and 429 over its rate
+func (a *Authenticator) Require(scope string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || secret == "" {
+			unauthorized(w)
+			return
+		}
+		token, found, err := a.lookup(secret)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !found {
+			unauthorized(w)
+			return
+		}
+		if !Includes(token.Scope, scope) {
+			writeError(w, http.StatusForbidden, fmt.Sprintf("token %s has scope %s, this needs %s", token.Name, token.Scope, scope))
+			return
+		}
+		rate := token.RatePerMinute
+		if rate <= 0 {
+			rate = a.defaultRate
+		}
+		if wait := a.allow(token.Hash, rate, time.Now()); wait > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+			writeError(w, http.StatusTooManyRequests, fmt.Sprintf("token %s is limited to %d requests a minute", token.Name, rate))
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), nameKey{}, token.Name)))
+	})
+}
+
+// lookup finds the valid token whose hash matches the secret's
+func (a *Authenticator) lookup(secret string) (storage.APIToken, bool, error) {
+	tokens := a.static
+	if a.store != nil {
+		issued, err := a.store.GetAPITokens()
+		if err != nil {
+			return storage.APIToken{}, false, err
+		}
+		tokens = append(append([]storage.APIToken(nil), tokens...), issued...)
+	}
+	hash := []byte(Hash(secret))
+	for _, token := range tokens {
+		if token.RevokedAt.IsZero() && subtle.ConstantTimeCompare(hash, []byte(token.Hash)) == 1 {
+			return token, true, nil
+		}
+	}
+	return storage.APIToken{}, false, nil
+}
+
+// allow counts a request against the fixed one-minute window of the token with the given hash,
+// returning how long to wait when the window is full. Windows are keyed by hash, not name, so a
+// static and an issued token that share a name do not share a limit.
+func (a *Authenticator) allow(hash string, rate int, now time.Time) time.Duration {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	current, ok := a.windows[hash]
+	if !ok || now.Sub(current.start) >= time.Minute {
+		current = &window{start: now}
+		a.windows[hash] = current
+	}
+	if current.count >= rate {
+		return current.start.Add(time.Minute).Sub(now)
+	}
+	current.count++
+	return 0
+}
+
+// unauthorized answers 401 with the bearer challenge
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="commands"`)
+	writeError(w, http.StatusUnauthorized, "missing or unknown API token")
+}
+
+// writeError answers with {"error": message}
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
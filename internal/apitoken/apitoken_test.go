@@ -0,0 +1,144 @@
+package apitoken
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/storage"
+)
+
+// TestIssueRevokeAndScopes tests that issued secrets authenticate with their scope, that only
+// the hash is stored and that a revocation applies to the next request
+func TestIssueRevokeAndScopes(t *testing.T) {
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "sqlite", Path: t.TempDir(), Database: "test.db"})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	dashboard, err := Issue(store, "dashboard", ScopeRead, 0, now)
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+	script, err := Issue(store, "script", ScopeControl, 0, now)
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+	if _, err := Issue(store, "script", ScopeControl, 0, now); err == nil {
+		t.Error("expected a second valid token of the same name to be refused")
+	}
+	for _, bad := range [][2]string{{"has space", ScopeRead}, {"ok", "admin"}} {
+		if _, err := Issue(store, bad[0], bad[1], 0, now); err == nil {
+			t.Errorf("expected %v to be refused", bad)
+		}
+	}
+	tokens, _ := store.GetAPITokens()
+	if len(tokens) != 2 || tokens[0].Hash == dashboard || !strings.HasPrefix(dashboard, "lat_") || tokens[0].Hash != Hash(dashboard) {
+		t.Fatalf("expected two tokens stored by hash, got %+v", tokens)
+	}
+
+	auth := NewAuthenticator(nil, store, 0)
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(Name(r.Context()))) })
+	read, control := httptest.NewServer(auth.Require(ScopeRead, ok)), httptest.NewServer(auth.Require(ScopeControl, ok))
+	defer read.Close()
+	defer control.Close()
+
+	for _, check := range []struct {
+		server *httptest.Server
+		secret string
+		status int
+	}{
+		{read, dashboard, http.StatusOK},
+		{read, script, http.StatusOK},
+		{control, script, http.StatusOK},
+		{control, dashboard, http.StatusForbidden},
+		{control, "lat_unknown", http.StatusUnauthorized},
+		{read, "", http.StatusUnauthorized},
+	} {
+		if status := call(t, check.server, check.secret); status != check.status {
+			t.Errorf("secret %.8q: expected %d, got %d", check.secret, check.status, status)
+		}
+	}
+
+	if err := Revoke(store, "script", now.Add(time.Hour)); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if status := call(t, control, script); status != http.StatusUnauthorized {
+		t.Errorf("expected a revoked token to be refused, got %d", status)
+	}
+	if err := Revoke(store, "script", now); err == nil {
+		t.Error("expected revoking twice to fail")
+	}
+	if _, err := Issue(store, "script", ScopeRead, 0, now); err != nil {
+		t.Errorf("expected a revoked name to be issued again, got %v", err)
+	}
+}
+
+// TestIncludes tests that each scope includes the less privileged ones
+func TestIncludes(t *testing.T) {
+	for _, check := range []struct {
+		have, need string
+		want       bool
+	}{
+		{ScopeControl, ScopeReview, true},
+		{ScopeReview, ScopeRead, true},
+		{ScopeReview, ScopeControl, false},
+		{ScopeRead, ScopeReview, false},
+		{"admin", ScopeRead, false},
+	} {
+		if got := Includes(check.have, check.need); got != check.want {
+			t.Errorf("Includes(%q, %q) = %v, want %v", check.have, check.need, got, check.want)
+		}
+	}
+}
+
+// TestRateLimit tests that each token gets its own requests per minute
+func TestRateLimit(t *testing.T) {
+	auth := NewAuthenticator([]storage.APIToken{
+		{Name: "slow", Hash: Hash("slow-secret"), Scope: ScopeRead, RatePerMinute: 2},
+		{Name: "fast", Hash: Hash("fast-secret"), Scope: ScopeRead},
+		{Name: "shared", Hash: Hash("first-secret"), Scope: ScopeRead, RatePerMinute: 1},
+		{Name: "shared", Hash: Hash("second-secret"), Scope: ScopeRead, RatePerMinute: 1},
+	}, nil, 5)
+	server := httptest.NewServer(auth.Require(ScopeRead, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	defer server.Close()
+
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		if status := call(t, server, "slow-secret"); status != want {
+			t.Errorf("slow request %d: expected %d, got %d", i+1, want, status)
+		}
+	}
+	for i := 0; i < 5; i++ {
+		if status := call(t, server, "fast-secret"); status != http.StatusOK {
+			t.Errorf("fast request %d: expected the default rate to allow it, got %d", i+1, status)
+		}
+	}
+	for _, secret := range []string{"first-secret", "second-secret"} {
+		if status := call(t, server, secret); status != http.StatusOK {
+			t.Errorf("%s: expected tokens sharing a name to have their own windows, got %d", secret, status)
+		}
+	}
+
+	now := time.Now()
+	if wait := auth.allow(Hash("slow-secret"), 2, now.Add(time.Minute)); wait != 0 {
+		t.Errorf("expected a new window after a minute, got wait %s", wait)
+	}
+}
+
+func call(t *testing.T, server *httptest.Server, secret string) int {
+	t.Helper()
+	request, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	if secret != "" {
+		request.Header.Set("Authorization", "Bearer "+secret)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	response.Body.Close()
+	return response.StatusCode
+}
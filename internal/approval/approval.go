@@ -0,0 +1,187 @@
+// Package approval holds a campaign's invites and messages as drafts until a reviewer
+// approves, edits or rejects them. Nothing held is sent before it is approved.
+package approval
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// Kinds of send a draft holds
+const (
+	KindInvite      = "invite"
+	KindMessage     = "message"
+	KindOpenProfile = "open_profile"
+)
+
+// Draft statuses
+const (
+	StatusPending  = "pending"  // Waiting for a reviewer
+	StatusApproved = "approved" // Sent by the campaign's next run that reaches the lead
+	StatusRejected = "rejected" // Never sent
+	StatusSent     = "sent"
+)
+
+var (
+	// ErrPending is returned for a send waiting for a reviewer; the lead should be run again later
+	ErrPending = errors.New("awaiting approval")
+	// ErrRejected is returned for a send a reviewer rejected
+	ErrRejected = errors.New("rejected by a reviewer")
+	// ErrSent is returned for a send already made after its approval
+	ErrSent = errors.New("already sent after approval")
+	// ErrNotFound is returned for a draft ID that does not exist
+	ErrNotFound = errors.New("no such draft")
+)
+
+// Store keeps drafts
+type Store interface {
+	SaveDraft(draft storage.Draft) error
+	GetDrafts() ([]storage.Draft, error)
+}
+
+// ID returns the ID of the draft for a campaign step's send to a profile. Profile URLs are
+// compared by their identity key, so the same lead maps to the same draft.
+func ID(campaign, stepID, profileURL string) string {
+	sum := sha256.Sum256([]byte(campaign + "\x00" + stepID + "\x00" + identity.ProfileKey(profileURL)))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Gate holds one campaign's sends for review. A nil *Gate lets every send through unchanged.
+type Gate struct {
+	store    Store
+	campaign string
+}
+
+// NewGate creates a gate for the named campaign
+func NewGate(store Store, campaign string) *Gate {
+	return &Gate{store: store, campaign: campaign}
+}
+
+// Hold returns the text to send for a step's send to a lead: the approved text, which the
+// reviewer may have edited. The first time it records a pending draft and, like every time
+// until the draft is approved, returns ErrPending. Rejected and already sent drafts return
+// ErrRejected and ErrSent.
+func (g *Gate) Hold(stepID, kind, profileURL, leadName, subject, text string, now time.Time) (string, error) {
+	if g == nil {
+		return text, nil
+	}
+	id := ID(g.campaign, stepID, profileURL)
+	draft, found, err := find(g.store, id)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		draft = storage.Draft{
+			ID:         id,
+			Campaign:   g.campaign,
+			StepID:     stepID,
+			Kind:       kind,
+			ProfileURL: profileURL,
+			LeadName:   leadName,
+			Subject:    subject,
+			Text:       text,
+			Status:     StatusPending,
+			CreatedAt:  now,
+		}
+		if err := g.store.SaveDraft(draft); err != nil {
+			return "", err
+		}
+		return "", ErrPending
+	}
+
+	switch draft.Status {
+	case StatusApproved:
+		return draft.Text, nil
+	case StatusRejected:
+		return "", ErrRejected
+	case StatusSent:
+		return "", ErrSent
+	default:
+		return "", ErrPending
+	}
+}
+
+// Sent records that the approved send was made
+func (g *Gate) Sent(stepID, profileURL string, now time.Time) error {
+	if g == nil {
+		return nil
+	}
+	draft, found, err := find(g.store, ID(g.campaign, stepID, profileURL))
+	if err != nil || !found {
+		return err
+	}
+	draft.Status, draft.SentAt = StatusSent, now
+	return g.store.SaveDraft(draft)
+}
+
+// Pending returns the drafts waiting for a reviewer, oldest first
+func Pending(store Store) ([]storage.Draft, error) {
+	drafts, err := store.GetDrafts()
+	if err != nil {
+		return nil, err
+	}
+	var pending []storage.Draft
+	for _, draft := range drafts {
+		if draft.Status == StatusPending {
+			pending = append(pending, draft)
+		}
+	}
+	return pending, nil
+}
+
+// Approve approves a draft for sending, with text replacing its text unless it is "". A
+// draft can be approved again, e.g. to edit it, until it is sent.
+func Approve(store Store, id, reviewer, text string, now time.Time) (storage.Draft, error) {
+	return review(store, id, func(draft *storage.Draft) {
+		draft.Status, draft.Reviewer, draft.Reason, draft.ReviewedAt = StatusApproved, reviewer, "", now
+		if text != "" {
+			draft.Text = text
+		}
+	})
+}
+
+// Reject stops a draft from being sent
+func Reject(store Store, id, reviewer, reason string, now time.Time) (storage.Draft, error) {
+	return review(store, id, func(draft *storage.Draft) {
+		draft.Status, draft.Reviewer, draft.Reason, draft.ReviewedAt = StatusRejected, reviewer, reason, now
+	})
+}
+
+// review applies a reviewer's decision to a draft that has not been sent
+func review(store Store, id string, decide func(draft *storage.Draft)) (storage.Draft, error) {
+	draft, found, err := find(store, id)
+	if err != nil {
+		return storage.Draft{}, err
+	}
+	if !found {
+		return storage.Draft{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if draft.Status == StatusSent {
+		return storage.Draft{}, fmt.Errorf("draft %s: %w", id, ErrSent)
+	}
+	decide(&draft)
+	if err := store.SaveDraft(draft); err != nil {
+		return storage.Draft{}, err
+	}
+	return draft, nil
+}
+
+// find looks a draft up by ID
+func find(store Store, id string) (storage.Draft, bool, error) {
+	drafts, err := store.GetDrafts()
+	if err != nil {
+		return storage.Draft{}, false, err
+	}
+	for _, draft := range drafts {
+		if draft.ID == id {
+			return draft, true, nil
+		}
+	}
+	return storage.Draft{}, false, nil
+}
@@ -0,0 +1,73 @@
+package approval
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/storage"
+)
+
+// TestGateHoldsUntilApproved tests that a send is held as a pending draft, goes out with the
+// reviewer's edit once approved and is not sent twice
+func TestGateHoldsUntilApproved(t *testing.T) {
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "json", Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	gate := NewGate(store, "spring")
+	jane := "https://www.linkedin.com/in/jane-doe/"
+	for i := 0; i < 2; i++ {
+		if _, err := gate.Hold("invite", KindInvite, jane, "Jane Doe", "", "Hi Jane", now); !errors.Is(err, ErrPending) {
+			t.Fatalf("hold %d: expected the invite to wait for approval, got %v", i+1, err)
+		}
+	}
+	pending, err := Pending(store)
+	if err != nil || len(pending) != 1 || pending[0].Text != "Hi Jane" || pending[0].ID != ID("spring", "invite", "https://linkedin.com/in/Jane-Doe") {
+		t.Fatalf("expected one pending draft for Jane, got %+v (%v)", pending, err)
+	}
+
+	approved, err := Approve(store, pending[0].ID, "sam", "Hello Jane", now.Add(time.Hour))
+	if err != nil || approved.Status != StatusApproved || approved.Reviewer != "sam" {
+		t.Fatalf("expected the draft approved by sam, got %+v (%v)", approved, err)
+	}
+	text, err := gate.Hold("invite", KindInvite, jane, "Jane Doe", "", "Hi Jane", now)
+	if err != nil || text != "Hello Jane" {
+		t.Fatalf("expected the edited note to be sent, got %q (%v)", text, err)
+	}
+	if err := gate.Sent("invite", jane, now.Add(2*time.Hour)); err != nil {
+		t.Fatalf("Sent failed: %v", err)
+	}
+	if _, err := gate.Hold("invite", KindInvite, jane, "Jane Doe", "", "Hi Jane", now); !errors.Is(err, ErrSent) {
+		t.Errorf("expected a sent draft not to be sent again, got %v", err)
+	}
+	if _, err := Reject(store, pending[0].ID, "sam", "too late", now); err == nil {
+		t.Error("expected a sent draft to be final")
+	}
+
+	// Other steps and campaigns hold their own drafts
+	john := "https://www.linkedin.com/in/john-roe/"
+	gate.Hold("follow-up", KindMessage, john, "John Roe", "", "Hi John", now)
+	NewGate(store, "autumn").Hold("follow-up", KindMessage, john, "John Roe", "", "Hi John", now.Add(time.Minute))
+	pending, _ = Pending(store)
+	if len(pending) != 2 {
+		t.Fatalf("expected two pending drafts, got %+v", pending)
+	}
+	if _, err := Reject(store, pending[0].ID, "sam", "off-brand", now); err != nil {
+		t.Fatalf("Reject failed: %v", err)
+	}
+	if _, err := gate.Hold("follow-up", KindMessage, john, "John Roe", "", "Hi John", now); !errors.Is(err, ErrRejected) {
+		t.Errorf("expected a rejected message not to be sent, got %v", err)
+	}
+	if _, err := Approve(store, "missing", "sam", "", now); err == nil {
+		t.Error("expected approving an unknown draft to fail")
+	}
+
+	var none *Gate
+	if text, err := none.Hold("invite", KindInvite, jane, "", "", "Hi", now); err != nil || text != "Hi" || none.Sent("invite", jane, now) != nil {
+		t.Errorf("expected a nil gate to let the send through, got %q (%v)", text, err)
+	}
+}
@@ -0,0 +1,105 @@
+// Package audience reads the size of the account's own audience, its followers, post impressions
+// and newsletter subscribers, from LinkedIn's analytics pages, so its growth can be set against
+// the outreach that went out.
+package audience
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"linkedin-automation-framework/internal/browser"
+	"linkedin-automation-framework/internal/search"
+	"linkedin-automation-framework/internal/selectors"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// Analytics pages the counts are read from
+const (
+	FollowersURL   = "https://www.linkedin.com/analytics/creator/audience/"
+	ImpressionsURL = "https://www.linkedin.com/analytics/creator/content/?metricType=IMPRESSIONS&timeRange=past_7_days"
+)
+
+// Scraper reads the account's analytics pages
+type Scraper struct {
+	page      browser.PageDriver
+	selectors selectors.Set
+}
+
+// NewScraper creates a scraper for the account's analytics and newsletter pages, reading the
+// follower, impression and subscriber counts into a snapshot
+func NewScraper(page browser.PageDriver, set selectors.Set) *Scraper {
+	return &Scraper{page: page, selectors: set}
+}
+
+// Snapshot reads the follower count and the last 7 days' post impressions and, when newsletterURL
+// is set, the newsletter's subscribers. The followers must be shown; impressions are 0 for an
+// account that has not posted in the period.
+func (s *Scraper) Snapshot(ctx context.Context, newsletterURL string, now time.Time) (storage.AudienceSnapshot, error) {
+	page := s.page.Context(ctx)
+	snapshot := storage.AudienceSnapshot{TakenAt: now}
+
+	followers, ok, err := s.count(page, FollowersURL, s.selectors.AnalyticsFollowers)
+	if err != nil {
+		return snapshot, err
+	}
+	if !ok {
+		return snapshot, fmt.Errorf("no follower count on %s", FollowersURL)
+	}
+	snapshot.Followers = followers
+
+	if snapshot.Impressions, _, err = s.count(page, ImpressionsURL, s.selectors.AnalyticsImpressions); err != nil {
+		return snapshot, err
+	}
+
+	if newsletterURL != "" {
+		subscribers, ok, err := s.count(page, newsletterURL, s.selectors.NewsletterSubscribers)
+		if err != nil {
+			return snapshot, err
+		}
+		if !ok {
+			return snapshot, fmt.Errorf("no subscriber count on %s", newsletterURL)
+		}
+		snapshot.Subscribers = subscribers
+	}
+	return snapshot, nil
+}
+
+// count opens pageURL and reads the count in the first element matching a candidate selector,
+// reporting false when none shows one
+func (s *Scraper) count(page browser.PageDriver, pageURL string, candidates []string) (int, bool, error) {
+	if err := page.Navigate(pageURL); err != nil {
+		return 0, false, fmt.Errorf("failed to open %s: %w", pageURL, err)
+	}
+	if err := page.WaitLoad(); err != nil {
+		return 0, false, fmt.Errorf("failed to wait for %s to load: %w", pageURL, err)
+	}
+	for _, selector := range candidates {
+		found, element, err := page.Has(selector)
+		if err != nil || !found {
+			continue
+		}
+		text, err := element.Text()
+		if err != nil {
+			continue
+		}
+		if count, ok := search.ParseCount(text); ok {
+			return count, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// CheckNewsletterURL refuses what is not a link to a LinkedIn newsletter
+func CheckNewsletterURL(newsletterURL string) error {
+	if newsletterURL == "" {
+		return nil
+	}
+	parsed, err := url.Parse(strings.TrimSpace(newsletterURL))
+	if err != nil || !strings.HasSuffix(parsed.Hostname(), "linkedin.com") || !strings.HasPrefix(parsed.Path, "/newsletters/") {
+		return fmt.Errorf("not a LinkedIn newsletter URL: %q (expected https://www.linkedin.com/newsletters/...)", newsletterURL)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package audience
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/browser/browsertest"
+	"linkedin-automation-framework/internal/selectors"
+)
+
+const newsletterURL = "https://www.linkedin.com/newsletters/platform-notes-7000000000000000000/"
+
+// TestSnapshot tests that followers, impressions and subscribers are read from their pages
+func TestSnapshot(t *testing.T) {
+	set := selectors.Desktop
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	page := browsertest.NewPage("https://www.linkedin.com/feed/")
+	page.Route(FollowersURL, func(page *browsertest.Page) {
+		page.Append(browsertest.NewElement(set.AnalyticsFollowers[1]).SetText("4,807"))
+	})
+	page.Route(ImpressionsURL, func(page *browsertest.Page) {
+		page.Append(browsertest.NewElement(set.AnalyticsImpressions[0]).SetText("12.5K"))
+	})
+	page.Route(newsletterURL, func(page *browsertest.Page) {
+		page.Append(browsertest.NewElement(set.NewsletterSubscribers[0]).SetText("2,150 subscribers"))
+	})
+
+	snapshot, err := NewScraper(page, set).Snapshot(context.Background(), newsletterURL, now)
+	if err != nil {
+		t.Fatalf("failed to take snapshot: %v", err)
+	}
+	if !snapshot.TakenAt.Equal(now) || snapshot.Followers != 4807 || snapshot.Impressions != 12500 || snapshot.Subscribers != 2150 {
+		t.Errorf("unexpected snapshot %+v", snapshot)
+	}
+
+	// Without a follower count there is no snapshot
+	empty := browsertest.NewPage("https://www.linkedin.com/feed/")
+	empty.Route(FollowersURL, func(page *browsertest.Page) {})
+	if _, err := NewScraper(empty, set).Snapshot(context.Background(), "", now); err == nil {
+		t.Error("expected a page without a follower count to fail")
+	}
+}
+
+// TestCheckNewsletterURL tests that only links to newsletters are taken
+func TestCheckNewsletterURL(t *testing.T) {
+	for _, good := range []string{"", newsletterURL} {
+		if err := CheckNewsletterURL(good); err != nil {
+			t.Errorf("expected %q to be taken: %v", good, err)
+		}
+	}
+	if err := CheckNewsletterURL("https://www.linkedin.com/in/jane-doe/"); err == nil {
+		t.Error("expected a profile URL to be refused")
+	}
+}
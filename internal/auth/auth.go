@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/go-rod/rod"
-	"github.com/go-rod/rod/lib/proto"
 	
 	"linkedin-automation-framework/internal/errors"
 )
@@ -31,7 +30,7 @@ type Credentials struct {
 // StealthTyper interface for human-like typing
 type StealthTyper interface {
 	HumanType(ctx context.Context, element *rod.Element, text string) error
-	RandomDelay(min, max time.Duration) error
+	RandomDelay(ctx context.Context, min, max time.Duration) error
 }
 
 // AuthManager implements Authenticator interface
@@ -41,6 +40,10 @@ type AuthManager struct {
 	cookieManager CookieManager
 	errorHandler  *errors.RodErrorHandler
 	recovery      *errors.GracefulErrorRecovery
+	flow          LoginFlowConfig
+	hooks         LoginHooks
+	trusted       TrustedDeviceStore
+	trustedPath   string
 }
 
 // CookieManager interface for cookie persistence
@@ -56,6 +59,7 @@ func NewAuthManager(stealthTyper StealthTyper, cookieManager CookieManager) *Aut
 		cookieManager: cookieManager,
 		errorHandler:  errors.NewRodErrorHandler(30 * time.Second),
 		recovery:      errors.NewGracefulErrorRecovery(nil),
+		flow:          DefaultLoginFlowConfig(),
 	}
 }
 
@@ -79,92 +83,10 @@ func (am *AuthManager) LoadCredentials() error {
 	return nil
 }
 
-// Login performs LinkedIn login using Rod navigation and stealth typing
+// Login performs LinkedIn login with the login state machine; failures are returned as *LoginError
 func (am *AuthManager) Login(ctx context.Context, page *rod.Page) error {
 	return am.recovery.SafeExecute("login", func() error {
-		if page == nil {
-			return errors.NewError(errors.ErrorTypeConfiguration, "login", 
-				"page cannot be nil", nil)
-		}
-
-		retryConfig := errors.DefaultRetryConfig()
-		retryConfig.MaxAttempts = 2 // Login should not be retried too many times
-		retryConfig.InitialDelay = 5 * time.Second
-		
-		return errors.RetryWithBackoff(ctx, retryConfig, func(ctx context.Context, attempt int) error {
-			// Navigate to LinkedIn login page
-			err := am.errorHandler.SafeNavigation(ctx, page, "https://www.linkedin.com/login")
-			if err != nil {
-				return err
-			}
-
-			// Find and fill username field
-			err = am.errorHandler.SafeElementOperation(ctx, page, "#username", func(element *rod.Element) error {
-				if am.stealthTyper != nil {
-					return am.stealthTyper.HumanType(ctx, element, am.credentials.Username)
-				}
-				return element.Input(am.credentials.Username)
-			})
-			if err != nil {
-				return err
-			}
-
-			// Small delay between fields
-			if am.stealthTyper != nil {
-				am.stealthTyper.RandomDelay(500*time.Millisecond, 1500*time.Millisecond)
-			}
-
-			// Find and fill password field
-			err = am.errorHandler.SafeElementOperation(ctx, page, "#password", func(element *rod.Element) error {
-				if am.stealthTyper != nil {
-					return am.stealthTyper.HumanType(ctx, element, am.credentials.Password)
-				}
-				return element.Input(am.credentials.Password)
-			})
-			if err != nil {
-				return err
-			}
-
-			// Small delay before clicking submit
-			if am.stealthTyper != nil {
-				am.stealthTyper.RandomDelay(500*time.Millisecond, 1500*time.Millisecond)
-			}
-
-			// Find and click submit button
-			err = am.errorHandler.SafeElementOperation(ctx, page, "button[type='submit']", func(element *rod.Element) error {
-				return element.Click(proto.InputMouseButtonLeft, 1)
-			})
-			if err != nil {
-				return err
-			}
-
-			// Wait for navigation after login
-			time.Sleep(3 * time.Second)
-
-			// Check if login was successful
-			loggedIn, err := am.IsLoggedIn(ctx, page)
-			if err != nil {
-				return errors.NewError(errors.ErrorTypeTransient, "login", 
-					"failed to check login state", err)
-			}
-
-			if !loggedIn {
-				// Check for security challenges
-				hasChallenge, err := am.detectChallenge(ctx, page)
-				if err != nil {
-					return errors.NewError(errors.ErrorTypeTransient, "login", 
-						"failed to detect security challenge", err)
-				}
-				if hasChallenge {
-					return errors.NewError(errors.ErrorTypePermanent, "login", 
-						"security challenge detected (captcha or 2FA required)", nil)
-				}
-				return errors.NewError(errors.ErrorTypePermanent, "login", 
-					"login failed - credentials may be incorrect", nil)
-			}
-
-			return nil
-		})
+		return am.LoginWithResult(ctx, page).AsError()
 	})
 }
 
@@ -18,7 +18,7 @@ func (m *mockStealthTyper) HumanType(ctx context.Context, element *rod.Element,
 	return element.Input(text)
 }
 
-func (m *mockStealthTyper) RandomDelay(min, max time.Duration) error {
+func (m *mockStealthTyper) RandomDelay(ctx context.Context, min, max time.Duration) error {
 	return nil
 }
 
@@ -179,7 +179,7 @@ func (t *trackingStealthTyper) HumanType(ctx context.Context, element *rod.Eleme
 	return element.Input(text)
 }
 
-func (t *trackingStealthTyper) RandomDelay(min, max time.Duration) error {
+func (t *trackingStealthTyper) RandomDelay(ctx context.Context, min, max time.Duration) error {
 	return nil
 }
 
@@ -0,0 +1,465 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+
+	"linkedin-automation-framework/internal/errors"
+)
+
+// LoginState is a step of the login flow, recognized from the page the browser is on
+type LoginState string
+
+const (
+	StateCredentials    LoginState = "credentials"     // Login form waiting for username and password
+	StateRejected       LoginState = "rejected"        // Login form showing a credentials error
+	StateCaptcha        LoginState = "captcha"         // Puzzle or CAPTCHA challenge
+	StateTwoFactor      LoginState = "two_factor"      // Verification code prompt
+	StateCheckpoint     LoginState = "checkpoint"      // Any other security checkpoint
+	StateRememberDevice LoginState = "remember_device" // "Remember this browser" prompt after verification
+	StateFeed           LoginState = "feed"            // Logged in
+	StateUnknown        LoginState = "unknown"         // No detector matched, e.g. while a page loads
+)
+
+// LoginOutcome is how a login attempt ended
+type LoginOutcome string
+
+const (
+	OutcomeLoggedIn          LoginOutcome = "logged_in"
+	OutcomeBadCredentials    LoginOutcome = "bad_credentials"
+	OutcomeCaptchaRequired   LoginOutcome = "captcha_required"    // No hook was set to let the user solve it
+	OutcomeTwoFactorRequired LoginOutcome = "two_factor_required" // No hook was set to supply the code
+	OutcomeCheckpoint        LoginOutcome = "checkpoint"          // No hook was set to let the user pass it
+	OutcomeTimeout           LoginOutcome = "timeout"             // A state did not move on within its timeout
+	OutcomeFailed            LoginOutcome = "failed"              // The page could not be driven, or the login was cancelled
+)
+
+// Detector recognizes a login state; it matches when any of its URL parts, selectors or phrases is present
+type Detector struct {
+	URLContains []string
+	Selectors   []string
+	Phrases     []string // Lower-case text searched for in the page body
+}
+
+// StateDetector pairs a state with the detector recognizing it
+type StateDetector struct {
+	State    LoginState
+	Detector Detector
+}
+
+// LoginFlowConfig contains the login state machine's detectors and timeouts
+type LoginFlowConfig struct {
+	Detectors      []StateDetector              // Checked in order; the first match is the current state
+	StateTimeouts  map[LoginState]time.Duration // Time a state may last after its action, DefaultTimeout if missing
+	DefaultTimeout time.Duration
+	PollInterval   time.Duration // Time between detections while waiting for the next state
+	MaxSteps       int           // States visited before the login gives up, guarding against loops
+}
+
+// DefaultLoginFlowConfig returns detectors for LinkedIn's current login pages
+func DefaultLoginFlowConfig() LoginFlowConfig {
+	return LoginFlowConfig{
+		Detectors: []StateDetector{
+			{StateFeed, Detector{
+				URLContains: []string{"linkedin.com/feed"},
+				Selectors:   []string{".global-nav__me", ".feed-identity-module"},
+			}},
+			{StateRememberDevice, Detector{
+				URLContains: []string{"/checkpoint/lg/remember", "remember-me"},
+				Phrases:     []string{"remember this browser", "remember me on this browser"},
+			}},
+			{StateTwoFactor, Detector{
+				Selectors: []string{"input[name='pin']", "#input__phone_verification_pin", "#input__email_verification_pin"},
+			}},
+			{StateCaptcha, Detector{
+				Selectors: []string{"#captcha-internal", ".g-recaptcha", "iframe[src*='arkoselabs']", "iframe[title*='captcha' i]"},
+			}},
+			{StateRejected, Detector{
+				Selectors: []string{"#error-for-password:not(.hidden__imp)", "#error-for-username:not(.hidden__imp)"},
+			}},
+			{StateCheckpoint, Detector{
+				URLContains: []string{"/checkpoint/", "/challenge"},
+				Selectors:   []string{".security-verification"},
+			}},
+			{StateCredentials, Detector{
+				Selectors: []string{"#username"},
+			}},
+		},
+		StateTimeouts: map[LoginState]time.Duration{
+			StateCredentials: 30 * time.Second,
+			StateCaptcha:     5 * time.Minute,
+			StateCheckpoint:  5 * time.Minute,
+		},
+		DefaultTimeout: 20 * time.Second,
+		PollInterval:   500 * time.Millisecond,
+		MaxSteps:       10,
+	}
+}
+
+// LoginHooks let callers take part in the login flow. Unset hooks end the login at the state that needs them.
+type LoginHooks struct {
+	OnState        func(state LoginState)                            // Called whenever a new state is entered
+	SolveChallenge func(ctx context.Context, state LoginState) error // Blocks while the user passes a captcha or checkpoint by hand
+	TwoFactorCode  func(ctx context.Context) (string, error)         // Supplies the verification code
+	RememberDevice func() bool                                       // Whether to let LinkedIn trust this browser; defaults to yes with a trusted device store
+}
+
+// TrustedDeviceStore keeps the cookies of a browser LinkedIn was told to remember, apart from the session cookies
+type TrustedDeviceStore interface {
+	SaveTrustedDevice(path string) error
+	LoadTrustedDevice(path string) error
+}
+
+// LoginResult is the typed outcome of a login attempt
+type LoginResult struct {
+	Outcome LoginOutcome
+	State   LoginState   // State the flow ended in
+	Visited []LoginState // States in the order they were entered
+	Err     error        // Underlying error for OutcomeFailed, or why trusted-device cookies could not be saved
+
+	Remembered         bool // Whether LinkedIn was asked to remember this browser
+	TrustedDeviceUsed  bool // Whether saved trusted-device cookies were restored before logging in
+	TrustedDeviceSaved bool // Whether the trusted-device cookies were saved after logging in
+}
+
+// AsError returns nil for a successful login and a *LoginError otherwise
+func (r LoginResult) AsError() error {
+	if r.Outcome == OutcomeLoggedIn {
+		return nil
+	}
+	return &LoginError{Outcome: r.Outcome, State: r.State, Err: r.Err}
+}
+
+// LoginError reports a login that did not reach the feed
+type LoginError struct {
+	Outcome LoginOutcome
+	State   LoginState
+	Err     error
+}
+
+func (e *LoginError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("login ended with %s in state %s: %v", e.Outcome, e.State, e.Err)
+	}
+	return fmt.Sprintf("login ended with %s in state %s", e.Outcome, e.State)
+}
+
+func (e *LoginError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode is E_CHALLENGE for a login stopped by a captcha, verification code or checkpoint,
+// and leaves other outcomes to the error they wrap
+func (e *LoginError) ErrorCode() errors.Code {
+	switch e.Outcome {
+	case OutcomeCaptchaRequired, OutcomeTwoFactorRequired, OutcomeCheckpoint:
+		return errors.CodeChallenge
+	}
+	return ""
+}
+
+// loginPage is what the login flow needs from a browser page
+type loginPage interface {
+	URL() (string, error)
+	Has(selector string) bool
+	Text() (string, error)
+	Fill(ctx context.Context, selector, text string) error
+	Click(ctx context.Context, selector, label string) error // label, if set, is a pattern the element's text must match
+}
+
+// Detect returns the first state whose detector matches the page
+func (c LoginFlowConfig) Detect(page loginPage) LoginState {
+	url, _ := page.URL()
+	url = strings.ToLower(url)
+
+	var text string
+	textRead := false
+	for _, candidate := range c.Detectors {
+		detector := candidate.Detector
+		for _, part := range detector.URLContains {
+			if strings.Contains(url, strings.ToLower(part)) {
+				return candidate.State
+			}
+		}
+		for _, selector := range detector.Selectors {
+			if page.Has(selector) {
+				return candidate.State
+			}
+		}
+		if len(detector.Phrases) > 0 && !textRead {
+			text, _ = page.Text()
+			text = strings.ToLower(text)
+			textRead = true
+		}
+		for _, phrase := range detector.Phrases {
+			if strings.Contains(text, phrase) {
+				return candidate.State
+			}
+		}
+	}
+	return StateUnknown
+}
+
+// SetLoginFlow replaces the login detectors and timeouts
+func (am *AuthManager) SetLoginFlow(config LoginFlowConfig) {
+	am.flow = config
+}
+
+// SetLoginHooks sets the hooks called during login
+func (am *AuthManager) SetLoginHooks(hooks LoginHooks) {
+	am.hooks = hooks
+}
+
+// SetTimeouts changes how long the login waits for pages and form fields; zero timeouts are kept
+func (am *AuthManager) SetTimeouts(timeouts errors.Timeouts) {
+	am.errorHandler.SetTimeouts(timeouts)
+}
+
+// SetTrustedDevice keeps trusted-device cookies at path: they are restored before every login so
+// LinkedIn recognizes the browser, and saved whenever a login ends with LinkedIn remembering it
+func (am *AuthManager) SetTrustedDevice(store TrustedDeviceStore, path string) {
+	am.trusted = store
+	am.trustedPath = path
+}
+
+// LoginWithResult runs the login state machine from the login page and returns how it ended
+func (am *AuthManager) LoginWithResult(ctx context.Context, page *rod.Page) LoginResult {
+	if page == nil {
+		return LoginResult{Outcome: OutcomeFailed, State: StateUnknown,
+			Err: errors.NewError(errors.ErrorTypeConfiguration, "login", "page cannot be nil", nil)}
+	}
+
+	// Missing or expired trusted-device cookies just mean LinkedIn may challenge this login
+	used := am.trusted != nil && am.trusted.LoadTrustedDevice(am.trustedPath) == nil
+	if err := am.errorHandler.SafeNavigation(ctx, page, "https://www.linkedin.com/login"); err != nil {
+		return LoginResult{Outcome: OutcomeFailed, State: StateUnknown, Err: err, TrustedDeviceUsed: used}
+	}
+
+	result := am.runLogin(ctx, &rodLoginPage{page: page, typer: am.stealthTyper, timeout: am.errorHandler.Timeouts().Login})
+	result.TrustedDeviceUsed = used
+	am.keepTrustedDevice(&result)
+	return result
+}
+
+// keepTrustedDevice saves the trusted-device cookies after a login in which LinkedIn was asked to remember the browser
+func (am *AuthManager) keepTrustedDevice(result *LoginResult) {
+	if am.trusted == nil || result.Outcome != OutcomeLoggedIn || !result.Remembered {
+		return
+	}
+	if err := am.trusted.SaveTrustedDevice(am.trustedPath); err != nil {
+		result.Err = fmt.Errorf("failed to save trusted device cookies: %w", err)
+		return
+	}
+	result.TrustedDeviceSaved = true
+}
+
+// runLogin drives page through the login states until it reaches the feed or a state it cannot leave
+func (am *AuthManager) runLogin(ctx context.Context, page loginPage) LoginResult {
+	flow := am.flow
+	result := LoginResult{}
+	fail := func(state LoginState, outcome LoginOutcome, err error) LoginResult {
+		result.Outcome, result.State, result.Err = outcome, state, err
+		return result
+	}
+
+	state := flow.Detect(page)
+	if state == StateUnknown {
+		next, err := am.waitForState(ctx, page, state, flow.DefaultTimeout)
+		if err != nil {
+			return fail(state, OutcomeFailed, err)
+		}
+		state = next
+	}
+
+	for step := 0; ; step++ {
+		if state == StateUnknown {
+			return fail(state, OutcomeTimeout, nil)
+		}
+		if step >= flow.MaxSteps {
+			return fail(state, OutcomeFailed, fmt.Errorf("login did not finish after %d states", flow.MaxSteps))
+		}
+		result.Visited = append(result.Visited, state)
+		if am.hooks.OnState != nil {
+			am.hooks.OnState(state)
+		}
+
+		var err error
+		switch state {
+		case StateFeed:
+			result.Outcome, result.State = OutcomeLoggedIn, state
+			return result
+		case StateRejected:
+			return fail(state, OutcomeBadCredentials, nil)
+		case StateCredentials:
+			err = am.submitCredentials(ctx, page)
+		case StateCaptcha, StateCheckpoint:
+			if am.hooks.SolveChallenge == nil {
+				outcome := OutcomeCheckpoint
+				if state == StateCaptcha {
+					outcome = OutcomeCaptchaRequired
+				}
+				return fail(state, outcome, nil)
+			}
+			err = am.hooks.SolveChallenge(ctx, state)
+		case StateTwoFactor:
+			if am.hooks.TwoFactorCode == nil {
+				return fail(state, OutcomeTwoFactorRequired, nil)
+			}
+			err = am.submitTwoFactor(ctx, page)
+		case StateRememberDevice:
+			result.Remembered, err = am.answerRememberDevice(ctx, page)
+		}
+		if err != nil {
+			return fail(state, OutcomeFailed, err)
+		}
+
+		timeout := flow.DefaultTimeout
+		if stateTimeout, ok := flow.StateTimeouts[state]; ok {
+			timeout = stateTimeout
+		}
+		next, err := am.waitForState(ctx, page, state, timeout)
+		if err != nil {
+			return fail(state, OutcomeFailed, err)
+		}
+		if next == state {
+			return fail(state, OutcomeTimeout, nil)
+		}
+		state = next
+	}
+}
+
+// waitForState polls the page until it shows a recognized state other than current, returning current on timeout
+func (am *AuthManager) waitForState(ctx context.Context, page loginPage, current LoginState, timeout time.Duration) (LoginState, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		if state := am.flow.Detect(page); state != current && state != StateUnknown {
+			return state, nil
+		}
+		if time.Now().After(deadline) {
+			return current, nil
+		}
+		select {
+		case <-ctx.Done():
+			return current, ctx.Err()
+		case <-time.After(am.flow.PollInterval):
+		}
+	}
+}
+
+// submitCredentials fills in and submits the login form
+func (am *AuthManager) submitCredentials(ctx context.Context, page loginPage) error {
+	if err := page.Fill(ctx, "#username", am.credentials.Username); err != nil {
+		return err
+	}
+	am.pause(ctx)
+	if err := page.Fill(ctx, "#password", am.credentials.Password); err != nil {
+		return err
+	}
+	am.pause(ctx)
+	return page.Click(ctx, "button[type='submit']", "")
+}
+
+// submitTwoFactor enters the code from the TwoFactorCode hook into whichever code field is shown
+func (am *AuthManager) submitTwoFactor(ctx context.Context, page loginPage) error {
+	code, err := am.hooks.TwoFactorCode(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get verification code: %w", err)
+	}
+
+	for _, candidate := range am.flow.Detectors {
+		if candidate.State != StateTwoFactor {
+			continue
+		}
+		for _, selector := range candidate.Detector.Selectors {
+			if !page.Has(selector) {
+				continue
+			}
+			if err := page.Fill(ctx, selector, strings.TrimSpace(code)); err != nil {
+				return err
+			}
+			am.pause(ctx)
+			return page.Click(ctx, "button[type='submit'], #two-step-submit-button", "")
+		}
+	}
+	return fmt.Errorf("verification code field disappeared")
+}
+
+// answerRememberDevice accepts or declines LinkedIn's offer to trust this browser and reports which
+func (am *AuthManager) answerRememberDevice(ctx context.Context, page loginPage) (bool, error) {
+	remember := am.trusted != nil
+	if am.hooks.RememberDevice != nil {
+		remember = am.hooks.RememberDevice()
+	}
+
+	label := "(?i)not now|skip|don.t remember"
+	if remember {
+		label = "(?i)^\\s*(yes|remember|trust)"
+	}
+	return remember, page.Click(ctx, "button", label)
+}
+
+// pause waits a human-like moment between form actions
+func (am *AuthManager) pause(ctx context.Context) {
+	if am.stealthTyper != nil {
+		am.stealthTyper.RandomDelay(ctx, 500*time.Millisecond, 1500*time.Millisecond)
+	}
+}
+
+// rodLoginPage drives the login flow in a Rod page
+type rodLoginPage struct {
+	page    *rod.Page
+	typer   StealthTyper
+	timeout time.Duration // Bounds the wait for a form field or button
+}
+
+func (p *rodLoginPage) URL() (string, error) {
+	info, err := p.page.Info()
+	if err != nil {
+		return "", err
+	}
+	return info.URL, nil
+}
+
+func (p *rodLoginPage) Has(selector string) bool {
+	has, _, err := p.page.Has(selector)
+	return err == nil && has
+}
+
+func (p *rodLoginPage) Text() (string, error) {
+	body, err := p.page.Timeout(2 * time.Second).Element("body")
+	if err != nil {
+		return "", err
+	}
+	return body.Text()
+}
+
+func (p *rodLoginPage) Fill(ctx context.Context, selector, text string) error {
+	element, err := p.page.Context(ctx).Timeout(p.timeout).Element(selector)
+	if err != nil {
+		return fmt.Errorf("failed to find %s: %w", selector, err)
+	}
+	if p.typer != nil {
+		return p.typer.HumanType(ctx, element, text)
+	}
+	return element.Input(text)
+}
+
+func (p *rodLoginPage) Click(ctx context.Context, selector, label string) error {
+	page := p.page.Context(ctx).Timeout(p.timeout)
+	var element *rod.Element
+	var err error
+	if label != "" {
+		element, err = page.ElementR(selector, label)
+	} else {
+		element, err = page.Element(selector)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find %s: %w", selector, err)
+	}
+	return element.Click(proto.InputMouseButtonLeft, 1)
+}
@@ -0,0 +1,268 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/errors"
+)
+
+// fakeScreen is one page of a scripted login
+type fakeScreen struct {
+	url       string
+	selectors []string
+	text      string
+}
+
+// scriptedLoginPage moves to the next screen whenever a button is clicked
+type scriptedLoginPage struct {
+	screens []fakeScreen
+	current int
+	filled  map[string]string
+	labels  []string
+}
+
+func (p *scriptedLoginPage) URL() (string, error) { return p.screens[p.current].url, nil }
+
+func (p *scriptedLoginPage) Has(selector string) bool {
+	for _, present := range p.screens[p.current].selectors {
+		if present == selector {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *scriptedLoginPage) Text() (string, error) { return p.screens[p.current].text, nil }
+
+func (p *scriptedLoginPage) Fill(ctx context.Context, selector, text string) error {
+	p.filled[selector] = text
+	return nil
+}
+
+func (p *scriptedLoginPage) Click(ctx context.Context, selector, label string) error {
+	p.labels = append(p.labels, label)
+	if p.current < len(p.screens)-1 {
+		p.current++
+	}
+	return nil
+}
+
+var (
+	loginScreen     = fakeScreen{url: "https://www.linkedin.com/login", selectors: []string{"#username"}}
+	rejectedScreen  = fakeScreen{url: "https://www.linkedin.com/checkpoint/lg/login-submit", selectors: []string{"#username", "#error-for-password:not(.hidden__imp)"}}
+	twoFactorScreen = fakeScreen{url: "https://www.linkedin.com/checkpoint/challenge/abc", selectors: []string{"input[name='pin']"}}
+	captchaScreen   = fakeScreen{url: "https://www.linkedin.com/checkpoint/challenge/def", selectors: []string{"#captcha-internal"}}
+	rememberScreen  = fakeScreen{url: "https://www.linkedin.com/checkpoint/lg/remember-me", text: "Remember this browser?"}
+	feedScreen      = fakeScreen{url: "https://www.linkedin.com/feed/", selectors: []string{".global-nav__me"}}
+)
+
+// newLoginTestManager creates a manager with fast polling and credentials set
+func newLoginTestManager(hooks LoginHooks) *AuthManager {
+	am := NewAuthManager(&mockStealthTyper{}, &mockCookieManager{})
+	am.credentials = Credentials{Username: "user@example.com", Password: "secret"}
+	flow := DefaultLoginFlowConfig()
+	flow.PollInterval = time.Millisecond
+	flow.DefaultTimeout = 50 * time.Millisecond
+	flow.StateTimeouts = nil
+	am.SetLoginFlow(flow)
+	am.SetLoginHooks(hooks)
+	return am
+}
+
+// TestLoginStateMachineOutcomes tests that each path through the login pages ends in its typed outcome
+func TestLoginStateMachineOutcomes(t *testing.T) {
+	cases := []struct {
+		name    string
+		screens []fakeScreen
+		hooks   LoginHooks
+		outcome LoginOutcome
+		visited []LoginState
+		code    errors.Code
+	}{
+		{
+			name:    "direct",
+			screens: []fakeScreen{loginScreen, feedScreen},
+			outcome: OutcomeLoggedIn,
+			visited: []LoginState{StateCredentials, StateFeed},
+		},
+		{
+			name:    "bad credentials",
+			screens: []fakeScreen{loginScreen, rejectedScreen},
+			outcome: OutcomeBadCredentials,
+			visited: []LoginState{StateCredentials, StateRejected},
+		},
+		{
+			name:    "two factor without a code hook",
+			screens: []fakeScreen{loginScreen, twoFactorScreen},
+			outcome: OutcomeTwoFactorRequired,
+			visited: []LoginState{StateCredentials, StateTwoFactor},
+			code:    errors.CodeChallenge,
+		},
+		{
+			name:    "two factor and remember device",
+			screens: []fakeScreen{loginScreen, twoFactorScreen, rememberScreen, feedScreen},
+			hooks: LoginHooks{
+				TwoFactorCode:  func(ctx context.Context) (string, error) { return " 123456 ", nil },
+				RememberDevice: func() bool { return true },
+			},
+			outcome: OutcomeLoggedIn,
+			visited: []LoginState{StateCredentials, StateTwoFactor, StateRememberDevice, StateFeed},
+		},
+		{
+			name:    "captcha without a solve hook",
+			screens: []fakeScreen{loginScreen, captchaScreen},
+			outcome: OutcomeCaptchaRequired,
+			visited: []LoginState{StateCredentials, StateCaptcha},
+			code:    errors.CodeChallenge,
+		},
+		{
+			name:    "form that never advances",
+			screens: []fakeScreen{loginScreen},
+			outcome: OutcomeTimeout,
+			visited: []LoginState{StateCredentials},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var entered []LoginState
+			tc.hooks.OnState = func(state LoginState) { entered = append(entered, state) }
+			am := newLoginTestManager(tc.hooks)
+			page := &scriptedLoginPage{screens: tc.screens, filled: map[string]string{}}
+
+			result := am.runLogin(context.Background(), page)
+			if result.Outcome != tc.outcome {
+				t.Fatalf("expected outcome %s, got %s (%v)", tc.outcome, result.Outcome, result.Err)
+			}
+			if len(result.Visited) != len(tc.visited) || len(entered) != len(tc.visited) {
+				t.Fatalf("expected states %v, visited %v and hooked %v", tc.visited, result.Visited, entered)
+			}
+			for i := range tc.visited {
+				if result.Visited[i] != tc.visited[i] {
+					t.Fatalf("expected states %v, got %v", tc.visited, result.Visited)
+				}
+			}
+
+			err := result.AsError()
+			if tc.outcome == OutcomeLoggedIn {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			loginErr, ok := err.(*LoginError)
+			if !ok || loginErr.Outcome != tc.outcome {
+				t.Errorf("expected a *LoginError with outcome %s, got %v", tc.outcome, err)
+			}
+			if code := errors.CodeOf(err); code != tc.code {
+				t.Errorf("expected code %q, got %q", tc.code, code)
+			}
+		})
+	}
+}
+
+// TestLoginStateMachineUsesHooks tests that the code and remember-device answers reach the page
+func TestLoginStateMachineUsesHooks(t *testing.T) {
+	page := &scriptedLoginPage{
+		screens: []fakeScreen{loginScreen, captchaScreen, twoFactorScreen, rememberScreen, feedScreen},
+		filled:  map[string]string{},
+	}
+	solved := false
+	am := newLoginTestManager(LoginHooks{
+		// Passing the captcha by hand moves the page on without a click
+		SolveChallenge: func(ctx context.Context, state LoginState) error {
+			solved = state == StateCaptcha
+			page.current++
+			return nil
+		},
+		TwoFactorCode: func(ctx context.Context) (string, error) { return "654321", nil },
+	})
+
+	result := am.runLogin(context.Background(), page)
+	if result.Outcome != OutcomeLoggedIn {
+		t.Fatalf("expected a login, got %s in %s (%v)", result.Outcome, result.State, result.Err)
+	}
+	if !solved {
+		t.Errorf("expected the captcha to be handed to SolveChallenge")
+	}
+	if page.filled["#username"] != "user@example.com" || page.filled["#password"] != "secret" {
+		t.Errorf("unexpected credentials typed %v", page.filled)
+	}
+	if page.filled["input[name='pin']"] != "654321" {
+		t.Errorf("expected the verification code in the pin field, got %v", page.filled)
+	}
+	// Without a RememberDevice hook the prompt is declined
+	if last := page.labels[len(page.labels)-1]; last != "(?i)not now|skip|don.t remember" {
+		t.Errorf("expected the remember-device prompt to be declined, clicked %q", last)
+	}
+}
+
+// TestDetectLoginState tests that detectors are checked in order
+func TestDetectLoginState(t *testing.T) {
+	flow := DefaultLoginFlowConfig()
+	for _, tc := range []struct {
+		screen fakeScreen
+		state  LoginState
+	}{
+		{loginScreen, StateCredentials},
+		{rejectedScreen, StateRejected},
+		{twoFactorScreen, StateTwoFactor},
+		{captchaScreen, StateCaptcha},
+		{rememberScreen, StateRememberDevice},
+		{feedScreen, StateFeed},
+		{fakeScreen{url: "https://www.linkedin.com/checkpoint/challenge/xyz"}, StateCheckpoint},
+		{fakeScreen{url: "about:blank"}, StateUnknown},
+	} {
+		page := &scriptedLoginPage{screens: []fakeScreen{tc.screen}}
+		if state := flow.Detect(page); state != tc.state {
+			t.Errorf("expected %s for %s, got %s", tc.state, tc.screen.url, state)
+		}
+	}
+}
+
+// fakeTrustedDevices records trusted-device saves
+type fakeTrustedDevices struct {
+	saved []string
+}
+
+func (d *fakeTrustedDevices) SaveTrustedDevice(path string) error {
+	d.saved = append(d.saved, path)
+	return nil
+}
+
+func (d *fakeTrustedDevices) LoadTrustedDevice(path string) error {
+	return nil
+}
+
+// TestLoginRemembersTrustedDevice tests that a trusted device store accepts the prompt and keeps the cookies
+func TestLoginRemembersTrustedDevice(t *testing.T) {
+	devices := &fakeTrustedDevices{}
+	am := newLoginTestManager(LoginHooks{
+		TwoFactorCode: func(ctx context.Context) (string, error) { return "123456", nil },
+	})
+	am.SetTrustedDevice(devices, "trusted.json")
+
+	page := &scriptedLoginPage{screens: []fakeScreen{loginScreen, twoFactorScreen, rememberScreen, feedScreen}, filled: map[string]string{}}
+	result := am.runLogin(context.Background(), page)
+	am.keepTrustedDevice(&result)
+	if result.Outcome != OutcomeLoggedIn || !result.Remembered {
+		t.Fatalf("expected a login remembering the browser, got %+v", result)
+	}
+	if !result.TrustedDeviceSaved || len(devices.saved) != 1 || devices.saved[0] != "trusted.json" {
+		t.Errorf("expected the trusted device cookies to be saved once, got %v", devices.saved)
+	}
+
+	// Declining the prompt leaves the earlier trusted device file alone
+	am.SetLoginHooks(LoginHooks{
+		TwoFactorCode:  func(ctx context.Context) (string, error) { return "123456", nil },
+		RememberDevice: func() bool { return false },
+	})
+	page = &scriptedLoginPage{screens: []fakeScreen{loginScreen, twoFactorScreen, rememberScreen, feedScreen}, filled: map[string]string{}}
+	result = am.runLogin(context.Background(), page)
+	am.keepTrustedDevice(&result)
+	if result.Remembered || result.TrustedDeviceSaved || len(devices.saved) != 1 {
+		t.Errorf("expected nothing saved after declining, got %+v with saves %v", result, devices.saved)
+	}
+}
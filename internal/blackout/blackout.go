@@ -0,0 +1,141 @@
+package blackout
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"linkedin-automation-framework/internal/storage"
+)
+
+// Window is a period during which no actions run, such as a vacation, a company holiday or a
+// maintenance window
+type Window struct {
+	Start  time.Time
+	End    time.Time
+	Reason string
+	AdHoc  bool // Added from the command line rather than the configuration
+}
+
+// Contains reports whether t falls inside the window; the end is exclusive
+func (w Window) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// timeLayouts are the formats accepted for configured start and end times
+var timeLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04"}
+
+// ParseTime parses a time as RFC 3339, "2006-01-02 15:04" or a date alone, the last two in loc.
+// dateOnly reports a date without a time of day.
+func ParseTime(value string, loc *time.Location) (t time.Time, dateOnly bool, err error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return t, true, nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, false, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("invalid time %q: use 2006-01-02, 2006-01-02 15:04 or RFC 3339", value)
+}
+
+// ParseWindow parses a configured window. An end given as a date alone includes that whole day,
+// so "2024-12-24" to "2024-12-26" covers all three days.
+func ParseWindow(start, end, reason string, loc *time.Location) (Window, error) {
+	startTime, _, err := ParseTime(start, loc)
+	if err != nil {
+		return Window{}, fmt.Errorf("blackout start: %w", err)
+	}
+	endTime, dateOnly, err := ParseTime(end, loc)
+	if err != nil {
+		return Window{}, fmt.Errorf("blackout end: %w", err)
+	}
+	if dateOnly {
+		endTime = endTime.AddDate(0, 0, 1)
+	}
+	if !endTime.After(startTime) {
+		return Window{}, fmt.Errorf("blackout %s to %s ends before it starts", start, end)
+	}
+	return Window{Start: startTime, End: endTime, Reason: reason}, nil
+}
+
+// Store holds the ad-hoc pauses added from the command line
+type Store interface {
+	SaveBlackout(blackout storage.Blackout) error
+	GetBlackouts() ([]storage.Blackout, error)
+}
+
+// Calendar combines the configured windows with the ad-hoc pauses in storage. Pauses are read on
+// every call, so one added while a scheduler runs takes effect at its next check.
+type Calendar struct {
+	windows []Window
+	store   Store
+}
+
+// NewCalendar creates a calendar of the configured windows and the pauses in store, which may be nil
+func NewCalendar(windows []Window, store Store) *Calendar {
+	return &Calendar{windows: windows, store: store}
+}
+
+// Windows returns every configured window and ad-hoc pause, earliest start first
+func (c *Calendar) Windows() ([]Window, error) {
+	windows := append([]Window(nil), c.windows...)
+	if c.store != nil {
+		blackouts, err := c.store.GetBlackouts()
+		if err != nil {
+			return nil, err
+		}
+		for _, blackout := range blackouts {
+			windows = append(windows, Window{Start: blackout.Start, End: blackout.End, Reason: blackout.Reason, AdHoc: true})
+		}
+	}
+	sort.SliceStable(windows, func(i, j int) bool { return windows[i].Start.Before(windows[j].Start) })
+	return windows, nil
+}
+
+// Active returns the blackout now falls in, if any. Windows that overlap or touch it are merged,
+// so the returned End is when actions may run again.
+func (c *Calendar) Active(now time.Time) (Window, bool, error) {
+	windows, err := c.Windows()
+	if err != nil {
+		return Window{}, false, err
+	}
+
+	var active Window
+	found := false
+	for _, window := range windows {
+		if window.Contains(now) && (!found || window.End.After(active.End)) {
+			if !found {
+				active = window
+			}
+			active.End = window.End
+			found = true
+		}
+	}
+	if !found {
+		return Window{}, false, nil
+	}
+
+	// Windows are sorted by start, so one pass extends the end through every chained window
+	for _, window := range windows {
+		if !window.Start.After(active.End) && window.End.After(active.End) {
+			active.End = window.End
+		}
+	}
+	return active, true, nil
+}
+
+// Pause adds an ad-hoc pause from now for duration
+func (c *Calendar) Pause(now time.Time, duration time.Duration, reason string) (Window, error) {
+	if duration <= 0 {
+		return Window{}, fmt.Errorf("pause duration must be positive, got %s", duration)
+	}
+	if c.store == nil {
+		return Window{}, fmt.Errorf("no storage for ad-hoc pauses")
+	}
+	window := Window{Start: now, End: now.Add(duration), Reason: reason, AdHoc: true}
+	if err := c.store.SaveBlackout(storage.Blackout{Start: window.Start, End: window.End, Reason: reason, CreatedAt: now}); err != nil {
+		return Window{}, err
+	}
+	return window, nil
+}
@@ -0,0 +1,84 @@
+package blackout
+
+import (
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/storage"
+)
+
+// memoryStore keeps ad-hoc pauses in memory
+type memoryStore struct {
+	blackouts []storage.Blackout
+}
+
+func (s *memoryStore) SaveBlackout(blackout storage.Blackout) error {
+	s.blackouts = append(s.blackouts, blackout)
+	return nil
+}
+
+func (s *memoryStore) GetBlackouts() ([]storage.Blackout, error) {
+	return s.blackouts, nil
+}
+
+// TestParseWindow tests the accepted formats and that a date-only end covers its whole day
+func TestParseWindow(t *testing.T) {
+	window, err := ParseWindow("2024-12-24", "2024-12-26", "holidays", time.UTC)
+	if err != nil {
+		t.Fatalf("failed to parse window: %v", err)
+	}
+	if !window.Start.Equal(time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC)) || !window.End.Equal(time.Date(2024, 12, 27, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected 24 to 26 December inclusive, got %s to %s", window.Start, window.End)
+	}
+
+	window, err = ParseWindow("2024-07-01 22:00", "2024-07-02T02:30:00Z", "maintenance", time.UTC)
+	if err != nil {
+		t.Fatalf("failed to parse window: %v", err)
+	}
+	if window.End.Sub(window.Start) != 4*time.Hour+30*time.Minute {
+		t.Errorf("expected a 4h30m window, got %s", window.End.Sub(window.Start))
+	}
+
+	for _, bad := range [][2]string{{"2024-12-26", "2024-12-24"}, {"tomorrow", "2024-12-24"}, {"2024-12-24 10:00", "2024-12-24 10:00"}} {
+		if _, err := ParseWindow(bad[0], bad[1], "", time.UTC); err == nil {
+			t.Errorf("expected %s to %s to be rejected", bad[0], bad[1])
+		}
+	}
+}
+
+// TestCalendarActive tests that configured windows and ad-hoc pauses block actions, chained windows
+// are merged, and the end is exclusive
+func TestCalendarActive(t *testing.T) {
+	now := time.Date(2024, 12, 23, 18, 0, 0, 0, time.UTC)
+	holidays, _ := ParseWindow("2024-12-24", "2024-12-26", "holidays", time.UTC)
+	calendar := NewCalendar([]Window{holidays}, &memoryStore{})
+
+	if _, active, err := calendar.Active(now); err != nil || active {
+		t.Fatalf("expected no blackout before the holidays, got %v (%v)", active, err)
+	}
+
+	// A pause running into the holidays extends to their end
+	if _, err := calendar.Pause(now, 8*time.Hour, "offsite"); err != nil {
+		t.Fatalf("failed to pause: %v", err)
+	}
+	window, active, err := calendar.Active(now.Add(time.Hour))
+	if err != nil || !active {
+		t.Fatalf("expected the pause to be active, got %v (%v)", active, err)
+	}
+	if window.Reason != "offsite" || !window.AdHoc || !window.End.Equal(holidays.End) {
+		t.Errorf("expected the offsite pause merged through the holidays, got %+v", window)
+	}
+
+	if _, active, _ := calendar.Active(holidays.End); active {
+		t.Errorf("expected actions to run again once the holidays end")
+	}
+
+	windows, err := calendar.Windows()
+	if err != nil || len(windows) != 2 || windows[0].Reason != "offsite" {
+		t.Errorf("expected both windows, earliest first, got %+v (%v)", windows, err)
+	}
+
+	if _, err := calendar.Pause(now, 0, ""); err == nil {
+		t.Errorf("expected a zero pause to be rejected")
+	}
+}
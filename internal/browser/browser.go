@@ -2,16 +2,17 @@ package browser
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"os"
 	"time"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/launcher/flags"
 	"github.com/go-rod/rod/lib/proto"
 	
 	"linkedin-automation-framework/internal/errors"
+	"linkedin-automation-framework/internal/locale"
 )
 
 // BrowserManager interface for Rod browser lifecycle management
@@ -25,22 +26,41 @@ type BrowserManager interface {
 	Close() error
 }
 
+// Checkpointer is called at the points of a workflow where the page state matters, such as an
+// open invite dialog. replay.Recorder saves the page there and replay.Player restores it.
+type Checkpointer interface {
+	Checkpoint(page *rod.Page, label string) error
+}
+
 // Manager implements BrowserManager interface
 type Manager struct {
 	browser      *rod.Browser
 	config       BrowserConfig
 	errorHandler *errors.RodErrorHandler
 	recovery     *errors.GracefulErrorRecovery
+	checkpointer Checkpointer
+	pages        pageRegistry
+	pageHook     PageHook
+	launchedAt   time.Time
 }
 
 // BrowserConfig contains browser configuration options
 type BrowserConfig struct {
-	Headless   bool
-	UserAgent  string
-	ViewportW  int
-	ViewportH  int
-	Flags      []string
-	CookiePath string
+	Headless             bool
+	HeadlessMode         string // HeadlessModeNew (default) or HeadlessModeOld when Headless is set
+	Device               string // DeviceDesktop (default) or a DeviceProfiles name for mobile web emulation
+	UserAgent            string
+	ViewportW            int
+	ViewportH            int
+	Flags                []string
+	DisabledStealthFlags []string // Names of StealthFlags entries to leave out
+	CookiePath           string
+	CookieDomain         string          // Domain saved cookies are scoped to, defaults to DefaultCookieDomain
+	DownloadDir          string          // Where Download saves files, defaults to DefaultDownloadDir
+	Proxy                string          // Proxy URL all traffic goes through, credentials included; empty for none
+	WebRTC               string          // WebRTC IP handling policy, WebRTCDisableNonProxiedUDP unless set
+	DNSOverHTTPS         string          // DoH server template, e.g. "https://cloudflare-dns.com/dns-query"; empty uses the system resolver
+	Locale               locale.Settings // Time zone, languages and geolocation pages claim; the host's unless set
 }
 
 // NewManager creates a new browser manager instance
@@ -58,27 +78,46 @@ func (m *Manager) Initialize(ctx context.Context) error {
 		retryConfig := errors.DefaultRetryConfig()
 		retryConfig.MaxAttempts = 3
 		retryConfig.InitialDelay = 2 * time.Second
+		retryConfig.Operation = "browser_initialize"
+		
+		// Invalid devices and flag combinations will not fix themselves, so check them before retrying launches
+		device, err := ResolveDevice(m.config)
+		if err != nil {
+			return errors.NewError(errors.ErrorTypeConfiguration, "browser_initialize",
+				"invalid device profile", err)
+		}
+		
+		launchConfig := m.config
+		launchConfig.UserAgent = device.UserAgent
+		plan, err := BuildLaunchPlan(launchConfig)
+		if err != nil {
+			return errors.NewError(errors.ErrorTypeConfiguration, "browser_initialize",
+				"invalid browser launch flags", err)
+		}
 		
 		return errors.RetryWithBackoff(ctx, retryConfig, func(ctx context.Context, attempt int) error {
 			// Create launcher with configuration
 			l := launcher.New()
 			
 			// Configure headless mode
-			if m.config.Headless {
-				l = l.Headless(true)
-			} else {
+			switch {
+			case !m.config.Headless:
 				l = l.Headless(false)
+			case m.config.HeadlessMode == HeadlessModeOld:
+				l = l.Headless(true)
+			default:
+				l = l.Set(flags.Headless, HeadlessModeNew)
 			}
 			
-			// Apply common browser flags using available methods
-			for _, flag := range m.config.Flags {
-				switch flag {
-				case "--no-sandbox":
-					l = l.NoSandbox(true)
-				case "--disable-dev-shm-usage":
-					// This flag will be handled by Rod automatically in most cases
-				case "--disable-web-security":
-					// This flag will be handled by Rod automatically in most cases
+			// Apply curated stealth flags and free-form flags, dropping automation giveaways
+			for _, name := range plan.Delete {
+				l = l.Delete(flags.Flag(name))
+			}
+			for _, flag := range plan.Set {
+				if flag.Value == "" {
+					l = l.Set(flags.Flag(flag.Name))
+				} else {
+					l = l.Set(flags.Flag(flag.Name), flag.Value)
 				}
 			}
 			
@@ -96,6 +135,12 @@ func (m *Manager) Initialize(ctx context.Context) error {
 			}
 			
 			m.browser = browser
+			m.launchedAt = time.Now()
+
+			if err := m.handleProxyAuth(); err != nil {
+				return errors.NewError(errors.ErrorTypeTransient, "browser_initialize",
+					"failed to set up proxy authentication", err)
+			}
 			
 			// Configure fingerprint settings
 			err = m.configureFingerprint(ctx)
@@ -113,7 +158,32 @@ func (m *Manager) Browser() *rod.Browser {
 	return m.browser
 }
 
+// SetCheckpointer records or replays the page at every Checkpoint; nil turns checkpoints off
+func (m *Manager) SetCheckpointer(checkpointer Checkpointer) {
+	m.checkpointer = checkpointer
+}
+
+// Checkpoint passes the page to the checkpointer under label, if one is set
+func (m *Manager) Checkpoint(page *rod.Page, label string) error {
+	if m.checkpointer == nil {
+		return nil
+	}
+	return m.checkpointer.Checkpoint(page, label)
+}
+
+// NewPage creates a page with the device profile applied. The page is tracked until it is closed;
+// OpenPage also closes it when its work ends.
 func (m *Manager) NewPage() (*rod.Page, error) {
+	page, err := m.newPage()
+	if err != nil {
+		return nil, err
+	}
+	m.track(context.Background(), page, "")
+	return page, nil
+}
+
+// newPage creates a page with the device profile applied, closing it again if that fails
+func (m *Manager) newPage() (*rod.Page, error) {
 	var page *rod.Page
 	err := m.recovery.SafeExecute("new_page", func() error {
 		if m.browser == nil {
@@ -127,15 +197,21 @@ func (m *Manager) NewPage() (*rod.Page, error) {
 			return m.errorHandler.HandleRodError("create_page", err)
 		}
 		
-		// Set viewport if configured
-		if m.config.ViewportW > 0 && m.config.ViewportH > 0 {
-			err = page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
-				Width:  m.config.ViewportW,
-				Height: m.config.ViewportH,
-			})
-			if err != nil {
-				return m.errorHandler.HandleRodError("set_viewport", err)
-			}
+		// Apply the device profile's viewport, touch support and user agent
+		if err := m.emulateDevice(page); err != nil {
+			_ = page.Close()
+			page = nil
+			return m.errorHandler.HandleRodError("emulate_device", err)
+		}
+		if err := m.blockWebRTC(page); err != nil {
+			_ = page.Close()
+			page = nil
+			return m.errorHandler.HandleRodError("block_webrtc", err)
+		}
+		if err := m.emulateLocale(page); err != nil {
+			_ = page.Close()
+			page = nil
+			return m.errorHandler.HandleRodError("emulate_locale", err)
 		}
 		
 		return nil
@@ -157,23 +233,29 @@ func (m *Manager) NewIncognitoPage() (*rod.Page, error) {
 	
 	page, err := incognito.Page(proto.TargetCreateTarget{})
 	if err != nil {
+		_ = incognito.Close()
 		return nil, fmt.Errorf("failed to create incognito page: %w", err)
 	}
 	
-	// Set viewport if configured
-	if m.config.ViewportW > 0 && m.config.ViewportH > 0 {
-		err = page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
-			Width:  m.config.ViewportW,
-			Height: m.config.ViewportH,
-		})
-		if err != nil {
-			return nil, fmt.Errorf("failed to set viewport: %w", err)
-		}
+	// Apply the device profile's viewport, touch support and user agent
+	if err := m.emulateDevice(page); err != nil {
+		_ = incognito.Close()
+		return nil, err
+	}
+	if err := m.blockWebRTC(page); err != nil {
+		_ = incognito.Close()
+		return nil, err
+	}
+	if err := m.emulateLocale(page); err != nil {
+		_ = incognito.Close()
+		return nil, err
 	}
 	
+	m.track(context.Background(), page, "incognito")
 	return page, nil
 }
 
+// SaveCookies writes the browser's cookies for the configured domain to a versioned cookie jar file
 func (m *Manager) SaveCookies(path string) error {
 	if m.browser == nil {
 		return fmt.Errorf("browser not initialized")
@@ -195,42 +277,21 @@ func (m *Manager) SaveCookies(path string) error {
 		return fmt.Errorf("failed to get cookies: %w", err)
 	}
 	
-	// Marshal cookies to JSON
-	data, err := json.Marshal(cookies)
-	if err != nil {
-		return fmt.Errorf("failed to marshal cookies: %w", err)
-	}
+	jar := NewCookieJar(m.cookieDomain(), cookies)
+	jar.Prune(time.Now())
 	
-	// Write to file
-	err = os.WriteFile(path, data, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to write cookies file: %w", err)
-	}
-	
-	return nil
+	return jar.Save(path)
 }
 
+// LoadCookies restores a saved cookie jar, refusing sessions whose essential auth cookies are missing or expired
 func (m *Manager) LoadCookies(path string) error {
 	if m.browser == nil {
 		return fmt.Errorf("browser not initialized")
 	}
 	
-	// Check if file exists
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return fmt.Errorf("cookies file does not exist: %s", path)
-	}
-	
-	// Read cookies file
-	data, err := os.ReadFile(path)
+	jar, err := m.RestorableCookies(path)
 	if err != nil {
-		return fmt.Errorf("failed to read cookies file: %w", err)
-	}
-	
-	// Unmarshal cookies
-	var cookies []*proto.NetworkCookie
-	err = json.Unmarshal(data, &cookies)
-	if err != nil {
-		return fmt.Errorf("failed to unmarshal cookies: %w", err)
+		return err
 	}
 	
 	// Get pages to set cookies
@@ -243,22 +304,8 @@ func (m *Manager) LoadCookies(path string) error {
 		return fmt.Errorf("no pages available to set cookies")
 	}
 	
-	// Convert cookies to the correct type for SetCookies
-	cookieParams := make([]*proto.NetworkCookieParam, len(cookies))
-	for i, cookie := range cookies {
-		cookieParams[i] = &proto.NetworkCookieParam{
-			Name:     cookie.Name,
-			Value:    cookie.Value,
-			Domain:   cookie.Domain,
-			Path:     cookie.Path,
-			Secure:   cookie.Secure,
-			HTTPOnly: cookie.HTTPOnly,
-			SameSite: cookie.SameSite,
-		}
-	}
-	
 	// Set cookies on the first page
-	err = pages[0].SetCookies(cookieParams)
+	err = pages[0].SetCookies(jar.Params())
 	if err != nil {
 		return fmt.Errorf("failed to set cookies: %w", err)
 	}
@@ -266,13 +313,128 @@ func (m *Manager) LoadCookies(path string) error {
 	return nil
 }
 
+// SaveTrustedDevice writes the browser's trusted-device cookies to their own jar at path, apart
+// from the session cookies, so a logout or expired session does not lose the trusted device
+func (m *Manager) SaveTrustedDevice(path string) error {
+	if m.browser == nil {
+		return fmt.Errorf("browser not initialized")
+	}
+
+	cookies, err := m.browser.GetCookies()
+	if err != nil {
+		return fmt.Errorf("failed to get cookies: %w", err)
+	}
+
+	trusted, err := NewCookieJar(m.cookieDomain(), cookies).TrustedDevice(time.Now())
+	if err != nil {
+		return err
+	}
+	return trusted.Save(path)
+}
+
+// LoadTrustedDevice restores trusted-device cookies saved by SaveTrustedDevice, returning
+// ErrNoTrustedDevice if there are none or they have expired
+func (m *Manager) LoadTrustedDevice(path string) error {
+	if m.browser == nil {
+		return fmt.Errorf("browser not initialized")
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return ErrNoTrustedDevice
+	}
+	jar, err := LoadCookieJar(path)
+	if err != nil {
+		return err
+	}
+	scoped := NewCookieJar(m.cookieDomain(), jar.Cookies)
+	trusted, err := scoped.TrustedDevice(time.Now())
+	if err != nil {
+		return err
+	}
+
+	if err := m.browser.SetCookies(trusted.Params()); err != nil {
+		return fmt.Errorf("failed to set trusted device cookies: %w", err)
+	}
+	return nil
+}
+
+// RestorableCookies loads the cookie jar at path, scoped to the configured domain with expired
+// cookies pruned, and returns ErrSessionNotRestorable if it cannot restore a logged-in session
+func (m *Manager) RestorableCookies(path string) (*CookieJar, error) {
+	jar, err := LoadCookieJar(path)
+	if err != nil {
+		return nil, err
+	}
+	
+	now := time.Now()
+	scoped := NewCookieJar(m.cookieDomain(), jar.Cookies)
+	scoped.SavedAt = jar.SavedAt
+	scoped.Prune(now)
+	
+	if err := scoped.Validate(m.cookieDomain(), now); err != nil {
+		return nil, err
+	}
+	return scoped, nil
+}
+
+// Device returns the emulated device profile, falling back to desktop if the configured device is unknown
+func (m *Manager) Device() DeviceProfile {
+	device, err := ResolveDevice(m.config)
+	if err != nil {
+		device, _ = ResolveDevice(BrowserConfig{UserAgent: m.config.UserAgent, ViewportW: m.config.ViewportW, ViewportH: m.config.ViewportH})
+	}
+	return device
+}
+
+// emulateDevice applies the configured device profile to a new page
+func (m *Manager) emulateDevice(page *rod.Page) error {
+	return EmulateDevice(page, m.Device())
+}
+
+// Ping asks the browser for its version, failing if it is not initialized or does not answer
+// before ctx ends
+func (m *Manager) Ping(ctx context.Context) error {
+	if m.browser == nil {
+		return fmt.Errorf("browser not initialized")
+	}
+	if _, err := m.browser.Context(ctx).Version(); err != nil {
+		return fmt.Errorf("browser did not answer: %w", err)
+	}
+	return nil
+}
+
+// CheckSession checks the browser's cookies for the configured domain the way a saved jar is
+// checked, returning ErrSessionNotRestorable once its essential auth cookies are missing or expired
+func (m *Manager) CheckSession(ctx context.Context) error {
+	if m.browser == nil {
+		return fmt.Errorf("browser not initialized")
+	}
+	cookies, err := m.browser.Context(ctx).GetCookies()
+	if err != nil {
+		return fmt.Errorf("failed to get cookies: %w", err)
+	}
+	now := time.Now()
+	jar := NewCookieJar(m.cookieDomain(), cookies)
+	jar.Prune(now)
+	return jar.Validate(m.cookieDomain(), now)
+}
+
+// cookieDomain returns the domain cookies are scoped to
+func (m *Manager) cookieDomain() string {
+	if m.config.CookieDomain != "" {
+		return m.config.CookieDomain
+	}
+	return DefaultCookieDomain
+}
+
 func (m *Manager) Close() error {
 	return m.recovery.SafeExecute("browser_close", func() error {
 		if m.browser == nil {
 			return nil // Already closed or never initialized
 		}
 		
-		// Close all pages first
+		// Close all pages first, tracked ones through the registry so none is closed twice
+		m.pages.closeAll()
 		pages, err := m.browser.Pages()
 		if err == nil {
 			for _, page := range pages {
@@ -324,10 +486,11 @@ func (m *Manager) configureFingerprint(ctx context.Context) error {
 			return fmt.Errorf("failed to mask webdriver property: %w", err)
 		}
 		
-		// Set user agent if configured
-		if m.config.UserAgent != "" {
+		// Set user agent if configured, preferring the emulated device's
+		if device := m.Device(); device.UserAgent != "" {
 			err = page.SetUserAgent(&proto.NetworkSetUserAgentOverride{
-				UserAgent: m.config.UserAgent,
+				UserAgent: device.UserAgent,
+				Platform:  device.Platform,
 			})
 			if err != nil {
 				return fmt.Errorf("failed to set user agent: %w", err)
@@ -0,0 +1,355 @@
+// Package browsertest provides an in-memory page for testing code written against
+// browser.PageDriver without a browser.
+package browsertest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/go-rod/rod/lib/input"
+	"github.com/ysmood/gson"
+
+	"linkedin-automation-framework/internal/browser"
+)
+
+// Page is a fake page holding a tree of elements. Selectors are not parsed: an element matches
+// exactly the selectors it was created with, so tests name the selectors the code under test uses.
+type Page struct {
+	url         string
+	root        *Element
+	routes      map[string]func(page *Page)
+	scripts     map[string]func(args ...interface{}) (interface{}, error)
+	navigations []string
+	mouseX      float64
+	mouseY      float64
+	mouseMoves  int
+	scrollX     float64
+	scrollY     float64
+}
+
+// NewPage creates an empty page at url
+func NewPage(url string) *Page {
+	return &Page{
+		url:     url,
+		root:    &Element{},
+		routes:  make(map[string]func(page *Page)),
+		scripts: make(map[string]func(args ...interface{}) (interface{}, error)),
+	}
+}
+
+// Append adds elements to the page's document
+func (p *Page) Append(elements ...*Element) *Page {
+	p.root.Append(elements...)
+	return p
+}
+
+// Route builds the document shown after navigating to url. Navigating to a URL without a route
+// leaves the document as it is, so tests of a single page need no routes.
+func (p *Page) Route(url string, build func(page *Page)) *Page {
+	p.routes[url] = build
+	return p
+}
+
+// OnEval answers a script passed to Eval
+func (p *Page) OnEval(js string, fn func(args ...interface{}) (interface{}, error)) *Page {
+	p.scripts[js] = fn
+	return p
+}
+
+// Clear removes every element from the document
+func (p *Page) Clear() {
+	p.root = &Element{}
+}
+
+// Navigations returns the URLs navigated to, in order
+func (p *Page) Navigations() []string {
+	return append([]string(nil), p.navigations...)
+}
+
+// Mouse returns the mouse position and how many times it was moved
+func (p *Page) Mouse() (x, y float64, moves int) {
+	return p.mouseX, p.mouseY, p.mouseMoves
+}
+
+// Scrolled returns how far the page was scrolled in total
+func (p *Page) Scrolled() (x, y float64) {
+	return p.scrollX, p.scrollY
+}
+
+func (p *Page) Navigate(url string) error {
+	p.navigations = append(p.navigations, url)
+	p.url = url
+	if build, ok := p.routes[url]; ok {
+		p.Clear()
+		build(p)
+	}
+	return nil
+}
+
+func (p *Page) WaitLoad() error {
+	return nil
+}
+
+func (p *Page) URL() (string, error) {
+	return p.url, nil
+}
+
+func (p *Page) Element(selector string) (browser.ElementDriver, error) {
+	return p.root.Element(selector)
+}
+
+func (p *Page) Elements(selector string) ([]browser.ElementDriver, error) {
+	return p.root.Elements(selector)
+}
+
+func (p *Page) Has(selector string) (bool, browser.ElementDriver, error) {
+	element, err := p.root.Element(selector)
+	if err != nil {
+		return false, nil, nil
+	}
+	return true, element, nil
+}
+
+func (p *Page) Eval(js string, args ...interface{}) (gson.JSON, error) {
+	fn, ok := p.scripts[js]
+	if !ok {
+		return gson.JSON{}, fmt.Errorf("no fake result for script %q", js)
+	}
+	value, err := fn(args...)
+	if err != nil {
+		return gson.JSON{}, err
+	}
+	return gson.New(value), nil
+}
+
+func (p *Page) MouseMoveTo(x, y float64) error {
+	p.mouseX, p.mouseY = x, y
+	p.mouseMoves++
+	return nil
+}
+
+func (p *Page) MouseScroll(x, y float64, steps int) error {
+	p.scrollX += x
+	p.scrollY += y
+	return nil
+}
+
+func (p *Page) Context(ctx context.Context) browser.PageDriver {
+	return p
+}
+
+// Element is a fake element. Its text is its own text followed by its descendants' on new lines.
+type Element struct {
+	selectors  []string
+	text       string
+	attributes map[string]string
+	hidden     bool
+	x, y       float64
+	parent     *Element
+	children   []*Element
+	onClick    func()
+	clicks     int
+	value      string
+	selected   bool
+}
+
+// NewElement creates an element matching selectors
+func NewElement(selectors ...string) *Element {
+	return &Element{selectors: selectors, attributes: make(map[string]string)}
+}
+
+// SetText sets the element's own text
+func (e *Element) SetText(text string) *Element {
+	e.text = text
+	return e
+}
+
+// SetAttribute sets an attribute
+func (e *Element) SetAttribute(name, value string) *Element {
+	e.attributes[name] = value
+	return e
+}
+
+// SetHidden makes the element invisible; it is still found by selectors, as in a real page
+func (e *Element) SetHidden(hidden bool) *Element {
+	e.hidden = hidden
+	return e
+}
+
+// SetPosition sets the element's center on the page
+func (e *Element) SetPosition(x, y float64) *Element {
+	e.x, e.y = x, y
+	return e
+}
+
+// OnClick sets a function run on every click, e.g. to open a dialog
+func (e *Element) OnClick(fn func()) *Element {
+	e.onClick = fn
+	return e
+}
+
+// Append adds children to the element
+func (e *Element) Append(children ...*Element) *Element {
+	for _, child := range children {
+		child.parent = e
+		e.children = append(e.children, child)
+	}
+	return e
+}
+
+// Remove detaches the element from its parent
+func (e *Element) Remove() {
+	if e.parent == nil {
+		return
+	}
+	siblings := e.parent.children[:0]
+	for _, child := range e.parent.children {
+		if child != e {
+			siblings = append(siblings, child)
+		}
+	}
+	e.parent.children = siblings
+	e.parent = nil
+}
+
+// Clicks returns how many times the element was clicked
+func (e *Element) Clicks() int {
+	return e.clicks
+}
+
+// Value returns the text typed into the element
+func (e *Element) Value() string {
+	return e.value
+}
+
+// matches reports whether the element was created with selector
+func (e *Element) matches(selector string) bool {
+	for _, s := range e.selectors {
+		if s == selector {
+			return true
+		}
+	}
+	return false
+}
+
+// descendants appends the element's descendants matching selector in document order
+func (e *Element) descendants(selector string, found []browser.ElementDriver) []browser.ElementDriver {
+	for _, child := range e.children {
+		if child.matches(selector) {
+			found = append(found, child)
+		}
+		found = child.descendants(selector, found)
+	}
+	return found
+}
+
+func (e *Element) Element(selector string) (browser.ElementDriver, error) {
+	found := e.descendants(selector, nil)
+	if len(found) == 0 {
+		return nil, fmt.Errorf("element not found: %s", selector)
+	}
+	return found[0], nil
+}
+
+func (e *Element) Elements(selector string) ([]browser.ElementDriver, error) {
+	return e.descendants(selector, []browser.ElementDriver{}), nil
+}
+
+func (e *Element) Parent() (browser.ElementDriver, error) {
+	if e.parent == nil {
+		return nil, fmt.Errorf("element has no parent")
+	}
+	return e.parent, nil
+}
+
+func (e *Element) Closest(selector string) (browser.ElementDriver, error) {
+	for ancestor := e.parent; ancestor != nil; ancestor = ancestor.parent {
+		if ancestor.matches(selector) {
+			return ancestor, nil
+		}
+	}
+	return nil, fmt.Errorf("no ancestor matches %s", selector)
+}
+
+func (e *Element) Text() (string, error) {
+	var lines []string
+	if e.text != "" {
+		lines = append(lines, e.text)
+	}
+	for _, child := range e.children {
+		if text, _ := child.Text(); text != "" {
+			lines = append(lines, text)
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (e *Element) Attribute(name string) (*string, error) {
+	value, ok := e.attributes[name]
+	if !ok {
+		return nil, nil
+	}
+	return &value, nil
+}
+
+func (e *Element) Visible() (bool, error) {
+	return !e.hidden, nil
+}
+
+func (e *Element) Center() (float64, float64, error) {
+	if e.hidden {
+		return 0, 0, fmt.Errorf("element has no visible area")
+	}
+	return e.x, e.y, nil
+}
+
+func (e *Element) Click() error {
+	if e.hidden {
+		return fmt.Errorf("element is not visible")
+	}
+	e.clicks++
+	if e.onClick != nil {
+		e.onClick()
+	}
+	return nil
+}
+
+func (e *Element) SelectAllText() error {
+	e.selected = true
+	return nil
+}
+
+// Input replaces the selected text, or adds to the end of the value
+func (e *Element) Input(text string) error {
+	if e.selected {
+		e.value = ""
+		e.selected = false
+	}
+	e.value += text
+	return nil
+}
+
+func (e *Element) Type(keys ...input.Key) error {
+	for _, key := range keys {
+		switch {
+		case key == input.Backspace:
+			if e.selected {
+				e.value, e.selected = "", false
+			} else if runes := []rune(e.value); len(runes) > 0 {
+				e.value = string(runes[:len(runes)-1])
+			}
+		case unicode.IsPrint(rune(key)):
+			if err := e.Input(string(rune(key))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var (
+	_ browser.PageDriver    = (*Page)(nil)
+	_ browser.ElementDriver = (*Element)(nil)
+)
@@ -0,0 +1,214 @@
+package browser
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+
+	"linkedin-automation-framework/internal/errors"
+)
+
+// CookieJarVersion is the current on-disk cookie jar format version
+const CookieJarVersion = 1
+
+// DefaultCookieDomain scopes saved and restored cookies when no domain is configured
+const DefaultCookieDomain = "linkedin.com"
+
+// EssentialCookies must be present and unexpired for a saved session to be restorable
+var EssentialCookies = []string{"li_at"}
+
+// TrustedDeviceCookies identify a browser LinkedIn was told to remember after a verification
+// challenge; restoring them lets later logins skip the challenge
+var TrustedDeviceCookies = []string{"li_rm", "bcookie", "bscookie"}
+
+// ErrNoTrustedDevice is returned when no trusted-device cookies are available
+var ErrNoTrustedDevice = stderrors.New("no trusted device cookies")
+
+// ErrSessionNotRestorable is returned when a cookie jar lacks valid authentication cookies
+var ErrSessionNotRestorable = errors.NewCoded(errors.CodeSessionExpired, "session not restorable")
+
+// CookieJar is a versioned, domain-scoped set of browser cookies
+type CookieJar struct {
+	Version int                    `json:"version"`
+	Domain  string                 `json:"domain,omitempty"`
+	SavedAt time.Time              `json:"saved_at"`
+	Cookies []*proto.NetworkCookie `json:"cookies"`
+}
+
+// NewCookieJar creates a jar holding the cookies that belong to domain; an empty domain keeps every cookie
+func NewCookieJar(domain string, cookies []*proto.NetworkCookie) *CookieJar {
+	jar := &CookieJar{
+		Version: CookieJarVersion,
+		Domain:  domain,
+		SavedAt: time.Now(),
+	}
+	for _, cookie := range cookies {
+		if cookie != nil && CookieMatchesDomain(cookie.Domain, domain) {
+			jar.Cookies = append(jar.Cookies, cookie)
+		}
+	}
+	return jar
+}
+
+// LoadCookieJar reads a cookie jar file, accepting the legacy bare-array format as version 0
+func LoadCookieJar(path string) (*CookieJar, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("cookies file does not exist: %s", path)
+		}
+		return nil, fmt.Errorf("failed to read cookies file: %w", err)
+	}
+
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "[") {
+		var cookies []*proto.NetworkCookie
+		if err := json.Unmarshal(data, &cookies); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal cookies: %w", err)
+		}
+		return &CookieJar{Version: 0, Cookies: cookies}, nil
+	}
+
+	jar := &CookieJar{}
+	if err := json.Unmarshal(data, jar); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cookie jar: %w", err)
+	}
+	if jar.Version > CookieJarVersion {
+		return nil, fmt.Errorf("unsupported cookie jar version %d (latest supported is %d)", jar.Version, CookieJarVersion)
+	}
+	return jar, nil
+}
+
+// Save writes the jar to path; the file is readable only by the owner since it holds session tokens
+func (j *CookieJar) Save(path string) error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cookie jar: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cookies file: %w", err)
+	}
+	return nil
+}
+
+// Prune removes cookies that expired before now and returns how many were dropped
+func (j *CookieJar) Prune(now time.Time) int {
+	kept := j.Cookies[:0]
+	for _, cookie := range j.Cookies {
+		if !cookieExpired(cookie, now) {
+			kept = append(kept, cookie)
+		}
+	}
+	pruned := len(j.Cookies) - len(kept)
+	j.Cookies = kept
+	return pruned
+}
+
+// ForDomain returns the cookies scoped to the given domain or one of its subdomains
+func (j *CookieJar) ForDomain(domain string) []*proto.NetworkCookie {
+	var cookies []*proto.NetworkCookie
+	for _, cookie := range j.Cookies {
+		if CookieMatchesDomain(cookie.Domain, domain) {
+			cookies = append(cookies, cookie)
+		}
+	}
+	return cookies
+}
+
+// Get returns the named cookie for the domain, or nil if the jar does not hold it
+func (j *CookieJar) Get(domain, name string) *proto.NetworkCookie {
+	for _, cookie := range j.ForDomain(domain) {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+// Validate checks that every essential authentication cookie is present and unexpired
+func (j *CookieJar) Validate(domain string, now time.Time) error {
+	var missing []string
+	for _, name := range EssentialCookies {
+		cookie := j.Get(domain, name)
+		if cookie == nil || cookie.Value == "" || cookieExpired(cookie, now) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: missing or expired %s", ErrSessionNotRestorable, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Only returns a jar holding just the named cookies
+func (j *CookieJar) Only(names []string) *CookieJar {
+	only := &CookieJar{Version: j.Version, Domain: j.Domain, SavedAt: j.SavedAt}
+	for _, cookie := range j.Cookies {
+		for _, name := range names {
+			if cookie.Name == name {
+				only.Cookies = append(only.Cookies, cookie)
+				break
+			}
+		}
+	}
+	return only
+}
+
+// TrustedDevice returns the jar's trusted-device cookies, or ErrNoTrustedDevice if the
+// remember-me cookie is missing or expired, since the others alone do not skip challenges
+func (j *CookieJar) TrustedDevice(now time.Time) (*CookieJar, error) {
+	trusted := j.Only(TrustedDeviceCookies)
+	trusted.Prune(now)
+	if cookie := trusted.Get(j.Domain, TrustedDeviceCookies[0]); cookie == nil || cookie.Value == "" {
+		return nil, ErrNoTrustedDevice
+	}
+	return trusted, nil
+}
+
+// Params converts the jar's cookies into parameters for Page.SetCookies
+func (j *CookieJar) Params() []*proto.NetworkCookieParam {
+	params := make([]*proto.NetworkCookieParam, len(j.Cookies))
+	for i, cookie := range j.Cookies {
+		params[i] = &proto.NetworkCookieParam{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			Secure:   cookie.Secure,
+			HTTPOnly: cookie.HTTPOnly,
+			SameSite: cookie.SameSite,
+		}
+		if !cookie.Session && cookie.Expires > 0 {
+			params[i].Expires = cookie.Expires
+		}
+	}
+	return params
+}
+
+// CookieMatchesDomain reports whether a cookie set for cookieDomain belongs to domain or one of its subdomains;
+// single-label domains such as ".com" never match, so public-suffix cookies stay out of the jar
+func CookieMatchesDomain(cookieDomain, domain string) bool {
+	if domain == "" {
+		return true
+	}
+	cookieDomain = strings.ToLower(strings.TrimPrefix(cookieDomain, "."))
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	if !strings.Contains(cookieDomain, ".") || !strings.Contains(domain, ".") {
+		return false
+	}
+	return cookieDomain == domain || strings.HasSuffix(cookieDomain, "."+domain)
+}
+
+// cookieExpired reports whether a persistent cookie's expiry lies before now; session cookies never expire here
+func cookieExpired(cookie *proto.NetworkCookie, now time.Time) bool {
+	if cookie.Session || cookie.Expires <= 0 {
+		return false
+	}
+	return cookie.Expires.Time().Before(now)
+}
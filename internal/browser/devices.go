@@ -0,0 +1,115 @@
+package browser
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// DeviceDesktop is the default device: the configured user agent and viewport without mobile emulation
+const DeviceDesktop = "desktop"
+
+// DeviceProfile describes the device a page emulates
+type DeviceProfile struct {
+	Name              string
+	UserAgent         string
+	Platform          string // navigator.platform reported with the user agent override
+	ViewportW         int
+	ViewportH         int
+	DeviceScaleFactor float64
+	Mobile            bool // Mobile viewport semantics and LinkedIn's mobile web layout
+	MaxTouchPoints    int  // Touch emulation is enabled when greater than zero
+}
+
+// DeviceProfiles are the built-in mobile web profiles selectable by name
+var DeviceProfiles = map[string]DeviceProfile{
+	"iphone-safari": {
+		Name:              "iphone-safari",
+		UserAgent:         "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+		Platform:          "iPhone",
+		ViewportW:         390,
+		ViewportH:         844,
+		DeviceScaleFactor: 3,
+		Mobile:            true,
+		MaxTouchPoints:    5,
+	},
+	"android-chrome": {
+		Name:              "android-chrome",
+		UserAgent:         "Mozilla/5.0 (Linux; Android 14; Pixel 7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36",
+		Platform:          "Linux armv81",
+		ViewportW:         412,
+		ViewportH:         915,
+		DeviceScaleFactor: 2.625,
+		Mobile:            true,
+		MaxTouchPoints:    5,
+	},
+}
+
+// DeviceNames lists the selectable device names, desktop first
+func DeviceNames() []string {
+	names := make([]string, 0, len(DeviceProfiles))
+	for name := range DeviceProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{DeviceDesktop}, names...)
+}
+
+// ResolveDevice returns the device profile for the configuration; the desktop
+// profile takes its user agent and viewport from the configuration itself
+func ResolveDevice(config BrowserConfig) (DeviceProfile, error) {
+	if config.Device == "" || config.Device == DeviceDesktop {
+		return DeviceProfile{
+			Name:      DeviceDesktop,
+			UserAgent: config.UserAgent,
+			ViewportW: config.ViewportW,
+			ViewportH: config.ViewportH,
+		}, nil
+	}
+
+	profile, ok := DeviceProfiles[config.Device]
+	if !ok {
+		return DeviceProfile{}, fmt.Errorf("unknown device %q (available: %v)", config.Device, DeviceNames())
+	}
+	return profile, nil
+}
+
+// EmulateDevice applies the profile's viewport, touch support and user agent to the page
+func EmulateDevice(page *rod.Page, device DeviceProfile) error {
+	if device.ViewportW > 0 && device.ViewportH > 0 {
+		err := page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
+			Width:             device.ViewportW,
+			Height:            device.ViewportH,
+			DeviceScaleFactor: device.DeviceScaleFactor,
+			Mobile:            device.Mobile,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set viewport: %w", err)
+		}
+	}
+
+	if device.MaxTouchPoints > 0 {
+		maxTouchPoints := device.MaxTouchPoints
+		err := proto.EmulationSetTouchEmulationEnabled{
+			Enabled:        true,
+			MaxTouchPoints: &maxTouchPoints,
+		}.Call(page)
+		if err != nil {
+			return fmt.Errorf("failed to enable touch emulation: %w", err)
+		}
+	}
+
+	if device.Mobile && device.UserAgent != "" {
+		err := page.SetUserAgent(&proto.NetworkSetUserAgentOverride{
+			UserAgent: device.UserAgent,
+			Platform:  device.Platform,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set user agent: %w", err)
+		}
+	}
+
+	return nil
+}
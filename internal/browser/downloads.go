@@ -0,0 +1,67 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultDownloadDir is where downloads are saved when no directory is configured
+const DefaultDownloadDir = "./downloads"
+
+// Download runs trigger, which should start exactly one download (typically by clicking a
+// link), waits for it to complete and returns the path of the saved file
+func (m *Manager) Download(ctx context.Context, trigger func() error) (string, error) {
+	if m.browser == nil {
+		return "", fmt.Errorf("browser not initialized")
+	}
+
+	// Chrome requires an absolute download path
+	dir, err := filepath.Abs(m.downloadDir())
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve download directory: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	wait := m.browser.Context(ctx).WaitDownload(dir)
+	if err := trigger(); err != nil {
+		return "", fmt.Errorf("failed to start download: %w", err)
+	}
+
+	info := wait()
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("download did not complete: %w", err)
+	}
+	if info == nil {
+		return "", fmt.Errorf("no download was started")
+	}
+
+	// Downloads are saved under their GUID; give the file its suggested name
+	saved := filepath.Join(dir, info.GUID)
+	name := filepath.Base(info.SuggestedFilename)
+	if name == "." || name == string(filepath.Separator) || name == "" {
+		return saved, nil
+	}
+
+	target := filepath.Join(dir, name)
+	if _, err := os.Stat(target); err == nil {
+		ext := filepath.Ext(name)
+		target = filepath.Join(dir, fmt.Sprintf("%s-%s%s", name[:len(name)-len(ext)], time.Now().Format("20060102-150405"), ext))
+	}
+	if err := os.Rename(saved, target); err != nil {
+		return "", fmt.Errorf("failed to rename download: %w", err)
+	}
+
+	return target, nil
+}
+
+func (m *Manager) downloadDir() string {
+	if m.config.DownloadDir != "" {
+		return m.config.DownloadDir
+	}
+	return DefaultDownloadDir
+}
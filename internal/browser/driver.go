@@ -0,0 +1,239 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/input"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/ysmood/gson"
+)
+
+// PageDriver is what the search, connect and messaging workflows need from a page. NewPageDriver
+// wraps a Rod page; browsertest.Page is an in-memory fake for tests.
+type PageDriver interface {
+	Navigate(url string) error
+	WaitLoad() error
+	URL() (string, error)
+	Element(selector string) (ElementDriver, error) // Waits for a match like Rod's Page.Element
+	Elements(selector string) ([]ElementDriver, error)
+	Has(selector string) (bool, ElementDriver, error) // Like Element without waiting
+	Eval(js string, args ...interface{}) (gson.JSON, error)
+	MouseMoveTo(x, y float64) error
+	MouseScroll(x, y float64, steps int) error // Scrolls by x, y pixels from the mouse, in steps wheel events
+	Context(ctx context.Context) PageDriver
+}
+
+// ElementDriver is what the workflows need from an element of a PageDriver
+type ElementDriver interface {
+	Element(selector string) (ElementDriver, error)
+	Elements(selector string) ([]ElementDriver, error)
+	Parent() (ElementDriver, error)
+	Closest(selector string) (ElementDriver, error) // Nearest ancestor matching selector
+	Text() (string, error)
+	Attribute(name string) (*string, error)
+	Visible() (bool, error)
+	Center() (x, y float64, err error) // Middle of the element's visible area, for the mouse
+	Click() error
+	SelectAllText() error
+	Input(text string) error
+	Type(keys ...input.Key) error
+}
+
+// Container is a page or element whose descendants can be looked up
+type Container interface {
+	Elements(selector string) ([]ElementDriver, error)
+}
+
+// FirstElement returns the first element in container matching a candidate selector, tried in
+// order, without waiting for one
+func FirstElement(container Container, candidates []string) (ElementDriver, bool) {
+	for _, selector := range candidates {
+		if elements, err := container.Elements(selector); err == nil && len(elements) > 0 {
+			return elements[0], true
+		}
+	}
+	return nil, false
+}
+
+// FirstText returns the trimmed text of the first element in container matching a candidate
+// selector whose text is not blank, without waiting for one; empty when there is none
+func FirstText(container Container, candidates []string) string {
+	for _, selector := range candidates {
+		elements, err := container.Elements(selector)
+		if err != nil {
+			continue
+		}
+		for _, element := range elements {
+			if text, err := element.Text(); err == nil && strings.TrimSpace(text) != "" {
+				return strings.TrimSpace(text)
+			}
+		}
+	}
+	return ""
+}
+
+// rodPage drives a Rod page
+type rodPage struct {
+	page *rod.Page
+}
+
+// NewPageDriver adapts a Rod page for the workflows
+func NewPageDriver(page *rod.Page) PageDriver {
+	return &rodPage{page: page}
+}
+
+func (p *rodPage) Navigate(url string) error {
+	return p.page.Navigate(url)
+}
+
+func (p *rodPage) WaitLoad() error {
+	return p.page.WaitLoad()
+}
+
+func (p *rodPage) URL() (string, error) {
+	info, err := p.page.Info()
+	if err != nil {
+		return "", err
+	}
+	return info.URL, nil
+}
+
+func (p *rodPage) Element(selector string) (ElementDriver, error) {
+	element, err := p.page.Element(selector)
+	if err != nil {
+		return nil, err
+	}
+	return NewElementDriver(element), nil
+}
+
+func (p *rodPage) Elements(selector string) ([]ElementDriver, error) {
+	elements, err := p.page.Elements(selector)
+	if err != nil {
+		return nil, err
+	}
+	return wrapElements(elements), nil
+}
+
+func (p *rodPage) Has(selector string) (bool, ElementDriver, error) {
+	has, element, err := p.page.Has(selector)
+	if err != nil || !has {
+		return false, nil, err
+	}
+	return true, NewElementDriver(element), nil
+}
+
+func (p *rodPage) Eval(js string, args ...interface{}) (gson.JSON, error) {
+	result, err := p.page.Eval(js, args...)
+	if err != nil {
+		return gson.JSON{}, err
+	}
+	return result.Value, nil
+}
+
+func (p *rodPage) MouseMoveTo(x, y float64) error {
+	return p.page.Mouse.MoveTo(proto.Point{X: x, Y: y})
+}
+
+func (p *rodPage) MouseScroll(x, y float64, steps int) error {
+	return p.page.Mouse.Scroll(x, y, steps)
+}
+
+func (p *rodPage) Context(ctx context.Context) PageDriver {
+	return &rodPage{page: p.page.Context(ctx)}
+}
+
+// rodElement drives an element of a Rod page
+type rodElement struct {
+	element *rod.Element
+}
+
+// NewElementDriver adapts a Rod element for the workflows
+func NewElementDriver(element *rod.Element) ElementDriver {
+	return &rodElement{element: element}
+}
+
+func wrapElements(elements rod.Elements) []ElementDriver {
+	drivers := make([]ElementDriver, 0, len(elements))
+	for _, element := range elements {
+		drivers = append(drivers, NewElementDriver(element))
+	}
+	return drivers
+}
+
+func (e *rodElement) Element(selector string) (ElementDriver, error) {
+	element, err := e.element.Element(selector)
+	if err != nil {
+		return nil, err
+	}
+	return NewElementDriver(element), nil
+}
+
+func (e *rodElement) Elements(selector string) ([]ElementDriver, error) {
+	elements, err := e.element.Elements(selector)
+	if err != nil {
+		return nil, err
+	}
+	return wrapElements(elements), nil
+}
+
+func (e *rodElement) Parent() (ElementDriver, error) {
+	parent, err := e.element.Parent()
+	if err != nil {
+		return nil, err
+	}
+	return NewElementDriver(parent), nil
+}
+
+func (e *rodElement) Closest(selector string) (ElementDriver, error) {
+	parents, err := e.element.Parents(selector)
+	if err != nil {
+		return nil, err
+	}
+	if parents.Empty() {
+		return nil, fmt.Errorf("no ancestor matches %s", selector)
+	}
+	return NewElementDriver(parents.First()), nil
+}
+
+func (e *rodElement) Text() (string, error) {
+	return e.element.Text()
+}
+
+func (e *rodElement) Attribute(name string) (*string, error) {
+	return e.element.Attribute(name)
+}
+
+func (e *rodElement) Visible() (bool, error) {
+	return e.element.Visible()
+}
+
+func (e *rodElement) Center() (float64, float64, error) {
+	shape, err := e.element.Shape()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get element shape: %w", err)
+	}
+	if len(shape.Quads) == 0 {
+		return 0, 0, fmt.Errorf("element has no visible area")
+	}
+	quad := shape.Quads[0]
+	return (quad[0] + quad[2] + quad[4] + quad[6]) / 4, (quad[1] + quad[3] + quad[5] + quad[7]) / 4, nil
+}
+
+func (e *rodElement) Click() error {
+	return e.element.Click(proto.InputMouseButtonLeft, 1)
+}
+
+func (e *rodElement) SelectAllText() error {
+	return e.element.SelectAllText()
+}
+
+func (e *rodElement) Input(text string) error {
+	return e.element.Input(text)
+}
+
+func (e *rodElement) Type(keys ...input.Key) error {
+	return e.element.Type(keys...)
+}
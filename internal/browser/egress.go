@@ -0,0 +1,64 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"linkedin-automation-framework/internal/locale"
+)
+
+// EgressIP asks echoURL, an endpoint answering with the caller's IP as plain text such as
+// https://api.ipify.org, for the IP the browser's requests leave from. The request goes through
+// the browser, and so through its proxy, like LinkedIn's pages do.
+func (m *Manager) EgressIP(ctx context.Context, echoURL string, timeout time.Duration) (string, error) {
+	text, err := m.fetchText(ctx, echoURL, timeout)
+	if err != nil {
+		return "", err
+	}
+	ip := net.ParseIP(strings.TrimSpace(text))
+	if ip == nil {
+		return "", fmt.Errorf("%s did not answer with an IP address", echoURL)
+	}
+	return ip.String(), nil
+}
+
+// EgressGeo asks geoURL, an IP geolocation endpoint answering like https://ipapi.co/json/, where
+// the IP the browser's requests leave from is
+func (m *Manager) EgressGeo(ctx context.Context, geoURL string, timeout time.Duration) (locale.Geo, error) {
+	text, err := m.fetchText(ctx, geoURL, timeout)
+	if err != nil {
+		return locale.Geo{}, err
+	}
+	return locale.ParseGeo(text)
+}
+
+// fetchText opens rawURL in a page of its own and returns the text it shows
+func (m *Manager) fetchText(ctx context.Context, rawURL string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	page, err := m.OpenPage(ctx, "egress")
+	if err != nil {
+		return "", err
+	}
+	defer m.ClosePage(page)
+
+	page = page.Context(ctx)
+	if err := page.Navigate(rawURL); err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", rawURL, err)
+	}
+	if err := page.WaitLoad(); err != nil {
+		return "", fmt.Errorf("failed to load %s: %w", rawURL, err)
+	}
+	body, err := page.Element("body")
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", rawURL, err)
+	}
+	text, err := body.Text()
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", rawURL, err)
+	}
+	return text, nil
+}
@@ -0,0 +1,185 @@
+package browser
+
+import (
+	"fmt"
+	"strings"
+
+	"linkedin-automation-framework/internal/proxy"
+)
+
+// Headless modes supported by the browser manager
+const (
+	HeadlessModeNew = "new" // Chrome's --headless=new, which shares the full browser's rendering and fingerprint
+	HeadlessModeOld = "old" // Legacy headless shell, easier to fingerprint
+)
+
+// LaunchFlag is a Chrome command line switch without its leading dashes
+type LaunchFlag struct {
+	Name  string
+	Value string // Empty for boolean switches
+}
+
+// String formats the flag as it appears on the command line
+func (f LaunchFlag) String() string {
+	if f.Value == "" {
+		return "--" + f.Name
+	}
+	return "--" + f.Name + "=" + f.Value
+}
+
+// StealthFlags is the curated set of anti-automation launch flags applied unless disabled by name
+var StealthFlags = []LaunchFlag{
+	{Name: "disable-blink-features", Value: "AutomationControlled"}, // Keeps navigator.webdriver false
+	{Name: "disable-infobars"},                                      // No "controlled by automated software" bar
+	{Name: "no-default-browser-check"},
+	{Name: "password-store", Value: "basic"},
+	{Name: "lang", Value: "en-US"},
+}
+
+// automationFlags are launcher defaults that reveal automation and are removed alongside the stealth flags
+var automationFlags = []string{"enable-automation"}
+
+// managedFlags are set by the browser manager or launcher and cannot be passed as free-form flags
+var managedFlags = map[string]string{
+	"headless":                        "use browser.headless and browser.headless_mode",
+	"remote-debugging-port":           "the launcher picks a free port",
+	"remote-debugging-pipe":           "the launcher connects over a port",
+	"user-data-dir":                   "the launcher manages the profile directory",
+	"enable-automation":               "it exposes automation; remove it",
+	"proxy-server":                    "use proxy.pool",
+	"force-webrtc-ip-handling-policy": "use browser.webrtc",
+}
+
+// unsafeFlags turn off the browser's security checks, which would expose the logged-in session
+// to any page it opens, and cannot be passed as free-form flags
+var unsafeFlags = map[string]string{
+	"disable-web-security":                     "it turns off the same-origin policy",
+	"disable-site-isolation-trials":            "it lets sites share a renderer process",
+	"allow-running-insecure-content":           "it loads http scripts into https pages",
+	"ignore-certificate-errors":                "it accepts any TLS certificate",
+	"ignore-certificate-errors-spki-list":      "it accepts the listed TLS certificates unchecked",
+	"allow-insecure-localhost":                 "it accepts any TLS certificate on localhost",
+	"unsafely-treat-insecure-origin-as-secure": "it treats http origins as secure",
+	"reduce-security-for-testing":              "it weakens security checks for tests",
+}
+
+// LaunchPlan lists the launcher flags to set and the launcher defaults to remove
+type LaunchPlan struct {
+	Set    []LaunchFlag
+	Delete []string
+}
+
+// ParseLaunchFlag parses "--name=value" or "--name" into a LaunchFlag
+func ParseLaunchFlag(raw string) (LaunchFlag, error) {
+	trimmed := strings.TrimSpace(raw)
+	if !strings.HasPrefix(trimmed, "--") {
+		return LaunchFlag{}, fmt.Errorf("browser flag %q must start with --", raw)
+	}
+
+	name, value, _ := strings.Cut(strings.TrimPrefix(trimmed, "--"), "=")
+	if name == "" {
+		return LaunchFlag{}, fmt.Errorf("browser flag %q has no name", raw)
+	}
+	return LaunchFlag{Name: strings.ToLower(name), Value: value}, nil
+}
+
+// BuildLaunchPlan combines the headless mode, curated stealth flags and free-form flags,
+// rejecting flags the manager owns, flags that weaken security and flags that contradict each other
+func BuildLaunchPlan(config BrowserConfig) (LaunchPlan, error) {
+	var plan LaunchPlan
+
+	switch config.HeadlessMode {
+	case "", HeadlessModeNew, HeadlessModeOld:
+	default:
+		return plan, fmt.Errorf("unknown headless mode %q (expected %q or %q)", config.HeadlessMode, HeadlessModeNew, HeadlessModeOld)
+	}
+
+	disabled := make(map[string]bool)
+	for _, name := range config.DisabledStealthFlags {
+		name = strings.ToLower(strings.TrimPrefix(name, "--"))
+		if !isStealthFlag(name) {
+			return plan, fmt.Errorf("cannot disable unknown stealth flag %q", name)
+		}
+		disabled[name] = true
+	}
+
+	// Track where each flag came from so conflicts name both sources
+	chosen := make(map[string]LaunchFlag)
+	source := make(map[string]string)
+	add := func(flag LaunchFlag, origin string) error {
+		if existing, ok := chosen[flag.Name]; ok {
+			if existing.Value != flag.Value {
+				return fmt.Errorf("conflicting browser flags: %s (%s) and %s (%s)", existing, source[flag.Name], flag, origin)
+			}
+			return nil
+		}
+		chosen[flag.Name] = flag
+		source[flag.Name] = origin
+		plan.Set = append(plan.Set, flag)
+		return nil
+	}
+
+	for _, flag := range StealthFlags {
+		if disabled[flag.Name] {
+			continue
+		}
+		if err := add(flag, "stealth flags"); err != nil {
+			return plan, err
+		}
+	}
+
+	if config.UserAgent != "" {
+		if err := add(LaunchFlag{Name: "user-agent", Value: config.UserAgent}, "browser.user_agent"); err != nil {
+			return plan, err
+		}
+	}
+
+	if config.Proxy != "" {
+		endpoint, err := proxy.Parse(config.Proxy)
+		if err != nil {
+			return plan, err
+		}
+		if err := add(LaunchFlag{Name: "proxy-server", Value: endpoint.Server()}, "proxy.pool"); err != nil {
+			return plan, err
+		}
+	}
+
+	leaks, err := leakFlags(config)
+	if err != nil {
+		return plan, err
+	}
+	for _, flag := range leaks {
+		if err := add(flag, "browser.webrtc and browser.dns_over_https"); err != nil {
+			return plan, err
+		}
+	}
+
+	for _, raw := range config.Flags {
+		flag, err := ParseLaunchFlag(raw)
+		if err != nil {
+			return plan, err
+		}
+		if reason, managed := managedFlags[flag.Name]; managed {
+			return plan, fmt.Errorf("browser flag %s is not allowed: %s", flag, reason)
+		}
+		if reason, unsafe := unsafeFlags[flag.Name]; unsafe {
+			return plan, fmt.Errorf("browser flag %s is not allowed: %s", flag, reason)
+		}
+		if err := add(flag, "browser.flags"); err != nil {
+			return plan, err
+		}
+	}
+
+	plan.Delete = append(plan.Delete, automationFlags...)
+	return plan, nil
+}
+
+// isStealthFlag reports whether name belongs to the curated stealth set
+func isStealthFlag(name string) bool {
+	for _, flag := range StealthFlags {
+		if flag.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package browser
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/go-rod/rod"
+)
+
+// WebRTC IP handling policies. A page can ask a STUN server for the browser's IP over UDP, which
+// bypasses the proxy and reveals the real IP.
+const (
+	WebRTCDefault              = "default"                       // Chrome's default, which exposes local and public IPs
+	WebRTCPublicInterfaceOnly  = "default_public_interface_only" // Only the public IP of the default route, still the real one
+	WebRTCDisableNonProxiedUDP = "disable_non_proxied_udp"       // Only proxied traffic, so STUN sees the proxy's IP or nothing (default)
+	WebRTCDisabled             = "disabled"                      // As disable_non_proxied_udp, and pages get no RTCPeerConnection at all
+)
+
+// webRTCPolicies maps each policy to Chrome's --force-webrtc-ip-handling-policy value
+var webRTCPolicies = map[string]string{
+	WebRTCDefault:              "default",
+	WebRTCPublicInterfaceOnly:  "default_public_interface_only",
+	WebRTCDisableNonProxiedUDP: "disable_non_proxied_udp",
+	WebRTCDisabled:             "disable_non_proxied_udp",
+}
+
+// noWebRTC removes the WebRTC constructors before any script of the page runs
+const noWebRTC = `(() => {
+	for (const name of ['RTCPeerConnection', 'webkitRTCPeerConnection', 'RTCDataChannel', 'RTCIceCandidate', 'RTCSessionDescription']) {
+		try { delete window[name]; } catch (e) {}
+		Object.defineProperty(window, name, { value: undefined, configurable: true, writable: false });
+	}
+})()`
+
+// leakFlags returns the launch flags that keep WebRTC and DNS from going around the proxy
+func leakFlags(config BrowserConfig) ([]LaunchFlag, error) {
+	policy := config.WebRTC
+	if policy == "" {
+		policy = WebRTCDisableNonProxiedUDP
+	}
+	value, ok := webRTCPolicies[policy]
+	if !ok {
+		return nil, fmt.Errorf("unknown WebRTC policy %q", policy)
+	}
+	flags := []LaunchFlag{{Name: "force-webrtc-ip-handling-policy", Value: value}}
+
+	if config.DNSOverHTTPS != "" {
+		template, err := url.Parse(config.DNSOverHTTPS)
+		if err != nil || template.Scheme != "https" || template.Host == "" {
+			return nil, fmt.Errorf("DNS-over-HTTPS template must be an https URL, got %q", config.DNSOverHTTPS)
+		}
+		// Chrome takes a DoH server on its command line only as a forced field trial of the
+		// DnsOverHttps feature; Fallback false keeps it from falling back to the system resolver
+		flags = append(flags,
+			LaunchFlag{Name: "enable-features", Value: "DnsOverHttps<DoHTrial"},
+			LaunchFlag{Name: "force-fieldtrials", Value: "DoHTrial/Group1"},
+			LaunchFlag{Name: "force-fieldtrial-params", Value: "DoHTrial.Group1:Fallback/false/Templates/" + url.QueryEscape(config.DNSOverHTTPS)},
+		)
+	}
+	return flags, nil
+}
+
+// blockWebRTC removes WebRTC from page under the disabled policy
+func (m *Manager) blockWebRTC(page *rod.Page) error {
+	if m.config.WebRTC != WebRTCDisabled {
+		return nil
+	}
+	_, err := page.EvalOnNewDocument(noWebRTC)
+	return err
+}
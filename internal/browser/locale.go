@@ -0,0 +1,59 @@
+package browser
+
+import (
+	"fmt"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+
+	"linkedin-automation-framework/internal/locale"
+)
+
+// SetLocale changes the time zone, languages and geolocation pages claim, e.g. to match the
+// proxy's location. It applies to pages created from now on, across restarts.
+func (m *Manager) SetLocale(settings locale.Settings) {
+	m.config.Locale = settings
+}
+
+// emulateLocale applies the configured time zone, languages and geolocation to a new page
+func (m *Manager) emulateLocale(page *rod.Page) error {
+	settings := m.config.Locale
+	if settings.Timezone != "" {
+		if err := (proto.EmulationSetTimezoneOverride{TimezoneID: settings.Timezone}).Call(page); err != nil {
+			return fmt.Errorf("failed to set time zone: %w", err)
+		}
+	}
+	if settings.AcceptLanguage != "" {
+		if err := (proto.EmulationSetLocaleOverride{Locale: settings.Locale()}).Call(page); err != nil {
+			return fmt.Errorf("failed to set locale: %w", err)
+		}
+		// The user agent override carries the languages, so it keeps the user agent pages have
+		device := m.Device()
+		userAgent := device.UserAgent
+		if userAgent == "" {
+			version, err := proto.BrowserGetVersion{}.Call(page)
+			if err != nil {
+				return fmt.Errorf("failed to get user agent: %w", err)
+			}
+			userAgent = version.UserAgent
+		}
+		override := &proto.NetworkSetUserAgentOverride{UserAgent: userAgent, AcceptLanguage: settings.AcceptLanguage}
+		if device.Mobile {
+			override.Platform = device.Platform
+		}
+		if err := page.SetUserAgent(override); err != nil {
+			return fmt.Errorf("failed to set languages: %w", err)
+		}
+	}
+	if position := settings.Geolocation; position != nil {
+		err := proto.EmulationSetGeolocationOverride{
+			Latitude:  &position.Latitude,
+			Longitude: &position.Longitude,
+			Accuracy:  &position.Accuracy,
+		}.Call(page)
+		if err != nil {
+			return fmt.Errorf("failed to set geolocation: %w", err)
+		}
+	}
+	return nil
+}
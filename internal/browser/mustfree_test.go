@@ -0,0 +1,72 @@
+package browser
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// mustAllowed are the Must functions that only panic on a programming error, at start-up
+var mustAllowed = map[string]bool{
+	"regexp.MustCompile": true,
+	"template.Must":      true,
+}
+
+// TestNoMustCalls fails on any call of a Must* method outside tests, since Rod's Must methods
+// panic on any error; see safe.go
+func TestNoMustCalls(t *testing.T) {
+	root := filepath.Join("..", "..")
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if name := entry.Name(); path != root && (strings.HasPrefix(name, ".") || name == "testdata" || name == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			selector, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || !isMust(selector.Sel.Name) {
+				return true
+			}
+			if receiver, ok := selector.X.(*ast.Ident); ok && mustAllowed[receiver.Name+"."+selector.Sel.Name] {
+				return true
+			}
+			t.Errorf("%s: %s panics on error, use the error-returning method or a browser wrapper", fset.Position(call.Pos()), selector.Sel.Name)
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to scan the sources: %v", err)
+	}
+}
+
+// isMust reports whether name is a Must function, like MustClick or Must
+func isMust(name string) bool {
+	rest, ok := strings.CutPrefix(name, "Must")
+	if !ok {
+		return false
+	}
+	return rest == "" || unicode.IsUpper([]rune(rest)[0])
+}
@@ -0,0 +1,97 @@
+package browser
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// ErrNotSettled is returned when a page loaded but kept changing until the settle timeout. The
+// page is usable, so callers may log it and go on.
+var ErrNotSettled = stderrors.New("page loaded but did not settle")
+
+// SettleCondition is what a loaded page waits for before it counts as settled
+type SettleCondition string
+
+const (
+	SettleDOMStable   SettleCondition = "dom_stable"   // The DOM stopped changing
+	SettleNetworkIdle SettleCondition = "network_idle" // No requests are in flight, besides sockets and media
+)
+
+// Settle defaults
+const (
+	DefaultSettleQuiet   = 500 * time.Millisecond
+	DefaultSettleTimeout = 10 * time.Second
+)
+
+// SettleOptions say how long to wait, and for what, once a page has loaded
+type SettleOptions struct {
+	Until   SettleCondition // SettleDOMStable unless set
+	Quiet   time.Duration   // How long the DOM or the network must stay quiet, DefaultSettleQuiet unless set
+	Timeout time.Duration   // Bounds the wait after the load, DefaultSettleTimeout unless set
+}
+
+// withDefaults fills in the unset options
+func (o SettleOptions) withDefaults() SettleOptions {
+	if o.Until == "" {
+		o.Until = SettleDOMStable
+	}
+	if o.Quiet <= 0 {
+		o.Quiet = DefaultSettleQuiet
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultSettleTimeout
+	}
+	return o
+}
+
+// Navigate opens url in page, waits up to loadTimeout for it to load, then up to settle.Timeout
+// for it to settle. It replaces a load wait followed by a fixed sleep: it returns as soon as the
+// page is quiet, and waits longer than a sleep would while LinkedIn is slow.
+func Navigate(ctx context.Context, page *rod.Page, url string, loadTimeout time.Duration, settle SettleOptions) error {
+	if page == nil {
+		return fmt.Errorf("page cannot be nil")
+	}
+	loading := page.Context(ctx).Timeout(loadTimeout)
+	defer loading.CancelTimeout()
+	if err := loading.Navigate(url); err != nil {
+		return fmt.Errorf("failed to navigate to %s: %w", url, err)
+	}
+	return WaitSettled(ctx, page, loadTimeout, settle)
+}
+
+// WaitSettled waits up to loadTimeout for the page to load, then up to settle.Timeout for it to
+// settle. ErrNotSettled means the page loaded but never went quiet.
+func WaitSettled(ctx context.Context, page *rod.Page, loadTimeout time.Duration, settle SettleOptions) error {
+	if page == nil {
+		return fmt.Errorf("page cannot be nil")
+	}
+	settle = settle.withDefaults()
+
+	loading := page.Context(ctx).Timeout(loadTimeout)
+	defer loading.CancelTimeout()
+	if err := loading.WaitLoad(); err != nil {
+		return fmt.Errorf("failed to wait for the page to load: %w", err)
+	}
+
+	settling := page.Context(ctx).Timeout(settle.Timeout)
+	defer settling.CancelTimeout()
+	var err error
+	switch settle.Until {
+	case SettleNetworkIdle:
+		// Returns once the network is idle or the timeout passes, telling the two apart only by the context
+		settling.WaitRequestIdle(settle.Quiet, nil, nil, nil)()
+		err = settling.GetContext().Err()
+	case SettleDOMStable:
+		err = settling.WaitDOMStable(settle.Quiet, 0)
+	default:
+		return fmt.Errorf("unknown settle condition: %s", settle.Until)
+	}
+	if err != nil && ctx.Err() == nil && stderrors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w within %s (%s)", ErrNotSettled, settle.Timeout, settle.Until)
+	}
+	return err
+}
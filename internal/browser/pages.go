@@ -0,0 +1,237 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// PageInfo describes a page the manager opened that has not been closed yet
+type PageInfo struct {
+	ID       string    `json:"id"`
+	Label    string    `json:"label,omitempty"` // What the page was opened for, empty for pages from NewPage
+	OpenedAt time.Time `json:"opened_at"`
+}
+
+// trackedPage is a registry entry
+type trackedPage struct {
+	info    PageInfo
+	close   func() error
+	closing func()      // Called before the page is closed or forgotten; may be nil
+	stop    func() bool // Cancels the close on context cancellation
+}
+
+// pageRegistry tracks the pages a manager opened, so that a page whose work has ended is closed
+// even when an error path forgets to and long sessions do not pile up pages
+type pageRegistry struct {
+	pages map[string]*trackedPage
+	mutex sync.Mutex
+}
+
+// add tracks a page, closing it once ctx ends. closing, if not nil, is called once the page is
+// about to be closed or has been closed elsewhere.
+func (r *pageRegistry) add(ctx context.Context, info PageInfo, close func() error, closing func()) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.pages == nil {
+		r.pages = make(map[string]*trackedPage)
+	}
+	entry := &trackedPage{info: info, close: close, closing: closing}
+	entry.stop = context.AfterFunc(ctx, func() {
+		_ = r.close(info.ID)
+	})
+	r.pages[info.ID] = entry
+}
+
+// close closes a tracked page and stops tracking it; untracked pages are left alone
+func (r *pageRegistry) close(id string) error {
+	r.mutex.Lock()
+	entry, ok := r.pages[id]
+	delete(r.pages, id)
+	r.mutex.Unlock()
+
+	if !ok {
+		return nil
+	}
+	entry.stop()
+	if entry.closing != nil {
+		entry.closing()
+	}
+	return entry.close()
+}
+
+// list returns the tracked pages, oldest first, after forgetting those open reports closed elsewhere
+func (r *pageRegistry) list(open func(id string) bool) []PageInfo {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	pages := make([]PageInfo, 0, len(r.pages))
+	for id, entry := range r.pages {
+		if open != nil && !open(id) {
+			entry.stop()
+			delete(r.pages, id)
+			if entry.closing != nil {
+				go entry.closing() // Not under the mutex, it may take a while
+			}
+			continue
+		}
+		pages = append(pages, entry.info)
+	}
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].OpenedAt.Before(pages[j].OpenedAt)
+	})
+	return pages
+}
+
+// closeAll closes every tracked page
+func (r *pageRegistry) closeAll() {
+	r.mutex.Lock()
+	ids := make([]string, 0, len(r.pages))
+	for id := range r.pages {
+		ids = append(ids, id)
+	}
+	r.mutex.Unlock()
+
+	for _, id := range ids {
+		_ = r.close(id) // Pages of a closing browser may already be gone
+	}
+}
+
+// OpenPage creates a page like NewPage that is closed automatically once ctx ends. label says
+// what the page is for in Pages.
+func (m *Manager) OpenPage(ctx context.Context, label string) (*rod.Page, error) {
+	page, err := m.newPage()
+	if err != nil {
+		return nil, err
+	}
+	m.track(ctx, page, label)
+	return page, nil
+}
+
+// ClosePage closes a page and stops tracking it
+func (m *Manager) ClosePage(page *rod.Page) error {
+	if page == nil {
+		return nil
+	}
+	return m.pages.close(string(page.TargetID))
+}
+
+// Pages lists the pages opened through the manager that are still open, oldest first
+func (m *Manager) Pages() []PageInfo {
+	var open func(id string) bool
+	if m.browser != nil {
+		if targets, err := m.browser.Pages(); err == nil {
+			ids := make(map[string]bool, len(targets))
+			for _, target := range targets {
+				ids[string(target.TargetID)] = true
+			}
+			open = func(id string) bool { return ids[id] }
+		}
+	}
+	return m.pages.list(open)
+}
+
+// PageHook is called with every page the manager creates. The function it returns, if any, is
+// called just before the page is closed, or once it was found closed elsewhere.
+type PageHook func(page *rod.Page, label string) (closing func())
+
+// SetPageHook calls hook for every page the manager creates from now on; nil calls none
+func (m *Manager) SetPageHook(hook PageHook) {
+	m.pageHook = hook
+}
+
+// track registers a new page, closing it once ctx ends
+func (m *Manager) track(ctx context.Context, page *rod.Page, label string) {
+	var closing func()
+	if m.pageHook != nil {
+		closing = m.pageHook(page, label)
+	}
+	m.pages.add(ctx, PageInfo{ID: string(page.TargetID), Label: label, OpenedAt: time.Now()}, page.Close, closing)
+}
+
+// LaunchedAt returns when the running browser was launched, zero before Initialize
+func (m *Manager) LaunchedAt() time.Time {
+	return m.launchedAt
+}
+
+// Restart relaunches the browser with the session it had, giving back the memory a browser
+// gathers over a long session. The cookies are saved to cookiePath first and restored from it
+// after the relaunch. Every page is closed, so callers restart between pieces of work and open
+// new pages afterwards. A browser a failed restart left closed is launched again.
+func (m *Manager) Restart(ctx context.Context, cookiePath string) error {
+	if cookiePath == "" {
+		return fmt.Errorf("restarting the browser needs a cookie path to carry the session over")
+	}
+	if m.browser != nil {
+		if err := m.SaveCookies(cookiePath); err != nil {
+			return fmt.Errorf("failed to save session before restart: %w", err)
+		}
+		if err := m.Close(); err != nil {
+			return fmt.Errorf("failed to close browser for restart: %w", err)
+		}
+	}
+	if err := m.Initialize(ctx); err != nil {
+		return fmt.Errorf("failed to relaunch browser: %w", err)
+	}
+	if err := m.LoadCookies(cookiePath); err != nil {
+		return fmt.Errorf("failed to restore session after restart: %w", err)
+	}
+	return nil
+}
+
+// MemoryStats is a snapshot of the memory a run holds
+type MemoryStats struct {
+	HeapBytes   uint64        `json:"heap_bytes"` // Go heap in use
+	SysBytes    uint64        `json:"sys_bytes"`  // Memory the Go runtime holds from the OS
+	Goroutines  int           `json:"goroutines"`
+	Pages       int           `json:"pages"`         // Pages open through the manager
+	JSHeapBytes uint64        `json:"js_heap_bytes"` // JavaScript heap in use across those pages
+	BrowserAge  time.Duration `json:"browser_age"`   // Time since the browser was launched
+	SampledAt   time.Time     `json:"sampled_at"`
+}
+
+// Memory reports the process's and the browser's memory use. The JavaScript heap of a page that
+// does not answer within a second is left out.
+func (m *Manager) Memory() MemoryStats {
+	var runtimeStats runtime.MemStats
+	runtime.ReadMemStats(&runtimeStats)
+	stats := MemoryStats{
+		HeapBytes:  runtimeStats.HeapAlloc,
+		SysBytes:   runtimeStats.Sys,
+		Goroutines: runtime.NumGoroutine(),
+		SampledAt:  time.Now(),
+	}
+
+	pages := m.Pages()
+	stats.Pages = len(pages)
+	if m.browser == nil {
+		return stats
+	}
+	stats.BrowserAge = stats.SampledAt.Sub(m.launchedAt)
+
+	tracked := make(map[string]bool, len(pages))
+	for _, page := range pages {
+		tracked[page.ID] = true
+	}
+	targets, err := m.browser.Pages()
+	if err != nil {
+		return stats
+	}
+	for _, target := range targets {
+		if !tracked[string(target.TargetID)] {
+			continue
+		}
+		usage, err := proto.RuntimeGetHeapUsage{}.Call(target.Timeout(time.Second))
+		if err == nil {
+			stats.JSHeapBytes += uint64(usage.UsedSize)
+		}
+	}
+	return stats
+}
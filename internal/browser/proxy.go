@@ -0,0 +1,57 @@
+package browser
+
+import (
+	"github.com/go-rod/rod/lib/proto"
+
+	"linkedin-automation-framework/internal/proxy"
+)
+
+// SetProxy routes the browser through another proxy, e.g. after the current one died. It takes
+// effect on the next Initialize or Restart.
+func (m *Manager) SetProxy(raw string) {
+	m.config.Proxy = raw
+}
+
+// Proxy returns the proxy the browser goes through, without its password; empty for none
+func (m *Manager) Proxy() string {
+	if m.config.Proxy == "" {
+		return ""
+	}
+	return proxy.Redact(m.config.Proxy)
+}
+
+// handleProxyAuth answers the proxy's authentication challenges with the credentials of the
+// proxy URL for as long as the browser runs. Chrome takes no credentials on its command line.
+func (m *Manager) handleProxyAuth() error {
+	if m.config.Proxy == "" {
+		return nil
+	}
+	endpoint, err := proxy.Parse(m.config.Proxy)
+	if err != nil {
+		return err
+	}
+	username, password, ok := endpoint.Credentials()
+	if !ok {
+		return nil
+	}
+
+	browser := m.browser
+	if err := (proto.FetchEnable{HandleAuthRequests: true}).Call(browser); err != nil {
+		return err
+	}
+	go browser.EachEvent(func(e *proto.FetchRequestPaused) {
+		_ = proto.FetchContinueRequest{RequestID: e.RequestID}.Call(browser)
+	}, func(e *proto.FetchAuthRequired) {
+		response := &proto.FetchAuthChallengeResponse{
+			Response: proto.FetchAuthChallengeResponseResponseProvideCredentials,
+			Username: username,
+			Password: password,
+		}
+		if e.AuthChallenge == nil || e.AuthChallenge.Source != proto.FetchAuthChallengeSourceProxy {
+			// Only the proxy gets the credentials; sites asking for a login are turned away
+			response = &proto.FetchAuthChallengeResponse{Response: proto.FetchAuthChallengeResponseResponseCancelAuth}
+		}
+		_ = proto.FetchContinueWithAuth{RequestID: e.RequestID, AuthChallengeResponse: response}.Call(browser)
+	})()
+	return nil
+}
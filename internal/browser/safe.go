@@ -0,0 +1,46 @@
+package browser
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+
+	"linkedin-automation-framework/internal/errors"
+)
+
+// Rod's Must* methods panic on any error, which kills a campaign halfway through a lead. Code in
+// this repository calls the error-returning methods instead, or these wrappers, which also turn
+// a panic on the way into an error; TestNoMustCalls enforces it.
+
+// WaitLoad waits up to timeout for page to load, where MustWaitLoad would panic
+func WaitLoad(ctx context.Context, page *rod.Page, timeout time.Duration) error {
+	if page == nil {
+		return errors.NewError(errors.ErrorTypeConfiguration, "wait_load", "page cannot be nil", nil)
+	}
+	return safely("wait_load", func() error {
+		return page.Context(ctx).Timeout(timeout).WaitLoad()
+	})
+}
+
+// Click clicks element once with the left button, waiting up to timeout for it to become
+// clickable, where MustClick would panic
+func Click(ctx context.Context, element *rod.Element, timeout time.Duration) error {
+	if element == nil {
+		return errors.NewError(errors.ErrorTypeConfiguration, "click", "element cannot be nil", nil)
+	}
+	return safely("click", func() error {
+		return element.Context(ctx).Timeout(timeout).Click(proto.InputMouseButtonLeft, 1)
+	})
+}
+
+// safely runs a Rod operation, classifying its error and recovering from a panic
+func safely(operation string, fn func() error) error {
+	return errors.NewGracefulErrorRecovery(nil).SafeExecute(operation, func() error {
+		if err := fn(); err != nil {
+			return errors.NewRodErrorHandler(0).HandleRodError(operation, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,415 @@
+package campaign
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Campaign describes an outreach workflow as an ordered list of steps
+type Campaign struct {
+	Name        string            `yaml:"name"`
+	Target      int               `yaml:"target"` // Leads the campaign should reach in total, which campaign plan forecasts for
+	Limits      RateConfig        `yaml:"limits"`
+	Searches    SearchConfig      `yaml:"searches"`
+	Invites     InviteConfig      `yaml:"invites"`
+	Approval    bool              `yaml:"approval"` // Hold every invite and message for a reviewer before it is sent
+	Uniqueness  UniquenessConfig  `yaml:"uniqueness"`
+	Priority    PriorityConfig    `yaml:"priority"` // How the lead pool is ordered before the limits apply
+	Concurrency ConcurrencyConfig `yaml:"concurrency"`
+	Steps       []StepConfig      `yaml:"steps"`
+}
+
+// SearchConfig lists the searches that fill the lead pool before the steps run; without
+// queries or a network search the campaign works through the stored search results instead
+type SearchConfig struct {
+	Concurrency int            `yaml:"concurrency"` // Queries run at once, each on its own page; 0 or 1 runs them in sequence
+	Queries     []SearchQuery  `yaml:"queries"`
+	Network     *NetworkSearch `yaml:"network"` // Takes leads from the account's own connections instead of searching
+}
+
+// SearchQuery is one search feeding the lead pool
+type SearchQuery struct {
+	Name       string `yaml:"name"`        // Label for logs; names a saved search when Query is empty
+	Query      string `yaml:"query"`       // People search URL or keywords
+	MaxResults int    `yaml:"max_results"` // Profiles read, defaults to the saved search's setting or 100
+}
+
+// RateConfig bounds how quickly a campaign processes leads
+type RateConfig struct {
+	LeadsPerHour int `yaml:"leads_per_hour"` // 0 means unthrottled
+	DailyCap     int `yaml:"daily_cap"`      // Maximum leads per run, 0 means unlimited
+	WeeklyCap    int `yaml:"weekly_cap"`     // Maximum leads per week, Monday to Sunday, 0 means unlimited
+}
+
+// ConcurrencyConfig bounds how much of a campaign runs at once
+type ConcurrencyConfig struct {
+	MaxPages   int `yaml:"max_pages"`   // Browser pages open at once, search pages included; 0 leaves searches.concurrency alone
+	Workers    int `yaml:"workers"`     // Leads run at once; 0 or 1 runs them one at a time
+	QueueDepth int `yaml:"queue_depth"` // Leads handed out ahead of a free worker; 0 hands a lead over only when a worker takes it
+}
+
+// SearchConcurrency returns how many queries may run at once next to pagesInUse pages the
+// campaign already holds, at least one
+func (c ConcurrencyConfig) SearchConcurrency(requested, pagesInUse int) int {
+	if c.MaxPages > 0 && requested > c.MaxPages-pagesInUse {
+		requested = c.MaxPages - pagesInUse
+	}
+	if requested < 1 {
+		return 1
+	}
+	return requested
+}
+
+// StepConfig describes a single campaign step as declared in the campaign YAML
+type StepConfig struct {
+	ID      string            `yaml:"id"`
+	Type    string            `yaml:"type"`
+	Next    string            `yaml:"next"`    // Optional ID of the step to run after this one
+	Command string            `yaml:"command"` // Executable for plugin steps
+	Args    []string          `yaml:"args"`
+	Timeout time.Duration     `yaml:"timeout"`
+	Params  map[string]string `yaml:"params"`
+
+	Template      string            `yaml:"template"`       // Optional text/template rendered against the lead
+	Templates     []string          `yaml:"templates"`      // Variants for steps that send messages; one is picked per lead
+	Localized     map[string]string `yaml:"localized"`      // Templates by language code, used instead of template for leads in that language
+	TemplateFiles map[string]string `yaml:"template_files"` // Language code to template file, relative to the campaign file; read into Localized
+	Subject       string            `yaml:"subject"`        // Message subject template for steps that send messages
+	Limits        RateConfig        `yaml:"limits"`         // The step's own send limits, on top of the campaign's
+	Outputs       []string          `yaml:"outputs"`        // Lead attributes this step sets, available to later templates
+	State         string            `yaml:"state"`          // State the lead enters when the step continues
+	Workers       int               `yaml:"workers"`        // Leads in this step at once; 0 leaves it to the campaign's workers
+}
+
+// Lead represents a prospect flowing through a campaign
+type Lead struct {
+	ProfileURL string            `json:"profile_url"`
+	Name       string            `json:"name"`
+	Title      string            `json:"title"`
+	Company    string            `json:"company"`
+	Location   string            `json:"location"`
+	State      string            `json:"state"`
+	Mutual     int               `json:"mutual,omitempty"` // Mutual connections with the account
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// QueuePrefix starts the name of the durable queue a campaign's leads run through, followed by
+// the campaign's name
+const QueuePrefix = "campaign:"
+
+// QueueName names the durable queue the named campaign's leads run through
+func QueueName(campaignName string) string {
+	return QueuePrefix + campaignName
+}
+
+// Outcome tells the runner what to do with a lead after a step
+type Outcome string
+
+const (
+	OutcomeContinue Outcome = "continue" // Proceed to the next step
+	OutcomeSkip     Outcome = "skip"     // Stop processing this lead without error
+)
+
+// StepResult is returned by every step execution
+type StepResult struct {
+	Outcome    Outcome           `json:"outcome"`
+	Reason     string            `json:"reason,omitempty"`
+	Message    string            `json:"message,omitempty"` // Text produced by the step, such as a rendered template
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Step is a single unit of work executed for a lead
+type Step interface {
+	Run(ctx context.Context, lead *Lead) (StepResult, error)
+}
+
+// StepFunc adapts a plain function to the Step interface
+type StepFunc func(ctx context.Context, lead *Lead) (StepResult, error)
+
+// Run calls f(ctx, lead)
+func (f StepFunc) Run(ctx context.Context, lead *Lead) (StepResult, error) {
+	return f(ctx, lead)
+}
+
+// StepFactory builds a Step from its YAML configuration
+type StepFactory func(config StepConfig) (Step, error)
+
+// Registry maps step types to their factories
+type Registry struct {
+	factories map[string]StepFactory
+	mutex     sync.RWMutex
+}
+
+// NewRegistry creates a registry with the built-in step types registered
+func NewRegistry() *Registry {
+	r := &Registry{
+		factories: make(map[string]StepFactory),
+	}
+	r.Register(StepTypePlugin, NewPluginStep)
+	return r
+}
+
+// Register adds or replaces the factory for a step type
+func (r *Registry) Register(stepType string, factory StepFactory) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.factories[stepType] = factory
+}
+
+// Has reports whether a factory is registered for the step type
+func (r *Registry) Has(stepType string) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	_, ok := r.factories[stepType]
+	return ok
+}
+
+// Build creates a Step for the given configuration
+func (r *Registry) Build(config StepConfig) (Step, error) {
+	r.mutex.RLock()
+	factory, ok := r.factories[config.Type]
+	r.mutex.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown step type %q for step %q", config.Type, config.ID)
+	}
+
+	step, err := factory(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build step %q: %w", config.ID, err)
+	}
+	return step, nil
+}
+
+// Load reads a campaign definition from a YAML file
+func Load(path string) (*Campaign, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read campaign file: %w", err)
+	}
+
+	campaign := &Campaign{}
+	if err := yaml.Unmarshal(data, campaign); err != nil {
+		return nil, fmt.Errorf("failed to parse campaign YAML: %w", err)
+	}
+
+	// Steps without an explicit ID are addressed by their position
+	for i := range campaign.Steps {
+		if campaign.Steps[i].ID == "" {
+			campaign.Steps[i].ID = fmt.Sprintf("step-%d", i+1)
+		}
+		if err := loadTemplateFiles(&campaign.Steps[i], filepath.Dir(path)); err != nil {
+			return nil, err
+		}
+		for _, text := range stepTemplates(campaign.Steps[i]) {
+			if _, err := parseTemplate(campaign.Steps[i].ID, text); err != nil {
+				return nil, fmt.Errorf("step %q: %w", campaign.Steps[i].ID, err)
+			}
+		}
+	}
+
+	return campaign, nil
+}
+
+// StepRecord captures the result of one step for one lead
+type StepRecord struct {
+	StepID string
+	Result StepResult
+	Err    error
+}
+
+// Runner executes a campaign's steps for individual leads. RunLead may be called for several
+// leads at once: each step then takes at most its workers' worth of leads, and the steps that
+// drive the browser share one page, so they take one lead at a time between them.
+type Runner struct {
+	campaign *Campaign
+	steps    map[string]Step
+	index    map[string]int
+	slots    map[string]chan struct{} // Free workers of the steps with a limit
+	observer StepObserver
+}
+
+// StepObserver is told about each step a lead ran while the step still holds its worker, so it
+// may use the step's page, and before the lead takes the step's attributes and state
+type StepObserver func(ctx context.Context, lead *Lead, config StepConfig, result StepResult, err error)
+
+// pageStepTypes are the step types that drive the campaign's shared browser page
+var pageStepTypes = map[string]bool{StepTypeInvite: true, StepTypeMessage: true, StepTypeOpenProfile: true}
+
+// UsesPage reports whether steps of the type drive the campaign's shared browser page
+func UsesPage(stepType string) bool {
+	return pageStepTypes[stepType]
+}
+
+// NewRunner builds every step of the campaign using the registry
+func NewRunner(campaign *Campaign, registry *Registry) (*Runner, error) {
+	if campaign == nil {
+		return nil, fmt.Errorf("campaign cannot be nil")
+	}
+	if registry == nil {
+		return nil, fmt.Errorf("registry cannot be nil")
+	}
+
+	r := &Runner{
+		campaign: campaign,
+		steps:    make(map[string]Step),
+		index:    make(map[string]int),
+		slots:    make(map[string]chan struct{}),
+	}
+
+	page := make(chan struct{}, 1)
+	for i, config := range campaign.Steps {
+		if _, exists := r.steps[config.ID]; exists {
+			return nil, fmt.Errorf("duplicate step id %q", config.ID)
+		}
+		step, err := registry.Build(config)
+		if err != nil {
+			return nil, err
+		}
+		r.steps[config.ID] = step
+		r.index[config.ID] = i
+		switch {
+		case pageStepTypes[config.Type]:
+			r.slots[config.ID] = page
+		case config.Workers > 0:
+			r.slots[config.ID] = make(chan struct{}, config.Workers)
+		}
+	}
+
+	for _, config := range campaign.Steps {
+		if config.Next != "" {
+			if _, ok := r.steps[config.Next]; !ok {
+				return nil, fmt.Errorf("step %q references unknown next step %q", config.ID, config.Next)
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// RunLead walks the lead through the campaign until it is skipped, fails or runs out of steps
+func (r *Runner) RunLead(ctx context.Context, lead *Lead) ([]StepRecord, error) {
+	if lead == nil {
+		return nil, fmt.Errorf("lead cannot be nil")
+	}
+	if len(r.campaign.Steps) == 0 {
+		return nil, nil
+	}
+
+	var records []StepRecord
+	current := r.campaign.Steps[0].ID
+
+	// Bound the walk so that cyclic "next" references cannot loop forever
+	maxSteps := len(r.campaign.Steps) * 2
+	for i := 0; current != "" && i < maxSteps; i++ {
+		if err := ctx.Err(); err != nil {
+			return records, err
+		}
+
+		result, err := r.runStep(ctx, current, lead)
+		records = append(records, StepRecord{StepID: current, Result: result, Err: err})
+		if err != nil {
+			return records, fmt.Errorf("step %q failed: %w", current, err)
+		}
+
+		if len(result.Attributes) > 0 {
+			if lead.Attributes == nil {
+				lead.Attributes = make(map[string]string)
+			}
+			for k, v := range result.Attributes {
+				lead.Attributes[k] = v
+			}
+		}
+
+		if result.Outcome == OutcomeSkip {
+			break
+		}
+
+		if state := r.campaign.Steps[r.index[current]].State; state != "" {
+			lead.State = state
+		}
+
+		current = r.nextStep(current)
+	}
+
+	return records, nil
+}
+
+// runStep runs one step for the lead once the step has a free worker
+func (r *Runner) runStep(ctx context.Context, stepID string, lead *Lead) (StepResult, error) {
+	if slots := r.slots[stepID]; slots != nil {
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+		case <-ctx.Done():
+			return StepResult{}, ctx.Err()
+		}
+	}
+	ctx = context.WithValue(ctx, stepIDKey{}, stepID)
+	result, err := r.steps[stepID].Run(ctx, lead)
+	if r.observer != nil {
+		r.observer(ctx, lead, r.campaign.Steps[r.index[stepID]], result, err)
+	}
+	return result, err
+}
+
+// Observe sets the observer told about every step run; set it before running leads
+func (r *Runner) Observe(observer StepObserver) {
+	r.observer = observer
+}
+
+// stepIDKey carries the running step's ID in its context
+type stepIDKey struct{}
+
+// StepID returns the ID of the step running with ctx, so what a step sends through can tell
+// its steps apart; "" outside a step
+func StepID(ctx context.Context) string {
+	id, _ := ctx.Value(stepIDKey{}).(string)
+	return id
+}
+
+// templateIDKey carries the ID of the template the running step sends in its context
+type templateIDKey struct{}
+
+// withTemplate returns ctx carrying the ID of the template text, in the language code when
+// localized, that the running step renders what it sends from
+func withTemplate(ctx context.Context, text, code string) context.Context {
+	hash := fnv.New32a()
+	hash.Write([]byte(text))
+	id := StepID(ctx)
+	if code != "" {
+		id += "@" + code
+	}
+	return context.WithValue(ctx, templateIDKey{}, fmt.Sprintf("%s#%08x", id, hash.Sum32()))
+}
+
+// TemplateID returns the ID of the template the step running with ctx renders what it sends
+// from, e.g. "welcome@de#1f3a9c02": the step, the language of a localized template and a hash
+// of the unrendered text, so each variant and every edit of a template has a history of its
+// own; "" outside a step that sends a template
+func TemplateID(ctx context.Context) string {
+	id, _ := ctx.Value(templateIDKey{}).(string)
+	return id
+}
+
+// nextStep resolves the step that follows the given one
+func (r *Runner) nextStep(stepID string) string {
+	config := r.campaign.Steps[r.index[stepID]]
+	if config.Next != "" {
+		return config.Next
+	}
+	position := r.index[stepID] + 1
+	if position >= len(r.campaign.Steps) {
+		return ""
+	}
+	return r.campaign.Steps[position].ID
+}
@@ -0,0 +1,1065 @@
+package campaign
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pgregory.net/rapid"
+)
+
+// TestHelperPlugin is not a real test; it acts as an external plugin process
+// when the plugin step re-executes the test binary.
+func TestHelperPlugin(t *testing.T) {
+	if os.Getenv("CAMPAIGN_HELPER_PLUGIN") != "1" {
+		return
+	}
+
+	var request PluginRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+		fmt.Fprintf(os.Stderr, "bad request: %v", err)
+		os.Exit(2)
+	}
+
+	response := PluginResponse{Outcome: OutcomeContinue}
+	if strings.Contains(request.Lead.Company, "competitor") {
+		response.Outcome = OutcomeSkip
+		response.Reason = "company is a competitor"
+	}
+	response.Attributes = map[string]string{
+		"crm_checked": request.Params["crm"],
+		"seen_name":   request.Lead.Name,
+	}
+
+	json.NewEncoder(os.Stdout).Encode(response)
+	os.Exit(0)
+}
+
+func helperPluginConfig(id string) StepConfig {
+	return StepConfig{
+		ID:      id,
+		Type:    StepTypePlugin,
+		Command: os.Args[0],
+		Args:    []string{"-test.run=TestHelperPlugin"},
+		Params:  map[string]string{"crm": "hubspot"},
+	}
+}
+
+// TestPluginStepProtocol tests that plugin steps exchange leads and results over JSON
+func TestPluginStepProtocol(t *testing.T) {
+	t.Setenv("CAMPAIGN_HELPER_PLUGIN", "1")
+
+	step, err := NewRegistry().Build(helperPluginConfig("crm-check"))
+	if err != nil {
+		t.Fatalf("failed to build plugin step: %v", err)
+	}
+
+	lead := &Lead{Name: "Jane Doe", Company: "Acme"}
+	result, err := step.Run(context.Background(), lead)
+	if err != nil {
+		t.Fatalf("plugin run failed: %v", err)
+	}
+	if result.Outcome != OutcomeContinue {
+		t.Errorf("expected continue outcome, got %q", result.Outcome)
+	}
+	if result.Attributes["crm_checked"] != "hubspot" || result.Attributes["seen_name"] != "Jane Doe" {
+		t.Errorf("unexpected plugin attributes: %v", result.Attributes)
+	}
+
+	competitor := &Lead{Name: "John Roe", Company: "competitor inc"}
+	result, err = step.Run(context.Background(), competitor)
+	if err != nil {
+		t.Fatalf("plugin run failed: %v", err)
+	}
+	if result.Outcome != OutcomeSkip || result.Reason == "" {
+		t.Errorf("expected skip with reason, got %+v", result)
+	}
+}
+
+// TestPluginStepFailure tests that a failing plugin process surfaces an error
+func TestPluginStepFailure(t *testing.T) {
+	config := helperPluginConfig("broken")
+	config.Command = filepath.Join(t.TempDir(), "does-not-exist")
+
+	step, err := NewPluginStep(config)
+	if err != nil {
+		t.Fatalf("failed to build plugin step: %v", err)
+	}
+	if _, err := step.Run(context.Background(), &Lead{}); err == nil {
+		t.Fatalf("expected error for missing plugin executable")
+	}
+
+	if _, err := NewPluginStep(StepConfig{ID: "empty", Type: StepTypePlugin}); err == nil {
+		t.Fatalf("expected error for plugin step without command")
+	}
+}
+
+// TestRunnerStepOrdering tests that the runner follows declared order, next references and skips
+func TestRunnerStepOrdering(t *testing.T) {
+	rapid.Check(t, func(rt *rapid.T) {
+		stepCount := rapid.IntRange(1, 8).Draw(rt, "step_count")
+		skipAt := rapid.IntRange(-1, stepCount-1).Draw(rt, "skip_at")
+
+		var visited []string
+		registry := NewRegistry()
+		registry.Register("record", func(config StepConfig) (Step, error) {
+			return StepFunc(func(ctx context.Context, lead *Lead) (StepResult, error) {
+				if StepID(ctx) != config.ID {
+					rt.Fatalf("step %s ran with step ID %q", config.ID, StepID(ctx))
+				}
+				visited = append(visited, config.ID)
+				if config.Params["skip"] == "true" {
+					return StepResult{Outcome: OutcomeSkip}, nil
+				}
+				return StepResult{Outcome: OutcomeContinue, Attributes: map[string]string{config.ID: "done"}}, nil
+			}), nil
+		})
+
+		c := &Campaign{Name: "ordering"}
+		for i := 0; i < stepCount; i++ {
+			c.Steps = append(c.Steps, StepConfig{
+				ID:     fmt.Sprintf("s%d", i),
+				Type:   "record",
+				Params: map[string]string{"skip": fmt.Sprint(i == skipAt)},
+			})
+		}
+
+		runner, err := NewRunner(c, registry)
+		if err != nil {
+			rt.Fatalf("failed to create runner: %v", err)
+		}
+
+		lead := &Lead{ProfileURL: "https://www.linkedin.com/in/jane"}
+		records, err := runner.RunLead(context.Background(), lead)
+		if err != nil {
+			rt.Fatalf("run failed: %v", err)
+		}
+
+		expected := stepCount
+		if skipAt >= 0 {
+			expected = skipAt + 1
+		}
+		if len(visited) != expected || len(records) != expected {
+			rt.Fatalf("expected %d steps to run, got %d (%v)", expected, len(visited), visited)
+		}
+		for i, id := range visited {
+			if id != fmt.Sprintf("s%d", i) {
+				rt.Fatalf("step %d ran out of order: %s", i, id)
+			}
+		}
+		if skipAt != 0 && lead.Attributes["s0"] != "done" {
+			rt.Fatalf("step attributes were not merged into the lead")
+		}
+	})
+}
+
+// TestRunnerRejectsInvalidCampaigns tests runner construction errors
+func TestRunnerRejectsInvalidCampaigns(t *testing.T) {
+	registry := NewRegistry()
+
+	unknownType := &Campaign{Steps: []StepConfig{{ID: "a", Type: "teleport"}}}
+	if _, err := NewRunner(unknownType, registry); err == nil {
+		t.Errorf("expected error for unknown step type")
+	}
+
+	badNext := &Campaign{Steps: []StepConfig{{ID: "a", Type: StepTypePlugin, Command: "x", Next: "missing"}}}
+	if _, err := NewRunner(badNext, registry); err == nil {
+		t.Errorf("expected error for unknown next step")
+	}
+
+	duplicate := &Campaign{Steps: []StepConfig{
+		{ID: "a", Type: StepTypePlugin, Command: "x"},
+		{ID: "a", Type: StepTypePlugin, Command: "y"},
+	}}
+	if _, err := NewRunner(duplicate, registry); err == nil {
+		t.Errorf("expected error for duplicate step ids")
+	}
+}
+
+// TestLoadCampaign tests YAML loading and default step IDs
+func TestLoadCampaign(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "campaign.yaml")
+	content := `name: enrichment
+steps:
+  - type: plugin
+    command: ./enrich
+    timeout: 10s
+    params:
+      endpoint: https://crm.internal/api
+  - id: final
+    type: plugin
+    command: ./notify
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write campaign: %v", err)
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load campaign: %v", err)
+	}
+	if c.Name != "enrichment" || len(c.Steps) != 2 {
+		t.Fatalf("unexpected campaign: %+v", c)
+	}
+	if c.Steps[0].ID != "step-1" || c.Steps[1].ID != "final" {
+		t.Errorf("unexpected step ids: %s, %s", c.Steps[0].ID, c.Steps[1].ID)
+	}
+	if c.Steps[0].Params["endpoint"] != "https://crm.internal/api" {
+		t.Errorf("params not loaded: %v", c.Steps[0].Params)
+	}
+}
+
+// TestRenderTemplate tests rendering lead fields and attributes, and failing on missing attributes
+func TestRenderTemplate(t *testing.T) {
+	lead := &Lead{Name: "Jane Doe", Company: "Acme", Attributes: map[string]string{"crm_id": "42"}}
+
+	message, err := RenderTemplate("Hi {{.Name}} at {{.Company}} ({{.Attributes.crm_id}})", lead)
+	if err != nil {
+		t.Fatalf("failed to render template: %v", err)
+	}
+	if message != "Hi Jane Doe at Acme (42)" {
+		t.Errorf("unexpected message: %q", message)
+	}
+
+	if _, err := RenderTemplate("{{.Attributes.missing}}", lead); err == nil {
+		t.Errorf("expected error for missing attribute")
+	}
+	if message, err := RenderTemplate("", lead); err != nil || message != "" {
+		t.Errorf("expected empty template to render nothing, got %q, %v", message, err)
+	}
+
+	fields, attributes, err := TemplateVariables(`{{.Name}} {{if .Attributes.a}}{{index .Attributes "b"}}{{end}}`)
+	if err != nil {
+		t.Fatalf("failed to inspect template: %v", err)
+	}
+	if strings.Join(fields, ",") != "Name" || strings.Join(attributes, ",") != "a,b" {
+		t.Errorf("unexpected variables: fields=%v attributes=%v", fields, attributes)
+	}
+}
+
+// TestTemplateVariableCatalog tests rendering catalog variables and rejecting unknown ones when a campaign loads
+func TestTemplateVariableCatalog(t *testing.T) {
+	lead := &Lead{
+		Name:       "Dr. Jane van Doe, PMP 🚀",
+		Company:    "Acme",
+		Location:   "Greater Munich Metropolitan Area, Germany",
+		Mutual:     3,
+		Attributes: map[string]string{AttributeRecentPostTopic: "observability"},
+	}
+	message, err := RenderTemplate("Hi {{first_name}} {{last_name}} in {{city}} at {{company}}: {{mutuals}} mutuals, {{recent_post_topic}}", lead)
+	if err != nil {
+		t.Fatalf("failed to render template: %v", err)
+	}
+	if message != "Hi Jane van Doe in Munich at Acme: 3 mutuals, observability" {
+		t.Errorf("unexpected message: %q", message)
+	}
+	if message, err := RenderTemplate("Hi {{salutation}}", &Lead{Name: "🚀"}); err != nil || message != "Hi there" {
+		t.Errorf("expected the salutation fallback, got %q, %v", message, err)
+	}
+	if _, err := RenderTemplate("{{about}}", lead); err == nil {
+		t.Errorf("expected error for a variable whose attribute the lead lacks")
+	}
+
+	_, attributes, err := TemplateVariables("{{first_name}} {{if true}}{{years_connected}}{{end}}")
+	if err != nil {
+		t.Fatalf("failed to inspect template: %v", err)
+	}
+	if strings.Join(attributes, ",") != AttributeYearsConnected {
+		t.Errorf("expected the years_connected attribute, got %v", attributes)
+	}
+
+	path := filepath.Join(t.TempDir(), "campaign.yaml")
+	content := `name: typo
+steps:
+  - type: plugin
+    command: ./note
+    template: "Hi {{frist_name}}"
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write campaign: %v", err)
+	}
+	_, err = Load(path)
+	if err == nil || !strings.Contains(err.Error(), `unknown template variable "frist_name"`) {
+		t.Errorf("expected load to reject the unknown variable, got %v", err)
+	}
+}
+
+// TestLintCampaign tests that lint reports reference, template, rate and variable problems
+func TestLintCampaign(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(StepTypeOpenProfile, NewOpenProfileStepFactory(nil))
+
+	valid := &Campaign{
+		Name:   "valid",
+		Limits: RateConfig{LeadsPerHour: 5, DailyCap: 40},
+		Steps: []StepConfig{
+			{ID: "crm", Type: StepTypePlugin, Command: "./crm", Outputs: []string{"crm_id"}},
+			{ID: "note", Type: StepTypePlugin, Command: "./note", Template: "Hi {{.Name}}, ref {{.Attributes.crm_id}}"},
+		},
+	}
+	if issues := Lint(valid, registry); len(issues) != 0 {
+		t.Errorf("expected no issues, got %v", issues)
+	}
+
+	cases := map[string]*Campaign{
+		"unknown next":                   {Name: "c", Steps: []StepConfig{{ID: "a", Type: StepTypePlugin, Command: "x", Next: "missing"}}},
+		"unknown type":                   {Name: "c", Steps: []StepConfig{{ID: "a", Type: "teleport"}}},
+		"bad template":                   {Name: "c", Steps: []StepConfig{{ID: "a", Type: StepTypePlugin, Command: "x", Template: "{{.Name"}}},
+		"unknown field":                  {Name: "c", Steps: []StepConfig{{ID: "a", Type: StepTypePlugin, Command: "x", Template: "{{.Email}}"}}},
+		"unknown variable":               {Name: "c", Steps: []StepConfig{{ID: "a", Type: StepTypePlugin, Command: "x", Template: "{{email}}"}}},
+		"variable without its attribute": {Name: "c", Steps: []StepConfig{{ID: "a", Type: StepTypePlugin, Command: "x", Template: "{{recent_post_topic}}"}}},
+		"negative rate":                  {Name: "c", Limits: RateConfig{LeadsPerHour: -1}, Steps: []StepConfig{{ID: "a", Type: StepTypePlugin, Command: "x"}}},
+		"open profile without templates": {Name: "c", Steps: []StepConfig{{ID: "a", Type: StepTypeOpenProfile}}},
+		"bad subject":                    {Name: "c", Steps: []StepConfig{{ID: "a", Type: StepTypeOpenProfile, Templates: []string{"Hi"}, Subject: "{{.Nope}}"}}},
+		"unknown note strategy":          {Name: "c", Invites: InviteConfig{Note: "sometimes"}, Steps: []StepConfig{{ID: "a", Type: StepTypePlugin, Command: "x"}}},
+		"negative step limit":            {Name: "c", Steps: []StepConfig{{ID: "a", Type: StepTypeOpenProfile, Template: "Hi", Limits: RateConfig{DailyCap: -1}}}},
+		"negative workers":               {Name: "c", Concurrency: ConcurrencyConfig{Workers: -1}, Steps: []StepConfig{{ID: "a", Type: StepTypePlugin, Command: "x"}}},
+		"negative step workers":          {Name: "c", Steps: []StepConfig{{ID: "a", Type: StepTypePlugin, Command: "x", Workers: -2}}},
+		"no page for searches": {Name: "c", Concurrency: ConcurrencyConfig{MaxPages: 1},
+			Searches: SearchConfig{Queries: []SearchQuery{{Query: "sre"}}},
+			Steps:    []StepConfig{{ID: "a", Type: StepTypeOpenProfile, Template: "Hi"}}},
+		"attribute used too early": {Name: "c", Steps: []StepConfig{
+			{ID: "a", Type: StepTypePlugin, Command: "x", Template: "{{.Attributes.crm_id}}"},
+			{ID: "b", Type: StepTypePlugin, Command: "x", Outputs: []string{"crm_id"}},
+		}},
+		"cycle": {Name: "c", Steps: []StepConfig{
+			{ID: "a", Type: StepTypePlugin, Command: "x", Next: "b"},
+			{ID: "b", Type: StepTypePlugin, Command: "x", Next: "a"},
+		}},
+	}
+	for name, c := range cases {
+		if issues := Lint(c, registry); !HasErrors(issues) {
+			t.Errorf("%s: expected lint errors, got %v", name, issues)
+		}
+	}
+
+	unreachable := &Campaign{Name: "c", Steps: []StepConfig{
+		{ID: "a", Type: StepTypePlugin, Command: "x", Next: "c"},
+		{ID: "b", Type: StepTypePlugin, Command: "x"},
+		{ID: "c", Type: StepTypePlugin, Command: "x"},
+	}}
+	issues := Lint(unreachable, registry)
+	if HasErrors(issues) || len(issues) != 1 || issues[0].StepID != "b" {
+		t.Errorf("expected a single unreachable warning for step b, got %v", issues)
+	}
+}
+
+// TestSimulatorWalksStates tests that simulation renders templates and applies states without running plugins
+func TestSimulatorWalksStates(t *testing.T) {
+	c := &Campaign{
+		Name: "simulated",
+		Steps: []StepConfig{
+			{ID: "crm", Type: StepTypePlugin, Command: "/nonexistent/plugin", Outputs: []string{"crm_id"}, State: "checked"},
+			{ID: "invite", Type: "invite", Template: "Hi {{.Name}} ({{.Attributes.crm_id}})", State: "invited"},
+		},
+	}
+
+	simulator, err := NewSimulator(c)
+	if err != nil {
+		t.Fatalf("failed to create simulator: %v", err)
+	}
+
+	lead := &Lead{Name: "Jane Doe"}
+	records, err := simulator.RunLead(context.Background(), lead)
+	if err != nil {
+		t.Fatalf("simulation failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 step records, got %d", len(records))
+	}
+	if records[1].Result.Message != "Hi Jane Doe (<crm.crm_id>)" {
+		t.Errorf("unexpected rendered message: %q", records[1].Result.Message)
+	}
+	if lead.State != "invited" {
+		t.Errorf("expected lead to end in state invited, got %q", lead.State)
+	}
+}
+
+// fakeOpenProfiles reports the listed profiles as open and records the messages sent to them
+type fakeOpenProfiles struct {
+	open map[string]bool
+	sent []string
+}
+
+func (f *fakeOpenProfiles) IsOpenProfile(ctx context.Context, lead *Lead) (bool, error) {
+	return f.open[lead.ProfileURL], nil
+}
+
+func (f *fakeOpenProfiles) SendOpenProfileMessage(ctx context.Context, lead *Lead, subject, body string) error {
+	f.sent = append(f.sent, subject+"|"+body)
+	return nil
+}
+
+// TestOpenProfileStep tests that open profiles are messaged instead of invited until the step's limits run out
+func TestOpenProfileStep(t *testing.T) {
+	messenger := &fakeOpenProfiles{open: map[string]bool{
+		"https://www.linkedin.com/in/ann":  true,
+		"https://www.linkedin.com/in/bob":  true,
+		"https://www.linkedin.com/in/cara": true,
+	}}
+	var invited []string
+	registry := NewRegistry()
+	registry.Register(StepTypeOpenProfile, NewOpenProfileStepFactory(messenger))
+	registry.Register("invite", func(config StepConfig) (Step, error) {
+		return StepFunc(func(ctx context.Context, lead *Lead) (StepResult, error) {
+			invited = append(invited, lead.Name)
+			return StepResult{Outcome: OutcomeContinue}, nil
+		}), nil
+	})
+
+	c := &Campaign{Name: "open", Steps: []StepConfig{
+		{ID: "open", Type: StepTypeOpenProfile, Subject: "For {{.Name}}", Templates: []string{"Hi {{.Name}}"}, Limits: RateConfig{DailyCap: 2}},
+		{ID: "invite", Type: "invite"},
+	}}
+	runner, err := NewRunner(c, registry)
+	if err != nil {
+		t.Fatalf("failed to create runner: %v", err)
+	}
+
+	for _, name := range []string{"ann", "dan", "bob", "cara"} {
+		lead := &Lead{ProfileURL: "https://www.linkedin.com/in/" + name, Name: name}
+		if _, err := runner.RunLead(context.Background(), lead); err != nil {
+			t.Fatalf("run failed for %s: %v", name, err)
+		}
+		if name == "cara" && lead.Attributes["open_profile_messaged"] != "false" {
+			t.Errorf("expected cara to be left for the invite once the cap was reached, got %v", lead.Attributes)
+		}
+	}
+
+	if strings.Join(messenger.sent, ",") != "For ann|Hi ann,For bob|Hi bob" {
+		t.Errorf("unexpected open profile messages: %v", messenger.sent)
+	}
+	if strings.Join(invited, ",") != "dan,cara" {
+		t.Errorf("expected only dan and cara to be invited, got %v", invited)
+	}
+
+	if pickVariant("https://www.linkedin.com/in/ann", 3) != pickVariant("https://www.linkedin.com/in/ann", 3) {
+		t.Errorf("expected the same variant for the same lead")
+	}
+	if _, err := NewOpenProfileStepFactory(messenger)(StepConfig{ID: "empty", Type: StepTypeOpenProfile}); err == nil {
+		t.Errorf("expected a step without templates to be rejected")
+	}
+}
+
+// TestLocalizedTemplates tests template files, language detection and the English fallback
+func TestLocalizedTemplates(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "de.tmpl"), []byte("Hallo {{.Name}}\n"), 0644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+	path := filepath.Join(dir, "campaign.yaml")
+	content := `name: localized
+steps:
+  - id: note
+    type: plugin
+    command: ./note
+    template: "Hi {{.Name}}"
+    localized:
+      es: "Hola {{.Name}}"
+    template_files:
+      de: de.tmpl
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write campaign: %v", err)
+	}
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load campaign: %v", err)
+	}
+	if issues := Lint(c, NewRegistry()); len(issues) != 0 {
+		t.Errorf("expected no issues, got %v", issues)
+	}
+
+	simulator, err := NewSimulator(c)
+	if err != nil {
+		t.Fatalf("failed to create simulator: %v", err)
+	}
+	cases := []struct {
+		lead *Lead
+		want string
+	}{
+		{&Lead{Name: "Jana", Title: "Leiterin der Entwicklung bei Acme und Mentorin für Teams"}, "Hallo Jana"},
+		{&Lead{Name: "Jana", Title: "CTO", Location: "Madrid, Spain"}, "Hola Jana"},
+		{&Lead{Name: "Jana", Title: "CTO", Location: "Paris, France"}, "Hi Jana"},
+		{&Lead{Name: "Jana", Title: "Head of Sales", Attributes: map[string]string{AttributeLanguage: "de"}}, "Hallo Jana"},
+	}
+	for _, tc := range cases {
+		records, err := simulator.RunLead(context.Background(), tc.lead)
+		if err != nil {
+			t.Fatalf("simulation failed: %v", err)
+		}
+		if records[0].Result.Message != tc.want {
+			t.Errorf("expected %q for %+v, got %q", tc.want, tc.lead, records[0].Result.Message)
+		}
+	}
+
+	c.Steps[0].TemplateFiles = map[string]string{"es": "es.tmpl"}
+	if err := loadTemplateFiles(&c.Steps[0], dir); err == nil {
+		t.Errorf("expected a language set inline and as a file to be rejected")
+	}
+}
+
+// fakeInviter records the note each lead was invited with, and the template ID when templates
+// is set; notes to leads in noField fall back to blank
+type fakeInviter struct {
+	notes     map[string]string
+	noField   map[string]bool
+	templates map[string]string
+}
+
+func (f *fakeInviter) Invite(ctx context.Context, lead *Lead, note string) (bool, error) {
+	if f.noField[lead.Name] {
+		note = ""
+	}
+	f.notes[lead.Name] = note
+	if f.templates != nil {
+		f.templates[lead.Name] = TemplateID(ctx)
+	}
+	return note != "", nil
+}
+
+// TestInviteTemplateID tests that invites with a note carry the ID of the step's template in the
+// lead's language, which changes with the template's text
+func TestInviteTemplateID(t *testing.T) {
+	inviter := &fakeInviter{notes: make(map[string]string), templates: make(map[string]string)}
+	step := StepConfig{ID: "invite", Type: StepTypeInvite, Localized: map[string]string{"en": "Hi {{.Name}}", "de": "Hallo {{.Name}}"}}
+	ctx := context.WithValue(context.Background(), stepIDKey{}, "invite")
+	run := func(step StepConfig, invites InviteConfig, lead *Lead) string {
+		built, err := NewInviteStepFactory(inviter, invites)(step)
+		if err != nil {
+			t.Fatalf("failed to build invite step: %v", err)
+		}
+		if _, err := built.Run(ctx, lead); err != nil {
+			t.Fatalf("invite failed: %v", err)
+		}
+		return inviter.templates[lead.Name]
+	}
+
+	german := &Lead{ProfileURL: "https://www.linkedin.com/in/jana/", Name: "jana", Attributes: map[string]string{AttributeLanguage: "de"}}
+	english := &Lead{ProfileURL: "https://www.linkedin.com/in/john/", Name: "john", Attributes: map[string]string{AttributeLanguage: "en"}}
+	de, en := run(step, InviteConfig{}, german), run(step, InviteConfig{}, english)
+	if !strings.HasPrefix(de, "invite@de#") || !strings.HasPrefix(en, "invite@en#") {
+		t.Errorf("expected IDs of the German and English templates, got %q and %q", de, en)
+	}
+	if again := run(step, InviteConfig{}, english); again != en {
+		t.Errorf("expected the same template to keep its ID, got %q and %q", en, again)
+	}
+	step.Localized["en"] = "Hello {{.Name}}"
+	if edited := run(step, InviteConfig{}, english); edited == en || !strings.HasPrefix(edited, "invite@en#") {
+		t.Errorf("expected an edited template to get a new ID, got %q after %q", edited, en)
+	}
+	if blank := run(step, InviteConfig{Note: NoteNever}, english); blank != "" {
+		t.Errorf("expected no template ID for a blank invite, got %q", blank)
+	}
+}
+
+// TestInviteNoteStrategy tests that invites carry notes always, never, or for a stable share of leads
+func TestInviteNoteStrategy(t *testing.T) {
+	step := StepConfig{ID: "invite", Type: StepTypeInvite, Template: "Hi {{.Name}}"}
+
+	for _, tc := range []struct {
+		invites  InviteConfig
+		min, max int
+	}{
+		{InviteConfig{}, 100, 100},
+		{InviteConfig{Note: NoteNever}, 0, 0},
+		{InviteConfig{Note: NoteSplit}, 35, 65},
+		{InviteConfig{Note: NoteSplit, NoteSplit: 20}, 10, 30},
+	} {
+		inviter := &fakeInviter{notes: make(map[string]string)}
+		built, err := NewInviteStepFactory(inviter, tc.invites)(step)
+		if err != nil {
+			t.Fatalf("failed to build invite step: %v", err)
+		}
+
+		withNote := 0
+		for i := 0; i < 100; i++ {
+			lead := &Lead{ProfileURL: fmt.Sprintf("https://www.linkedin.com/in/lead-%d/", i), Name: fmt.Sprintf("lead-%d", i)}
+			result, err := built.Run(context.Background(), lead)
+			if err != nil {
+				t.Fatalf("invite failed: %v", err)
+			}
+			if inviter.notes[lead.Name] != "" {
+				withNote++
+				if result.Attributes["invite_arm"] != "note" {
+					t.Errorf("expected the note arm for %s, got %v", lead.Name, result.Attributes)
+				}
+			} else if result.Attributes["invite_arm"] != "blank" {
+				t.Errorf("expected the blank arm for %s, got %v", lead.Name, result.Attributes)
+			}
+			if tc.invites.WithNote(lead.ProfileURL) != (inviter.notes[lead.Name] != "") {
+				t.Errorf("expected %s to stay in the same arm", lead.Name)
+			}
+		}
+		if withNote < tc.min || withNote > tc.max {
+			t.Errorf("%+v: expected %d-%d invites with a note, got %d", tc.invites, tc.min, tc.max, withNote)
+		}
+	}
+}
+
+// TestInviteWithoutNoteFallback tests that an invite whose note field failed is recorded with note_sent=false
+func TestInviteWithoutNoteFallback(t *testing.T) {
+	inviter := &fakeInviter{notes: make(map[string]string), noField: map[string]bool{"jane": true}}
+	built, err := NewInviteStepFactory(inviter, InviteConfig{})(StepConfig{ID: "invite", Type: StepTypeInvite, Template: "Hi {{.Name}}"})
+	if err != nil {
+		t.Fatalf("failed to build invite step: %v", err)
+	}
+
+	result, err := built.Run(context.Background(), &Lead{ProfileURL: "https://www.linkedin.com/in/jane/", Name: "jane"})
+	if err != nil {
+		t.Fatalf("expected the blank invite to succeed, got %v", err)
+	}
+	if result.Outcome != OutcomeContinue || result.Attributes["note_sent"] != "false" || result.Message != "" {
+		t.Errorf("expected a blank invite with note_sent=false, got %+v", result)
+	}
+
+	result, err = built.Run(context.Background(), &Lead{ProfileURL: "https://www.linkedin.com/in/john/", Name: "john"})
+	if err != nil || result.Attributes["note_sent"] != "true" || result.Message != "Hi john" {
+		t.Errorf("expected the note to be sent to john, got %+v, %v", result, err)
+	}
+}
+
+// fakeConnections records the messages sent to first-degree connections
+type fakeConnections struct {
+	sent []string
+}
+
+func (f *fakeConnections) MessageConnection(ctx context.Context, lead *Lead, body string) error {
+	f.sent = append(f.sent, body)
+	return nil
+}
+
+// TestNetworkCampaignSkipsInvite tests that connections are messaged without an invite while
+// other leads are invited and not messaged
+func TestNetworkCampaignSkipsInvite(t *testing.T) {
+	messenger := &fakeConnections{}
+	inviter := &fakeInviter{notes: make(map[string]string)}
+	registry := NewRegistry()
+	registry.Register(StepTypeInvite, NewInviteStepFactory(inviter, InviteConfig{}))
+	registry.Register(StepTypeMessage, NewMessageStepFactory(messenger))
+
+	c := &Campaign{Name: "reconnect", Searches: SearchConfig{Network: &NetworkSearch{Tags: []string{"alumni"}}}, Steps: []StepConfig{
+		{ID: "invite", Type: StepTypeInvite, Template: "Hi {{.Name}}"},
+		{ID: "catch-up", Type: StepTypeMessage, Template: "Hi {{.Name}}, how is {{.Company}}?", State: "messaged"},
+	}}
+	if issues := Lint(c, registry); len(issues) != 0 {
+		t.Fatalf("expected no lint issues, got %v", issues)
+	}
+	runner, err := NewRunner(c, registry)
+	if err != nil {
+		t.Fatalf("failed to create runner: %v", err)
+	}
+
+	connection := &Lead{ProfileURL: "https://www.linkedin.com/in/ann", Name: "Ann", Company: "Acme",
+		Attributes: map[string]string{AttributeDegree: DegreeFirst}}
+	records, err := runner.RunLead(context.Background(), connection)
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if _, invited := inviter.notes["Ann"]; invited || records[0].Result.Reason == "" {
+		t.Errorf("expected the connection to pass the invite step, got %+v", records[0].Result)
+	}
+	if strings.Join(messenger.sent, ",") != "Hi Ann, how is Acme?" || connection.State != "messaged" {
+		t.Errorf("expected the connection messaged, got %v in state %q", messenger.sent, connection.State)
+	}
+
+	stranger := &Lead{ProfileURL: "https://www.linkedin.com/in/bob", Name: "Bob"}
+	if _, err := runner.RunLead(context.Background(), stranger); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if inviter.notes["Bob"] != "Hi Bob" || len(messenger.sent) != 1 || stranger.Attributes["messaged"] != "false" {
+		t.Errorf("expected the stranger invited but not messaged, got %v and %v", inviter.notes, messenger.sent)
+	}
+
+	c.Searches.Queries = []SearchQuery{{Query: "founder"}}
+	if issues := Lint(c, registry); !HasErrors(issues) {
+		t.Errorf("expected network and queries together to be rejected, got %v", issues)
+	}
+	if _, err := NewMessageStepFactory(messenger)(StepConfig{ID: "empty", Type: StepTypeMessage}); err == nil {
+		t.Errorf("expected a message step without templates to be rejected")
+	}
+}
+
+// TestDormantNetworkCampaign tests the attributes network leads start with and that message
+// steps keep to their own daily cap
+func TestDormantNetworkCampaign(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	attributes := NetworkLeadAttributes(time.Date(2023, 3, 11, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), now)
+	if attributes[AttributeYearsConnected] != "2" || attributes[AttributeConnectedOn] != "2023-03-11" || attributes[AttributeLastContact] != "2025-01-02" {
+		t.Errorf("unexpected network attributes: %v", attributes)
+	}
+	if undated := NetworkLeadAttributes(time.Time{}, time.Time{}, now); len(undated) != 1 || undated[AttributeDegree] != DegreeFirst {
+		t.Errorf("expected only the degree for an undated connection, got %v", undated)
+	}
+
+	messenger := &fakeConnections{}
+	registry := NewRegistry()
+	registry.Register(StepTypeMessage, NewMessageStepFactory(messenger))
+	c := &Campaign{Name: "dormant", Searches: SearchConfig{Network: &NetworkSearch{DormantMonths: 12}}, Steps: []StepConfig{
+		{ID: "re-engage", Type: StepTypeMessage, Template: "Hi {{.Name}}, {{.Attributes.years_connected}} years already!", Limits: RateConfig{DailyCap: 2}},
+	}}
+	if issues := Lint(c, registry); len(issues) != 0 {
+		t.Fatalf("expected network attributes to be available to templates, got %v", issues)
+	}
+	runner, err := NewRunner(c, registry)
+	if err != nil {
+		t.Fatalf("failed to create runner: %v", err)
+	}
+	for _, name := range []string{"ann", "bob", "cara"} {
+		lead := &Lead{ProfileURL: "https://www.linkedin.com/in/" + name, Name: name, Attributes: NetworkLeadAttributes(now.AddDate(-3, 0, 0), time.Time{}, now)}
+		if _, err := runner.RunLead(context.Background(), lead); err != nil {
+			t.Fatalf("run failed for %s: %v", name, err)
+		}
+		if name == "cara" && lead.Attributes["messaged"] != "false" {
+			t.Errorf("expected cara left unmessaged once the cap was reached, got %v", lead.Attributes)
+		}
+	}
+	if strings.Join(messenger.sent, ",") != "Hi ann, 3 years already!,Hi bob, 3 years already!" {
+		t.Errorf("unexpected messages: %v", messenger.sent)
+	}
+
+	c.Searches.Network.DormantMonths = -1
+	if issues := Lint(c, registry); !HasErrors(issues) {
+		t.Errorf("expected negative dormant_months to be rejected, got %v", issues)
+	}
+}
+
+// concurrencyProbe records the most calls it has seen running at once
+type concurrencyProbe struct {
+	running, peak int
+	mutex         sync.Mutex
+}
+
+func (p *concurrencyProbe) enter() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.running++
+	if p.running > p.peak {
+		p.peak = p.running
+	}
+}
+
+func (p *concurrencyProbe) leave() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.running--
+}
+
+// TestPoolBoundsConcurrency tests that leads, step workers and the shared browser page stay within their limits
+func TestPoolBoundsConcurrency(t *testing.T) {
+	leads, enrich, page := &concurrencyProbe{}, &concurrencyProbe{}, &concurrencyProbe{}
+	probed := func(probes ...*concurrencyProbe) StepFactory {
+		return func(config StepConfig) (Step, error) {
+			return StepFunc(func(ctx context.Context, lead *Lead) (StepResult, error) {
+				for _, probe := range probes {
+					probe.enter()
+				}
+				time.Sleep(5 * time.Millisecond)
+				for _, probe := range probes {
+					probe.leave()
+				}
+				return StepResult{Outcome: OutcomeContinue}, nil
+			}), nil
+		}
+	}
+	registry := NewRegistry()
+	registry.Register("enrich", probed(leads, enrich))
+	registry.Register(StepTypeInvite, probed(leads, page))
+	registry.Register(StepTypeMessage, probed(leads, page))
+
+	c := &Campaign{
+		Name:        "pool",
+		Concurrency: ConcurrencyConfig{Workers: 4, QueueDepth: 2},
+		Steps: []StepConfig{
+			{ID: "enrich", Type: "enrich", Workers: 2},
+			{ID: "invite", Type: StepTypeInvite},
+			{ID: "message", Type: StepTypeMessage},
+		},
+	}
+	runner, err := NewRunner(c, registry)
+	if err != nil {
+		t.Fatalf("failed to create runner: %v", err)
+	}
+
+	done := 0
+	pool := NewPool(context.Background(), runner, c.Concurrency, func(lead *Lead, records []StepRecord, err error) {
+		if err != nil || len(records) != 3 {
+			t.Errorf("lead %s: expected three steps to run, got %v (%v)", lead.ProfileURL, records, err)
+		}
+		done++
+	})
+	for i := 0; i < 12; i++ {
+		if err := pool.Submit(context.Background(), &Lead{ProfileURL: fmt.Sprintf("https://www.linkedin.com/in/lead-%d", i)}); err != nil {
+			t.Fatalf("submit failed: %v", err)
+		}
+	}
+	pool.Close()
+
+	if done != 12 {
+		t.Errorf("expected every lead to be done, got %d", done)
+	}
+	if leads.peak > 4 || enrich.peak > 2 || page.peak != 1 {
+		t.Errorf("limits exceeded: %d steps at once, %d enriching, %d on the page", leads.peak, enrich.peak, page.peak)
+	}
+}
+
+// TestPoolBackPressure tests that Submit waits for a full queue and a stopped pool drops queued leads
+func TestPoolBackPressure(t *testing.T) {
+	release := make(chan struct{})
+	registry := NewRegistry()
+	registry.Register("block", func(config StepConfig) (Step, error) {
+		return StepFunc(func(ctx context.Context, lead *Lead) (StepResult, error) {
+			<-release
+			return StepResult{Outcome: OutcomeContinue}, nil
+		}), nil
+	})
+	c := &Campaign{Name: "pressure", Steps: []StepConfig{{ID: "block", Type: "block"}}}
+	runner, err := NewRunner(c, registry)
+	if err != nil {
+		t.Fatalf("failed to create runner: %v", err)
+	}
+
+	results := make(map[string]error)
+	pool := NewPool(context.Background(), runner, ConcurrencyConfig{Workers: 1, QueueDepth: 1}, func(lead *Lead, records []StepRecord, err error) {
+		results[lead.ProfileURL] = err
+	})
+	for _, url := range []string{"running", "queued"} {
+		if err := pool.Submit(context.Background(), &Lead{ProfileURL: url}); err != nil {
+			t.Fatalf("submit %s failed: %v", url, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := pool.Submit(ctx, &Lead{ProfileURL: "waiting"}); err != context.DeadlineExceeded {
+		t.Errorf("expected submit to wait for the full queue, got %v", err)
+	}
+
+	pool.Stop()
+	if err := pool.Submit(context.Background(), &Lead{ProfileURL: "late"}); err != ErrNotRun {
+		t.Errorf("expected a stopped pool to refuse leads, got %v", err)
+	}
+	close(release)
+	pool.Close()
+
+	if len(results) != 2 || results["running"] != nil || results["queued"] != ErrNotRun {
+		t.Errorf("expected the running lead to finish and the queued one to be dropped, got %v", results)
+	}
+}
+
+// TestRunnerObserver tests that the observer sees every step before the lead takes its state
+func TestRunnerObserver(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("noop", func(config StepConfig) (Step, error) {
+		return StepFunc(func(ctx context.Context, lead *Lead) (StepResult, error) {
+			return StepResult{Outcome: OutcomeContinue}, nil
+		}), nil
+	})
+	c := &Campaign{Name: "observed", Steps: []StepConfig{
+		{ID: "check", Type: "noop"},
+		{ID: "invite", Type: "noop", State: "invited"},
+	}}
+	runner, err := NewRunner(c, registry)
+	if err != nil {
+		t.Fatalf("failed to create runner: %v", err)
+	}
+
+	var seen []string
+	runner.Observe(func(ctx context.Context, lead *Lead, config StepConfig, result StepResult, err error) {
+		if StepID(ctx) != config.ID {
+			t.Errorf("observer for %s ran with step ID %q", config.ID, StepID(ctx))
+		}
+		seen = append(seen, config.ID+":"+lead.State)
+	})
+	lead := &Lead{ProfileURL: "https://www.linkedin.com/in/jane", State: "new"}
+	if _, err := runner.RunLead(context.Background(), lead); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if strings.Join(seen, ",") != "check:new,invite:new" || lead.State != "invited" {
+		t.Errorf("expected both steps observed before the state changed, got %v (state %s)", seen, lead.State)
+	}
+}
+
+// TestPlan tests that the forecast keeps to the daily and weekly caps, skips the days nothing
+// runs, and counts leads already run against the first week
+func TestPlan(t *testing.T) {
+	wednesday := time.Date(2024, 7, 3, 15, 0, 0, 0, time.UTC)
+	forecast, err := Plan(PlanOptions{
+		Start:        wednesday,
+		Target:       100,
+		Limits:       RateConfig{DailyCap: 20, WeeklyCap: 50, LeadsPerHour: 4},
+		Hours:        4,
+		Weekdays:     []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		UsedThisWeek: 30,
+		Closed: func(day time.Time) (string, bool) {
+			return "blackout: holiday", day.Month() == time.July && day.Day() == 9
+		},
+	})
+	if err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+	if forecast.PerDay != 16 {
+		t.Errorf("expected leads_per_hour over 4 hours to bound the day at 16, got %d", forecast.PerDay)
+	}
+
+	var schedule []string
+	for _, day := range forecast.Days {
+		entry := fmt.Sprintf("%s:%d", day.Date.Format("01-02"), day.Leads)
+		if day.Reason != "" {
+			entry = day.Date.Format("01-02") + ":" + day.Reason
+		}
+		schedule = append(schedule, entry)
+	}
+	expected := []string{
+		"07-03:16", "07-04:4", "07-05:" + PlanWeeklyCap, "07-06:" + PlanNoRunDay, "07-07:" + PlanNoRunDay,
+		"07-08:16", "07-09:blackout: holiday", "07-10:16", "07-11:16", "07-12:2", "07-13:" + PlanNoRunDay, "07-14:" + PlanNoRunDay,
+		"07-15:16", "07-16:14",
+	}
+	if strings.Join(schedule, ",") != strings.Join(expected, ",") {
+		t.Errorf("unexpected schedule\n got: %v\nwant: %v", schedule, expected)
+	}
+	if !forecast.Finish.Equal(time.Date(2024, 7, 16, 0, 0, 0, 0, time.UTC)) || forecast.Days[len(forecast.Days)-1].Total != 100 {
+		t.Errorf("expected the target reached on 16 July, got %v", forecast.Finish)
+	}
+
+	if _, err := Plan(PlanOptions{Start: wednesday}); err == nil {
+		t.Error("expected a plan without a target rejected")
+	}
+	unreachable, err := Plan(PlanOptions{Start: wednesday, Target: 10, Weekdays: []time.Weekday{time.Monday},
+		Closed: func(time.Time) (string, bool) { return "blackout", true }})
+	if err != nil || !unreachable.Finish.IsZero() {
+		t.Errorf("expected a target never reached to have no finish, got %v, %v", unreachable.Finish, err)
+	}
+}
+
+// TestParseWeekdays tests weekday lists and ranges, including one across the weekend
+func TestParseWeekdays(t *testing.T) {
+	weekdays, err := ParseWeekdays("mon-wed, fri")
+	if err != nil || fmt.Sprint(weekdays) != "[Monday Tuesday Wednesday Friday]" {
+		t.Errorf("unexpected weekdays %v, %v", weekdays, err)
+	}
+	weekdays, err = ParseWeekdays("fri-mon")
+	if err != nil || fmt.Sprint(weekdays) != "[Friday Saturday Sunday Monday]" {
+		t.Errorf("unexpected weekdays %v, %v", weekdays, err)
+	}
+	if _, err := ParseWeekdays("weekdays"); err == nil {
+		t.Error("expected an unknown weekday rejected")
+	}
+	if monday := WeekStart(time.Date(2024, 7, 7, 23, 0, 0, 0, time.UTC)); monday.Day() != 1 {
+		t.Errorf("expected Sunday's week to start on Monday 1 July, got %v", monday)
+	}
+}
+
+// TestSpintax tests that spintax picks one option of every group, nested ones too, the same for
+// a lead every time, and leaves template actions and text outside groups alone
+func TestSpintax(t *testing.T) {
+	text := "{Hi|Hello|Hey {{.Name}}}, {great {work|post}|nice profile} | {{if .Company}}{{.Company}}{{end}}"
+	lead := &Lead{ProfileURL: "https://www.linkedin.com/in/jane/", Name: "Jane", Company: "Acme"}
+	seen := make(map[string]bool)
+	for attempt := 0; attempt < 50; attempt++ {
+		rendered, err := renderSpin(text, lead, attempt)
+		if err != nil {
+			t.Fatalf("failed to render: %v", err)
+		}
+		if strings.ContainsAny(rendered, "{}") || !strings.HasSuffix(rendered, " | Acme") {
+			t.Errorf("expected every group expanded and the rest kept, got %q", rendered)
+		}
+		seen[rendered] = true
+	}
+	if len(seen) != 9 {
+		t.Errorf("expected all 9 spins picked over 50 attempts, got %d: %v", len(seen), seen)
+	}
+	first, _ := RenderTemplate(text, lead)
+	if again, _ := RenderTemplate(text, lead); again != first {
+		t.Errorf("expected the lead to keep its pick, got %q and %q", first, again)
+	}
+
+	for _, invalid := range []string{"{Hi|Hello", "Hi}", "{Hi|{{.Name}}"} {
+		if _, err := parseTemplate("invite", invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+// fakeClaims claims texts like a uniqueness window with no expiry
+type fakeClaims map[string]string
+
+func (f fakeClaims) Claim(text, profileURL string) (bool, error) {
+	if owner, taken := f[text]; taken && owner != profileURL {
+		return false, nil
+	}
+	f[text] = profileURL
+	return true, nil
+}
+
+// fakeUniqueInviter is an inviter that keeps notes unique
+type fakeUniqueInviter struct {
+	fakeInviter
+	fakeClaims
+}
+
+// TestInviteUniqueNotes tests that no two leads are invited with the same note while spins are
+// left, and that a lead is skipped once every spin went to someone else
+func TestInviteUniqueNotes(t *testing.T) {
+	inviter := &fakeUniqueInviter{fakeInviter{notes: make(map[string]string)}, make(fakeClaims)}
+	built, err := NewInviteStepFactory(inviter, InviteConfig{})(StepConfig{ID: "invite", Type: StepTypeInvite, Template: "{Hi|Hello} there, {let's connect|glad to connect}"})
+	if err != nil {
+		t.Fatalf("failed to build invite step: %v", err)
+	}
+
+	notes := make(map[string]bool)
+	for i := 0; i < 5; i++ {
+		lead := &Lead{ProfileURL: fmt.Sprintf("https://www.linkedin.com/in/lead-%d/", i), Name: fmt.Sprintf("lead-%d", i)}
+		result, err := built.Run(context.Background(), lead)
+		if err != nil {
+			t.Fatalf("invite failed: %v", err)
+		}
+		if i < 4 {
+			if result.Outcome != OutcomeContinue || notes[inviter.notes[lead.Name]] {
+				t.Errorf("expected lead %d invited with a note no one else got, got %+v", i, result)
+			}
+			notes[inviter.notes[lead.Name]] = true
+			continue
+		}
+		if result.Outcome != OutcomeSkip || result.Reason != ReasonNoUniqueText || inviter.notes[lead.Name] != "" {
+			t.Errorf("expected the fifth lead skipped with all 4 spins taken, got %+v", result)
+		}
+	}
+}
+
+// TestPriority tests that the priority attribute wins over signals, that signals take the
+// campaign's priority or PriorityHigh, and that leads of equal rank keep their order
+func TestPriority(t *testing.T) {
+	config := PriorityConfig{Signals: map[string]int{"viewed_profile": 20, "liked_post": 5}}
+	cases := []struct {
+		attributes map[string]string
+		expected   int
+	}{
+		{nil, PriorityNormal},
+		{map[string]string{AttributePriority: "high", AttributeSignal: "viewed_profile"}, PriorityHigh},
+		{map[string]string{AttributePriority: "-3"}, -3},
+		{map[string]string{AttributeSignal: "liked_post, viewed_profile"}, 20},
+		{map[string]string{AttributeSignal: "replied_to_email"}, PriorityHigh},
+	}
+	for _, c := range cases {
+		if priority, err := config.Priority(c.attributes); err != nil || priority != c.expected {
+			t.Errorf("Priority(%v) = %d (%v), expected %d", c.attributes, priority, err, c.expected)
+		}
+	}
+	if _, err := config.Priority(map[string]string{AttributePriority: "urgent"}); err == nil {
+		t.Error("expected an unknown priority name to be refused")
+	}
+
+	ranks := map[string]Rank{"b": {Priority: PriorityHigh}, "c": {Score: 3}, "d": {Score: 3}, "e": {Priority: PriorityLow, Score: 9}}
+	leads := []string{"a", "e", "c", "b", "d"}
+	SortByRank(leads, func(lead string) Rank { return ranks[lead] })
+	if strings.Join(leads, "") != "bcdae" {
+		t.Errorf("expected leads ordered by priority, then score, then pool order, got %v", leads)
+	}
+}
@@ -0,0 +1,120 @@
+package campaign
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+)
+
+// StepTypeInvite is the step type that sends a connection request, with the step's rendered template as the note
+const StepTypeInvite = "invite"
+
+// Note strategies for invite steps
+const (
+	NoteAlways = "always" // Every invite carries the rendered note
+	NoteNever  = "never"  // Invites are blank and the Add-a-note dialog is never opened
+	NoteSplit  = "split"  // Leads are split between the two for an A/B comparison
+)
+
+// defaultNoteSplit is the share of leads invited with a note under the split strategy
+const defaultNoteSplit = 50
+
+// InviteConfig is the campaign's invitation settings
+type InviteConfig struct {
+	Note      string `yaml:"note"`       // "always" (default), "never" or "split"
+	NoteSplit int    `yaml:"note_split"` // Percent of leads invited with a note under "split", default 50
+}
+
+// Validate checks the note strategy and split
+func (c InviteConfig) Validate() error {
+	switch c.Note {
+	case "", NoteAlways, NoteNever, NoteSplit:
+	default:
+		return fmt.Errorf("invites.note must be %q, %q or %q, got %q", NoteAlways, NoteNever, NoteSplit, c.Note)
+	}
+	if c.NoteSplit < 0 || c.NoteSplit > 100 {
+		return fmt.Errorf("invites.note_split must be between 0 and 100, got %d", c.NoteSplit)
+	}
+	return nil
+}
+
+// WithNote reports whether the lead is invited with a note. Split campaigns assign a lead to an
+// arm by its profile URL, so the lead stays in the same arm across runs.
+func (c InviteConfig) WithNote(profileURL string) bool {
+	switch c.Note {
+	case NoteNever:
+		return false
+	case NoteSplit:
+		split := c.NoteSplit
+		if split == 0 {
+			split = defaultNoteSplit
+		}
+		hash := fnv.New32a()
+		hash.Write([]byte(profileURL))
+		return int(hash.Sum32()%100) < split
+	default:
+		return true
+	}
+}
+
+// Inviter sends connection requests for invite steps; note is "" for a blank invite. It reports
+// whether the note was sent, which is false when it fell back to a blank invite.
+type Inviter interface {
+	Invite(ctx context.Context, lead *Lead, note string) (bool, error)
+}
+
+// NewInviteStepFactory returns a factory building invite steps that send through inviter and
+// attach notes as the campaign's invitation settings say. An inviter that is a
+// UniquenessChecker gets notes no other recipient got, or the lead is skipped.
+func NewInviteStepFactory(inviter Inviter, invites InviteConfig) StepFactory {
+	checker, _ := inviter.(UniquenessChecker)
+	return func(config StepConfig) (Step, error) {
+		if inviter == nil {
+			return nil, fmt.Errorf("invite step %q needs a browser session", config.ID)
+		}
+		if err := invites.Validate(); err != nil {
+			return nil, err
+		}
+		for _, text := range append([]string{config.Template}, localizedTemplates(config)...) {
+			if _, err := parseTemplate(config.ID, text); err != nil {
+				return nil, err
+			}
+		}
+
+		return StepFunc(func(ctx context.Context, lead *Lead) (StepResult, error) {
+			if lead.Attributes[AttributeDegree] == DegreeFirst {
+				return StepResult{Outcome: OutcomeContinue, Reason: "already a connection, not invited"}, nil
+			}
+			arm, note := "blank", ""
+			if invites.WithNote(lead.ProfileURL) {
+				text, code := localize(config.Localized, config.Template, lead)
+				rendered, unique, err := renderUnique(text, lead, checker)
+				if err != nil {
+					return StepResult{}, err
+				}
+				if !unique {
+					return StepResult{Outcome: OutcomeSkip, Reason: ReasonNoUniqueText}, nil
+				}
+				if rendered != "" {
+					arm, note = "note", rendered
+					ctx = withTemplate(ctx, text, code)
+				}
+			}
+			noteSent, err := inviter.Invite(ctx, lead, note)
+			if err != nil {
+				return StepResult{}, fmt.Errorf("failed to invite: %w", err)
+			}
+			result := StepResult{
+				Outcome:    OutcomeContinue,
+				Attributes: map[string]string{"invite_arm": arm, "note_sent": strconv.FormatBool(noteSent)},
+			}
+			if noteSent {
+				result.Message = note
+			} else if note != "" {
+				result.Reason = "note field unavailable, invited without a note"
+			}
+			return result, nil
+		}), nil
+	}
+}
@@ -0,0 +1,315 @@
+package campaign
+
+import (
+	"fmt"
+	"strings"
+
+	"linkedin-automation-framework/internal/language"
+)
+
+// Severity classifies a lint issue
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // The campaign cannot run as written
+	SeverityWarning Severity = "warning" // The campaign runs but probably not as intended
+)
+
+// Issue is a single problem found while linting a campaign
+type Issue struct {
+	Severity Severity
+	StepID   string // Empty for campaign-level issues
+	Message  string
+}
+
+// String formats the issue for terminal output
+func (i Issue) String() string {
+	if i.StepID == "" {
+		return fmt.Sprintf("%s: %s", i.Severity, i.Message)
+	}
+	return fmt.Sprintf("%s: step %q: %s", i.Severity, i.StepID, i.Message)
+}
+
+// HasErrors reports whether any issue is an error
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint validates step references, step types, templates, rate limits, search queries and
+// template variable availability without building or running any step
+func Lint(campaign *Campaign, registry *Registry) []Issue {
+	var issues []Issue
+	add := func(severity Severity, stepID, format string, args ...interface{}) {
+		issues = append(issues, Issue{Severity: severity, StepID: stepID, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if campaign == nil {
+		add(SeverityError, "", "campaign cannot be nil")
+		return issues
+	}
+	if campaign.Name == "" {
+		add(SeverityWarning, "", "campaign has no name")
+	}
+	if campaign.Limits.LeadsPerHour < 0 {
+		add(SeverityError, "", "limits.leads_per_hour cannot be negative")
+	}
+	if campaign.Limits.DailyCap < 0 {
+		add(SeverityError, "", "limits.daily_cap cannot be negative")
+	}
+	if campaign.Limits.DailyCap > 0 && campaign.Limits.LeadsPerHour > campaign.Limits.DailyCap {
+		add(SeverityWarning, "", "limits.leads_per_hour (%d) exceeds limits.daily_cap (%d)",
+			campaign.Limits.LeadsPerHour, campaign.Limits.DailyCap)
+	}
+	if campaign.Limits.WeeklyCap < 0 {
+		add(SeverityError, "", "limits.weekly_cap cannot be negative")
+	}
+	if campaign.Limits.WeeklyCap > 0 && campaign.Limits.DailyCap > campaign.Limits.WeeklyCap {
+		add(SeverityWarning, "", "limits.daily_cap (%d) exceeds limits.weekly_cap (%d)",
+			campaign.Limits.DailyCap, campaign.Limits.WeeklyCap)
+	}
+	if campaign.Target < 0 {
+		add(SeverityError, "", "target cannot be negative")
+	}
+	if campaign.Uniqueness.Window < 0 {
+		add(SeverityError, "", "uniqueness.window cannot be negative")
+	}
+	if err := campaign.Invites.Validate(); err != nil {
+		add(SeverityError, "", "%v", err)
+	}
+	if campaign.Searches.Concurrency < 0 {
+		add(SeverityError, "", "searches.concurrency cannot be negative")
+	}
+	if queries := len(campaign.Searches.Queries); queries > 0 && campaign.Searches.Concurrency > queries {
+		add(SeverityWarning, "", "searches.concurrency (%d) exceeds the number of queries (%d)",
+			campaign.Searches.Concurrency, queries)
+	}
+	if campaign.Searches.Network != nil && len(campaign.Searches.Queries) > 0 {
+		add(SeverityError, "", "searches.network cannot be combined with searches.queries")
+	}
+	if campaign.Searches.Network != nil && campaign.Searches.Network.DormantMonths < 0 {
+		add(SeverityError, "", "searches.network.dormant_months cannot be negative")
+	}
+	concurrency := campaign.Concurrency
+	if concurrency.MaxPages < 0 || concurrency.Workers < 0 || concurrency.QueueDepth < 0 {
+		add(SeverityError, "", "concurrency settings cannot be negative")
+	}
+	if concurrency.MaxPages > 0 {
+		pages := 0
+		for _, config := range campaign.Steps {
+			if pageStepTypes[config.Type] {
+				pages = 1
+				break
+			}
+		}
+		if len(campaign.Searches.Queries) > 0 && concurrency.MaxPages <= pages {
+			add(SeverityError, "", "concurrency.max_pages (%d) leaves no page for searches next to the page invite and message steps share",
+				concurrency.MaxPages)
+		} else if searchPages := concurrency.SearchConcurrency(campaign.Searches.Concurrency, pages); campaign.Searches.Concurrency > searchPages {
+			add(SeverityWarning, "", "searches.concurrency (%d) is held to %d by concurrency.max_pages",
+				campaign.Searches.Concurrency, searchPages)
+		}
+	}
+	queryNames := make(map[string]bool)
+	for i, query := range campaign.Searches.Queries {
+		label := query.Name
+		if label == "" {
+			label = fmt.Sprintf("#%d", i+1)
+		}
+		if query.Query == "" && query.Name == "" {
+			add(SeverityError, "", "search %s needs a query or the name of a saved search", label)
+		}
+		if query.MaxResults < 0 {
+			add(SeverityError, "", "search %s: max_results cannot be negative", label)
+		}
+		if query.Name != "" {
+			if queryNames[query.Name] {
+				add(SeverityError, "", "duplicate search name %q", query.Name)
+			}
+			queryNames[query.Name] = true
+		}
+	}
+	if len(campaign.Steps) == 0 {
+		add(SeverityError, "", "campaign has no steps")
+		return issues
+	}
+
+	ids := make(map[string]bool)
+	for _, config := range campaign.Steps {
+		if ids[config.ID] {
+			add(SeverityError, config.ID, "duplicate step id")
+		}
+		ids[config.ID] = true
+
+		if config.Type == "" {
+			add(SeverityError, config.ID, "step has no type")
+		} else if registry != nil && !registry.Has(config.Type) {
+			add(SeverityError, config.ID, "unknown step type %q", config.Type)
+		}
+		if config.Type == StepTypePlugin && config.Command == "" {
+			add(SeverityError, config.ID, "plugin step requires a command")
+		}
+		if config.Timeout < 0 {
+			add(SeverityError, config.ID, "timeout cannot be negative")
+		}
+		for _, text := range stepTemplates(config) {
+			if _, err := parseTemplate(config.ID, text); err != nil {
+				add(SeverityError, config.ID, "%v", err)
+			}
+		}
+		if campaign.Uniqueness.Window > 0 && pageStepTypes[config.Type] {
+			for _, text := range append(openProfileTemplates(config), localizedTemplates(config)...) {
+				if !strings.Contains(text, "{") {
+					add(SeverityWarning, config.ID, "template has neither spintax nor variables, so uniqueness.window lets it go to one lead at a time")
+					break
+				}
+			}
+		}
+		if config.Type == StepTypeInvite && campaign.Invites.Note == NoteNever && (config.Template != "" || len(config.Localized) > 0) {
+			add(SeverityWarning, config.ID, "template is never sent because invites.note is %q", NoteNever)
+		}
+		if config.Type == StepTypeOpenProfile && len(openProfileTemplates(config)) == 0 {
+			add(SeverityError, config.ID, "open profile step requires a template or templates")
+		}
+		if config.Type == StepTypeMessage {
+			if len(openProfileTemplates(config)) == 0 {
+				add(SeverityError, config.ID, "message step requires a template or templates")
+			}
+			if campaign.Searches.Network == nil {
+				add(SeverityWarning, config.ID, "message step only messages first-degree connections, which come from searches.network")
+			}
+		}
+		for _, code := range localizedCodes(config) {
+			if !language.IsSupported(code) {
+				add(SeverityWarning, config.ID, "language %q is never detected, only set through the language attribute", code)
+			}
+		}
+		if len(config.Localized) > 0 && config.Localized[language.English] == "" && config.Template == "" && len(config.Templates) == 0 {
+			add(SeverityWarning, config.ID, "localized templates have no English or default template to fall back to")
+		}
+		if config.Limits.LeadsPerHour < 0 || config.Limits.DailyCap < 0 {
+			add(SeverityError, config.ID, "step limits cannot be negative")
+		}
+		if config.Workers < 0 {
+			add(SeverityError, config.ID, "workers cannot be negative")
+		} else if config.Workers > 1 && pageStepTypes[config.Type] {
+			add(SeverityWarning, config.ID, "workers is ignored, steps on the browser page take one lead at a time")
+		} else if config.Workers > 1 && config.Workers > concurrency.Workers {
+			add(SeverityWarning, config.ID, "workers (%d) exceeds concurrency.workers (%d), so the extra workers stay idle",
+				config.Workers, concurrency.Workers)
+		}
+	}
+
+	for _, config := range campaign.Steps {
+		if config.Next != "" && !ids[config.Next] {
+			add(SeverityError, config.ID, "references unknown next step %q", config.Next)
+		}
+	}
+	if HasErrors(issues) {
+		// The walk below assumes unique, resolvable step references
+		return issues
+	}
+
+	sequence, cyclic := stepSequence(campaign)
+	if cyclic {
+		add(SeverityError, "", "next references form a cycle: %s", strings.Join(sequence, " -> "))
+	}
+
+	reached := make(map[string]bool)
+	for _, id := range sequence {
+		reached[id] = true
+	}
+	for _, config := range campaign.Steps {
+		if !reached[config.ID] {
+			add(SeverityWarning, config.ID, "step is unreachable")
+		}
+	}
+
+	// Attributes become available once an earlier step on the path declares them as outputs
+	fields := leadFields()
+	available := make(map[string]bool)
+	if campaign.Searches.Network != nil {
+		for _, attribute := range networkAttributes {
+			available[attribute] = true
+		}
+	}
+	index := stepIndex(campaign)
+	for position, id := range sequence {
+		if cyclic && position == len(sequence)-1 {
+			break
+		}
+		config := campaign.Steps[index[id]]
+		for _, text := range stepTemplates(config) {
+			usedFields, usedAttributes, err := TemplateVariables(text)
+			if err == nil {
+				for _, field := range usedFields {
+					if !fields[field] {
+						add(SeverityError, id, "template references unknown lead field %q", field)
+					}
+				}
+				for _, attribute := range usedAttributes {
+					if !available[attribute] {
+						add(SeverityError, id, "template references attribute %q before any step outputs it", attribute)
+					}
+				}
+			}
+		}
+		for _, output := range config.Outputs {
+			available[output] = true
+		}
+	}
+
+	return issues
+}
+
+// stepTemplates lists every non-empty template a step renders: template, its variants, the
+// localized templates and subject
+func stepTemplates(config StepConfig) []string {
+	templates := append(openProfileTemplates(config), localizedTemplates(config)...)
+	if config.Subject != "" {
+		templates = append(templates, config.Subject)
+	}
+	return templates
+}
+
+// stepIndex maps step IDs to their position in the campaign
+func stepIndex(campaign *Campaign) map[string]int {
+	index := make(map[string]int, len(campaign.Steps))
+	for i, config := range campaign.Steps {
+		index[config.ID] = i
+	}
+	return index
+}
+
+// stepSequence lists the steps a lead visits when every step continues; a repeated
+// step ends the sequence and marks it cyclic
+func stepSequence(campaign *Campaign) ([]string, bool) {
+	if len(campaign.Steps) == 0 {
+		return nil, false
+	}
+
+	index := stepIndex(campaign)
+	visited := make(map[string]bool)
+	var sequence []string
+	for current := campaign.Steps[0].ID; current != ""; {
+		sequence = append(sequence, current)
+		if visited[current] {
+			return sequence, true
+		}
+		visited[current] = true
+
+		position := index[current]
+		if next := campaign.Steps[position].Next; next != "" {
+			current = next
+		} else if position+1 < len(campaign.Steps) {
+			current = campaign.Steps[position+1].ID
+		} else {
+			current = ""
+		}
+	}
+	return sequence, false
+}
@@ -0,0 +1,84 @@
+package campaign
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"linkedin-automation-framework/internal/language"
+)
+
+// Lead attributes read and written by localized steps
+const (
+	AttributeLanguage = "language" // Set by a plugin or an earlier step to override detection
+	AttributeAbout    = "about"    // Profile About text, when a plugin has enriched the lead with it
+)
+
+// LeadLanguage returns the lead's language: the "language" attribute if set, otherwise the
+// language of the headline and About text, otherwise the language of the location
+func LeadLanguage(lead *Lead) string {
+	if code := strings.ToLower(lead.Attributes[AttributeLanguage]); code != "" {
+		return code
+	}
+	return language.Detect(lead.Title+"\n"+lead.Attributes[AttributeAbout], lead.Location)
+}
+
+// localize picks the localized template for the lead's language, then the English one, and
+// returns fallback when the step has neither. The language is "" when fallback is used.
+func localize(localized map[string]string, fallback string, lead *Lead) (string, string) {
+	if len(localized) == 0 {
+		return fallback, ""
+	}
+	if code := LeadLanguage(lead); localized[code] != "" {
+		return localized[code], code
+	}
+	if localized[language.English] != "" {
+		return localized[language.English], language.English
+	}
+	return fallback, ""
+}
+
+// localizedCodes lists the languages a step has templates for, sorted
+func localizedCodes(config StepConfig) []string {
+	codes := make([]string, 0, len(config.Localized))
+	for code := range config.Localized {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// localizedTemplates lists a step's localized templates ordered by language code
+func localizedTemplates(config StepConfig) []string {
+	var templates []string
+	for _, code := range localizedCodes(config) {
+		templates = append(templates, config.Localized[code])
+	}
+	return templates
+}
+
+// loadTemplateFiles reads a step's template files, resolved against dir, into its localized templates
+func loadTemplateFiles(config *StepConfig, dir string) error {
+	if len(config.TemplateFiles) == 0 {
+		return nil
+	}
+	if config.Localized == nil {
+		config.Localized = make(map[string]string, len(config.TemplateFiles))
+	}
+	for code, file := range config.TemplateFiles {
+		if _, inline := config.Localized[code]; inline {
+			return fmt.Errorf("step %q sets language %q both inline and as a file", config.ID, code)
+		}
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s template of step %q: %w", code, config.ID, err)
+		}
+		config.Localized[code] = strings.TrimRight(string(data), "\n")
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package campaign
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// StepTypeMessage is the step type that messages a lead who is already a first-degree connection
+const StepTypeMessage = "message"
+
+// Lead attributes describing how the lead relates to the account; network leads have them all
+// from the start, the dates when known
+const (
+	AttributeDegree         = "degree"          // "1st" for leads taken from the account's own network
+	AttributeConnectedOn    = "connected_on"    // Date the connection was made, as 2006-01-02
+	AttributeYearsConnected = "years_connected" // Whole years since the connection was made
+	AttributeLastContact    = "last_contact"    // Date of the last message sent or the connection, whichever is later
+	DegreeFirst             = "1st"
+)
+
+// networkAttributes are the attributes every network lead may carry, for lint
+var networkAttributes = []string{AttributeDegree, AttributeConnectedOn, AttributeYearsConnected, AttributeLastContact}
+
+// NetworkSearch fills the lead pool from the imported first-degree connections instead of a
+// people search, for re-engagement campaigns. Each list matches when any of its entries does,
+// and a connection must match every list that is set.
+type NetworkSearch struct {
+	Titles    []string `yaml:"titles"`    // Words or phrases in the connection's position
+	Companies []string `yaml:"companies"` // Words or phrases in the connection's company
+	Tags      []string `yaml:"tags"`      // Tags given with the connections tag command
+
+	// DormantMonths keeps only connections with no contact in this many months: nothing sent
+	// to them and no connection made since. 0 keeps every connection.
+	DormantMonths int `yaml:"dormant_months"`
+}
+
+// NetworkLeadAttributes returns the attributes of a lead taken from the network. Zero dates,
+// e.g. from an export without "Connected On", are left out.
+func NetworkLeadAttributes(connectedOn, lastContact, now time.Time) map[string]string {
+	attributes := map[string]string{AttributeDegree: DegreeFirst}
+	if !connectedOn.IsZero() {
+		years := now.Year() - connectedOn.Year()
+		if now.Before(connectedOn.AddDate(years, 0, 0)) {
+			years--
+		}
+		attributes[AttributeConnectedOn] = connectedOn.Format("2006-01-02")
+		attributes[AttributeYearsConnected] = strconv.Itoa(max(years, 0))
+	}
+	if !lastContact.IsZero() {
+		attributes[AttributeLastContact] = lastContact.Format("2006-01-02")
+	}
+	return attributes
+}
+
+// Messenger messages first-degree connections for message steps
+type Messenger interface {
+	MessageConnection(ctx context.Context, lead *Lead, body string) error
+}
+
+// NewMessageStepFactory returns a factory building message steps that send through messenger.
+// A message step continues after sending, so later steps can follow up on the conversation, and
+// once its own limits are used up it continues without sending. A messenger that is a
+// UniquenessChecker gets messages no other recipient got, or the lead is skipped.
+func NewMessageStepFactory(messenger Messenger) StepFactory {
+	checker, _ := messenger.(UniquenessChecker)
+	return func(config StepConfig) (Step, error) {
+		if messenger == nil {
+			return nil, fmt.Errorf("message step %q needs a browser session", config.ID)
+		}
+		templates := openProfileTemplates(config)
+		if len(templates) == 0 {
+			return nil, fmt.Errorf("message step %q requires a template or templates", config.ID)
+		}
+		for _, text := range append(templates, localizedTemplates(config)...) {
+			if _, err := parseTemplate(config.ID, text); err != nil {
+				return nil, err
+			}
+		}
+		limiter := newSendLimiter(config.Limits)
+
+		return StepFunc(func(ctx context.Context, lead *Lead) (StepResult, error) {
+			if lead.Attributes[AttributeDegree] != DegreeFirst {
+				return StepResult{
+					Outcome:    OutcomeContinue,
+					Reason:     "not a first-degree connection",
+					Attributes: map[string]string{"messaged": "false"},
+				}, nil
+			}
+
+			if reason := limiter.reserve("message"); reason != "" {
+				return StepResult{Outcome: OutcomeContinue, Reason: reason, Attributes: map[string]string{"messaged": "false"}}, nil
+			}
+
+			attributes := map[string]string{"messaged": "true"}
+			text, code := localize(config.Localized, "", lead)
+			if code != "" {
+				attributes[AttributeLanguage] = code
+			} else {
+				variant := pickVariant(lead.ProfileURL, len(templates))
+				text = templates[variant]
+				attributes["message_variant"] = strconv.Itoa(variant + 1)
+			}
+			body, unique, err := renderUnique(text, lead, checker)
+			if err != nil {
+				limiter.release()
+				return StepResult{}, err
+			}
+			if !unique {
+				limiter.release()
+				return StepResult{Outcome: OutcomeSkip, Reason: ReasonNoUniqueText, Attributes: map[string]string{"messaged": "false"}}, nil
+			}
+			if err := messenger.MessageConnection(withTemplate(ctx, text, code), lead, body); err != nil {
+				limiter.release()
+				return StepResult{}, fmt.Errorf("failed to message connection: %w", err)
+			}
+			return StepResult{Outcome: OutcomeContinue, Message: body, Attributes: attributes}, nil
+		}), nil
+	}
+}
@@ -0,0 +1,190 @@
+package campaign
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// StepTypeOpenProfile is the step type that messages Open Profile members instead of inviting them
+const StepTypeOpenProfile = "open_profile_message"
+
+// OpenProfileMessenger checks profiles for Open Profile and sends them free messages
+type OpenProfileMessenger interface {
+	IsOpenProfile(ctx context.Context, lead *Lead) (bool, error)
+	SendOpenProfileMessage(ctx context.Context, lead *Lead, subject, body string) error
+}
+
+// OpenProfileStep messages leads with an Open Profile and skips their remaining steps, so they
+// are not also invited. Other leads, and Open Profiles once the step's limits are used up,
+// continue to the next step with the "open_profile" attribute set.
+type OpenProfileStep struct {
+	id        string
+	messenger OpenProfileMessenger
+	subject   string
+	templates []string
+	localized map[string]string
+	limiter   *sendLimiter
+}
+
+// NewOpenProfileStepFactory returns a factory building open profile steps that send through
+// messenger. A messenger that is a UniquenessChecker gets messages no other recipient got, or
+// the lead is skipped.
+func NewOpenProfileStepFactory(messenger OpenProfileMessenger) StepFactory {
+	return func(config StepConfig) (Step, error) {
+		if messenger == nil {
+			return nil, fmt.Errorf("open profile step %q needs a browser session", config.ID)
+		}
+		templates := openProfileTemplates(config)
+		if len(templates) == 0 {
+			return nil, fmt.Errorf("open profile step %q requires a template or templates", config.ID)
+		}
+		for _, text := range append(append([]string{config.Subject}, templates...), localizedTemplates(config)...) {
+			if _, err := parseTemplate(config.ID, text); err != nil {
+				return nil, err
+			}
+		}
+		return &OpenProfileStep{
+			id:        config.ID,
+			messenger: messenger,
+			subject:   config.Subject,
+			templates: templates,
+			localized: config.Localized,
+			limiter:   newSendLimiter(config.Limits),
+		}, nil
+	}
+}
+
+// openProfileTemplates lists the step's message variants, template first
+func openProfileTemplates(config StepConfig) []string {
+	var templates []string
+	if config.Template != "" {
+		templates = append(templates, config.Template)
+	}
+	return append(templates, config.Templates...)
+}
+
+// Run messages the lead if it has an Open Profile and the step's limits allow
+func (s *OpenProfileStep) Run(ctx context.Context, lead *Lead) (StepResult, error) {
+	open, err := s.messenger.IsOpenProfile(ctx, lead)
+	if err != nil {
+		return StepResult{}, fmt.Errorf("failed to check for an open profile: %w", err)
+	}
+	if !open {
+		return StepResult{Outcome: OutcomeContinue, Attributes: map[string]string{"open_profile": "false"}}, nil
+	}
+
+	if reason := s.limiter.reserve("open profile"); reason != "" {
+		return StepResult{
+			Outcome:    OutcomeContinue,
+			Reason:     reason,
+			Attributes: map[string]string{"open_profile": "true", "open_profile_messaged": "false"},
+		}, nil
+	}
+
+	attributes := map[string]string{"open_profile": "true", "open_profile_messaged": "true"}
+	// A localized template replaces the variants, which are the English fallback
+	text, code := localize(s.localized, "", lead)
+	if code != "" {
+		attributes[AttributeLanguage] = code
+	} else {
+		variant := pickVariant(lead.ProfileURL, len(s.templates))
+		text = s.templates[variant]
+		attributes["open_profile_variant"] = strconv.Itoa(variant + 1)
+	}
+	checker, _ := s.messenger.(UniquenessChecker)
+	body, unique, err := renderUnique(text, lead, checker)
+	if err != nil {
+		s.limiter.release()
+		return StepResult{}, err
+	}
+	if !unique {
+		s.limiter.release()
+		attributes["open_profile_messaged"] = "false"
+		return StepResult{Outcome: OutcomeSkip, Reason: ReasonNoUniqueText, Attributes: attributes}, nil
+	}
+	subject, err := RenderTemplate(s.subject, lead)
+	if err != nil {
+		s.limiter.release()
+		return StepResult{}, err
+	}
+	if err := s.messenger.SendOpenProfileMessage(withTemplate(ctx, text, code), lead, subject, body); err != nil {
+		s.limiter.release()
+		return StepResult{}, fmt.Errorf("failed to message open profile: %w", err)
+	}
+
+	return StepResult{
+		Outcome:    OutcomeSkip,
+		Reason:     "messaged as an open profile instead of inviting",
+		Message:    body,
+		Attributes: attributes,
+	}, nil
+}
+
+// sendLimiter holds a step's sends to its own hourly and daily limits
+type sendLimiter struct {
+	limits RateConfig
+	now    func() time.Time
+
+	mutex sync.Mutex
+	sent  []time.Time
+}
+
+// newSendLimiter creates a limiter for a step's limits
+func newSendLimiter(limits RateConfig) *sendLimiter {
+	return &sendLimiter{limits: limits, now: time.Now}
+}
+
+// reserve counts a send against the hourly and daily limits, returning why it cannot be made,
+// with kind naming the sends in the reason
+func (s *sendLimiter) reserve(kind string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	lastHour, lastDay := 0, 0
+	kept := s.sent[:0]
+	for _, at := range s.sent {
+		if now.Sub(at) >= 24*time.Hour {
+			continue
+		}
+		kept = append(kept, at)
+		lastDay++
+		if now.Sub(at) < time.Hour {
+			lastHour++
+		}
+	}
+	s.sent = kept
+
+	if s.limits.DailyCap > 0 && lastDay >= s.limits.DailyCap {
+		return kind + " daily cap reached"
+	}
+	if s.limits.LeadsPerHour > 0 && lastHour >= s.limits.LeadsPerHour {
+		return kind + " hourly limit reached"
+	}
+	s.sent = append(s.sent, now)
+	return ""
+}
+
+// release returns the last reserved send after a failure
+func (s *sendLimiter) release() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.sent) > 0 {
+		s.sent = s.sent[:len(s.sent)-1]
+	}
+}
+
+// pickVariant chooses a template variant from the profile URL, so a lead always gets the same one
+func pickVariant(profileURL string, variants int) int {
+	if variants <= 1 {
+		return 0
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(profileURL))
+	return int(hash.Sum32() % uint32(variants))
+}
@@ -0,0 +1,157 @@
+package campaign
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxPlanDays bounds a forecast, so a target the caps cannot reach in a reasonable time ends
+// the forecast instead of planning forever
+const maxPlanDays = 3 * 366
+
+// Plan day reasons, next to the reason of a blackout
+const (
+	PlanNoRunDay  = "not a campaign day"
+	PlanWeeklyCap = "weekly cap reached"
+)
+
+// weekdayNames are the weekdays ParseWeekdays accepts, by their three-letter names
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// PlanOptions is what a forecast assumes about when and how fast the campaign runs. Daily caps
+// bound each run, so the forecast assumes one run on every campaign day.
+type PlanOptions struct {
+	Start        time.Time      // First day leads may run
+	Target       int            // Leads to process
+	Limits       RateConfig     // The campaign's caps
+	Hours        int            // Hours a day leads run, which bounds leads_per_hour; 24 unless set
+	Weekdays     []time.Weekday // Days the campaign runs; every day unless set
+	UsedThisWeek int            // Leads already processed in Start's week, counted against the weekly cap
+
+	// Closed reports days nothing runs, such as blackouts, and why; nil for none
+	Closed func(day time.Time) (reason string, closed bool)
+}
+
+// PlanDay is one day of a forecast
+type PlanDay struct {
+	Date   time.Time `json:"date"`
+	Leads  int       `json:"leads"`            // Leads processed that day
+	Total  int       `json:"total"`            // Leads processed by the end of the day
+	Reason string    `json:"reason,omitempty"` // Why no leads run that day
+}
+
+// Forecast is the day a campaign reaches its target and what it processes each day until then
+type Forecast struct {
+	Target  int       `json:"target"`
+	PerDay  int       `json:"per_day"`  // Most leads a day takes, 0 for unlimited
+	PerWeek int       `json:"per_week"` // Most leads a week takes, 0 for unlimited
+	Finish  time.Time `json:"finish"`   // Day the last lead runs; zero when the target is out of the forecast's reach
+	Days    []PlanDay `json:"days"`
+}
+
+// Plan forecasts the days the campaign takes to process the target, from the start's day until
+// the target is reached or maxPlanDays have passed
+func Plan(options PlanOptions) (Forecast, error) {
+	limits := options.Limits
+	if options.Target <= 0 {
+		return Forecast{}, fmt.Errorf("target must be positive, got %d", options.Target)
+	}
+	if limits.DailyCap < 0 || limits.WeeklyCap < 0 || limits.LeadsPerHour < 0 {
+		return Forecast{}, fmt.Errorf("limits cannot be negative")
+	}
+	if options.Hours < 0 || options.Hours > 24 {
+		return Forecast{}, fmt.Errorf("hours must be between 0 and 24, got %d", options.Hours)
+	}
+
+	hours := options.Hours
+	if hours == 0 {
+		hours = 24
+	}
+	perDay := limits.DailyCap
+	if limits.LeadsPerHour > 0 && (perDay == 0 || limits.LeadsPerHour*hours < perDay) {
+		perDay = limits.LeadsPerHour * hours
+	}
+	runDays := make(map[time.Weekday]bool, len(options.Weekdays))
+	for _, weekday := range options.Weekdays {
+		runDays[weekday] = true
+	}
+
+	forecast := Forecast{Target: options.Target, PerDay: perDay, PerWeek: limits.WeeklyCap}
+	start := options.Start
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	week, used, total := WeekStart(day), options.UsedThisWeek, 0
+	for i := 0; total < options.Target && i < maxPlanDays; i++ {
+		date := day.AddDate(0, 0, i)
+		if monday := WeekStart(date); !monday.Equal(week) {
+			week, used = monday, 0
+		}
+
+		entry := PlanDay{Date: date}
+		reason, closed := "", false
+		if options.Closed != nil {
+			reason, closed = options.Closed(date)
+		}
+		switch {
+		case len(runDays) > 0 && !runDays[date.Weekday()]:
+			entry.Reason = PlanNoRunDay
+		case closed:
+			entry.Reason = reason
+		case limits.WeeklyCap > 0 && used >= limits.WeeklyCap:
+			entry.Reason = PlanWeeklyCap
+		default:
+			entry.Leads = options.Target - total
+			if perDay > 0 {
+				entry.Leads = min(entry.Leads, perDay)
+			}
+			if limits.WeeklyCap > 0 {
+				entry.Leads = min(entry.Leads, limits.WeeklyCap-used)
+			}
+		}
+		total += entry.Leads
+		used += entry.Leads
+		entry.Total = total
+		forecast.Days = append(forecast.Days, entry)
+	}
+	if total >= options.Target {
+		forecast.Finish = forecast.Days[len(forecast.Days)-1].Date
+	}
+	return forecast, nil
+}
+
+// WeekStart returns the midnight starting t's week, which runs Monday to Sunday
+func WeekStart(t time.Time) time.Time {
+	sinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-sinceMonday, 0, 0, 0, 0, t.Location())
+}
+
+// ParseWeekdays parses a list of weekdays such as "mon,wed,fri", where ranges like "mon-fri"
+// include every day from the first to the last
+func ParseWeekdays(value string) ([]time.Weekday, error) {
+	var weekdays []time.Weekday
+	seen := make(map[time.Weekday]bool)
+	for _, entry := range strings.Split(value, ",") {
+		first, last, isRange := strings.Cut(strings.ToLower(strings.TrimSpace(entry)), "-")
+		if !isRange {
+			last = first
+		}
+		from, ok := weekdayNames[first]
+		to, okLast := weekdayNames[last]
+		if !ok || !okLast {
+			return nil, fmt.Errorf("invalid weekdays %q: use names like mon, tue or ranges like mon-fri", entry)
+		}
+		for weekday := from; ; weekday = (weekday + 1) % 7 {
+			if !seen[weekday] {
+				seen[weekday] = true
+				weekdays = append(weekdays, weekday)
+			}
+			if weekday == to {
+				break
+			}
+		}
+	}
+	return weekdays, nil
+}
@@ -0,0 +1,137 @@
+package campaign
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// StepTypePlugin is the step type for external executable plugins
+const StepTypePlugin = "plugin"
+
+// defaultPluginTimeout bounds a plugin invocation when the step sets no timeout
+const defaultPluginTimeout = 30 * time.Second
+
+// PluginRequest is written as JSON to the plugin's stdin
+type PluginRequest struct {
+	Step     string            `json:"step"`
+	Params   map[string]string `json:"params,omitempty"`
+	Message  string            `json:"message,omitempty"`  // Step template rendered for the lead
+	Language string            `json:"language,omitempty"` // Language of the localized template used for message, if any
+	Lead     Lead              `json:"lead"`
+}
+
+// PluginResponse is read as JSON from the plugin's stdout
+type PluginResponse struct {
+	Outcome    Outcome           `json:"outcome"`
+	Reason     string            `json:"reason,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// PluginStep runs an external executable for each lead.
+// The executable receives a PluginRequest on stdin and must print a
+// PluginResponse on stdout, which lets users add steps such as CRM checks
+// or enrichment calls in any language without modifying this codebase.
+type PluginStep struct {
+	id        string
+	command   string
+	args      []string
+	params    map[string]string
+	template  string
+	localized map[string]string
+	timeout   time.Duration
+}
+
+// NewPluginStep creates a plugin step from its configuration
+func NewPluginStep(config StepConfig) (Step, error) {
+	if config.Command == "" {
+		return nil, fmt.Errorf("plugin step %q requires a command", config.ID)
+	}
+
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = defaultPluginTimeout
+	}
+
+	for _, text := range append([]string{config.Template}, localizedTemplates(config)...) {
+		if _, err := parseTemplate(config.ID, text); err != nil {
+			return nil, err
+		}
+	}
+
+	return &PluginStep{
+		id:        config.ID,
+		command:   config.Command,
+		args:      config.Args,
+		params:    config.Params,
+		template:  config.Template,
+		localized: config.Localized,
+		timeout:   timeout,
+	}, nil
+}
+
+// Run executes the plugin process for the lead
+func (ps *PluginStep) Run(ctx context.Context, lead *Lead) (StepResult, error) {
+	text, code := localize(ps.localized, ps.template, lead)
+	message, err := RenderTemplate(text, lead)
+	if err != nil {
+		return StepResult{}, err
+	}
+
+	request := PluginRequest{
+		Step:     ps.id,
+		Params:   ps.params,
+		Message:  message,
+		Language: code,
+		Lead:     *lead,
+	}
+	input, err := json.Marshal(request)
+	if err != nil {
+		return StepResult{}, fmt.Errorf("failed to marshal plugin request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ps.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, ps.command, ps.args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return StepResult{}, fmt.Errorf("plugin %s timed out after %v", ps.command, ps.timeout)
+		}
+		return StepResult{}, fmt.Errorf("plugin %s failed: %w (stderr: %s)", ps.command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var response PluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return StepResult{}, fmt.Errorf("failed to parse plugin response: %w", err)
+	}
+
+	if response.Error != "" {
+		return StepResult{}, fmt.Errorf("plugin %s reported error: %s", ps.command, response.Error)
+	}
+
+	switch response.Outcome {
+	case "":
+		response.Outcome = OutcomeContinue
+	case OutcomeContinue, OutcomeSkip:
+	default:
+		return StepResult{}, fmt.Errorf("plugin %s returned unknown outcome %q", ps.command, response.Outcome)
+	}
+
+	return StepResult{
+		Outcome:    response.Outcome,
+		Reason:     response.Reason,
+		Message:    message,
+		Attributes: response.Attributes,
+	}, nil
+}
@@ -0,0 +1,88 @@
+package campaign
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrNotRun is reported for leads a stopped pool dropped from its queue without running them
+var ErrNotRun = errors.New("lead not run, the campaign stopped")
+
+// LeadDone receives a lead with its step records once it has run
+type LeadDone func(lead *Lead, records []StepRecord, err error)
+
+// Pool runs leads through a runner on a fixed number of workers. Leads wait in a queue of bounded
+// depth and Submit blocks while it is full, so leads are fed no faster than the workers finish them.
+type Pool struct {
+	runner  *Runner
+	jobs    chan *Lead
+	done    LeadDone
+	stopped atomic.Bool
+	wg      sync.WaitGroup
+	mutex   sync.Mutex // Serializes calls to done
+}
+
+// NewPool starts the workers concurrency asks for. done is called for every submitted lead, for
+// one lead at a time, so it needs no locking of its own.
+func NewPool(ctx context.Context, runner *Runner, concurrency ConcurrencyConfig, done LeadDone) *Pool {
+	workers := concurrency.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	queueDepth := concurrency.QueueDepth
+	if queueDepth < 0 {
+		queueDepth = 0
+	}
+
+	p := &Pool{runner: runner, jobs: make(chan *Lead, queueDepth), done: done}
+	for worker := 0; worker < workers; worker++ {
+		p.wg.Add(1)
+		go p.work(ctx)
+	}
+	return p
+}
+
+// work runs queued leads until the queue is closed
+func (p *Pool) work(ctx context.Context) {
+	defer p.wg.Done()
+
+	for lead := range p.jobs {
+		var records []StepRecord
+		err := ErrNotRun
+		if !p.stopped.Load() {
+			records, err = p.runner.RunLead(ctx, lead)
+		}
+
+		p.mutex.Lock()
+		p.done(lead, records, err)
+		p.mutex.Unlock()
+	}
+}
+
+// Submit queues a lead, waiting while the queue is full. It returns ErrNotRun once the pool is
+// stopped and ctx's error once it ends; the lead is not queued then.
+func (p *Pool) Submit(ctx context.Context, lead *Lead) error {
+	if p.stopped.Load() {
+		return ErrNotRun
+	}
+	select {
+	case p.jobs <- lead:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stop stops the pool taking leads. Leads still queued are reported to done with ErrNotRun, and
+// leads already running finish. It may be called from done.
+func (p *Pool) Stop() {
+	p.stopped.Store(true)
+}
+
+// Close waits for every queued and running lead to be done
+func (p *Pool) Close() {
+	close(p.jobs)
+	p.wg.Wait()
+}
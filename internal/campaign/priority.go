@@ -0,0 +1,90 @@
+package campaign
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Lead attributes users and inbound systems set to move a lead up or down the pool, e.g. with
+// "leads set" or the commands endpoint
+const (
+	AttributePriority = "priority" // A number, or one of high, normal and low
+	AttributeSignal   = "signal"   // Inbound signals, comma-separated, e.g. viewed_profile
+)
+
+// Named priorities the priority attribute may take instead of a number; a lead with an inbound
+// signal is PriorityHigh unless the campaign gives the signal another priority
+const (
+	PriorityLow    = -10
+	PriorityNormal = 0
+	PriorityHigh   = 10
+)
+
+// PriorityConfig orders the lead pool so the campaign's limits go to the leads that matter most.
+// A lead's priority attribute wins over its signals; leads of equal priority keep the pool's order,
+// queued leads first, unless Score ranks them.
+type PriorityConfig struct {
+	Score   bool           `yaml:"score"`   // Rank leads of equal priority by the lead filter's score, highest first
+	Signals map[string]int `yaml:"signals"` // Priority of each inbound signal, e.g. viewed_profile: 20
+}
+
+// Rank is where a lead stands in the pool
+type Rank struct {
+	Priority int
+	Score    float64 // The lead filter's score, when the campaign ranks by it
+}
+
+// Priority returns the priority of a lead with the given attributes: its priority attribute
+// when set, else the highest priority of its signals, else PriorityNormal
+func (c PriorityConfig) Priority(attributes map[string]string) (int, error) {
+	if value := strings.TrimSpace(attributes[AttributePriority]); value != "" {
+		return ParsePriority(value)
+	}
+	priority, signalled := PriorityNormal, false
+	for _, signal := range strings.Split(attributes[AttributeSignal], ",") {
+		signal = strings.TrimSpace(signal)
+		if signal == "" {
+			continue
+		}
+		signalPriority, ok := c.Signals[signal]
+		if !ok {
+			signalPriority = PriorityHigh
+		}
+		if !signalled || signalPriority > priority {
+			priority, signalled = signalPriority, true
+		}
+	}
+	return priority, nil
+}
+
+// ParsePriority reads a priority attribute: a whole number, or high, normal or low
+func ParsePriority(value string) (int, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "high":
+		return PriorityHigh, nil
+	case "normal":
+		return PriorityNormal, nil
+	case "low":
+		return PriorityLow, nil
+	}
+	priority, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid priority %q: expected a whole number, high, normal or low", value)
+	}
+	return priority, nil
+}
+
+// SortByRank orders items highest priority first, then highest score; items of equal rank keep
+// their order
+func SortByRank[T any](items []T, rank func(T) Rank) {
+	slices.SortStableFunc(items, func(a, b T) int {
+		ra, rb := rank(a), rank(b)
+		if c := cmp.Compare(rb.Priority, ra.Priority); c != 0 {
+			return c
+		}
+		return cmp.Compare(rb.Score, ra.Score)
+	})
+}
@@ -0,0 +1,52 @@
+package campaign
+
+import (
+	"context"
+	"fmt"
+)
+
+// NewSimulator builds a runner whose steps perform no external actions: each step renders
+// its template, fills its declared outputs with placeholders and continues, so a campaign's
+// state machine can be walked against real leads without launching plugins or a browser
+func NewSimulator(campaign *Campaign) (*Runner, error) {
+	if campaign == nil {
+		return nil, fmt.Errorf("campaign cannot be nil")
+	}
+
+	registry := &Registry{factories: make(map[string]StepFactory)}
+	for _, config := range campaign.Steps {
+		registry.Register(config.Type, newDryStep)
+	}
+	return NewRunner(campaign, registry)
+}
+
+// newDryStep creates the side-effect free stand-in for a configured step
+func newDryStep(config StepConfig) (Step, error) {
+	for _, text := range append([]string{config.Template}, localizedTemplates(config)...) {
+		if _, err := parseTemplate(config.ID, text); err != nil {
+			return nil, err
+		}
+	}
+
+	return StepFunc(func(ctx context.Context, lead *Lead) (StepResult, error) {
+		text, _ := localize(config.Localized, config.Template, lead)
+		message, err := RenderTemplate(text, lead)
+		if err != nil {
+			return StepResult{}, err
+		}
+
+		var attributes map[string]string
+		if len(config.Outputs) > 0 {
+			attributes = make(map[string]string, len(config.Outputs))
+			for _, output := range config.Outputs {
+				attributes[output] = fmt.Sprintf("<%s.%s>", config.ID, output)
+			}
+		}
+
+		return StepResult{
+			Outcome:    OutcomeContinue,
+			Message:    message,
+			Attributes: attributes,
+		}, nil
+	}), nil
+}
@@ -0,0 +1,163 @@
+package campaign
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSpins is how many spintax picks a step tries for a text no other recipient got before it
+// skips the lead
+const maxSpins = 20
+
+// ReasonNoUniqueText is the skip reason of a lead every spin of the template was taken for
+const ReasonNoUniqueText = "no unique text left: every spin of the template went to another recipient recently"
+
+// UniquenessConfig keeps the texts a campaign sends from being byte-identical across recipients,
+// which makes repeated templates easy to fingerprint
+type UniquenessConfig struct {
+	Window time.Duration `yaml:"window"` // How long a sent text stays taken, e.g. "720h"; 0 turns the check off
+}
+
+// UniquenessChecker is implemented by inviters and messengers that keep texts unique. Claim
+// reports whether text is free for the recipient, that is no other recipient got it within the
+// window, and if so reserves it for them.
+type UniquenessChecker interface {
+	Claim(text, profileURL string) (bool, error)
+}
+
+// spinNode is a run of text or a choice between options, each a sequence of nodes
+type spinNode struct {
+	text    string
+	options [][]spinNode
+}
+
+// parseSpintax parses text whose {option A|option B} groups, which may nest, pick one of their
+// options; template actions in {{ }} are text
+func parseSpintax(text string) ([]spinNode, error) {
+	nodes, rest, err := parseSpinSequence(text, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	if rest != len(text) {
+		return nil, fmt.Errorf("invalid spintax: unmatched } at offset %d", rest)
+	}
+	return nodes, nil
+}
+
+// parseSpinSequence parses nodes from offset until the end of text or, in a group, a | or }
+// ending the option, returning the offset it stopped at; outside a group | is text
+func parseSpinSequence(text string, offset int, inGroup bool) ([]spinNode, int, error) {
+	var nodes []spinNode
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			nodes = append(nodes, spinNode{text: literal.String()})
+			literal.Reset()
+		}
+	}
+	for i := offset; i < len(text); {
+		switch {
+		case strings.HasPrefix(text[i:], "{{"):
+			end := strings.Index(text[i:], "}}")
+			if end < 0 {
+				return nil, 0, fmt.Errorf("invalid spintax: unclosed {{ at offset %d", i)
+			}
+			literal.WriteString(text[i : i+end+2])
+			i += end + 2
+		case text[i] == '{':
+			flush()
+			group := spinNode{}
+			j := i + 1
+			for {
+				option, stop, err := parseSpinSequence(text, j, true)
+				if err != nil {
+					return nil, 0, err
+				}
+				if stop >= len(text) {
+					return nil, 0, fmt.Errorf("invalid spintax: unclosed { at offset %d", i)
+				}
+				group.options = append(group.options, option)
+				j = stop + 1
+				if text[stop] == '}' {
+					break
+				}
+			}
+			nodes = append(nodes, group)
+			i = j
+		case text[i] == '}' || (inGroup && text[i] == '|'):
+			flush()
+			return nodes, i, nil
+		default:
+			literal.WriteByte(text[i])
+			i++
+		}
+	}
+	flush()
+	return nodes, len(text), nil
+}
+
+// checkSpintax reports unbalanced spintax groups in a template
+func checkSpintax(text string) error {
+	_, err := parseSpintax(text)
+	return err
+}
+
+// spin expands the spintax of text with a pick of every group seeded by the profile URL and
+// the attempt, so a lead keeps its pick across runs and each attempt can pick another
+func spin(text, profileURL string, attempt int) (string, error) {
+	if !strings.Contains(text, "{") {
+		return text, nil
+	}
+	nodes, err := parseSpintax(text)
+	if err != nil {
+		return "", err
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(profileURL + "#" + strconv.Itoa(attempt)))
+	random := rand.New(rand.NewSource(int64(hash.Sum64())))
+
+	var builder strings.Builder
+	var write func(nodes []spinNode)
+	write = func(nodes []spinNode) {
+		for _, node := range nodes {
+			if node.options == nil {
+				builder.WriteString(node.text)
+				continue
+			}
+			write(node.options[random.Intn(len(node.options))])
+		}
+	}
+	write(nodes)
+	return builder.String(), nil
+}
+
+// renderUnique renders text for the lead, spinning it again until checker claims a text no
+// other recipient got; ok is false when every spin tried was taken. Without a checker it
+// renders like RenderTemplate.
+func renderUnique(text string, lead *Lead, checker UniquenessChecker) (rendered string, ok bool, err error) {
+	if checker == nil {
+		rendered, err := RenderTemplate(text, lead)
+		return rendered, err == nil, err
+	}
+	for attempt := 0; attempt < maxSpins; attempt++ {
+		rendered, err := renderSpin(text, lead, attempt)
+		if err != nil {
+			return "", false, err
+		}
+		if rendered == "" {
+			return "", true, nil
+		}
+		claimed, err := checker.Claim(rendered, lead.ProfileURL)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to check the text is unique: %w", err)
+		}
+		if claimed {
+			return rendered, true, nil
+		}
+	}
+	return "", false, nil
+}
@@ -0,0 +1,147 @@
+package campaign
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// parseTemplate compiles a step template; missing attributes fail rendering instead of printing "<no value>",
+// and names outside the variable catalog fail parsing
+func parseTemplate(stepID, text string) (*template.Template, error) {
+	if err := checkSpintax(text); err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(stepID).Option("missingkey=error").Funcs(variableFuncs(nil)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", unknownVariable(err))
+	}
+	return tmpl, nil
+}
+
+// RenderTemplate renders a step template against the lead, returning "" for an empty template.
+// Spintax groups such as {Hi|Hello} pick the same option for the lead every time.
+func RenderTemplate(text string, lead *Lead) (string, error) {
+	return renderSpin(text, lead, 0)
+}
+
+// renderSpin renders a step template against the lead with the attempt's spintax picks
+func renderSpin(text string, lead *Lead, attempt int) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+
+	text, err := spin(text, lead.ProfileURL, attempt)
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := parseTemplate("message", text)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	if err := tmpl.Funcs(variableFuncs(lead)).Execute(&builder, lead); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return builder.String(), nil
+}
+
+// TemplateVariables lists the lead fields and attributes a template references, counting the
+// attribute behind each attribute-backed catalog variable
+func TemplateVariables(text string) (fields []string, attributes []string, err error) {
+	tmpl, err := parseTemplate("variables", text)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tmpl.Tree == nil {
+		return nil, nil, nil
+	}
+
+	fieldSet := make(map[string]bool)
+	attributeSet := make(map[string]bool)
+	walkTemplate(tmpl.Tree.Root, fieldSet, attributeSet)
+
+	return sortedKeys(fieldSet), sortedKeys(attributeSet), nil
+}
+
+// walkTemplate collects .Field and .Attributes.key references, including index .Attributes "key"
+func walkTemplate(node parse.Node, fields, attributes map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkTemplate(child, fields, attributes)
+		}
+	case *parse.ActionNode:
+		walkTemplate(n.Pipe, fields, attributes)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walkTemplate(cmd, fields, attributes)
+		}
+	case *parse.CommandNode:
+		if len(n.Args) == 3 {
+			if ident, ok := n.Args[0].(*parse.IdentifierNode); ok && ident.Ident == "index" {
+				field, isField := n.Args[1].(*parse.FieldNode)
+				key, isString := n.Args[2].(*parse.StringNode)
+				if isField && isString && len(field.Ident) == 1 && field.Ident[0] == "Attributes" {
+					attributes[key.Text] = true
+					return
+				}
+			}
+		}
+		for _, arg := range n.Args {
+			walkTemplate(arg, fields, attributes)
+		}
+	case *parse.IdentifierNode:
+		if variable, ok := variableNamed(n.Ident); ok && variable.Attribute != "" {
+			attributes[variable.Attribute] = true
+		}
+	case *parse.FieldNode:
+		if len(n.Ident) >= 2 && n.Ident[0] == "Attributes" {
+			attributes[n.Ident[1]] = true
+			return
+		}
+		fields[n.Ident[0]] = true
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, fields, attributes)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, fields, attributes)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, fields, attributes)
+	}
+}
+
+// walkBranch visits the pipeline and both bodies of if/range/with
+func walkBranch(branch *parse.BranchNode, fields, attributes map[string]bool) {
+	walkTemplate(branch.Pipe, fields, attributes)
+	walkTemplate(branch.List, fields, attributes)
+	walkTemplate(branch.ElseList, fields, attributes)
+}
+
+// leadFields lists the exported Lead fields templates may reference
+func leadFields() map[string]bool {
+	fields := make(map[string]bool)
+	leadType := reflect.TypeOf(Lead{})
+	for i := 0; i < leadType.NumField(); i++ {
+		fields[leadType.Field(i).Name] = true
+	}
+	return fields
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
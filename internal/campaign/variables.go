@@ -0,0 +1,106 @@
+package campaign
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"linkedin-automation-framework/internal/identity"
+)
+
+// AttributeRecentPostTopic is the topic of the lead's most recent post, when a plugin has
+// enriched the lead with it
+const AttributeRecentPostTopic = "recent_post_topic"
+
+// Variable is a named value templates can use without knowing the Lead's layout, written
+// {{first_name}}. Attribute-backed variables fail rendering, like a missing .Attributes key,
+// when the lead does not have the attribute.
+type Variable struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Attribute   string `json:"attribute,omitempty"` // Lead attribute the value comes from, if any
+	value       func(lead *Lead) string
+}
+
+// variables is the catalog, in the order it is documented
+var variables = []Variable{
+	{Name: "name", Description: "Full name as shown on the profile", value: func(lead *Lead) string { return lead.Name }},
+	{Name: "first_name", Description: "First name, without honorifics, credentials or emoji", value: func(lead *Lead) string { return identity.ParseName(lead.Name).First }},
+	{Name: "last_name", Description: "Last name, without credentials or emoji", value: func(lead *Lead) string { return identity.ParseName(lead.Name).Last }},
+	{Name: "salutation", Description: "First name, or \"there\" when the name has none, for \"Hi {{salutation}}\"", value: func(lead *Lead) string { return identity.Salutation(lead.Name, "there") }},
+	{Name: "title", Description: "Headline or current position", value: func(lead *Lead) string { return lead.Title }},
+	{Name: "company", Description: "Current company", value: func(lead *Lead) string { return lead.Company }},
+	{Name: "location", Description: "Location as shown on the profile", value: func(lead *Lead) string { return lead.Location }},
+	{Name: "city", Description: "City part of the location, without \"Greater\" or \"Area\"", value: func(lead *Lead) string { return city(lead.Location) }},
+	{Name: "mutuals", Description: "Number of mutual connections", value: func(lead *Lead) string { return strconv.Itoa(lead.Mutual) }},
+	{Name: "connected_on", Description: "Date the connection was made (network leads)", Attribute: AttributeConnectedOn},
+	{Name: "years_connected", Description: "Whole years since the connection was made (network leads)", Attribute: AttributeYearsConnected},
+	{Name: "last_contact", Description: "Date of the last message or the connection (network leads)", Attribute: AttributeLastContact},
+	{Name: "about", Description: "Profile About text, from a plugin", Attribute: AttributeAbout},
+	{Name: "recent_post_topic", Description: "Topic of the most recent post, from a plugin", Attribute: AttributeRecentPostTopic},
+}
+
+// Variables returns the template variable catalog
+func Variables() []Variable {
+	return append([]Variable(nil), variables...)
+}
+
+// variableNamed finds a catalog variable by name
+func variableNamed(name string) (Variable, bool) {
+	for _, variable := range variables {
+		if variable.Name == name {
+			return variable, true
+		}
+	}
+	return Variable{}, false
+}
+
+// variableFuncs binds the catalog to a lead as template functions; a nil lead gives
+// placeholders that let templates parse
+func variableFuncs(lead *Lead) map[string]any {
+	funcs := make(map[string]any, len(variables))
+	for _, variable := range variables {
+		variable := variable
+		funcs[variable.Name] = func() (string, error) {
+			if lead == nil {
+				return "", nil
+			}
+			if variable.Attribute == "" {
+				return variable.value(lead), nil
+			}
+			value, ok := lead.Attributes[variable.Attribute]
+			if !ok {
+				return "", fmt.Errorf("lead has no %q attribute for {{%s}}", variable.Attribute, variable.Name)
+			}
+			return value, nil
+		}
+	}
+	return funcs
+}
+
+// unknownVariable rewrites text/template's error for an undefined function, which is what an
+// unknown {{name}} is, into one naming the catalog
+func unknownVariable(err error) error {
+	message := err.Error()
+	start := strings.Index(message, `function "`)
+	if start < 0 || !strings.HasSuffix(message, `" not defined`) {
+		return err
+	}
+	name := strings.TrimSuffix(message[start+len(`function "`):], `" not defined`)
+	names := make([]string, 0, len(variables))
+	for _, variable := range variables {
+		names = append(names, variable.Name)
+	}
+	return fmt.Errorf("unknown template variable %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// city takes "Berlin, Germany" or "Greater Munich Metropolitan Area" down to the city
+func city(location string) string {
+	city, _, _ := strings.Cut(location, ",")
+	city = strings.TrimSpace(city)
+	city = strings.TrimPrefix(city, "Greater ")
+	for _, suffix := range []string{" Metropolitan Area", " Metro Area", " Area"} {
+		city = strings.TrimSuffix(city, suffix)
+	}
+	return strings.TrimSpace(city)
+}
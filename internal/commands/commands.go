@@ -0,0 +1,598 @@
+// Package commands serves the inbound commands endpoint, through which external systems queue
+// leads for campaigns, pause or resume the running account, read its status, review the drafts
+// campaigns hold for approval, annotate leads, browse the screenshots campaign runs took and
+// review the leads campaigns dead-lettered
+package commands
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"linkedin-automation-framework/internal/annotations"
+	"linkedin-automation-framework/internal/apitoken"
+	"linkedin-automation-framework/internal/approval"
+	"linkedin-automation-framework/internal/control"
+	"linkedin-automation-framework/internal/deadletters"
+	"linkedin-automation-framework/internal/errors"
+	"linkedin-automation-framework/internal/gallery"
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// DefaultMaxLeads caps the profile URLs one request may queue when no cap is configured
+const DefaultMaxLeads = 500
+
+// Queue holds leads until a campaign run takes them
+type Queue interface {
+	QueueLeads(leads []storage.QueuedLead) (int, error)
+	GetQueuedLeads() ([]storage.QueuedLead, error)
+}
+
+// Options configures the endpoint
+type Options struct {
+	Account  string                  // The account this instance runs, the only one commands accept
+	Auth     *apitoken.Authenticator // Checks each command's token; nil refuses every command
+	MaxLeads int                     // Profile URLs one request may queue; DefaultMaxLeads if 0
+
+	// Health returns the account's health score and level for the status command; nil leaves
+	// them out
+	Health func() (int, string, error)
+
+	// Drafts holds the drafts campaigns requiring approval wait on; nil leaves out the draft
+	// commands
+	Drafts approval.Store
+
+	// Annotations keeps the notes and attributes users attach to leads, which drafts show; nil
+	// leaves out the lead commands
+	Annotations annotations.Store
+
+	// Screenshots is the directory of the runs' screenshot galleries; empty leaves out the
+	// screenshot commands, and the screenshots of dead letters
+	Screenshots string
+
+	// DeadLetters keeps the campaign leads their queue gave up on; nil leaves out the dead letter
+	// commands
+	DeadLetters deadletters.Store
+}
+
+// Draft is an invite or message waiting for a reviewer
+type Draft struct {
+	ID         string    `json:"id"`
+	Campaign   string    `json:"campaign"`
+	StepID     string    `json:"step"`
+	Kind       string    `json:"kind"`
+	ProfileURL string    `json:"profile_url"`
+	LeadName   string    `json:"lead_name,omitempty"`
+	Subject    string    `json:"subject,omitempty"`
+	Text       string    `json:"text"`
+	Status     string    `json:"status"`
+	Reviewer   string    `json:"reviewer,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	Lead       *Lead     `json:"lead,omitempty"` // Notes and attributes of the lead, when it has any
+}
+
+// Lead is what users attached to a lead
+type Lead struct {
+	ProfileURL string            `json:"profile_url"`
+	Notes      []Note            `json:"notes"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// Note is a note attached to a lead
+type Note struct {
+	Text      string    `json:"text"`
+	Author    string    `json:"author,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DeadLetter is a campaign lead its queue gave up on after it failed too often
+type DeadLetter struct {
+	ID          int64             `json:"id"`
+	Campaign    string            `json:"campaign"`
+	ProfileURL  string            `json:"profile_url"`
+	LeadName    string            `json:"lead_name,omitempty"`
+	Attributes  map[string]string `json:"attributes"` // What the lead runs with once requeued
+	Attempts    int               `json:"attempts"`
+	Error       string            `json:"error"`
+	Fingerprint string            `json:"fingerprint"`
+	RunID       string            `json:"run_id,omitempty"`
+	Screenshots []string          `json:"screenshots"` // Paths of the last failure's screenshots, under /commands/screenshots
+	EnqueuedAt  time.Time         `json:"enqueued_at"`
+	FailedAt    time.Time         `json:"failed_at"`
+}
+
+// BulkResult answers requeueing or discarding dead letters
+type BulkResult struct {
+	Action string `json:"action"` // "requeue" or "discard"
+	Count  int    `json:"count"`
+}
+
+// AccountStatus answers the status command
+type AccountStatus struct {
+	Account     string         `json:"account"`
+	Control     control.Status `json:"control"`
+	QueuedLeads int            `json:"queued_leads"`
+	HealthScore *int           `json:"health_score,omitempty"`
+	HealthLevel string         `json:"health_level,omitempty"`
+}
+
+// QueueResult answers a queueing command
+type QueueResult struct {
+	Campaign      string `json:"campaign"` // "" for the next run of any campaign
+	Queued        int    `json:"queued"`
+	AlreadyQueued int    `json:"already_queued"`
+}
+
+// Handler serves the commands, each authenticated with "Authorization: Bearer <token>" of the
+// scope given:
+//
+//	GET  /commands/accounts/{account}/status   read     AccountStatus
+//	POST /commands/invite                      control  {"profile_url": "...", "campaign": "..."}
+//	POST /commands/campaigns/{campaign}/leads  control  {"profile_urls": ["...", ...]}
+//	POST /commands/accounts/{account}/pause    control  {"reason": "..."}
+//	POST /commands/accounts/{account}/resume   control
+//	GET  /commands/drafts                      review   pending Drafts, oldest first
+//	POST /commands/drafts/{id}/approve         review   {"text": "..."}, replacing the text if given
+//	POST /commands/drafts/{id}/reject          review   {"reason": "..."}
+//	GET  /commands/leads?profile_url=...       read     the Lead
+//	POST /commands/leads/notes                 review   {"profile_url": "...", "text": "..."}
+//	POST /commands/leads/attributes            review   {"profile_url": "...", "attributes": {"key": "value"}}, "" removing one
+//	GET  /commands/screenshots                 read     gallery.Runs, latest first
+//	GET  /commands/screenshots/{run}           read     the run's gallery.Shots in order
+//	GET  /commands/screenshots/{run}/{file}    read     one screenshot as image/png
+//	GET  /commands/dead-letters                review   DeadLetters, oldest first; ?campaign= and ?fingerprint= narrow them
+//	POST /commands/dead-letters/{id}           review   {"attributes": {"key": "value"}}, "" removing one
+//	POST /commands/dead-letters/requeue        control  {"ids": [1, 2], "campaign": "...", "fingerprint": "...", "all": false}
+//	POST /commands/dead-letters/discard        control  the same selection
+//
+// Queueing commands answer with a QueueResult, pause and resume with the control Status,
+// approving and rejecting with the reviewed Draft, the lead commands with the Lead, editing a
+// dead letter with the DeadLetter and requeueing or discarding with a BulkResult. A bulk command
+// selects the dead letters matching every field given, and needs "all" to select every one.
+// Failed commands answer with {"error": "..."},
+// and with the error's "code", e.g. "E_SESSION_EXPIRED", when it has one.
+func Handler(queue Queue, controller *control.Controller, options Options) http.Handler {
+	if options.MaxLeads <= 0 {
+		options.MaxLeads = DefaultMaxLeads
+	}
+	if options.Auth == nil {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeError(w, http.StatusServiceUnavailable, "commands are disabled")
+		})
+	}
+	mux := http.NewServeMux()
+	handle := func(pattern, scope string, handler http.HandlerFunc) {
+		mux.Handle(pattern, options.Auth.Require(scope, handler))
+	}
+
+	handle("GET /commands/accounts/{account}/status", apitoken.ScopeRead, func(w http.ResponseWriter, r *http.Request) {
+		if !sameAccount(w, r, options.Account) {
+			return
+		}
+		queued, err := queue.GetQueuedLeads()
+		if err != nil {
+			writeFailure(w, http.StatusInternalServerError, err)
+			return
+		}
+		status := AccountStatus{Account: options.Account, Control: controller.Status(), QueuedLeads: len(queued)}
+		if options.Health != nil {
+			score, level, err := options.Health()
+			if err != nil {
+				writeFailure(w, http.StatusInternalServerError, err)
+				return
+			}
+			status.HealthScore, status.HealthLevel = &score, level
+		}
+		writeJSON(w, http.StatusOK, status)
+	})
+	handle("POST /commands/invite", apitoken.ScopeControl, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			ProfileURL string `json:"profile_url"`
+			Campaign   string `json:"campaign"`
+		}
+		if !decode(w, r, &body) {
+			return
+		}
+		enqueue(w, queue, token(r), strings.TrimSpace(body.Campaign), []string{body.ProfileURL}, options.MaxLeads)
+	})
+	handle("POST /commands/campaigns/{campaign}/leads", apitoken.ScopeControl, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			ProfileURLs []string `json:"profile_urls"`
+		}
+		if !decode(w, r, &body) {
+			return
+		}
+		enqueue(w, queue, token(r), r.PathValue("campaign"), body.ProfileURLs, options.MaxLeads)
+	})
+	handle("POST /commands/accounts/{account}/pause", apitoken.ScopeControl, func(w http.ResponseWriter, r *http.Request) {
+		if !sameAccount(w, r, options.Account) {
+			return
+		}
+		var body struct {
+			Reason string `json:"reason"`
+		}
+		if r.ContentLength != 0 && !decode(w, r, &body) {
+			return
+		}
+		reason := strings.TrimSpace(body.Reason)
+		if reason == "" {
+			reason = "paused by " + token(r)
+		}
+		controller.Pause(reason)
+		writeJSON(w, http.StatusOK, controller.Status())
+	})
+	handle("POST /commands/accounts/{account}/resume", apitoken.ScopeControl, func(w http.ResponseWriter, r *http.Request) {
+		if !sameAccount(w, r, options.Account) {
+			return
+		}
+		controller.Resume()
+		writeJSON(w, http.StatusOK, controller.Status())
+	})
+
+	if options.Drafts != nil {
+		handle("GET /commands/drafts", apitoken.ScopeReview, func(w http.ResponseWriter, r *http.Request) {
+			pending, err := approval.Pending(options.Drafts)
+			if err != nil {
+				writeFailure(w, http.StatusInternalServerError, err)
+				return
+			}
+			var annotated map[string]annotations.Annotation
+			if options.Annotations != nil {
+				if annotated, err = annotations.Load(options.Annotations); err != nil {
+					writeFailure(w, http.StatusInternalServerError, err)
+					return
+				}
+			}
+			drafts := make([]Draft, 0, len(pending))
+			for _, draft := range pending {
+				converted := newDraft(draft)
+				if annotation, found := annotated[identity.ProfileKey(draft.ProfileURL)]; found {
+					converted.Lead = newLead(annotation)
+				}
+				drafts = append(drafts, converted)
+			}
+			writeJSON(w, http.StatusOK, drafts)
+		})
+		handle("POST /commands/drafts/{id}/approve", apitoken.ScopeReview, func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Text string `json:"text"`
+			}
+			if r.ContentLength != 0 && !decode(w, r, &body) {
+				return
+			}
+			draft, err := approval.Approve(options.Drafts, r.PathValue("id"), token(r), body.Text, time.Now())
+			writeReview(w, draft, err)
+		})
+		handle("POST /commands/drafts/{id}/reject", apitoken.ScopeReview, func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Reason string `json:"reason"`
+			}
+			if r.ContentLength != 0 && !decode(w, r, &body) {
+				return
+			}
+			draft, err := approval.Reject(options.Drafts, r.PathValue("id"), token(r), strings.TrimSpace(body.Reason), time.Now())
+			writeReview(w, draft, err)
+		})
+	}
+
+	if options.Annotations != nil {
+		handle("GET /commands/leads", apitoken.ScopeRead, func(w http.ResponseWriter, r *http.Request) {
+			profileURL := r.URL.Query().Get("profile_url")
+			if profileURL == "" {
+				writeError(w, http.StatusBadRequest, "profile_url is required")
+				return
+			}
+			writeLead(w, options.Annotations, profileURL, nil)
+		})
+		handle("POST /commands/leads/notes", apitoken.ScopeReview, func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				ProfileURL string `json:"profile_url"`
+				Text       string `json:"text"`
+			}
+			if !decode(w, r, &body) {
+				return
+			}
+			err := annotations.AddNote(options.Annotations, body.ProfileURL, body.Text, token(r), time.Now())
+			writeLead(w, options.Annotations, body.ProfileURL, err)
+		})
+		handle("POST /commands/leads/attributes", apitoken.ScopeReview, func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				ProfileURL string            `json:"profile_url"`
+				Attributes map[string]string `json:"attributes"`
+			}
+			if !decode(w, r, &body) {
+				return
+			}
+			err := annotations.SetAttributes(options.Annotations, body.ProfileURL, body.Attributes, token(r), time.Now())
+			writeLead(w, options.Annotations, body.ProfileURL, err)
+		})
+	}
+
+	if options.Screenshots != "" {
+		handle("GET /commands/screenshots", apitoken.ScopeRead, func(w http.ResponseWriter, r *http.Request) {
+			runs, err := gallery.Runs(options.Screenshots)
+			if err != nil {
+				writeFailure(w, http.StatusInternalServerError, err)
+				return
+			}
+			if runs == nil {
+				runs = []gallery.Run{}
+			}
+			writeJSON(w, http.StatusOK, runs)
+		})
+		handle("GET /commands/screenshots/{run}", apitoken.ScopeRead, func(w http.ResponseWriter, r *http.Request) {
+			shots, err := gallery.Shots(options.Screenshots, r.PathValue("run"))
+			if stderrors.Is(err, gallery.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "no screenshots for run "+r.PathValue("run"))
+				return
+			}
+			if err != nil {
+				writeFailure(w, http.StatusInternalServerError, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, shots)
+		})
+		handle("GET /commands/screenshots/{run}/{file}", apitoken.ScopeRead, func(w http.ResponseWriter, r *http.Request) {
+			path, err := gallery.Path(options.Screenshots, r.PathValue("run"), r.PathValue("file"))
+			if stderrors.Is(err, gallery.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "no such screenshot")
+				return
+			}
+			if err != nil {
+				writeFailure(w, http.StatusInternalServerError, err)
+				return
+			}
+			w.Header().Set("Content-Type", "image/png")
+			http.ServeFile(w, r, path)
+		})
+	}
+
+	if options.DeadLetters != nil {
+		handle("GET /commands/dead-letters", apitoken.ScopeReview, func(w http.ResponseWriter, r *http.Request) {
+			filter := deadletters.Filter{Campaign: r.URL.Query().Get("campaign"), Fingerprint: r.URL.Query().Get("fingerprint")}
+			letters, err := deadletters.List(options.DeadLetters, filter, options.Screenshots)
+			if err != nil {
+				writeFailure(w, http.StatusInternalServerError, err)
+				return
+			}
+			converted := make([]DeadLetter, 0, len(letters))
+			for _, letter := range letters {
+				converted = append(converted, newDeadLetter(letter))
+			}
+			writeJSON(w, http.StatusOK, converted)
+		})
+		handle("POST /commands/dead-letters/{id}", apitoken.ScopeReview, func(w http.ResponseWriter, r *http.Request) {
+			id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+			if err != nil {
+				writeError(w, http.StatusNotFound, "no such dead letter: "+r.PathValue("id"))
+				return
+			}
+			var body struct {
+				Attributes map[string]string `json:"attributes"`
+			}
+			if !decode(w, r, &body) {
+				return
+			}
+			letter, err := deadletters.SetAttributes(options.DeadLetters, id, body.Attributes)
+			switch {
+			case stderrors.Is(err, deadletters.ErrNotFound):
+				writeError(w, http.StatusNotFound, err.Error())
+			case stderrors.Is(err, annotations.ErrInvalid):
+				writeError(w, http.StatusBadRequest, err.Error())
+			case err != nil:
+				writeFailure(w, http.StatusInternalServerError, err)
+			default:
+				writeJSON(w, http.StatusOK, newDeadLetter(letter))
+			}
+		})
+		for _, action := range []string{"requeue", "discard"} {
+			handle("POST /commands/dead-letters/"+action, apitoken.ScopeControl, func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					IDs         []int64 `json:"ids"`
+					Campaign    string  `json:"campaign"`
+					Fingerprint string  `json:"fingerprint"`
+					All         bool    `json:"all"`
+				}
+				if !decode(w, r, &body) {
+					return
+				}
+				filter := deadletters.Filter{IDs: body.IDs, Campaign: strings.TrimSpace(body.Campaign), Fingerprint: strings.TrimSpace(body.Fingerprint), All: body.All}
+				var count int
+				var err error
+				if action == "requeue" {
+					count, err = deadletters.Requeue(options.DeadLetters, filter, time.Now())
+				} else {
+					count, err = deadletters.Discard(options.DeadLetters, filter)
+				}
+				switch {
+				case stderrors.Is(err, deadletters.ErrNoSelection):
+					writeError(w, http.StatusBadRequest, err.Error())
+				case err != nil:
+					writeFailure(w, http.StatusInternalServerError, err)
+				default:
+					writeJSON(w, http.StatusOK, BulkResult{Action: action, Count: count})
+				}
+			})
+		}
+	}
+
+	return mux
+}
+
+// newDeadLetter converts a dead letter, with the paths its screenshots are served at
+func newDeadLetter(letter deadletters.Letter) DeadLetter {
+	converted := DeadLetter{
+		ID:          letter.ID,
+		Campaign:    letter.Campaign,
+		ProfileURL:  letter.Lead.ProfileURL,
+		LeadName:    letter.Lead.Name,
+		Attributes:  letter.Lead.Attributes,
+		Attempts:    letter.Attempts,
+		Error:       letter.Error,
+		Fingerprint: letter.Fingerprint,
+		RunID:       letter.RunID,
+		Screenshots: make([]string, 0, len(letter.Screenshots)),
+		EnqueuedAt:  letter.EnqueuedAt,
+		FailedAt:    letter.FailedAt,
+	}
+	if converted.Attributes == nil {
+		converted.Attributes = map[string]string{}
+	}
+	for _, shot := range letter.Screenshots {
+		converted.Screenshots = append(converted.Screenshots, "/commands/screenshots/"+letter.RunID+"/"+shot.File)
+	}
+	return converted
+}
+
+// newDraft converts a stored draft
+func newDraft(draft storage.Draft) Draft {
+	return Draft{
+		ID:         draft.ID,
+		Campaign:   draft.Campaign,
+		StepID:     draft.StepID,
+		Kind:       draft.Kind,
+		ProfileURL: draft.ProfileURL,
+		LeadName:   draft.LeadName,
+		Subject:    draft.Subject,
+		Text:       draft.Text,
+		Status:     draft.Status,
+		Reviewer:   draft.Reviewer,
+		Reason:     draft.Reason,
+		CreatedAt:  draft.CreatedAt,
+	}
+}
+
+// newLead converts a lead's annotation
+func newLead(annotation annotations.Annotation) *Lead {
+	lead := &Lead{ProfileURL: annotation.ProfileURL, Notes: make([]Note, 0, len(annotation.Notes)), Attributes: annotation.Attributes}
+	for _, note := range annotation.Notes {
+		lead.Notes = append(lead.Notes, Note{Text: note.Text, Author: note.Author, CreatedAt: note.CreatedAt})
+	}
+	if lead.Attributes == nil {
+		lead.Attributes = map[string]string{}
+	}
+	return lead
+}
+
+// writeLead answers a lead command with the lead's annotation, or with the error the command
+// failed with
+func writeLead(w http.ResponseWriter, store annotations.Store, profileURL string, err error) {
+	switch {
+	case stderrors.Is(err, annotations.ErrInvalid):
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		writeFailure(w, http.StatusInternalServerError, err)
+		return
+	}
+	annotation, err := annotations.For(store, profileURL)
+	if err != nil {
+		writeFailure(w, http.StatusInternalServerError, err)
+		return
+	}
+	lead := newLead(annotation)
+	lead.ProfileURL = identity.NormalizeProfileURL(profileURL)
+	writeJSON(w, http.StatusOK, lead)
+}
+
+// writeReview answers an approval or rejection with the reviewed draft
+func writeReview(w http.ResponseWriter, draft storage.Draft, err error) {
+	switch {
+	case stderrors.Is(err, approval.ErrNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case stderrors.Is(err, approval.ErrSent):
+		writeError(w, http.StatusConflict, err.Error())
+	case err != nil:
+		writeFailure(w, http.StatusInternalServerError, err)
+	default:
+		writeJSON(w, http.StatusOK, newDraft(draft))
+	}
+}
+
+// enqueue queues the profile URLs for the campaign after checking every one of them
+func enqueue(w http.ResponseWriter, queue Queue, source, campaign string, profileURLs []string, maxLeads int) {
+	if len(profileURLs) == 0 {
+		writeError(w, http.StatusBadRequest, "no profile URLs given")
+		return
+	}
+	if len(profileURLs) > maxLeads {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("at most %d profile URLs per request, got %d", maxLeads, len(profileURLs)))
+		return
+	}
+
+	now := time.Now()
+	leads := make([]storage.QueuedLead, 0, len(profileURLs))
+	seen := make(map[string]bool, len(profileURLs))
+	for _, profileURL := range profileURLs {
+		normalized := identity.NormalizeProfileURL(strings.TrimSpace(profileURL))
+		if !strings.HasPrefix(normalized, identity.CanonicalProfilePrefix) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("not a LinkedIn profile URL: %q", profileURL))
+			return
+		}
+		if seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		leads = append(leads, storage.QueuedLead{ProfileURL: normalized, Campaign: campaign, Source: source, QueuedAt: now})
+	}
+
+	added, err := queue.QueueLeads(leads)
+	if err != nil {
+		writeFailure(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, QueueResult{Campaign: campaign, Queued: added, AlreadyQueued: len(leads) - added})
+}
+
+// sameAccount refuses commands for an account this instance does not run
+func sameAccount(w http.ResponseWriter, r *http.Request, account string) bool {
+	if r.PathValue("account") != account {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("this instance runs account %q, not %q", account, r.PathValue("account")))
+		return false
+	}
+	return true
+}
+
+// decode reads a JSON body of at most 1 MiB, answering 400 when it is malformed
+func decode(w http.ResponseWriter, r *http.Request, body any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// writeError answers with {"error": message}
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// writeFailure answers with {"error": message, "code": code}, leaving out the code of an error
+// that has none, see errors.CodeOf
+func writeFailure(w http.ResponseWriter, status int, err error) {
+	body := map[string]string{"error": err.Error()}
+	if code := errors.CodeOf(err); code != "" {
+		body["code"] = string(code)
+	}
+	writeJSON(w, status, body)
+}
+
+// writeJSON encodes value as the response body
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// token returns the name of the token the request was authenticated with, as "api:<name>"
+func token(r *http.Request) string {
+	return "api:" + apitoken.Name(r.Context())
+}
@@ -0,0 +1,429 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/apitoken"
+	"linkedin-automation-framework/internal/approval"
+	"linkedin-automation-framework/internal/control"
+	"linkedin-automation-framework/internal/errors"
+	"linkedin-automation-framework/internal/gallery"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// memoryQueue keeps queued leads in memory, ignoring duplicates like storage does
+type memoryQueue struct {
+	leads []storage.QueuedLead
+}
+
+func (q *memoryQueue) QueueLeads(leads []storage.QueuedLead) (int, error) {
+	added := 0
+	for _, lead := range leads {
+		duplicate := false
+		for _, queued := range q.leads {
+			duplicate = duplicate || (queued.ProfileURL == lead.ProfileURL && queued.Campaign == lead.Campaign)
+		}
+		if !duplicate {
+			q.leads = append(q.leads, lead)
+			added++
+		}
+	}
+	return added, nil
+}
+
+func (q *memoryQueue) GetQueuedLeads() ([]storage.QueuedLead, error) {
+	return q.leads, nil
+}
+
+const (
+	secret       = "0123456789abcdef-control"
+	readSecret   = "0123456789abcdef-read"
+	reviewSecret = "0123456789abcdef-review"
+)
+
+// auth accepts secret with control scope, readSecret with read scope and reviewSecret with
+// review scope
+func auth() *apitoken.Authenticator {
+	return apitoken.NewAuthenticator([]storage.APIToken{
+		{Name: "zapier", Hash: apitoken.Hash(secret), Scope: apitoken.ScopeControl},
+		{Name: "dashboard", Hash: apitoken.Hash(readSecret), Scope: apitoken.ScopeRead},
+		{Name: "sam", Hash: apitoken.Hash(reviewSecret), Scope: apitoken.ScopeReview},
+	}, nil, 0)
+}
+
+func get(t *testing.T, server *httptest.Server, token, path string) (int, map[string]any) {
+	t.Helper()
+	request, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", path, err)
+	}
+	defer response.Body.Close()
+	var decoded map[string]any
+	json.NewDecoder(response.Body).Decode(&decoded)
+	return response.StatusCode, decoded
+}
+
+func post(t *testing.T, server *httptest.Server, token, path, body string) (int, map[string]any) {
+	t.Helper()
+	request, _ := http.NewRequest(http.MethodPost, server.URL+path, strings.NewReader(body))
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("POST %s failed: %v", path, err)
+	}
+	defer response.Body.Close()
+	var decoded map[string]any
+	json.NewDecoder(response.Body).Decode(&decoded)
+	return response.StatusCode, decoded
+}
+
+// TestCommandsQueueLeads tests authentication, queueing single and bulk leads, and rejection
+// of bad URLs and oversized requests
+func TestCommandsQueueLeads(t *testing.T) {
+	queue := &memoryQueue{}
+	server := httptest.NewServer(Handler(queue, control.NewController(), Options{
+		Account:  "default",
+		Auth:     auth(),
+		MaxLeads: 3,
+	}))
+	defer server.Close()
+
+	if status, _ := post(t, server, "", "/commands/invite", `{"profile_url":"https://www.linkedin.com/in/jane-doe"}`); status != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a token, got %d", status)
+	}
+	if status, _ := post(t, server, "wrong-token-wrong-token", "/commands/invite", `{}`); status != http.StatusUnauthorized {
+		t.Errorf("expected 401 with an unknown token, got %d", status)
+	}
+
+	status, body := post(t, server, secret, "/commands/invite", `{"profile_url":"https://linkedin.com/in/Jane-Doe?trk=x"}`)
+	if status != http.StatusAccepted || body["queued"] != float64(1) || body["campaign"] != "" {
+		t.Errorf("expected the invite queued for any campaign, got %d %v", status, body)
+	}
+	status, body = post(t, server, secret, "/commands/campaigns/spring/leads",
+		`{"profile_urls":["https://www.linkedin.com/in/jane-doe/","https://www.linkedin.com/in/john-roe","https://www.linkedin.com/in/john-roe/"]}`)
+	if status != http.StatusAccepted || body["queued"] != float64(2) || body["already_queued"] != float64(0) {
+		t.Errorf("expected two leads queued for spring, got %d %v", status, body)
+	}
+	if len(queue.leads) != 3 || queue.leads[0].ProfileURL != "https://www.linkedin.com/in/jane-doe/" || queue.leads[1].Campaign != "spring" || queue.leads[1].Source != "api:zapier" {
+		t.Errorf("unexpected queue %+v", queue.leads)
+	}
+
+	for path, body := range map[string]string{
+		"/commands/invite":                 `{"profile_url":"https://example.com/in/jane"}`,
+		"/commands/campaigns/spring/leads": `{"profile_urls":[]}`,
+		"/commands/campaigns/x/leads":      `{"profile_urls":["a","b","c","d"]}`,
+		"/commands/campaigns/y/leads":      `{"urls":["https://www.linkedin.com/in/jane-doe"]}`,
+	} {
+		if status, _ := post(t, server, secret, path, body); status < 400 || status >= 500 {
+			t.Errorf("%s %s: expected a client error, got %d", path, body, status)
+		}
+	}
+	if len(queue.leads) != 3 {
+		t.Errorf("expected rejected requests to queue nothing, got %+v", queue.leads)
+	}
+}
+
+// TestCommandsPauseAccount tests pausing and resuming the running account only, and that a
+// read-only token sees the status but cannot pause
+func TestCommandsPauseAccount(t *testing.T) {
+	controller := control.NewController()
+	server := httptest.NewServer(Handler(&memoryQueue{}, controller, Options{
+		Account: "default",
+		Auth:    auth(),
+		Health:  func() (int, string, error) { return 82, "normal", nil },
+	}))
+	defer server.Close()
+
+	if status, _ := post(t, server, readSecret, "/commands/accounts/default/pause", ""); status != http.StatusForbidden {
+		t.Errorf("expected 403 for a read-only token, got %d", status)
+	}
+	status, body := get(t, server, readSecret, "/commands/accounts/default/status")
+	if status != http.StatusOK || body["health_score"] != float64(82) || body["queued_leads"] != float64(0) {
+		t.Errorf("expected the status with health for a read-only token, got %d %v", status, body)
+	}
+
+	if status, _ := post(t, server, secret, "/commands/accounts/other/pause", ""); status != http.StatusNotFound {
+		t.Errorf("expected 404 for another account, got %d", status)
+	}
+	status, body = post(t, server, secret, "/commands/accounts/default/pause", `{"reason":"CRM sync"}`)
+	if status != http.StatusOK || controller.Status().State == control.StateRunning || controller.Status().Reason != "CRM sync" {
+		t.Errorf("expected the account paused, got %d %v", status, body)
+	}
+	if status, _ := post(t, server, secret, "/commands/accounts/default/resume", ""); status != http.StatusOK || controller.Status().State != control.StateRunning {
+		t.Errorf("expected the account resumed, got %d", status)
+	}
+
+	disabled := httptest.NewServer(Handler(&memoryQueue{}, controller, Options{Account: "default"}))
+	defer disabled.Close()
+	if status, _ := post(t, disabled, secret, "/commands/accounts/default/pause", ""); status != http.StatusServiceUnavailable {
+		t.Errorf("expected commands disabled without an authenticator, got %d", status)
+	}
+}
+
+// TestCommandsErrorCode tests that a failed command answers with its error's code
+func TestCommandsErrorCode(t *testing.T) {
+	server := httptest.NewServer(Handler(&memoryQueue{}, control.NewController(), Options{
+		Account: "default",
+		Auth:    auth(),
+		Health: func() (int, string, error) {
+			return 0, "", errors.NewError(errors.ErrorTypeAuthentication, "account_health", "session expired", nil)
+		},
+	}))
+	defer server.Close()
+
+	status, body := get(t, server, readSecret, "/commands/accounts/default/status")
+	if status != http.StatusInternalServerError || body["code"] != string(errors.CodeSessionExpired) || body["error"] == nil {
+		t.Errorf("expected a 500 with E_SESSION_EXPIRED, got %d %v", status, body)
+	}
+}
+
+// TestCommandsReviewDrafts tests that reviewers list, edit and reject held drafts and that
+// read-only tokens cannot
+func TestCommandsReviewDrafts(t *testing.T) {
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "json", Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+	gate := approval.NewGate(store, "spring")
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	gate.Hold("invite", approval.KindInvite, "https://www.linkedin.com/in/jane-doe/", "Jane Doe", "", "Hi Jane", now)
+	gate.Hold("invite", approval.KindInvite, "https://www.linkedin.com/in/john-roe/", "John Roe", "", "Hi John", now.Add(time.Minute))
+
+	server := httptest.NewServer(Handler(&memoryQueue{}, control.NewController(), Options{Account: "default", Auth: auth(), Drafts: store}))
+	defer server.Close()
+
+	list := func(token string) (int, []Draft) {
+		request, _ := http.NewRequest(http.MethodGet, server.URL+"/commands/drafts", nil)
+		request.Header.Set("Authorization", "Bearer "+token)
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("GET /commands/drafts failed: %v", err)
+		}
+		defer response.Body.Close()
+		var drafts []Draft
+		json.NewDecoder(response.Body).Decode(&drafts)
+		return response.StatusCode, drafts
+	}
+	if status, _ := list(readSecret); status != http.StatusForbidden {
+		t.Errorf("expected 403 for a read-only token, got %d", status)
+	}
+	status, drafts := list(reviewSecret)
+	if status != http.StatusOK || len(drafts) != 2 || drafts[0].LeadName != "Jane Doe" || drafts[0].Text != "Hi Jane" {
+		t.Fatalf("expected both drafts oldest first, got %d %+v", status, drafts)
+	}
+
+	status, body := post(t, server, reviewSecret, "/commands/drafts/"+drafts[0].ID+"/approve", `{"text":"Hello Jane"}`)
+	if status != http.StatusOK || body["status"] != approval.StatusApproved || body["text"] != "Hello Jane" || body["reviewer"] != "api:sam" {
+		t.Errorf("expected Jane's draft approved with the edit, got %d %v", status, body)
+	}
+	if status, body := post(t, server, secret, "/commands/drafts/"+drafts[1].ID+"/reject", `{"reason":"not a fit"}`); status != http.StatusOK || body["status"] != approval.StatusRejected {
+		t.Errorf("expected a control token to reject John's draft, got %d %v", status, body)
+	}
+	if status, _ := post(t, server, reviewSecret, "/commands/drafts/missing/approve", ""); status != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown draft, got %d", status)
+	}
+	if _, drafts := list(reviewSecret); len(drafts) != 0 {
+		t.Errorf("expected nothing left to review, got %+v", drafts)
+	}
+	if text, err := gate.Hold("invite", approval.KindInvite, "https://www.linkedin.com/in/jane-doe/", "Jane Doe", "", "Hi Jane", now); err != nil || text != "Hello Jane" {
+		t.Errorf("expected the campaign to send the edited note, got %q (%v)", text, err)
+	}
+}
+
+// TestLeadCommands tests annotating a lead with notes and attributes, reading them back and
+// seeing them on the lead's draft
+func TestLeadCommands(t *testing.T) {
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "json", Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+	approval.NewGate(store, "spring").Hold("invite", approval.KindInvite, "https://www.linkedin.com/in/jane-doe/", "Jane Doe", "", "Hi Jane", time.Now())
+
+	server := httptest.NewServer(Handler(&memoryQueue{}, control.NewController(), Options{Account: "default", Auth: auth(), Drafts: store, Annotations: store}))
+	defer server.Close()
+
+	if status, _ := post(t, server, readSecret, "/commands/leads/notes", `{"profile_url":"https://www.linkedin.com/in/jane-doe","text":"Met at GopherCon"}`); status != http.StatusForbidden {
+		t.Errorf("expected 403 for a read-only token, got %d", status)
+	}
+	status, body := post(t, server, reviewSecret, "/commands/leads/notes", `{"profile_url":"https://www.linkedin.com/in/jane-doe","text":"Met at GopherCon"}`)
+	if notes, _ := body["notes"].([]any); status != http.StatusOK || len(notes) != 1 || notes[0].(map[string]any)["author"] != "api:sam" {
+		t.Errorf("expected the note attached by sam, got %d %v", status, body)
+	}
+	if status, body := post(t, server, reviewSecret, "/commands/leads/attributes", `{"profile_url":"https://linkedin.com/in/Jane-Doe","attributes":{"deal_stage":"demo"}}`); status != http.StatusOK {
+		t.Errorf("expected the attribute set, got %d %v", status, body)
+	}
+	if status, body := post(t, server, reviewSecret, "/commands/leads/attributes", `{"profile_url":"https://www.linkedin.com/in/jane-doe","attributes":{"Deal Stage":"demo"}}`); status != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid key, got %d %v", status, body)
+	}
+
+	status, body = get(t, server, readSecret, "/commands/leads?profile_url=https://www.linkedin.com/in/jane-doe/")
+	if attributes, _ := body["attributes"].(map[string]any); status != http.StatusOK || attributes["deal_stage"] != "demo" || body["profile_url"] != "https://www.linkedin.com/in/jane-doe/" {
+		t.Errorf("expected jane's note and attribute, got %d %v", status, body)
+	}
+	if status, body := get(t, server, readSecret, "/commands/leads?profile_url=https://www.linkedin.com/in/john"); status != http.StatusOK || len(body["notes"].([]any)) != 0 {
+		t.Errorf("expected an empty lead for john, got %d %v", status, body)
+	}
+
+	request, _ := http.NewRequest(http.MethodGet, server.URL+"/commands/drafts", nil)
+	request.Header.Set("Authorization", "Bearer "+reviewSecret)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("GET /commands/drafts failed: %v", err)
+	}
+	defer response.Body.Close()
+	var drafts []Draft
+	json.NewDecoder(response.Body).Decode(&drafts)
+	if len(drafts) != 1 || drafts[0].Lead == nil || drafts[0].Lead.Notes[0].Text != "Met at GopherCon" || drafts[0].Lead.Attributes["deal_stage"] != "demo" {
+		t.Errorf("expected jane's draft to show her note and attribute, got %+v", drafts)
+	}
+}
+
+// TestScreenshotCommands tests that read tokens can list run galleries and fetch their screenshots
+func TestScreenshotCommands(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := gallery.NewRecorder(dir, "2024-06-12-1", 1)
+	if err != nil {
+		t.Fatalf("failed to create gallery: %v", err)
+	}
+	camera := func() ([]byte, string, error) { return []byte("\x89PNG"), "https://www.linkedin.com/in/jane/", nil }
+	if err := recorder.Capture(gallery.ReasonTransition, "invite jane", camera); err != nil {
+		t.Fatalf("capture failed: %v", err)
+	}
+	server := httptest.NewServer(Handler(&memoryQueue{}, control.NewController(), Options{Account: "default", Auth: auth(), Screenshots: dir}))
+	defer server.Close()
+
+	fetch := func(token, path string) (int, string, string) {
+		request, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		request.Header.Set("Authorization", "Bearer "+token)
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		defer response.Body.Close()
+		body, _ := io.ReadAll(response.Body)
+		return response.StatusCode, response.Header.Get("Content-Type"), string(body)
+	}
+
+	status, _, body := fetch(readSecret, "/commands/screenshots")
+	var runs []gallery.Run
+	if status != http.StatusOK || json.Unmarshal([]byte(body), &runs) != nil || len(runs) != 1 || runs[0].RunID != "2024-06-12-1" {
+		t.Fatalf("expected the run's gallery listed, got %d %s", status, body)
+	}
+	status, _, body = fetch(readSecret, "/commands/screenshots/2024-06-12-1")
+	var shots []gallery.Shot
+	if status != http.StatusOK || json.Unmarshal([]byte(body), &shots) != nil || len(shots) != 1 || shots[0].File != "0001.png" {
+		t.Fatalf("expected the run's screenshots, got %d %s", status, body)
+	}
+	status, contentType, body := fetch(readSecret, "/commands/screenshots/2024-06-12-1/0001.png")
+	if status != http.StatusOK || contentType != "image/png" || body != "\x89PNG" {
+		t.Errorf("expected the screenshot, got %d %s %q", status, contentType, body)
+	}
+	for _, path := range []string{"/commands/screenshots/2024-06-12-1/index.json", "/commands/screenshots/other-run"} {
+		if status, _, _ := fetch(readSecret, path); status != http.StatusNotFound {
+			t.Errorf("expected 404 for %s, got %d", path, status)
+		}
+	}
+
+	without := httptest.NewServer(Handler(&memoryQueue{}, control.NewController(), Options{Account: "default", Auth: auth()}))
+	defer without.Close()
+	request, _ := http.NewRequest(http.MethodGet, without.URL+"/commands/screenshots", nil)
+	request.Header.Set("Authorization", "Bearer "+readSecret)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf("expected no screenshot commands without a gallery directory, got %d", response.StatusCode)
+	}
+}
+
+// TestDeadLetterCommands tests listing dead letters with their screenshots, correcting their
+// attributes and requeueing or discarding them in bulk
+func TestDeadLetterCommands(t *testing.T) {
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "json", Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+	store.SetRunID("2024-06-12-1")
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for i, lead := range []string{`{"profile_url":"https://www.linkedin.com/in/jane-doe/","name":"Jane Doe"}`, `{"profile_url":"https://www.linkedin.com/in/john-roe/","name":"John Roe"}`} {
+		key := fmt.Sprintf("lead-%d", i)
+		store.EnqueueJobs([]storage.Job{{Queue: "campaign:spring", Key: key, Payload: lead, EnqueuedAt: now, AvailableAt: now}})
+		job, _, _ := store.LeaseJob("campaign:spring", key, "lease", now, time.Hour)
+		store.DeadLetterJob(job.ID, "lease", storage.DeadLetter{Queue: job.Queue, Key: key, Payload: lead, Attempts: 3, Error: "boom", Fingerprint: "f00d", FailedAt: now})
+	}
+	dir := t.TempDir()
+	recorder, err := gallery.NewRecorder(dir, "2024-06-12-1", 0)
+	if err != nil {
+		t.Fatalf("failed to create gallery: %v", err)
+	}
+	camera := func() ([]byte, string, error) { return []byte("\x89PNG"), "https://www.linkedin.com/in/jane-doe/", nil }
+	recorder.Capture(gallery.ReasonFailure, "invite https://www.linkedin.com/in/jane-doe/: boom", camera)
+
+	server := httptest.NewServer(Handler(&memoryQueue{}, control.NewController(), Options{Account: "default", Auth: auth(), Screenshots: dir, DeadLetters: store}))
+	defer server.Close()
+
+	list := func(token, query string) (int, []DeadLetter) {
+		request, _ := http.NewRequest(http.MethodGet, server.URL+"/commands/dead-letters"+query, nil)
+		request.Header.Set("Authorization", "Bearer "+token)
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("GET /commands/dead-letters failed: %v", err)
+		}
+		defer response.Body.Close()
+		var letters []DeadLetter
+		json.NewDecoder(response.Body).Decode(&letters)
+		return response.StatusCode, letters
+	}
+	if status, _ := list(readSecret, ""); status != http.StatusForbidden {
+		t.Errorf("expected 403 for a read-only token, got %d", status)
+	}
+	status, letters := list(reviewSecret, "?campaign=spring&fingerprint=f00d")
+	if status != http.StatusOK || len(letters) != 2 || letters[0].Campaign != "spring" || letters[0].LeadName != "Jane Doe" {
+		t.Fatalf("expected both dead letters, got %d %+v", status, letters)
+	}
+	if len(letters[0].Screenshots) != 1 || letters[0].Screenshots[0] != "/commands/screenshots/2024-06-12-1/0001.png" || len(letters[1].Screenshots) != 0 {
+		t.Errorf("expected jane's failure screenshot, got %+v", letters)
+	}
+
+	status, body := post(t, server, reviewSecret, fmt.Sprintf("/commands/dead-letters/%d", letters[0].ID), `{"attributes":{"first_name":"Jane"}}`)
+	if attributes, _ := body["attributes"].(map[string]any); status != http.StatusOK || attributes["first_name"] != "Jane" {
+		t.Errorf("expected jane's attribute set, got %d %v", status, body)
+	}
+	if status, _ := post(t, server, reviewSecret, "/commands/dead-letters/999", `{"attributes":{"first_name":"Jane"}}`); status != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown dead letter, got %d", status)
+	}
+
+	if status, _ := post(t, server, reviewSecret, "/commands/dead-letters/requeue", `{"all":true}`); status != http.StatusForbidden {
+		t.Errorf("expected 403 for a review token, got %d", status)
+	}
+	if status, _ := post(t, server, secret, "/commands/dead-letters/discard", `{}`); status != http.StatusBadRequest {
+		t.Errorf("expected 400 for a bulk command selecting nothing, got %d", status)
+	}
+	status, body = post(t, server, secret, "/commands/dead-letters/requeue", fmt.Sprintf(`{"ids":[%d]}`, letters[0].ID))
+	if status != http.StatusOK || body["count"] != float64(1) {
+		t.Errorf("expected jane requeued, got %d %v", status, body)
+	}
+	status, body = post(t, server, secret, "/commands/dead-letters/discard", `{"campaign":"spring"}`)
+	if status != http.StatusOK || body["action"] != "discard" || body["count"] != float64(1) {
+		t.Errorf("expected john discarded, got %d %v", status, body)
+	}
+	if jobs, err := store.GetJobs("campaign:spring"); err != nil || len(jobs) != 1 || !strings.Contains(jobs[0].Payload, `"first_name":"Jane"`) {
+		t.Errorf("expected jane queued with her attribute, got %+v (%v)", jobs, err)
+	}
+}
@@ -0,0 +1,280 @@
+// Package companies looks up the companies leads work at on LinkedIn and caches their size,
+// industry, headquarters, website and follower count for lead scoring and CRM exports.
+package companies
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// Store caches looked-up companies
+type Store interface {
+	GetCompany(key string) (storage.Company, bool, error)
+	SaveCompany(company storage.Company) error
+}
+
+// Source finds a company on LinkedIn by name; Scraper implements it. It reports false when
+// LinkedIn has no company of that name.
+type Source interface {
+	Lookup(ctx context.Context, name string) (storage.Company, bool, error)
+}
+
+// Quota limits LinkedIn searches, e.g. search.RateLimiter; each lookup spends one search
+type Quota interface {
+	Remaining() int
+	RecordSearch()
+}
+
+// Options controls which companies Enrich looks up
+type Options struct {
+	MaxAge time.Duration // Companies fetched longer ago are looked up again; 0 keeps them forever
+	Limit  int           // Lookups per run, 0 for no limit beyond the quota
+
+	// Websites scans company websites for keywords; nil leaves websites alone. Websites are
+	// scanned again after MaxAge, or every run with Rescan, e.g. after the keywords changed.
+	Websites Scanner
+	Rescan   bool
+
+	// Pause is called before each lookup and may block to pause the run there; nil never pauses
+	Pause func(ctx context.Context, position string) error
+}
+
+// Report counts what Enrich did
+type Report struct {
+	Fetched  int  // Companies read from their LinkedIn page
+	NotFound int  // Names LinkedIn has no company for
+	Fresh    int  // Companies already cached within MaxAge
+	Limited  bool // Stopped early by the quota or Limit
+
+	Scanned    int // Websites scanned for keywords
+	ScanFailed int // Websites that could not be fetched; they are tried again next run
+}
+
+// About is what a company's About page lists
+type About struct {
+	Website      string
+	Industry     string
+	Size         string
+	Headquarters string
+}
+
+// employeesPattern finds the lower bound of a size range such as "51-200 employees" or "10,001+ employees"
+var employeesPattern = regexp.MustCompile(`\d[\d,.]*`)
+
+// Enrich looks up the companies named that are not cached yet, or were fetched longer ago than
+// MaxAge, and caches them. Names LinkedIn has no company for are cached empty, so they are not
+// searched again until they go stale. With Websites set, the websites of cached companies are
+// scanned as well; scans spend no LinkedIn quota.
+func Enrich(ctx context.Context, store Store, source Source, quota Quota, names []string, options Options, now time.Time) (Report, error) {
+	var report Report
+	seen := make(map[string]bool)
+	for _, name := range names {
+		key := identity.NormalizeCompany(name)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		cached, found, err := store.GetCompany(key)
+		if err != nil {
+			return report, fmt.Errorf("failed to read company cache: %w", err)
+		}
+		if found && (options.MaxAge <= 0 || now.Sub(cached.FetchedAt) < options.MaxAge) {
+			report.Fresh++
+			if scanDue(cached, options, now) && scan(ctx, &cached, options.Websites, now, &report) {
+				if err := store.SaveCompany(cached); err != nil {
+					return report, fmt.Errorf("failed to cache company: %w", err)
+				}
+			}
+			continue
+		}
+
+		if (options.Limit > 0 && report.Fetched+report.NotFound >= options.Limit) || (quota != nil && quota.Remaining() < 1) {
+			report.Limited = true
+			return report, nil
+		}
+		if options.Pause != nil {
+			if err := options.Pause(ctx, "company "+name); err != nil {
+				return report, err
+			}
+		}
+
+		company, found, err := source.Lookup(ctx, name)
+		if quota != nil {
+			quota.RecordSearch()
+		}
+		if err != nil {
+			return report, fmt.Errorf("failed to look up company %q: %w", name, err)
+		}
+		if found {
+			report.Fetched++
+		} else {
+			company = storage.Company{}
+			report.NotFound++
+		}
+		company.Key, company.FetchedAt = key, now
+		if found && company.Website == cached.Website {
+			// Keep the last scan until the website is scanned again
+			company.Keywords, company.ScannedAt = cached.Keywords, cached.ScannedAt
+		}
+		if scanDue(company, options, now) {
+			scan(ctx, &company, options.Websites, now, &report)
+		}
+		if err := store.SaveCompany(company); err != nil {
+			return report, fmt.Errorf("failed to cache company: %w", err)
+		}
+	}
+	return report, nil
+}
+
+// scanDue reports whether a company's website should be scanned for keywords
+func scanDue(company storage.Company, options Options, now time.Time) bool {
+	if options.Websites == nil || company.Website == "" {
+		return false
+	}
+	return options.Rescan || company.ScannedAt.IsZero() || (options.MaxAge > 0 && now.Sub(company.ScannedAt) >= options.MaxAge)
+}
+
+// scan records the keywords company's website mentions and reports whether it could be scanned.
+// A website that cannot be fetched says nothing about the keywords, so its last scan is kept.
+func scan(ctx context.Context, company *storage.Company, websites Scanner, now time.Time, report *Report) bool {
+	keywords, err := websites.Scan(ctx, company.Website)
+	if err != nil {
+		report.ScanFailed++
+		return false
+	}
+	company.Keywords, company.ScannedAt = keywords, now
+	report.Scanned++
+	return true
+}
+
+// Names lists the distinct companies of results, those most leads work at first
+func Names(results []storage.ProfileResult) []string {
+	counts := make(map[string]int)
+	names := make(map[string]string)
+	for _, result := range results {
+		key := identity.NormalizeCompany(result.Company)
+		if key == "" {
+			continue
+		}
+		if counts[key] == 0 {
+			names[key] = strings.TrimSpace(result.Company)
+		}
+		counts[key]++
+	}
+
+	keys := make([]string, 0, len(names))
+	for key := range names {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	sorted := make([]string, len(keys))
+	for i, key := range keys {
+		sorted[i] = names[key]
+	}
+	return sorted
+}
+
+// ForLead returns the cached company a lead's profile names, if it was found on LinkedIn
+func ForLead(store Store, company string) (storage.Company, bool, error) {
+	key := identity.NormalizeCompany(company)
+	if key == "" {
+		return storage.Company{}, false, nil
+	}
+	cached, found, err := store.GetCompany(key)
+	if err != nil || !found || cached.URL == "" {
+		return storage.Company{}, false, err
+	}
+	return cached, true, nil
+}
+
+// Employees returns the lower bound of a company size such as "51-200 employees", or 0 if unknown
+func Employees(size string) int {
+	match := employeesPattern.FindString(size)
+	count, err := strconv.Atoi(strings.NewReplacer(",", "", ".", "").Replace(match))
+	if err != nil {
+		return 0
+	}
+	return count
+}
+
+// CompanyURL returns the canonical company page URL for a link to a company page, or "" for
+// other links
+func CompanyURL(href string) string {
+	parsed, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return ""
+	}
+	if parsed.Host != "" && !strings.HasSuffix(strings.ToLower(parsed.Host), "linkedin.com") {
+		return ""
+	}
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(segments) < 2 || segments[0] != "company" || segments[1] == "" {
+		return ""
+	}
+	return "https://www.linkedin.com/company/" + segments[1] + "/"
+}
+
+// ParseAbout reads the fields of a company's About page from its text. Labels stand on their own
+// line above their value, as on the desktop layout, or before a colon on the same line.
+func ParseAbout(text string) About {
+	var about About
+	var pending *string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.Join(strings.Fields(identity.StripFormatting(line)), " ")
+		if line == "" {
+			continue
+		}
+
+		label, value, _ := strings.Cut(line, ":")
+		if field := about.field(label); field != nil {
+			pending = nil
+			if value = strings.TrimSpace(value); value == "" {
+				pending = field
+			} else if *field == "" {
+				*field = value
+			}
+			continue
+		}
+		if pending != nil {
+			if *pending == "" {
+				*pending = line
+			}
+			pending = nil
+		}
+	}
+	return about
+}
+
+// field returns the field an About page label holds, or nil for other lines
+func (a *About) field(label string) *string {
+	switch strings.ToLower(strings.TrimSpace(label)) {
+	case "website":
+		return &a.Website
+	case "industry":
+		return &a.Industry
+	case "company size":
+		return &a.Size
+	case "headquarters":
+		return &a.Headquarters
+	}
+	return nil
+}
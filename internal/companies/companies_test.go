@@ -0,0 +1,315 @@
+package companies
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/browser/browsertest"
+	"linkedin-automation-framework/internal/selectors"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// TestParseAbout tests reading the About page fields from both label layouts
+func TestParseAbout(t *testing.T) {
+	desktop := "Overview\nAcme builds rockets.\nWebsite\nhttps://acme.example\nIndustry\nAviation and Aerospace\n" +
+		"Company size\n51-200 employees\n143 associated members\nHeadquarters\nBerlin, Germany\nFounded\n2010"
+	want := About{Website: "https://acme.example", Industry: "Aviation and Aerospace", Size: "51-200 employees", Headquarters: "Berlin, Germany"}
+	if about := ParseAbout(desktop); about != want {
+		t.Errorf("desktop layout: expected %+v, got %+v", want, about)
+	}
+
+	mobile := "Website: https://acme.example\n  Industry:  Aviation   and Aerospace\nCompany size: 51-200 employees\nHeadquarters: Berlin, Germany"
+	if about := ParseAbout(mobile); about != want {
+		t.Errorf("mobile layout: expected %+v, got %+v", want, about)
+	}
+
+	if about := ParseAbout("Website\nIndustry\nSoftware Development"); about.Website != "" || about.Industry != "Software Development" {
+		t.Errorf("expected a label without a value to stay empty, got %+v", about)
+	}
+}
+
+func TestEmployees(t *testing.T) {
+	for size, want := range map[string]int{
+		"51-200 employees":         51,
+		"10,001+ employees":        10001,
+		"2-10 employees":           2,
+		"1.001-5.000 Beschäftigte": 1001,
+		"":                         0,
+		"Myself only":              0,
+	} {
+		if got := Employees(size); got != want {
+			t.Errorf("Employees(%q) = %d, want %d", size, got, want)
+		}
+	}
+}
+
+func TestCompanyURL(t *testing.T) {
+	for href, want := range map[string]string{
+		"https://www.linkedin.com/company/acme/?trk=search": "https://www.linkedin.com/company/acme/",
+		"/company/acme/about/":                              "https://www.linkedin.com/company/acme/",
+		"https://linkedin.com/company/acme":                 "https://www.linkedin.com/company/acme/",
+		"https://www.linkedin.com/in/jane-doe/":             "",
+		"https://acme.example/company/acme/":                "",
+		"https://www.linkedin.com/company/":                 "",
+	} {
+		if got := CompanyURL(href); got != want {
+			t.Errorf("CompanyURL(%q) = %q, want %q", href, got, want)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	results := []storage.ProfileResult{
+		{Company: "Globex"},
+		{Company: "Acme Inc."},
+		{Company: ""},
+		{Company: "ACME"},
+	}
+	names := Names(results)
+	if len(names) != 2 || names[0] != "Acme Inc." || names[1] != "Globex" {
+		t.Errorf("expected Acme first as the most common, got %v", names)
+	}
+}
+
+// fakeSource answers lookups from a map, counting them
+type fakeSource struct {
+	companies map[string]storage.Company
+	lookups   []string
+}
+
+func (f *fakeSource) Lookup(ctx context.Context, name string) (storage.Company, bool, error) {
+	f.lookups = append(f.lookups, name)
+	company, found := f.companies[name]
+	return company, found, nil
+}
+
+// fakeQuota allows a fixed number of searches
+type fakeQuota struct {
+	remaining int
+}
+
+func (q *fakeQuota) Remaining() int { return q.remaining }
+func (q *fakeQuota) RecordSearch()  { q.remaining-- }
+
+// TestEnrich tests that only missing and stale companies are looked up, within the quota, and
+// that names LinkedIn does not know are cached too
+func TestEnrich(t *testing.T) {
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "json", Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for _, company := range []storage.Company{
+		{Key: "globex", URL: "https://www.linkedin.com/company/globex/", Name: "Globex", FetchedAt: now.Add(-24 * time.Hour)},
+		{Key: "initech", URL: "https://www.linkedin.com/company/initech/", Name: "Initech", FetchedAt: now.Add(-100 * 24 * time.Hour)},
+	} {
+		if err := store.SaveCompany(company); err != nil {
+			t.Fatalf("failed to save company: %v", err)
+		}
+	}
+
+	source := &fakeSource{companies: map[string]storage.Company{
+		"Acme Inc": {URL: "https://www.linkedin.com/company/acme/", Name: "Acme", Size: "11-50 employees"},
+		"Initech":  {URL: "https://www.linkedin.com/company/initech/", Name: "Initech", Size: "201-500 employees"},
+	}}
+	names := []string{"Acme Inc", "ACME", "Globex", "Initech", "Unknown Labs", "Hooli"}
+	report, err := Enrich(context.Background(), store, source, &fakeQuota{remaining: 3}, names, Options{MaxAge: 90 * 24 * time.Hour}, now)
+	if err != nil {
+		t.Fatalf("enrich failed: %v", err)
+	}
+	if report != (Report{Fetched: 2, NotFound: 1, Fresh: 1, Limited: true}) {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if len(source.lookups) != 3 {
+		t.Errorf("expected Acme, Initech and Unknown Labs looked up, got %v", source.lookups)
+	}
+
+	acme, found, err := ForLead(store, "acme, inc.")
+	if err != nil || !found || acme.Size != "11-50 employees" || !acme.FetchedAt.Equal(now) {
+		t.Errorf("expected Acme linked to the lead's company, got %+v, %v (%v)", acme, found, err)
+	}
+	if initech, _, _ := store.GetCompany("initech"); initech.Size != "201-500 employees" {
+		t.Errorf("expected stale Initech refetched, got %+v", initech)
+	}
+	if _, found, _ := ForLead(store, "Unknown Labs"); found {
+		t.Error("expected a company LinkedIn does not know not to link")
+	}
+	if cached, found, _ := store.GetCompany("unknown labs"); !found || cached.URL != "" {
+		t.Errorf("expected the unknown company cached empty, got %+v, %v", cached, found)
+	}
+
+	report, err = Enrich(context.Background(), store, source, nil, append(names, "Umbrella"), Options{Limit: 1}, now)
+	if err != nil || report.Fetched+report.NotFound != 1 || !report.Limited {
+		t.Errorf("expected one lookup within the limit, got %+v (%v)", report, err)
+	}
+}
+
+// TestScraperLookup tests searching for a company and reading its About page
+func TestScraperLookup(t *testing.T) {
+	set := selectors.Desktop
+	page := browsertest.NewPage("https://www.linkedin.com/feed/")
+	page.Route(SearchURL("Acme Inc"), func(page *browsertest.Page) {
+		page.Append(
+			browsertest.NewElement(set.CompanyLinks[0]).SetAttribute("href", "https://www.linkedin.com/company/acme-robotics/").SetText("Acme Robotics"),
+			browsertest.NewElement(set.CompanyLinks[0]).SetAttribute("href", "https://www.linkedin.com/company/acme/?trk=x").SetText("Acme"),
+		)
+	})
+	page.Route("https://www.linkedin.com/company/acme/about/", func(page *browsertest.Page) {
+		page.Append(
+			browsertest.NewElement(set.CompanyFollowers[0]).SetText("Aviation · Berlin · 12,345 followers"),
+			browsertest.NewElement(set.CompanyAbout[0]).Append(
+				browsertest.NewElement("dt").SetText("Industry"),
+				browsertest.NewElement("dd").SetText("Aviation"),
+				browsertest.NewElement("dt").SetText("Company size"),
+				browsertest.NewElement("dd").SetText("51-200 employees"),
+			),
+		)
+	})
+
+	scraper := NewScraper(page, set)
+	company, found, err := scraper.Lookup(context.Background(), "Acme Inc")
+	if err != nil || !found {
+		t.Fatalf("expected Acme to be found, got %v (%v)", found, err)
+	}
+	want := storage.Company{URL: "https://www.linkedin.com/company/acme/", Name: "Acme", Industry: "Aviation", Size: "51-200 employees", Followers: 12345}
+	if !reflect.DeepEqual(company, want) {
+		t.Errorf("expected %+v, got %+v", want, company)
+	}
+
+	if _, found, err := scraper.Lookup(context.Background(), "Globex"); err != nil || found {
+		t.Errorf("expected no match among unrelated results, got %v (%v)", found, err)
+	}
+}
+
+// TestWebsiteScanner tests keyword matching on a company's home page
+func TestWebsiteScanner(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`<html><head><meta name="generator" content="WordPress 6.4"><script src="/js/react.min.js"></script></head>` +
+			`<body>We run on KUBERNETES and C++. Goodbye, legacy!</body></html>`))
+	}))
+	defer server.Close()
+
+	scanner := NewWebsiteScanner([]string{"Go", "kubernetes", "C++", "wordpress", "React", " ", "Salesforce"}, server.Client())
+	found, err := scanner.Scan(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	if want := []string{"kubernetes", "C++", "wordpress", "React"}; !reflect.DeepEqual(found, want) {
+		t.Errorf("expected %v, got %v", want, found)
+	}
+
+	if _, err := scanner.Scan(context.Background(), server.URL+"/gone"); err == nil {
+		t.Error("expected an error for a missing page")
+	}
+	if _, err := scanner.Scan(context.Background(), "ftp://acme.example"); err == nil {
+		t.Error("expected an error for a non-http website")
+	}
+}
+
+func TestWebsiteURL(t *testing.T) {
+	for website, want := range map[string]string{
+		"acme.example":              "https://acme.example",
+		" http://acme.example/en ":  "http://acme.example/en",
+		"https://www.acme.example/": "https://www.acme.example/",
+	} {
+		if got, err := WebsiteURL(website); err != nil || got != want {
+			t.Errorf("WebsiteURL(%q) = %q (%v), want %q", website, got, err, want)
+		}
+	}
+}
+
+// TestDefaultClientRefusesPrivateAddresses tests that websites cannot point the scanner inside the network
+func TestDefaultClientRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("internal"))
+	}))
+	defer server.Close()
+
+	if _, err := NewWebsiteScanner([]string{"internal"}, nil).Scan(context.Background(), server.URL); err == nil {
+		t.Error("expected the default client to refuse a loopback address")
+	}
+}
+
+// fakeScanner answers scans from a map, failing for websites it does not know
+type fakeScanner struct {
+	keywords map[string][]string
+	scans    int
+}
+
+func (f *fakeScanner) Scan(ctx context.Context, website string) ([]string, error) {
+	f.scans++
+	keywords, ok := f.keywords[website]
+	if !ok {
+		return nil, errors.New("unreachable")
+	}
+	return keywords, nil
+}
+
+// TestEnrichScansWebsites tests that websites are scanned once per MaxAge, including those of
+// companies cached before scanning was turned on, and that failed scans keep the last result
+func TestEnrichScansWebsites(t *testing.T) {
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "json", Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	if err := store.SaveCompany(storage.Company{Key: "globex", URL: "https://www.linkedin.com/company/globex/", Website: "globex.example", FetchedAt: now}); err != nil {
+		t.Fatalf("failed to save company: %v", err)
+	}
+	source := &fakeSource{companies: map[string]storage.Company{
+		"Acme":     {URL: "https://www.linkedin.com/company/acme/", Name: "Acme", Website: "acme.example"},
+		"Hooli":    {URL: "https://www.linkedin.com/company/hooli/", Name: "Hooli", Website: "hooli.example"},
+		"Vandelay": {URL: "https://www.linkedin.com/company/vandelay/", Name: "Vandelay"},
+	}}
+	scanner := &fakeScanner{keywords: map[string][]string{
+		"acme.example":   {"Go"},
+		"globex.example": nil,
+	}}
+	options := Options{MaxAge: 90 * 24 * time.Hour, Websites: scanner}
+	names := []string{"Acme", "Globex", "Hooli", "Vandelay"}
+
+	report, err := Enrich(context.Background(), store, source, nil, names, options, now)
+	if err != nil {
+		t.Fatalf("enrich failed: %v", err)
+	}
+	if report.Scanned != 2 || report.ScanFailed != 1 || scanner.scans != 3 {
+		t.Errorf("expected Acme and Globex scanned and Hooli failed, got %+v after %d scans", report, scanner.scans)
+	}
+	if acme, _, _ := store.GetCompany("acme"); !reflect.DeepEqual(acme.Keywords, []string{"Go"}) || !acme.ScannedAt.Equal(now) {
+		t.Errorf("expected Acme's keywords stored, got %+v", acme)
+	}
+	if globex, _, _ := store.GetCompany("globex"); len(globex.Keywords) != 0 || !globex.ScannedAt.Equal(now) {
+		t.Errorf("expected the cached Globex scanned without matches, got %+v", globex)
+	}
+	if hooli, _, _ := store.GetCompany("hooli"); !hooli.ScannedAt.IsZero() {
+		t.Errorf("expected Hooli left unscanned, got %+v", hooli)
+	}
+
+	scanner.scans = 0
+	if _, err := Enrich(context.Background(), store, source, nil, names, options, now.Add(time.Hour)); err != nil || scanner.scans != 1 {
+		t.Errorf("expected only Hooli scanned again, got %d scans (%v)", scanner.scans, err)
+	}
+
+	scanner.scans = 0
+	options.Rescan = true
+	delete(scanner.keywords, "acme.example")
+	if _, err := Enrich(context.Background(), store, source, nil, names, options, now.Add(2*time.Hour)); err != nil || scanner.scans != 3 {
+		t.Errorf("expected every website scanned again, got %d scans (%v)", scanner.scans, err)
+	}
+	if acme, _, _ := store.GetCompany("acme"); !reflect.DeepEqual(acme.Keywords, []string{"Go"}) {
+		t.Errorf("expected a failed rescan to keep Acme's keywords, got %+v", acme)
+	}
+}
@@ -0,0 +1,98 @@
+package companies
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"linkedin-automation-framework/internal/browser"
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/search"
+	"linkedin-automation-framework/internal/selectors"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// searchURL is LinkedIn's company search
+const searchURL = "https://www.linkedin.com/search/results/companies/?keywords="
+
+// Scraper looks companies up in a logged-in LinkedIn page: it searches for the name, opens the
+// first result with the same name and reads its About page
+type Scraper struct {
+	page      browser.PageDriver
+	selectors selectors.Set
+}
+
+// NewScraper creates a scraper that finds a company through LinkedIn's company search and reads
+// its website, industry, size, headquarters and followers from its About page
+func NewScraper(page browser.PageDriver, set selectors.Set) *Scraper {
+	return &Scraper{page: page, selectors: set}
+}
+
+// SearchURL returns the company search for name
+func SearchURL(name string) string {
+	return searchURL + url.QueryEscape(name)
+}
+
+// Lookup finds the company named name. A result only counts when its name normalizes to the
+// same as name, so "Acme" does not pick up "Acme Robotics".
+func (s *Scraper) Lookup(ctx context.Context, name string) (storage.Company, bool, error) {
+	page := s.page.Context(ctx)
+	if err := open(page, SearchURL(name)); err != nil {
+		return storage.Company{}, false, err
+	}
+
+	company, found := s.findResult(page, identity.NormalizeCompany(name))
+	if !found {
+		return storage.Company{}, false, nil
+	}
+
+	if err := open(page, company.URL+"about/"); err != nil {
+		return storage.Company{}, false, err
+	}
+	about := browser.FirstText(page, s.selectors.CompanyAbout)
+	if about == "" {
+		return storage.Company{}, false, fmt.Errorf("no About section on %s", company.URL)
+	}
+	details := ParseAbout(about)
+	company.Website, company.Industry, company.Size, company.Headquarters = details.Website, details.Industry, details.Size, details.Headquarters
+	if summary := browser.FirstText(page, s.selectors.CompanyFollowers); summary != "" {
+		company.Followers = search.ExtractFollowers(summary)
+	}
+	return company, true, nil
+}
+
+// findResult returns the first company search result named key
+func (s *Scraper) findResult(page browser.PageDriver, key string) (storage.Company, bool) {
+	for _, selector := range s.selectors.CompanyLinks {
+		links, err := page.Elements(selector)
+		if err != nil {
+			continue
+		}
+		for _, link := range links {
+			href, err := link.Attribute("href")
+			if err != nil || href == nil {
+				continue
+			}
+			companyURL := CompanyURL(*href)
+			text, err := link.Text()
+			if companyURL == "" || err != nil {
+				continue
+			}
+			if name := search.CleanText(text); identity.NormalizeCompany(name) == key {
+				return storage.Company{URL: companyURL, Name: name}, true
+			}
+		}
+	}
+	return storage.Company{}, false
+}
+
+// open navigates to pageURL and waits for it to load
+func open(page browser.PageDriver, pageURL string) error {
+	if err := page.Navigate(pageURL); err != nil {
+		return fmt.Errorf("failed to open %s: %w", pageURL, err)
+	}
+	if err := page.WaitLoad(); err != nil {
+		return fmt.Errorf("failed to wait for %s to load: %w", pageURL, err)
+	}
+	return nil
+}
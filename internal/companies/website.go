@@ -0,0 +1,116 @@
+package companies
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// MaxPageSize caps how much of a website's page is read
+const MaxPageSize = 2 << 20
+
+// Scanner reports which keywords a company website mentions; WebsiteScanner implements it
+type Scanner interface {
+	Scan(ctx context.Context, website string) ([]string, error)
+}
+
+// WebsiteScanner fetches a company's home page, outside LinkedIn, and looks for keywords in it.
+// The page's HTML is searched as served, so script, stylesheet and generator names count too:
+// "react", "HubSpot" or "WordPress" find the technologies a site is built with.
+type WebsiteScanner struct {
+	client   *http.Client
+	keywords []string
+	patterns []*regexp.Regexp
+}
+
+// NewWebsiteScanner creates a scanner for keywords, matched case-insensitively as whole words. A
+// nil client uses one with a 10s timeout that only connects to public addresses, since websites
+// come from company pages anyone can edit.
+func NewWebsiteScanner(keywords []string, client *http.Client) *WebsiteScanner {
+	if client == nil {
+		dialer := &net.Dialer{Timeout: 10 * time.Second, Control: publicOnly}
+		client = &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{DialContext: dialer.DialContext},
+		}
+	}
+	scanner := &WebsiteScanner{client: client}
+	for _, keyword := range keywords {
+		if keyword = strings.TrimSpace(keyword); keyword == "" {
+			continue
+		}
+		scanner.keywords = append(scanner.keywords, keyword)
+		scanner.patterns = append(scanner.patterns, regexp.MustCompile(`(?i)(?:^|[^\pL\pN])`+regexp.QuoteMeta(keyword)+`(?:$|[^\pL\pN])`))
+	}
+	return scanner
+}
+
+// Scan fetches website and returns the keywords it mentions, in the order they were configured
+func (s *WebsiteScanner) Scan(ctx context.Context, website string) ([]string, error) {
+	pageURL, err := WebsiteURL(website)
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", pageURL, err)
+	}
+	request.Header.Set("Accept", "text/html")
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", pageURL, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s returned %s", pageURL, response.Status)
+	}
+	page, err := io.ReadAll(io.LimitReader(response.Body, MaxPageSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", pageURL, err)
+	}
+
+	var found []string
+	for i, pattern := range s.patterns {
+		if pattern.Match(page) {
+			found = append(found, s.keywords[i])
+		}
+	}
+	return found, nil
+}
+
+// WebsiteURL turns a website as company pages list it, often without a scheme, into an http(s) URL
+func WebsiteURL(website string) (string, error) {
+	website = strings.TrimSpace(website)
+	if !strings.Contains(website, "://") {
+		website = "https://" + website
+	}
+	parsed, err := url.Parse(website)
+	if err != nil || parsed.Host == "" {
+		return "", fmt.Errorf("invalid website %q", website)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", fmt.Errorf("website %q is not an http(s) URL", website)
+	}
+	return parsed.String(), nil
+}
+
+// publicOnly refuses connections to loopback, private, link-local and unspecified addresses
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("refusing to connect to non-public address %s", host)
+	}
+	return nil
+}
@@ -2,21 +2,55 @@ package connect
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"strings"
 	"time"
 
-	"github.com/go-rod/rod"
-	
+	"linkedin-automation-framework/internal/browser"
 	"linkedin-automation-framework/internal/errors"
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/selectors"
+	"linkedin-automation-framework/internal/stealth"
+	"linkedin-automation-framework/internal/warnings"
 )
 
 // ConnectionManager interface for LinkedIn connection requests
 type ConnectionManager interface {
-	SendConnectionRequest(ctx context.Context, page *rod.Page, profile ProfileResult, note string) error
-	DetectConnectButton(ctx context.Context, page *rod.Page) (*rod.Element, error)
+	SendConnectionRequest(ctx context.Context, page browser.PageDriver, profile ProfileResult, note string) error
+	DetectConnectButton(ctx context.Context, page browser.PageDriver) (browser.ElementDriver, error)
 	TrackSentRequest(request ConnectionRequest) error
-	NavigateToProfile(ctx context.Context, page *rod.Page, profileURL string) error
+	NavigateToProfile(ctx context.Context, page browser.PageDriver, profileURL string) error
+}
+
+// ErrAlreadyConnected is the cause of the error returned when the profile is already in the network
+var ErrAlreadyConnected = stderrors.New("already connected")
+
+// ErrEmailRequired is the cause of the error returned when the member only accepts invites with their email address
+var ErrEmailRequired = stderrors.New("invite requires the member's email address")
+
+// SkipReasonEmailRequired is recorded for leads whose invite asks for an email address that is not known
+const SkipReasonEmailRequired = "email_required"
+
+// ErrInviteLimit is the cause of the error returned once LinkedIn stops accepting invitations for now
+var ErrInviteLimit = errors.NewCoded(errors.CodeRateLimit, "invitation limit reached")
+
+// InviteLimitBackoff is how long invitations stop after LinkedIn's weekly limit is reached
+const InviteLimitBackoff = 7 * 24 * time.Hour
+
+// InviteLimitError reports the limit surface LinkedIn showed and when invitations may be tried again
+type InviteLimitError struct {
+	Kind       warnings.Kind
+	RetryAfter time.Time
+}
+
+func (e *InviteLimitError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", e.Kind, e.RetryAfter.Format(time.RFC3339))
+}
+
+// Unwrap lets errors.Is match ErrInviteLimit
+func (e *InviteLimitError) Unwrap() error {
+	return ErrInviteLimit
 }
 
 // ProfileResult represents a profile to connect with
@@ -29,6 +63,7 @@ type ProfileResult struct {
 	Mutual      int
 	Premium     bool
 	Timestamp   time.Time
+	Email       string // Known address, e.g. from enrichment, for invites that ask for one
 }
 
 // ConnectionRequest represents a sent connection request
@@ -38,6 +73,7 @@ type ConnectionRequest struct {
 	Note        string
 	SentAt      time.Time
 	Status      string // pending, accepted, declined
+	Template    string // Template the note was rendered from, "" for a blank invite
 }
 
 // ConnectManager implements ConnectionManager interface
@@ -47,6 +83,10 @@ type ConnectManager struct {
 	stealth      StealthInterface
 	errorHandler *errors.RodErrorHandler
 	recovery     *errors.GracefulErrorRecovery
+	selectors    selectors.Set
+	network      NetworkInterface
+	limit        *InviteLimitError // Set once LinkedIn reports the invitation limit
+	sleep        func(context.Context, time.Duration) error // Waits for dialogs to open and requests to go through
 }
 
 // StorageInterface defines storage operations needed by connect
@@ -55,6 +95,16 @@ type StorageInterface interface {
 	GetSentRequests() ([]ConnectionRequest, error)
 }
 
+// SkipRecorder is implemented by storage that keeps why a lead was passed over
+type SkipRecorder interface {
+	RecordSkip(profileURL, reason string) error
+}
+
+// NetworkInterface reports whether a profile is already a first-degree connection
+type NetworkInterface interface {
+	InNetwork(profileURL, name, company string) (bool, string)
+}
+
 // RateLimiterInterface defines rate limiting operations
 type RateLimiterInterface interface {
 	CanSendConnection() bool
@@ -63,24 +113,36 @@ type RateLimiterInterface interface {
 
 // StealthInterface defines stealth operations needed by connect
 type StealthInterface interface {
-	HumanMouseMove(ctx context.Context, page *rod.Page, target *rod.Element) error
-	HumanType(ctx context.Context, element *rod.Element, text string) error
-	RandomDelay(min, max time.Duration) error
+	MoveMouse(ctx context.Context, page browser.PageDriver, target browser.ElementDriver) error
+	TypeText(ctx context.Context, element browser.ElementDriver, text string) error
+	RandomDelay(ctx context.Context, min, max time.Duration) error
 }
 
 // NewConnectManager creates a new connection manager
-func NewConnectManager(storage StorageInterface, rateLimiter RateLimiterInterface, stealth StealthInterface) *ConnectManager {
+func NewConnectManager(storage StorageInterface, rateLimiter RateLimiterInterface, stealthBehavior StealthInterface) *ConnectManager {
 	return &ConnectManager{
 		storage:      storage,
 		rateLimiter:  rateLimiter,
-		stealth:      stealth,
+		stealth:      stealthBehavior,
 		errorHandler: errors.NewRodErrorHandler(30 * time.Second),
 		recovery:     errors.NewGracefulErrorRecovery(nil),
+		selectors:    selectors.Desktop,
+		sleep:        stealth.Sleep,
 	}
 }
 
+// SetSelectors switches the selectors used on profile pages, e.g. to selectors.Mobile
+func (cm *ConnectManager) SetSelectors(set selectors.Set) {
+	cm.selectors = set
+}
+
+// SetNetwork enables the already-connected check before each invitation
+func (cm *ConnectManager) SetNetwork(network NetworkInterface) {
+	cm.network = network
+}
+
 // NavigateToProfile navigates to a LinkedIn profile page using Rod methods
-func (cm *ConnectManager) NavigateToProfile(ctx context.Context, page *rod.Page, profileURL string) error {
+func (cm *ConnectManager) NavigateToProfile(ctx context.Context, page browser.PageDriver, profileURL string) error {
 	if page == nil {
 		return fmt.Errorf("page cannot be nil")
 	}
@@ -94,8 +156,8 @@ func (cm *ConnectManager) NavigateToProfile(ctx context.Context, page *rod.Page,
 		return fmt.Errorf("invalid LinkedIn profile URL: %s", profileURL)
 	}
 
-	// Navigate to the profile page
-	err := page.Navigate(profileURL)
+	// Navigate to the canonical profile URL, without tracking parameters
+	err := page.Navigate(identity.NormalizeProfileURL(profileURL))
 	if err != nil {
 		return fmt.Errorf("failed to navigate to profile %s: %w", profileURL, err)
 	}
@@ -108,7 +170,7 @@ func (cm *ConnectManager) NavigateToProfile(ctx context.Context, page *rod.Page,
 
 	// Add a small delay to ensure page is fully rendered
 	if cm.stealth != nil {
-		err = cm.stealth.RandomDelay(1*time.Second, 3*time.Second)
+		err = cm.stealth.RandomDelay(ctx, 1*time.Second, 3*time.Second)
 		if err != nil {
 			return fmt.Errorf("failed to add navigation delay: %w", err)
 		}
@@ -118,23 +180,13 @@ func (cm *ConnectManager) NavigateToProfile(ctx context.Context, page *rod.Page,
 }
 
 // DetectConnectButton detects Connect buttons using Rod selectors
-func (cm *ConnectManager) DetectConnectButton(ctx context.Context, page *rod.Page) (*rod.Element, error) {
+func (cm *ConnectManager) DetectConnectButton(ctx context.Context, page browser.PageDriver) (browser.ElementDriver, error) {
 	if page == nil {
 		return nil, fmt.Errorf("page cannot be nil")
 	}
 
-	// Common LinkedIn Connect button selectors
-	selectors := []string{
-		`button[aria-label*="Connect"]`,
-		`button[data-control-name="connect"]`,
-		`button:has-text("Connect")`,
-		`.pv-s-profile-actions button:has-text("Connect")`,
-		`button[data-test-id="connect-cta"]`,
-		`.artdeco-button--primary:has-text("Connect")`,
-	}
-
 	// Try each selector to find the Connect button
-	for _, selector := range selectors {
+	for _, selector := range cm.selectors.ConnectButton {
 		element, err := page.Element(selector)
 		if err == nil && element != nil {
 			// Verify the element is visible and clickable
@@ -157,8 +209,8 @@ func (cm *ConnectManager) DetectConnectButton(ctx context.Context, page *rod.Pag
 			continue
 		}
 
-		// Check if button text contains "Connect" (case insensitive)
-		if strings.Contains(strings.ToLower(text), "connect") {
+		// Check if button text says "Connect" in one of the interface languages (case insensitive)
+		if selectors.Matches(selectors.LabelConnect, text, cm.selectors.Languages) {
 			visible, err := button.Visible()
 			if err == nil && visible {
 				return button, nil
@@ -167,7 +219,7 @@ func (cm *ConnectManager) DetectConnectButton(ctx context.Context, page *rod.Pag
 
 		// Also check aria-label
 		ariaLabel, err := button.Attribute("aria-label")
-		if err == nil && ariaLabel != nil && strings.Contains(strings.ToLower(*ariaLabel), "connect") {
+		if err == nil && ariaLabel != nil && selectors.Matches(selectors.LabelConnect, *ariaLabel, cm.selectors.Languages) {
 			visible, err := button.Visible()
 			if err == nil && visible {
 				return button, nil
@@ -175,12 +227,41 @@ func (cm *ConnectManager) DetectConnectButton(ctx context.Context, page *rod.Pag
 		}
 	}
 
-	return nil, fmt.Errorf("no Connect button found on the page")
+	return nil, errors.NewError(errors.ErrorTypeTransient, "detect_connect_button", "no Connect button found on the page", nil).
+		WithContext(errors.ContextSelector, strings.Join(cm.selectors.ConnectButton, ", ")).WithCode(errors.CodeSelectorMiss)
+}
+
+// InviteResult describes how an invitation was sent
+type InviteResult struct {
+	NoteSent bool // False for blank invites, including those sent without a note after the note field failed
 }
 
 // SendConnectionRequest sends a connection request with optional personalized note
-func (cm *ConnectManager) SendConnectionRequest(ctx context.Context, page *rod.Page, profile ProfileResult, note string) error {
-	return cm.recovery.SafeExecute("send_connection_request", func() error {
+func (cm *ConnectManager) SendConnectionRequest(ctx context.Context, page browser.PageDriver, profile ProfileResult, note string) error {
+	_, err := cm.SendInvite(ctx, page, profile, note)
+	return err
+}
+
+// SendInvite sends a connection request like SendConnectionRequest and reports whether the note
+// went with it. When the note field cannot be found or filled, the invitation is sent without a
+// note instead of failing.
+func (cm *ConnectManager) SendInvite(ctx context.Context, page browser.PageDriver, profile ProfileResult, note string) (InviteResult, error) {
+	var result InviteResult
+	err := cm.recovery.SafeExecute("send_connection_request", func() error {
+		// Never re-invite someone already connected through another account or a manual connect
+		if cm.network != nil {
+			if connected, reason := cm.network.InNetwork(profile.URL, profile.Name, profile.Company); connected {
+				return errors.NewError(errors.ErrorTypePermanent, "send_connection_request",
+					fmt.Sprintf("profile is already in the network (%s)", reason), ErrAlreadyConnected)
+			}
+		}
+
+		// After the invitation limit, fail fast instead of clicking buttons that no longer send
+		if cm.limit != nil && time.Now().Before(cm.limit.RetryAfter) {
+			return errors.NewError(errors.ErrorTypePermanent, "send_connection_request",
+				"invitation limit reached", cm.limit)
+		}
+
 		// Check rate limiting first
 		if cm.rateLimiter != nil && !cm.rateLimiter.CanSendConnection() {
 			return errors.NewError(errors.ErrorTypeRateLimit, "send_connection_request", 
@@ -195,6 +276,7 @@ func (cm *ConnectManager) SendConnectionRequest(ctx context.Context, page *rod.P
 		retryConfig := errors.DefaultRetryConfig()
 		retryConfig.MaxAttempts = 2
 		retryConfig.InitialDelay = 3 * time.Second
+		retryConfig.Operation = "send_connection_request"
 		
 		return errors.RetryWithBackoff(ctx, retryConfig, func(ctx context.Context, attempt int) error {
 			// Navigate to the profile
@@ -211,14 +293,14 @@ func (cm *ConnectManager) SendConnectionRequest(ctx context.Context, page *rod.P
 
 			// Use stealth behavior to move to and click the button
 			if cm.stealth != nil {
-				err = cm.stealth.HumanMouseMove(ctx, page, connectButton)
+				err = cm.stealth.MoveMouse(ctx, page, connectButton)
 				if err != nil {
 					return errors.NewError(errors.ErrorTypeTransient, "send_connection_request", 
 						"failed to move mouse to Connect button", err)
 				}
 
 				// Add a small delay before clicking
-				err = cm.stealth.RandomDelay(500*time.Millisecond, 1500*time.Millisecond)
+				err = cm.stealth.RandomDelay(ctx, 500*time.Millisecond, 1500*time.Millisecond)
 				if err != nil {
 					return errors.NewError(errors.ErrorTypeTransient, "send_connection_request", 
 						"failed to add pre-click delay", err)
@@ -226,36 +308,60 @@ func (cm *ConnectManager) SendConnectionRequest(ctx context.Context, page *rod.P
 			}
 
 			// Click the Connect button
-			err = connectButton.Click("left", 1)
+			err = connectButton.Click()
 			if err != nil {
 				return cm.errorHandler.HandleRodError("click_connect_button", err)
 			}
 
 			// Wait for potential modal or note dialog
-			time.Sleep(2 * time.Second)
+			if err := cm.sleep(ctx, 2*time.Second); err != nil {
+				return err
+			}
 
-			// If a note is provided, try to find and fill the note field
-			if note != "" {
-				err = cm.handleConnectionNote(ctx, page, note)
-				if err != nil {
-					// Don't fail the entire operation if note handling fails
-					// Just continue without the note
+			// Some members only accept invites from people who know their email address
+			if emailField := visibleElement(page, cm.selectors.InviteEmail); emailField != nil {
+				if profile.Email == "" {
+					cm.dismissDialog(page)
+					if recorder, ok := cm.storage.(SkipRecorder); ok {
+						_ = recorder.RecordSkip(profile.URL, SkipReasonEmailRequired)
+					}
+					return errors.NewError(errors.ErrorTypePermanent, "send_connection_request",
+						"invite requires the member's email address", ErrEmailRequired)
+				}
+				if err := cm.fill(ctx, emailField, profile.Email); err != nil {
+					return errors.NewError(errors.ErrorTypeTransient, "send_connection_request",
+						"failed to enter the member's email address", err)
 				}
 			}
 
+			// If a note is provided, try to find and fill the note field; without one the invite goes blank
+			result.NoteSent = false
+			if note != "" {
+				result.NoteSent = cm.handleConnectionNote(ctx, page, note) == nil
+			}
+
 			// Look for and click the final Send button
-			err = cm.confirmConnectionRequest(ctx, page)
+			if note != "" && !result.NoteSent {
+				err = cm.sendWithoutNote(ctx, page)
+			} else {
+				err = cm.confirmConnectionRequest(ctx, page)
+			}
+			if limit := cm.detectInviteLimit(page); limit != nil {
+				return errors.NewError(errors.ErrorTypePermanent, "send_connection_request",
+					"invitation limit reached", limit)
+			}
 			if err != nil {
 				return err
 			}
 
 			// Record the connection request
 			request := ConnectionRequest{
-				ProfileURL:  profile.URL,
+				ProfileURL:  identity.NormalizeProfileURL(profile.URL),
 				ProfileName: profile.Name,
-				Note:        note,
+				Note:        sentNote(note, result.NoteSent),
 				SentAt:      time.Now(),
 				Status:      "pending",
+				Template:    sentNote(templateFrom(ctx), result.NoteSent),
 			}
 
 			err = cm.TrackSentRequest(request)
@@ -272,70 +378,162 @@ func (cm *ConnectManager) SendConnectionRequest(ctx context.Context, page *rod.P
 			return nil
 		})
 	})
+	return result, err
 }
 
-// handleConnectionNote handles adding a personalized note to the connection request
-func (cm *ConnectManager) handleConnectionNote(ctx context.Context, page *rod.Page, note string) error {
-	// Common selectors for note input fields
-	noteSelectors := []string{
-		`textarea[name="message"]`,
-		`textarea[aria-label*="message"]`,
-		`textarea[placeholder*="message"]`,
-		`.send-invite__custom-message textarea`,
-		`#custom-message`,
+// sentNote is the note recorded for a request: the note if it was sent, otherwise ""
+func sentNote(note string, sent bool) string {
+	if !sent {
+		return ""
 	}
+	return note
+}
 
-	var noteField *rod.Element
-	var err error
+// templateKey carries the name of the template an invite's note was rendered from
+type templateKey struct{}
 
-	// Try to find the note input field
-	for _, selector := range noteSelectors {
-		noteField, err = page.Element(selector)
-		if err == nil && noteField != nil {
-			visible, err := noteField.Visible()
-			if err == nil && visible {
-				break
-			}
+// WithTemplate returns ctx naming the template the note of an invite sent with it was rendered
+// from, which the request is tracked under so templates can be compared by how they do
+func WithTemplate(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, templateKey{}, name)
+}
+
+// templateFrom returns the template name WithTemplate set on ctx, or ""
+func templateFrom(ctx context.Context) string {
+	name, _ := ctx.Value(templateKey{}).(string)
+	return name
+}
+
+// InviteLimit returns the invitation limit LinkedIn reported during this run, or nil
+func (cm *ConnectManager) InviteLimit() *InviteLimitError {
+	return cm.limit
+}
+
+// detectInviteLimit checks the page for the weekly limit modal or the out-of-invitations toast.
+// Once seen, every later request fails fast until the limit's retry time.
+func (cm *ConnectManager) detectInviteLimit(page browser.PageDriver) *InviteLimitError {
+	warning, err := warnings.Detect(warningPage{page: page}, warnings.DefaultSurfaces())
+	if err != nil || warning == nil {
+		return nil
+	}
+	if warning.Kind != warnings.KindInviteLimit && warning.Kind != warnings.KindOutOfInvitations {
+		return nil
+	}
+	cm.limit = &InviteLimitError{Kind: warning.Kind, RetryAfter: warning.At.Add(InviteLimitBackoff)}
+	return cm.limit
+}
+
+// visibleElement returns the first visible element matching one of candidates without waiting for any to appear
+func visibleElement(page browser.PageDriver, candidates []string) browser.ElementDriver {
+	for _, selector := range candidates {
+		has, element, err := page.Has(selector)
+		if err != nil || !has {
+			continue
+		}
+		if visible, err := element.Visible(); err == nil && visible {
+			return element
 		}
-		noteField = nil
 	}
+	return nil
+}
 
-	if noteField == nil {
-		return fmt.Errorf("could not find note input field")
+// warningPage reads a page driver for warnings.Detect
+type warningPage struct {
+	page browser.PageDriver
+}
+
+func (p warningPage) URL() (string, error) {
+	return p.page.URL()
+}
+
+func (p warningPage) Texts(selector string) ([]string, error) {
+	elements, err := p.page.Elements(selector)
+	if err != nil {
+		return nil, err
+	}
+	texts := make([]string, 0, len(elements))
+	for _, element := range elements {
+		if text, err := element.Text(); err == nil {
+			texts = append(texts, text)
+		}
+	}
+	return texts, nil
+}
+
+// dismissDialog closes the invitation modal if one is open
+func (cm *ConnectManager) dismissDialog(page browser.PageDriver) {
+	if button := visibleElement(page, selectors.Localize([]string{
+		`button[aria-label*="Dismiss"]`,
+		`.artdeco-modal__dismiss`,
+		`button[aria-label*="Close"]`,
+	}, cm.selectors.Languages)); button != nil {
+		_ = button.Click()
 	}
+}
 
-	// Use stealth typing to fill the note
+// fill types text into a field, with human-like typing when stealth is configured
+func (cm *ConnectManager) fill(ctx context.Context, field browser.ElementDriver, text string) error {
 	if cm.stealth != nil {
-		err = cm.stealth.HumanType(ctx, noteField, note)
-		if err != nil {
-			return fmt.Errorf("failed to type note: %w", err)
+		return cm.stealth.TypeText(ctx, field, text)
+	}
+	return field.Input(text)
+}
+
+// handleConnectionNote opens the note field of the invitation modal and types the note into it
+func (cm *ConnectManager) handleConnectionNote(ctx context.Context, page browser.PageDriver, note string) error {
+	// The modal asks first whether to add a note; the field only appears after "Add a note"
+	if addNote := visibleElement(page, cm.selectors.AddNote); addNote != nil {
+		if err := addNote.Click(); err != nil {
+			return fmt.Errorf("failed to click Add a note: %w", err)
 		}
-	} else {
-		err = noteField.Input(note)
-		if err != nil {
-			return fmt.Errorf("failed to input note: %w", err)
+		if err := cm.sleep(ctx, time.Second); err != nil {
+			return err
 		}
 	}
 
+	noteField := visibleElement(page, cm.selectors.InviteNote)
+	if noteField == nil {
+		return fmt.Errorf("could not find note input field")
+	}
+
+	if err := cm.fill(ctx, noteField, note); err != nil {
+		// A half-typed note must not go out with the blank invite that follows
+		_ = noteField.SelectAllText()
+		_ = noteField.Input("")
+		return fmt.Errorf("failed to type note: %w", err)
+	}
+
 	return nil
 }
 
-// confirmConnectionRequest finds and clicks the final Send button
-func (cm *ConnectManager) confirmConnectionRequest(ctx context.Context, page *rod.Page) error {
-	// Common selectors for Send/Confirm buttons
-	sendSelectors := []string{
-		`button[aria-label*="Send"]`,
-		`button:has-text("Send invitation")`,
-		`button:has-text("Send")`,
-		`.send-invite__actions button[type="submit"]`,
-		`button[data-control-name="send_invite"]`,
+// sendWithoutNote sends the invitation blank after the note could not be added, with the modal's
+// "Send without a note" button if it shows one, otherwise with its Send button
+func (cm *ConnectManager) sendWithoutNote(ctx context.Context, page browser.PageDriver) error {
+	button := visibleElement(page, cm.selectors.SendWithoutNote)
+	if button == nil {
+		return cm.confirmConnectionRequest(ctx, page)
+	}
+	if cm.stealth != nil {
+		if err := cm.stealth.MoveMouse(ctx, page, button); err != nil {
+			return fmt.Errorf("failed to move mouse to Send without a note: %w", err)
+		}
+	}
+	if err := button.Click(); err != nil {
+		return fmt.Errorf("failed to click Send without a note: %w", err)
 	}
+	if err := cm.sleep(ctx, 2*time.Second); err != nil {
+		return err
+	}
+	return nil
+}
 
-	var sendButton *rod.Element
+// confirmConnectionRequest finds and clicks the final Send button
+func (cm *ConnectManager) confirmConnectionRequest(ctx context.Context, page browser.PageDriver) error {
+	var sendButton browser.ElementDriver
 	var err error
 
 	// Try to find the Send button
-	for _, selector := range sendSelectors {
+	for _, selector := range cm.selectors.SendInvite {
 		sendButton, err = page.Element(selector)
 		if err == nil && sendButton != nil {
 			visible, err := sendButton.Visible()
@@ -347,30 +545,33 @@ func (cm *ConnectManager) confirmConnectionRequest(ctx context.Context, page *ro
 	}
 
 	if sendButton == nil {
-		return fmt.Errorf("could not find Send button")
+		return errors.NewError(errors.ErrorTypeTransient, "confirm_connection_request", "could not find Send button", nil).
+			WithContext(errors.ContextSelector, strings.Join(cm.selectors.SendInvite, ", ")).WithCode(errors.CodeSelectorMiss)
 	}
 
 	// Use stealth behavior to click the Send button
 	if cm.stealth != nil {
-		err = cm.stealth.HumanMouseMove(ctx, page, sendButton)
+		err = cm.stealth.MoveMouse(ctx, page, sendButton)
 		if err != nil {
 			return fmt.Errorf("failed to move mouse to Send button: %w", err)
 		}
 
-		err = cm.stealth.RandomDelay(500*time.Millisecond, 1000*time.Millisecond)
+		err = cm.stealth.RandomDelay(ctx, 500*time.Millisecond, 1000*time.Millisecond)
 		if err != nil {
 			return fmt.Errorf("failed to add pre-send delay: %w", err)
 		}
 	}
 
 	// Click the Send button
-	err = sendButton.Click("left", 1)
+	err = sendButton.Click()
 	if err != nil {
 		return fmt.Errorf("failed to click Send button: %w", err)
 	}
 
 	// Wait for the request to be processed
-	time.Sleep(2 * time.Second)
+	if err := cm.sleep(ctx, 2*time.Second); err != nil {
+		return err
+	}
 
 	return nil
 }
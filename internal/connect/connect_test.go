@@ -2,12 +2,17 @@ package connect
 
 import (
 	"context"
+	stderrors "errors"
 	"strings"
 	"testing"
 	"time"
 
-	"github.com/go-rod/rod"
 	"pgregory.net/rapid"
+
+	"linkedin-automation-framework/internal/browser"
+	"linkedin-automation-framework/internal/browser/browsertest"
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/warnings"
 )
 
 // MockStorage implements StorageInterface for testing
@@ -27,15 +32,15 @@ func (ms *MockStorage) GetSentRequests() ([]ConnectionRequest, error) {
 // MockStealth implements StealthInterface for testing
 type MockStealth struct{}
 
-func (ms *MockStealth) HumanMouseMove(ctx context.Context, page *rod.Page, target *rod.Element) error {
+func (ms *MockStealth) MoveMouse(ctx context.Context, page browser.PageDriver, target browser.ElementDriver) error {
 	return nil
 }
 
-func (ms *MockStealth) HumanType(ctx context.Context, element *rod.Element, text string) error {
+func (ms *MockStealth) TypeText(ctx context.Context, element browser.ElementDriver, text string) error {
 	return element.Input(text)
 }
 
-func (ms *MockStealth) RandomDelay(min, max time.Duration) error {
+func (ms *MockStealth) RandomDelay(ctx context.Context, min, max time.Duration) error {
 	time.Sleep(min)
 	return nil
 }
@@ -149,6 +154,34 @@ func TestConnectButtonDetection(t *testing.T) {
 		
 		// The property holds: DetectConnectButton handles invalid inputs gracefully
 		// and provides meaningful error messages when Connect buttons cannot be found
+
+		// Property: the visible Connect button is found whichever selector matches it, past hidden
+		// decoys, and by its text when no selector does
+		index := rapid.IntRange(0, len(cm.selectors.ConnectButton)).Draw(t, "selector")
+		decoy := rapid.Bool().Draw(t, "hiddenDecoy")
+		page := browsertest.NewPage("https://www.linkedin.com/in/jane-doe/")
+		if decoy {
+			page.Append(browsertest.NewElement(cm.selectors.ConnectButton[0], "button").SetText("Connect").SetHidden(true))
+		}
+		want := browsertest.NewElement("button").SetText("Connect")
+		if index < len(cm.selectors.ConnectButton) {
+			want = browsertest.NewElement(cm.selectors.ConnectButton[index], "button").SetText("Connect")
+		}
+		page.Append(browsertest.NewElement("button").SetText("Message"), want)
+
+		got, err := cm.DetectConnectButton(ctx, page)
+		if err != nil {
+			t.Fatalf("expected the Connect button, got %v", err)
+		}
+		if got != browser.ElementDriver(want) {
+			t.Fatalf("expected the visible Connect button")
+		}
+
+		// Without a visible Connect button there is nothing to click
+		want.SetHidden(true)
+		if _, err := cm.DetectConnectButton(ctx, page); err == nil {
+			t.Fatalf("expected an error when the only Connect buttons are hidden")
+		}
 	})
 }
 
@@ -201,9 +234,100 @@ func TestConnectionRequestSending(t *testing.T) {
 		}
 		
 		// The property holds: SendConnectionRequest validates inputs and handles errors gracefully
+
+		// Property: on a profile page, the invite goes out once with the note typed into the note
+		// field, and exactly one request is recorded under the canonical URL
+		addNoteStep := rapid.Bool().Draw(t, "addNoteStep")
+		cm = newInstantConnectManager(storage, rateLimiter)
+		invite := newInvitePage(cm, profile.URL, addNoteStep)
+
+		result, err := cm.SendInvite(ctx, invite.page, profile, note)
+		if err != nil {
+			t.Fatalf("expected the invite to be sent, got %v", err)
+		}
+		canonical := identity.NormalizeProfileURL(profile.URL)
+		if navigations := invite.page.Navigations(); len(navigations) != 1 || navigations[0] != canonical {
+			t.Fatalf("expected one navigation to %s, got %v", canonical, navigations)
+		}
+		if invite.send.Clicks() != 1 {
+			t.Fatalf("expected Send to be clicked once, got %d", invite.send.Clicks())
+		}
+		if result.NoteSent != (note != "") || invite.note.Value() != note {
+			t.Fatalf("expected note %q to be sent, got %v with %q typed", note, result.NoteSent, invite.note.Value())
+		}
+		if len(storage.requests) != 1 || storage.requests[0].ProfileURL != canonical || storage.requests[0].Note != note {
+			t.Fatalf("expected one request for %s with the note, got %+v", canonical, storage.requests)
+		}
+		if x, y, moves := invite.page.Mouse(); moves == 0 || x != sendX || y != sendY {
+			t.Fatalf("expected the mouse to end on Send, got (%v, %v) after %d moves", x, y, moves)
+		}
 	})
 }
 
+// instantStealth moves the mouse straight to the target and types without delays
+type instantStealth struct{}
+
+func (instantStealth) MoveMouse(ctx context.Context, page browser.PageDriver, target browser.ElementDriver) error {
+	x, y, err := target.Center()
+	if err != nil {
+		return err
+	}
+	return page.MouseMoveTo(x, y)
+}
+
+func (instantStealth) TypeText(ctx context.Context, element browser.ElementDriver, text string) error {
+	if err := element.SelectAllText(); err != nil {
+		return err
+	}
+	return element.Input(text)
+}
+
+func (instantStealth) RandomDelay(ctx context.Context, min, max time.Duration) error {
+	return nil
+}
+
+// newInstantConnectManager creates a connection manager that never waits
+func newInstantConnectManager(storage StorageInterface, rateLimiter RateLimiterInterface) *ConnectManager {
+	cm := NewConnectManager(storage, rateLimiter, instantStealth{})
+	cm.sleep = func(context.Context, time.Duration) error { return nil }
+	return cm
+}
+
+// Where the fake Send button sits on the page
+const sendX, sendY = 640, 480
+
+// invitePage is a fake profile page whose Connect button opens the invitation modal
+type invitePage struct {
+	page  *browsertest.Page
+	modal *browsertest.Element
+	note  *browsertest.Element
+	send  *browsertest.Element
+}
+
+// newInvitePage builds the profile page. With addNoteStep the note field only appears after
+// "Add a note" is clicked, as in LinkedIn's current modal.
+func newInvitePage(cm *ConnectManager, profileURL string, addNoteStep bool) *invitePage {
+	invite := &invitePage{
+		page:  browsertest.NewPage(profileURL),
+		modal: browsertest.NewElement(".send-invite"),
+		note:  browsertest.NewElement(cm.selectors.InviteNote[0]),
+		send:  browsertest.NewElement(cm.selectors.SendInvite[0]).SetText("Send").SetPosition(sendX, sendY),
+	}
+	if addNoteStep {
+		addNote := browsertest.NewElement(cm.selectors.AddNote[0]).SetText("Add a note")
+		addNote.OnClick(func() { invite.modal.Append(invite.note) })
+		invite.modal.Append(addNote)
+	} else {
+		invite.modal.Append(invite.note)
+	}
+	invite.modal.Append(invite.send)
+
+	connectButton := browsertest.NewElement(cm.selectors.ConnectButton[0], "button").SetText("Connect").SetPosition(300, 200)
+	connectButton.OnClick(func() { invite.page.Append(invite.modal) })
+	invite.page.Append(browsertest.NewElement("main").Append(connectButton))
+	return invite
+}
+
 // TestRateLimitEnforcement tests rate limit enforcement functionality
 // **Feature: linkedin-automation-framework, Property 28: Rate limit enforcement**
 // **Validates: Requirements 5.4**
@@ -319,4 +443,139 @@ func TestNavigateToProfileWithNilPage(t *testing.T) {
 	if err == nil {
 		t.Fatal("Expected error when page is nil")
 	}
-}
\ No newline at end of file
+}
+
+// staticNetwork reports every profile with a known URL as connected
+type staticNetwork map[string]bool
+
+func (n staticNetwork) InNetwork(profileURL, name, company string) (bool, string) {
+	if n[profileURL] {
+		return true, "imported connection"
+	}
+	return false, ""
+}
+
+// TestAlreadyConnectedProfilesAreNotInvited tests that the network check runs before any invitation
+func TestAlreadyConnectedProfilesAreNotInvited(t *testing.T) {
+	storage := &MockStorage{}
+	rateLimiter := NewSimpleRateLimiter(10, time.Hour)
+	cm := NewConnectManager(storage, rateLimiter, &MockStealth{})
+	cm.SetNetwork(staticNetwork{"https://www.linkedin.com/in/jane-doe": true})
+
+	// The page is never touched, so a nil page must not be reached
+	err := cm.SendConnectionRequest(context.Background(), nil, ProfileResult{
+		URL:  "https://www.linkedin.com/in/jane-doe",
+		Name: "Jane Doe",
+	}, "")
+	if !stderrors.Is(err, ErrAlreadyConnected) {
+		t.Fatalf("expected ErrAlreadyConnected, got %v", err)
+	}
+	if len(storage.requests) != 0 {
+		t.Fatalf("no request should be tracked for an existing connection")
+	}
+	if !rateLimiter.CanSendConnection() {
+		t.Fatalf("skipped invitations should not use up the rate limit")
+	}
+}
+
+
+// skipStorage also records skipped leads
+type skipStorage struct {
+	MockStorage
+	skips map[string]string
+}
+
+func (s *skipStorage) RecordSkip(profileURL, reason string) error {
+	s.skips[profileURL] = reason
+	return nil
+}
+
+// TestEmailRequiredInvites tests that an invite asking for the member's email address is filled
+// in when the email is known, and otherwise dismissed and recorded as skipped
+func TestEmailRequiredInvites(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		username := rapid.StringMatching(`[a-z0-9\-]{3,30}`).Draw(t, "username")
+		email := rapid.SampledFrom([]string{"", username + "@example.com"}).Draw(t, "email")
+		profile := ProfileResult{URL: "https://www.linkedin.com/in/" + username + "/", Name: "Jane Doe", Email: email}
+
+		storage := &skipStorage{skips: make(map[string]string)}
+		cm := newInstantConnectManager(storage, NewSimpleRateLimiter(10, time.Hour))
+		invite := newInvitePage(cm, profile.URL, false)
+		emailField := browsertest.NewElement(cm.selectors.InviteEmail[0])
+		dismiss := browsertest.NewElement(`button[aria-label*="Dismiss"]`)
+		invite.modal.Append(emailField, dismiss)
+
+		err := cm.SendConnectionRequest(context.Background(), invite.page, profile, "")
+		if email == "" {
+			if !stderrors.Is(err, ErrEmailRequired) {
+				t.Fatalf("expected ErrEmailRequired, got %v", err)
+			}
+			if dismiss.Clicks() != 1 || invite.send.Clicks() != 0 || len(storage.requests) != 0 {
+				t.Fatalf("expected the modal dismissed and nothing sent")
+			}
+			if storage.skips[profile.URL] != SkipReasonEmailRequired {
+				t.Fatalf("expected the lead recorded as skipped, got %v", storage.skips)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("expected the invite to be sent with the email, got %v", err)
+		}
+		if emailField.Value() != email || invite.send.Clicks() != 1 || len(storage.requests) != 1 {
+			t.Fatalf("expected %q entered and one invite sent, got %q", email, emailField.Value())
+		}
+	})
+}
+
+// TestInviteLimitModalStopsRequests tests that the weekly limit modal shown after Send fails the
+// request without recording it, and fails later requests before they touch the page
+func TestInviteLimitModalStopsRequests(t *testing.T) {
+	storage := &MockStorage{}
+	cm := newInstantConnectManager(storage, NewSimpleRateLimiter(10, time.Hour))
+	invite := newInvitePage(cm, "https://www.linkedin.com/in/jane-doe/", false)
+	invite.send.OnClick(func() {
+		invite.page.Append(browsertest.NewElement(".artdeco-modal").SetText("You've reached the weekly invitation limit"))
+	})
+
+	err := cm.SendConnectionRequest(context.Background(), invite.page, ProfileResult{URL: "https://www.linkedin.com/in/jane-doe/"}, "")
+	if !stderrors.Is(err, ErrInviteLimit) {
+		t.Fatalf("expected ErrInviteLimit, got %v", err)
+	}
+	if len(storage.requests) != 0 || cm.InviteLimit() == nil {
+		t.Fatalf("expected no request recorded and the limit kept")
+	}
+
+	next := browsertest.NewPage("https://www.linkedin.com/in/john-roe/")
+	err = cm.SendConnectionRequest(context.Background(), next, ProfileResult{URL: "https://www.linkedin.com/in/john-roe/"}, "")
+	if !stderrors.Is(err, ErrInviteLimit) || len(next.Navigations()) != 0 {
+		t.Fatalf("expected the next request to fail before navigating, got %v", err)
+	}
+}
+
+// TestInviteLimitStopsLaterRequests tests that requests fail fast once the invitation limit is reached
+func TestInviteLimitStopsLaterRequests(t *testing.T) {
+	storage := &MockStorage{}
+	rateLimiter := NewSimpleRateLimiter(10, time.Hour)
+	cm := NewConnectManager(storage, rateLimiter, &MockStealth{})
+	cm.limit = &InviteLimitError{Kind: warnings.KindInviteLimit, RetryAfter: time.Now().Add(InviteLimitBackoff)}
+
+	// The page is never touched, so a nil page must not be reached
+	err := cm.SendConnectionRequest(context.Background(), nil, ProfileResult{URL: "https://www.linkedin.com/in/jane-doe"}, "")
+	if !stderrors.Is(err, ErrInviteLimit) {
+		t.Fatalf("expected ErrInviteLimit, got %v", err)
+	}
+	var limit *InviteLimitError
+	if !stderrors.As(err, &limit) || limit.Kind != warnings.KindInviteLimit {
+		t.Errorf("expected the limit details in the error, got %v", err)
+	}
+	if !rateLimiter.CanSendConnection() || len(storage.requests) != 0 {
+		t.Errorf("expected nothing sent or counted after the limit")
+	}
+
+	// Once the retry time has passed, requests go ahead again
+	cm.limit.RetryAfter = time.Now().Add(-time.Minute)
+	err = cm.SendConnectionRequest(context.Background(), nil, ProfileResult{URL: "https://www.linkedin.com/in/jane-doe"}, "")
+	if stderrors.Is(err, ErrInviteLimit) {
+		t.Errorf("expected the limit to lapse, got %v", err)
+	}
+}
@@ -0,0 +1,324 @@
+package connections
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// Contact formats
+const (
+	FormatCSV   = "csv"
+	FormatVCard = "vcard"
+)
+
+// Contact is an accepted connection as exported for a CRM: the imported connection or accepted
+// request, filled in from the stored search result for the same profile
+type Contact struct {
+	ProfileURL  string
+	FirstName   string
+	LastName    string
+	Email       string
+	Title       string
+	Company     string
+	Location    string
+	ConnectedOn time.Time
+	Tags        []string
+	Followers   int
+	Mutual      int
+	Photo       string            // Path of the downloaded profile photo, when photos are enabled
+	RunID       string            // Run that sent the accepted request, "" for connections made outside the tool
+	Notes       []string          // Notes users attached to the lead, oldest first
+	Attributes  map[string]string // Custom attributes users set on the lead
+
+	// Firmographics of Company, once looked up with "companies enrich"
+	CompanySize      string
+	Industry         string
+	Headquarters     string
+	CompanyWebsite   string
+	CompanyFollowers int
+}
+
+// Name returns the contact's full name
+func (c Contact) Name() string {
+	return strings.TrimSpace(c.FirstName + " " + c.LastName)
+}
+
+// Column is one CSV column: its header and the contact field it holds
+type Column struct {
+	Header string
+	Field  string
+}
+
+// contactFields reads each field a CSV column can hold
+var contactFields = map[string]func(Contact) string{
+	"profile_url": func(c Contact) string { return c.ProfileURL },
+	"name":        Contact.Name,
+	"first_name":  func(c Contact) string { return c.FirstName },
+	"last_name":   func(c Contact) string { return c.LastName },
+	"email":       func(c Contact) string { return c.Email },
+	"title":       func(c Contact) string { return c.Title },
+	"company":     func(c Contact) string { return c.Company },
+	"location":    func(c Contact) string { return c.Location },
+	"connected_on": func(c Contact) string {
+		if c.ConnectedOn.IsZero() {
+			return ""
+		}
+		return c.ConnectedOn.Format("2006-01-02")
+	},
+	"tags":      func(c Contact) string { return strings.Join(c.Tags, ";") },
+	"followers": func(c Contact) string { return countField(c.Followers) },
+	"mutual":    func(c Contact) string { return countField(c.Mutual) },
+	"photo":     func(c Contact) string { return c.Photo },
+
+	"company_size":      func(c Contact) string { return c.CompanySize },
+	"industry":          func(c Contact) string { return c.Industry },
+	"headquarters":      func(c Contact) string { return c.Headquarters },
+	"company_website":   func(c Contact) string { return c.CompanyWebsite },
+	"company_followers": func(c Contact) string { return countField(c.CompanyFollowers) },
+
+	"notes": func(c Contact) string { return strings.Join(c.Notes, "\n") },
+}
+
+// AttributeFieldPrefix starts the field of a column holding a custom lead attribute, e.g.
+// "attribute.deal_stage"
+const AttributeFieldPrefix = "attribute."
+
+// DefaultColumns are the CSV columns written without a custom mapping
+var DefaultColumns = []Column{
+	{"First Name", "first_name"},
+	{"Last Name", "last_name"},
+	{"Email", "email"},
+	{"Title", "title"},
+	{"Company", "company"},
+	{"Location", "location"},
+	{"LinkedIn URL", "profile_url"},
+	{"Connected On", "connected_on"},
+	{"Tags", "tags"},
+	{"Notes", "notes"},
+}
+
+// ParseColumns parses a column mapping given as "Header=field" entries, in column order
+func ParseColumns(specs []string) ([]Column, error) {
+	columns := make([]Column, 0, len(specs))
+	for _, spec := range specs {
+		header, field, ok := strings.Cut(spec, "=")
+		header, field = strings.TrimSpace(header), strings.ToLower(strings.TrimSpace(field))
+		if !ok || header == "" {
+			return nil, fmt.Errorf("column %q must be written as Header=field", spec)
+		}
+		_, known := contactFields[field]
+		if key, custom := strings.CutPrefix(field, AttributeFieldPrefix); custom && key != "" {
+			known = true
+		}
+		if !known {
+			return nil, fmt.Errorf("column %q: unknown field %q (known: %s)", header, field, strings.Join(ContactFields(), ", "))
+		}
+		columns = append(columns, Column{Header: header, Field: field})
+	}
+	return columns, nil
+}
+
+// ContactFields lists the fields a CSV column can hold, besides custom attributes
+func ContactFields() []string {
+	fields := make([]string, 0, len(contactFields)+1)
+	for field := range contactFields {
+		fields = append(fields, field)
+	}
+	fields = append(fields, AttributeFieldPrefix+"<key>")
+	slices.Sort(fields)
+	return fields
+}
+
+// contactField reads a field of the contact: a known field or a custom attribute
+func contactField(c Contact, field string) string {
+	if key, custom := strings.CutPrefix(field, AttributeFieldPrefix); custom {
+		return c.Attributes[key]
+	}
+	return contactFields[field](c)
+}
+
+// Contacts lists the accepted connections: every imported connection, then accepted requests
+// to profiles not yet in an import. Fields missing from either are filled in from the search
+// result for the same profile among profiles.
+func Contacts(store Store, profiles []storage.ProfileResult) ([]Contact, error) {
+	connections, err := store.GetConnections()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load imported connections: %w", err)
+	}
+	requests, err := store.GetSentRequests()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load connection requests: %w", err)
+	}
+
+	// The latest accepted request to a profile is the one the connection came from
+	accepted := make(map[string]storage.ConnectionRequest)
+	for _, request := range requests {
+		key := identity.ProfileKey(request.ProfileURL)
+		if request.Status == StatusAccepted && request.SentAt.After(accepted[key].SentAt) {
+			accepted[key] = request
+		}
+	}
+
+	found := make(map[string]storage.ProfileResult, len(profiles))
+	for _, profile := range profiles {
+		found[identity.ProfileKey(profile.URL)] = profile
+	}
+
+	var contacts []Contact
+	seen := make(map[string]bool)
+	for _, connection := range connections {
+		seen[identity.ProfileKey(connection.ProfileURL)] = true
+		contacts = append(contacts, Contact{
+			ProfileURL:  connection.ProfileURL,
+			FirstName:   connection.FirstName,
+			LastName:    connection.LastName,
+			Email:       connection.Email,
+			Title:       connection.Position,
+			Company:     connection.Company,
+			ConnectedOn: connection.ConnectedOn,
+			Tags:        connection.Tags,
+			RunID:       accepted[identity.ProfileKey(connection.ProfileURL)].RunID,
+		})
+	}
+	for _, request := range requests {
+		key := identity.ProfileKey(request.ProfileURL)
+		if request.Status != StatusAccepted || seen[key] {
+			continue
+		}
+		seen[key] = true
+		name := identity.ParseName(request.ProfileName)
+		contacts = append(contacts, Contact{ProfileURL: request.ProfileURL, FirstName: name.First, LastName: name.Last, RunID: accepted[key].RunID})
+	}
+
+	for i := range contacts {
+		profile, ok := found[identity.ProfileKey(contacts[i].ProfileURL)]
+		if !ok {
+			continue
+		}
+		if contacts[i].FirstName == "" && contacts[i].LastName == "" {
+			name := identity.ParseName(profile.Name)
+			contacts[i].FirstName, contacts[i].LastName = name.First, name.Last
+		}
+		if contacts[i].Title == "" {
+			contacts[i].Title = profile.Title
+		}
+		if contacts[i].Company == "" {
+			contacts[i].Company = profile.Company
+		}
+		contacts[i].Location = profile.Location
+		contacts[i].Followers = profile.Followers
+		contacts[i].Mutual = profile.Mutual
+	}
+	return contacts, nil
+}
+
+// WriteCSV writes the contacts as CSV with a header row, one column per mapping entry
+func WriteCSV(w io.Writer, contacts []Contact, columns []Column) error {
+	writer := csv.NewWriter(w)
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Header
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, contact := range contacts {
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = contactField(contact, column.Field)
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write contact %s: %w", contact.ProfileURL, err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteVCards writes the contacts as vCard 4.0 (RFC 6350), one card each
+func WriteVCards(w io.Writer, contacts []Contact) error {
+	for _, contact := range contacts {
+		lines := []string{
+			"BEGIN:VCARD",
+			"VERSION:4.0",
+			"FN:" + vcardText(contact.Name()),
+			"N:" + vcardText(contact.LastName) + ";" + vcardText(contact.FirstName) + ";;;",
+		}
+		if contact.Email != "" {
+			lines = append(lines, "EMAIL;TYPE=work:"+vcardText(contact.Email))
+		}
+		if contact.Title != "" {
+			lines = append(lines, "TITLE:"+vcardText(contact.Title))
+		}
+		if contact.Company != "" {
+			lines = append(lines, "ORG:"+vcardText(contact.Company))
+		}
+		if contact.Location != "" {
+			lines = append(lines, "ADR;TYPE=work;LABEL=\""+strings.ReplaceAll(contact.Location, "\"", "'")+"\":;;;;;;")
+		}
+		if contact.ProfileURL != "" {
+			lines = append(lines, "URL;TYPE=linkedin:"+contact.ProfileURL)
+		}
+		if len(contact.Tags) > 0 {
+			tags := make([]string, len(contact.Tags))
+			for i, tag := range contact.Tags {
+				tags[i] = vcardText(tag)
+			}
+			lines = append(lines, "CATEGORIES:"+strings.Join(tags, ","))
+		}
+		notes := slices.Clone(contact.Notes)
+		if !contact.ConnectedOn.IsZero() {
+			notes = append([]string{"LinkedIn connection since " + contact.ConnectedOn.Format("2006-01-02")}, notes...)
+		}
+		if len(notes) > 0 {
+			lines = append(lines, "NOTE:"+vcardText(strings.Join(notes, "\n")))
+		}
+		lines = append(lines, "END:VCARD")
+
+		for _, line := range lines {
+			if _, err := io.WriteString(w, foldLine(line)); err != nil {
+				return fmt.Errorf("failed to write vCard for %s: %w", contact.ProfileURL, err)
+			}
+		}
+	}
+	return nil
+}
+
+// vcardText escapes a text value: backslashes, commas, semicolons and newlines
+func vcardText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
+
+// foldLine ends a content line with CRLF, folding it so no line exceeds 75 octets without
+// splitting a UTF-8 character
+func foldLine(line string) string {
+	var folded strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > 75 {
+			folded.WriteString("\r\n ")
+			width = 1
+		}
+		folded.WriteRune(r)
+		width += size
+	}
+	folded.WriteString("\r\n")
+	return folded.String()
+}
+
+// countField formats a count, leaving it empty when unknown
+func countField(count int) string {
+	if count <= 0 {
+		return ""
+	}
+	return strconv.Itoa(count)
+}
@@ -0,0 +1,107 @@
+package connections
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/storage"
+)
+
+// TestContactsExport tests merging accepted connections with search results and writing them
+// as mapped CSV and as vCards
+func TestContactsExport(t *testing.T) {
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "json", Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.SaveConnections([]storage.Connection{{
+		ProfileURL:  "https://www.linkedin.com/in/jane-doe",
+		FirstName:   "Jane",
+		LastName:    "Doe",
+		Email:       "jane@example.com",
+		Company:     "Acme, Inc.",
+		ConnectedOn: time.Date(2021, 4, 2, 0, 0, 0, 0, time.UTC),
+		Tags:        []string{"alumni"},
+	}}); err != nil {
+		t.Fatalf("failed to save connections: %v", err)
+	}
+	for _, request := range []storage.ConnectionRequest{
+		{ProfileURL: "https://www.linkedin.com/in/john-roe", ProfileName: "John Roe", Status: StatusAccepted, SentAt: time.Now()},
+		{ProfileURL: "https://www.linkedin.com/in/pending", ProfileName: "Pat Pending", Status: StatusPending, SentAt: time.Now()},
+		{ProfileURL: "https://www.linkedin.com/in/jane-doe", ProfileName: "Jane Doe", Status: StatusAccepted, SentAt: time.Now(), RunID: "run-1"},
+	} {
+		if err := store.SaveConnectionRequest(request); err != nil {
+			t.Fatalf("failed to save request: %v", err)
+		}
+	}
+	profiles := []storage.ProfileResult{
+		{URL: "https://www.linkedin.com/in/Jane-Doe/", Name: "Jane Doe", Title: "Head of Platform", Company: "Other", Location: "Berlin", Followers: 1200},
+		{URL: "https://www.linkedin.com/in/john-roe", Name: "John Roe", Title: "CTO", Company: "Globex"},
+	}
+
+	contacts, err := Contacts(store, profiles)
+	if err != nil {
+		t.Fatalf("Contacts failed: %v", err)
+	}
+	if len(contacts) != 2 {
+		t.Fatalf("expected Jane and John, got %+v", contacts)
+	}
+	if jane := contacts[0]; jane.Title != "Head of Platform" || jane.Company != "Acme, Inc." || jane.Location != "Berlin" || jane.RunID != "run-1" {
+		t.Errorf("expected Jane's export fields kept and gaps filled from search and the accepted run, got %+v", jane)
+	}
+
+	contacts[0].Notes = []string{"Met at GopherCon", "Prefers email"}
+	contacts[0].Attributes = map[string]string{"deal_stage": "demo"}
+
+	columns, err := ParseColumns([]string{"Full Name=name", "Account=company", "Followers=followers", "Stage=attribute.deal_stage"})
+	if err != nil {
+		t.Fatalf("ParseColumns failed: %v", err)
+	}
+	var csvOut bytes.Buffer
+	if err := WriteCSV(&csvOut, contacts, columns); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	if want := "Full Name,Account,Followers,Stage\nJane Doe,\"Acme, Inc.\",1200,demo\nJohn Roe,Globex,,\n"; csvOut.String() != want {
+		t.Errorf("expected CSV %q, got %q", want, csvOut.String())
+	}
+	csvOut.Reset()
+	if err := WriteCSV(&csvOut, contacts[:1], DefaultColumns); err != nil || !strings.HasSuffix(csvOut.String(), ",alumni,\"Met at GopherCon\nPrefers email\"\n") {
+		t.Errorf("expected the notes in the default columns, got %q (%v)", csvOut.String(), err)
+	}
+	for _, spec := range []string{"name", "=name", "Name=shoe_size", "Stage=attribute."} {
+		if _, err := ParseColumns([]string{spec}); err == nil {
+			t.Errorf("expected column %q to be rejected", spec)
+		}
+	}
+
+	contacts[0].Title = strings.Repeat("Platform; ", 10)
+	var vcards bytes.Buffer
+	if err := WriteVCards(&vcards, contacts); err != nil {
+		t.Fatalf("WriteVCards failed: %v", err)
+	}
+	out := vcards.String()
+	for _, want := range []string{
+		"BEGIN:VCARD\r\nVERSION:4.0\r\nFN:Jane Doe\r\nN:Doe;Jane;;;\r\n",
+		"ORG:Acme\\, Inc.\r\n",
+		"CATEGORIES:alumni\r\n",
+		"NOTE:LinkedIn connection since 2021-04-02\\nMet at GopherCon\\nPrefers email\r\n",
+		"URL;TYPE=linkedin:https://www.linkedin.com/in/jane-doe/\r\n",
+		"FN:John Roe\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in vCards:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "BEGIN:VCARD") != 2 {
+		t.Errorf("expected two cards, got:\n%s", out)
+	}
+	for _, line := range strings.Split(out, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 octets: %q", line)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package connections
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+
+	"linkedin-automation-framework/internal/errors"
+)
+
+// DownloadMyDataURL is LinkedIn's "Get a copy of your data" settings page
+const DownloadMyDataURL = "https://www.linkedin.com/mypreferences/d/download-my-data"
+
+// ErrPasswordRequired is returned when LinkedIn asks for the account password before preparing an archive
+var ErrPasswordRequired = stderrors.New("LinkedIn requires the account password to request a data archive; request it once manually and run the export again")
+
+// Downloader saves the file a trigger starts downloading; browser.Manager implements it
+type Downloader interface {
+	Download(ctx context.Context, trigger func() error) (string, error)
+}
+
+// Exporter requests LinkedIn's connections export and downloads it once it is ready
+type Exporter struct {
+	page         *rod.Page
+	downloader   Downloader
+	pollInterval time.Duration
+	timeout      time.Duration
+	timeouts     errors.Timeouts // Bound the waits for the settings page's options and prompts
+}
+
+// NewExporter creates an exporter; connections-only archives are usually ready within ten minutes
+func NewExporter(page *rod.Page, downloader Downloader) *Exporter {
+	return &Exporter{
+		page:         page,
+		downloader:   downloader,
+		pollInterval: time.Minute,
+		timeout:      30 * time.Minute,
+		timeouts:     errors.DefaultTimeouts(),
+	}
+}
+
+// SetTimeout changes how long Export waits for a requested archive to become available
+func (e *Exporter) SetTimeout(timeout time.Duration) {
+	e.timeout = timeout
+}
+
+// SetPageTimeouts changes how long the exporter waits for elements and the password prompt
+func (e *Exporter) SetPageTimeouts(timeouts errors.Timeouts) {
+	e.timeouts = timeouts
+}
+
+// Export downloads the connections archive, requesting a new one first if none is available,
+// and returns the path of the downloaded file
+func (e *Exporter) Export(ctx context.Context) (string, error) {
+	if e.page == nil {
+		return "", fmt.Errorf("page cannot be nil")
+	}
+	if e.downloader == nil {
+		return "", fmt.Errorf("downloader cannot be nil")
+	}
+
+	page := e.page.Context(ctx)
+	if err := page.Navigate(DownloadMyDataURL); err != nil {
+		return "", fmt.Errorf("failed to open data export page: %w", err)
+	}
+	if err := page.WaitLoad(); err != nil {
+		return "", fmt.Errorf("failed to wait for data export page to load: %w", err)
+	}
+
+	// An archive requested earlier may already be waiting
+	if button := e.findButton(page, "Download archive"); button != nil {
+		return e.download(ctx, button)
+	}
+
+	if err := e.requestArchive(page); err != nil {
+		return "", err
+	}
+
+	deadline := time.Now().Add(e.timeout)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(e.pollInterval):
+		}
+
+		if err := page.Reload(); err != nil {
+			return "", fmt.Errorf("failed to reload data export page: %w", err)
+		}
+		if err := page.WaitLoad(); err != nil {
+			return "", fmt.Errorf("failed to wait for data export page to load: %w", err)
+		}
+		if button := e.findButton(page, "Download archive"); button != nil {
+			return e.download(ctx, button)
+		}
+	}
+
+	return "", fmt.Errorf("connections archive was not ready within %s", e.timeout)
+}
+
+// requestArchive selects the connections-only export and submits the request
+func (e *Exporter) requestArchive(page *rod.Page) error {
+	if option := e.findElement(page, "label", "Want something in particular"); option != nil {
+		if err := option.Click("left", 1); err != nil {
+			return fmt.Errorf("failed to choose a partial export: %w", err)
+		}
+	}
+
+	option := e.findElement(page, "label", `^\s*Connections\s*$`)
+	if option == nil {
+		return fmt.Errorf("could not find the Connections export option")
+	}
+	if err := option.Click("left", 1); err != nil {
+		return fmt.Errorf("failed to select the Connections export: %w", err)
+	}
+
+	button := e.findButton(page, "Request archive")
+	if button == nil {
+		return fmt.Errorf("could not find the Request archive button")
+	}
+	if err := button.Click("left", 1); err != nil {
+		return fmt.Errorf("failed to request archive: %w", err)
+	}
+
+	// LinkedIn may re-prompt for the password before accepting the request
+	if password, err := page.Timeout(e.timeouts.Dialog).Element(`input[type="password"]`); err == nil && password != nil {
+		return ErrPasswordRequired
+	}
+
+	return nil
+}
+
+// download clicks the download button and waits for the archive to be saved
+func (e *Exporter) download(ctx context.Context, button *rod.Element) (string, error) {
+	path, err := e.downloader.Download(ctx, func() error {
+		return button.Click("left", 1)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to download connections archive: %w", err)
+	}
+	return path, nil
+}
+
+// findButton returns the button whose text matches, or nil if the page has none
+func (e *Exporter) findButton(page *rod.Page, text string) *rod.Element {
+	return e.findElement(page, "button", text)
+}
+
+// findElement returns the first element matching selector whose text matches the regular expression
+func (e *Exporter) findElement(page *rod.Page, selector, text string) *rod.Element {
+	element, err := page.Timeout(e.timeouts.Element).ElementR(selector, text)
+	if err != nil {
+		return nil
+	}
+	return element
+}
@@ -0,0 +1,135 @@
+package connections
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// Filter selects imported connections. Each list matches when any of its entries does, and a
+// connection must match every list that is set; an empty filter selects the whole network.
+type Filter struct {
+	Titles    []string // Words or phrases found in the connection's position, ignoring case
+	Companies []string // Words or phrases found in the connection's company, ignoring case
+	Tags      []string // Tags the connection was given, ignoring case
+
+	// DormantSince keeps only connections last contacted before it, as LastContacts tells;
+	// the zero time keeps every connection
+	DormantSince time.Time
+}
+
+// Matches reports whether the connection passes the filter
+func (f Filter) Matches(connection storage.Connection) bool {
+	if len(f.Titles) > 0 && !containsAny(connection.Position, f.Titles) {
+		return false
+	}
+	if len(f.Companies) > 0 && !containsAny(connection.Company, f.Companies) {
+		return false
+	}
+	if len(f.Tags) > 0 && !slices.ContainsFunc(connection.Tags, func(tag string) bool {
+		return slices.ContainsFunc(f.Tags, func(want string) bool { return strings.EqualFold(tag, want) })
+	}) {
+		return false
+	}
+	return true
+}
+
+// Select returns the imported connections that pass the filter, most recently connected first
+func Select(store Store, filter Filter) ([]storage.Connection, error) {
+	connections, err := store.GetConnections()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load imported connections: %w", err)
+	}
+
+	var contacts map[string]time.Time
+	if !filter.DormantSince.IsZero() {
+		if contacts, err = LastContacts(store); err != nil {
+			return nil, err
+		}
+	}
+
+	var selected []storage.Connection
+	for _, connection := range connections {
+		if !filter.Matches(connection) {
+			continue
+		}
+		if contacts != nil && !contacts[identity.ProfileKey(connection.ProfileURL)].Before(filter.DormantSince) {
+			continue
+		}
+		selected = append(selected, connection)
+	}
+	return selected, nil
+}
+
+// LastContacts returns when each imported connection was last contacted, by profile key: the
+// latest message sent to them or the date the connection was made. A connection with neither
+// has the zero time.
+func LastContacts(store Store) (map[string]time.Time, error) {
+	connections, err := store.GetConnections()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load imported connections: %w", err)
+	}
+	messages, err := store.GetMessageHistory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load message history: %w", err)
+	}
+
+	contacts := make(map[string]time.Time, len(connections))
+	for _, connection := range connections {
+		contacts[identity.ProfileKey(connection.ProfileURL)] = connection.ConnectedOn
+	}
+	for _, message := range messages {
+		key := identity.ProfileKey(message.RecipientURL)
+		if last, ok := contacts[key]; ok && message.SentAt.After(last) {
+			contacts[key] = message.SentAt
+		}
+	}
+	return contacts, nil
+}
+
+// Tag adds and removes tags on an imported connection, returning its tags afterwards
+func Tag(store Store, profileURL string, add, remove []string) ([]string, error) {
+	connections, err := store.GetConnections()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load imported connections: %w", err)
+	}
+
+	key := identity.ProfileKey(profileURL)
+	for _, connection := range connections {
+		if identity.ProfileKey(connection.ProfileURL) != key {
+			continue
+		}
+		var tags []string
+		for _, tag := range append(connection.Tags, add...) {
+			tag = strings.TrimSpace(tag)
+			if tag == "" || slices.ContainsFunc(tags, func(kept string) bool { return strings.EqualFold(kept, tag) }) {
+				continue
+			}
+			if slices.ContainsFunc(remove, func(removed string) bool { return strings.EqualFold(removed, tag) }) {
+				continue
+			}
+			tags = append(tags, tag)
+		}
+		connection.Tags = tags
+		if err := store.SaveConnections([]storage.Connection{connection}); err != nil {
+			return nil, fmt.Errorf("failed to save tags: %w", err)
+		}
+		return tags, nil
+	}
+	return nil, fmt.Errorf("%s is not an imported connection", profileURL)
+}
+
+// containsAny reports whether text contains any of the phrases, ignoring case
+func containsAny(text string, phrases []string) bool {
+	text = strings.ToLower(text)
+	for _, phrase := range phrases {
+		if phrase = strings.ToLower(strings.TrimSpace(phrase)); phrase != "" && strings.Contains(text, phrase) {
+			return true
+		}
+	}
+	return false
+}
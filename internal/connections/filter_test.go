@@ -0,0 +1,118 @@
+package connections
+
+import (
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// TestFilterAndTags tests selecting connections by title, company and tags, and that tags
+// survive a fresh import
+func TestFilterAndTags(t *testing.T) {
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "sqlite", Path: t.TempDir(), Database: "test.db"})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	exported := []storage.Connection{
+		{ProfileURL: "https://www.linkedin.com/in/ann", FirstName: "Ann", Position: "VP Engineering", Company: "Acme", ImportedAt: time.Now()},
+		{ProfileURL: "https://www.linkedin.com/in/bob", FirstName: "Bob", Position: "Engineering Manager", Company: "Globex", ImportedAt: time.Now()},
+		{ProfileURL: "https://www.linkedin.com/in/cara", FirstName: "Cara", Position: "Recruiter", Company: "Acme", ImportedAt: time.Now()},
+	}
+	if _, err := Import(store, exported); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+
+	if _, err := Tag(store, "https://www.linkedin.com/in/bob/", []string{"alumni", "Alumni", "warm"}, nil); err != nil {
+		t.Fatalf("tag failed: %v", err)
+	}
+	tags, err := Tag(store, "https://www.linkedin.com/in/bob", nil, []string{"WARM"})
+	if err != nil || len(tags) != 1 || tags[0] != "alumni" {
+		t.Fatalf("expected bob tagged alumni only, got %v, %v", tags, err)
+	}
+	if _, err := Tag(store, "https://www.linkedin.com/in/nobody", []string{"x"}, nil); err == nil {
+		t.Errorf("expected tagging an unknown connection to fail")
+	}
+
+	// Re-importing the export must not drop the tags given here
+	if _, err := Import(store, exported); err != nil {
+		t.Fatalf("re-import failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		filter Filter
+		want   string
+	}{
+		{Filter{}, "Ann,Bob,Cara"},
+		{Filter{Titles: []string{"engineering"}}, "Ann,Bob"},
+		{Filter{Titles: []string{"engineering"}, Companies: []string{"acme"}}, "Ann"},
+		{Filter{Tags: []string{"ALUMNI"}}, "Bob"},
+		{Filter{Companies: []string{"initech"}}, ""},
+	} {
+		selected, err := Select(store, tc.filter)
+		if err != nil {
+			t.Fatalf("select failed: %v", err)
+		}
+		got := ""
+		for _, connection := range selected {
+			if got != "" {
+				got += ","
+			}
+			got += connection.FirstName
+		}
+		if got != tc.want {
+			t.Errorf("%+v: expected %q, got %q", tc.filter, tc.want, got)
+		}
+	}
+}
+
+// TestDormantConnections tests that only connections without a message or a new connection
+// since the cutoff are selected
+func TestDormantConnections(t *testing.T) {
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "json", Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Now()
+	if err := store.SaveConnections([]storage.Connection{
+		{ProfileURL: "https://www.linkedin.com/in/old-quiet", FirstName: "Quiet", ConnectedOn: now.AddDate(-3, 0, 0)},
+		{ProfileURL: "https://www.linkedin.com/in/old-talked", FirstName: "Talked", ConnectedOn: now.AddDate(-3, 0, 0)},
+		{ProfileURL: "https://www.linkedin.com/in/recent", FirstName: "Recent", ConnectedOn: now.AddDate(0, -1, 0)},
+		{ProfileURL: "https://www.linkedin.com/in/undated", FirstName: "Undated"},
+	}); err != nil {
+		t.Fatalf("failed to save connections: %v", err)
+	}
+	for _, message := range []storage.SentMessage{
+		{RecipientURL: "https://www.linkedin.com/in/Old-Talked/", SentAt: now.AddDate(0, -2, 0)},
+		{RecipientURL: "https://www.linkedin.com/in/old-quiet", SentAt: now.AddDate(-1, 0, 0)},
+	} {
+		if err := store.SaveMessage(message); err != nil {
+			t.Fatalf("failed to save message: %v", err)
+		}
+	}
+
+	selected, err := Select(store, Filter{DormantSince: now.AddDate(0, -6, 0)})
+	if err != nil {
+		t.Fatalf("select failed: %v", err)
+	}
+	dormant := make(map[string]bool)
+	for _, connection := range selected {
+		dormant[connection.FirstName] = true
+	}
+	if len(dormant) != 2 || !dormant["Quiet"] || !dormant["Undated"] {
+		t.Errorf("expected Quiet and Undated to be dormant, got %v", dormant)
+	}
+
+	contacts, err := LastContacts(store)
+	if err != nil {
+		t.Fatalf("LastContacts failed: %v", err)
+	}
+	if last := contacts[identity.ProfileKey("https://www.linkedin.com/in/old-quiet")]; !last.Equal(now.AddDate(-1, 0, 0)) {
+		t.Errorf("expected the message a year ago as Quiet's last contact, got %v", last)
+	}
+}
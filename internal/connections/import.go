@@ -0,0 +1,189 @@
+package connections
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// ExportFileName is the name of the connections file inside LinkedIn's data archive
+const ExportFileName = "Connections.csv"
+
+// connectedOnLayouts are the date formats LinkedIn has used for the "Connected On" column
+var connectedOnLayouts = []string{"02 Jan 2006", "2 Jan 2006", "2006-01-02"}
+
+// Store is the storage needed to import connections, compare them with tracked requests and
+// find when they were last contacted
+type Store interface {
+	SaveConnections(connections []storage.Connection) error
+	GetConnections() ([]storage.Connection, error)
+	GetSentRequests() ([]storage.ConnectionRequest, error)
+	GetMessageHistory() ([]storage.SentMessage, error)
+}
+
+// ImportSummary reports what an import changed
+type ImportSummary struct {
+	Total   int // Connections in the export
+	New     int // Connections not seen in an earlier import
+	Updated int // Connections already imported, refreshed from the export
+	Skipped int // Rows without a profile URL
+	Tracked int // Connections that came from requests sent by the tool
+}
+
+// ReadExport reads connections from a Connections.csv file or from the zip archive LinkedIn delivers
+func ReadExport(path string) ([]storage.Connection, int, error) {
+	if strings.EqualFold(filepath.Ext(path), ".zip") {
+		archive, err := zip.OpenReader(path)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to open export archive: %w", err)
+		}
+		defer archive.Close()
+
+		for _, file := range archive.File {
+			if !strings.EqualFold(filepath.Base(file.Name), ExportFileName) {
+				continue
+			}
+			reader, err := file.Open()
+			if err != nil {
+				return nil, 0, fmt.Errorf("failed to open %s in archive: %w", file.Name, err)
+			}
+			defer reader.Close()
+			return ParseCSV(reader)
+		}
+		return nil, 0, fmt.Errorf("export archive %s does not contain %s", path, ExportFileName)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open export: %w", err)
+	}
+	defer file.Close()
+	return ParseCSV(file)
+}
+
+// ParseCSV parses LinkedIn's connections export, skipping the notes preamble before the header row.
+// It returns the connections and the number of rows skipped for lacking a profile URL.
+func ParseCSV(r io.Reader) ([]storage.Connection, int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var columns map[string]int
+	var connections []storage.Connection
+	skipped := 0
+	importedAt := time.Now()
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read connections export: %w", err)
+		}
+
+		if columns == nil {
+			// Everything before the header row is LinkedIn's explanatory notes
+			if len(record) > 0 && strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(record[0], "\ufeff")), "First Name") {
+				columns = make(map[string]int)
+				for i, name := range record {
+					columns[strings.ToLower(strings.TrimSpace(name))] = i
+				}
+				if _, ok := columns["url"]; !ok {
+					return nil, 0, fmt.Errorf("connections export header has no URL column")
+				}
+			}
+			continue
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		if field("url") == "" {
+			skipped++
+			continue
+		}
+
+		connections = append(connections, storage.Connection{
+			ProfileURL:  field("url"),
+			FirstName:   field("first name"),
+			LastName:    field("last name"),
+			Email:       field("email address"),
+			Company:     field("company"),
+			Position:    field("position"),
+			ConnectedOn: parseConnectedOn(field("connected on")),
+			ImportedAt:  importedAt,
+		})
+	}
+
+	if columns == nil {
+		return nil, 0, fmt.Errorf("connections export has no header row")
+	}
+	return connections, skipped, nil
+}
+
+// Import saves the exported connections and reports how they relate to earlier imports and tracked requests
+func Import(store Store, connections []storage.Connection) (ImportSummary, error) {
+	summary := ImportSummary{Total: len(connections)}
+
+	existing, err := store.GetConnections()
+	if err != nil {
+		return summary, fmt.Errorf("failed to load imported connections: %w", err)
+	}
+	known := make(map[string]storage.Connection, len(existing))
+	for _, connection := range existing {
+		known[identity.ProfileKey(connection.ProfileURL)] = connection
+	}
+
+	requests, err := store.GetSentRequests()
+	if err != nil {
+		return summary, fmt.Errorf("failed to load connection requests: %w", err)
+	}
+	requested := make(map[string]bool, len(requests))
+	for _, request := range requests {
+		requested[identity.ProfileKey(request.ProfileURL)] = true
+	}
+
+	for i := range connections {
+		key := identity.ProfileKey(connections[i].ProfileURL)
+		if previous, ok := known[key]; ok {
+			// Tags are given in this tool, never in the export
+			connections[i].Tags = previous.Tags
+			summary.Updated++
+		} else {
+			summary.New++
+		}
+		if requested[key] {
+			connections[i].Tracked = true
+			summary.Tracked++
+		}
+	}
+
+	if err := store.SaveConnections(connections); err != nil {
+		return summary, fmt.Errorf("failed to save connections: %w", err)
+	}
+
+	return summary, nil
+}
+
+// parseConnectedOn parses the "Connected On" column, returning the zero time when it is missing or unrecognised
+func parseConnectedOn(value string) time.Time {
+	for _, layout := range connectedOnLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
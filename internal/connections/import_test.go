@@ -0,0 +1,111 @@
+package connections
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/storage"
+)
+
+const sampleExport = `Notes:
+"When exporting your connection data, you may notice that some of the email addresses are missing. You will only see email addresses for connections who have allowed their connections to see or download their email address."
+
+First Name,Last Name,URL,Email Address,Company,Position,Connected On
+Jane,Doe,https://www.linkedin.com/in/jane-doe,jane@example.com,Acme,Engineering Manager,15 Oct 2024
+John,Smith,https://www.linkedin.com/in/john-smith-42,,"Widgets, Inc.",Founder,3 Jan 2023
+Ghost,Member,,,,,01 Feb 2022
+`
+
+// TestParseCSVSkipsNotes tests parsing LinkedIn's export with its notes preamble
+func TestParseCSVSkipsNotes(t *testing.T) {
+	connections, skipped, err := ParseCSV(strings.NewReader(sampleExport))
+	if err != nil {
+		t.Fatalf("failed to parse export: %v", err)
+	}
+	if len(connections) != 2 || skipped != 1 {
+		t.Fatalf("expected 2 connections and 1 skipped row, got %d and %d", len(connections), skipped)
+	}
+
+	john := connections[1]
+	if john.Company != "Widgets, Inc." || john.Email != "" || john.Position != "Founder" {
+		t.Errorf("unexpected fields for %+v", john)
+	}
+	if !john.ConnectedOn.Equal(time.Date(2023, time.January, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected connected on date %v", john.ConnectedOn)
+	}
+
+	if _, _, err := ParseCSV(strings.NewReader("Notes:\nnothing here\n")); err == nil {
+		t.Errorf("expected error for export without header row")
+	}
+}
+
+// TestImportFromArchive tests reading the zip archive and importing it twice into storage
+func TestImportFromArchive(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "Basic_LinkedInDataExport.zip")
+
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatalf("failed to create archive: %v", err)
+	}
+	writer := zip.NewWriter(file)
+	entry, err := writer.Create("Connections.csv")
+	if err != nil {
+		t.Fatalf("failed to add archive entry: %v", err)
+	}
+	if _, err := entry.Write([]byte(sampleExport)); err != nil {
+		t.Fatalf("failed to write archive entry: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to close archive: %v", err)
+	}
+	file.Close()
+
+	exported, _, err := ReadExport(archivePath)
+	if err != nil {
+		t.Fatalf("failed to read archive: %v", err)
+	}
+
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "sqlite", Path: dir, Database: "test.db"})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	// The tool invited Jane earlier; the stored URL has a trailing slash and tracking query
+	if err := store.SaveConnectionRequest(storage.ConnectionRequest{
+		ProfileURL: "https://linkedin.com/in/jane-doe/?trk=search",
+		SentAt:     time.Now(),
+		Status:     "pending",
+	}); err != nil {
+		t.Fatalf("failed to save request: %v", err)
+	}
+
+	summary, err := Import(store, exported)
+	if err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+	if summary.Total != 2 || summary.New != 2 || summary.Updated != 0 || summary.Tracked != 1 {
+		t.Fatalf("unexpected first import summary: %+v", summary)
+	}
+
+	summary, err = Import(store, exported)
+	if err != nil {
+		t.Fatalf("failed to re-import: %v", err)
+	}
+	if summary.New != 0 || summary.Updated != 2 {
+		t.Fatalf("unexpected second import summary: %+v", summary)
+	}
+
+	stored, err := store.GetConnections()
+	if err != nil {
+		t.Fatalf("failed to get connections: %v", err)
+	}
+	if len(stored) != 2 {
+		t.Fatalf("expected re-import to replace rather than duplicate, got %d connections", len(stored))
+	}
+}
@@ -0,0 +1,69 @@
+package connections
+
+import (
+	"fmt"
+
+	"linkedin-automation-framework/internal/identity"
+)
+
+// Network answers whether a profile is already a first-degree connection, either imported from
+// LinkedIn's export or recorded as an accepted request
+type Network struct {
+	urls   map[string]string // profile key -> how the connection is known
+	people map[string]string // normalized name and company -> profile URL of the imported connection
+}
+
+// LoadNetwork builds the network from imported connections and accepted connection requests
+func LoadNetwork(store Store) (*Network, error) {
+	network := &Network{
+		urls:   make(map[string]string),
+		people: make(map[string]string),
+	}
+
+	connections, err := store.GetConnections()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load imported connections: %w", err)
+	}
+	for _, connection := range connections {
+		network.urls[identity.ProfileKey(connection.ProfileURL)] = "imported connection"
+		if key := identity.PersonKey(connection.FirstName+" "+connection.LastName, connection.Company); key != "" {
+			network.people[key] = connection.ProfileURL
+		}
+	}
+
+	requests, err := store.GetSentRequests()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load connection requests: %w", err)
+	}
+	for _, request := range requests {
+		if request.Status == StatusAccepted {
+			if _, ok := network.urls[identity.ProfileKey(request.ProfileURL)]; !ok {
+				network.urls[identity.ProfileKey(request.ProfileURL)] = "accepted connection request"
+			}
+		}
+	}
+
+	return network, nil
+}
+
+// Size returns the number of known connections
+func (n *Network) Size() int {
+	return len(n.urls)
+}
+
+// InNetwork reports whether the profile is already a connection and why. The URL is checked first;
+// otherwise the name and company must both match an imported connection, since the same person
+// is often reached through a different URL (e.g. a vanity URL versus the ID-based one).
+func (n *Network) InNetwork(profileURL, name, company string) (bool, string) {
+	if profileURL != "" {
+		if reason, ok := n.urls[identity.ProfileKey(profileURL)]; ok {
+			return true, reason
+		}
+	}
+	if key := identity.PersonKey(name, company); key != "" {
+		if url, ok := n.people[key]; ok {
+			return true, fmt.Sprintf("name and company match imported connection %s", url)
+		}
+	}
+	return false, ""
+}
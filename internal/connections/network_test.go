@@ -0,0 +1,62 @@
+package connections
+
+import (
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/storage"
+)
+
+// TestNetworkMatchesURLAndFuzzyName tests detecting existing connections by URL or by name and company
+func TestNetworkMatchesURLAndFuzzyName(t *testing.T) {
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "json", Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.SaveConnections([]storage.Connection{{
+		ProfileURL: "https://www.linkedin.com/in/jane-doe-4b1a2c",
+		FirstName:  "Jane",
+		LastName:   "Doe",
+		Company:    "Acme, Inc.",
+		ImportedAt: time.Now(),
+	}}); err != nil {
+		t.Fatalf("failed to save connections: %v", err)
+	}
+	for _, request := range []storage.ConnectionRequest{
+		{ProfileURL: "https://www.linkedin.com/in/accepted-person", Status: StatusAccepted, SentAt: time.Now()},
+		{ProfileURL: "https://www.linkedin.com/in/pending-person", Status: StatusPending, SentAt: time.Now()},
+	} {
+		if err := store.SaveConnectionRequest(request); err != nil {
+			t.Fatalf("failed to save request: %v", err)
+		}
+	}
+
+	network, err := LoadNetwork(store)
+	if err != nil {
+		t.Fatalf("failed to load network: %v", err)
+	}
+	if network.Size() != 2 {
+		t.Fatalf("expected 2 known connections, got %d", network.Size())
+	}
+
+	cases := []struct {
+		url, name, company string
+		connected          bool
+	}{
+		{"https://linkedin.com/in/jane-doe-4b1a2c/?miniProfileUrn=abc", "", "", true},
+		{"https://www.linkedin.com/in/accepted-person/", "", "", true},
+		{"https://www.linkedin.com/in/pending-person", "", "", false},
+		{"https://www.linkedin.com/in/janedoe", "Jane Doe, PhD", "ACME Inc", true},
+		{"https://www.linkedin.com/in/janedoe", "Jane (JD) Doe", "acme", true},
+		{"https://www.linkedin.com/in/janedoe", "Jane Doe", "", false},
+		{"https://www.linkedin.com/in/janedoe", "Jane Doe", "Globex", false},
+	}
+	for _, c := range cases {
+		connected, reason := network.InNetwork(c.url, c.name, c.company)
+		if connected != c.connected {
+			t.Errorf("InNetwork(%q, %q, %q) = %v (%s), expected %v", c.url, c.name, c.company, connected, reason, c.connected)
+		}
+	}
+}
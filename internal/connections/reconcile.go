@@ -0,0 +1,143 @@
+package connections
+
+import (
+	"fmt"
+	"time"
+
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// DefaultPendingExpiry is how long LinkedIn keeps an unanswered invitation before withdrawing it
+const DefaultPendingExpiry = 180 * 24 * time.Hour
+
+// Request statuses set by reconciliation, alongside pending, accepted and declined
+const (
+	StatusAccepted    = "accepted"
+	StatusPending     = "pending"
+	StatusDisappeared = "disappeared" // Recorded as accepted but no longer in the connections export
+	StatusExpired     = "expired"     // Pending past DefaultPendingExpiry without becoming a connection
+)
+
+// Reconciler is the storage needed to compare imported connections with tracked requests and fix drift
+type Reconciler interface {
+	Store
+	UpdateConnectionRequestStatus(profileURL, status string) error
+}
+
+// ReconcileOptions controls a reconciliation run
+type ReconcileOptions struct {
+	PendingExpiry time.Duration // Defaults to DefaultPendingExpiry
+	DryRun        bool          // Report drift without updating storage
+	Now           time.Time     // Defaults to time.Now, for tests
+}
+
+// Report describes how local tracking drifted from the imported connections list
+type Report struct {
+	Snapshot    time.Time                   // When the compared export was imported
+	Connections int                         // Connections in the import
+	Requests    int                         // Profiles the tool sent requests to before the snapshot
+	Accepted    []storage.ConnectionRequest // Recorded as pending but connected; marked accepted
+	Untracked   []storage.Connection        // Connected without a request from this tool
+	Disappeared []storage.ConnectionRequest // Recorded as accepted but missing from the export; marked disappeared
+	Expired     []storage.ConnectionRequest // Pending past the expiry without connecting; marked expired
+}
+
+// Drift is the number of requests whose stored status was wrong
+func (r Report) Drift() int {
+	return len(r.Accepted) + len(r.Disappeared) + len(r.Expired)
+}
+
+// Reconcile compares the latest imported connections with the connection requests sent by the tool.
+// Requests sent after the export was imported are left alone since the export cannot reflect them.
+func Reconcile(store Reconciler, options ReconcileOptions) (Report, error) {
+	var report Report
+	if options.PendingExpiry <= 0 {
+		options.PendingExpiry = DefaultPendingExpiry
+	}
+	if options.Now.IsZero() {
+		options.Now = time.Now()
+	}
+
+	connections, err := store.GetConnections()
+	if err != nil {
+		return report, fmt.Errorf("failed to load imported connections: %w", err)
+	}
+	if len(connections) == 0 {
+		return report, fmt.Errorf("no imported connections; import a connections export first")
+	}
+
+	connected := make(map[string]bool, len(connections))
+	for _, connection := range connections {
+		connected[identity.ProfileKey(connection.ProfileURL)] = true
+		if connection.ImportedAt.After(report.Snapshot) {
+			report.Snapshot = connection.ImportedAt
+		}
+	}
+	report.Connections = len(connections)
+
+	requests, err := store.GetSentRequests()
+	if err != nil {
+		return report, fmt.Errorf("failed to load connection requests: %w", err)
+	}
+
+	// Judge each profile by its most recent request
+	latest := make(map[string]storage.ConnectionRequest)
+	for _, request := range requests {
+		if request.SentAt.After(report.Snapshot) {
+			continue
+		}
+		key := identity.ProfileKey(request.ProfileURL)
+		if previous, ok := latest[key]; !ok || request.SentAt.After(previous.SentAt) {
+			latest[key] = request
+		}
+	}
+	report.Requests = len(latest)
+
+	var updates []storage.ConnectionRequest
+	for key, request := range latest {
+		switch {
+		case connected[key] && request.Status != StatusAccepted:
+			report.Accepted = append(report.Accepted, request)
+			request.Status = StatusAccepted
+			updates = append(updates, request)
+		case !connected[key] && request.Status == StatusAccepted:
+			report.Disappeared = append(report.Disappeared, request)
+			request.Status = StatusDisappeared
+			updates = append(updates, request)
+		case !connected[key] && request.Status == StatusPending && options.Now.Sub(request.SentAt) > options.PendingExpiry:
+			report.Expired = append(report.Expired, request)
+			request.Status = StatusExpired
+			updates = append(updates, request)
+		}
+	}
+
+	var retagged []storage.Connection
+	for _, connection := range connections {
+		_, tracked := latest[identity.ProfileKey(connection.ProfileURL)]
+		if !tracked {
+			report.Untracked = append(report.Untracked, connection)
+		}
+		if connection.Tracked != tracked {
+			connection.Tracked = tracked
+			retagged = append(retagged, connection)
+		}
+	}
+
+	if options.DryRun {
+		return report, nil
+	}
+
+	for _, request := range updates {
+		if err := store.UpdateConnectionRequestStatus(request.ProfileURL, request.Status); err != nil {
+			return report, err
+		}
+	}
+	if len(retagged) > 0 {
+		if err := store.SaveConnections(retagged); err != nil {
+			return report, fmt.Errorf("failed to mark untracked connections: %w", err)
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,92 @@
+package connections
+
+import (
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/storage"
+)
+
+// TestReconcileReportsDrift tests that reconciliation fixes request statuses and marks untracked connections
+func TestReconcileReportsDrift(t *testing.T) {
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "json", Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Now()
+	requests := []storage.ConnectionRequest{
+		{ProfileURL: "https://www.linkedin.com/in/accepted/", SentAt: now.Add(-72 * time.Hour), Status: StatusPending},
+		{ProfileURL: "https://www.linkedin.com/in/removed", SentAt: now.Add(-90 * 24 * time.Hour), Status: StatusAccepted},
+		{ProfileURL: "https://www.linkedin.com/in/ignored", SentAt: now.Add(-200 * 24 * time.Hour), Status: StatusPending},
+		{ProfileURL: "https://www.linkedin.com/in/waiting", SentAt: now.Add(-48 * time.Hour), Status: StatusPending},
+		{ProfileURL: "https://www.linkedin.com/in/after-export", SentAt: now.Add(time.Hour), Status: StatusPending},
+	}
+	for _, request := range requests {
+		if err := store.SaveConnectionRequest(request); err != nil {
+			t.Fatalf("failed to save request: %v", err)
+		}
+	}
+
+	if err := store.SaveConnections([]storage.Connection{
+		{ProfileURL: "https://www.linkedin.com/in/accepted", ImportedAt: now},
+		{ProfileURL: "https://www.linkedin.com/in/manual", ImportedAt: now},
+	}); err != nil {
+		t.Fatalf("failed to save connections: %v", err)
+	}
+
+	report, err := Reconcile(store, ReconcileOptions{DryRun: true, Now: now})
+	if err != nil {
+		t.Fatalf("failed to reconcile: %v", err)
+	}
+	if report.Requests != 4 || report.Drift() != 3 {
+		t.Fatalf("expected 4 requests and 3 drifted, got %+v", report)
+	}
+	if len(report.Accepted) != 1 || len(report.Disappeared) != 1 || len(report.Expired) != 1 {
+		t.Fatalf("unexpected drift: %+v", report)
+	}
+	if len(report.Untracked) != 1 || report.Untracked[0].ProfileURL != "https://www.linkedin.com/in/manual/" {
+		t.Fatalf("expected the manual connection to be untracked, got %+v", report.Untracked)
+	}
+
+	// A dry run leaves storage untouched; a real run applies the same changes once
+	if _, err := Reconcile(store, ReconcileOptions{Now: now}); err != nil {
+		t.Fatalf("failed to reconcile: %v", err)
+	}
+	stored, err := store.GetSentRequests()
+	if err != nil {
+		t.Fatalf("failed to get requests: %v", err)
+	}
+	// Storage keeps canonical profile URLs
+	expected := map[string]string{
+		"https://www.linkedin.com/in/accepted/":     StatusAccepted,
+		"https://www.linkedin.com/in/removed/":      StatusDisappeared,
+		"https://www.linkedin.com/in/ignored/":      StatusExpired,
+		"https://www.linkedin.com/in/waiting/":      StatusPending,
+		"https://www.linkedin.com/in/after-export/": StatusPending,
+	}
+	for _, request := range stored {
+		if request.Status != expected[request.ProfileURL] {
+			t.Errorf("%s: expected status %q, got %q", request.ProfileURL, expected[request.ProfileURL], request.Status)
+		}
+	}
+
+	connections, err := store.GetConnections()
+	if err != nil {
+		t.Fatalf("failed to get connections: %v", err)
+	}
+	for _, connection := range connections {
+		if connection.Tracked != (connection.ProfileURL == "https://www.linkedin.com/in/accepted/") {
+			t.Errorf("%s: unexpected tracked flag %v", connection.ProfileURL, connection.Tracked)
+		}
+	}
+
+	report, err = Reconcile(store, ReconcileOptions{Now: now})
+	if err != nil {
+		t.Fatalf("failed to reconcile: %v", err)
+	}
+	if report.Drift() != 0 {
+		t.Fatalf("expected no drift after reconciling, got %+v", report)
+	}
+}
@@ -0,0 +1,155 @@
+// Package content finds prospects by what they post: it searches recent posts for a hashtag or
+// keyword and feeds their authors into the lead pool, tagged with the query and, optionally, the
+// post's text so a reviewer can mention it.
+package content
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"linkedin-automation-framework/internal/annotations"
+	"linkedin-automation-framework/internal/engagers"
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/search"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// AttributePostText is the lead attribute holding the text of the post the lead was found by,
+// recorded when Options asks for it. The post_topic and post_url attributes are those of
+// engagers.
+const AttributePostText = "post_text"
+
+// Source is who queued the authors and set their attributes
+const Source = "content-search"
+
+// MaxTextLength is how many characters of a post's text are recorded
+const MaxTextLength = 500
+
+// searchURL is LinkedIn's content search, most recent posts first
+const searchURL = "https://www.linkedin.com/search/results/content/?sortBy=%22date_posted%22&keywords="
+
+// Values LinkedIn's content search takes for how recent posts must be
+var postedWithin = []string{"past-24h", "past-week", "past-month"}
+
+// SearchURL returns the content search for query, a hashtag such as "#golang" or keywords,
+// limited to posts from posted ("past-24h", "past-week" or "past-month", "" for any time)
+func SearchURL(query, posted string) string {
+	searchURL := searchURL + url.QueryEscape(strings.TrimSpace(query))
+	if posted != "" {
+		searchURL += "&datePosted=" + url.QueryEscape(`"`+posted+`"`)
+	}
+	return searchURL
+}
+
+// CheckPosted refuses a posted value LinkedIn's content search does not take
+func CheckPosted(posted string) error {
+	for _, value := range postedWithin {
+		if posted == value || posted == "" {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid posted %q: expected one of %s", posted, strings.Join(postedWithin, ", "))
+}
+
+// Store keeps the authors as leads, their attributes and the queue campaigns take leads from
+type Store interface {
+	annotations.Store
+	SaveSearchResults(results []storage.ProfileResult) error
+	QueueLeads(leads []storage.QueuedLead) (int, error)
+}
+
+// Post is a content search result
+type Post struct {
+	URL            string // Link to the post, "" when the result does not give its URN
+	AuthorURL      string
+	AuthorName     string
+	AuthorHeadline string
+	Text           string
+}
+
+// Options controls how Feed adds the authors of a search's posts
+type Options struct {
+	Query      string // The search, recorded as the leads' post_topic
+	RecordText bool   // Record each post's text in the post_text attribute
+	Queue      bool   // Queue the authors for the next run of any campaign
+	// Exclude returns why an author must not be fed, e.g. "already_connected", or "" to go ahead
+	Exclude func(post Post) string
+}
+
+// Report counts what Feed did with the posts found
+type Report struct {
+	Posts  int
+	Fed    []string       // Profile URLs of the authors saved as leads
+	Queued int            // Of those, newly queued
+	Passed map[string]int // Authors not fed, by reason
+}
+
+// Feed saves the authors of posts as leads, each tagged with the query and the URL, and optionally
+// the text, of their first post in the results. An author with several posts is fed once.
+func Feed(store Store, posts []Post, options Options, now time.Time) (Report, error) {
+	report := Report{Posts: len(posts), Passed: make(map[string]int)}
+	seen := make(map[string]bool)
+
+	var results []storage.ProfileResult
+	var queue []storage.QueuedLead
+	for _, post := range posts {
+		key := identity.ProfileKey(post.AuthorURL)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if options.Exclude != nil {
+			if reason := options.Exclude(post); reason != "" {
+				report.Passed[reason]++
+				continue
+			}
+		}
+
+		attributes := map[string]string{engagers.AttributePostTopic: strings.TrimSpace(options.Query)}
+		if post.URL != "" {
+			attributes[engagers.AttributePostURL] = post.URL
+		}
+		if options.RecordText && post.Text != "" {
+			attributes[AttributePostText] = truncate(post.Text, MaxTextLength)
+		}
+		if err := annotations.SetAttributes(store, post.AuthorURL, attributes, Source, now); err != nil {
+			return report, err
+		}
+		results = append(results, storage.ProfileResult{
+			URL:       post.AuthorURL,
+			Name:      post.AuthorName,
+			Title:     post.AuthorHeadline,
+			Company:   search.CompanyFromHeadline(post.AuthorHeadline),
+			Timestamp: now,
+		})
+		if options.Queue {
+			queue = append(queue, storage.QueuedLead{ProfileURL: post.AuthorURL, Source: Source, QueuedAt: now})
+		}
+		report.Fed = append(report.Fed, post.AuthorURL)
+	}
+
+	if len(results) > 0 {
+		if err := store.SaveSearchResults(results); err != nil {
+			return report, fmt.Errorf("failed to save authors: %w", err)
+		}
+	}
+	if len(queue) > 0 {
+		queued, err := store.QueueLeads(queue)
+		if err != nil {
+			return report, fmt.Errorf("failed to queue authors: %w", err)
+		}
+		report.Queued = queued
+	}
+	return report, nil
+}
+
+// truncate shortens text to at most max characters, ending it with "…" when cut
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:max-1])) + "…"
+}
@@ -0,0 +1,129 @@
+package content
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/annotations"
+	"linkedin-automation-framework/internal/browser/browsertest"
+	"linkedin-automation-framework/internal/engagers"
+	"linkedin-automation-framework/internal/selectors"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// result builds a content search result card
+func result(urn, href, name, headline, text string) *browsertest.Element {
+	set := selectors.Desktop
+	card := browsertest.NewElement(set.ContentCards[0])
+	if urn != "" {
+		card.SetAttribute("data-urn", urn)
+	}
+	return card.Append(
+		browsertest.NewElement(set.PostAuthor[0]).SetAttribute("href", href).SetText(name),
+		browsertest.NewElement(set.ContentAuthorHeadline[0]).SetText(headline),
+		browsertest.NewElement(set.PostText[0]).SetText(text),
+	)
+}
+
+// TestScraper tests that posts are read across "load more" pages and that company posts are
+// left out
+func TestScraper(t *testing.T) {
+	searchURL := SearchURL("#golang", "past-week")
+	page := browsertest.NewPage("https://www.linkedin.com/feed/")
+	page.Route(searchURL, func(page *browsertest.Page) {
+		page.Append(
+			result("urn:li:activity:7100000000000000001", "https://www.linkedin.com/in/jane-doe?trk=x", "Jane Doe", "Staff Engineer at Initech", "Why we moved to #golang"),
+			result("urn:li:activity:7100000000000000002", "https://www.linkedin.com/company/acme/", "Acme", "Software", "We are hiring #golang developers"),
+		)
+		more := browsertest.NewElement(selectors.Desktop.MoreContent[0])
+		more.OnClick(func() {
+			page.Append(result("", "/in/john-roe/", "John Roe", "CTO at Globex", "Generics in #golang"))
+			more.Remove()
+		})
+		page.Append(more)
+	})
+
+	posts, err := NewScraper(page, selectors.Desktop).Search(context.Background(), "#golang", "past-week", 0)
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	if len(posts) != 2 {
+		t.Fatalf("expected jane's and john's posts, got %+v", posts)
+	}
+	expected := Post{
+		URL:            "https://www.linkedin.com/feed/update/urn:li:activity:7100000000000000001/",
+		AuthorURL:      "https://www.linkedin.com/in/jane-doe/",
+		AuthorName:     "Jane Doe",
+		AuthorHeadline: "Staff Engineer at Initech",
+		Text:           "Why we moved to #golang",
+	}
+	if posts[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, posts[0])
+	}
+	if posts[1].AuthorURL != "https://www.linkedin.com/in/john-roe/" || posts[1].URL != "" {
+		t.Errorf("expected john's post without a URL, got %+v", posts[1])
+	}
+}
+
+// TestFeed tests that authors are saved once, tagged with the query and, when asked, the post's
+// text, and that excluded authors are passed over
+func TestFeed(t *testing.T) {
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "sqlite", Path: t.TempDir(), Database: "test.db"})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	posts := []Post{
+		{URL: "https://www.linkedin.com/feed/update/urn:li:activity:1/", AuthorURL: "https://www.linkedin.com/in/jane-doe/", AuthorName: "Jane Doe", AuthorHeadline: "Staff Engineer at Initech", Text: strings.Repeat("go ", 300)},
+		{URL: "https://www.linkedin.com/feed/update/urn:li:activity:2/", AuthorURL: "https://www.linkedin.com/in/jane-doe", AuthorName: "Jane Doe", Text: "An older post"},
+		{AuthorURL: "https://www.linkedin.com/in/bob-ray/", AuthorName: "Bob Ray"},
+	}
+	options := Options{
+		Query:      "#golang",
+		RecordText: true,
+		Queue:      true,
+		Exclude: func(post Post) string {
+			if post.AuthorName == "Bob Ray" {
+				return "already_connected"
+			}
+			return ""
+		},
+	}
+
+	report, err := Feed(store, posts, options, now)
+	if err != nil {
+		t.Fatalf("failed to feed authors: %v", err)
+	}
+	if report.Posts != 3 || len(report.Fed) != 1 || report.Queued != 1 || report.Passed["already_connected"] != 1 {
+		t.Fatalf("expected only jane fed, got %+v", report)
+	}
+	jane, err := annotations.For(store, "https://www.linkedin.com/in/jane-doe/")
+	if err != nil {
+		t.Fatalf("failed to load jane's attributes: %v", err)
+	}
+	text := []rune(jane.Attributes[AttributePostText])
+	if jane.Attributes[engagers.AttributePostTopic] != "#golang" || jane.Attributes[engagers.AttributePostURL] != posts[0].URL || len(text) > MaxTextLength || text[len(text)-1] != '…' {
+		t.Errorf("unexpected attributes %+v", jane.Attributes)
+	}
+	results, err := store.GetSearchResults()
+	if err != nil || len(results) != 1 || results[0].Company != "Initech" {
+		t.Errorf("expected jane saved as a lead, got %+v (%v)", results, err)
+	}
+}
+
+// TestSearchURL tests the search for a hashtag and the posted filter
+func TestSearchURL(t *testing.T) {
+	if got := SearchURL(" #golang ", ""); got != "https://www.linkedin.com/search/results/content/?sortBy=%22date_posted%22&keywords=%23golang" {
+		t.Errorf("unexpected search URL %s", got)
+	}
+	if got := SearchURL("platform engineering", "past-24h"); !strings.HasSuffix(got, "keywords=platform+engineering&datePosted=%22past-24h%22") {
+		t.Errorf("unexpected search URL %s", got)
+	}
+	if err := CheckPosted("past-year"); err == nil {
+		t.Error("expected past-year to be refused")
+	}
+}
@@ -0,0 +1,110 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"linkedin-automation-framework/internal/browser"
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/search"
+	"linkedin-automation-framework/internal/selectors"
+)
+
+// DefaultMaxLoads is how many times Search loads more results when given no limit
+const DefaultMaxLoads = 3
+
+// postURL is the link to a post by its activity URN
+const postURL = "https://www.linkedin.com/feed/update/"
+
+// Scraper reads LinkedIn's content search results
+type Scraper struct {
+	page      browser.PageDriver
+	selectors selectors.Set
+}
+
+// NewScraper creates a scraper for LinkedIn's content search, reading each result card into a
+// post with its author's profile, name and headline
+func NewScraper(page browser.PageDriver, set selectors.Set) *Scraper {
+	return &Scraper{page: page, selectors: set}
+}
+
+// Search runs the content search for query and returns the posts whose author shows a member
+// profile, loading more results up to maxLoads times (DefaultMaxLoads if 0). Posts by companies
+// are left out.
+func (s *Scraper) Search(ctx context.Context, query, posted string, maxLoads int) ([]Post, error) {
+	if maxLoads <= 0 {
+		maxLoads = DefaultMaxLoads
+	}
+	page := s.page.Context(ctx)
+	searchURL := SearchURL(query, posted)
+	if err := page.Navigate(searchURL); err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", searchURL, err)
+	}
+	if err := page.WaitLoad(); err != nil {
+		return nil, fmt.Errorf("failed to wait for %s to load: %w", searchURL, err)
+	}
+
+	for loads := 0; loads < maxLoads; loads++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		button, ok := browser.FirstElement(page, s.selectors.MoreContent)
+		if !ok {
+			break
+		}
+		if visible, err := button.Visible(); err != nil || !visible {
+			break
+		}
+		if err := button.Click(); err != nil {
+			return nil, fmt.Errorf("failed to load more results: %w", err)
+		}
+		if err := page.WaitLoad(); err != nil {
+			return nil, fmt.Errorf("failed to wait for more results to load: %w", err)
+		}
+	}
+
+	var posts []Post
+	for _, selector := range s.selectors.ContentCards {
+		cards, err := page.Elements(selector)
+		if err != nil || len(cards) == 0 {
+			continue
+		}
+		for _, card := range cards {
+			if post, ok := s.post(card); ok {
+				posts = append(posts, post)
+			}
+		}
+		break
+	}
+	return posts, nil
+}
+
+// post reads one result card
+func (s *Scraper) post(card browser.ElementDriver) (Post, bool) {
+	var post Post
+	for _, selector := range s.selectors.PostAuthor {
+		link, err := card.Element(selector)
+		if err != nil {
+			continue
+		}
+		href, err := link.Attribute("href")
+		if err != nil || href == nil || !search.IsValidLinkedInProfileURL(identity.NormalizeProfileURL(*href)) {
+			continue
+		}
+		post.AuthorURL = identity.NormalizeProfileURL(*href)
+		if text, err := link.Text(); err == nil {
+			post.AuthorName = search.CleanText(text)
+		}
+		break
+	}
+	if post.AuthorURL == "" {
+		return Post{}, false
+	}
+	if urn, err := card.Attribute("data-urn"); err == nil && urn != nil && strings.HasPrefix(*urn, "urn:li:activity:") {
+		post.URL = postURL + *urn + "/"
+	}
+	post.AuthorHeadline = search.CleanText(browser.FirstText(card, s.selectors.ContentAuthorHeadline))
+	post.Text = search.CleanText(browser.FirstText(card, s.selectors.PostText))
+	return post, true
+}
@@ -0,0 +1,105 @@
+package control
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"linkedin-automation-framework/internal/session"
+)
+
+// State describes whether automation is running
+type State string
+
+const (
+	StateRunning State = "running" // Workers run normally
+	StatePausing State = "pausing" // A pause was requested; workers stop at their next safe point
+	StatePaused  State = "paused"  // At least one worker is waiting at a safe point
+)
+
+// Status is what the control channel reports about a run
+type Status struct {
+	State    State      `json:"state"`
+	Reason   string     `json:"reason,omitempty"`    // Why the run was paused
+	Position string     `json:"position,omitempty"`  // Last safe point reached, e.g. "lead 12 of 40"
+	PausedAt *time.Time `json:"paused_at,omitempty"` // When the pause was requested
+}
+
+// Controller pauses automation at the next safe point on request and resumes it where it stopped.
+// Workers call Checkpoint between actions; a pause never interrupts an action halfway, so the page
+// is left as it was for the user to solve a puzzle or challenge by hand.
+type Controller struct {
+	gate     *session.Gate
+	mutex    sync.Mutex
+	position string
+	pausedAt time.Time
+	waiting  int
+}
+
+// NewController creates a controller that lets workers run
+func NewController() *Controller {
+	return &Controller{gate: session.NewGate()}
+}
+
+// Pause asks workers to stop at their next safe point
+func (c *Controller) Pause(reason string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if paused, _ := c.gate.Paused(); !paused {
+		c.pausedAt = time.Now()
+	}
+	c.gate.Pause(reason)
+}
+
+// Resume lets paused workers continue from the safe point they stopped at
+func (c *Controller) Resume() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.gate.Resume()
+	c.pausedAt = time.Time{}
+}
+
+// Checkpoint records position as the worker's last safe point and blocks while a pause is in effect
+func (c *Controller) Checkpoint(ctx context.Context, position string) error {
+	c.mutex.Lock()
+	c.position = position
+	c.waiting++
+	c.mutex.Unlock()
+
+	defer func() {
+		c.mutex.Lock()
+		c.waiting--
+		c.mutex.Unlock()
+	}()
+	return c.gate.Wait(ctx)
+}
+
+// Wait blocks while a pause is in effect without moving the recorded position
+func (c *Controller) Wait(ctx context.Context) error {
+	c.mutex.Lock()
+	position := c.position
+	c.mutex.Unlock()
+
+	return c.Checkpoint(ctx, position)
+}
+
+// Status reports the current state and where the run stopped
+func (c *Controller) Status() Status {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	paused, reason := c.gate.Paused()
+	status := Status{State: StateRunning, Position: c.position}
+	if paused {
+		status.State = StatePausing
+		if c.waiting > 0 {
+			status.State = StatePaused
+		}
+		status.Reason = reason
+		pausedAt := c.pausedAt
+		status.PausedAt = &pausedAt
+	}
+	return status
+}
@@ -0,0 +1,194 @@
+package control
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestControllerPausesAtCheckpoint tests that a worker stops at its next safe point and continues from it
+func TestControllerPausesAtCheckpoint(t *testing.T) {
+	controller := NewController()
+	if err := controller.Checkpoint(context.Background(), "lead 1 of 3"); err != nil {
+		t.Fatalf("expected a running controller to pass the checkpoint, got %v", err)
+	}
+
+	controller.Pause("puzzle")
+	if status := controller.Status(); status.State != StatePausing || status.Reason != "puzzle" || status.PausedAt == nil {
+		t.Fatalf("expected a pausing state before any worker stops, got %+v", status)
+	}
+
+	passed := make(chan error, 1)
+	go func() {
+		passed <- controller.Checkpoint(context.Background(), "lead 2 of 3")
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for controller.Status().State != StatePaused {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the worker to wait at its checkpoint, got %+v", controller.Status())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if status := controller.Status(); status.Position != "lead 2 of 3" {
+		t.Errorf("expected the run to stop at lead 2, got %q", status.Position)
+	}
+
+	controller.Resume()
+	select {
+	case err := <-passed:
+		if err != nil {
+			t.Errorf("expected the worker to continue, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("worker still waiting after resume")
+	}
+	if status := controller.Status(); status.State != StateRunning || status.PausedAt != nil || status.Position != "lead 2 of 3" {
+		t.Errorf("unexpected status after resume %+v", status)
+	}
+
+	// A paused worker gives up when the run is cancelled
+	controller.Pause("")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := controller.Wait(ctx); err == nil {
+		t.Errorf("expected a cancelled wait to fail")
+	}
+}
+
+// TestClientControlsHandler tests pausing and resuming over HTTP
+func TestClientControlsHandler(t *testing.T) {
+	controller := NewController()
+	server := httptest.NewServer(Handler(controller))
+	defer server.Close()
+
+	client := NewClient(strings.TrimPrefix(server.URL, "http://"), nil)
+	ctx := context.Background()
+
+	status, err := client.Pause(ctx, "solving a puzzle")
+	if err != nil {
+		t.Fatalf("failed to pause: %v", err)
+	}
+	if status.State != StatePausing || status.Reason != "solving a puzzle" {
+		t.Errorf("unexpected status after pause %+v", status)
+	}
+	if paused, _ := controller.gate.Paused(); !paused {
+		t.Errorf("expected the controller to be paused")
+	}
+
+	status, err = client.Resume(ctx)
+	if err != nil || status.State != StateRunning {
+		t.Errorf("expected a running state after resume, got %+v (%v)", status, err)
+	}
+	if status, err = client.Status(ctx); err != nil || status.State != StateRunning {
+		t.Errorf("expected a running status, got %+v (%v)", status, err)
+	}
+
+	if _, err := client.call(ctx, "GET", "/pause"); err == nil {
+		t.Errorf("expected pausing with GET to be rejected")
+	}
+}
+
+// TestClientCertificates tests that a channel requiring client certificates only answers
+// clients presenting one signed by its CA
+func TestClientCertificates(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := writeCertificate(t, dir, "ca", nil, nil)
+	writeCertificate(t, dir, "server", ca, caKey)
+	writeCertificate(t, dir, "client", ca, caKey)
+	writeCertificate(t, dir, "stranger", nil, nil)
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	serverTLS, err := ServerTLS(path("server.pem"), path("server.key"), path("ca.pem"))
+	if err != nil {
+		t.Fatalf("ServerTLS failed: %v", err)
+	}
+	server := httptest.NewUnstartedServer(Handler(NewController()))
+	server.TLS = serverTLS
+	server.StartTLS()
+	defer server.Close()
+	address := strings.TrimPrefix(server.URL, "https://")
+
+	for _, check := range []struct {
+		name, certificate string
+		ok                bool
+	}{
+		{"signed client", "client", true},
+		{"no client certificate", "", false},
+		{"self-signed client", "stranger", false},
+	} {
+		certFile, keyFile := "", ""
+		if check.certificate != "" {
+			certFile, keyFile = path(check.certificate+".pem"), path(check.certificate+".key")
+		}
+		clientTLS, err := ClientTLS(path("ca.pem"), certFile, keyFile)
+		if err != nil {
+			t.Fatalf("%s: ClientTLS failed: %v", check.name, err)
+		}
+		_, err = NewClient(address, clientTLS).Status(context.Background())
+		if (err == nil) != check.ok {
+			t.Errorf("%s: expected success %v, got %v", check.name, check.ok, err)
+		}
+	}
+
+	if _, err := ClientTLS(path("server.key"), "", ""); err == nil {
+		t.Error("expected a CA file without certificates to be rejected")
+	}
+}
+
+// writeCertificate writes name.pem and name.key to dir, signed by parent or, without one,
+// self-signed as a CA
+func writeCertificate(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(filepath.Join(dir, name+".pem"), certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".key"), keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certificate, key
+}
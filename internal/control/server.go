@@ -0,0 +1,160 @@
+package control
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"linkedin-automation-framework/internal/webhook"
+)
+
+// Handler serves the control channel:
+//
+//	GET  /status           current Status
+//	POST /pause?reason=... pause at the next safe point
+//	POST /resume           continue where the run stopped
+//	GET  /schema           webhook payload schemas, as served by webhook.SchemaHandler
+//
+// Every endpoint but /schema answers with the resulting Status as JSON.
+func Handler(controller *Controller) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/schema", webhook.SchemaHandler())
+	mux.Handle("/schema/", webhook.SchemaHandler())
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "use GET", http.StatusMethodNotAllowed)
+			return
+		}
+		writeStatus(w, controller.Status())
+	})
+	mux.HandleFunc("/pause", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "use POST", http.StatusMethodNotAllowed)
+			return
+		}
+		reason := strings.TrimSpace(r.URL.Query().Get("reason"))
+		if reason == "" {
+			reason = "paused by user"
+		}
+		controller.Pause(reason)
+		writeStatus(w, controller.Status())
+	})
+	mux.HandleFunc("/resume", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "use POST", http.StatusMethodNotAllowed)
+			return
+		}
+		controller.Resume()
+		writeStatus(w, controller.Status())
+	})
+	return mux
+}
+
+// writeStatus encodes status as the response body
+func writeStatus(w http.ResponseWriter, status Status) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+// Serve listens on address until ctx ends. The channel has no authentication, so address
+// should be a loopback address such as 127.0.0.1:8765.
+func Serve(ctx context.Context, address string, controller *Controller) error {
+	return ServeHandler(ctx, address, Handler(controller), nil)
+}
+
+// ServeHandler is Serve for a handler that extends Handler, e.g. with more routes. A
+// non-nil tlsConfig, e.g. from ServerTLS, serves HTTPS instead of HTTP.
+func ServeHandler(ctx context.Context, address string, handler http.Handler, tlsConfig *tls.Config) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	server := &http.Server{Handler: handler, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	if err := server.Serve(listener); err != nil && !stderrors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Client talks to the control channel of a running instance
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// NewClient creates a client for the channel listening on address. A non-nil tlsConfig, e.g.
+// from ClientTLS, talks HTTPS to a channel served with TLS.
+func NewClient(address string, tlsConfig *tls.Config) *Client {
+	if tlsConfig == nil {
+		return &Client{
+			baseURL: "http://" + address,
+			http:    &http.Client{Timeout: 10 * time.Second},
+		}
+	}
+	return &Client{
+		baseURL: "https://" + address,
+		http: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+	}
+}
+
+// Status returns the state of the running instance
+func (c *Client) Status(ctx context.Context) (Status, error) {
+	return c.call(ctx, http.MethodGet, "/status")
+}
+
+// Pause asks the running instance to stop at its next safe point
+func (c *Client) Pause(ctx context.Context, reason string) (Status, error) {
+	path := "/pause"
+	if reason != "" {
+		path += "?reason=" + url.QueryEscape(reason)
+	}
+	return c.call(ctx, http.MethodPost, path)
+}
+
+// Resume lets the running instance continue
+func (c *Client) Resume(ctx context.Context) (Status, error) {
+	return c.call(ctx, http.MethodPost, "/resume")
+}
+
+// call sends one request and decodes the Status it answers with
+func (c *Client) call(ctx context.Context, method, path string) (Status, error) {
+	request, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+	if err != nil {
+		return Status{}, err
+	}
+	response, err := c.http.Do(request)
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to reach control channel at %s: %w", c.baseURL, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return Status{}, fmt.Errorf("control channel returned %s", response.Status)
+	}
+	var status Status
+	if err := json.NewDecoder(response.Body).Decode(&status); err != nil {
+		return Status{}, fmt.Errorf("failed to parse control channel response: %w", err)
+	}
+	return status, nil
+}
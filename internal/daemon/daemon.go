@@ -0,0 +1,232 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"linkedin-automation-framework/internal/browser"
+	"linkedin-automation-framework/internal/control"
+	"linkedin-automation-framework/internal/errors"
+	"linkedin-automation-framework/internal/latency"
+)
+
+// ErrNotRunning is returned by the client when no daemon answers on the socket
+var ErrNotRunning = stderrors.New("daemon is not running")
+
+// Info describes a running daemon
+type Info struct {
+	PID       int                  `json:"pid"`
+	RunID     string               `json:"run_id"`
+	StartedAt time.Time            `json:"started_at"`
+	Control   control.Status       `json:"control"`                // Whether the daemon's workers are running or paused
+	Memory    *browser.MemoryStats `json:"memory,omitempty"`       // Sampled after each scheduled search
+	Latency   *latency.Status      `json:"latency,omitempty"`      // Nil unless latency is tracked
+	Failure   *Failure             `json:"last_failure,omitempty"` // Last scheduled search that failed
+}
+
+// Failure is a scheduled search that failed, with the error's code if it has one
+type Failure struct {
+	Search string      `json:"search"`
+	Error  string      `json:"error"`
+	Code   errors.Code `json:"code,omitempty"`
+	At     time.Time   `json:"at"`
+}
+
+// NewFailure describes the failure of search at
+func NewFailure(search string, err error, at time.Time) *Failure {
+	return &Failure{Search: search, Error: err.Error(), Code: errors.CodeOf(err), At: at}
+}
+
+// Handler serves the daemon socket:
+//
+//	GET  /daemon           current Info
+//	POST /stop             shut down cleanly, answering with the Info before stopping
+//	GET  /status, POST /pause and POST /resume as served by control.Handler
+func Handler(controller *control.Controller, info func() Info, stop func()) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", control.Handler(controller))
+	mux.HandleFunc("/daemon", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "use GET", http.StatusMethodNotAllowed)
+			return
+		}
+		writeInfo(w, info())
+	})
+	mux.HandleFunc("/stop", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "use POST", http.StatusMethodNotAllowed)
+			return
+		}
+		writeInfo(w, info())
+		stop()
+	})
+	return mux
+}
+
+// writeInfo encodes info as the response body
+func writeInfo(w http.ResponseWriter, info Info) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(info)
+}
+
+// Server serves the daemon socket and owns the PID file while the daemon runs
+type Server struct {
+	socket  string
+	pidFile string
+	server  *http.Server
+}
+
+// Start refuses to run next to a daemon that still answers on socket. Otherwise it replaces a
+// stale socket and PID file left by a daemon that died, writes this process's PID and serves
+// handler on the socket.
+func Start(socket, pidFile string, handler http.Handler) (*Server, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if info, err := NewClient(socket).Info(ctx); err == nil {
+		return nil, fmt.Errorf("daemon already running with pid %d (run %s)", info.PID, info.RunID)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(socket), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	if err := os.Remove(socket); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", socket, err)
+	}
+	if err := WritePIDFile(pidFile, os.Getpid()); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	s := &Server{
+		socket:  socket,
+		pidFile: pidFile,
+		server:  &http.Server{Handler: handler, ReadHeaderTimeout: 5 * time.Second},
+	}
+	go s.server.Serve(listener)
+	return s, nil
+}
+
+// Close stops serving and removes the socket and PID file
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := s.server.Shutdown(ctx)
+	if removeErr := os.Remove(s.socket); removeErr != nil && !os.IsNotExist(removeErr) && err == nil {
+		err = removeErr
+	}
+	if removeErr := os.Remove(s.pidFile); removeErr != nil && !os.IsNotExist(removeErr) && err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// WritePIDFile writes pid to path, creating its directory
+func WritePIDFile(path string, pid int) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create PID file directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(strconv.Itoa(pid)+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write PID file: %w", err)
+	}
+	return nil
+}
+
+// ReadPIDFile reads the PID written by WritePIDFile
+func ReadPIDFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid PID file %s: %w", path, err)
+	}
+	return pid, nil
+}
+
+// Client talks to a running daemon over its socket
+type Client struct {
+	socket string
+	http   *http.Client
+}
+
+// NewClient creates a client for the daemon listening on socket
+func NewClient(socket string) *Client {
+	return &Client{
+		socket: socket,
+		http: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var dialer net.Dialer
+					return dialer.DialContext(ctx, "unix", socket)
+				},
+			},
+		},
+	}
+}
+
+// Info returns what the daemon reports about itself, or ErrNotRunning
+func (c *Client) Info(ctx context.Context) (Info, error) {
+	return c.call(ctx, http.MethodGet, "/daemon")
+}
+
+// Stop asks the daemon to shut down and returns the Info it had; WaitStopped waits for the shutdown
+func (c *Client) Stop(ctx context.Context) (Info, error) {
+	return c.call(ctx, http.MethodPost, "/stop")
+}
+
+// WaitStopped polls until the daemon no longer answers or ctx ends
+func (c *Client) WaitStopped(ctx context.Context) error {
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if _, err := c.Info(ctx); stderrors.Is(err, ErrNotRunning) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("daemon did not stop: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// call sends one request and decodes the Info it answers with
+func (c *Client) call(ctx context.Context, method, path string) (Info, error) {
+	request, err := http.NewRequestWithContext(ctx, method, "http://daemon"+path, nil)
+	if err != nil {
+		return Info{}, err
+	}
+	response, err := c.http.Do(request)
+	if err != nil {
+		var opErr *net.OpError
+		if stderrors.As(err, &opErr) && opErr.Op == "dial" {
+			return Info{}, ErrNotRunning
+		}
+		return Info{}, fmt.Errorf("failed to reach daemon at %s: %w", c.socket, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return Info{}, fmt.Errorf("daemon returned %s", response.Status)
+	}
+	var info Info
+	if err := json.NewDecoder(response.Body).Decode(&info); err != nil {
+		return Info{}, fmt.Errorf("failed to parse daemon response: %w", err)
+	}
+	return info, nil
+}
@@ -0,0 +1,110 @@
+package daemon
+
+import (
+	"context"
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/browser"
+	"linkedin-automation-framework/internal/control"
+	"linkedin-automation-framework/internal/errors"
+)
+
+// TestServerLifecycle tests that a daemon writes its PID file, answers and stops over the socket,
+// and cleans up after itself
+func TestServerLifecycle(t *testing.T) {
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "daemon.sock")
+	pidFile := filepath.Join(dir, "daemon.pid")
+	ctx := context.Background()
+
+	client := NewClient(socket)
+	if _, err := client.Info(ctx); !stderrors.Is(err, ErrNotRunning) {
+		t.Fatalf("expected ErrNotRunning before start, got %v", err)
+	}
+
+	started := time.Date(2024, 6, 12, 9, 0, 0, 0, time.UTC)
+	controller := control.NewController()
+	stopped := make(chan struct{})
+	failure := NewFailure("founders", errors.NewError(errors.ErrorTypeRateLimit, "run_search", "search limit reached", nil), started.Add(time.Hour))
+	memory := &browser.MemoryStats{HeapBytes: 64 << 20, Pages: 1, BrowserAge: 3 * time.Hour, SampledAt: started.Add(3 * time.Hour)}
+	info := func() Info {
+		return Info{PID: os.Getpid(), RunID: "2024-06-12-1", StartedAt: started, Control: controller.Status(), Memory: memory, Failure: failure}
+	}
+	server, err := Start(socket, pidFile, Handler(controller, info, func() { close(stopped) }))
+	if err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+
+	if pid, err := ReadPIDFile(pidFile); err != nil || pid != os.Getpid() {
+		t.Errorf("expected PID file with %d, got %d (%v)", os.Getpid(), pid, err)
+	}
+	got, err := client.Info(ctx)
+	if err != nil {
+		t.Fatalf("failed to get info: %v", err)
+	}
+	if got.RunID != "2024-06-12-1" || !got.StartedAt.Equal(started) || got.Control.State != control.StateRunning {
+		t.Errorf("unexpected info %+v", got)
+	}
+	if got.Memory == nil || got.Memory.HeapBytes != memory.HeapBytes || got.Memory.BrowserAge != memory.BrowserAge {
+		t.Errorf("expected the memory sample, got %+v", got.Memory)
+	}
+	if got.Failure == nil || got.Failure.Search != "founders" || got.Failure.Code != errors.CodeRateLimit {
+		t.Errorf("expected the last failure with its code, got %+v", got.Failure)
+	}
+
+	// A second daemon refuses to start while the first answers
+	if _, err := Start(socket, pidFile, Handler(controller, info, func() {})); err == nil {
+		t.Errorf("expected a second daemon to be refused")
+	}
+
+	if _, err := client.Stop(ctx); err != nil {
+		t.Fatalf("failed to stop: %v", err)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatalf("stop was not passed to the daemon")
+	}
+
+	if err := server.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := client.WaitStopped(waitCtx); err != nil {
+		t.Errorf("expected the daemon to be stopped, got %v", err)
+	}
+	for _, path := range []string{socket, pidFile} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, got %v", filepath.Base(path), err)
+		}
+	}
+}
+
+// TestStartReplacesStaleFiles tests that a socket and PID file left by a dead daemon do not block a new one
+func TestStartReplacesStaleFiles(t *testing.T) {
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "daemon.sock")
+	pidFile := filepath.Join(dir, "daemon.pid")
+	if err := os.WriteFile(socket, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WritePIDFile(pidFile, 999999); err != nil {
+		t.Fatal(err)
+	}
+
+	info := func() Info { return Info{PID: os.Getpid()} }
+	server, err := Start(socket, pidFile, Handler(control.NewController(), info, func() {}))
+	if err != nil {
+		t.Fatalf("expected stale files to be replaced, got %v", err)
+	}
+	defer server.Close()
+
+	if pid, _ := ReadPIDFile(pidFile); pid != os.Getpid() {
+		t.Errorf("expected the PID file to hold %d, got %d", os.Getpid(), pid)
+	}
+}
@@ -0,0 +1,207 @@
+// Package deadletters reviews the campaign leads their durable queue gave up on once they failed
+// as often as it allows. It lists them with their error fingerprints and the screenshots of their
+// last failure, corrects the attributes they run with, and requeues or discards them in bulk.
+package deadletters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"linkedin-automation-framework/internal/annotations"
+	"linkedin-automation-framework/internal/campaign"
+	"linkedin-automation-framework/internal/gallery"
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/storage"
+)
+
+var (
+	// ErrNotFound is returned for a dead letter ID that does not exist
+	ErrNotFound = errors.New("no such dead letter")
+	// ErrNoSelection is returned for a bulk action given nothing to select dead letters by
+	ErrNoSelection = errors.New("no dead letters selected: give IDs, a campaign, a fingerprint or all")
+)
+
+// Store keeps dead letters
+type Store interface {
+	GetDeadLetters() ([]storage.DeadLetter, error)
+	UpdateDeadLetterPayload(id int64, payload string) (bool, error)
+	RequeueDeadLetters(ids []int64, now time.Time) (int, error)
+	DeleteDeadLetters(ids []int64) (int, error)
+}
+
+// Letter is a dead-lettered campaign lead
+type Letter struct {
+	storage.DeadLetter
+	Campaign    string         // Campaign whose queue the lead was in, "" for another queue
+	Lead        campaign.Lead  // The lead as it was queued
+	Screenshots []gallery.Shot // The lead's failure screenshots in the run of its last attempt
+}
+
+// Filter selects dead letters by any of IDs, campaign and fingerprint. An empty filter selects
+// every dead letter for a list, and none for a bulk action unless All is set.
+type Filter struct {
+	IDs         []int64
+	Campaign    string
+	Fingerprint string
+	All         bool
+}
+
+// empty reports whether the filter names nothing to select by
+func (f Filter) empty() bool {
+	return len(f.IDs) == 0 && f.Campaign == "" && f.Fingerprint == ""
+}
+
+// matches reports whether the filter selects letter
+func (f Filter) matches(letter storage.DeadLetter) bool {
+	if len(f.IDs) > 0 && !slices.Contains(f.IDs, letter.ID) {
+		return false
+	}
+	if f.Campaign != "" && letter.Queue != campaign.QueueName(f.Campaign) {
+		return false
+	}
+	return f.Fingerprint == "" || letter.Fingerprint == f.Fingerprint
+}
+
+// List returns the dead letters filter selects, oldest first, with the screenshots of their last
+// failure from the galleries in screenshotsDir; "" leaves the screenshots out
+func List(store Store, filter Filter, screenshotsDir string) ([]Letter, error) {
+	stored, err := store.GetDeadLetters()
+	if err != nil {
+		return nil, err
+	}
+	var letters []Letter
+	shots := make(map[string][]gallery.Shot)
+	for _, dead := range stored {
+		if !filter.matches(dead) {
+			continue
+		}
+		letter, err := newLetter(dead)
+		if err != nil {
+			return nil, err
+		}
+		if screenshotsDir != "" && dead.RunID != "" {
+			if _, read := shots[dead.RunID]; !read {
+				// A run whose gallery was pruned or never kept has no screenshots
+				if shots[dead.RunID], err = gallery.Shots(screenshotsDir, dead.RunID); err != nil && !errors.Is(err, gallery.ErrNotFound) {
+					return nil, err
+				}
+			}
+			letter.Screenshots = failures(shots[dead.RunID], letter.Lead.ProfileURL)
+		}
+		letters = append(letters, letter)
+	}
+	return letters, nil
+}
+
+// SetAttributes sets attributes of a dead-lettered lead, which it runs with once requeued. Keys
+// follow the lead annotation rules; an empty value removes the attribute.
+func SetAttributes(store Store, id int64, attributes map[string]string) (Letter, error) {
+	for key := range attributes {
+		if err := annotations.CheckKey(key); err != nil {
+			return Letter{}, err
+		}
+	}
+	letters, err := List(store, Filter{IDs: []int64{id}}, "")
+	if err != nil {
+		return Letter{}, err
+	}
+	if len(letters) == 0 {
+		return Letter{}, fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	letter := letters[0]
+	if letter.Lead.Attributes == nil {
+		letter.Lead.Attributes = make(map[string]string, len(attributes))
+	}
+	for key, value := range attributes {
+		if value = strings.TrimSpace(value); value == "" {
+			delete(letter.Lead.Attributes, key)
+		} else {
+			letter.Lead.Attributes[key] = value
+		}
+	}
+	payload, err := json.Marshal(letter.Lead)
+	if err != nil {
+		return Letter{}, fmt.Errorf("failed to encode dead letter %d: %w", id, err)
+	}
+	updated, err := store.UpdateDeadLetterPayload(id, string(payload))
+	if err != nil {
+		return Letter{}, err
+	}
+	if !updated {
+		return Letter{}, fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	letter.Payload = string(payload)
+	return letter, nil
+}
+
+// Requeue moves the dead letters filter selects back into their queues, with no attempts made,
+// for the campaign's next run to take first, and returns how many it moved
+func Requeue(store Store, filter Filter, now time.Time) (int, error) {
+	ids, err := selected(store, filter)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	return store.RequeueDeadLetters(ids, now)
+}
+
+// Discard deletes the dead letters filter selects and returns how many it deleted
+func Discard(store Store, filter Filter) (int, error) {
+	ids, err := selected(store, filter)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	return store.DeleteDeadLetters(ids)
+}
+
+// selected returns the IDs of the dead letters a bulk action's filter selects
+func selected(store Store, filter Filter) ([]int64, error) {
+	if filter.empty() && !filter.All {
+		return nil, ErrNoSelection
+	}
+	letters, err := store.GetDeadLetters()
+	if err != nil {
+		return nil, err
+	}
+	var ids []int64
+	for _, letter := range letters {
+		if filter.matches(letter) {
+			ids = append(ids, letter.ID)
+		}
+	}
+	return ids, nil
+}
+
+// newLetter decodes a stored dead letter's lead
+func newLetter(dead storage.DeadLetter) (Letter, error) {
+	letter := Letter{DeadLetter: dead}
+	if name, ok := strings.CutPrefix(dead.Queue, campaign.QueuePrefix); ok {
+		letter.Campaign = name
+	}
+	if err := json.Unmarshal([]byte(dead.Payload), &letter.Lead); err != nil {
+		return Letter{}, fmt.Errorf("failed to decode dead letter %d: %w", dead.ID, err)
+	}
+	if letter.Lead.ProfileURL == "" {
+		letter.Lead.ProfileURL = dead.Key
+	}
+	return letter, nil
+}
+
+// failures picks the failure screenshots of the lead at profileURL, whose labels read
+// "<step> <profile URL>: <error>"
+func failures(shots []gallery.Shot, profileURL string) []gallery.Shot {
+	var picked []gallery.Shot
+	for _, shot := range shots {
+		if shot.Reason != gallery.ReasonFailure {
+			continue
+		}
+		fields := strings.Fields(shot.Label)
+		if len(fields) >= 2 && identity.ProfileKey(strings.TrimSuffix(fields[1], ":")) == identity.ProfileKey(profileURL) {
+			picked = append(picked, shot)
+		}
+	}
+	return picked
+}
@@ -0,0 +1,105 @@
+package deadletters
+
+import (
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/annotations"
+	"linkedin-automation-framework/internal/campaign"
+	"linkedin-automation-framework/internal/gallery"
+	"linkedin-automation-framework/internal/jobqueue"
+	"linkedin-automation-framework/internal/storage"
+)
+
+const (
+	janeURL = "https://www.linkedin.com/in/jane-doe/"
+	johnURL = "https://www.linkedin.com/in/john-roe/"
+)
+
+// deadLetter runs a lead through its campaign's queue until it is dead-lettered with cause
+func deadLetter(t *testing.T, store *storage.StorageManager, campaignName string, lead campaign.Lead, cause error, now time.Time) {
+	t.Helper()
+	queue := jobqueue.New(store, campaign.QueueName(campaignName), jobqueue.Options{MaxAttempts: 1})
+	if _, err := queue.Enqueue([]jobqueue.Item{{Key: lead.ProfileURL, Payload: lead}}, now); err != nil {
+		t.Fatalf("failed to enqueue: %v", err)
+	}
+	lease, ok, err := queue.Lease(lead.ProfileURL, now)
+	if err != nil || !ok {
+		t.Fatalf("failed to lease: %v, %v", ok, err)
+	}
+	if dead, err := lease.Fail(cause, now); err != nil || !dead {
+		t.Fatalf("expected the lead dead-lettered, got %v (%v)", dead, err)
+	}
+}
+
+// TestReview tests that dead letters are listed with their failure screenshots, edited, and
+// requeued or discarded by campaign and fingerprint
+func TestReview(t *testing.T) {
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "sqlite", Path: t.TempDir(), Database: "test.db"})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+	store.SetRunID("run-1")
+
+	screenshots := t.TempDir()
+	recorder, err := gallery.NewRecorder(screenshots, "run-1", 0)
+	if err != nil {
+		t.Fatalf("failed to create gallery: %v", err)
+	}
+	camera := func() ([]byte, string, error) { return []byte("png"), janeURL, nil }
+	for _, label := range []string{"invite " + janeURL + ": connect button not found", "invite " + johnURL + ": connect button not found"} {
+		if err := recorder.Capture(gallery.ReasonFailure, label, camera); err != nil {
+			t.Fatalf("failed to take screenshot: %v", err)
+		}
+	}
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	notFound := stderrors.New("connect button not found")
+	deadLetter(t, store, "q1", campaign.Lead{ProfileURL: janeURL, Name: "Jane Doe", Attributes: map[string]string{"first_name": "Jnae"}}, notFound, now)
+	deadLetter(t, store, "q2", campaign.Lead{ProfileURL: johnURL, Name: "John Roe"}, stderrors.New("plugin exited with status 1"), now)
+
+	letters, err := List(store, Filter{Campaign: "q1"}, screenshots)
+	if err != nil || len(letters) != 1 {
+		t.Fatalf("expected jane's dead letter, got %+v (%v)", letters, err)
+	}
+	jane := letters[0]
+	if jane.Campaign != "q1" || jane.Lead.Name != "Jane Doe" || jane.Fingerprint == "" || jane.Error != notFound.Error() {
+		t.Errorf("unexpected dead letter %+v", jane)
+	}
+	if len(jane.Screenshots) != 1 || jane.Screenshots[0].File != "0001.png" {
+		t.Errorf("expected jane's failure screenshot only, got %+v", jane.Screenshots)
+	}
+
+	edited, err := SetAttributes(store, jane.ID, map[string]string{"first_name": "Jane", "topic": "Go"})
+	if err != nil || edited.Lead.Attributes["first_name"] != "Jane" || edited.Lead.Attributes["topic"] != "Go" {
+		t.Fatalf("expected the corrected attributes, got %+v (%v)", edited.Lead, err)
+	}
+	if _, err := SetAttributes(store, jane.ID, map[string]string{"First Name": "Jane"}); !stderrors.Is(err, annotations.ErrInvalid) {
+		t.Errorf("expected an invalid key to be refused, got %v", err)
+	}
+	if _, err := SetAttributes(store, 999, map[string]string{"topic": "Go"}); !stderrors.Is(err, ErrNotFound) {
+		t.Errorf("expected an unknown dead letter to be refused, got %v", err)
+	}
+
+	if _, err := Requeue(store, Filter{}, now); !stderrors.Is(err, ErrNoSelection) {
+		t.Errorf("expected a bulk action without a selection to be refused, got %v", err)
+	}
+	requeued, err := Requeue(store, Filter{Fingerprint: jane.Fingerprint}, now.Add(time.Hour))
+	if err != nil || requeued != 1 {
+		t.Fatalf("expected jane requeued, got %d (%v)", requeued, err)
+	}
+	pending, err := jobqueue.New(store, campaign.QueueName("q1"), jobqueue.Options{}).Pending(now.Add(time.Hour))
+	if err != nil || len(pending) != 1 || pending[0].Payload != edited.Payload {
+		t.Errorf("expected jane back in the queue with her corrected attributes, got %+v (%v)", pending, err)
+	}
+
+	discarded, err := Discard(store, Filter{All: true})
+	if err != nil || discarded != 1 {
+		t.Fatalf("expected john discarded, got %d (%v)", discarded, err)
+	}
+	if letters, err := List(store, Filter{}, ""); err != nil || len(letters) != 0 {
+		t.Errorf("expected no dead letters left, got %+v (%v)", letters, err)
+	}
+}
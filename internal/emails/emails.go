@@ -0,0 +1,170 @@
+// Package emails guesses the work email addresses of leads from their name and their company's
+// website domain, for follow-up outside LinkedIn. Nothing is sent: a domain is only checked for
+// mail exchangers in DNS.
+package emails
+
+import (
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Pattern builds the local part of an address from a normalized first and last name
+type Pattern struct {
+	Name  string  // e.g. "first.last"
+	Share float64 // Share of company domains using it, the confidence of a guess with nothing else known
+	build func(first, last string) string
+}
+
+// Patterns are the address patterns guessed, most common first
+var Patterns = []Pattern{
+	{"first.last", 0.35, func(f, l string) string { return join(f, ".", l) }},
+	{"first", 0.15, func(f, l string) string { return f }},
+	{"flast", 0.12, func(f, l string) string { return join(initial(f), "", l) }},
+	{"firstlast", 0.08, func(f, l string) string { return join(f, "", l) }},
+	{"first_last", 0.05, func(f, l string) string { return join(f, "_", l) }},
+	{"f.last", 0.05, func(f, l string) string { return join(initial(f), ".", l) }},
+	{"last.first", 0.03, func(f, l string) string { return join(l, ".", f) }},
+	{"firstl", 0.03, func(f, l string) string { return join(f, "", initial(l)) }},
+	{"first-last", 0.02, func(f, l string) string { return join(f, "-", l) }},
+	{"lastf", 0.02, func(f, l string) string { return join(l, "", initial(f)) }},
+	{"last", 0.02, func(f, l string) string { return l }},
+}
+
+// Known is an address already known for someone at a domain, e.g. from a connections export
+type Known struct {
+	First string
+	Last  string
+	Email string
+}
+
+// Guess is a candidate address with how likely it is to be right, from 0 to 1
+type Guess struct {
+	Email      string
+	Pattern    string
+	Confidence float64
+}
+
+// transliterations spell letters outside a-z the way addresses usually do
+var transliterations = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ą': "a", 'æ': "ae",
+	'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e", 'ğ': "g",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'ı': "i", 'ł': "l", 'ľ': "l",
+	'ñ': "n", 'ń': "n", 'ň': "n", 'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ő': "o", 'œ': "oe",
+	'ř': "r", 'ś': "s", 'š': "s", 'ş': "s", 'ß': "ss", 'ť': "t", 'ţ': "t", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u", 'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+// nonCompanyHosts are sites company pages list instead of their own domain
+var nonCompanyHosts = []string{"linkedin.com", "facebook.com", "instagram.com", "twitter.com", "x.com", "linktr.ee", "bit.ly", "wixsite.com", "github.io"}
+
+// Candidates returns the guesses for a person at domain, most likely first. Addresses known for
+// others at the domain show its pattern: each pattern's confidence is its share smoothed with the
+// fraction of known addresses following it, so one known address outweighs the shares and several
+// agreeing ones make a guess near certain. It returns nothing for names that cannot be spelled in
+// an address, such as names in non-Latin scripts.
+func Candidates(first, last, domain string, known []Known) []Guess {
+	first, last = LocalName(first), LocalName(last)
+	if first == "" || domain == "" {
+		return nil
+	}
+
+	matches := make(map[string]int)
+	samples := 0
+	for _, address := range known {
+		local, addressDomain, ok := strings.Cut(strings.ToLower(address.Email), "@")
+		knownFirst, knownLast := LocalName(address.First), LocalName(address.Last)
+		if !ok || addressDomain != domain || knownFirst == "" || knownLast == "" {
+			continue
+		}
+		samples++
+		for _, pattern := range Patterns {
+			if pattern.build(knownFirst, knownLast) == local {
+				matches[pattern.Name]++
+			}
+		}
+	}
+
+	var guesses []Guess
+	seen := make(map[string]bool)
+	for _, pattern := range Patterns {
+		local := pattern.build(first, last)
+		if local == "" || seen[local] {
+			continue
+		}
+		seen[local] = true
+		guesses = append(guesses, Guess{
+			Email:      local + "@" + domain,
+			Pattern:    pattern.Name,
+			Confidence: (float64(matches[pattern.Name]) + pattern.Share) / float64(samples+1),
+		})
+	}
+	sort.SliceStable(guesses, func(i, j int) bool {
+		return guesses[i].Confidence > guesses[j].Confidence
+	})
+	return guesses
+}
+
+// LocalName spells a name part the way it appears in addresses: lowercase a-z and digits, with
+// accents dropped and hyphens, apostrophes and spaces removed. It returns "" for names with
+// letters that have no such spelling.
+func LocalName(name string) string {
+	var spelled strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			spelled.WriteRune(r)
+		case transliterations[r] != "":
+			spelled.WriteString(transliterations[r])
+		case r == '-', r == '\'', r == '’', r == '.', unicode.IsSpace(r), unicode.Is(unicode.Mn, r):
+		default:
+			return ""
+		}
+	}
+	return spelled.String()
+}
+
+// Domain returns the mail domain of a company website, or "" when the website is not the
+// company's own domain
+func Domain(website string) string {
+	website = strings.TrimSpace(website)
+	if website == "" {
+		return ""
+	}
+	if !strings.Contains(website, "://") {
+		website = "https://" + website
+	}
+	parsed, err := url.Parse(website)
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	if !strings.Contains(host, ".") || net.ParseIP(host) != nil {
+		return ""
+	}
+	for _, other := range nonCompanyHosts {
+		if host == other || strings.HasSuffix(host, "."+other) {
+			return ""
+		}
+	}
+	return host
+}
+
+// join joins two name parts, or returns "" when either is missing
+func join(a, separator, b string) string {
+	if a == "" || b == "" {
+		return ""
+	}
+	return a + separator + b
+}
+
+// initial returns the first letter of a name part
+func initial(name string) string {
+	if name == "" {
+		return ""
+	}
+	return name[:1]
+}
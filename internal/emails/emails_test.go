@@ -0,0 +1,148 @@
+package emails
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestLocalName(t *testing.T) {
+	for name, want := range map[string]string{
+		"Jane":         "jane",
+		"O'Brien":      "obrien",
+		"Smith-Jones":  "smithjones",
+		"José":         "jose",
+		"José":        "jose",
+		"Müller":       "muller",
+		"Łukasz":       "lukasz",
+		"Straße":       "strasse",
+		"van der Berg": "vanderberg",
+		"王":            "",
+		"Александр":    "",
+		"  ":           "",
+	} {
+		if got := LocalName(name); got != want {
+			t.Errorf("LocalName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestDomain(t *testing.T) {
+	for website, want := range map[string]string{
+		"https://www.acme.example/en/":           "acme.example",
+		"acme.example":                           "acme.example",
+		"http://Shop.Acme.Example:8080":          "shop.acme.example",
+		"https://www.linkedin.com/company/acme/": "",
+		"https://linktr.ee/acme":                 "",
+		"http://192.0.2.1":                       "",
+		"localhost":                              "",
+		"":                                       "",
+	} {
+		if got := Domain(website); got != want {
+			t.Errorf("Domain(%q) = %q, want %q", website, got, want)
+		}
+	}
+}
+
+// TestCandidates tests ranking by pattern share, and by the patterns known addresses follow
+func TestCandidates(t *testing.T) {
+	guesses := Candidates("José", "García-López", "acme.example", nil)
+	if len(guesses) != len(Patterns) {
+		t.Fatalf("expected one guess per pattern, got %+v", guesses)
+	}
+	if guesses[0].Email != "jose.garcialopez@acme.example" || guesses[0].Confidence != 0.35 {
+		t.Errorf("expected first.last first with its share, got %+v", guesses[0])
+	}
+
+	known := []Known{
+		{First: "Ann", Last: "Lee", Email: "alee@acme.example"},
+		{First: "Bob", Last: "Stone", Email: "BStone@acme.example"},
+		{First: "Cy", Last: "Young", Email: "cy.young@other.example"},
+	}
+	guesses = Candidates("Jane", "Doe", "acme.example", known)
+	if guesses[0].Email != "jdoe@acme.example" || guesses[0].Pattern != "flast" {
+		t.Fatalf("expected the domain's flast pattern first, got %+v", guesses[0])
+	}
+	if want := (2 + 0.12) / 3; math.Abs(guesses[0].Confidence-want) > 1e-9 {
+		t.Errorf("expected confidence %v, got %v", want, guesses[0].Confidence)
+	}
+	if guesses[1].Pattern != "first.last" || guesses[1].Confidence >= 0.35 {
+		t.Errorf("expected other patterns to lose confidence, got %+v", guesses[1])
+	}
+
+	if guesses := Candidates("Cher", "", "acme.example", nil); len(guesses) != 1 || guesses[0].Email != "cher@acme.example" {
+		t.Errorf("expected only the first-name pattern without a last name, got %+v", guesses)
+	}
+	if guesses := Candidates("王", "伟", "acme.example", nil); guesses != nil {
+		t.Errorf("expected no guesses for a name without a Latin spelling, got %+v", guesses)
+	}
+}
+
+// fakeResolver answers MX lookups from a map; missing domains do not exist
+type fakeResolver struct {
+	records map[string][]*net.MX
+	lookups int
+}
+
+func (r *fakeResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	r.lookups++
+	if name == "broken.example" {
+		return nil, errors.New("server misbehaving")
+	}
+	records, ok := r.records[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+// TestGuessLeads tests that only domains with mail exchangers get guesses and known addresses
+// are kept as they are
+func TestGuessLeads(t *testing.T) {
+	resolver := &fakeResolver{records: map[string][]*net.MX{
+		"acme.example":   {{Host: "mx1.acme.example.", Pref: 10}},
+		"nomail.example": {{Host: ".", Pref: 0}},
+	}}
+	verifier := NewVerifier(resolver)
+	leads := []Lead{
+		{ProfileURL: "https://www.linkedin.com/in/jane-doe/", FirstName: "Jane", LastName: "Doe", Company: "Acme", Domain: "acme.example"},
+		{ProfileURL: "https://www.linkedin.com/in/john-roe/", FirstName: "John", LastName: "Roe", Company: "Acme", Domain: "acme.example"},
+		{ProfileURL: "https://www.linkedin.com/in/known/", FirstName: "Kim", LastName: "Nown", KnownEmail: "kim@acme.example"},
+		{FirstName: "Ned", LastName: "Null", Domain: "nomail.example"},
+		{FirstName: "Gus", LastName: "Gone", Domain: "gone.example"},
+		{FirstName: "Walt", LastName: "Webless"},
+		{FirstName: "伟", LastName: "王", Domain: "acme.example"},
+	}
+
+	rows, report, err := GuessLeads(context.Background(), verifier, leads, nil, 2)
+	if err != nil {
+		t.Fatalf("guessing failed: %v", err)
+	}
+	if report != (Report{Guessed: 2, Known: 1, NoDomain: 1, NoMail: 2, Unusable: 1}) {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if len(rows) != 5 || rows[0].Email != "jane.doe@acme.example" || rows[4].Pattern != "known" {
+		t.Errorf("expected two guesses each for Jane and John and Kim's known address, got %+v", rows)
+	}
+	if resolver.lookups != 3 {
+		t.Errorf("expected each domain looked up once, got %d lookups", resolver.lookups)
+	}
+
+	if _, _, err := GuessLeads(context.Background(), verifier, []Lead{{FirstName: "Bo", Domain: "broken.example"}}, nil, 1); err == nil {
+		t.Error("expected a DNS failure to be reported rather than taken as no mail")
+	}
+
+	var out bytes.Buffer
+	if err := WriteCSV(&out, rows[:1]); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	want := "profile_url,first_name,last_name,company,domain,email,pattern,confidence\n" +
+		"https://www.linkedin.com/in/jane-doe/,Jane,Doe,Acme,acme.example,jane.doe@acme.example,first.last,0.35\n"
+	if out.String() != want {
+		t.Errorf("unexpected CSV:\n%s", strings.TrimSpace(out.String()))
+	}
+}
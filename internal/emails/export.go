@@ -0,0 +1,96 @@
+package emails
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Lead is a person to guess addresses for
+type Lead struct {
+	ProfileURL string
+	FirstName  string
+	LastName   string
+	Company    string
+	Domain     string // Mail domain of the company, see Domain
+	KnownEmail string // Address already known, e.g. from a connections export; it is exported as is
+}
+
+// Row is one guessed or known address of a lead
+type Row struct {
+	Lead
+	Guess
+}
+
+// Report counts what GuessLeads did with the leads
+type Report struct {
+	Guessed  int // Leads given guesses
+	Known    int // Leads exported with their known address
+	NoDomain int // Leads whose company has no website domain
+	NoMail   int // Leads whose company domain accepts no mail
+	Unusable int // Leads whose name cannot be spelled in an address
+}
+
+// GuessLeads returns up to perLead guesses for each lead, most likely first. Leads with a known
+// address get only that one, with confidence 1. Guesses are only made for domains that accept
+// mail; known addresses at the same domain rank the pattern they follow first.
+func GuessLeads(ctx context.Context, verifier *Verifier, leads []Lead, known []Known, perLead int) ([]Row, Report, error) {
+	var rows []Row
+	var report Report
+	for _, lead := range leads {
+		if email := strings.TrimSpace(lead.KnownEmail); email != "" {
+			rows = append(rows, Row{Lead: lead, Guess: Guess{Email: email, Pattern: "known", Confidence: 1}})
+			report.Known++
+			continue
+		}
+		if lead.Domain == "" {
+			report.NoDomain++
+			continue
+		}
+
+		accepts, err := verifier.AcceptsMail(ctx, lead.Domain)
+		if err != nil {
+			return rows, report, err
+		}
+		if !accepts {
+			report.NoMail++
+			continue
+		}
+
+		guesses := Candidates(lead.FirstName, lead.LastName, lead.Domain, known)
+		if len(guesses) == 0 {
+			report.Unusable++
+			continue
+		}
+		if perLead > 0 && len(guesses) > perLead {
+			guesses = guesses[:perLead]
+		}
+		for _, guess := range guesses {
+			rows = append(rows, Row{Lead: lead, Guess: guess})
+		}
+		report.Guessed++
+	}
+	return rows, report, nil
+}
+
+// WriteCSV writes rows with a header, one address per line
+func WriteCSV(w io.Writer, rows []Row) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"profile_url", "first_name", "last_name", "company", "domain", "email", "pattern", "confidence"}); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, row := range rows {
+		record := []string{
+			row.ProfileURL, row.FirstName, row.LastName, row.Company, row.Domain,
+			row.Email, row.Pattern, strconv.FormatFloat(row.Confidence, 'f', 2, 64),
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,53 @@
+package emails
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Resolver looks up mail exchangers, e.g. net.DefaultResolver
+type Resolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+}
+
+// Verifier checks whether domains accept mail, remembering each answer
+type Verifier struct {
+	resolver Resolver
+	checked  map[string]bool
+}
+
+// NewVerifier creates a verifier; a nil resolver uses the system's
+func NewVerifier(resolver Resolver) *Verifier {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &Verifier{resolver: resolver, checked: make(map[string]bool)}
+}
+
+// AcceptsMail reports whether domain publishes a mail exchanger. A domain that does not exist, has
+// no MX records or publishes the "no mail" null MX does not. No connection is made to the mail
+// servers, so nothing tells whether a particular address exists.
+func (v *Verifier) AcceptsMail(ctx context.Context, domain string) (bool, error) {
+	if accepts, ok := v.checked[domain]; ok {
+		return accepts, nil
+	}
+
+	records, err := v.resolver.LookupMX(ctx, domain)
+	var dnsErr *net.DNSError
+	if err != nil && !(errors.As(err, &dnsErr) && dnsErr.IsNotFound) {
+		return false, fmt.Errorf("failed to look up mail exchangers of %s: %w", domain, err)
+	}
+
+	accepts := false
+	for _, record := range records {
+		if strings.TrimSuffix(record.Host, ".") != "" {
+			accepts = true
+			break
+		}
+	}
+	v.checked[domain] = accepts
+	return accepts, nil
+}
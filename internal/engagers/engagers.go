@@ -0,0 +1,187 @@
+// Package engagers harvests the people who reacted to or commented on a LinkedIn post, the
+// account's own or a competitor's, and feeds them into the lead pool as warm leads tagged with
+// the post's topic.
+package engagers
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"linkedin-automation-framework/internal/annotations"
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/search"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// Lead attributes a harvested engager is tagged with, as shown by "leads show"
+const (
+	AttributePostTopic = "post_topic"
+	AttributePostURL   = "post_url"
+)
+
+// Signals a harvested engager carries in its signal attribute
+const (
+	SignalCommented = "commented_on_post"
+	SignalReacted   = "reacted_to_post"
+)
+
+// Source is who queued the engagers and set their attributes
+const Source = "post-engagers"
+
+// OutcomeAuthor is why the post's author is not fed as a lead
+const OutcomeAuthor = "post_author"
+
+// Store keeps the harvested leads, their attributes and the queue campaigns take leads from
+type Store interface {
+	annotations.Store
+	SaveSearchResults(results []storage.ProfileResult) error
+	QueueLeads(leads []storage.QueuedLead) (int, error)
+}
+
+// Engager is someone who reacted to or commented on a post
+type Engager struct {
+	ProfileURL string
+	Name       string
+	Headline   string
+	Reacted    bool
+	Commented  bool
+}
+
+// Post is a post and the people who engaged with it, commenters first
+type Post struct {
+	URL       string
+	AuthorURL string // Profile of the post's author, when shown
+	Text      string
+	Engagers  []Engager
+}
+
+// Options controls how Feed adds a post's engagers
+type Options struct {
+	Topic string // The post's topic; Topic(post.Text) if empty
+	Queue bool   // Queue the engagers for the next run of any campaign
+	// Exclude returns why an engager must not be fed, e.g. "already_connected", or "" to go ahead
+	Exclude func(engager Engager) string
+}
+
+// Report counts what Feed did with the post's engagers
+type Report struct {
+	Topic  string
+	Fed    []string       // Profile URLs saved as leads
+	Queued int            // Of those, newly queued
+	Passed map[string]int // Engagers not fed, by reason
+}
+
+// Feed saves the post's engagers as leads, tagged with the post's topic and URL and marked with
+// the commented_on_post or reacted_to_post signal so campaigns take them first. The post's
+// author and the engagers options.Exclude turns down are passed over.
+func Feed(store Store, post Post, options Options, now time.Time) (Report, error) {
+	report := Report{Topic: options.Topic, Passed: make(map[string]int)}
+	if report.Topic == "" {
+		report.Topic = Topic(post.Text)
+	}
+	author := identity.ProfileKey(post.AuthorURL)
+
+	var results []storage.ProfileResult
+	var queue []storage.QueuedLead
+	for _, engager := range post.Engagers {
+		if post.AuthorURL != "" && identity.ProfileKey(engager.ProfileURL) == author {
+			report.Passed[OutcomeAuthor]++
+			continue
+		}
+		if options.Exclude != nil {
+			if reason := options.Exclude(engager); reason != "" {
+				report.Passed[reason]++
+				continue
+			}
+		}
+
+		attributes := map[string]string{AttributePostURL: post.URL}
+		if report.Topic != "" {
+			attributes[AttributePostTopic] = report.Topic
+		}
+		if err := annotations.SetAttributes(store, engager.ProfileURL, attributes, Source, now); err != nil {
+			return report, err
+		}
+		for _, signal := range engager.signals() {
+			if err := annotations.AddSignal(store, engager.ProfileURL, signal, Source, now); err != nil {
+				return report, err
+			}
+		}
+		results = append(results, storage.ProfileResult{
+			URL:       engager.ProfileURL,
+			Name:      engager.Name,
+			Title:     engager.Headline,
+			Company:   search.CompanyFromHeadline(engager.Headline),
+			Timestamp: now,
+		})
+		if options.Queue {
+			queue = append(queue, storage.QueuedLead{ProfileURL: engager.ProfileURL, Source: Source, QueuedAt: now})
+		}
+		report.Fed = append(report.Fed, engager.ProfileURL)
+	}
+
+	if len(results) > 0 {
+		if err := store.SaveSearchResults(results); err != nil {
+			return report, fmt.Errorf("failed to save engagers: %w", err)
+		}
+	}
+	if len(queue) > 0 {
+		queued, err := store.QueueLeads(queue)
+		if err != nil {
+			return report, fmt.Errorf("failed to queue engagers: %w", err)
+		}
+		report.Queued = queued
+	}
+	return report, nil
+}
+
+// signals returns the signals the engager's engagement gives
+func (e Engager) signals() []string {
+	var signals []string
+	if e.Reacted {
+		signals = append(signals, SignalReacted)
+	}
+	if e.Commented {
+		signals = append(signals, SignalCommented)
+	}
+	return signals
+}
+
+// hashtagPattern finds a post's hashtags
+var hashtagPattern = regexp.MustCompile(`#(\pL[\pL\pN_]*)`)
+
+// topicWords is how many words of a post without hashtags its topic takes
+const topicWords = 8
+
+// Topic returns the topic of a post's text: its first three hashtags, or else its first words
+func Topic(text string) string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, match := range hashtagPattern.FindAllStringSubmatch(text, -1) {
+		if tag := strings.ToLower(match[1]); !seen[tag] && len(tags) < 3 {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > 0 {
+		return strings.Join(tags, ", ")
+	}
+	words := strings.Fields(identity.StripFormatting(text))
+	if len(words) > topicWords {
+		return strings.Join(words[:topicWords], " ") + "…"
+	}
+	return strings.Join(words, " ")
+}
+
+// CheckPostURL refuses what is not a link to a LinkedIn post
+func CheckPostURL(postURL string) error {
+	parsed, err := url.Parse(strings.TrimSpace(postURL))
+	if err != nil || !strings.HasSuffix(parsed.Hostname(), "linkedin.com") ||
+		!(strings.HasPrefix(parsed.Path, "/posts/") || strings.HasPrefix(parsed.Path, "/feed/update/")) {
+		return fmt.Errorf("not a LinkedIn post URL: %q (expected https://www.linkedin.com/posts/... or /feed/update/...)", postURL)
+	}
+	return nil
+}
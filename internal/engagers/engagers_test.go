@@ -0,0 +1,174 @@
+package engagers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/annotations"
+	"linkedin-automation-framework/internal/browser/browsertest"
+	"linkedin-automation-framework/internal/selectors"
+	"linkedin-automation-framework/internal/storage"
+)
+
+const postURL = "https://www.linkedin.com/posts/acme_hiring-activity-7100000000000000000-abcd/"
+
+// card builds a comment or reaction card
+func card(selector, headlineSelector, href, name, headline string) *browsertest.Element {
+	return browsertest.NewElement(selector).Append(
+		browsertest.NewElement("a[href*='/in/']").SetAttribute("href", href).SetText(name),
+		browsertest.NewElement(headlineSelector).SetText(headline),
+	)
+}
+
+// TestScraper tests that commenters and reactors are read across their "load more" pages, that
+// someone who did both is listed once and that the post's author is read
+func TestScraper(t *testing.T) {
+	set := selectors.Desktop
+	comment := func(href, name, headline string) *browsertest.Element {
+		return card(set.CommentCards[0], set.CommentHeadline[0], href, name, headline)
+	}
+	reactor := func(href, name, headline string) *browsertest.Element {
+		return card(set.ReactorCards[0], set.ReactorHeadline[0], href, name, headline)
+	}
+
+	page := browsertest.NewPage("https://www.linkedin.com/feed/")
+	page.Route(postURL, func(page *browsertest.Page) {
+		page.Append(
+			browsertest.NewElement(set.PostAuthor[0]).SetAttribute("href", "https://www.linkedin.com/in/acme-ceo/").SetText("Acme CEO"),
+			browsertest.NewElement(set.PostText[0]).SetText("We are hiring platform engineers #Hiring #PlatformEngineering"),
+			comment("https://www.linkedin.com/in/jane-doe/", "Jane Doe", "CTO at Initech"),
+		)
+		more := browsertest.NewElement(set.MoreComments[0])
+		more.OnClick(func() {
+			page.Append(comment("/in/john-roe?trk=x", "John Roe", "Engineer at Globex"))
+			more.Remove()
+		})
+		page.Append(more)
+
+		reactions := browsertest.NewElement(set.ReactionsCount[0]).SetText("42")
+		reactions.OnClick(func() {
+			page.Append(
+				reactor("https://www.linkedin.com/in/jane-doe", "Jane Doe", "CTO at Initech"),
+				reactor("https://www.linkedin.com/in/ann-lee/", "Ann Lee", "VP Engineering at Hooli"),
+			)
+			// The list never ends, so only the load limit stops it
+			moreReactors := browsertest.NewElement(set.MoreReactors[0])
+			moreReactors.OnClick(func() {
+				page.Append(reactor("https://www.linkedin.com/in/ann-lee/", "Ann Lee", "VP Engineering at Hooli"))
+			})
+			page.Append(moreReactors)
+		})
+		page.Append(reactions)
+	})
+
+	post, err := NewScraper(page, set).Harvest(context.Background(), postURL, 3)
+	if err != nil {
+		t.Fatalf("failed to harvest the post: %v", err)
+	}
+	if post.AuthorURL != "https://www.linkedin.com/in/acme-ceo/" || Topic(post.Text) != "hiring, platformengineering" {
+		t.Errorf("unexpected post %+v", post)
+	}
+	if len(post.Engagers) != 3 {
+		t.Fatalf("expected jane, john and ann, got %+v", post.Engagers)
+	}
+	jane, john, ann := post.Engagers[0], post.Engagers[1], post.Engagers[2]
+	if jane.ProfileURL != "https://www.linkedin.com/in/jane-doe/" || !jane.Commented || !jane.Reacted || jane.Headline != "CTO at Initech" {
+		t.Errorf("expected jane to have commented and reacted, got %+v", jane)
+	}
+	if john.ProfileURL != "https://www.linkedin.com/in/john-roe/" || !john.Commented || john.Reacted {
+		t.Errorf("expected john to have commented, got %+v", john)
+	}
+	if ann.Name != "Ann Lee" || ann.Commented || !ann.Reacted {
+		t.Errorf("expected ann to have reacted, got %+v", ann)
+	}
+}
+
+// TestFeed tests that engagers are saved, tagged with the topic and their signals and queued,
+// and that the author and excluded engagers are passed over
+func TestFeed(t *testing.T) {
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "sqlite", Path: t.TempDir(), Database: "test.db"})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	post := Post{
+		URL:       postURL,
+		AuthorURL: "https://www.linkedin.com/in/acme-ceo/",
+		Text:      "We are hiring platform engineers #Hiring",
+		Engagers: []Engager{
+			{ProfileURL: "https://www.linkedin.com/in/jane-doe/", Name: "Jane Doe", Headline: "CTO at Initech", Commented: true, Reacted: true},
+			{ProfileURL: "https://www.linkedin.com/in/acme-ceo", Name: "Acme CEO", Commented: true},
+			{ProfileURL: "https://www.linkedin.com/in/bob-ray/", Name: "Bob Ray", Reacted: true},
+		},
+	}
+	options := Options{
+		Queue: true,
+		Exclude: func(engager Engager) string {
+			if engager.Name == "Bob Ray" {
+				return "already_connected"
+			}
+			return ""
+		},
+	}
+
+	report, err := Feed(store, post, options, now)
+	if err != nil {
+		t.Fatalf("failed to feed engagers: %v", err)
+	}
+	if report.Topic != "hiring" || len(report.Fed) != 1 || report.Queued != 1 || report.Passed[OutcomeAuthor] != 1 || report.Passed["already_connected"] != 1 {
+		t.Fatalf("expected only jane fed, got %+v", report)
+	}
+	jane, err := annotations.For(store, "https://www.linkedin.com/in/jane-doe/")
+	if err != nil {
+		t.Fatalf("failed to load jane's attributes: %v", err)
+	}
+	if jane.Attributes[AttributePostTopic] != "hiring" || jane.Attributes[AttributePostURL] != postURL || jane.Attributes["signal"] != "reacted_to_post,commented_on_post" {
+		t.Errorf("unexpected attributes %+v", jane.Attributes)
+	}
+	results, err := store.GetSearchResults()
+	if err != nil || len(results) != 1 || results[0].Company != "Initech" {
+		t.Errorf("expected jane saved as a lead, got %+v (%v)", results, err)
+	}
+
+	// Harvesting the post again tags jane the same and does not queue her twice
+	options.Topic = "Platform hiring"
+	if report, err = Feed(store, post, options, now); err != nil || report.Queued != 0 {
+		t.Fatalf("expected nothing newly queued, got %+v (%v)", report, err)
+	}
+	jane, _ = annotations.For(store, "https://www.linkedin.com/in/jane-doe/")
+	if jane.Attributes[AttributePostTopic] != "Platform hiring" || jane.Attributes["signal"] != "reacted_to_post,commented_on_post" {
+		t.Errorf("unexpected attributes after the second harvest %+v", jane.Attributes)
+	}
+}
+
+// TestTopic tests that a post's topic is its hashtags, or else its first words
+func TestTopic(t *testing.T) {
+	cases := map[string]string{
+		"Big news! #AI #MachineLearning #ai #Startups #Funding":          "ai, machinelearning, startups",
+		"Five lessons from scaling our platform team to fifty engineers": "Five lessons from scaling our platform team to…",
+		"Short post": "Short post",
+		"":           "",
+	}
+	for text, expected := range cases {
+		if topic := Topic(text); topic != expected {
+			t.Errorf("Topic(%q) = %q, expected %q", text, topic, expected)
+		}
+	}
+}
+
+// TestCheckPostURL tests that only links to posts are taken
+func TestCheckPostURL(t *testing.T) {
+	for _, postURL := range []string{postURL, "https://www.linkedin.com/feed/update/urn:li:activity:7100000000000000000/"} {
+		if err := CheckPostURL(postURL); err != nil {
+			t.Errorf("expected %s to be taken: %v", postURL, err)
+		}
+	}
+	for _, postURL := range []string{"https://www.linkedin.com/in/jane-doe/", "https://example.com/posts/x", "not a url"} {
+		if err := CheckPostURL(postURL); err == nil {
+			t.Errorf("expected %s to be refused", postURL)
+		}
+	}
+}
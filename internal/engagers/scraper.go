@@ -0,0 +1,162 @@
+package engagers
+
+import (
+	"context"
+	"fmt"
+
+	"linkedin-automation-framework/internal/browser"
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/search"
+	"linkedin-automation-framework/internal/selectors"
+)
+
+// DefaultMaxLoads is how many times Harvest loads more comments, and more reactions, when given
+// no limit
+const DefaultMaxLoads = 5
+
+// Scraper reads a post's page, its comments and the list of its reactions
+type Scraper struct {
+	page      browser.PageDriver
+	selectors selectors.Set
+}
+
+// NewScraper creates a scraper for post pages, reading a post's author and text and the members
+// who commented on or reacted to it
+func NewScraper(page browser.PageDriver, set selectors.Set) *Scraper {
+	return &Scraper{page: page, selectors: set}
+}
+
+// Harvest opens the post and returns it with the people who commented on and reacted to it,
+// loading more comments and more reactions up to maxLoads times each (DefaultMaxLoads if 0).
+// Someone who did both is listed once, with both flags set.
+func (s *Scraper) Harvest(ctx context.Context, postURL string, maxLoads int) (Post, error) {
+	if maxLoads <= 0 {
+		maxLoads = DefaultMaxLoads
+	}
+	page := s.page.Context(ctx)
+	if err := page.Navigate(postURL); err != nil {
+		return Post{}, fmt.Errorf("failed to open %s: %w", postURL, err)
+	}
+	if err := page.WaitLoad(); err != nil {
+		return Post{}, fmt.Errorf("failed to wait for %s to load: %w", postURL, err)
+	}
+
+	post := Post{URL: postURL}
+	post.Text = search.CleanText(browser.FirstText(page, s.selectors.PostText))
+	for _, selector := range s.selectors.PostAuthor {
+		if found, link, err := page.Has(selector); err == nil && found {
+			if profileURL, _, ok := profileLink(link); ok {
+				post.AuthorURL = profileURL
+				break
+			}
+		}
+	}
+
+	engagers := make(map[string]int) // Index in post.Engagers by profile key
+	add := func(engager Engager) {
+		key := identity.ProfileKey(engager.ProfileURL)
+		if i, found := engagers[key]; found {
+			post.Engagers[i].Reacted = post.Engagers[i].Reacted || engager.Reacted
+			post.Engagers[i].Commented = post.Engagers[i].Commented || engager.Commented
+			if post.Engagers[i].Headline == "" {
+				post.Engagers[i].Headline = engager.Headline
+			}
+			return
+		}
+		engagers[key] = len(post.Engagers)
+		post.Engagers = append(post.Engagers, engager)
+	}
+
+	if err := s.loadMore(ctx, page, s.selectors.MoreComments, maxLoads); err != nil {
+		return post, err
+	}
+	for _, card := range s.cards(page, s.selectors.CommentCards) {
+		if engager, ok := s.engager(card, s.selectors.CommentHeadline); ok {
+			engager.Commented = true
+			add(engager)
+		}
+	}
+
+	// The reactions list opens in a dialog over the post; a post without reactions has no count
+	if count, ok := browser.FirstElement(page, s.selectors.ReactionsCount); ok {
+		if err := count.Click(); err != nil {
+			return post, fmt.Errorf("failed to open the reactions of %s: %w", postURL, err)
+		}
+		if err := s.loadMore(ctx, page, s.selectors.MoreReactors, maxLoads); err != nil {
+			return post, err
+		}
+		for _, card := range s.cards(page, s.selectors.ReactorCards) {
+			if engager, ok := s.engager(card, s.selectors.ReactorHeadline); ok {
+				engager.Reacted = true
+				add(engager)
+			}
+		}
+	}
+	return post, nil
+}
+
+// loadMore clicks the first button matching a candidate selector until it is gone or has been
+// clicked maxLoads times
+func (s *Scraper) loadMore(ctx context.Context, page browser.PageDriver, candidates []string, maxLoads int) error {
+	for loads := 0; loads < maxLoads; loads++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		button, ok := browser.FirstElement(page, candidates)
+		if !ok {
+			return nil
+		}
+		if visible, err := button.Visible(); err != nil || !visible {
+			return nil
+		}
+		if err := button.Click(); err != nil {
+			return fmt.Errorf("failed to load more: %w", err)
+		}
+		if err := page.WaitLoad(); err != nil {
+			return fmt.Errorf("failed to wait for more to load: %w", err)
+		}
+	}
+	return nil
+}
+
+// engager reads one comment or reaction card
+func (s *Scraper) engager(card browser.ElementDriver, headline []string) (Engager, bool) {
+	for _, selector := range s.selectors.ProfileLinks {
+		link, err := card.Element(selector)
+		if err != nil {
+			continue
+		}
+		if profileURL, name, ok := profileLink(link); ok {
+			return Engager{ProfileURL: profileURL, Name: name, Headline: search.CleanText(browser.FirstText(card, headline))}, true
+		}
+	}
+	return Engager{}, false
+}
+
+// profileLink returns the normalized profile URL and text of a link to a member's profile,
+// reporting false for links elsewhere such as a company page
+func profileLink(link browser.ElementDriver) (string, string, bool) {
+	href, err := link.Attribute("href")
+	if err != nil || href == nil {
+		return "", "", false
+	}
+	profileURL := identity.NormalizeProfileURL(*href)
+	if !search.IsValidLinkedInProfileURL(profileURL) {
+		return "", "", false
+	}
+	var name string
+	if text, err := link.Text(); err == nil {
+		name = search.CleanText(text)
+	}
+	return profileURL, name, true
+}
+
+// cards returns the elements matching the first candidate selector that matches any
+func (s *Scraper) cards(page browser.PageDriver, candidates []string) []browser.ElementDriver {
+	for _, selector := range candidates {
+		if found, err := page.Elements(selector); err == nil && len(found) > 0 {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrRetryBudgetExhausted is wrapped by the error of a retry the run's budget no longer allows
+var ErrRetryBudgetExhausted = stderrors.New("retry budget exhausted")
+
+// RetryBudget caps the retries all operations of a run make together, so that one broken
+// selector fails the run instead of retrying a navigation for every lead. It is safe for
+// concurrent use; a nil *RetryBudget allows every retry.
+type RetryBudget struct {
+	max        int
+	used       int
+	operations map[string]*OperationRetries
+	exhausted  func(report BudgetReport)
+	mutex      sync.Mutex
+}
+
+// OperationRetries is how often one operation was retried
+type OperationRetries struct {
+	Operation string `json:"operation"`
+	Retries   int    `json:"retries"`
+	LastError string `json:"last_error"` // Error of the last attempt that was retried or refused
+}
+
+// BudgetReport is what a budget was spent on, the most retried operations first
+type BudgetReport struct {
+	Max        int                `json:"max"`
+	Used       int                `json:"used"`
+	Operations []OperationRetries `json:"operations"`
+}
+
+// String describes the report in one line, e.g. for a log or a failed run's error
+func (r BudgetReport) String() string {
+	parts := make([]string, 0, len(r.Operations))
+	for _, operation := range r.Operations {
+		parts = append(parts, fmt.Sprintf("%s %dx (last: %s)", operation.Operation, operation.Retries, operation.LastError))
+	}
+	return fmt.Sprintf("%d of %d retries used: %s", r.Used, r.Max, strings.Join(parts, "; "))
+}
+
+// NewRetryBudget allows max retries. exhausted, if not nil, is called once, with the report, when
+// the first retry is refused.
+func NewRetryBudget(max int, exhausted func(report BudgetReport)) *RetryBudget {
+	return &RetryBudget{max: max, operations: make(map[string]*OperationRetries), exhausted: exhausted}
+}
+
+// Spend takes one retry of operation after err from the budget, reporting whether it is allowed
+func (b *RetryBudget) Spend(operation string, err error) bool {
+	if b == nil {
+		return true
+	}
+	b.mutex.Lock()
+	entry, ok := b.operations[operation]
+	if !ok {
+		entry = &OperationRetries{Operation: operation}
+		b.operations[operation] = entry
+	}
+	if err != nil {
+		entry.LastError = err.Error()
+	}
+	if b.used < b.max {
+		b.used++
+		entry.Retries++
+		b.mutex.Unlock()
+		return true
+	}
+	first := b.used == b.max
+	b.used++ // Counts refusals past max, so only the first calls exhausted
+	b.mutex.Unlock()
+
+	if first && b.exhausted != nil {
+		b.exhausted(b.Report())
+	}
+	return false
+}
+
+// Exhausted reports whether a retry has been refused
+func (b *RetryBudget) Exhausted() bool {
+	if b == nil {
+		return false
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.used > b.max
+}
+
+// Report returns what the budget was spent on
+func (b *RetryBudget) Report() BudgetReport {
+	if b == nil {
+		return BudgetReport{}
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	report := BudgetReport{Max: b.max, Used: min(b.used, b.max)}
+	for _, entry := range b.operations {
+		report.Operations = append(report.Operations, *entry)
+	}
+	sort.Slice(report.Operations, func(i, j int) bool {
+		if report.Operations[i].Retries != report.Operations[j].Retries {
+			return report.Operations[i].Retries > report.Operations[j].Retries
+		}
+		return report.Operations[i].Operation < report.Operations[j].Operation
+	})
+	return report
+}
+
+// RetryBudgetError is returned for a retry the budget refused. It wraps both
+// ErrRetryBudgetExhausted and the error that would have been retried.
+type RetryBudgetError struct {
+	Operation string
+	Err       error
+	Report    BudgetReport
+}
+
+// Error implements the error interface
+func (e *RetryBudgetError) Error() string {
+	return fmt.Sprintf("%s: %v (not retried, %s: %s)", e.Operation, e.Err, ErrRetryBudgetExhausted, e.Report)
+}
+
+// Unwrap returns ErrRetryBudgetExhausted and the refused retry's error
+func (e *RetryBudgetError) Unwrap() []error {
+	return []error{ErrRetryBudgetExhausted, e.Err}
+}
+
+// retryBudgetKey carries a run's retry budget in its context
+type retryBudgetKey struct{}
+
+// WithRetryBudget returns a context whose retries RetryWithBackoff takes from budget
+func WithRetryBudget(ctx context.Context, budget *RetryBudget) context.Context {
+	return context.WithValue(ctx, retryBudgetKey{}, budget)
+}
+
+// RetryBudgetFrom returns the retry budget of ctx, nil if it has none
+func RetryBudgetFrom(ctx context.Context) *RetryBudget {
+	budget, _ := ctx.Value(retryBudgetKey{}).(*RetryBudget)
+	return budget
+}
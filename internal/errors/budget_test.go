@@ -0,0 +1,100 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRetryBudget tests that a budget allows its retries, refuses the rest and reports once
+// what they were spent on
+func TestRetryBudget(t *testing.T) {
+	var reports []BudgetReport
+	budget := NewRetryBudget(3, func(report BudgetReport) { reports = append(reports, report) })
+	missing := stderrors.New("connect button not found")
+
+	for i := 0; i < 2; i++ {
+		if !budget.Spend("send_connection_request", missing) {
+			t.Fatalf("retry %d refused within the budget", i+1)
+		}
+	}
+	if !budget.Spend("navigate_profile", stderrors.New("timeout")) {
+		t.Fatal("last retry of the budget refused")
+	}
+	if budget.Exhausted() {
+		t.Fatal("budget exhausted before a retry was refused")
+	}
+	for i := 0; i < 2; i++ {
+		if budget.Spend("send_connection_request", missing) {
+			t.Fatal("retry past the budget allowed")
+		}
+	}
+	if !budget.Exhausted() {
+		t.Error("expected the budget exhausted")
+	}
+	if len(reports) != 1 {
+		t.Fatalf("expected one exhaustion report, got %d", len(reports))
+	}
+
+	report := budget.Report()
+	if report.Max != 3 || report.Used != 3 || len(report.Operations) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	first := report.Operations[0]
+	if first.Operation != "send_connection_request" || first.Retries != 2 || first.LastError != missing.Error() {
+		t.Errorf("expected the most retried operation first, got %+v", first)
+	}
+	if text := report.String(); !strings.Contains(text, "3 of 3 retries used") || !strings.Contains(text, "navigate_profile 1x (last: timeout)") {
+		t.Errorf("unexpected report text: %s", text)
+	}
+
+	var unlimited *RetryBudget
+	if !unlimited.Spend("anything", missing) || unlimited.Exhausted() {
+		t.Error("a nil budget should allow every retry")
+	}
+}
+
+// TestRetryWithBackoffSpendsBudget tests that retries are taken from the context's budget and
+// that a refused retry fails with the budget's error
+func TestRetryWithBackoffSpendsBudget(t *testing.T) {
+	config := DefaultRetryConfig()
+	config.MaxAttempts = 5
+	config.InitialDelay = time.Millisecond
+	config.MaxDelay = time.Millisecond
+
+	budget := NewRetryBudget(2, nil)
+	ctx := WithRetryBudget(context.Background(), budget)
+	if RetryBudgetFrom(ctx) != budget || RetryBudgetFrom(context.Background()) != nil {
+		t.Fatal("expected the budget carried by the context only")
+	}
+
+	attempts := 0
+	transient := NewError(ErrorTypeTransient, "click_connect_button", "element not found", nil)
+	err := RetryWithBackoff(ctx, config, func(ctx context.Context, attempt int) error {
+		attempts++
+		return transient
+	})
+	if attempts != 3 {
+		t.Errorf("expected the first attempt and 2 budgeted retries, got %d attempts", attempts)
+	}
+	var budgetErr *RetryBudgetError
+	if !stderrors.As(err, &budgetErr) || !stderrors.Is(err, ErrRetryBudgetExhausted) || !stderrors.Is(err, transient) {
+		t.Fatalf("expected a retry budget error wrapping the last error, got %v", err)
+	}
+	if budgetErr.Operation != "click_connect_button" {
+		t.Errorf("expected the operation taken from the error, got %q", budgetErr.Operation)
+	}
+
+	// Once exhausted, operations fail on their first error
+	attempts = 0
+	config.Operation = "navigate_profile"
+	err = RetryWithBackoff(ctx, config, func(ctx context.Context, attempt int) error {
+		attempts++
+		return transient
+	})
+	if attempts != 1 || !stderrors.As(err, &budgetErr) || budgetErr.Operation != "navigate_profile" {
+		t.Errorf("expected one attempt under the configured operation, got %d attempts and %v", attempts, err)
+	}
+}
@@ -0,0 +1,114 @@
+package errors
+
+import (
+	stderrors "errors"
+
+	"github.com/go-rod/rod"
+)
+
+// Code is a stable, machine-readable name for how an operation failed, so that whatever runs
+// the tool can branch on the kind of failure instead of parsing messages. Codes never change
+// once released.
+type Code string
+
+const (
+	CodeSelectorMiss   Code = "E_SELECTOR_MISS"   // An element the operation needs is not on the page
+	CodeRateLimit      Code = "E_RATE_LIMIT"      // LinkedIn or a configured limit refused more actions for now
+	CodeChallenge      Code = "E_CHALLENGE"       // LinkedIn asked for a captcha, verification code or checkpoint
+	CodeSessionExpired Code = "E_SESSION_EXPIRED" // The session is logged out and could not be restored
+)
+
+// Exit statuses of a process whose run ended with an error of each code. Any other error exits
+// with ExitFailure.
+const (
+	ExitFailure        = 1
+	ExitSelectorMiss   = 10
+	ExitRateLimit      = 11
+	ExitChallenge      = 12
+	ExitSessionExpired = 13
+)
+
+// Coder is implemented by errors that know their code. An empty code leaves it to the errors
+// they wrap.
+type Coder interface {
+	ErrorCode() Code
+}
+
+// ErrorCode returns the code set with WithCode, or the one the error's type implies:
+// E_RATE_LIMIT for rate limits and E_SESSION_EXPIRED for authentication errors
+func (e *LinkedInError) ErrorCode() Code {
+	if e.Code != "" {
+		return e.Code
+	}
+	switch e.Type {
+	case ErrorTypeRateLimit:
+		return CodeRateLimit
+	case ErrorTypeAuthentication:
+		return CodeSessionExpired
+	}
+	return ""
+}
+
+// WithCode sets the error's code
+func (e *LinkedInError) WithCode(code Code) *LinkedInError {
+	e.Code = code
+	return e
+}
+
+// codedError is a sentinel error with a code, see NewCoded
+type codedError struct {
+	code    Code
+	message string
+}
+
+func (e *codedError) Error() string {
+	return e.message
+}
+
+func (e *codedError) ErrorCode() Code {
+	return e.code
+}
+
+// NewCoded returns an error with message and code, for packages whose sentinel errors are
+// matched with errors.Is
+func NewCoded(code Code, message string) error {
+	return &codedError{code: code, message: message}
+}
+
+// CodeOf returns the code of err: that of the outermost error it wraps that has one, else
+// E_SELECTOR_MISS if it wraps Rod's element not found error. It is empty for nil and for errors
+// no code describes.
+func CodeOf(err error) Code {
+	var code Code
+	walkErrors(err, func(e error) {
+		if coder, ok := e.(Coder); ok && code == "" {
+			code = coder.ErrorCode()
+		}
+	})
+	if code != "" {
+		return code
+	}
+	var notFound *rod.ErrElementNotFound
+	if stderrors.As(err, &notFound) {
+		return CodeSelectorMiss
+	}
+	return ""
+}
+
+// ExitCode returns the process exit status for a run that ended with err, 0 if err is nil
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	switch CodeOf(err) {
+	case CodeSelectorMiss:
+		return ExitSelectorMiss
+	case CodeRateLimit:
+		return ExitRateLimit
+	case CodeChallenge:
+		return ExitChallenge
+	case CodeSessionExpired:
+		return ExitSessionExpired
+	}
+	return ExitFailure
+}
@@ -0,0 +1,42 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-rod/rod"
+)
+
+// TestCodeOf tests the codes of coded, typed, wrapped and uncoded errors, and the exit status
+// each gives a process
+func TestCodeOf(t *testing.T) {
+	inviteLimit := NewCoded(CodeRateLimit, "invitation limit reached")
+	for _, tc := range []struct {
+		name     string
+		err      error
+		expected Code
+		exit     int
+	}{
+		{"nil", nil, "", 0},
+		{"plain", stderrors.New("boom"), "", ExitFailure},
+		{"explicit", NewError(ErrorTypeTransient, "confirm_connection_request", "could not find Send button", nil).WithCode(CodeSelectorMiss), CodeSelectorMiss, ExitSelectorMiss},
+		{"rate limit type", NewError(ErrorTypeRateLimit, "send_connection_request", "rate limit exceeded", nil), CodeRateLimit, ExitRateLimit},
+		{"authentication type", NewError(ErrorTypeAuthentication, "wait_for_login", "not logged in", nil), CodeSessionExpired, ExitSessionExpired},
+		{"coded sentinel", fmt.Errorf("connect: %w", NewError(ErrorTypePermanent, "send_connection_request", "invitation limit reached", inviteLimit)), CodeRateLimit, ExitRateLimit},
+		{"rod element not found", fmt.Errorf("click: %w", &rod.ErrElementNotFound{}), CodeSelectorMiss, ExitSelectorMiss},
+		{"refused retry", &RetryBudgetError{Operation: "navigate_profile", Err: NewError(ErrorTypeAuthentication, "navigate_profile", "redirected to login", nil)}, CodeSessionExpired, ExitSessionExpired},
+		{"outermost wins", NewError(ErrorTypeTransient, "login", "login stopped", NewError(ErrorTypeRateLimit, "login", "too many attempts", nil)).WithCode(CodeChallenge), CodeChallenge, ExitChallenge},
+	} {
+		if code := CodeOf(tc.err); code != tc.expected {
+			t.Errorf("%s: expected code %q, got %q", tc.name, tc.expected, code)
+		}
+		if exit := ExitCode(tc.err); exit != tc.exit {
+			t.Errorf("%s: expected exit status %d, got %d", tc.name, tc.exit, exit)
+		}
+	}
+
+	if !stderrors.Is(fmt.Errorf("wrapped: %w", inviteLimit), inviteLimit) {
+		t.Error("expected a coded sentinel to match with errors.Is")
+	}
+}
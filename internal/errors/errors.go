@@ -2,8 +2,10 @@ package errors
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"math"
+	"math/rand"
 	"time"
 
 	"github.com/go-rod/rod"
@@ -25,6 +27,7 @@ const (
 // LinkedInError represents a structured error with context
 type LinkedInError struct {
 	Type      ErrorType
+	Code      Code // Stable code for machine-readable output; see ErrorCode for the default
 	Operation string
 	Message   string
 	Cause     error
@@ -68,6 +71,16 @@ func (e *LinkedInError) WithContext(key string, value interface{}) *LinkedInErro
 	return e
 }
 
+// Jitter is how a backoff delay is randomized, so that workers failing together do not all
+// retry at the same moment
+type Jitter int
+
+const (
+	JitterNone  Jitter = iota // Wait the computed delay exactly
+	JitterFull                // Wait anywhere between none and the computed delay
+	JitterEqual               // Wait at least half the computed delay, and up to all of it
+)
+
 // RetryConfig defines retry behavior configuration
 type RetryConfig struct {
 	MaxAttempts     int
@@ -75,6 +88,9 @@ type RetryConfig struct {
 	MaxDelay        time.Duration
 	BackoffFactor   float64
 	RetryableErrors []ErrorType
+	Jitter          Jitter
+	Random          func() float64 // Source of jitter in [0, 1); nil uses math/rand
+	Operation       string         // Names the retries in the run's retry budget; defaults to the LinkedInError's operation
 }
 
 // DefaultRetryConfig returns a sensible default retry configuration
@@ -84,6 +100,7 @@ func DefaultRetryConfig() RetryConfig {
 		InitialDelay:  1 * time.Second,
 		MaxDelay:      30 * time.Second,
 		BackoffFactor: 2.0,
+		Jitter:        JitterEqual,
 		RetryableErrors: []ErrorType{
 			ErrorTypeTransient,
 			ErrorTypeTimeout,
@@ -95,7 +112,11 @@ func DefaultRetryConfig() RetryConfig {
 // RetryableOperation represents an operation that can be retried
 type RetryableOperation func(ctx context.Context, attempt int) error
 
-// RetryWithBackoff executes an operation with exponential backoff retry logic
+// RetryWithBackoff executes an operation with exponential backoff retry logic. A retry whose
+// delay would end after ctx's deadline is not waited for; the last error is returned at once,
+// wrapped with context.DeadlineExceeded, so both stay detectable. Likewise ctx ending during a
+// delay returns the last error wrapped with ctx's error. Each retry is taken from the retry
+// budget of ctx, if it has one, and a *RetryBudgetError is returned once the budget refuses one.
 func RetryWithBackoff(ctx context.Context, config RetryConfig, operation RetryableOperation) error {
 	var lastErr error
 
@@ -118,14 +139,24 @@ func RetryWithBackoff(ctx context.Context, config RetryConfig, operation Retryab
 			break
 		}
 
+		operation := retryOperation(err, config)
+		if budget := RetryBudgetFrom(ctx); !budget.Spend(operation, err) {
+			return &RetryBudgetError{Operation: operation, Err: err, Report: budget.Report()}
+		}
+
 		// Calculate delay with exponential backoff
-		delay := calculateBackoffDelay(attempt, config)
+		delay := jitterDelay(calculateBackoffDelay(attempt, config), config)
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return fmt.Errorf("%w: giving up before the deadline: %w", lastErr, context.DeadlineExceeded)
+		}
 
 		// Check if context is cancelled
+		timer := time.NewTimer(delay)
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(delay):
+			timer.Stop()
+			return fmt.Errorf("%w: giving up: %w", lastErr, ctx.Err())
+		case <-timer.C:
 			// Continue to next attempt
 		}
 	}
@@ -133,6 +164,18 @@ func RetryWithBackoff(ctx context.Context, config RetryConfig, operation Retryab
 	return lastErr
 }
 
+// retryOperation names the operation a retry is for
+func retryOperation(err error, config RetryConfig) string {
+	if config.Operation != "" {
+		return config.Operation
+	}
+	var linkedInErr *LinkedInError
+	if stderrors.As(err, &linkedInErr) && linkedInErr.Operation != "" {
+		return linkedInErr.Operation
+	}
+	return "unnamed"
+}
+
 // shouldRetry determines if an error should be retried
 func shouldRetry(err error, retryableTypes []ErrorType) bool {
 	linkedInErr, ok := err.(*LinkedInError)
@@ -162,16 +205,78 @@ func calculateBackoffDelay(attempt int, config RetryConfig) time.Duration {
 	return time.Duration(delay)
 }
 
+// jitterDelay randomizes a backoff delay as config.Jitter asks
+func jitterDelay(delay time.Duration, config RetryConfig) time.Duration {
+	random := config.Random
+	if random == nil {
+		random = rand.Float64
+	}
+	switch config.Jitter {
+	case JitterFull:
+		return time.Duration(random() * float64(delay))
+	case JitterEqual:
+		return delay/2 + time.Duration(random()*float64(delay-delay/2))
+	default:
+		return delay
+	}
+}
+
+// Timeouts bound each kind of Rod operation
+type Timeouts struct {
+	Navigation time.Duration // Navigating to a page and waiting for it to load
+	Element    time.Duration // Waiting for an element the page should have
+	Dialog     time.Duration // Waiting for a modal or prompt that may not appear at all
+	Login      time.Duration // Waiting for the fields and buttons of the login form
+	Settle     time.Duration // Waiting for a loaded page to stop changing
+}
+
+// DefaultTimeouts are the timeouts used unless configured otherwise
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		Navigation: 30 * time.Second,
+		Element:    5 * time.Second,
+		Dialog:     5 * time.Second,
+		Login:      10 * time.Second,
+		Settle:     10 * time.Second,
+	}
+}
+
 // RodErrorHandler provides Rod-specific error handling utilities
 type RodErrorHandler struct {
-	defaultTimeout time.Duration
+	defaultTimeout time.Duration // Bounds an operation given no context
+	timeouts       Timeouts
 }
 
-// NewRodErrorHandler creates a new Rod error handler
+// NewRodErrorHandler creates a new Rod error handler bounding every operation by defaultTimeout
 func NewRodErrorHandler(defaultTimeout time.Duration) *RodErrorHandler {
 	return &RodErrorHandler{
 		defaultTimeout: defaultTimeout,
+		timeouts:       Timeouts{Navigation: defaultTimeout, Element: defaultTimeout, Dialog: defaultTimeout, Login: defaultTimeout, Settle: defaultTimeout},
+	}
+}
+
+// SetTimeouts bounds each kind of operation separately. Zero timeouts keep their current value.
+func (reh *RodErrorHandler) SetTimeouts(timeouts Timeouts) {
+	if timeouts.Navigation > 0 {
+		reh.timeouts.Navigation = timeouts.Navigation
+	}
+	if timeouts.Element > 0 {
+		reh.timeouts.Element = timeouts.Element
+	}
+	if timeouts.Dialog > 0 {
+		reh.timeouts.Dialog = timeouts.Dialog
+	}
+	if timeouts.Login > 0 {
+		reh.timeouts.Login = timeouts.Login
 	}
+	if timeouts.Settle > 0 {
+		reh.timeouts.Settle = timeouts.Settle
+	}
+}
+
+// Timeouts returns the timeouts each kind of operation is bounded by
+func (reh *RodErrorHandler) Timeouts() Timeouts {
+	return reh.timeouts
 }
 
 // SafeElementOperation performs a Rod element operation with proper error handling
@@ -192,29 +297,29 @@ func (reh *RodErrorHandler) SafeElementOperation(ctx context.Context, page *rod.
 	}
 
 	// Find the element with timeout
-	element, err := page.Timeout(reh.defaultTimeout).Element(selector)
+	element, err := page.Timeout(reh.timeouts.Element).Element(selector)
 	if err != nil {
 		return NewError(ErrorTypeTransient, "SafeElementOperation", 
-			fmt.Sprintf("failed to find element with selector: %s", selector), err)
+			fmt.Sprintf("failed to find element with selector: %s", selector), err).WithContext(ContextSelector, selector).WithCode(CodeSelectorMiss)
 	}
 
 	// Check if element is visible
 	visible, err := element.Visible()
 	if err != nil {
 		return NewError(ErrorTypeTransient, "SafeElementOperation", 
-			"failed to check element visibility", err)
+			"failed to check element visibility", err).WithContext(ContextSelector, selector)
 	}
 
 	if !visible {
 		return NewError(ErrorTypeTransient, "SafeElementOperation", 
-			fmt.Sprintf("element not visible: %s", selector), nil)
+			fmt.Sprintf("element not visible: %s", selector), nil).WithContext(ContextSelector, selector).WithCode(CodeSelectorMiss)
 	}
 
 	// Perform the operation
 	err = operation(element)
 	if err != nil {
 		return NewError(ErrorTypeTransient, "SafeElementOperation", 
-			"element operation failed", err)
+			"element operation failed", err).WithContext(ContextSelector, selector)
 	}
 
 	return nil
@@ -238,14 +343,14 @@ func (reh *RodErrorHandler) SafeNavigation(ctx context.Context, page *rod.Page,
 	}
 
 	// Navigate with timeout
-	err := page.Timeout(reh.defaultTimeout).Navigate(url)
+	err := page.Timeout(reh.timeouts.Navigation).Navigate(url)
 	if err != nil {
 		return NewError(ErrorTypeNetwork, "SafeNavigation", 
 			fmt.Sprintf("failed to navigate to URL: %s", url), err)
 	}
 
 	// Wait for page load with timeout
-	err = page.Timeout(reh.defaultTimeout).WaitLoad()
+	err = page.Timeout(reh.timeouts.Navigation).WaitLoad()
 	if err != nil {
 		return NewError(ErrorTypeTimeout, "SafeNavigation", 
 			"page load timeout", err)
@@ -275,7 +380,7 @@ func (reh *RodErrorHandler) HandleRodError(operation string, err error) error {
 
 	// Element not found (usually retryable)
 	if containsAny(errorMessage, []string{"element not found", "no such element", "cannot find"}) {
-		return NewError(ErrorTypeTransient, operation, "element not found", err)
+		return NewError(ErrorTypeTransient, operation, "element not found", err).WithCode(CodeSelectorMiss)
 	}
 
 	// Browser/page errors (usually retryable)
@@ -2,6 +2,7 @@ package errors
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"testing"
 	"time"
@@ -365,9 +366,9 @@ func TestRetryContextCancellation(t *testing.T) {
 		return transientErr // Always fail to trigger retries
 	})
 	
-	// Should return context error when cancelled
-	if err != context.DeadlineExceeded {
-		t.Errorf("Expected context.DeadlineExceeded, got: %v", err)
+	// Should return context error when cancelled, alongside the last failure
+	if !stderrors.Is(err, context.DeadlineExceeded) || !stderrors.Is(err, transientErr) {
+		t.Errorf("Expected context.DeadlineExceeded wrapping the transient error, got: %v", err)
 	}
 	
 	// Should have attempted at least once but not all attempts
@@ -381,6 +382,71 @@ func TestRetryContextCancellation(t *testing.T) {
 	}
 }
 
+// TestBackoffJitter tests that jittered delays stay within their bounds and spread out
+func TestBackoffJitter(t *testing.T) {
+	delay := 800 * time.Millisecond
+	testCases := []struct {
+		jitter   Jitter
+		random   float64
+		expected time.Duration
+	}{
+		{JitterNone, 0.5, 800 * time.Millisecond},
+		{JitterFull, 0, 0},
+		{JitterFull, 0.25, 200 * time.Millisecond},
+		{JitterEqual, 0, 400 * time.Millisecond},
+		{JitterEqual, 0.5, 600 * time.Millisecond},
+	}
+	for _, tc := range testCases {
+		config := RetryConfig{Jitter: tc.jitter, Random: func() float64 { return tc.random }}
+		if actual := jitterDelay(delay, config); actual != tc.expected {
+			t.Errorf("jitter %d with random %v: expected %v, got %v", tc.jitter, tc.random, tc.expected, actual)
+		}
+	}
+
+	// Without a source, delays are drawn at random within the bounds
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 50; i++ {
+		actual := jitterDelay(delay, RetryConfig{Jitter: JitterEqual})
+		if actual < delay/2 || actual > delay {
+			t.Fatalf("equal jitter delay %v outside %v-%v", actual, delay/2, delay)
+		}
+		seen[actual] = true
+	}
+	if len(seen) < 2 {
+		t.Error("expected jittered delays to differ")
+	}
+}
+
+// TestRetryHonorsDeadline tests that a retry that would end after the deadline is not waited for
+func TestRetryHonorsDeadline(t *testing.T) {
+	config := DefaultRetryConfig()
+	config.InitialDelay = 10 * time.Second
+	config.MaxDelay = 10 * time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	attempts := 0
+	start := time.Now()
+	err := RetryWithBackoff(ctx, config, func(ctx context.Context, attempt int) error {
+		attempts++
+		return NewError(ErrorTypeTransient, "test", "transient error", nil)
+	})
+	if !stderrors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got: %v", err)
+	}
+	var linkedInErr *LinkedInError
+	if !stderrors.As(err, &linkedInErr) || linkedInErr.Type != ErrorTypeTransient {
+		t.Errorf("expected the last error to stay classifiable, got: %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("expected a single attempt, got %d", attempts)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected to give up at once, waited %v", elapsed)
+	}
+}
+
 // **Feature: linkedin-automation-framework, Property 44: Rod timeout and context usage**
 // **Validates: Requirements 8.5**
 func TestRodTimeoutUsage(t *testing.T) {
@@ -576,4 +642,14 @@ func TestTimeoutContextCreation(t *testing.T) {
 	if linkedInErr.Type != ErrorTypeConfiguration {
 		t.Fatalf("Expected configuration error, got %v", linkedInErr.Type)
 	}
-}
\ No newline at end of file
+}
+// TestRodErrorHandlerTimeouts tests that configured timeouts replace the default per kind of operation
+func TestRodErrorHandlerTimeouts(t *testing.T) {
+	handler := NewRodErrorHandler(30 * time.Second)
+	handler.SetTimeouts(Timeouts{Element: 2 * time.Second, Login: 15 * time.Second})
+
+	want := Timeouts{Navigation: 30 * time.Second, Element: 2 * time.Second, Dialog: 30 * time.Second, Login: 15 * time.Second, Settle: 30 * time.Second}
+	if got := handler.Timeouts(); got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
@@ -0,0 +1,139 @@
+package errors
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-rod/rod"
+)
+
+// ContextSelector is the LinkedInError context key of the selector an operation failed on
+const ContextSelector = "selector"
+
+// Error classes given to the Rod and context errors a fingerprint recognizes. Other errors are
+// classed by their normalized message.
+const (
+	ClassElementNotFound = "element_not_found"
+	ClassNotInteractable = "not_interactable"
+	ClassNavigation      = "navigation"
+	ClassEval            = "eval"
+	ClassTimeout         = "timeout"
+	ClassCanceled        = "canceled"
+)
+
+// Fingerprint identifies errors that failed the same way: in the same operation, on the same
+// selector and with the same class of error. Messages differ from one profile to the next,
+// fingerprints do not, so a broken selector shows up as one fingerprint failing again and again.
+type Fingerprint struct {
+	ID        string `json:"fingerprint"`
+	Operation string `json:"operation"`
+	Selector  string `json:"selector,omitempty"`
+	Class     string `json:"class"`
+}
+
+// String describes the fingerprint, e.g. "click_connect_button on button.connect: not_interactable"
+func (f Fingerprint) String() string {
+	if f.Selector == "" {
+		return fmt.Sprintf("%s: %s", f.Operation, f.Class)
+	}
+	return fmt.Sprintf("%s on %s: %s", f.Operation, f.Selector, f.Class)
+}
+
+// FingerprintOf fingerprints err. The operation and selector are those of the innermost
+// LinkedInError that names them, the most specific step that failed; an error without an
+// operation is "unnamed".
+func FingerprintOf(err error) Fingerprint {
+	fingerprint := Fingerprint{Operation: "unnamed"}
+	var root error
+	walkErrors(err, func(e error) {
+		if linkedInErr, ok := e.(*LinkedInError); ok {
+			if linkedInErr.Operation != "" {
+				fingerprint.Operation = linkedInErr.Operation
+			}
+			if selector, ok := linkedInErr.Context[ContextSelector].(string); ok && selector != "" {
+				fingerprint.Selector = selector
+			}
+		}
+		root = e
+	})
+
+	fingerprint.Class = errorClass(err)
+	if fingerprint.Class == "" && root != nil {
+		message := root.Error()
+		if linkedInErr, ok := root.(*LinkedInError); ok {
+			message = linkedInErr.Message
+		}
+		fingerprint.Class = normalizeMessage(message)
+	}
+
+	sum := sha256.Sum256([]byte(fingerprint.Operation + "\x00" + fingerprint.Selector + "\x00" + fingerprint.Class))
+	fingerprint.ID = hex.EncodeToString(sum[:6])
+	return fingerprint
+}
+
+// walkErrors calls visit for err and everything it wraps, depth first, so the last error
+// visited is the innermost cause
+func walkErrors(err error, visit func(error)) {
+	if err == nil {
+		return
+	}
+	visit(err)
+	switch wrapper := err.(type) {
+	case interface{ Unwrap() error }:
+		walkErrors(wrapper.Unwrap(), visit)
+	case interface{ Unwrap() []error }:
+		for _, wrapped := range wrapper.Unwrap() {
+			walkErrors(wrapped, visit)
+		}
+	}
+}
+
+// errorClass classes the Rod and context errors err wraps, empty if it wraps none of them
+func errorClass(err error) string {
+	var notFound *rod.ErrElementNotFound
+	var notInteractable *rod.ErrNotInteractable
+	switch {
+	case stderrors.As(err, &notFound):
+		return ClassElementNotFound
+	case stderrors.As(err, &notInteractable), stderrors.Is(err, &rod.ErrInvisibleShape{}),
+		stderrors.Is(err, &rod.ErrCovered{}), stderrors.Is(err, &rod.ErrNoPointerEvents{}):
+		return ClassNotInteractable
+	case stderrors.Is(err, &rod.ErrNavigation{}):
+		return ClassNavigation
+	case stderrors.Is(err, &rod.ErrEval{}):
+		return ClassEval
+	case stderrors.Is(err, context.DeadlineExceeded):
+		return ClassTimeout
+	case stderrors.Is(err, context.Canceled):
+		return ClassCanceled
+	}
+	return ""
+}
+
+var (
+	urlPattern    = regexp.MustCompile(`https?://\S+`)
+	quotedPattern = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+	numberPattern = regexp.MustCompile(`\d+`)
+)
+
+// maxClassLength caps a class taken from an error message
+const maxClassLength = 80
+
+// normalizeMessage strips what varies between errors of one kind from their message: URLs,
+// quoted values such as names, and numbers
+func normalizeMessage(message string) string {
+	message = strings.ToLower(message)
+	message = urlPattern.ReplaceAllString(message, "<url>")
+	message = quotedPattern.ReplaceAllString(message, "<value>")
+	message = numberPattern.ReplaceAllString(message, "<n>")
+	message = strings.Join(strings.Fields(message), " ")
+	if runes := []rune(message); len(runes) > maxClassLength {
+		message = string(runes[:maxClassLength])
+	}
+	return message
+}
@@ -0,0 +1,73 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-rod/rod"
+)
+
+// TestFingerprintOf tests that errors failing the same way share a fingerprint whatever their
+// messages, and that errors failing differently do not
+func TestFingerprintOf(t *testing.T) {
+	selectorMiss := func(profile string) error {
+		err := NewError(ErrorTypeTransient, "send_connection_request", "failed to find the Connect button",
+			NewError(ErrorTypeTransient, "click_connect_button", "element not found", &rod.ErrElementNotFound{}).
+				WithContext(ContextSelector, "button.connect"))
+		return fmt.Errorf("invite to %s: %w", profile, err)
+	}
+
+	first, second := FingerprintOf(selectorMiss("https://www.linkedin.com/in/ada/")), FingerprintOf(selectorMiss("https://www.linkedin.com/in/alan/"))
+	if first != second {
+		t.Fatalf("expected one fingerprint for the same failure, got %+v and %+v", first, second)
+	}
+	expected := Fingerprint{ID: first.ID, Operation: "click_connect_button", Selector: "button.connect", Class: ClassElementNotFound}
+	if first != expected || len(first.ID) != 12 {
+		t.Errorf("expected %+v, got %+v", expected, first)
+	}
+	if text := first.String(); text != "click_connect_button on button.connect: element_not_found" {
+		t.Errorf("unexpected description %q", text)
+	}
+
+	for name, err := range map[string]error{
+		"other operation": NewError(ErrorTypeTransient, "click_send_button", "element not found", &rod.ErrElementNotFound{}).
+			WithContext(ContextSelector, "button.connect"),
+		"other selector": NewError(ErrorTypeTransient, "click_connect_button", "element not found", &rod.ErrElementNotFound{}).
+			WithContext(ContextSelector, "button.invite"),
+		"other class": NewError(ErrorTypeTransient, "click_connect_button", "click failed", &rod.ErrCovered{}).
+			WithContext(ContextSelector, "button.connect"),
+	} {
+		if FingerprintOf(err).ID == first.ID {
+			t.Errorf("%s: expected a different fingerprint", name)
+		}
+	}
+}
+
+// TestFingerprintClass tests the classes given to Rod, context and other errors
+func TestFingerprintClass(t *testing.T) {
+	for _, tc := range []struct {
+		err      error
+		expected string
+	}{
+		{fmt.Errorf("wait: %w", context.DeadlineExceeded), ClassTimeout},
+		{context.Canceled, ClassCanceled},
+		{&rod.ErrNavigation{Reason: "net::ERR_CONNECTION_RESET"}, ClassNavigation},
+		{&rod.ErrInvisibleShape{}, ClassNotInteractable},
+		{&rod.ErrEval{}, ClassEval},
+		{NewError(ErrorTypePermanent, "send_connection_request", "invitation limit reached", stderrors.New("weekly limit")), "weekly limit"},
+		{stderrors.New(`Profile "Jane Doe" at https://www.linkedin.com/in/jane-doe/ returned 429 after 3 tries`), "profile <value> at <url> returned <n> after <n> tries"},
+		{NewError(ErrorTypeRateLimit, "send_connection_request", "Rate limit exceeded", nil), "rate limit exceeded"},
+	} {
+		if class := FingerprintOf(tc.err).Class; class != tc.expected {
+			t.Errorf("FingerprintOf(%v).Class = %q, expected %q", tc.err, class, tc.expected)
+		}
+	}
+
+	// A retry the budget refused is classed by the error that would have been retried
+	refused := &RetryBudgetError{Operation: "navigate_profile", Err: NewError(ErrorTypeTimeout, "navigate_profile", "page load timeout", context.DeadlineExceeded)}
+	if fingerprint := FingerprintOf(refused); fingerprint.Operation != "navigate_profile" || fingerprint.Class != ClassTimeout {
+		t.Errorf("unexpected fingerprint of a refused retry: %+v", fingerprint)
+	}
+}
@@ -0,0 +1,190 @@
+package funnel
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// Decay defaults
+const (
+	DefaultDecayWindow = 14 * 24 * time.Hour // Recent invites and messages compared with the ones before
+	DefaultDecaySettle = 7 * 24 * time.Hour  // Time given to accept or reply before a send counts
+	DefaultDecayDrop   = 0.3                 // Share of the baseline rate the recent rate must lose
+	DefaultDecayMin    = 20                  // Sends each period needs before the rates are compared
+)
+
+// decayZ is the z-score a drop must reach, one-sided at 95% confidence, so a few unlucky sends
+// do not flag a template
+const decayZ = 1.645
+
+// Decay metrics
+const (
+	MetricAcceptance = "acceptance"
+	MetricReply      = "reply"
+)
+
+// DecayOptions bound what counts as a template's performance decaying
+type DecayOptions struct {
+	Window     time.Duration // Length of the recent period; DefaultDecayWindow unless set
+	Settle     time.Duration // Time a send is given to be accepted or replied to; DefaultDecaySettle unless set
+	Drop       float64       // Relative drop from the baseline that flags a template; DefaultDecayDrop unless set
+	MinSamples int           // Sends both periods need; DefaultDecayMin unless set
+}
+
+// Period is how a template did over one period: its sends and how many were accepted or
+// replied to within the settle time
+type Period struct {
+	Sent int `json:"sent"`
+	Hits int `json:"hits"`
+}
+
+// Rate is the share of sends accepted or replied to, 0 without sends
+func (p Period) Rate() float64 {
+	if p.Sent == 0 {
+		return 0
+	}
+	return float64(p.Hits) / float64(p.Sent)
+}
+
+// TemplateDecay compares a template's rolling rate with its baseline, the rate of every send
+// before the recent period
+type TemplateDecay struct {
+	Template string `json:"template"`
+	Metric   string `json:"metric"` // MetricAcceptance or MetricReply
+	Baseline Period `json:"baseline"`
+	Recent   Period `json:"recent"`
+	Decayed  bool   `json:"decayed"` // The recent rate fell significantly below the baseline
+}
+
+// String describes the comparison for logs, suggesting a new variant for a decayed template
+func (d TemplateDecay) String() string {
+	text := fmt.Sprintf("%s %s rate %.1f%% of %d recent sends against %.1f%% of %d before",
+		d.Template, d.Metric, d.Recent.Rate()*100, d.Recent.Sent, d.Baseline.Rate()*100, d.Baseline.Sent)
+	if d.Decayed {
+		text += "; LinkedIn may be filtering the repeated text, rotate in a new variant"
+	}
+	return text
+}
+
+// Decay compares each template's recent acceptance and reply rates with its baseline at now.
+// Only sends old enough to have settled count, and only what happened within the settle time
+// of sending, so the recent period is not judged before its invites had time to be accepted.
+// Invites count for both metrics, messages for replies; sends without a template are left out.
+// The result is sorted by template and metric.
+func Decay(requests []storage.ConnectionRequest, imported []storage.Connection, messages []storage.SentMessage, replies []storage.Reply, now time.Time, options DecayOptions) []TemplateDecay {
+	if options.Window <= 0 {
+		options.Window = DefaultDecayWindow
+	}
+	if options.Settle <= 0 {
+		options.Settle = DefaultDecaySettle
+	}
+	if options.Drop <= 0 {
+		options.Drop = DefaultDecayDrop
+	}
+	if options.MinSamples <= 0 {
+		options.MinSamples = DefaultDecayMin
+	}
+	settled := now.Add(-options.Settle)
+	recentFrom := settled.Add(-options.Window)
+
+	connected := make(map[string]storage.Connection, len(imported))
+	for _, connection := range imported {
+		connected[identity.ProfileKey(connection.ProfileURL)] = connection
+	}
+	replied := make(map[string][]time.Time)
+	for _, reply := range replies {
+		key := identity.ProfileKey(reply.ProfileURL)
+		replied[key] = append(replied[key], reply.RepliedAt)
+	}
+	repliedWithin := func(key string, sentAt time.Time) bool {
+		for _, at := range replied[key] {
+			if !at.Before(sentAt) && !at.After(sentAt.Add(options.Settle)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	type metricKey struct{ template, metric string }
+	results := make(map[metricKey]*TemplateDecay)
+	record := func(template, metric string, sentAt time.Time, hit bool) {
+		key := metricKey{template, metric}
+		result, ok := results[key]
+		if !ok {
+			result = &TemplateDecay{Template: template, Metric: metric}
+			results[key] = result
+		}
+		period := &result.Baseline
+		if !sentAt.Before(recentFrom) {
+			period = &result.Recent
+		}
+		period.Sent++
+		if hit {
+			period.Hits++
+		}
+	}
+
+	for _, request := range requests {
+		if request.Template == "" || request.SentAt.After(settled) {
+			continue
+		}
+		key := identity.ProfileKey(request.ProfileURL)
+		at, accepted := acceptedAt(request, connected[key])
+		record(request.Template, MetricAcceptance, request.SentAt, accepted && !at.After(request.SentAt.Add(options.Settle)))
+		record(request.Template, MetricReply, request.SentAt, repliedWithin(key, request.SentAt))
+	}
+	for _, message := range messages {
+		if message.Template == "" || message.SentAt.After(settled) {
+			continue
+		}
+		record(message.Template, MetricReply, message.SentAt, repliedWithin(identity.ProfileKey(message.RecipientURL), message.SentAt))
+	}
+
+	decays := make([]TemplateDecay, 0, len(results))
+	for _, result := range results {
+		result.Decayed = decayed(result.Baseline, result.Recent, options)
+		decays = append(decays, *result)
+	}
+	sort.Slice(decays, func(i, j int) bool {
+		if decays[i].Template != decays[j].Template {
+			return decays[i].Template < decays[j].Template
+		}
+		return decays[i].Metric < decays[j].Metric
+	})
+	return decays
+}
+
+// Decayed returns the decayed templates among decays whose names start with prefix, such as a
+// campaign's name and "/"
+func Decayed(decays []TemplateDecay, prefix string) []TemplateDecay {
+	var found []TemplateDecay
+	for _, decay := range decays {
+		if decay.Decayed && strings.HasPrefix(decay.Template, prefix) {
+			found = append(found, decay)
+		}
+	}
+	return found
+}
+
+// decayed reports whether the recent rate fell below the baseline by at least the relative
+// drop, on enough sends that a one-sided two-proportion z-test finds the drop significant
+func decayed(baseline, recent Period, options DecayOptions) bool {
+	if baseline.Sent < options.MinSamples || recent.Sent < options.MinSamples || baseline.Hits == 0 {
+		return false
+	}
+	if recent.Rate() > baseline.Rate()*(1-options.Drop) {
+		return false
+	}
+	pooled := float64(baseline.Hits+recent.Hits) / float64(baseline.Sent+recent.Sent)
+	stderr := math.Sqrt(pooled * (1 - pooled) * (1/float64(baseline.Sent) + 1/float64(recent.Sent)))
+	if stderr == 0 {
+		return false
+	}
+	return (baseline.Rate()-recent.Rate())/stderr >= decayZ
+}
@@ -0,0 +1,167 @@
+// Package funnel follows invites down the funnel, from sent to accepted to replied, in weekly
+// cohorts by the week the invites were sent
+package funnel
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"linkedin-automation-framework/internal/connections"
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// DefaultWeeks is how many weeks a cohort is followed for unless set
+const DefaultWeeks = 8
+
+// Cohort is the invites sent in one week, Monday to Sunday, and how many of them had been
+// accepted and replied to by the end of that week and each following week
+type Cohort struct {
+	Week     time.Time // Monday the week started
+	Invites  int
+	Accepted []int // Accepted by the end of week 0, the week of sending, week 1 and so on; only weeks that have begun
+	Replied  []int // Replied to by the end of each week, like Accepted
+}
+
+// AcceptanceRate is the share of the cohort's invites accepted by the end of week
+func (c Cohort) AcceptanceRate(week int) float64 {
+	return rate(c.Accepted, week, c.Invites)
+}
+
+// ReplyRate is the share of the cohort's invites replied to by the end of week
+func (c Cohort) ReplyRate(week int) float64 {
+	return rate(c.Replied, week, c.Invites)
+}
+
+// Build groups the invites into cohorts, earliest week first, each followed for up to weeks
+// weeks or until now. An invite counts as accepted once it is marked accepted or the imported
+// connections hold the profile, at the connection's "Connected On" date or else the import's
+// time, and as replied to at the profile's first reply after it was sent.
+func Build(requests []storage.ConnectionRequest, imported []storage.Connection, replies []storage.Reply, now time.Time, weeks int) []Cohort {
+	if weeks <= 0 {
+		weeks = DefaultWeeks
+	}
+
+	connected := make(map[string]storage.Connection, len(imported))
+	for _, connection := range imported {
+		connected[identity.ProfileKey(connection.ProfileURL)] = connection
+	}
+	replied := make(map[string][]time.Time)
+	for _, reply := range replies {
+		key := identity.ProfileKey(reply.ProfileURL)
+		replied[key] = append(replied[key], reply.RepliedAt)
+	}
+
+	byWeek := make(map[time.Time]*Cohort)
+	var cohorts []*Cohort
+	for _, request := range requests {
+		week := WeekStart(request.SentAt)
+		cohort, ok := byWeek[week]
+		if !ok {
+			span := min(weeksBetween(week, now)+1, weeks)
+			if span < 1 {
+				continue // Sent after now
+			}
+			cohort = &Cohort{Week: week, Accepted: make([]int, span), Replied: make([]int, span)}
+			byWeek[week] = cohort
+			cohorts = append(cohorts, cohort)
+		}
+		cohort.Invites++
+
+		key := identity.ProfileKey(request.ProfileURL)
+		if acceptedAt, ok := acceptedAt(request, connected[key]); ok {
+			count(cohort.Accepted, weeksBetween(week, acceptedAt))
+		}
+		var firstReply time.Time
+		for _, repliedAt := range replied[key] {
+			if !repliedAt.Before(request.SentAt) && (firstReply.IsZero() || repliedAt.Before(firstReply)) {
+				firstReply = repliedAt
+			}
+		}
+		if !firstReply.IsZero() {
+			count(cohort.Replied, weeksBetween(week, firstReply))
+		}
+	}
+
+	result := make([]Cohort, 0, len(cohorts))
+	for _, cohort := range cohorts {
+		result = append(result, *cohort)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Week.Before(result[j].Week) })
+	return result
+}
+
+// WriteCSV writes one row per cohort and week after sending, with the cumulative counts and rates
+func WriteCSV(w io.Writer, cohorts []Cohort) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"week", "invites", "weeks_after", "accepted", "acceptance_rate", "replied", "reply_rate"})
+	for _, cohort := range cohorts {
+		for week := range cohort.Accepted {
+			writer.Write([]string{
+				cohort.Week.Format("2006-01-02"),
+				strconv.Itoa(cohort.Invites),
+				strconv.Itoa(week),
+				strconv.Itoa(cohort.Accepted[week]),
+				strconv.FormatFloat(cohort.AcceptanceRate(week), 'f', 4, 64),
+				strconv.Itoa(cohort.Replied[week]),
+				strconv.FormatFloat(cohort.ReplyRate(week), 'f', 4, 64),
+			})
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WeekStart returns the midnight starting t's week, which runs Monday to Sunday
+func WeekStart(t time.Time) time.Time {
+	sinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-sinceMonday, 0, 0, 0, 0, t.Location())
+}
+
+// acceptedAt returns when the invite was accepted, if it was
+func acceptedAt(request storage.ConnectionRequest, connection storage.Connection) (time.Time, bool) {
+	if connection.ProfileURL == "" {
+		return time.Time{}, false // Not connected, or accepted without a connection to date it
+	}
+	at := connection.ConnectedOn
+	if at.IsZero() {
+		at = connection.ImportedAt
+	}
+	// A connection made before the invite's day predates it, unless the invite was marked accepted
+	sentDay := time.Date(request.SentAt.Year(), request.SentAt.Month(), request.SentAt.Day(), 0, 0, 0, 0, time.UTC)
+	if request.Status != connections.StatusAccepted && at.Before(sentDay) {
+		return time.Time{}, false
+	}
+	// "Connected On" is a date, so an invite accepted the day it was sent shows as connected before it
+	if at.Before(request.SentAt) {
+		at = request.SentAt
+	}
+	return at, true
+}
+
+// weeksBetween counts the weeks from the week starting at week to t's week
+func weeksBetween(week, t time.Time) int {
+	if t.Before(week) {
+		return -1
+	}
+	// Rounded, since a week with a daylight saving time change is an hour shorter or longer
+	return int((WeekStart(t.In(week.Location())).Sub(week).Hours() + 12) / (7 * 24))
+}
+
+// count adds an event in week to the cumulative counts of that week and every later one
+func count(counts []int, week int) {
+	for i := max(week, 0); i < len(counts); i++ {
+		counts[i]++
+	}
+}
+
+// rate divides the count of week by invites, 0 for a week not followed or without invites
+func rate(counts []int, week, invites int) float64 {
+	if week < 0 || week >= len(counts) || invites == 0 {
+		return 0
+	}
+	return float64(counts[week]) / float64(invites)
+}
@@ -0,0 +1,200 @@
+package funnel
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/storage"
+)
+
+// TestBuild tests that invites fall into the week they were sent and count as accepted and
+// replied to from the week it happened on
+func TestBuild(t *testing.T) {
+	day := func(month time.Month, day int) time.Time { return time.Date(2024, month, day, 10, 0, 0, 0, time.UTC) }
+	requests := []storage.ConnectionRequest{
+		{ProfileURL: "https://www.linkedin.com/in/jane", SentAt: day(7, 2), Status: "accepted"},
+		{ProfileURL: "https://www.linkedin.com/in/john/", SentAt: day(7, 4), Status: "pending"},
+		{ProfileURL: "https://www.linkedin.com/in/old-friend", SentAt: day(7, 5), Status: "pending"},
+		{ProfileURL: "https://www.linkedin.com/in/ann", SentAt: day(7, 8), Status: "accepted"},
+	}
+	imported := []storage.Connection{
+		// Accepted the day it was sent, which the export dates to midnight
+		{ProfileURL: "https://www.linkedin.com/in/jane", ConnectedOn: time.Date(2024, 7, 2, 0, 0, 0, 0, time.UTC)},
+		// Not yet reconciled, so only the export says it was accepted
+		{ProfileURL: "https://www.linkedin.com/in/john", ConnectedOn: time.Date(2024, 7, 15, 0, 0, 0, 0, time.UTC)},
+		// Connected long before the invite, which does not accept it
+		{ProfileURL: "https://www.linkedin.com/in/old-friend", ConnectedOn: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// Without a date, accepted by the time of the import
+		{ProfileURL: "https://www.linkedin.com/in/ann", ImportedAt: day(7, 10)},
+	}
+	replies := []storage.Reply{
+		{ProfileURL: "https://www.linkedin.com/in/jane", RepliedAt: day(6, 1)}, // Before the invite
+		{ProfileURL: "https://www.linkedin.com/in/jane", RepliedAt: day(7, 9)},
+		{ProfileURL: "https://www.linkedin.com/in/jane", RepliedAt: day(7, 20)},
+	}
+
+	cohorts := Build(requests, imported, replies, day(7, 17), 8)
+	if len(cohorts) != 2 {
+		t.Fatalf("expected two cohorts, got %+v", cohorts)
+	}
+	first, second := cohorts[0], cohorts[1]
+	if !first.Week.Equal(time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)) || first.Invites != 3 {
+		t.Errorf("expected 3 invites in the week of 1 July, got %d in %v", first.Invites, first.Week)
+	}
+	if len(first.Accepted) != 3 || len(second.Accepted) != 2 {
+		t.Errorf("expected cohorts followed up to the current week, got %d and %d weeks", len(first.Accepted), len(second.Accepted))
+	}
+	if got := []int{first.Accepted[0], first.Accepted[1], first.Accepted[2]}; got[0] != 1 || got[1] != 1 || got[2] != 2 {
+		t.Errorf("expected 1, 1 and 2 accepted, got %v", got)
+	}
+	if first.Replied[0] != 0 || first.Replied[1] != 1 || first.ReplyRate(2) != 1.0/3 {
+		t.Errorf("expected jane's first reply after the invite counted from week 1, got %v", first.Replied)
+	}
+	if second.AcceptanceRate(0) != 1 || second.AcceptanceRate(5) != 0 {
+		t.Errorf("expected ann accepted in week 0 and no rate past the weeks followed, got %v", second.Accepted)
+	}
+
+	var csv bytes.Buffer
+	if err := WriteCSV(&csv, cohorts); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+	if len(lines) != 6 || lines[3] != "2024-07-01,3,2,2,0.6667,1,0.3333" {
+		t.Errorf("unexpected CSV:\n%s", csv.String())
+	}
+
+	var html bytes.Buffer
+	if err := WriteHTML(&html, cohorts, day(7, 17)); err != nil {
+		t.Fatalf("failed to write HTML: %v", err)
+	}
+	if strings.Count(html.String(), "<polyline") != 4 || !strings.Contains(html.String(), "66.7%") {
+		t.Errorf("expected a line per cohort in both charts and the rates in the tables, got:\n%s", html.String())
+	}
+}
+
+// TestDecay tests that a template whose recent invites are accepted far less often than before
+// is flagged, while a steady template, one with too few sends and unsettled sends are not
+func TestDecay(t *testing.T) {
+	now := time.Date(2024, 9, 30, 12, 0, 0, 0, time.UTC)
+	var requests []storage.ConnectionRequest
+	var imported []storage.Connection
+	var messages []storage.SentMessage
+	var replies []storage.Reply
+	invite := func(template string, daysAgo, i int, accepted, repliedTo bool) {
+		url := fmt.Sprintf("https://www.linkedin.com/in/%s-%d-%d", strings.ReplaceAll(template, "/", "-"), daysAgo, i)
+		sentAt := now.AddDate(0, 0, -daysAgo)
+		requests = append(requests, storage.ConnectionRequest{ProfileURL: url, SentAt: sentAt, Status: "pending", Template: template})
+		if accepted {
+			imported = append(imported, storage.Connection{ProfileURL: url, ConnectedOn: sentAt.AddDate(0, 0, 2)})
+		}
+		if repliedTo {
+			replies = append(replies, storage.Reply{ProfileURL: url, RepliedAt: sentAt.AddDate(0, 0, 3)})
+		}
+	}
+	for i := 0; i < 40; i++ {
+		invite("c/decaying", 40, i, i%2 == 0, i%4 == 0) // 50% accepted and 25% replied before
+		invite("c/steady", 40, i, i%2 == 0, false)
+	}
+	for i := 0; i < 30; i++ {
+		invite("c/decaying", 10, i, i%10 == 0, i%4 == 0) // 10% accepted recently, replies unchanged
+		invite("c/steady", 10, i, i%2 == 0, false)
+		invite("c/decaying", 2, i, false, false) // Not settled yet
+	}
+	for i := 0; i < 5; i++ {
+		invite("c/new", 40, i, true, false)
+		invite("c/new", 10, i, false, false)
+	}
+	invite("", 10, 0, false, false) // Blank invite
+	messages = append(messages, storage.SentMessage{RecipientURL: "https://www.linkedin.com/in/jane", Template: "c/welcome", SentAt: now.AddDate(0, 0, -30)})
+	replies = append(replies, storage.Reply{ProfileURL: "https://www.linkedin.com/in/jane", RepliedAt: now.AddDate(0, 0, -29)})
+
+	decays := Decay(requests, imported, messages, replies, now, DecayOptions{})
+	byKey := make(map[string]TemplateDecay)
+	for _, decay := range decays {
+		byKey[decay.Template+" "+decay.Metric] = decay
+	}
+	if len(decays) != 7 {
+		t.Errorf("expected both metrics of three invite templates and replies to one message template, got %+v", decays)
+	}
+	if decay := byKey["c/decaying acceptance"]; !decay.Decayed || decay.Recent.Sent != 30 || decay.Recent.Rate() != 0.1 || decay.Baseline.Rate() != 0.5 {
+		t.Errorf("expected the decaying template's acceptance flagged at 10%% of 30 against 50%%, got %+v", decay)
+	}
+	if decay := byKey["c/decaying reply"]; decay.Decayed {
+		t.Errorf("expected the unchanged reply rate not to be flagged, got %+v", decay)
+	}
+	if decay := byKey["c/steady acceptance"]; decay.Decayed {
+		t.Errorf("expected the steady template not to be flagged, got %+v", decay)
+	}
+	if decay := byKey["c/new acceptance"]; decay.Decayed {
+		t.Errorf("expected too few sends not to be flagged, got %+v", decay)
+	}
+	if decay := byKey["c/welcome reply"]; decay.Baseline.Hits != 1 {
+		t.Errorf("expected the message's reply counted, got %+v", decay)
+	}
+	if decayed := Decayed(decays, "c/"); len(decayed) != 1 || !strings.Contains(decayed[0].String(), "rotate in a new variant") {
+		t.Errorf("expected one decayed template suggesting a new variant, got %v", decayed)
+	}
+	if decayed := Decayed(decays, "other/"); len(decayed) != 0 {
+		t.Errorf("expected no decayed template in another campaign, got %v", decayed)
+	}
+}
+
+// TestBuildGrowth tests that each week takes its last snapshot and its outreach, that the
+// follower gain is only known after a tracked week, and that outreach and gain correlate
+func TestBuildGrowth(t *testing.T) {
+	// Wednesday of the fourth week the report covers
+	now := time.Date(2024, 7, 24, 12, 0, 0, 0, time.UTC)
+	day := func(month time.Month, day int) time.Time { return time.Date(2024, month, day, 10, 0, 0, 0, time.UTC) }
+	snapshots := []storage.AudienceSnapshot{
+		{TakenAt: day(6, 28), Followers: 900}, // Before the report
+		{TakenAt: day(7, 2), Followers: 1000, Impressions: 4000},
+		{TakenAt: day(7, 7), Followers: 1010, Impressions: 4100},
+		{TakenAt: day(7, 14), Followers: 1060, Impressions: 5200, Subscribers: 120},
+		{TakenAt: day(7, 23), Followers: 1070, Impressions: 3000, Subscribers: 125},
+	}
+	var requests []storage.ConnectionRequest
+	for i := 0; i < 20; i++ {
+		requests = append(requests, storage.ConnectionRequest{SentAt: day(7, 10)})
+	}
+	requests = append(requests, storage.ConnectionRequest{SentAt: day(7, 2)}, storage.ConnectionRequest{SentAt: day(7, 25)})
+	messages := []storage.SentMessage{{SentAt: day(7, 3)}, {SentAt: day(7, 22)}, {SentAt: day(7, 23)}}
+
+	growth := BuildGrowth(snapshots, requests, messages, now, 4)
+	if len(growth) != 4 || !growth[0].Week.Equal(time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the four weeks from July 1, got %+v", growth)
+	}
+	first, second, third, fourth := growth[0], growth[1], growth[2], growth[3]
+	if first.Followers != 1010 || first.Impressions != 4100 || first.GainKnown || first.Invites != 1 || first.Messages != 1 {
+		t.Errorf("expected the first week's last snapshot and no known gain, got %+v", first)
+	}
+	if second.Followers != 1060 || second.FollowerGain != 50 || !second.GainKnown || second.Invites != 20 || second.Subscribers != 120 {
+		t.Errorf("expected the second week to gain 50 followers, got %+v", second)
+	}
+	if third.Tracked || third.GainKnown || fourth.GainKnown || fourth.Followers != 1070 || fourth.Messages != 2 || fourth.Invites != 0 {
+		t.Errorf("expected no gain across the untracked third week, got %+v and %+v", third, fourth)
+	}
+
+	if _, samples, ok := Correlation(growth); ok || samples != 1 {
+		t.Errorf("expected too few weeks to correlate, got %d", samples)
+	}
+	weeks := []GrowthWeek{
+		{Invites: 10, FollowerGain: 12, GainKnown: true},
+		{Invites: 40, FollowerGain: 45, GainKnown: true},
+		{Invites: 20, Messages: 5, FollowerGain: 30, GainKnown: true},
+		{Invites: 80, FollowerGain: 0},
+	}
+	if r, samples, ok := Correlation(weeks); !ok || samples != 3 || r < 0.95 {
+		t.Errorf("expected a strong correlation over three weeks, got %.2f over %d", r, samples)
+	}
+
+	var html bytes.Buffer
+	if err := WriteGrowthHTML(&html, growth, now); err != nil {
+		t.Fatalf("failed to write HTML: %v", err)
+	}
+	if strings.Count(html.String(), "<polyline") != 6 || !strings.Contains(html.String(), "Followers gained") {
+		t.Errorf("expected a line per series in the three charts, got:\n%s", html.String())
+	}
+}
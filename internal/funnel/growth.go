@@ -0,0 +1,257 @@
+package funnel
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"linkedin-automation-framework/internal/storage"
+)
+
+// DefaultGrowthWeeks is how many weeks the growth report covers unless set
+const DefaultGrowthWeeks = 12
+
+// minCorrelationWeeks is how many weeks with a known follower gain a correlation needs
+const minCorrelationWeeks = 3
+
+// GrowthWeek is the account's audience at the end of one week, Monday to Sunday, beside the
+// outreach sent that week
+type GrowthWeek struct {
+	Week         time.Time // Monday the week started
+	Tracked      bool      // Whether a snapshot was taken in the week; the audience counts are 0 otherwise
+	Followers    int       // At the week's last snapshot
+	FollowerGain int       // Since the previous week's last snapshot
+	GainKnown    bool      // Whether the previous week was tracked too, so FollowerGain is set
+	Impressions  int       // Post impressions over the 7 days before the week's last snapshot
+	Subscribers  int       // Newsletter subscribers at the week's last snapshot
+	Invites      int       // Invites sent in the week
+	Messages     int       // Messages sent in the week
+}
+
+// Outreach is everything sent in the week
+func (w GrowthWeek) Outreach() int {
+	return w.Invites + w.Messages
+}
+
+// BuildGrowth lays the audience snapshots and the invites and messages sent out by week, over the
+// weeks weeks up to now's, earliest first
+func BuildGrowth(snapshots []storage.AudienceSnapshot, requests []storage.ConnectionRequest, messages []storage.SentMessage, now time.Time, weeks int) []GrowthWeek {
+	if weeks <= 0 {
+		weeks = DefaultGrowthWeeks
+	}
+	first := WeekStart(now).AddDate(0, 0, -7*(weeks-1))
+	growth := make([]GrowthWeek, weeks)
+	for i := range growth {
+		growth[i].Week = first.AddDate(0, 0, 7*i)
+	}
+	// index returns the week t falls in, or -1 outside the report
+	index := func(t time.Time) int {
+		if t.After(now) {
+			return -1
+		}
+		if i := weeksBetween(first, t); i < weeks {
+			return i
+		}
+		return -1
+	}
+
+	// Snapshots are oldest first, so each week ends up with its last
+	for _, snapshot := range snapshots {
+		if i := index(snapshot.TakenAt); i >= 0 {
+			growth[i].Tracked = true
+			growth[i].Followers, growth[i].Impressions, growth[i].Subscribers = snapshot.Followers, snapshot.Impressions, snapshot.Subscribers
+		}
+	}
+	for i := 1; i < weeks; i++ {
+		if growth[i].Tracked && growth[i-1].Tracked {
+			growth[i].FollowerGain, growth[i].GainKnown = growth[i].Followers-growth[i-1].Followers, true
+		}
+	}
+	for _, request := range requests {
+		if i := index(request.SentAt); i >= 0 {
+			growth[i].Invites++
+		}
+	}
+	for _, message := range messages {
+		if i := index(message.SentAt); i >= 0 {
+			growth[i].Messages++
+		}
+	}
+	return growth
+}
+
+// Correlation is the Pearson correlation between the outreach sent in a week and the followers
+// gained that week, over the weeks with a known gain. It reports false when fewer than three
+// weeks have one or either side never changes.
+func Correlation(growth []GrowthWeek) (float64, int, bool) {
+	var xs, ys []float64
+	for _, week := range growth {
+		if week.GainKnown {
+			xs = append(xs, float64(week.Outreach()))
+			ys = append(ys, float64(week.FollowerGain))
+		}
+	}
+	if len(xs) < minCorrelationWeeks {
+		return 0, len(xs), false
+	}
+	meanX, meanY := mean(xs), mean(ys)
+	var covariance, varianceX, varianceY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		covariance += dx * dy
+		varianceX += dx * dx
+		varianceY += dy * dy
+	}
+	if varianceX == 0 || varianceY == 0 {
+		return 0, len(xs), false
+	}
+	return covariance / math.Sqrt(varianceX*varianceY), len(xs), true
+}
+
+// mean averages values
+func mean(values []float64) float64 {
+	total := 0.0
+	for _, value := range values {
+		total += value
+	}
+	return total / float64(len(values))
+}
+
+// WriteGrowthCSV writes one row per week; the audience columns are empty for weeks not tracked
+func WriteGrowthCSV(w io.Writer, growth []GrowthWeek) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"week", "followers", "follower_gain", "impressions", "subscribers", "invites", "messages"})
+	for _, week := range growth {
+		row := []string{week.Week.Format("2006-01-02"), "", "", "", "", strconv.Itoa(week.Invites), strconv.Itoa(week.Messages)}
+		if week.Tracked {
+			row[1], row[3], row[4] = strconv.Itoa(week.Followers), strconv.Itoa(week.Impressions), strconv.Itoa(week.Subscribers)
+		}
+		if week.GainKnown {
+			row[2] = strconv.Itoa(week.FollowerGain)
+		}
+		writer.Write(row)
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// growthTemplate renders the audience charts as one self-contained page
+var growthTemplate = template.Must(template.Must(template.New("growth").Parse(chartMarkup)).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Audience growth</title>
+` + reportStyle + `
+</head>
+<body>
+<h1>Audience growth</h1>
+<p>Followers, post impressions and newsletter subscribers by week, beside the invites and messages sent. {{.Correlation}} Generated {{.Generated}}.</p>
+{{range .Charts}}{{template "chart" .}}{{end}}
+</body>
+</html>
+`))
+
+type growthPage struct {
+	Generated   string
+	Correlation string
+	Charts      []reportChart
+}
+
+// growthSeries is one line of a growth chart; weeks whose value is not known are left out of it
+type growthSeries struct {
+	Label string
+	Value func(GrowthWeek) (int, bool)
+}
+
+// WriteGrowthHTML writes the weeks as an HTML report: line charts of the audience and of the
+// weekly follower gain beside the outreach sent, with their correlation
+func WriteGrowthHTML(w io.Writer, growth []GrowthWeek, generated time.Time) error {
+	page := growthPage{Generated: generated.Format("2006-01-02 15:04"), Correlation: DescribeCorrelation(growth)}
+	tracked := func(value func(GrowthWeek) int) func(GrowthWeek) (int, bool) {
+		return func(week GrowthWeek) (int, bool) { return value(week), week.Tracked }
+	}
+	always := func(value func(GrowthWeek) int) func(GrowthWeek) (int, bool) {
+		return func(week GrowthWeek) (int, bool) { return value(week), true }
+	}
+	page.Charts = []reportChart{
+		countChart("Audience", growth, []growthSeries{
+			{"Followers", tracked(func(week GrowthWeek) int { return week.Followers })},
+			{"Newsletter subscribers", tracked(func(week GrowthWeek) int { return week.Subscribers })},
+		}),
+		countChart("Post impressions, last 7 days", growth, []growthSeries{
+			{"Impressions", tracked(func(week GrowthWeek) int { return week.Impressions })},
+		}),
+		countChart("Outreach and follower gain", growth, []growthSeries{
+			{"Invites", always(func(week GrowthWeek) int { return week.Invites })},
+			{"Messages", always(func(week GrowthWeek) int { return week.Messages })},
+			{"Followers gained", func(week GrowthWeek) (int, bool) { return week.FollowerGain, week.GainKnown }},
+		}),
+	}
+	return growthTemplate.Execute(w, page)
+}
+
+// DescribeCorrelation puts the correlation between outreach and follower gain in words
+func DescribeCorrelation(growth []GrowthWeek) string {
+	r, samples, ok := Correlation(growth)
+	if !ok {
+		return fmt.Sprintf("Outreach and follower gain cannot be correlated yet: it takes %d weeks with a known follower gain that varies, and there are %d so far.", minCorrelationWeeks, samples)
+	}
+	return fmt.Sprintf("Correlation between weekly outreach and follower gain: %.2f over %d weeks.", r, samples)
+}
+
+// countChart plots one line per series over the weeks, on a scale from the lowest value, or 0,
+// to the highest
+func countChart(title string, growth []GrowthWeek, series []growthSeries) reportChart {
+	chart := reportChart{Title: title, Width: chartWidth, Height: chartHeight}
+	bottom, top := 0, 1
+	for _, line := range series {
+		for _, week := range growth {
+			if value, ok := line.Value(week); ok {
+				bottom, top = min(bottom, value), max(top, value)
+			}
+		}
+	}
+
+	plotWidth, plotHeight := chartWidth-2*chartPadding, chartHeight-2*chartPadding
+	x := func(i int) int {
+		if len(growth) == 1 {
+			return chartPadding
+		}
+		return chartPadding + i*plotWidth/(len(growth)-1)
+	}
+	y := func(value float64) int {
+		return chartPadding + plotHeight - int((value-float64(bottom))/float64(top-bottom)*float64(plotHeight))
+	}
+
+	for step := 0; step <= 4; step++ {
+		value := float64(bottom) + float64(top-bottom)*float64(step)/4
+		chart.Grid = append(chart.Grid, gridLine{
+			X1: chartPadding, X2: chartWidth - chartPadding, Y: y(value), LabelX: chartPadding - 6,
+			Label: strconv.Itoa(int(math.Round(value))),
+		})
+	}
+	// Label every other week when there are many, so the dates do not overlap
+	every := max(1, (len(growth)+7)/8)
+	for i, week := range growth {
+		if i%every == 0 {
+			chart.Ticks = append(chart.Ticks, tick{X: x(i), Y: chartHeight - chartPadding/2, Label: week.Week.Format("Jan 2")})
+		}
+	}
+	for i, line := range series {
+		color := template.CSS(chartColors[i%len(chartColors)])
+		var points []string
+		for j, week := range growth {
+			if value, ok := line.Value(week); ok {
+				points = append(points, fmt.Sprintf("%d,%d", x(j), y(float64(value))))
+			}
+		}
+		chart.Lines = append(chart.Lines, chartLine{Color: color, Points: strings.Join(points, " ")})
+		chart.Legend = append(chart.Legend, legendEntry{Color: color, Label: line.Label})
+	}
+	return chart
+}
@@ -0,0 +1,209 @@
+package funnel
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// Chart dimensions, in SVG units
+const (
+	chartWidth   = 640
+	chartHeight  = 240
+	chartPadding = 40
+)
+
+// chartColors tell the cohorts apart, repeating after the last
+var chartColors = []string{"#0a66c2", "#e16745", "#2e8540", "#8e44ad", "#c0392b", "#16a085", "#d4a017", "#5d6d7e"}
+
+// chartMarkup draws a reportChart as an SVG line chart under its title, with its legend if it has one
+const chartMarkup = `{{define "chart"}}
+<h2>{{.Title}}</h2>
+<svg width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}">
+{{range .Grid}}<line x1="{{.X1}}" y1="{{.Y}}" x2="{{.X2}}" y2="{{.Y}}" stroke="#d0d7de"/><text x="{{.LabelX}}" y="{{.Y}}" font-size="11" text-anchor="end" dominant-baseline="middle">{{.Label}}</text>
+{{end}}{{range .Ticks}}<text x="{{.X}}" y="{{.Y}}" font-size="11" text-anchor="middle">{{.Label}}</text>
+{{end}}{{range .Lines}}<polyline fill="none" stroke="{{.Color}}" stroke-width="2" points="{{.Points}}"/>
+{{end}}</svg>
+{{if .Legend}}<div class="legend">{{range .Legend}}<span><i style="background: {{.Color}}"></i>{{.Label}}</span>{{end}}</div>
+{{end}}{{end}}`
+
+// reportStyle is the stylesheet the reports share
+const reportStyle = `<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #1d2226; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #d0d7de; padding: 4px 10px; text-align: right; }
+th { background: #f3f6f8; }
+svg { background: #fafbfc; border: 1px solid #d0d7de; margin-bottom: 1em; }
+.legend span { display: inline-block; margin-right: 1em; }
+.legend i { display: inline-block; width: 12px; height: 12px; margin-right: 4px; vertical-align: middle; }
+</style>`
+
+// reportTemplate renders the cohort tables and charts as one self-contained page
+var reportTemplate = template.Must(template.Must(template.New("report").Parse(chartMarkup)).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Invite cohorts</title>
+` + reportStyle + `
+</head>
+<body>
+<h1>Invite cohorts</h1>
+<p>Invites grouped by the week they were sent, with the share accepted and replied to by the end of each following week. Generated {{.Generated}}.</p>
+{{range .Charts}}{{template "chart" .}}{{end}}
+<div class="legend">{{range .Legend}}<span><i style="background: {{.Color}}"></i>{{.Label}}</span>{{end}}</div>
+{{range .Tables}}
+<h2>{{.Title}}</h2>
+<table>
+<tr><th>Week</th><th>Invites</th>{{range .Headers}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr><td>{{.Week}}</td><td>{{.Invites}}</td>{{range .Cells}}<td style="background: {{.Shade}}">{{.Text}}</td>{{end}}</tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+type reportPage struct {
+	Generated string
+	Charts    []reportChart
+	Legend    []legendEntry
+	Tables    []reportTable
+}
+
+type reportChart struct {
+	Title         string
+	Width, Height int
+	Grid          []gridLine
+	Ticks         []tick
+	Lines         []chartLine
+	Legend        []legendEntry // Set when the page has no legend for every chart
+}
+
+type gridLine struct {
+	X1, X2, Y, LabelX int
+	Label             string
+}
+
+type tick struct {
+	X, Y  int
+	Label string
+}
+
+type chartLine struct {
+	Color  template.CSS
+	Points string
+}
+
+type legendEntry struct {
+	Color template.CSS
+	Label string
+}
+
+type reportTable struct {
+	Title   string
+	Headers []string
+	Rows    []tableRow
+}
+
+type tableRow struct {
+	Week    string
+	Invites int
+	Cells   []tableCell
+}
+
+type tableCell struct {
+	Text  string
+	Shade template.CSS
+}
+
+// WriteHTML writes the cohorts as an HTML report: a line chart of each cohort's acceptance and
+// reply rate over the weeks after sending, and the cohort tables behind them
+func WriteHTML(w io.Writer, cohorts []Cohort, generated time.Time) error {
+	weeks := 1
+	for _, cohort := range cohorts {
+		weeks = max(weeks, len(cohort.Accepted))
+	}
+	page := reportPage{Generated: generated.Format("2006-01-02 15:04")}
+	for i, cohort := range cohorts {
+		page.Legend = append(page.Legend, legendEntry{
+			Color: template.CSS(chartColors[i%len(chartColors)]),
+			Label: fmt.Sprintf("%s (%d invites)", cohort.Week.Format("2006-01-02"), cohort.Invites),
+		})
+	}
+	page.Charts = []reportChart{
+		rateChart("Acceptance rate", cohorts, weeks, Cohort.AcceptanceRate),
+		rateChart("Reply rate", cohorts, weeks, Cohort.ReplyRate),
+	}
+	page.Tables = []reportTable{
+		rateTable("Accepted by week after sending", cohorts, weeks, Cohort.AcceptanceRate),
+		rateTable("Replied by week after sending", cohorts, weeks, Cohort.ReplyRate),
+	}
+	return reportTemplate.Execute(w, page)
+}
+
+// rateChart plots one line per cohort of a rate over the weeks after sending, scaled to the
+// highest rate so low reply rates stay readable
+func rateChart(title string, cohorts []Cohort, weeks int, rateOf func(Cohort, int) float64) reportChart {
+	chart := reportChart{Title: title, Width: chartWidth, Height: chartHeight}
+	top := 0.0
+	for _, cohort := range cohorts {
+		for week := range cohort.Accepted {
+			top = max(top, rateOf(cohort, week))
+		}
+	}
+	// Round the scale up to the next 10%
+	top = max(float64(int(top*10+0.999))/10, 0.1)
+
+	plotWidth, plotHeight := chartWidth-2*chartPadding, chartHeight-2*chartPadding
+	x := func(week int) int {
+		if weeks == 1 {
+			return chartPadding
+		}
+		return chartPadding + week*plotWidth/(weeks-1)
+	}
+	y := func(rate float64) int { return chartPadding + plotHeight - int(rate/top*float64(plotHeight)) }
+
+	for step := 0; step <= 4; step++ {
+		rate := top * float64(step) / 4
+		chart.Grid = append(chart.Grid, gridLine{
+			X1: chartPadding, X2: chartWidth - chartPadding, Y: y(rate), LabelX: chartPadding - 6,
+			Label: fmt.Sprintf("%.0f%%", rate*100),
+		})
+	}
+	for week := 0; week < weeks; week++ {
+		chart.Ticks = append(chart.Ticks, tick{X: x(week), Y: chartHeight - chartPadding/2, Label: fmt.Sprintf("W+%d", week)})
+	}
+	for i, cohort := range cohorts {
+		var points []string
+		for week := range cohort.Accepted {
+			points = append(points, fmt.Sprintf("%d,%d", x(week), y(rateOf(cohort, week))))
+		}
+		chart.Lines = append(chart.Lines, chartLine{Color: template.CSS(chartColors[i%len(chartColors)]), Points: strings.Join(points, " ")})
+	}
+	return chart
+}
+
+// rateTable lays a rate out as the cohort triangle, shading each cell by its rate
+func rateTable(title string, cohorts []Cohort, weeks int, rateOf func(Cohort, int) float64) reportTable {
+	table := reportTable{Title: title}
+	for week := 0; week < weeks; week++ {
+		table.Headers = append(table.Headers, fmt.Sprintf("W+%d", week))
+	}
+	for _, cohort := range cohorts {
+		row := tableRow{Week: cohort.Week.Format("2006-01-02"), Invites: cohort.Invites}
+		for week := 0; week < weeks; week++ {
+			if week >= len(cohort.Accepted) {
+				row.Cells = append(row.Cells, tableCell{Shade: "transparent"})
+				continue
+			}
+			rate := rateOf(cohort, week)
+			row.Cells = append(row.Cells, tableCell{
+				Text:  fmt.Sprintf("%.1f%%", rate*100),
+				Shade: template.CSS(fmt.Sprintf("rgba(10, 102, 194, %.2f)", rate*0.8)),
+			})
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table
+}
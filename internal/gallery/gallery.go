@@ -0,0 +1,236 @@
+// Package gallery keeps the screenshots a campaign run takes of the browser, one gallery per run,
+// so what the run saw can be audited afterwards without running it again.
+//
+// A run's screenshots are stored as <dir>/<run ID>/<sequence>.png, next to an index.json listing
+// them in the order they were taken.
+package gallery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// IndexFile lists a run's screenshots in its directory
+const IndexFile = "index.json"
+
+// Why a screenshot was taken
+const (
+	ReasonInterval   = "interval"   // Every so many actions
+	ReasonTransition = "transition" // A lead entered a new state
+	ReasonFailure    = "failure"    // A step failed
+)
+
+// ErrNotFound is returned for runs and screenshots that are not in the gallery
+var ErrNotFound = errors.New("not in the screenshot gallery")
+
+// namePattern matches run IDs and file names safe to join to a directory
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Shot is one screenshot of a run
+type Shot struct {
+	File    string    `json:"file"`
+	Reason  string    `json:"reason"`
+	Label   string    `json:"label"` // What was happening, e.g. the step and lead
+	URL     string    `json:"url,omitempty"`
+	TakenAt time.Time `json:"taken_at"`
+}
+
+// Run summarizes the gallery of one run
+type Run struct {
+	RunID   string    `json:"run_id"`
+	Shots   int       `json:"shots"`
+	First   time.Time `json:"first"`
+	Last    time.Time `json:"last"`
+	Reasons []string  `json:"reasons,omitempty"` // Distinct reasons, in the order they first occur
+}
+
+// Camera takes a PNG screenshot of a page and returns it with the page's URL
+type Camera func() (png []byte, url string, err error)
+
+// Recorder fills the gallery of one run. It is safe for concurrent use; a nil *Recorder takes
+// no screenshots.
+type Recorder struct {
+	dir     string
+	every   int
+	actions int
+	shots   []Shot
+	now     func() time.Time
+	mutex   sync.Mutex
+}
+
+// NewRecorder creates the gallery of runID under dir, taking a screenshot every so many actions
+// (never on actions if every is 0)
+func NewRecorder(dir, runID string, every int) (*Recorder, error) {
+	if !namePattern.MatchString(runID) {
+		return nil, fmt.Errorf("invalid run ID %q for a screenshot gallery", runID)
+	}
+	runDir := filepath.Join(dir, runID)
+	if err := os.MkdirAll(runDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create screenshot gallery: %w", err)
+	}
+	shots, err := readIndex(runDir)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	return &Recorder{dir: runDir, every: every, shots: shots, now: time.Now}, nil
+}
+
+// Action counts an action and takes a screenshot if it is the every-th since the last one
+func (r *Recorder) Action(label string, camera Camera) error {
+	if r == nil {
+		return nil
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.actions++
+	if r.every <= 0 || r.actions < r.every {
+		return nil
+	}
+	r.actions = 0
+	return r.capture(ReasonInterval, label, camera)
+}
+
+// Capture takes a screenshot for reason, such as ReasonTransition. It restarts the count of
+// actions, so an interval screenshot does not follow right after it.
+func (r *Recorder) Capture(reason, label string, camera Camera) error {
+	if r == nil {
+		return nil
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.actions = 0
+	return r.capture(reason, label, camera)
+}
+
+// capture writes a screenshot and the updated index; the caller holds the mutex
+func (r *Recorder) capture(reason, label string, camera Camera) error {
+	png, url, err := camera()
+	if err != nil {
+		return fmt.Errorf("failed to take screenshot: %w", err)
+	}
+
+	shot := Shot{File: fmt.Sprintf("%04d.png", len(r.shots)+1), Reason: reason, Label: label, URL: url, TakenAt: r.now()}
+	if err := os.WriteFile(filepath.Join(r.dir, shot.File), png, 0o644); err != nil {
+		return fmt.Errorf("failed to save screenshot: %w", err)
+	}
+	r.shots = append(r.shots, shot)
+
+	data, err := json.MarshalIndent(r.shots, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode screenshot index: %w", err)
+	}
+	// Written aside and renamed, so readers never see half an index
+	temp := filepath.Join(r.dir, IndexFile+".tmp")
+	if err := os.WriteFile(temp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write screenshot index: %w", err)
+	}
+	if err := os.Rename(temp, filepath.Join(r.dir, IndexFile)); err != nil {
+		return fmt.Errorf("failed to write screenshot index: %w", err)
+	}
+	return nil
+}
+
+// Shots lists the screenshots of a run in the order they were taken
+func Shots(dir, runID string) ([]Shot, error) {
+	if !namePattern.MatchString(runID) {
+		return nil, ErrNotFound
+	}
+	return readIndex(filepath.Join(dir, runID))
+}
+
+// Runs lists the runs with screenshots, latest first
+func Runs(dir string) ([]Run, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read screenshot galleries: %w", err)
+	}
+
+	var runs []Run
+	for _, entry := range entries {
+		if !entry.IsDir() || !namePattern.MatchString(entry.Name()) {
+			continue
+		}
+		shots, err := readIndex(filepath.Join(dir, entry.Name()))
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		if len(shots) == 0 {
+			continue
+		}
+		run := Run{RunID: entry.Name(), Shots: len(shots), First: shots[0].TakenAt, Last: shots[len(shots)-1].TakenAt}
+		for _, shot := range shots {
+			if !slices.Contains(run.Reasons, shot.Reason) {
+				run.Reasons = append(run.Reasons, shot.Reason)
+			}
+		}
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].Last.After(runs[j].Last)
+	})
+	return runs, nil
+}
+
+// Path returns the file of one screenshot of a run, or ErrNotFound for a name the run's index
+// does not list
+func Path(dir, runID, file string) (string, error) {
+	shots, err := Shots(dir, runID)
+	if err != nil {
+		return "", err
+	}
+	for _, shot := range shots {
+		if shot.File == file && namePattern.MatchString(file) {
+			return filepath.Join(dir, runID, file), nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+// Prune deletes the galleries of all but the keep latest runs and returns how many it deleted;
+// keep 0 deletes none
+func Prune(dir string, keep int) (int, error) {
+	if keep <= 0 {
+		return 0, nil
+	}
+	runs, err := Runs(dir)
+	if err != nil || len(runs) <= keep {
+		return 0, err
+	}
+	deleted := 0
+	for _, run := range runs[keep:] {
+		if err := os.RemoveAll(filepath.Join(dir, run.RunID)); err != nil {
+			return deleted, fmt.Errorf("failed to delete screenshots of run %s: %w", run.RunID, err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// readIndex reads the index of a run's directory
+func readIndex(runDir string) ([]Shot, error) {
+	data, err := os.ReadFile(filepath.Join(runDir, IndexFile))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read screenshot index: %w", err)
+	}
+	var shots []Shot
+	if err := json.Unmarshal(data, &shots); err != nil {
+		return nil, fmt.Errorf("failed to parse screenshot index %s: %w", runDir, err)
+	}
+	return shots, nil
+}
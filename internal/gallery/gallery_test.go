@@ -0,0 +1,107 @@
+package gallery
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+// fakeCamera returns a fixed picture of a fixed URL
+func fakeCamera(png string) Camera {
+	return func() ([]byte, string, error) {
+		return []byte(png), "https://www.linkedin.com/in/jane-doe/", nil
+	}
+}
+
+// TestRecorder tests that screenshots are taken every so many actions and on demand, and indexed in order
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir, "2024-06-12-1", 3)
+	if err != nil {
+		t.Fatalf("failed to create recorder: %v", err)
+	}
+	clock := time.Date(2024, 6, 12, 9, 0, 0, 0, time.UTC)
+	recorder.now = func() time.Time {
+		clock = clock.Add(time.Minute)
+		return clock
+	}
+
+	for i := 0; i < 7; i++ {
+		if err := recorder.Action("invite jane", fakeCamera("action")); err != nil {
+			t.Fatalf("action failed: %v", err)
+		}
+	}
+	if err := recorder.Capture(ReasonTransition, "jane invited", fakeCamera("state")); err != nil {
+		t.Fatalf("capture failed: %v", err)
+	}
+	if err := recorder.Action("invite john", fakeCamera("action")); err != nil {
+		t.Fatalf("action failed: %v", err)
+	}
+
+	shots, err := Shots(dir, "2024-06-12-1")
+	if err != nil {
+		t.Fatalf("failed to list shots: %v", err)
+	}
+	if len(shots) != 3 || shots[0].Reason != ReasonInterval || shots[2].Reason != ReasonTransition || shots[2].File != "0003.png" {
+		t.Fatalf("expected two interval shots then the transition, got %+v", shots)
+	}
+	path, err := Path(dir, "2024-06-12-1", "0003.png")
+	if err != nil {
+		t.Fatalf("failed to resolve shot: %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || !bytes.Equal(data, []byte("state")) {
+		t.Errorf("unexpected screenshot %q (%v)", data, err)
+	}
+
+	for _, name := range [][2]string{{"2024-06-12-1", "../index.json"}, {"2024-06-12-1", "index.json"}, {"..", "0001.png"}, {"missing", "0001.png"}} {
+		if _, err := Path(dir, name[0], name[1]); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected %s/%s to be refused, got %v", name[0], name[1], err)
+		}
+	}
+
+	var nilRecorder *Recorder
+	if err := nilRecorder.Action("x", fakeCamera("x")); err != nil {
+		t.Errorf("a nil recorder should take nothing, got %v", err)
+	}
+	if _, err := NewRecorder(dir, "../escape", 1); err == nil {
+		t.Error("expected an unsafe run ID to be refused")
+	}
+}
+
+// TestRunsAndPrune tests that galleries are listed latest first and pruned down to the latest runs
+func TestRunsAndPrune(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2024, 6, 12, 9, 0, 0, 0, time.UTC)
+	for i, runID := range []string{"run-a", "run-b", "run-c"} {
+		recorder, err := NewRecorder(dir, runID, 0)
+		if err != nil {
+			t.Fatalf("failed to create recorder: %v", err)
+		}
+		taken := start.Add(time.Duration(i) * time.Hour)
+		recorder.now = func() time.Time { return taken }
+		if err := recorder.Capture(ReasonFailure, "step failed", fakeCamera("x")); err != nil {
+			t.Fatalf("capture failed: %v", err)
+		}
+	}
+	if err := os.Mkdir(dir+"/empty", 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := Runs(dir)
+	if err != nil {
+		t.Fatalf("failed to list runs: %v", err)
+	}
+	if len(runs) != 3 || runs[0].RunID != "run-c" || runs[0].Shots != 1 || runs[0].Reasons[0] != ReasonFailure {
+		t.Fatalf("expected three runs latest first, got %+v", runs)
+	}
+
+	deleted, err := Prune(dir, 2)
+	if err != nil || deleted != 1 {
+		t.Fatalf("expected one run pruned, got %d (%v)", deleted, err)
+	}
+	if _, err := Shots(dir, "run-a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the oldest run to be gone, got %v", err)
+	}
+}
@@ -0,0 +1,223 @@
+package health
+
+import (
+	"fmt"
+	"time"
+
+	"linkedin-automation-framework/internal/storage"
+)
+
+// Account event types that count against an account's health
+const (
+	EventChallenge = "challenge" // Security checkpoint or verification shown mid-session
+	EventLogout    = "logout"    // Session ended without the tool logging out
+	EventWarning   = "warning"   // Warning banner or modal about the account's activity
+)
+
+// EventSlow records LinkedIn answering slower than the latency objectives. It is informational and
+// costs no health points.
+const EventSlow = "slow"
+
+// AcceptanceDelay leaves recent invites out of the acceptance rate, since most are accepted within a few days
+const AcceptanceDelay = 72 * time.Hour
+
+// Store provides the history health is computed from
+type Store interface {
+	GetSentRequests() ([]storage.ConnectionRequest, error)
+	GetAccountEvents(account string, since time.Time) ([]storage.AccountEvent, error)
+	SaveAccountEvent(event storage.AccountEvent) error
+}
+
+// Record saves an event against the account
+func Record(store Store, account, eventType, detail string, now time.Time) error {
+	return store.SaveAccountEvent(storage.AccountEvent{Account: account, Type: eventType, Detail: detail, At: now})
+}
+
+// Level is how much activity an account is allowed
+type Level string
+
+const (
+	LevelNormal  Level = "normal"
+	LevelReduced Level = "reduced"
+	LevelMinimal Level = "minimal"
+	LevelPaused  Level = "paused"
+)
+
+// Factor is the share of the configured limits an account at this level may use
+func (l Level) Factor() float64 {
+	switch l {
+	case LevelReduced:
+		return 0.5
+	case LevelMinimal:
+		return 0.25
+	case LevelPaused:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// Scale reduces a limit to the level's share, keeping at least 1 unless the account is paused
+func (l Level) Scale(limit int) int {
+	if l == LevelPaused || limit <= 0 {
+		return 0
+	}
+	scaled := int(float64(limit) * l.Factor())
+	if scaled < 1 {
+		return 1
+	}
+	return scaled
+}
+
+// Policy sets the window signals are collected over and the scores at which activity is cut back
+type Policy struct {
+	Window       time.Duration
+	MinInvites   int // Invites needed before the acceptance rate counts
+	ReducedBelow int
+	MinimalBelow int
+	PauseBelow   int
+}
+
+// DefaultPolicy looks back a week and halves activity below 70, quarters it below 50 and pauses below 30
+func DefaultPolicy() Policy {
+	return Policy{
+		Window:       7 * 24 * time.Hour,
+		MinInvites:   20,
+		ReducedBelow: 70,
+		MinimalBelow: 50,
+		PauseBelow:   30,
+	}
+}
+
+// Signals are the account's recent history
+type Signals struct {
+	Invites    int // Invites old enough to have been answered
+	Accepted   int
+	Challenges int
+	Warnings   int
+	Logouts    int
+}
+
+// AcceptanceRate is the share of invites accepted, 0 without invites
+func (s Signals) AcceptanceRate() float64 {
+	if s.Invites == 0 {
+		return 0
+	}
+	return float64(s.Accepted) / float64(s.Invites)
+}
+
+// Collect gathers the account's signals over the window ending at now. Each storage holds one
+// account's connection requests, so invites are read from the whole request history.
+func Collect(store Store, account string, window time.Duration, now time.Time) (Signals, error) {
+	var signals Signals
+	since := now.Add(-window)
+
+	requests, err := store.GetSentRequests()
+	if err != nil {
+		return signals, fmt.Errorf("failed to read connection requests: %w", err)
+	}
+	for _, request := range requests {
+		if request.SentAt.Before(since) || request.SentAt.After(now.Add(-AcceptanceDelay)) {
+			continue
+		}
+		signals.Invites++
+		if request.Status == "accepted" {
+			signals.Accepted++
+		}
+	}
+
+	events, err := store.GetAccountEvents(account, since)
+	if err != nil {
+		return signals, fmt.Errorf("failed to read account events: %w", err)
+	}
+	for _, event := range events {
+		switch event.Type {
+		case EventChallenge:
+			signals.Challenges++
+		case EventWarning:
+			signals.Warnings++
+		case EventLogout:
+			signals.Logouts++
+		}
+	}
+	return signals, nil
+}
+
+// Penalty is what one signal took off the score
+type Penalty struct {
+	Signal string
+	Points int
+	Detail string
+}
+
+// Report is an account's health score and the activity level it allows
+type Report struct {
+	Account   string
+	Score     int // 0 to 100
+	Level     Level
+	Signals   Signals
+	Penalties []Penalty
+}
+
+// Score rates signals from 100 down: low acceptance costs up to 40 points, each challenge 15
+// (at most 45), each warning 20 (at most 60) and each unexpected logout 10 (at most 30)
+func Score(signals Signals, policy Policy) Report {
+	report := Report{Score: 100, Signals: signals}
+	penalize := func(signal string, points int, detail string) {
+		if points <= 0 {
+			return
+		}
+		report.Penalties = append(report.Penalties, Penalty{Signal: signal, Points: points, Detail: detail})
+		report.Score -= points
+	}
+
+	if signals.Invites >= policy.MinInvites && signals.Invites > 0 {
+		rate := signals.AcceptanceRate()
+		detail := fmt.Sprintf("%.0f%% of %d invites accepted", rate*100, signals.Invites)
+		switch {
+		case rate < 0.10:
+			penalize("acceptance", 40, detail)
+		case rate < 0.20:
+			penalize("acceptance", 25, detail)
+		case rate < 0.30:
+			penalize("acceptance", 10, detail)
+		}
+	}
+	penalize("challenges", capped(signals.Challenges*15, 45), fmt.Sprintf("%d security challenges", signals.Challenges))
+	penalize("warnings", capped(signals.Warnings*20, 60), fmt.Sprintf("%d activity warnings", signals.Warnings))
+	penalize("logouts", capped(signals.Logouts*10, 30), fmt.Sprintf("%d unexpected logouts", signals.Logouts))
+
+	if report.Score < 0 {
+		report.Score = 0
+	}
+	switch {
+	case report.Score < policy.PauseBelow:
+		report.Level = LevelPaused
+	case report.Score < policy.MinimalBelow:
+		report.Level = LevelMinimal
+	case report.Score < policy.ReducedBelow:
+		report.Level = LevelReduced
+	default:
+		report.Level = LevelNormal
+	}
+	return report
+}
+
+// Assess collects the account's signals and scores them
+func Assess(store Store, account string, policy Policy, now time.Time) (Report, error) {
+	signals, err := Collect(store, account, policy.Window, now)
+	if err != nil {
+		return Report{}, err
+	}
+	report := Score(signals, policy)
+	report.Account = account
+	return report, nil
+}
+
+// capped limits points to max
+func capped(points, max int) int {
+	if points > max {
+		return max
+	}
+	return points
+}
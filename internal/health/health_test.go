@@ -0,0 +1,194 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/storage"
+)
+
+// TestAssessAccountHealth tests collecting signals from storage and the level they lead to
+func TestAssessAccountHealth(t *testing.T) {
+	for _, storageType := range []string{"sqlite", "json"} {
+		t.Run(storageType, func(t *testing.T) {
+			store, err := storage.NewStorageManager(storage.StorageConfig{Type: storageType, Path: t.TempDir(), Database: "test.db"})
+			if err != nil {
+				t.Fatalf("failed to create storage: %v", err)
+			}
+			defer store.Close()
+
+			now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+			report, err := Assess(store, "main", DefaultPolicy(), now)
+			if err != nil {
+				t.Fatalf("failed to assess a new account: %v", err)
+			}
+			if report.Score != 100 || report.Level != LevelNormal {
+				t.Fatalf("expected a new account to be healthy, got %+v", report)
+			}
+
+			// 20 answered invites with 3 accepted, plus recent and old ones that do not count
+			for i := 0; i < 20; i++ {
+				status := "pending"
+				if i < 3 {
+					status = "accepted"
+				}
+				request := storage.ConnectionRequest{ProfileURL: "https://www.linkedin.com/in/p" + string(rune('a'+i)), SentAt: now.Add(-4 * 24 * time.Hour), Status: status}
+				if err := store.SaveConnectionRequest(request); err != nil {
+					t.Fatalf("failed to save request: %v", err)
+				}
+			}
+			for _, sentAt := range []time.Time{now.Add(-time.Hour), now.Add(-30 * 24 * time.Hour)} {
+				if err := store.SaveConnectionRequest(storage.ConnectionRequest{ProfileURL: "https://www.linkedin.com/in/x" + sentAt.Format("150405"), SentAt: sentAt, Status: "pending"}); err != nil {
+					t.Fatalf("failed to save request: %v", err)
+				}
+			}
+
+			for _, event := range []struct {
+				account, kind string
+				at            time.Time
+			}{
+				{"main", EventChallenge, now.Add(-time.Hour)},
+				{"main", EventLogout, now.Add(-2 * time.Hour)},
+				{"main", EventChallenge, now.Add(-10 * 24 * time.Hour)}, // Outside the window
+				{"other", EventWarning, now.Add(-time.Hour)},            // Another account
+			} {
+				if err := Record(store, event.account, event.kind, "", event.at); err != nil {
+					t.Fatalf("failed to record event: %v", err)
+				}
+			}
+
+			report, err = Assess(store, "main", DefaultPolicy(), now)
+			if err != nil {
+				t.Fatalf("failed to assess: %v", err)
+			}
+			expected := Signals{Invites: 20, Accepted: 3, Challenges: 1, Logouts: 1}
+			if report.Signals != expected {
+				t.Errorf("expected signals %+v, got %+v", expected, report.Signals)
+			}
+			// 15% acceptance costs 25, one challenge 15 and one logout 10
+			if report.Score != 50 || report.Level != LevelReduced || len(report.Penalties) != 3 {
+				t.Errorf("expected a score of 50 at the reduced level, got %+v", report)
+			}
+		})
+	}
+}
+
+// TestScoreLevels tests the thresholds and how levels scale limits
+func TestScoreLevels(t *testing.T) {
+	policy := DefaultPolicy()
+	cases := []struct {
+		signals Signals
+		score   int
+		level   Level
+	}{
+		{Signals{Invites: 10, Accepted: 0}, 100, LevelNormal}, // Too few invites to judge acceptance
+		{Signals{Invites: 40, Accepted: 12}, 100, LevelNormal},
+		{Signals{Invites: 40, Accepted: 10}, 90, LevelNormal},
+		{Signals{Challenges: 2}, 70, LevelNormal},
+		{Signals{Challenges: 2, Logouts: 1}, 60, LevelReduced},
+		{Signals{Warnings: 2, Challenges: 1}, 45, LevelMinimal},
+		{Signals{Invites: 50, Accepted: 2, Warnings: 2}, 20, LevelPaused},
+		{Signals{Challenges: 9, Warnings: 9, Logouts: 9}, 0, LevelPaused},
+	}
+	for _, tc := range cases {
+		report := Score(tc.signals, policy)
+		if report.Score != tc.score || report.Level != tc.level {
+			t.Errorf("expected %d (%s) for %+v, got %d (%s)", tc.score, tc.level, tc.signals, report.Score, report.Level)
+		}
+	}
+
+	if LevelNormal.Scale(10) != 10 || LevelReduced.Scale(10) != 5 || LevelMinimal.Scale(2) != 1 || LevelPaused.Scale(10) != 0 {
+		t.Errorf("unexpected scaled limits")
+	}
+}
+
+// TestKillSwitchHaltsUntilEnabled tests tripping each guardrail and re-enabling by hand
+func TestKillSwitchHaltsUntilEnabled(t *testing.T) {
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "json", Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	guardrails := DefaultGuardrails()
+
+	// Two challenges today and one two days ago stay under the limit
+	for _, at := range []time.Time{now.Add(-48 * time.Hour), now.Add(-2 * time.Hour), now.Add(-time.Hour)} {
+		if err := Record(store, "main", EventChallenge, "", at); err != nil {
+			t.Fatalf("failed to record event: %v", err)
+		}
+	}
+	if reason, err := Trip(store, "main", guardrails, now); err != nil || reason != "" {
+		t.Fatalf("expected no trip, got %q (%v)", reason, err)
+	}
+
+	if err := Record(store, "main", EventChallenge, "", now); err != nil {
+		t.Fatalf("failed to record event: %v", err)
+	}
+	reason, err := Trip(store, "main", guardrails, now)
+	if err != nil || reason != "3 challenges within 24h0m0s" {
+		t.Fatalf("expected the challenge guardrail to trip, got %q (%v)", reason, err)
+	}
+	if err := Check(store, "main"); !errors.Is(err, ErrHalted) {
+		t.Fatalf("expected ErrHalted, got %v", err)
+	}
+	// A halted account is not halted again
+	if reason, _ := Trip(store, "main", guardrails, now.Add(time.Minute)); reason != "" {
+		t.Errorf("expected no second trip while halted, got %q", reason)
+	}
+
+	// Re-enabling forgives the challenges that caused the halt
+	later := now.Add(time.Hour)
+	if err := Enable(store, "main", "checked the account", later); err != nil {
+		t.Fatalf("failed to enable: %v", err)
+	}
+	if err := Check(store, "main"); err != nil {
+		t.Fatalf("expected the account to run again, got %v", err)
+	}
+	if reason, _ := Trip(store, "main", guardrails, later.Add(time.Minute)); reason != "" {
+		t.Errorf("expected old challenges not to trip again, got %q", reason)
+	}
+
+	// Any restricted page halts at once
+	if err := Record(store, "main", EventRestricted, "invitations restricted", later.Add(2*time.Minute)); err != nil {
+		t.Fatalf("failed to record event: %v", err)
+	}
+	if reason, _ := Trip(store, "main", guardrails, later.Add(3*time.Minute)); reason != "restriction detected: invitations restricted" {
+		t.Errorf("expected the restriction guardrail to trip, got %q", reason)
+	}
+}
+
+// TestKillSwitchAcceptance tests the acceptance guardrail over the most recent answered invites
+func TestKillSwitchAcceptance(t *testing.T) {
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "json", Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	guardrails := Guardrails{MinAcceptance: 0.10, AcceptanceInvites: 10}
+	// Older invites were accepted; the 10 most recent answered ones were not
+	for i := 0; i < 15; i++ {
+		status := "pending"
+		if i >= 10 {
+			status = "accepted"
+		}
+		request := storage.ConnectionRequest{
+			ProfileURL: fmt.Sprintf("https://www.linkedin.com/in/p%d", i),
+			SentAt:     now.Add(-AcceptanceDelay - time.Duration(i)*time.Hour),
+			Status:     status,
+		}
+		if err := store.SaveConnectionRequest(request); err != nil {
+			t.Fatalf("failed to save request: %v", err)
+		}
+	}
+
+	reason, err := Trip(store, "main", guardrails, now)
+	if err != nil || reason != "0% acceptance over the last 10 invites" {
+		t.Errorf("expected the acceptance guardrail to trip, got %q (%v)", reason, err)
+	}
+}
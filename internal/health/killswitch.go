@@ -0,0 +1,167 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"linkedin-automation-framework/internal/storage"
+)
+
+// Account event types for restrictions and the kill-switch itself
+const (
+	EventRestricted = "restricted" // Account restricted page detected
+	EventHalted     = "halted"     // A kill-switch stopped all automation for the account
+	EventEnabled    = "enabled"    // Automation re-enabled by hand after a halt
+)
+
+// ErrHalted is returned while a kill-switch has stopped the account
+var ErrHalted = errors.New("automation is halted for this account")
+
+// Guardrails are the conditions that halt an account outright. A zero field disables its condition.
+type Guardrails struct {
+	MaxChallenges     int // Challenges within ChallengeWindow that halt the account
+	ChallengeWindow   time.Duration
+	MinAcceptance     float64 // Acceptance rate below which the account halts
+	AcceptanceInvites int     // Answered invites the acceptance rate is measured over
+	HaltOnRestriction bool    // Halt on any restricted page
+}
+
+// DefaultGuardrails halts on 3 challenges in 24h, under 10% acceptance over 50 invites or any restriction
+func DefaultGuardrails() Guardrails {
+	return Guardrails{
+		MaxChallenges:     3,
+		ChallengeWindow:   24 * time.Hour,
+		MinAcceptance:     0.10,
+		AcceptanceInvites: 50,
+		HaltOnRestriction: true,
+	}
+}
+
+// Halt is the kill-switch state of an account
+type Halt struct {
+	Halted bool
+	Reason string
+	At     time.Time
+}
+
+// Status reports whether the account is halted. Only the latest halt or re-enable counts.
+func Status(store Store, account string) (Halt, error) {
+	events, err := store.GetAccountEvents(account, time.Time{})
+	if err != nil {
+		return Halt{}, fmt.Errorf("failed to read account events: %w", err)
+	}
+	var halt Halt
+	for _, event := range events {
+		switch event.Type {
+		case EventHalted:
+			halt = Halt{Halted: true, Reason: event.Detail, At: event.At}
+		case EventEnabled:
+			halt = Halt{}
+		}
+	}
+	return halt, nil
+}
+
+// Check returns ErrHalted, wrapped with the reason, if the account is halted
+func Check(store Store, account string) error {
+	halt, err := Status(store, account)
+	if err != nil {
+		return err
+	}
+	if halt.Halted {
+		return fmt.Errorf("%w since %s: %s", ErrHalted, halt.At.Format(time.RFC3339), halt.Reason)
+	}
+	return nil
+}
+
+// Enable lifts a halt; conditions are then judged only on what happens afterwards
+func Enable(store Store, account, note string, now time.Time) error {
+	return Record(store, account, EventEnabled, note, now)
+}
+
+// Trip evaluates the guardrails and halts the account if one is breached. It returns the reason,
+// empty when nothing tripped or the account was already halted. Only events and invites after
+// the last re-enable count, so an account is not halted again for what was already dealt with.
+func Trip(store Store, account string, guardrails Guardrails, now time.Time) (string, error) {
+	events, err := store.GetAccountEvents(account, time.Time{})
+	if err != nil {
+		return "", fmt.Errorf("failed to read account events: %w", err)
+	}
+	halted, enabledAt := false, time.Time{}
+	for _, event := range events {
+		switch event.Type {
+		case EventHalted:
+			halted = true
+		case EventEnabled:
+			halted, enabledAt = false, event.At
+		}
+	}
+	if halted {
+		return "", nil
+	}
+
+	reason, err := breach(store, events, enabledAt, guardrails, now)
+	if err != nil || reason == "" {
+		return "", err
+	}
+	if err := Record(store, account, EventHalted, reason, now); err != nil {
+		return "", err
+	}
+	return reason, nil
+}
+
+// breach returns the first guardrail breached after since
+func breach(store Store, events []storage.AccountEvent, since time.Time, guardrails Guardrails, now time.Time) (string, error) {
+	challengeSince := since
+	if start := now.Add(-guardrails.ChallengeWindow); start.After(challengeSince) {
+		challengeSince = start
+	}
+	challenges := 0
+	for _, event := range events {
+		if event.At.Before(since) {
+			continue
+		}
+		if event.Type == EventRestricted && guardrails.HaltOnRestriction {
+			return "restriction detected: " + event.Detail, nil
+		}
+		if event.Type == EventChallenge && !event.At.Before(challengeSince) {
+			challenges++
+		}
+	}
+	if guardrails.MaxChallenges > 0 && challenges >= guardrails.MaxChallenges {
+		return fmt.Sprintf("%d challenges within %s", challenges, guardrails.ChallengeWindow), nil
+	}
+
+	if guardrails.AcceptanceInvites <= 0 || guardrails.MinAcceptance <= 0 {
+		return "", nil
+	}
+	requests, err := store.GetSentRequests()
+	if err != nil {
+		return "", fmt.Errorf("failed to read connection requests: %w", err)
+	}
+	var answered []storage.ConnectionRequest
+	for _, request := range requests {
+		if request.SentAt.Before(since) || request.SentAt.After(now.Add(-AcceptanceDelay)) {
+			continue
+		}
+		answered = append(answered, request)
+	}
+	if len(answered) < guardrails.AcceptanceInvites {
+		return "", nil
+	}
+	// Measure over the most recent answered invites
+	sort.SliceStable(answered, func(i, j int) bool { return answered[i].SentAt.After(answered[j].SentAt) })
+	accepted := 0
+	for _, request := range answered[:guardrails.AcceptanceInvites] {
+		if request.Status == "accepted" {
+			accepted++
+		}
+	}
+	rate := float64(accepted) / float64(guardrails.AcceptanceInvites)
+	if rate < guardrails.MinAcceptance {
+		return fmt.Sprintf("%.0f%% acceptance over the last %d invites", rate*100, guardrails.AcceptanceInvites), nil
+	}
+	return "", nil
+}
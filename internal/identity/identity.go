@@ -0,0 +1,144 @@
+package identity
+
+import (
+	"net/url"
+	"strings"
+)
+
+// companySuffixes are legal-form words ignored when comparing company names
+var companySuffixes = map[string]bool{
+	"inc": true, "llc": true, "ltd": true, "limited": true, "corp": true,
+	"corporation": true, "co": true, "company": true, "gmbh": true, "plc": true,
+}
+
+// CanonicalProfilePrefix starts every URL returned by NormalizeProfileURL
+const CanonicalProfilePrefix = "https://www.linkedin.com/in/"
+
+// NormalizeProfileURL returns the canonical form of a LinkedIn profile link,
+// https://www.linkedin.com/in/<slug>/ with a lowercase slug, so links extracted from search,
+// connection and messaging pages match each other and stored data. Relative links, regional
+// subdomains, prefixes before /in/, locale suffixes, query strings (tracking parameters such as
+// miniProfileUrn or trk), fragments and case are all dropped. Anything that is not a LinkedIn
+// profile link is returned unchanged.
+func NormalizeProfileURL(profileURL string) string {
+	slug, ok := profileSlug(profileURL)
+	if !ok {
+		return profileURL
+	}
+	return CanonicalProfilePrefix + url.PathEscape(slug) + "/"
+}
+
+// ProfileKey reduces a profile URL to its /in/ slug so variants of the same link compare equal,
+// ignoring everything NormalizeProfileURL drops as well as percent-encoding.
+// Other URLs are returned lowercased without their trailing slash.
+func ProfileKey(profileURL string) string {
+	if slug, ok := profileSlug(profileURL); ok {
+		return "in/" + slug
+	}
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(profileURL), "/"))
+}
+
+// profileSlug extracts the decoded, lowercase slug following /in/ in a LinkedIn or relative URL
+func profileSlug(profileURL string) (string, bool) {
+	parsed, err := url.Parse(strings.TrimSpace(profileURL))
+	if err != nil {
+		return "", false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if host != "" && host != "linkedin.com" && !strings.HasSuffix(host, ".linkedin.com") {
+		return "", false
+	}
+	if host == "" && !strings.HasPrefix(parsed.Path, "/") {
+		return "", false
+	}
+
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] == "in" && segments[i+1] != "" {
+			return strings.ToLower(segments[i+1]), true
+		}
+	}
+	return "", false
+}
+
+// NormalizeName lowercases a display name and drops credentials, nicknames and punctuation,
+// so "Jane Doe, PhD" and "jane (JD) doe" both become "jane doe"
+func NormalizeName(name string) string {
+	if i := strings.Index(name, ","); i >= 0 {
+		name = name[:i]
+	}
+	for {
+		open := strings.Index(name, "(")
+		closing := strings.Index(name, ")")
+		if open < 0 || closing < open {
+			break
+		}
+		name = name[:open] + " " + name[closing+1:]
+	}
+	return strings.Join(words(name), " ")
+}
+
+// NormalizeCompany lowercases a company name and drops punctuation and legal-form suffixes
+func NormalizeCompany(company string) string {
+	var kept []string
+	for _, word := range words(company) {
+		if !companySuffixes[word] {
+			kept = append(kept, word)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// PersonKey combines the normalized name and company; it is empty unless both are known,
+// since a name alone is too common to identify someone
+func PersonKey(name, company string) string {
+	name = NormalizeName(name)
+	company = NormalizeCompany(company)
+	if name == "" || company == "" {
+		return ""
+	}
+	return name + "|" + company
+}
+
+// Deduper remembers people by profile URL and by name and company
+type Deduper struct {
+	urls   map[string]bool
+	people map[string]bool
+}
+
+// NewDeduper creates an empty deduper
+func NewDeduper() *Deduper {
+	return &Deduper{
+		urls:   make(map[string]bool),
+		people: make(map[string]bool),
+	}
+}
+
+// Contains reports whether the person was already added under any URL variant or the same name and company
+func (d *Deduper) Contains(profileURL, name, company string) bool {
+	if profileURL != "" && d.urls[ProfileKey(profileURL)] {
+		return true
+	}
+	key := PersonKey(name, company)
+	return key != "" && d.people[key]
+}
+
+// Add records the person and reports whether they were new
+func (d *Deduper) Add(profileURL, name, company string) bool {
+	seen := d.Contains(profileURL, name, company)
+	if profileURL != "" {
+		d.urls[ProfileKey(profileURL)] = true
+	}
+	if key := PersonKey(name, company); key != "" {
+		d.people[key] = true
+	}
+	return !seen
+}
+
+// words splits text into lowercase letter and digit runs, keeping combining marks and joiners
+// with the letters they belong to
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(StripFormatting(text)), func(r rune) bool {
+		return !isWordRune(r) && r != '\u200C' && r != '\u200D'
+	})
+}
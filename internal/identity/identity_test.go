@@ -0,0 +1,102 @@
+package identity
+
+import "testing"
+
+// TestProfileKeyIgnoresURLVariants tests that variants of the same profile link share a key
+func TestProfileKeyIgnoresURLVariants(t *testing.T) {
+	variants := []string{
+		"https://www.linkedin.com/in/jane-doe",
+		"https://www.linkedin.com/in/jane-doe/",
+		"https://linkedin.com/in/Jane-Doe?miniProfileUrn=urn%3Ali%3Afs_miniProfile%3AACoAA",
+		"https://de.linkedin.com/in/jane-doe/de",
+		"http://www.linkedin.com/in/jane-doe/?locale=fr_FR#experience",
+		"/in/jane-doe/",
+	}
+	for _, variant := range variants {
+		if key := ProfileKey(variant); key != "in/jane-doe" {
+			t.Errorf("ProfileKey(%q) = %q, expected in/jane-doe", variant, key)
+		}
+	}
+
+	if ProfileKey("https://www.linkedin.com/in/j%C3%A9r%C3%B4me-dupont") != ProfileKey("https://www.linkedin.com/in/jérôme-dupont/") {
+		t.Errorf("percent-encoded slugs should match their decoded form")
+	}
+	if ProfileKey("https://www.linkedin.com/in/jane-doe") == ProfileKey("https://www.linkedin.com/in/jane-doe-2") {
+		t.Errorf("different slugs must not match")
+	}
+}
+
+// TestDeduperMatchesNameAndCompany tests fuzzy duplicate detection by URL or by name and company
+func TestDeduperMatchesNameAndCompany(t *testing.T) {
+	seen := NewDeduper()
+	if !seen.Add("https://www.linkedin.com/in/jane-doe", "Jane Doe", "Acme Inc.") {
+		t.Fatalf("first profile should be new")
+	}
+
+	cases := []struct {
+		url, name, company string
+		duplicate          bool
+	}{
+		{"https://www.linkedin.com/in/jane-doe/?trk=people", "", "", true},
+		{"https://www.linkedin.com/in/ACoAAB12xyz", "Jane Doe, MBA", "ACME", true},
+		{"https://www.linkedin.com/in/ACoAAB12xyz", "jane doe", "", false},
+		{"https://www.linkedin.com/in/john-doe", "John Doe", "Acme", false},
+	}
+	for _, c := range cases {
+		if got := seen.Contains(c.url, c.name, c.company); got != c.duplicate {
+			t.Errorf("Contains(%q, %q, %q) = %v, expected %v", c.url, c.name, c.company, got, c.duplicate)
+		}
+	}
+}
+
+// TestNormalizeProfileURL tests canonicalizing extracted profile links
+func TestNormalizeProfileURL(t *testing.T) {
+	cases := map[string]string{
+		"/in/jane-doe?miniProfileUrn=urn%3Ali%3Afs_miniProfile%3AACoAA":   "https://www.linkedin.com/in/jane-doe/",
+		"https://linkedin.com/in/Jane-Doe":                                "https://www.linkedin.com/in/jane-doe/",
+		"https://uk.linkedin.com/in/jane-doe/en?trk=public_profile#about": "https://www.linkedin.com/in/jane-doe/",
+		"https://www.linkedin.com/in/j%C3%A9r%C3%B4me-dupont/":            "https://www.linkedin.com/in/j%C3%A9r%C3%B4me-dupont/",
+		"https://www.linkedin.com/company/acme/":                          "https://www.linkedin.com/company/acme/",
+		"https://example.com/in/jane-doe":                                 "https://example.com/in/jane-doe",
+		"not a url":                                                       "not a url",
+	}
+	for raw, expected := range cases {
+		if normalized := NormalizeProfileURL(raw); normalized != expected {
+			t.Errorf("NormalizeProfileURL(%q) = %q, expected %q", raw, normalized, expected)
+		}
+		// Normalizing is idempotent
+		if again := NormalizeProfileURL(expected); again != expected {
+			t.Errorf("NormalizeProfileURL(%q) = %q, expected it unchanged", expected, again)
+		}
+	}
+}
+
+func TestParseName(t *testing.T) {
+	cases := map[string]PersonName{
+		"Dr. Jane Doe, PMP 🚀":          {First: "Jane", Last: "Doe"},
+		"Prof. Dr. Hans Müller":        {First: "Hans", Last: "Müller"},
+		"JEAN-PAUL O'NEIL MBA":         {First: "Jean-Paul", Last: "O'Neil"},
+		"jane van doe":                 {First: "Jane", Last: "Van Doe"},
+		"Jane van Doe":                 {First: "Jane", Last: "van Doe"},
+		`Robert "Bob" Smith Jr.`:       {First: "Robert", Last: "Smith"},
+		"María José (she/her) García":  {First: "María", Last: "José García"},
+		"✨ Sam ✨ | Hiring engineers":   {First: "Sam"},
+		"🚀🚀":                           {},
+		"\u2068محمد عبد الله\u2069":    {First: "محمد", Last: "عبد الله"},
+		"王小明":                          {First: "王小明"},
+		"Jose\u0301 Garci\u0301a, PhD": {First: "Jose\u0301", Last: "Garci\u0301a"},
+		"نیما\u200cرضایی 👩\u200d💻":     {First: "نیما\u200cرضایی"},
+	}
+	for display, expected := range cases {
+		if parsed := ParseName(display); parsed != expected {
+			t.Errorf("ParseName(%q) = %+v, expected %+v", display, parsed, expected)
+		}
+	}
+
+	if greeting := Salutation("Dr. Jane Doe, PMP 🚀", "there"); greeting != "Jane" {
+		t.Errorf("expected Jane, got %q", greeting)
+	}
+	if greeting := Salutation("🚀", "there"); greeting != "there" {
+		t.Errorf("expected the fallback, got %q", greeting)
+	}
+}
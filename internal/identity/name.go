@@ -0,0 +1,109 @@
+package identity
+
+import (
+	"strings"
+	"unicode"
+)
+
+// honorifics are titles that can precede a name, compared lowercase without the trailing dot
+var honorifics = map[string]bool{
+	"dr": true, "mr": true, "mrs": true, "ms": true, "miss": true, "mx": true, "prof": true,
+	"professor": true, "sir": true, "dame": true, "rev": true, "herr": true, "frau": true,
+	"dipl.-ing": true, "ing": true, "mag": true, "dott": true, "dra": true, "sr": true,
+	"sra": true, "mme": true, "mlle": true,
+}
+
+// credentials are degrees, certifications and generational suffixes that can follow a name
+// without a comma, compared lowercase without dots
+var credentials = map[string]bool{
+	"phd": true, "mba": true, "pmp": true, "cpa": true, "cfa": true, "md": true, "msc": true,
+	"bsc": true, "meng": true, "csm": true, "cissp": true, "pe": true, "esq": true, "shrm-cp": true,
+	"jr": true, "sr": true, "ii": true, "iii": true, "iv": true,
+}
+
+// PersonName is a display name split into the parts a message addresses someone by
+type PersonName struct {
+	First string
+	Last  string
+}
+
+// ParseName splits a display name as scraped from a card into first and last name, dropping
+// honorifics, credentials, nicknames, emoji and other symbols, so "Dr. Jane Doe, PMP 🚀"
+// becomes Jane and Doe. A name written entirely in upper or lower case is capitalized.
+func ParseName(display string) PersonName {
+	display = StripFormatting(display)
+	if i := strings.IndexAny(display, ",|"); i >= 0 {
+		display = display[:i]
+	}
+	display = dropEnclosed(dropEnclosed(dropEnclosed(display, '(', ')'), '"', '"'), '“', '”')
+
+	var words []string
+	for _, word := range strings.Fields(strings.Map(nameRune, display)) {
+		if word = strings.Trim(word, "-'’\u200C\u200D"); word != "" && word != "." {
+			words = append(words, word)
+		}
+	}
+	for len(words) > 1 && honorifics[strings.TrimSuffix(strings.ToLower(words[0]), ".")] {
+		words = words[1:]
+	}
+	for len(words) > 1 && credentials[strings.ToLower(strings.ReplaceAll(words[len(words)-1], ".", ""))] {
+		words = words[:len(words)-1]
+	}
+	if len(words) == 0 {
+		return PersonName{}
+	}
+
+	if joined := strings.Join(words, " "); joined == strings.ToUpper(joined) || joined == strings.ToLower(joined) {
+		for i, word := range words {
+			words[i] = capitalize(word)
+		}
+	}
+	return PersonName{First: words[0], Last: strings.Join(words[1:], " ")}
+}
+
+// Salutation returns the name to greet someone by, "Hi Jane", or fallback when the display
+// name has nothing usable, e.g. only emoji
+func Salutation(display, fallback string) string {
+	if first := ParseName(display).First; first != "" {
+		return first
+	}
+	return fallback
+}
+
+// nameRune keeps letters, combining marks, joiners and the punctuation names contain, and
+// turns everything else, such as emoji and symbols, into spaces
+func nameRune(r rune) rune {
+	if unicode.IsLetter(r) || unicode.IsMark(r) || strings.ContainsRune("-'’.\u200C\u200D", r) {
+		return r
+	}
+	return ' '
+}
+
+// dropEnclosed removes every part of text between open and closing, e.g. a nickname
+func dropEnclosed(text string, open, closing rune) string {
+	for {
+		start := strings.IndexRune(text, open)
+		if start < 0 {
+			return text
+		}
+		end := strings.IndexRune(text[start+len(string(open)):], closing)
+		if end < 0 {
+			return text
+		}
+		text = text[:start] + " " + text[start+len(string(open))+end+len(string(closing)):]
+	}
+}
+
+// capitalize upper-cases the first letter of each hyphen or apostrophe separated part and
+// lower-cases the rest, so JEAN-PAUL becomes Jean-Paul
+func capitalize(word string) string {
+	runes := []rune(strings.ToLower(word))
+	start := true
+	for i, r := range runes {
+		if start && unicode.IsLetter(r) {
+			runes[i] = unicode.ToUpper(r)
+		}
+		start = r == '-' || r == '\'' || r == '’'
+	}
+	return string(runes)
+}
@@ -0,0 +1,29 @@
+package identity
+
+import (
+	"strings"
+	"unicode"
+)
+
+// StripFormatting removes the invisible characters pages wrap text in, such as the bidi
+// isolates around a right-to-left name, zero-width spaces, soft hyphens and byte order marks,
+// and replaces invalid UTF-8. Zero-width joiners and non-joiners are kept, since Persian,
+// Indic scripts and emoji sequences need them.
+func StripFormatting(text string) string {
+	text = strings.ToValidUTF8(text, "\uFFFD")
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\u200C' || r == '\u200D':
+			return r
+		case unicode.Is(unicode.Bidi_Control, r), r == '\u200B', r == '\u00AD', r == '\uFEFF', r == '\u2060':
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// isWordRune reports whether r belongs inside a word: letters, digits and the combining marks
+// that decomposed accents and many Indic and Arabic vowels are written with
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
@@ -0,0 +1,234 @@
+// Package pipedrive creates Pipedrive persons and deals for leads who replied positively
+package pipedrive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultDealTitle is the deal title used when none is configured
+const DefaultDealTitle = "LinkedIn: {name}"
+
+// Config holds the company account and where deals are created
+type Config struct {
+	BaseURL    string // Company domain, e.g. https://acme.pipedrive.com
+	APIToken   string // Personal API token of the user deals are created for
+	PipelineID int    // Pipeline deals are created in, 0 for the default pipeline
+	StageID    int    // Stage deals start in, 0 for the pipeline's first stage
+	DealTitle  string // Deal title, with {name} and {company} replaced; DefaultDealTitle if empty
+}
+
+// Reply is a lead's positive reply as recorded in Pipedrive
+type Reply struct {
+	ProfileURL string
+	Name       string
+	Email      string
+	Title      string
+	Company    string
+	Campaign   string // Campaign the lead replied in, "" if not from a campaign
+	Excerpt    string // The conversation excerpt attached to the deal as a note
+}
+
+// Result is what recording a reply created
+type Result struct {
+	PersonID      int
+	PersonCreated bool // Whether the person was created rather than found by email or name
+	DealID        int
+	NoteID        int
+}
+
+// Client talks to the Pipedrive v1 API
+type Client struct {
+	config Config
+	http   *http.Client
+}
+
+// New validates the configuration and creates a client; a nil httpClient uses one with a 30s timeout
+func New(config Config, httpClient *http.Client) (*Client, error) {
+	if config.BaseURL == "" || config.APIToken == "" {
+		return nil, fmt.Errorf("pipedrive needs a company domain and an API token")
+	}
+	if config.PipelineID < 0 || config.StageID < 0 {
+		return nil, fmt.Errorf("pipedrive pipeline and stage IDs cannot be negative")
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+	if config.DealTitle == "" {
+		config.DealTitle = DefaultDealTitle
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Client{config: config, http: httpClient}, nil
+}
+
+// RecordReply finds or creates the person, opens a deal for them and attaches the excerpt as a
+// note on the deal. A person is matched by email when known, otherwise by exact name, so
+// recording a second reply from the same lead does not duplicate them.
+func (c *Client) RecordReply(ctx context.Context, reply Reply) (Result, error) {
+	if strings.TrimSpace(reply.Name) == "" {
+		return Result{}, fmt.Errorf("reply from %s has no name to create a person with", reply.ProfileURL)
+	}
+
+	var result Result
+	personID, err := c.findPerson(ctx, reply)
+	if err != nil {
+		return Result{}, err
+	}
+	if personID == 0 {
+		person := map[string]any{"name": reply.Name}
+		if reply.Email != "" {
+			person["email"] = []map[string]any{{"value": reply.Email, "primary": true, "label": "work"}}
+		}
+		if personID, err = c.create(ctx, "persons", person); err != nil {
+			return Result{}, fmt.Errorf("failed to create person %s: %w", reply.Name, err)
+		}
+		result.PersonCreated = true
+	}
+	result.PersonID = personID
+
+	deal := map[string]any{"title": c.DealTitle(reply), "person_id": personID}
+	if c.config.PipelineID > 0 {
+		deal["pipeline_id"] = c.config.PipelineID
+	}
+	if c.config.StageID > 0 {
+		deal["stage_id"] = c.config.StageID
+	}
+	if result.DealID, err = c.create(ctx, "deals", deal); err != nil {
+		return result, fmt.Errorf("failed to create deal for %s: %w", reply.Name, err)
+	}
+
+	note := map[string]any{"content": Note(reply), "deal_id": result.DealID, "person_id": personID}
+	if result.NoteID, err = c.create(ctx, "notes", note); err != nil {
+		return result, fmt.Errorf("failed to attach the conversation to deal %d: %w", result.DealID, err)
+	}
+	return result, nil
+}
+
+// DealTitle returns the title of the deal opened for a reply
+func (c *Client) DealTitle(reply Reply) string {
+	title := strings.NewReplacer("{name}", reply.Name, "{company}", reply.Company).Replace(c.config.DealTitle)
+	return strings.TrimSpace(title)
+}
+
+// Note returns the HTML note attached to a reply's deal: the excerpt, then where it came from
+func Note(reply Reply) string {
+	var note strings.Builder
+	for _, line := range strings.Split(strings.TrimSpace(reply.Excerpt), "\n") {
+		note.WriteString("<p>" + html.EscapeString(strings.TrimSpace(line)) + "</p>")
+	}
+	var about []string
+	if reply.Title != "" {
+		about = append(about, html.EscapeString(reply.Title))
+	}
+	if reply.Campaign != "" {
+		about = append(about, "campaign "+html.EscapeString(reply.Campaign))
+	}
+	if reply.ProfileURL != "" {
+		link := html.EscapeString(reply.ProfileURL)
+		about = append(about, `<a href="`+link+`">`+link+`</a>`)
+	}
+	if len(about) > 0 {
+		note.WriteString("<p>LinkedIn reply — " + strings.Join(about, " · ") + "</p>")
+	}
+	return note.String()
+}
+
+// findPerson returns the ID of the person matching the reply's email, or its exact name when
+// there is no email, and 0 when there is none
+func (c *Client) findPerson(ctx context.Context, reply Reply) (int, error) {
+	query := url.Values{"term": {reply.Name}, "fields": {"name"}, "exact_match": {"true"}, "limit": {"1"}}
+	if reply.Email != "" {
+		query = url.Values{"term": {reply.Email}, "fields": {"email"}, "exact_match": {"true"}, "limit": {"1"}}
+	}
+	var found struct {
+		Items []struct {
+			Item struct {
+				ID int `json:"id"`
+			} `json:"item"`
+		} `json:"items"`
+	}
+	if err := c.call(ctx, http.MethodGet, "persons/search", query, nil, &found); err != nil {
+		return 0, fmt.Errorf("failed to search persons: %w", err)
+	}
+	if len(found.Items) == 0 {
+		return 0, nil
+	}
+	return found.Items[0].Item.ID, nil
+}
+
+// create posts an object and returns its ID
+func (c *Client) create(ctx context.Context, object string, fields map[string]any) (int, error) {
+	var created struct {
+		ID int `json:"id"`
+	}
+	if err := c.call(ctx, http.MethodPost, object, nil, fields, &created); err != nil {
+		return 0, err
+	}
+	if created.ID == 0 {
+		return 0, fmt.Errorf("pipedrive returned no %s ID", object)
+	}
+	return created.ID, nil
+}
+
+// call makes an API request and decodes the response's data into out
+func (c *Client) call(ctx context.Context, method, path string, query url.Values, body any, out any) error {
+	endpoint := c.config.BaseURL + "/api/v1/" + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	var payload io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		payload = bytes.NewReader(encoded)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, endpoint, payload)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	// The token goes in a header rather than the query, so it never shows up in logged URLs
+	request.Header.Set("x-api-token", c.config.APIToken)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return fmt.Errorf("pipedrive request failed: %w", err)
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read pipedrive response: %w", err)
+	}
+
+	var envelope struct {
+		Success bool            `json:"success"`
+		Error   string          `json:"error"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil || !envelope.Success || response.StatusCode >= 300 {
+		message := envelope.Error
+		if message == "" {
+			message = strings.TrimSpace(string(data))
+		}
+		return fmt.Errorf("pipedrive %s %s returned %d: %s", method, path, response.StatusCode, message)
+	}
+	if len(envelope.Data) == 0 || string(envelope.Data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("failed to decode pipedrive response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package pipedrive
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestRecordReplyCreatesPersonDealAndNote tests that a new lead gets a person, a deal in the
+// configured stage and the excerpt as a note, and that a known email reuses the person
+func TestRecordReplyCreatesPersonDealAndNote(t *testing.T) {
+	created := map[string][]map[string]any{}
+	known := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-token") != "token" || r.URL.Query().Get("api_token") != "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"success":false,"error":"unauthorized access"}`))
+			return
+		}
+		path := strings.TrimPrefix(r.URL.Path, "/api/v1/")
+		if path == "persons/search" {
+			items := []map[string]any{}
+			if id, ok := known[r.URL.Query().Get("term")]; ok && r.URL.Query().Get("fields") == "email" {
+				items = append(items, map[string]any{"item": map[string]any{"id": id}})
+			}
+			json.NewEncoder(w).Encode(map[string]any{"success": true, "data": map[string]any{"items": items}})
+			return
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		created[path] = append(created[path], body)
+		id := 100*len(created) + len(created[path])
+		if path == "persons" {
+			known["jane@example.com"] = id
+		}
+		json.NewEncoder(w).Encode(map[string]any{"success": true, "data": map[string]any{"id": id}})
+	}))
+	defer server.Close()
+
+	client, err := New(Config{BaseURL: server.URL + "/", APIToken: "token", StageID: 7, DealTitle: "LinkedIn: {name} ({company})"}, server.Client())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	reply := Reply{
+		ProfileURL: "https://www.linkedin.com/in/jane-doe/",
+		Name:       "Jane Doe",
+		Email:      "jane@example.com",
+		Company:    "Acme",
+		Campaign:   "spring-outreach",
+		Excerpt:    "Sounds great <3\nLet's talk next week",
+	}
+
+	first, err := client.RecordReply(context.Background(), reply)
+	if err != nil {
+		t.Fatalf("RecordReply failed: %v", err)
+	}
+	if !first.PersonCreated || first.DealID == 0 || first.NoteID == 0 {
+		t.Errorf("expected a new person, deal and note, got %+v", first)
+	}
+	deal := created["deals"][0]
+	if deal["title"] != "LinkedIn: Jane Doe (Acme)" || deal["stage_id"] != float64(7) || deal["person_id"] != float64(first.PersonID) {
+		t.Errorf("unexpected deal %v", deal)
+	}
+	if _, ok := deal["pipeline_id"]; ok {
+		t.Errorf("expected the default pipeline, got %v", deal["pipeline_id"])
+	}
+	note := created["notes"][0]["content"].(string)
+	if !strings.Contains(note, "<p>Sounds great &lt;3</p><p>Let&#39;s talk next week</p>") || !strings.Contains(note, "campaign spring-outreach") {
+		t.Errorf("expected the escaped excerpt and campaign in the note, got %q", note)
+	}
+
+	second, err := client.RecordReply(context.Background(), reply)
+	if err != nil {
+		t.Fatalf("second RecordReply failed: %v", err)
+	}
+	if second.PersonCreated || second.PersonID != first.PersonID || len(created["persons"]) != 1 || len(created["deals"]) != 2 {
+		t.Errorf("expected the person reused for a second deal, got %+v with %d persons", second, len(created["persons"]))
+	}
+
+	bad, _ := New(Config{BaseURL: server.URL, APIToken: "wrong"}, server.Client())
+	if _, err := bad.RecordReply(context.Background(), reply); err == nil || !strings.Contains(err.Error(), "unauthorized access") {
+		t.Errorf("expected the API error to be reported, got %v", err)
+	}
+	if _, err := New(Config{BaseURL: server.URL}, nil); err == nil {
+		t.Error("expected a missing API token to be rejected")
+	}
+}
@@ -0,0 +1,301 @@
+// Package salesforce creates and updates Salesforce Leads or Contacts for accepted connections
+package salesforce
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"linkedin-automation-framework/internal/identity"
+)
+
+// Objects a connection can be synced to
+const (
+	ObjectLead    = "Lead"
+	ObjectContact = "Contact"
+)
+
+// DefaultAPIVersion is the REST API version used when none is configured
+const DefaultAPIVersion = "v60.0"
+
+// Config holds the connected app's credentials and how records map onto Salesforce fields
+type Config struct {
+	LoginURL        string            // My Domain URL the token is requested from, e.g. https://acme.my.salesforce.com
+	ClientID        string            // Connected app consumer key
+	ClientSecret    string            // Connected app consumer secret
+	APIVersion      string            // REST API version, DefaultAPIVersion if empty
+	Object          string            // ObjectLead (default) or ObjectContact
+	ExternalIDField string            // External ID field holding the profile's public ID, e.g. LinkedIn_ID__c
+	Fields          map[string]string // Salesforce field -> record field, or "=text" for a fixed value
+}
+
+// Record is an accepted connection as synced to Salesforce
+type Record struct {
+	ProfileURL  string
+	FirstName   string
+	LastName    string
+	Email       string
+	Title       string
+	Company     string
+	Location    string
+	ConnectedOn time.Time
+	Campaign    string // Campaign whose invite the connection accepted, "" if not from a campaign
+}
+
+// PublicID returns the profile's public identifier, the slug after /in/ in its URL. It is the
+// external ID records are matched by, since Salesforce cannot take a URL in an upsert path.
+func (r Record) PublicID() string {
+	return strings.TrimPrefix(identity.ProfileKey(r.ProfileURL), "in/")
+}
+
+// recordFields reads each record field a mapping can name
+var recordFields = map[string]func(Record) string{
+	"profile_url": func(r Record) string { return r.ProfileURL },
+	"public_id":   Record.PublicID,
+	"first_name":  func(r Record) string { return r.FirstName },
+	"last_name":   func(r Record) string { return r.LastName },
+	"name":        func(r Record) string { return strings.TrimSpace(r.FirstName + " " + r.LastName) },
+	"email":       func(r Record) string { return r.Email },
+	"title":       func(r Record) string { return r.Title },
+	"company":     func(r Record) string { return r.Company },
+	"location":    func(r Record) string { return r.Location },
+	"campaign":    func(r Record) string { return r.Campaign },
+	"connected_on": func(r Record) string {
+		if r.ConnectedOn.IsZero() {
+			return ""
+		}
+		return r.ConnectedOn.Format("2006-01-02")
+	},
+}
+
+// DefaultFields maps the standard fields of each object when no mapping is configured
+var DefaultFields = map[string]map[string]string{
+	ObjectLead: {
+		"FirstName":  "first_name",
+		"LastName":   "last_name",
+		"Email":      "email",
+		"Title":      "title",
+		"Company":    "company",
+		"LeadSource": "=LinkedIn",
+	},
+	ObjectContact: {
+		"FirstName":  "first_name",
+		"LastName":   "last_name",
+		"Email":      "email",
+		"Title":      "title",
+		"LeadSource": "=LinkedIn",
+	},
+}
+
+// Result is what an upsert did
+type Result struct {
+	ID      string // Salesforce record ID
+	Created bool   // Whether the record was created rather than updated
+}
+
+// Client upserts records through the REST API, authenticating with the OAuth client
+// credentials flow. It is safe for concurrent use.
+type Client struct {
+	config Config
+	http   *http.Client
+
+	mutex       sync.Mutex
+	token       string
+	instanceURL string
+}
+
+// New validates the configuration and creates a client; a nil httpClient uses one with a 30s timeout
+func New(config Config, httpClient *http.Client) (*Client, error) {
+	if config.LoginURL == "" || config.ClientID == "" || config.ClientSecret == "" {
+		return nil, fmt.Errorf("salesforce needs a login URL, client ID and client secret")
+	}
+	if config.ExternalIDField == "" {
+		return nil, fmt.Errorf("salesforce needs an external ID field to match connections by public profile ID")
+	}
+	if config.Object == "" {
+		config.Object = ObjectLead
+	}
+	if config.Object != ObjectLead && config.Object != ObjectContact {
+		return nil, fmt.Errorf("salesforce object must be %s or %s, got %q", ObjectLead, ObjectContact, config.Object)
+	}
+	if config.APIVersion == "" {
+		config.APIVersion = DefaultAPIVersion
+	}
+	if len(config.Fields) == 0 {
+		config.Fields = DefaultFields[config.Object]
+	}
+	for field, source := range config.Fields {
+		if strings.HasPrefix(source, "=") {
+			continue
+		}
+		if _, ok := recordFields[source]; !ok {
+			return nil, fmt.Errorf("salesforce field %s maps unknown record field %q", field, source)
+		}
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Client{config: config, http: httpClient}, nil
+}
+
+// Object returns the object records are upserted as
+func (c *Client) Object() string {
+	return c.config.Object
+}
+
+// Body returns the fields sent for a record. Empty values are left out so they never clear a
+// field someone filled in by hand, and the external ID travels in the URL instead.
+func (c *Client) Body(record Record) map[string]string {
+	body := make(map[string]string, len(c.config.Fields))
+	for field, source := range c.config.Fields {
+		if field == c.config.ExternalIDField {
+			continue
+		}
+		value := strings.TrimPrefix(source, "=")
+		if !strings.HasPrefix(source, "=") {
+			value = recordFields[source](record)
+		}
+		if value != "" {
+			body[field] = value
+		}
+	}
+	return body
+}
+
+// Upsert creates the record, or updates the one whose external ID is the profile's public ID
+func (c *Client) Upsert(ctx context.Context, record Record) (Result, error) {
+	publicID := record.PublicID()
+	if publicID == "" || strings.Contains(publicID, "/") {
+		return Result{}, fmt.Errorf("record has no LinkedIn profile URL: %q", record.ProfileURL)
+	}
+	payload, err := json.Marshal(c.Body(record))
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to encode record: %w", err)
+	}
+
+	// An expired token is answered with 401, so the first one is retried with a new token
+	for attempt := 0; ; attempt++ {
+		token, instanceURL, err := c.authenticate(ctx, attempt > 0)
+		if err != nil {
+			return Result{}, err
+		}
+		endpoint := fmt.Sprintf("%s/services/data/%s/sobjects/%s/%s/%s", instanceURL, c.config.APIVersion,
+			c.config.Object, c.config.ExternalIDField, url.PathEscape(publicID))
+		request, err := http.NewRequestWithContext(ctx, http.MethodPatch, endpoint, bytes.NewReader(payload))
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to build upsert request: %w", err)
+		}
+		request.Header.Set("Authorization", "Bearer "+token)
+		request.Header.Set("Content-Type", "application/json")
+
+		response, err := c.http.Do(request)
+		if err != nil {
+			return Result{}, fmt.Errorf("salesforce upsert failed: %w", err)
+		}
+		body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+		response.Body.Close()
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to read salesforce response: %w", err)
+		}
+
+		switch {
+		case response.StatusCode == http.StatusUnauthorized && attempt == 0:
+			continue
+		case response.StatusCode == http.StatusNoContent:
+			// Older API versions answer an update without a body
+			return Result{}, nil
+		case response.StatusCode == http.StatusOK || response.StatusCode == http.StatusCreated:
+			var result struct {
+				ID      string `json:"id"`
+				Created bool   `json:"created"`
+			}
+			if err := json.Unmarshal(body, &result); err != nil {
+				return Result{}, fmt.Errorf("failed to decode salesforce response: %w", err)
+			}
+			return Result{ID: result.ID, Created: result.Created || response.StatusCode == http.StatusCreated}, nil
+		default:
+			return Result{}, fmt.Errorf("salesforce upsert of %s returned %d: %s", record.ProfileURL, response.StatusCode, apiError(body))
+		}
+	}
+}
+
+// authenticate returns the access token and instance URL, requesting a new token when there is
+// none yet or refresh is set
+func (c *Client) authenticate(ctx context.Context, refresh bool) (string, string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.token != "" && !refresh {
+		return c.token, c.instanceURL, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {c.config.ClientID},
+		"client_secret": {c.config.ClientSecret},
+	}
+	endpoint := strings.TrimSuffix(c.config.LoginURL, "/") + "/services/oauth2/token"
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to build token request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return "", "", fmt.Errorf("salesforce token request failed: %w", err)
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read salesforce token response: %w", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("salesforce token request returned %d: %s", response.StatusCode, apiError(body))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		InstanceURL string `json:"instance_url"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil || token.AccessToken == "" {
+		return "", "", fmt.Errorf("salesforce token response has no access token")
+	}
+	c.token, c.instanceURL = token.AccessToken, strings.TrimSuffix(token.InstanceURL, "/")
+	if c.instanceURL == "" {
+		c.instanceURL = strings.TrimSuffix(c.config.LoginURL, "/")
+	}
+	return c.token, c.instanceURL, nil
+}
+
+// apiError extracts the messages from a Salesforce error body, which is a list of errors for
+// REST calls and a single object for OAuth
+func apiError(body []byte) string {
+	var list []struct {
+		ErrorCode string `json:"errorCode"`
+		Message   string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &list); err == nil && len(list) > 0 {
+		messages := make([]string, len(list))
+		for i, item := range list {
+			messages[i] = item.ErrorCode + ": " + item.Message
+		}
+		sort.Strings(messages)
+		return strings.Join(messages, "; ")
+	}
+	var single struct {
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &single); err == nil && single.Error != "" {
+		return single.Error + ": " + single.Description
+	}
+	return strings.TrimSpace(string(body))
+}
@@ -0,0 +1,116 @@
+package salesforce
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// TestUpsertAuthenticatesAndMapsFields tests the client credentials flow, the mapped body, the
+// external ID URL and that an expired token is replaced once
+func TestUpsertAuthenticatesAndMapsFields(t *testing.T) {
+	var tokens atomic.Int32
+	var bodies []map[string]string
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/services/oauth2/token":
+			if err := r.ParseForm(); err != nil || r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("client_secret") != "secret" {
+				http.Error(w, `{"error":"invalid_client","error_description":"bad credentials"}`, http.StatusBadRequest)
+				return
+			}
+			n := tokens.Add(1)
+			json.NewEncoder(w).Encode(map[string]string{"access_token": "token-" + string(rune('0'+n)), "instance_url": server.URL})
+		case strings.HasPrefix(r.URL.Path, "/services/data/v60.0/sobjects/Lead/LinkedIn_ID__c/"):
+			if r.Header.Get("Authorization") == "Bearer token-1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`[{"errorCode":"INVALID_SESSION_ID","message":"Session expired"}]`))
+				return
+			}
+			if got := strings.TrimPrefix(r.URL.EscapedPath(), "/services/data/v60.0/sobjects/Lead/LinkedIn_ID__c/"); got != "jane-doe" {
+				t.Errorf("unexpected external ID in path: %s", got)
+			}
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			bodies = append(bodies, body)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"00Q1","success":true,"created":true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(Config{
+		LoginURL:        server.URL,
+		ClientID:        "id",
+		ClientSecret:    "secret",
+		ExternalIDField: "LinkedIn_ID__c",
+		Fields: map[string]string{
+			"LastName":             "last_name",
+			"Company":              "company",
+			"Title":                "title",
+			"LinkedIn_Campaign__c": "campaign",
+			"LeadSource":           "=LinkedIn",
+			"LinkedIn_URL__c":      "profile_url",
+			"LinkedIn_ID__c":       "public_id",
+		},
+	}, server.Client())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	result, err := client.Upsert(context.Background(), Record{
+		ProfileURL: "https://www.linkedin.com/in/jane-doe/",
+		LastName:   "Doe",
+		Company:    "Acme",
+		Campaign:   "spring-outreach",
+	})
+	if err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	if result.ID != "00Q1" || !result.Created || tokens.Load() != 2 {
+		t.Errorf("expected a created lead after one token refresh, got %+v with %d tokens", result, tokens.Load())
+	}
+	want := map[string]string{"LinkedIn_URL__c": "https://www.linkedin.com/in/jane-doe/", "LastName": "Doe", "Company": "Acme", "LinkedIn_Campaign__c": "spring-outreach", "LeadSource": "LinkedIn"}
+	if len(bodies) != 1 || len(bodies[0]) != len(want) {
+		t.Fatalf("expected one body %v, got %v", want, bodies)
+	}
+	for field, value := range want {
+		if bodies[0][field] != value {
+			t.Errorf("expected %s=%q, got %q", field, value, bodies[0][field])
+		}
+	}
+
+	bad, _ := New(Config{LoginURL: server.URL, ClientID: "id", ClientSecret: "wrong", ExternalIDField: "LinkedIn_ID__c"}, server.Client())
+	if _, err := bad.Upsert(context.Background(), Record{ProfileURL: "https://www.linkedin.com/in/jane-doe/"}); err == nil || !strings.Contains(err.Error(), "bad credentials") {
+		t.Errorf("expected the OAuth error to be reported, got %v", err)
+	}
+}
+
+// TestNewValidatesConfig tests the checks made before any request
+func TestNewValidatesConfig(t *testing.T) {
+	valid := Config{LoginURL: "https://acme.my.salesforce.com", ClientID: "id", ClientSecret: "secret", ExternalIDField: "LinkedIn_ID__c"}
+	client, err := New(valid, nil)
+	if err != nil {
+		t.Fatalf("expected a valid config, got %v", err)
+	}
+	if body := client.Body(Record{FirstName: "Jane", LastName: "Doe"}); body["LastName"] != "Doe" || body["LeadSource"] != "LinkedIn" || body["Company"] != "" {
+		t.Errorf("expected the default lead mapping without empty fields, got %v", body)
+	}
+
+	for name, config := range map[string]Config{
+		"no secret":      {LoginURL: valid.LoginURL, ClientID: "id", ExternalIDField: "X__c"},
+		"no external ID": {LoginURL: valid.LoginURL, ClientID: "id", ClientSecret: "secret"},
+		"bad object":     {LoginURL: valid.LoginURL, ClientID: "id", ClientSecret: "secret", ExternalIDField: "X__c", Object: "Account"},
+		"unknown field":  {LoginURL: valid.LoginURL, ClientID: "id", ClientSecret: "secret", ExternalIDField: "X__c", Fields: map[string]string{"Shoe__c": "shoe_size"}},
+	} {
+		if _, err := New(config, nil); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+// Package jobqueue is a durable work queue kept in storage. Work is enqueued once per key, leased
+// by one worker at a time and acknowledged when done. A lease not acknowledged in time, because
+// the process crashed mid-batch, lapses and the job is leased again, so no work is lost; a failed
+// attempt is retried after a backoff, and a job that keeps failing is moved to the dead letters
+// for review instead of being retried forever.
+package jobqueue
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"linkedin-automation-framework/internal/errors"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// Defaults for options left unset
+const (
+	DefaultVisibility  = time.Hour
+	DefaultMaxAttempts = 3
+	DefaultBackoff     = 15 * time.Minute
+)
+
+// ErrLeaseLost is returned when a lease lapsed before it was acknowledged or failed and another
+// worker leased the job since; the job is that worker's now
+var ErrLeaseLost = stderrors.New("lease lapsed and the job was leased again")
+
+// Store keeps the queues' jobs and dead letters
+type Store interface {
+	EnqueueJobs(jobs []storage.Job) (int, error)
+	LeaseJob(queue, key, lease string, now time.Time, visibility time.Duration) (storage.Job, bool, error)
+	AckJob(id int64, lease string) (bool, error)
+	RetryJob(id int64, lease string, attempts int, availableAt time.Time, lastError string) (bool, error)
+	DeadLetterJob(id int64, lease string, letter storage.DeadLetter) (bool, error)
+	GetJobs(queue string) ([]storage.Job, error)
+}
+
+// Options tune how a queue retries
+type Options struct {
+	Visibility  time.Duration // How long a lease lasts before the job can be leased again
+	MaxAttempts int           // Attempts before a failing job is dead-lettered
+	Backoff     time.Duration // Wait before the first retry, doubled for each retry after it
+}
+
+// Item is work to enqueue
+type Item struct {
+	Key     string // Identifies the work within the queue; a key already queued is not added again
+	Payload any    // Encoded as JSON
+}
+
+// Queue is one named queue
+type Queue struct {
+	store   Store
+	name    string
+	options Options
+}
+
+// New opens the queue called name, filling options left unset with the defaults
+func New(store Store, name string, options Options) *Queue {
+	if options.Visibility <= 0 {
+		options.Visibility = DefaultVisibility
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = DefaultMaxAttempts
+	}
+	if options.Backoff <= 0 {
+		options.Backoff = DefaultBackoff
+	}
+	return &Queue{store: store, name: name, options: options}
+}
+
+// Name returns the queue's name
+func (q *Queue) Name() string {
+	return q.name
+}
+
+// Enqueue adds the items to the queue, available from now, and returns how many were added.
+// An item whose key is queued already, leased or waiting for a retry, or was dead-lettered is
+// left out.
+func (q *Queue) Enqueue(items []Item, now time.Time) (int, error) {
+	jobs := make([]storage.Job, 0, len(items))
+	for _, item := range items {
+		payload, err := json.Marshal(item.Payload)
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode job %s: %w", item.Key, err)
+		}
+		jobs = append(jobs, storage.Job{Queue: q.name, Key: item.Key, Payload: string(payload), EnqueuedAt: now, AvailableAt: now})
+	}
+	added, err := q.store.EnqueueJobs(jobs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue to %s: %w", q.name, err)
+	}
+	return added, nil
+}
+
+// Pending returns the jobs that can be leased at now, oldest first: those never leased, those
+// whose lease lapsed and those whose retry is due
+func (q *Queue) Pending(now time.Time) ([]storage.Job, error) {
+	jobs, err := q.store.GetJobs(q.name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", q.name, err)
+	}
+	var pending []storage.Job
+	for _, job := range jobs {
+		if job.Available(now) {
+			pending = append(pending, job)
+		}
+	}
+	return pending, nil
+}
+
+// Lease leases the job for key, or the oldest available job when key is empty. It reports false
+// when there is none to lease: not queued, leased by another worker or waiting for a retry.
+func (q *Queue) Lease(key string, now time.Time) (*Lease, bool, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, false, err
+	}
+	job, ok, err := q.store.LeaseJob(q.name, key, token, now, q.options.Visibility)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to lease from %s: %w", q.name, err)
+	}
+	if !ok {
+		return nil, false, nil
+	}
+	return &Lease{queue: q, Job: job}, true, nil
+}
+
+// Lease is a worker's hold on one job until it acknowledges or fails it
+type Lease struct {
+	queue *Queue
+	Job   storage.Job
+}
+
+// Decode decodes the job's payload into v
+func (l *Lease) Decode(v any) error {
+	if err := json.Unmarshal([]byte(l.Job.Payload), v); err != nil {
+		return fmt.Errorf("failed to decode job %s: %w", l.Job.Key, err)
+	}
+	return nil
+}
+
+// Ack removes the job, done with, from the queue
+func (l *Lease) Ack() error {
+	acked, err := l.queue.store.AckJob(l.Job.ID, l.Job.Lease)
+	if err != nil {
+		return err
+	}
+	if !acked {
+		return fmt.Errorf("job %s: %w", l.Job.Key, ErrLeaseLost)
+	}
+	return nil
+}
+
+// Release hands the job back untried, e.g. because the run stopped before it, to be leased
+// again right away; the lease does not count as an attempt
+func (l *Lease) Release(now time.Time) error {
+	released, err := l.queue.store.RetryJob(l.Job.ID, l.Job.Lease, max(l.Job.Attempts-1, 0), now, l.Job.LastError)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return fmt.Errorf("job %s: %w", l.Job.Key, ErrLeaseLost)
+	}
+	return nil
+}
+
+// Fail records the attempt's failure. The job is retried after its backoff, or moved to the dead
+// letters with the cause's fingerprint once it failed as many times as the queue allows; Fail
+// reports whether it was.
+func (l *Lease) Fail(cause error, now time.Time) (bool, error) {
+	message := ""
+	if cause != nil {
+		message = cause.Error()
+	}
+	job, options := l.Job, l.queue.options
+	if job.Attempts >= options.MaxAttempts {
+		letter := storage.DeadLetter{
+			Queue:       job.Queue,
+			Key:         job.Key,
+			Payload:     job.Payload,
+			Attempts:    job.Attempts,
+			Error:       message,
+			Fingerprint: errors.FingerprintOf(cause).ID,
+			EnqueuedAt:  job.EnqueuedAt,
+			FailedAt:    now,
+		}
+		moved, err := l.queue.store.DeadLetterJob(job.ID, job.Lease, letter)
+		if err != nil {
+			return false, err
+		}
+		if !moved {
+			return false, fmt.Errorf("job %s: %w", job.Key, ErrLeaseLost)
+		}
+		return true, nil
+	}
+
+	retried, err := l.queue.store.RetryJob(job.ID, job.Lease, job.Attempts, now.Add(Backoff(options.Backoff, job.Attempts)), message)
+	if err != nil {
+		return false, err
+	}
+	if !retried {
+		return false, fmt.Errorf("job %s: %w", job.Key, ErrLeaseLost)
+	}
+	return false, nil
+}
+
+// Backoff is the wait before the retry after the given number of failed attempts: base after the
+// first, doubling with each one after it until it passes a day
+func Backoff(base time.Duration, attempts int) time.Duration {
+	wait := base
+	for i := 1; i < attempts && wait < 24*time.Hour; i++ {
+		wait *= 2
+	}
+	return wait
+}
+
+// newToken returns a random lease token
+func newToken() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to create lease token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,127 @@
+package jobqueue
+
+import (
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/errors"
+	"linkedin-automation-framework/internal/storage"
+)
+
+type lead struct {
+	URL  string
+	Name string
+}
+
+// newStore opens an empty store
+func newStore(t *testing.T) *storage.StorageManager {
+	t.Helper()
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "sqlite", Path: t.TempDir(), Database: "test.db"})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// TestCrashRecovery tests that a job leased by a worker that never acknowledged it is pending
+// again once its lease lapses, that the stale lease cannot acknowledge it after that, and that
+// a released job is leased again right away
+func TestCrashRecovery(t *testing.T) {
+	store := newStore(t)
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	queue := New(store, "campaign:q1", Options{Visibility: 30 * time.Minute})
+
+	added, err := queue.Enqueue([]Item{
+		{Key: "https://www.linkedin.com/in/jane-doe/", Payload: lead{URL: "https://www.linkedin.com/in/jane-doe/", Name: "Jane Doe"}},
+		{Key: "https://www.linkedin.com/in/john-roe/", Payload: lead{URL: "https://www.linkedin.com/in/john-roe/"}},
+	}, now)
+	if err != nil || added != 2 {
+		t.Fatalf("expected two jobs enqueued, got %d (%v)", added, err)
+	}
+	if added, _ := queue.Enqueue([]Item{{Key: "https://www.linkedin.com/in/jane-doe/", Payload: lead{}}}, now); added != 0 {
+		t.Error("expected a queued key not to be enqueued again")
+	}
+
+	crashed, ok, err := queue.Lease("https://www.linkedin.com/in/jane-doe/", now)
+	if err != nil || !ok {
+		t.Fatalf("failed to lease jane: %v, %v", ok, err)
+	}
+	var decoded lead
+	if err := crashed.Decode(&decoded); err != nil || decoded.Name != "Jane Doe" {
+		t.Errorf("unexpected payload %+v (%v)", decoded, err)
+	}
+	if pending, _ := queue.Pending(now.Add(time.Minute)); len(pending) != 1 || pending[0].Key != "https://www.linkedin.com/in/john-roe/" {
+		t.Errorf("expected only john pending while jane is leased, got %+v", pending)
+	}
+
+	pending, err := queue.Pending(now.Add(time.Hour))
+	if err != nil || len(pending) != 2 {
+		t.Fatalf("expected jane pending again once her lease lapsed, got %+v (%v)", pending, err)
+	}
+	recovered, ok, err := queue.Lease("https://www.linkedin.com/in/jane-doe/", now.Add(time.Hour))
+	if err != nil || !ok || recovered.Job.Attempts != 2 {
+		t.Fatalf("expected jane leased again, got %+v, %v (%v)", recovered, ok, err)
+	}
+	if err := crashed.Ack(); !stderrors.Is(err, ErrLeaseLost) {
+		t.Errorf("expected the lapsed lease to be refused, got %v", err)
+	}
+	if err := recovered.Release(now.Add(time.Hour)); err != nil {
+		t.Fatalf("failed to release jane: %v", err)
+	}
+	recovered, ok, err = queue.Lease("https://www.linkedin.com/in/jane-doe/", now.Add(time.Hour))
+	if err != nil || !ok || recovered.Job.Attempts != 2 {
+		t.Fatalf("expected jane leased again without the released attempt counted, got %+v, %v (%v)", recovered, ok, err)
+	}
+	if err := recovered.Ack(); err != nil {
+		t.Errorf("failed to acknowledge jane: %v", err)
+	}
+}
+
+// TestFail tests that a failing job backs off before each retry and is dead-lettered with its
+// error's fingerprint after the last attempt
+func TestFail(t *testing.T) {
+	store := newStore(t)
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	queue := New(store, "campaign:q1", Options{MaxAttempts: 2, Backoff: 10 * time.Minute})
+	if _, err := queue.Enqueue([]Item{{Key: "a", Payload: lead{URL: "a"}}}, now); err != nil {
+		t.Fatalf("failed to enqueue: %v", err)
+	}
+
+	cause := errors.NewError(errors.ErrorTypePermanent, "click_connect_button", "connect button not found", nil)
+	first, _, _ := queue.Lease("", now)
+	if dead, err := first.Fail(cause, now); err != nil || dead {
+		t.Fatalf("expected the first failure retried, got %v (%v)", dead, err)
+	}
+	if _, ok, _ := queue.Lease("a", now.Add(5*time.Minute)); ok {
+		t.Error("expected the job to back off before its retry")
+	}
+	second, ok, _ := queue.Lease("a", now.Add(10*time.Minute))
+	if !ok || second.Job.LastError != cause.Error() {
+		t.Fatalf("expected the retry due, got %+v", second)
+	}
+	if dead, err := second.Fail(cause, now.Add(10*time.Minute)); err != nil || !dead {
+		t.Fatalf("expected the last failure dead-lettered, got %v (%v)", dead, err)
+	}
+
+	letters, err := store.GetDeadLetters()
+	if err != nil || len(letters) != 1 {
+		t.Fatalf("expected one dead letter, got %+v (%v)", letters, err)
+	}
+	if letters[0].Key != "a" || letters[0].Attempts != 2 || letters[0].Fingerprint != errors.FingerprintOf(cause).ID || letters[0].Payload != `{"URL":"a","Name":""}` {
+		t.Errorf("unexpected dead letter %+v", letters[0])
+	}
+	if pending, _ := queue.Pending(now.Add(time.Hour)); len(pending) != 0 {
+		t.Errorf("expected the dead job out of the queue, got %+v", pending)
+	}
+}
+
+// TestBackoff tests that the wait doubles with each failed attempt
+func TestBackoff(t *testing.T) {
+	for attempts, expected := range map[int]time.Duration{1: 15 * time.Minute, 2: 30 * time.Minute, 3: time.Hour} {
+		if got := Backoff(15*time.Minute, attempts); got != expected {
+			t.Errorf("expected %s after %d attempts, got %s", expected, attempts, got)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package language
+
+import (
+	"strings"
+	"unicode"
+)
+
+// English is the language templates fall back to
+const English = "en"
+
+// stopwords are frequent short words that identify a language in a headline or About section
+var stopwords = map[string][]string{
+	"en": {"and", "the", "of", "for", "with", "at", "in", "to", "my", "i", "am", "helping", "building"},
+	"de": {"und", "der", "die", "das", "bei", "für", "mit", "ich", "von", "im", "zu", "ein", "eine", "leiter", "entwickler"},
+	"fr": {"et", "le", "la", "les", "de", "des", "du", "chez", "pour", "avec", "je", "un", "une", "responsable", "ingénieur"},
+	"es": {"y", "el", "la", "los", "las", "de", "del", "en", "para", "con", "soy", "un", "una", "desarrollador", "ingeniero"},
+	"pt": {"e", "o", "os", "as", "de", "do", "da", "em", "na", "no", "para", "com", "sou", "um", "uma", "desenvolvedor"},
+	"it": {"e", "il", "lo", "gli", "di", "del", "della", "in", "presso", "per", "con", "sono", "un", "una", "sviluppatore"},
+	"nl": {"en", "de", "het", "van", "bij", "voor", "met", "ik", "een", "naar", "ontwikkelaar"},
+}
+
+// locations map country and major city names, lower-case, to the language spoken there
+var locations = []struct {
+	name     string
+	language string
+}{
+	{"germany", "de"}, {"deutschland", "de"}, {"austria", "de"}, {"österreich", "de"}, {"berlin", "de"},
+	{"munich", "de"}, {"münchen", "de"}, {"hamburg", "de"}, {"vienna", "de"}, {"wien", "de"},
+	{"france", "fr"}, {"paris", "fr"}, {"lyon", "fr"}, {"québec", "fr"}, {"quebec", "fr"},
+	{"spain", "es"}, {"españa", "es"}, {"madrid", "es"}, {"barcelona", "es"}, {"mexico", "es"}, {"méxico", "es"},
+	{"argentina", "es"}, {"colombia", "es"}, {"chile", "es"}, {"peru", "es"}, {"perú", "es"},
+	{"brazil", "pt"}, {"brasil", "pt"}, {"portugal", "pt"}, {"são paulo", "pt"}, {"lisbon", "pt"}, {"lisboa", "pt"},
+	{"italy", "it"}, {"italia", "it"}, {"milan", "it"}, {"milano", "it"}, {"rome", "it"},
+	{"netherlands", "nl"}, {"nederland", "nl"}, {"amsterdam", "nl"}, {"rotterdam", "nl"},
+	{"japan", "ja"}, {"tokyo", "ja"}, {"china", "zh"}, {"beijing", "zh"}, {"shanghai", "zh"},
+	{"korea", "ko"}, {"seoul", "ko"}, {"russia", "ru"}, {"moscow", "ru"},
+}
+
+// scripts identify languages written in their own script
+var scripts = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Hiragana, "ja"}, {unicode.Katakana, "ja"}, {unicode.Hangul, "ko"},
+	{unicode.Han, "zh"}, {unicode.Cyrillic, "ru"}, {unicode.Arabic, "ar"},
+}
+
+// Supported lists the language codes Detect can return
+func Supported() []string {
+	return []string{"ar", "de", "en", "es", "fr", "it", "ja", "ko", "nl", "pt", "ru", "zh"}
+}
+
+// IsSupported reports whether code is a language Detect can return
+func IsSupported(code string) bool {
+	for _, supported := range Supported() {
+		if supported == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Detect guesses a prospect's language from their profile text, such as the headline and About
+// section, and falls back to their location. It returns "" when neither gives a clear answer.
+func Detect(text, location string) string {
+	if language := FromText(text); language != "" {
+		return language
+	}
+	return FromLocation(location)
+}
+
+// FromText guesses the language of text from its script, or from the stopwords it uses when
+// it is written in Latin script. It returns "" for short or mixed text.
+func FromText(text string) string {
+	counts := make(map[string]int)
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, script := range scripts {
+			if unicode.Is(script.table, r) {
+				counts[script.language]++
+				break
+			}
+		}
+	}
+	// Kana marks Japanese even though Japanese text also uses Han characters
+	if counts["ja"] > 0 {
+		return "ja"
+	}
+	for _, script := range scripts {
+		if letters > 0 && counts[script.language]*2 > letters {
+			return script.language
+		}
+	}
+
+	// Combining marks stay in their word, so a decomposed accent does not split it into stray fragments
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsMark(r) })
+	scores := make(map[string]int)
+	for _, word := range words {
+		for language, list := range stopwords {
+			for _, stopword := range list {
+				if word == stopword {
+					scores[language]++
+					break
+				}
+			}
+		}
+	}
+
+	best, bestScore, runnerUp := "", 0, 0
+	for _, language := range Supported() {
+		score := scores[language]
+		if score > bestScore {
+			best, bestScore, runnerUp = language, score, bestScore
+		} else if score > runnerUp {
+			runnerUp = score
+		}
+	}
+	if bestScore < 2 || bestScore == runnerUp {
+		return ""
+	}
+	return best
+}
+
+// FromLocation returns the language of the country or city named in a profile location, or ""
+func FromLocation(location string) string {
+	lower := strings.ToLower(location)
+	for _, entry := range locations {
+		if strings.Contains(lower, entry.name) {
+			return entry.language
+		}
+	}
+	return ""
+}
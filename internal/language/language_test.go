@@ -0,0 +1,31 @@
+package language
+
+import "testing"
+
+// TestDetect tests detection from headline text, script and location
+func TestDetect(t *testing.T) {
+	cases := []struct {
+		name     string
+		text     string
+		location string
+		want     string
+	}{
+		{"english headline", "Head of Engineering at Acme and mentor for new managers", "Berlin, Germany", "en"},
+		{"german headline", "Leiter der Softwareentwicklung bei Acme und Mentor für Teams", "", "de"},
+		{"french headline", "Responsable des ventes chez Acme pour la France et le Benelux", "", "fr"},
+		{"spanish headline", "Ingeniero de software en Acme, apasionado por los datos y la nube", "", "es"},
+		{"portuguese headline", "Desenvolvedor na Acme, apaixonado por dados e com foco em produto", "", "pt"},
+		{"japanese headline", "ソフトウェアエンジニア 東京", "", "ja"},
+		{"russian headline", "Руководитель отдела продаж", "", "ru"},
+		{"location fallback", "CTO", "São Paulo, Brazil", "pt"},
+		{"nothing to go on", "CTO", "Remote", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Detect(tc.text, tc.location); got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// Package latency tracks how fast LinkedIn answers and holds it to objectives. LinkedIn often
+// slows down before it rate limits an account, so a run whose pages and actions slow past their
+// objective is warned about and made to stretch its delays until they are fast again.
+package latency
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Kind is what a latency was measured for
+type Kind string
+
+const (
+	KindPageLoad Kind = "page_load" // From a page's navigation request to its load event
+	KindAction   Kind = "action"    // From a request to LinkedIn's API, e.g. made by a click, to its response
+)
+
+// recoveryMargin is the fraction of its objective a degraded kind's p90 must fall under to
+// recover, so a kind hovering around its objective does not flap
+const recoveryMargin = 0.8
+
+// Objectives are the latencies a run is held to. A kind is degraded while the p90 of its latest
+// Window samples is above its objective, once it has MinSamples.
+type Objectives struct {
+	PageLoad   time.Duration
+	Action     time.Duration
+	Window     int
+	MinSamples int
+	MaxStretch float64 // Most delays are stretched by while degraded, at least 1
+}
+
+// Stats summarizes the latest samples of one kind
+type Stats struct {
+	Kind      Kind          `json:"kind"`
+	Samples   int           `json:"samples"` // In the window the percentiles are computed over
+	Total     int           `json:"total"`   // Since tracking started
+	P50       time.Duration `json:"p50"`
+	P90       time.Duration `json:"p90"`
+	P99       time.Duration `json:"p99"`
+	Objective time.Duration `json:"objective"`
+	Degraded  bool          `json:"degraded"`
+}
+
+// Status is the state of every kind, with the factor delays are stretched by
+type Status struct {
+	Stretch float64 `json:"stretch"`
+	Kinds   []Stats `json:"kinds"`
+}
+
+// Change reports a kind degrading or recovering
+type Change func(stats Stats)
+
+// window keeps the latest samples of one kind in a ring
+type window struct {
+	samples  []time.Duration
+	next     int
+	total    int
+	degraded bool
+}
+
+// Tracker records latencies and compares them to objectives. It is safe for concurrent use; a
+// nil *Tracker records nothing and never stretches delays.
+type Tracker struct {
+	objectives Objectives
+	windows    map[Kind]*window
+	onChange   Change
+	mutex      sync.Mutex
+}
+
+// NewTracker creates a tracker holding latencies to objectives. onChange, if not nil, is called
+// whenever a kind degrades or recovers.
+func NewTracker(objectives Objectives, onChange Change) *Tracker {
+	if objectives.Window < 1 {
+		objectives.Window = 1
+	}
+	if objectives.MinSamples > objectives.Window {
+		objectives.MinSamples = objectives.Window
+	}
+	if objectives.MaxStretch < 1 {
+		objectives.MaxStretch = 1
+	}
+	return &Tracker{
+		objectives: objectives,
+		windows:    map[Kind]*window{KindPageLoad: {}, KindAction: {}},
+		onChange:   onChange,
+	}
+}
+
+// Record adds a latency of kind
+func (t *Tracker) Record(kind Kind, latency time.Duration) {
+	if t == nil || latency < 0 {
+		return
+	}
+	t.mutex.Lock()
+	w, ok := t.windows[kind]
+	if !ok {
+		t.mutex.Unlock()
+		return
+	}
+	if len(w.samples) < t.objectives.Window {
+		w.samples = append(w.samples, latency)
+	} else {
+		w.samples[w.next] = latency
+	}
+	w.next = (w.next + 1) % t.objectives.Window
+	w.total++
+
+	stats := t.stats(kind, w)
+	changed := false
+	switch {
+	case !w.degraded && stats.Samples >= t.objectives.MinSamples && stats.Objective > 0 && stats.P90 > stats.Objective:
+		w.degraded, changed = true, true
+	case w.degraded && float64(stats.P90) <= float64(stats.Objective)*recoveryMargin:
+		w.degraded, changed = false, true
+	}
+	stats.Degraded = w.degraded
+	t.mutex.Unlock()
+
+	if changed && t.onChange != nil {
+		t.onChange(stats)
+	}
+}
+
+// Stretch returns the factor delays are stretched by: 1 while every kind meets its objective,
+// otherwise how far the slowest degraded kind's p90 is over its objective, up to MaxStretch
+func (t *Tracker) Stretch() float64 {
+	if t == nil {
+		return 1
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.stretch()
+}
+
+// Status returns every kind's stats, page loads first
+func (t *Tracker) Status() Status {
+	if t == nil {
+		return Status{Stretch: 1}
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	status := Status{Stretch: t.stretch()}
+	for _, kind := range []Kind{KindPageLoad, KindAction} {
+		status.Kinds = append(status.Kinds, t.stats(kind, t.windows[kind]))
+	}
+	return status
+}
+
+// stretch computes Stretch; the caller holds the mutex
+func (t *Tracker) stretch() float64 {
+	stretch := 1.0
+	for kind, w := range t.windows {
+		if !w.degraded {
+			continue
+		}
+		stats := t.stats(kind, w)
+		stretch = math.Max(stretch, float64(stats.P90)/float64(stats.Objective))
+	}
+	return math.Min(stretch, t.objectives.MaxStretch)
+}
+
+// stats summarizes a window; the caller holds the mutex
+func (t *Tracker) stats(kind Kind, w *window) Stats {
+	stats := Stats{Kind: kind, Samples: len(w.samples), Total: w.total, Objective: t.objective(kind), Degraded: w.degraded}
+	if len(w.samples) == 0 {
+		return stats
+	}
+	sorted := append([]time.Duration(nil), w.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	stats.P50 = percentile(sorted, 50)
+	stats.P90 = percentile(sorted, 90)
+	stats.P99 = percentile(sorted, 99)
+	return stats
+}
+
+// objective returns the objective of kind
+func (t *Tracker) objective(kind Kind) time.Duration {
+	if kind == KindPageLoad {
+		return t.objectives.PageLoad
+	}
+	return t.objectives.Action
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted samples
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,100 @@
+package latency
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTrackerPercentiles tests that percentiles are computed over the latest samples only
+func TestTrackerPercentiles(t *testing.T) {
+	tracker := NewTracker(Objectives{PageLoad: time.Minute, Action: time.Minute, Window: 10, MaxStretch: 3}, nil)
+	for i := 1; i <= 10; i++ {
+		tracker.Record(KindPageLoad, time.Duration(i)*time.Second)
+	}
+	stats := tracker.Status().Kinds[0]
+	if stats.Kind != KindPageLoad || stats.Samples != 10 || stats.P50 != 5*time.Second || stats.P90 != 9*time.Second || stats.P99 != 10*time.Second {
+		t.Fatalf("unexpected page load stats: %+v", stats)
+	}
+
+	// Five fast loads push out the five oldest
+	for i := 0; i < 5; i++ {
+		tracker.Record(KindPageLoad, 100*time.Millisecond)
+	}
+	stats = tracker.Status().Kinds[0]
+	if stats.Samples != 10 || stats.Total != 15 || stats.P50 != 100*time.Millisecond || stats.P99 != 10*time.Second {
+		t.Errorf("expected percentiles over the latest 10 samples, got %+v", stats)
+	}
+	if action := tracker.Status().Kinds[1]; action.Kind != KindAction || action.Samples != 0 || action.P90 != 0 {
+		t.Errorf("expected no action samples, got %+v", action)
+	}
+}
+
+// TestTrackerDegradation tests that a kind degrades past its objective, stretches delays in
+// proportion and recovers only well under its objective
+func TestTrackerDegradation(t *testing.T) {
+	var changes []Stats
+	tracker := NewTracker(Objectives{PageLoad: 4 * time.Second, Action: time.Second, Window: 10, MinSamples: 5, MaxStretch: 3},
+		func(stats Stats) { changes = append(changes, stats) })
+
+	for i := 0; i < 4; i++ {
+		tracker.Record(KindAction, 2*time.Second)
+	}
+	if len(changes) != 0 || tracker.Stretch() != 1 {
+		t.Fatalf("expected no degradation before min_samples, got %+v", changes)
+	}
+	tracker.Record(KindAction, 2*time.Second)
+	if len(changes) != 1 || changes[0].Kind != KindAction || !changes[0].Degraded {
+		t.Fatalf("expected actions to degrade, got %+v", changes)
+	}
+	if stretch := tracker.Stretch(); stretch != 2 {
+		t.Errorf("expected delays stretched by p90 over the objective, got %v", stretch)
+	}
+
+	for i := 0; i < 10; i++ {
+		tracker.Record(KindAction, 10*time.Second)
+	}
+	if stretch := tracker.Stretch(); stretch != 3 {
+		t.Errorf("expected the stretch capped at max_stretch, got %v", stretch)
+	}
+
+	// Under the objective but not under the recovery margin stays degraded
+	for i := 0; i < 10; i++ {
+		tracker.Record(KindAction, 900*time.Millisecond)
+	}
+	if len(changes) != 1 || !tracker.Status().Kinds[1].Degraded {
+		t.Fatalf("expected actions to stay degraded just under the objective, got %+v", changes)
+	}
+	if stretch := tracker.Stretch(); stretch != 1 {
+		t.Errorf("expected no stretch once p90 is under the objective, got %v", stretch)
+	}
+	for i := 0; i < 10; i++ {
+		tracker.Record(KindAction, 500*time.Millisecond)
+	}
+	if len(changes) != 2 || changes[1].Degraded || tracker.Status().Kinds[1].Degraded {
+		t.Errorf("expected actions to recover, got %+v", changes)
+	}
+	if tracker.Status().Kinds[0].Degraded {
+		t.Error("page loads should not degrade with actions")
+	}
+
+	var nilTracker *Tracker
+	nilTracker.Record(KindAction, time.Hour)
+	if nilTracker.Stretch() != 1 {
+		t.Error("a nil tracker should never stretch delays")
+	}
+}
+
+// TestLinkedInAPI tests which requests count as actions
+func TestLinkedInAPI(t *testing.T) {
+	for rawURL, expected := range map[string]bool{
+		"https://www.linkedin.com/voyager/api/growth/normInvitations":         true,
+		"https://www.linkedin.com/voyager/api/graphql?queryId=messengerConvo": true,
+		"https://www.linkedin.com/in/jane-doe/":                               false,
+		"https://static.licdn.com/voyager/api/asset.js":                       false,
+		"https://linkedin.com.example.com/voyager/api/x":                      false,
+	} {
+		if actual := linkedInAPI(rawURL); actual != expected {
+			t.Errorf("linkedInAPI(%q) = %v, expected %v", rawURL, actual, expected)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package latency
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Watch records the page loads of page and its requests to LinkedIn's API until the returned
+// function is called. Latencies are taken from the browser's own timestamps, so they leave out
+// the time the events take to reach the tracker.
+func (t *Tracker) Watch(page *rod.Page) (stop func()) {
+	if t == nil {
+		return func() {}
+	}
+
+	events, cancel := page.WithCancel()
+	var navigation proto.MonotonicTime // When the page's current navigation was requested, 0 once loaded
+	pending := make(map[proto.NetworkRequestID]proto.MonotonicTime)
+
+	wait := events.EachEvent(
+		func(e *proto.NetworkRequestWillBeSent) {
+			switch {
+			case e.Type == proto.NetworkResourceTypeDocument && e.FrameID == page.FrameID:
+				// A redirect is sent as a new request with the same ID, which keeps the navigation's start
+				if e.RedirectResponse == nil || navigation == 0 {
+					navigation = e.Timestamp
+				}
+			case (e.Type == proto.NetworkResourceTypeXHR || e.Type == proto.NetworkResourceTypeFetch) && e.Request != nil && linkedInAPI(e.Request.URL):
+				if _, redirected := pending[e.RequestID]; !redirected {
+					pending[e.RequestID] = e.Timestamp
+				}
+			}
+		},
+		func(e *proto.NetworkResponseReceived) {
+			if start, ok := pending[e.RequestID]; ok {
+				delete(pending, e.RequestID)
+				t.Record(KindAction, (e.Timestamp - start).Duration())
+			}
+		},
+		func(e *proto.NetworkLoadingFailed) {
+			delete(pending, e.RequestID)
+		},
+		func(e *proto.PageLoadEventFired) {
+			if navigation != 0 {
+				t.Record(KindPageLoad, (e.Timestamp - navigation).Duration())
+				navigation = 0
+			}
+		},
+	)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wait()
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancel()
+			<-done
+		})
+	}
+}
+
+// linkedInAPI reports whether a request goes to LinkedIn's API, which LinkedIn's pages call for
+// what a user does on them
+func linkedInAPI(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := parsed.Hostname()
+	if host != "linkedin.com" && !strings.HasSuffix(host, ".linkedin.com") {
+		return false
+	}
+	return strings.HasPrefix(parsed.Path, "/voyager/api/")
+}
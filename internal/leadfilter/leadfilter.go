@@ -0,0 +1,245 @@
+package leadfilter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// ScriptTimeout is how long a filter script may take over one profile before it is stopped
+const ScriptTimeout = 2 * time.Second
+
+// Filter decides whether an extracted profile qualifies as a lead
+type Filter interface {
+	Evaluate(ctx context.Context, profile Profile) (Decision, error)
+	Close()
+}
+
+// Profile is the profile data exposed to filters
+type Profile struct {
+	URL      string
+	Name     string
+	Title    string
+	Company  string
+	Location string
+	Mutual   int
+	Premium  bool
+
+	// Firmographics of the company, empty until it has been looked up with "companies enrich"
+	CompanySize      string // Employee range, e.g. "51-200 employees"
+	Employees        int    // Lower bound of CompanySize
+	Industry         string
+	Headquarters     string
+	CompanyFollowers int
+	WebsiteKeywords  []string // Configured keywords found on the company's website, when website scans are on
+}
+
+// Decision is the result of evaluating a profile
+type Decision struct {
+	Accept bool
+	Score  float64
+	Reason string
+}
+
+// DefaultMinScore is the score the keyword filter requires unless configured otherwise
+const DefaultMinScore = 2
+
+// FilterConfig contains lead filter settings
+type FilterConfig struct {
+	Script   string   // Path to a Lua qualification script; empty uses the keyword filter
+	Keywords []string // Title keywords used by the keyword filter
+	MinScore *float64 // Minimum score the keyword filter requires to accept; DefaultMinScore if nil
+}
+
+// NewFilter creates the filter described by the configuration
+func NewFilter(config FilterConfig) (Filter, error) {
+	if config.Script != "" {
+		return NewScriptFilter(config.Script)
+	}
+	minScore := float64(DefaultMinScore)
+	if config.MinScore != nil {
+		minScore = *config.MinScore
+	}
+	return NewKeywordFilter(config.Keywords, minScore), nil
+}
+
+// KeywordFilter scores profiles by presence of name, company and a relevant title
+type KeywordFilter struct {
+	keywords []string
+	minScore float64
+}
+
+// NewKeywordFilter creates the built-in keyword filter
+func NewKeywordFilter(keywords []string, minScore float64) *KeywordFilter {
+	if len(keywords) == 0 {
+		keywords = []string{"engineer", "developer", "software"}
+	}
+	return &KeywordFilter{
+		keywords: keywords,
+		minScore: minScore,
+	}
+}
+
+// Evaluate awards one point each for a name, a matching title and a company
+func (kf *KeywordFilter) Evaluate(ctx context.Context, profile Profile) (Decision, error) {
+	score := 0.0
+	var reasons []string
+
+	if strings.TrimSpace(profile.Name) != "" {
+		score++
+		reasons = append(reasons, "has name")
+	}
+
+	title := strings.ToLower(profile.Title)
+	for _, keyword := range kf.keywords {
+		if strings.Contains(title, strings.ToLower(keyword)) {
+			score++
+			reasons = append(reasons, "relevant title")
+			break
+		}
+	}
+
+	if strings.TrimSpace(profile.Company) != "" {
+		score++
+		reasons = append(reasons, "has company")
+	}
+
+	return Decision{
+		Accept: score >= kf.minScore,
+		Score:  score,
+		Reason: strings.Join(reasons, ", "),
+	}, nil
+}
+
+// Close does nothing; the keyword filter holds no resources
+func (kf *KeywordFilter) Close() {}
+
+// ScriptFilter evaluates profiles with a user-supplied Lua script.
+// The script must define a global function
+//
+//	function qualify(profile) return accept, score, reason end
+//
+// where profile is a table with url, name, title, company, location,
+// mutual and premium fields, plus company_size, employees, industry,
+// headquarters and company_followers once the company has been looked up, and
+// website_match and website_keywords once its website has been scanned.
+// score and reason are optional. A call that takes longer than ScriptTimeout is stopped and
+// fails.
+type ScriptFilter struct {
+	path    string
+	state   *lua.LState
+	timeout time.Duration
+	mutex   sync.Mutex
+}
+
+// NewScriptFilter loads and validates a Lua qualification script
+func NewScriptFilter(path string) (*ScriptFilter, error) {
+	state := lua.NewState(lua.Options{SkipOpenLibs: true})
+
+	// Only expose side-effect free libraries to user scripts
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		state.Push(state.NewFunction(lib.open))
+		state.Push(lua.LString(lib.name))
+		state.Call(1, 0)
+	}
+	for _, unsafe := range []string{"dofile", "loadfile", "load", "loadstring", "require"} {
+		state.SetGlobal(unsafe, lua.LNil)
+	}
+
+	if err := state.DoFile(path); err != nil {
+		state.Close()
+		return nil, fmt.Errorf("failed to load filter script %s: %w", path, err)
+	}
+
+	if _, ok := state.GetGlobal("qualify").(*lua.LFunction); !ok {
+		state.Close()
+		return nil, fmt.Errorf("filter script %s must define a qualify(profile) function", path)
+	}
+
+	return &ScriptFilter{
+		path:    path,
+		state:   state,
+		timeout: ScriptTimeout,
+	}, nil
+}
+
+// Evaluate calls the script's qualify function for the profile, stopping it when ctx is done or
+// the call runs past the timeout
+func (sf *ScriptFilter) Evaluate(ctx context.Context, profile Profile) (Decision, error) {
+	sf.mutex.Lock()
+	defer sf.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, sf.timeout)
+	defer cancel()
+	sf.state.SetContext(ctx)
+	defer sf.state.RemoveContext()
+
+	table := sf.state.NewTable()
+	table.RawSetString("url", lua.LString(profile.URL))
+	table.RawSetString("name", lua.LString(profile.Name))
+	table.RawSetString("title", lua.LString(profile.Title))
+	table.RawSetString("company", lua.LString(profile.Company))
+	table.RawSetString("location", lua.LString(profile.Location))
+	table.RawSetString("mutual", lua.LNumber(profile.Mutual))
+	table.RawSetString("premium", lua.LBool(profile.Premium))
+	table.RawSetString("company_size", lua.LString(profile.CompanySize))
+	table.RawSetString("employees", lua.LNumber(profile.Employees))
+	table.RawSetString("industry", lua.LString(profile.Industry))
+	table.RawSetString("headquarters", lua.LString(profile.Headquarters))
+	table.RawSetString("company_followers", lua.LNumber(profile.CompanyFollowers))
+	websiteKeywords := sf.state.NewTable()
+	for _, keyword := range profile.WebsiteKeywords {
+		websiteKeywords.Append(lua.LString(keyword))
+	}
+	table.RawSetString("website_match", lua.LBool(len(profile.WebsiteKeywords) > 0))
+	table.RawSetString("website_keywords", websiteKeywords)
+
+	err := sf.state.CallByParam(lua.P{
+		Fn:      sf.state.GetGlobal("qualify"),
+		NRet:    3,
+		Protect: true,
+	}, table)
+	if err != nil {
+		if ctx.Err() != nil {
+			return Decision{}, fmt.Errorf("filter script %s stopped: %w", sf.path, ctx.Err())
+		}
+		return Decision{}, fmt.Errorf("filter script %s failed: %w", sf.path, err)
+	}
+
+	reason := sf.state.Get(-1)
+	score := sf.state.Get(-2)
+	accept := sf.state.Get(-3)
+	sf.state.Pop(3)
+
+	decision := Decision{Accept: lua.LVAsBool(accept)}
+	if number, ok := score.(lua.LNumber); ok {
+		decision.Score = float64(number)
+	} else if score != lua.LNil {
+		return Decision{}, fmt.Errorf("filter script %s returned non-numeric score %q", sf.path, score.String())
+	}
+	if reason != lua.LNil {
+		decision.Reason = reason.String()
+	}
+
+	return decision, nil
+}
+
+// Close releases the script interpreter
+func (sf *ScriptFilter) Close() {
+	sf.mutex.Lock()
+	defer sf.mutex.Unlock()
+
+	sf.state.Close()
+}
@@ -0,0 +1,254 @@
+package leadfilter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pgregory.net/rapid"
+)
+
+func writeScript(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "filter.lua")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write script: %v", err)
+	}
+	return path
+}
+
+// TestKeywordFilterScoring tests that the built-in filter reproduces the title/company heuristic
+func TestKeywordFilterScoring(t *testing.T) {
+	rapid.Check(t, func(rt *rapid.T) {
+		profile := Profile{
+			Name:    rapid.SampledFrom([]string{"", "Jane Doe"}).Draw(rt, "name"),
+			Title:   rapid.SampledFrom([]string{"", "Senior Software Engineer", "Marketing Lead"}).Draw(rt, "title"),
+			Company: rapid.SampledFrom([]string{"", "Acme"}).Draw(rt, "company"),
+		}
+
+		decision, err := NewKeywordFilter(nil, DefaultMinScore).Evaluate(context.Background(), profile)
+		if err != nil {
+			rt.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := 0.0
+		if profile.Name != "" {
+			expected++
+		}
+		if strings.Contains(profile.Title, "Engineer") {
+			expected++
+		}
+		if profile.Company != "" {
+			expected++
+		}
+
+		if decision.Score != expected {
+			rt.Fatalf("expected score %v, got %v", expected, decision.Score)
+		}
+		if decision.Accept != (expected >= 2) {
+			rt.Fatalf("accept mismatch for score %v", expected)
+		}
+	})
+}
+
+// TestScriptFilterDecisions tests that script return values map onto decisions
+func TestScriptFilterDecisions(t *testing.T) {
+	path := writeScript(t, `
+function qualify(profile)
+  local title = string.lower(profile.title)
+  if string.find(title, "cto") then
+    return true, 10 + profile.mutual, "executive"
+  end
+  if profile.premium then
+    return true, 1
+  end
+  return false, 0, "not a target"
+end
+`)
+
+	filter, err := NewScriptFilter(path)
+	if err != nil {
+		t.Fatalf("failed to load script: %v", err)
+	}
+	defer filter.Close()
+
+	rapid.Check(t, func(rt *rapid.T) {
+		profile := Profile{
+			Title:   rapid.SampledFrom([]string{"CTO at Acme", "Recruiter"}).Draw(rt, "title"),
+			Mutual:  rapid.IntRange(0, 500).Draw(rt, "mutual"),
+			Premium: rapid.Bool().Draw(rt, "premium"),
+		}
+
+		decision, err := filter.Evaluate(context.Background(), profile)
+		if err != nil {
+			rt.Fatalf("evaluation failed: %v", err)
+		}
+
+		switch {
+		case profile.Title == "CTO at Acme":
+			if !decision.Accept || decision.Score != float64(10+profile.Mutual) || decision.Reason != "executive" {
+				rt.Fatalf("unexpected executive decision: %+v", decision)
+			}
+		case profile.Premium:
+			if !decision.Accept || decision.Score != 1 || decision.Reason != "" {
+				rt.Fatalf("unexpected premium decision: %+v", decision)
+			}
+		default:
+			if decision.Accept || decision.Reason != "not a target" {
+				rt.Fatalf("unexpected rejection: %+v", decision)
+			}
+		}
+	})
+}
+
+// TestScriptFilterErrors tests invalid scripts and runtime failures
+func TestScriptFilterErrors(t *testing.T) {
+	if _, err := NewScriptFilter(writeScript(t, `x = 1`)); err == nil {
+		t.Errorf("expected error for script without qualify function")
+	}
+
+	if _, err := NewScriptFilter(writeScript(t, `function qualify(`)); err == nil {
+		t.Errorf("expected error for script with syntax error")
+	}
+
+	sandboxed, err := NewScriptFilter(writeScript(t, `
+function qualify(profile)
+  return os.execute("true")
+end
+`))
+	if err != nil {
+		t.Fatalf("failed to load script: %v", err)
+	}
+	defer sandboxed.Close()
+	if _, err := sandboxed.Evaluate(context.Background(), Profile{}); err == nil {
+		t.Errorf("expected os library to be unavailable to scripts")
+	}
+
+	badScore, err := NewScriptFilter(writeScript(t, `
+function qualify(profile)
+  return true, "high"
+end
+`))
+	if err != nil {
+		t.Fatalf("failed to load script: %v", err)
+	}
+	defer badScore.Close()
+	if _, err := badScore.Evaluate(context.Background(), Profile{}); err == nil {
+		t.Errorf("expected error for non-numeric score")
+	}
+}
+
+// TestScriptFilterTimeout tests that a script that never returns is stopped and the filter still
+// evaluates the next profile
+func TestScriptFilterTimeout(t *testing.T) {
+	filter, err := NewScriptFilter(writeScript(t, `
+function qualify(profile)
+  while profile.name == "loop" do end
+  return true
+end
+`))
+	if err != nil {
+		t.Fatalf("failed to load script: %v", err)
+	}
+	defer filter.Close()
+	filter.timeout = 50 * time.Millisecond
+
+	if _, err := filter.Evaluate(context.Background(), Profile{Name: "loop"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the looping script to be stopped, got %v", err)
+	}
+	if decision, err := filter.Evaluate(context.Background(), Profile{Name: "Jane"}); err != nil || !decision.Accept {
+		t.Errorf("expected the filter to work after a timeout, got %+v (%v)", decision, err)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := filter.Evaluate(cancelled, Profile{Name: "loop"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a cancelled run to stop the script, got %v", err)
+	}
+}
+
+// TestScriptFilterFirmographics tests that company data is exposed to scripts
+func TestScriptFilterFirmographics(t *testing.T) {
+	filter, err := NewScriptFilter(writeScript(t, `
+function qualify(profile)
+  if profile.employees >= 50 and profile.industry == "Software Development" then
+    return true, profile.company_followers / 1000, profile.company_size .. " in " .. profile.headquarters
+  end
+  return false
+end
+`))
+	if err != nil {
+		t.Fatalf("failed to load script: %v", err)
+	}
+	defer filter.Close()
+
+	decision, err := filter.Evaluate(context.Background(), Profile{
+		Company:          "Acme",
+		CompanySize:      "51-200 employees",
+		Employees:        51,
+		Industry:         "Software Development",
+		Headquarters:     "Berlin, Germany",
+		CompanyFollowers: 2500,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !decision.Accept || decision.Score != 2.5 || decision.Reason != "51-200 employees in Berlin, Germany" {
+		t.Errorf("unexpected decision %+v", decision)
+	}
+
+	if decision, err := filter.Evaluate(context.Background(), Profile{Company: "Acme"}); err != nil || decision.Accept {
+		t.Errorf("expected a company not looked up yet to be rejected, got %+v (%v)", decision, err)
+	}
+
+	websites, err := NewScriptFilter(writeScript(t, `
+function qualify(profile)
+  return profile.website_match, #profile.website_keywords, table.concat(profile.website_keywords, ",")
+end
+`))
+	if err != nil {
+		t.Fatalf("failed to load script: %v", err)
+	}
+	defer websites.Close()
+
+	decision, err = websites.Evaluate(context.Background(), Profile{WebsiteKeywords: []string{"Kubernetes", "Go"}})
+	if err != nil || !decision.Accept || decision.Score != 2 || decision.Reason != "Kubernetes,Go" {
+		t.Errorf("expected the website keywords exposed, got %+v (%v)", decision, err)
+	}
+	if decision, err := websites.Evaluate(context.Background(), Profile{}); err != nil || decision.Accept || decision.Score != 0 {
+		t.Errorf("expected no website match without keywords, got %+v (%v)", decision, err)
+	}
+}
+
+// TestNewFilterSelection tests that configuration selects the right filter
+func TestNewFilterSelection(t *testing.T) {
+	filter, err := NewFilter(FilterConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keyword, ok := filter.(*KeywordFilter); !ok || keyword.minScore != DefaultMinScore {
+		t.Errorf("expected keyword filter with the default score without script, got %#v", filter)
+	}
+
+	zero := 0.0
+	filter, err = NewFilter(FilterConfig{MinScore: &zero})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keyword, ok := filter.(*KeywordFilter); !ok || keyword.minScore != 0 {
+		t.Errorf("expected an explicit zero score to be kept, got %#v", filter)
+	}
+
+	path := writeScript(t, `function qualify(p) return true end`)
+	filter, err = NewFilter(FilterConfig{Script: path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := filter.(*ScriptFilter); !ok {
+		t.Errorf("expected script filter, got %T", filter)
+	}
+}
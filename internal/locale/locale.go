@@ -0,0 +1,230 @@
+// Package locale keeps what the browser claims about where it is, its time zone, languages and
+// geolocation, consistent with where its IP is. LinkedIn sees a proxy in Germany paired with a
+// US time zone and en-US as a browser pretending to be somewhere it is not.
+package locale
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // Time zones are compared on hosts without a zoneinfo database too
+)
+
+// DefaultAcceptLanguage is what Chrome sends under the --lang stealth flag unless overridden
+const DefaultAcceptLanguage = "en-US"
+
+// MaxDistanceKm is how far the geolocation may be from the IP's location before they disagree;
+// IP locations are rarely more precise than a region
+const MaxDistanceKm = 500
+
+// Geo is where an IP is, as an IP geolocation endpoint answering like https://ipapi.co/json/
+// reports it
+type Geo struct {
+	IP        string  `json:"ip"`
+	Country   string  `json:"country_code"` // ISO 3166 code, e.g. "DE"
+	City      string  `json:"city"`
+	Timezone  string  `json:"timezone"` // IANA name, e.g. "Europe/Berlin"
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Languages string  `json:"languages"` // Spoken in the country, most common first, e.g. "de-DE,hsb,dsb"
+}
+
+// ParseGeo parses an IP geolocation endpoint's answer
+func ParseGeo(body string) (Geo, error) {
+	var geo Geo
+	if err := json.Unmarshal([]byte(body), &geo); err != nil {
+		return Geo{}, fmt.Errorf("invalid IP geolocation: %w", err)
+	}
+	if geo.Country == "" || geo.Timezone == "" {
+		return Geo{}, fmt.Errorf("IP geolocation has no country or time zone")
+	}
+	return geo, nil
+}
+
+// Position is a geolocation pages are given in place of the host's
+type Position struct {
+	Latitude  float64
+	Longitude float64
+	Accuracy  float64 // Metres
+}
+
+// Settings are what the browser claims about where it is. Empty settings leave the browser's own.
+type Settings struct {
+	Timezone       string    // IANA name; the host's unless set
+	AcceptLanguage string    // e.g. "de-DE,de;q=0.9,en;q=0.8"; DefaultAcceptLanguage unless set
+	Geolocation    *Position // None unless set
+}
+
+// Locale is the ICU locale of the first language, e.g. "de_DE", which Intl formats dates and
+// numbers for; empty without an Accept-Language
+func (s Settings) Locale() string {
+	if s.AcceptLanguage == "" {
+		return ""
+	}
+	return strings.ReplaceAll(primaryLanguage(s.AcceptLanguage), "-", "_")
+}
+
+// Mismatch is a setting that disagrees with the IP's location
+type Mismatch struct {
+	Setting  string // "timezone", "accept_language" or "geolocation"
+	Value    string // What the browser claims
+	Expected string // What the IP's location suggests
+}
+
+// String describes the mismatch for logs
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%s %s does not match the IP's %s", m.Setting, m.Value, m.Expected)
+}
+
+// Check compares settings with the IP's location at now, since two time zones agree or not by
+// their UTC offsets, which change with daylight saving time
+func Check(geo Geo, settings Settings, now time.Time) []Mismatch {
+	var mismatches []Mismatch
+
+	expected, err := time.LoadLocation(geo.Timezone)
+	if err == nil {
+		claimed, name := time.Local, "host time zone "+time.Local.String()
+		if settings.Timezone != "" {
+			if claimed, err = time.LoadLocation(settings.Timezone); err != nil {
+				claimed = nil
+			}
+			name = settings.Timezone
+		}
+		if claimed != nil && offset(now, claimed) != offset(now, expected) {
+			mismatches = append(mismatches, Mismatch{Setting: "timezone", Value: name, Expected: geo.Timezone})
+		}
+	}
+
+	acceptLanguage := settings.AcceptLanguage
+	if acceptLanguage == "" {
+		acceptLanguage = DefaultAcceptLanguage
+	}
+	if !languageFits(primaryLanguage(acceptLanguage), geo) {
+		expected := geo.Country
+		if geo.Languages != "" {
+			expected = geo.Country + " (" + geo.Languages + ")"
+		}
+		mismatches = append(mismatches, Mismatch{Setting: "accept_language", Value: acceptLanguage, Expected: expected})
+	}
+
+	if position := settings.Geolocation; position != nil {
+		if distanceKm(position.Latitude, position.Longitude, geo.Latitude, geo.Longitude) > MaxDistanceKm {
+			mismatches = append(mismatches, Mismatch{
+				Setting:  "geolocation",
+				Value:    formatPosition(position.Latitude, position.Longitude),
+				Expected: formatPosition(geo.Latitude, geo.Longitude) + " " + geo.City,
+			})
+		}
+	}
+	return mismatches
+}
+
+// Harmonize derives the settings from the IP's location: its time zone, the country's languages
+// and the IP's position, to the accuracy of a city
+func Harmonize(geo Geo) Settings {
+	return Settings{
+		Timezone:       geo.Timezone,
+		AcceptLanguage: AcceptLanguage(geo.Languages),
+		Geolocation:    &Position{Latitude: geo.Latitude, Longitude: geo.Longitude, Accuracy: 5000},
+	}
+}
+
+// AcceptLanguage builds an Accept-Language header from a country's languages, most common first,
+// the way Chrome does: the first two languages with their base languages, then English, each
+// with a lower quality. "de-DE,hsb" becomes "de-DE,de;q=0.9,hsb;q=0.8,en-US;q=0.7,en;q=0.6".
+func AcceptLanguage(languages string) string {
+	var tags []string
+	seen := make(map[string]bool)
+	add := func(tag string) {
+		if tag != "" && !seen[strings.ToLower(tag)] {
+			seen[strings.ToLower(tag)] = true
+			tags = append(tags, tag)
+		}
+	}
+	for i, language := range strings.Split(languages, ",") {
+		if i == 2 {
+			break
+		}
+		language = strings.TrimSpace(language)
+		add(language)
+		base, _, _ := strings.Cut(language, "-")
+		add(base)
+	}
+	add("en-US")
+	add("en")
+
+	header := tags[0]
+	for i, tag := range tags[1:] {
+		q := max(0.9-0.1*float64(i), 0.1)
+		header += "," + tag + ";q=" + strconv.FormatFloat(q, 'f', 1, 64)
+	}
+	return header
+}
+
+// ValidAcceptLanguage reports whether header is a list of language tags with optional qualities
+func ValidAcceptLanguage(header string) bool {
+	for _, entry := range strings.Split(header, ",") {
+		tag, quality, hasQuality := strings.Cut(strings.TrimSpace(entry), ";")
+		if tag == "" || strings.IndexFunc(tag, func(r rune) bool {
+			return !(r == '-' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9'))
+		}) >= 0 {
+			return false
+		}
+		if hasQuality {
+			value, ok := strings.CutPrefix(strings.TrimSpace(quality), "q=")
+			if q, err := strconv.ParseFloat(value, 64); !ok || err != nil || q < 0 || q > 1 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// primaryLanguage returns the first tag of an Accept-Language header, e.g. "de-DE"
+func primaryLanguage(header string) string {
+	first, _, _ := strings.Cut(header, ",")
+	tag, _, _ := strings.Cut(first, ";")
+	return strings.TrimSpace(tag)
+}
+
+// languageFits reports whether a language tag suits the IP's country: its region is the
+// country, or without a region, the language is spoken there
+func languageFits(tag string, geo Geo) bool {
+	language, region, hasRegion := strings.Cut(tag, "-")
+	if hasRegion {
+		return strings.EqualFold(region, geo.Country)
+	}
+	if geo.Languages == "" {
+		return true
+	}
+	for _, spoken := range strings.Split(geo.Languages, ",") {
+		base, _, _ := strings.Cut(strings.TrimSpace(spoken), "-")
+		if strings.EqualFold(base, language) {
+			return true
+		}
+	}
+	return false
+}
+
+// offset returns the UTC offset of location at now, in seconds
+func offset(now time.Time, location *time.Location) int {
+	_, seconds := now.In(location).Zone()
+	return seconds
+}
+
+// distanceKm is the great-circle distance between two positions
+func distanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKm = 6371
+	rad := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	dLat, dLon := rad(lat2-lat1), rad(lon2-lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(rad(lat1))*math.Cos(rad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// formatPosition formats a position for logs
+func formatPosition(latitude, longitude float64) string {
+	return strconv.FormatFloat(latitude, 'f', 4, 64) + "," + strconv.FormatFloat(longitude, 'f', 4, 64)
+}
@@ -0,0 +1,86 @@
+package locale
+
+import (
+	"testing"
+	"time"
+)
+
+var berlin = Geo{
+	IP:        "203.0.113.7",
+	Country:   "DE",
+	City:      "Berlin",
+	Timezone:  "Europe/Berlin",
+	Latitude:  52.52,
+	Longitude: 13.40,
+	Languages: "de-DE,hsb,dsb",
+}
+
+// TestCheck tests that each setting disagreeing with the IP's location is reported, and that
+// time zones are compared by their offsets
+func TestCheck(t *testing.T) {
+	summer := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	consistent := Settings{
+		Timezone:       "Europe/Paris", // Same offset as Berlin
+		AcceptLanguage: "de-DE,de;q=0.9,en;q=0.8",
+		Geolocation:    &Position{Latitude: 52.40, Longitude: 13.06},
+	}
+	if mismatches := Check(berlin, consistent, summer); len(mismatches) != 0 {
+		t.Errorf("expected no mismatches, got %v", mismatches)
+	}
+	if mismatches := Check(berlin, Settings{Timezone: "Europe/Berlin", AcceptLanguage: "de"}, summer); len(mismatches) != 0 {
+		t.Errorf("expected a language without region spoken in the country to fit, got %v", mismatches)
+	}
+
+	inconsistent := Settings{
+		Timezone:    "America/New_York",
+		Geolocation: &Position{Latitude: 40.71, Longitude: -74.00},
+	}
+	found := make(map[string]bool)
+	for _, mismatch := range Check(berlin, inconsistent, summer) {
+		found[mismatch.Setting] = true
+	}
+	// An unset Accept-Language is Chrome's en-US, which does not fit Germany
+	for _, setting := range []string{"timezone", "accept_language", "geolocation"} {
+		if !found[setting] {
+			t.Errorf("expected a %s mismatch, got %v", setting, found)
+		}
+	}
+}
+
+// TestHarmonize tests that harmonized settings agree with the location they came from
+func TestHarmonize(t *testing.T) {
+	settings := Harmonize(berlin)
+	if settings.AcceptLanguage != "de-DE,de;q=0.9,hsb;q=0.8,en-US;q=0.7,en;q=0.6" {
+		t.Errorf("unexpected Accept-Language %q", settings.AcceptLanguage)
+	}
+	if settings.Locale() != "de_DE" {
+		t.Errorf("unexpected locale %q", settings.Locale())
+	}
+	if !ValidAcceptLanguage(settings.AcceptLanguage) {
+		t.Errorf("harmonized Accept-Language %q is not valid", settings.AcceptLanguage)
+	}
+	if mismatches := Check(berlin, settings, time.Now()); len(mismatches) != 0 {
+		t.Errorf("expected harmonized settings to agree, got %v", mismatches)
+	}
+	if header := AcceptLanguage(""); header != "en-US,en;q=0.9" {
+		t.Errorf("expected English without languages, got %q", header)
+	}
+}
+
+// TestParseGeo tests reading an ipapi.co style answer
+func TestParseGeo(t *testing.T) {
+	geo, err := ParseGeo(`{"ip": "203.0.113.7", "city": "Berlin", "country_code": "DE", "timezone": "Europe/Berlin",
+		"latitude": 52.52, "longitude": 13.40, "languages": "de-DE,hsb,dsb", "org": "Example"}`)
+	if err != nil || geo != berlin {
+		t.Fatalf("unexpected geo %+v, %v", geo, err)
+	}
+	if _, err := ParseGeo(`{"error": true, "reason": "RateLimited"}`); err == nil {
+		t.Error("expected an answer without a location rejected")
+	}
+	for _, header := range []string{"de-DE;q=2", "de DE", ""} {
+		if ValidAcceptLanguage(header) {
+			t.Errorf("expected %q rejected", header)
+		}
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"io"
 	"os"
 	"time"
+
+	"linkedin-automation-framework/internal/errors"
 )
 
 // Logger interface for structured logging
@@ -64,15 +66,18 @@ type LogEntry struct {
 	Timestamp string                 `json:"timestamp"`
 	Level     string                 `json:"level"`
 	Message   string                 `json:"message"`
+	Run       string                 `json:"run,omitempty"`
 	Module    string                 `json:"module,omitempty"`
 	Action    string                 `json:"action,omitempty"`
 	Profile   string                 `json:"profile,omitempty"`
+	Code      errors.Code            `json:"code,omitempty"` // Code of the first error field that has one
 	Fields    map[string]interface{} `json:"fields,omitempty"`
 }
 
 // LoggerManager implements Logger interface
 type LoggerManager struct {
 	config  LoggingConfig
+	run     string
 	module  string
 	action  string
 	profile string
@@ -102,7 +107,13 @@ func getWriter(output string) io.Writer {
 	}
 }
 
-// F creates a new log field
+// SetRunID tags every later entry from this logger, and from loggers derived from it afterwards, with the run ID
+func (l *LoggerManager) SetRunID(runID string) {
+	l.run = runID
+}
+
+// F creates a new log field. An error value is logged as its message, and its code, if it has
+// one, tags the entry.
 func F(key string, value interface{}) Field {
 	return Field{Key: key, Value: value}
 }
@@ -122,6 +133,7 @@ func (l *LoggerManager) log(level LogLevel, msg string, fields ...Field) {
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 		Level:     level.String(),
 		Message:   msg,
+		Run:       l.run,
 		Module:    l.module,
 		Action:    l.action,
 		Profile:   l.profile,
@@ -131,6 +143,13 @@ func (l *LoggerManager) log(level LogLevel, msg string, fields ...Field) {
 	if len(fields) > 0 {
 		entry.Fields = make(map[string]interface{})
 		for _, field := range fields {
+			if err, ok := field.Value.(error); ok {
+				if entry.Code == "" {
+					entry.Code = errors.CodeOf(err)
+				}
+				entry.Fields[field.Key] = err.Error()
+				continue
+			}
 			entry.Fields[field.Key] = field.Value
 		}
 	}
@@ -159,6 +178,9 @@ func (l *LoggerManager) writeJSON(entry LogEntry) {
 func (l *LoggerManager) writeText(entry LogEntry) {
 	output := fmt.Sprintf("[%s] %s %s", entry.Timestamp, entry.Level, entry.Message)
 	
+	if entry.Run != "" {
+		output += fmt.Sprintf(" run=%s", entry.Run)
+	}
 	if entry.Module != "" {
 		output += fmt.Sprintf(" module=%s", entry.Module)
 	}
@@ -168,6 +190,9 @@ func (l *LoggerManager) writeText(entry LogEntry) {
 	if entry.Profile != "" {
 		output += fmt.Sprintf(" profile=%s", entry.Profile)
 	}
+	if entry.Code != "" {
+		output += fmt.Sprintf(" code=%s", entry.Code)
+	}
 	
 	if entry.Fields != nil {
 		for key, value := range entry.Fields {
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"linkedin-automation-framework/internal/errors"
 	"pgregory.net/rapid"
 )
 
@@ -241,4 +242,62 @@ func TestCustomFields(t *testing.T) {
 	if entry.Fields["key2"] != float64(42) { // JSON unmarshals numbers as float64
 		t.Errorf("Expected key2=42, got %v", entry.Fields["key2"])
 	}
-}
\ No newline at end of file
+}
+// Unit test for run IDs on every entry
+func TestRunID(t *testing.T) {
+	for _, format := range []string{"json", "text"} {
+		var buf bytes.Buffer
+		logger := NewLogger(LoggingConfig{Level: InfoLevel, Format: format, Output: "stdout"})
+		logger.writer = &buf
+		logger.SetRunID("2024-06-12-3")
+
+		ctx := context.Background()
+		logger.Info(ctx, "first")
+		logger.WithModule("connect").Info(ctx, "second")
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("Expected 2 %s lines, got %d", format, len(lines))
+		}
+		for _, line := range lines {
+			if format == "json" {
+				var entry LogEntry
+				if err := json.Unmarshal([]byte(line), &entry); err != nil {
+					t.Fatalf("Failed to unmarshal JSON: %v", err)
+				}
+				if entry.Run != "2024-06-12-3" {
+					t.Errorf("Expected run 2024-06-12-3, got %q", entry.Run)
+				}
+			} else if !strings.Contains(line, "run=2024-06-12-3") {
+				t.Errorf("Expected run in text line %q", line)
+			}
+		}
+	}
+}
+
+// Unit test for error fields, logged as their message with the entry tagged by their code
+func TestErrorCode(t *testing.T) {
+	for _, format := range []string{"json", "text"} {
+		var buf bytes.Buffer
+		logger := NewLogger(LoggingConfig{Level: InfoLevel, Format: format, Output: "stdout"})
+		logger.writer = &buf
+
+		err := errors.NewError(errors.ErrorTypeRateLimit, "send_connection_request", "rate limit exceeded", nil)
+		logger.Error(context.Background(), "failed", F("error", err), F("attempt", 2))
+
+		line := strings.TrimSpace(buf.String())
+		if format == "text" {
+			if !strings.Contains(line, "code=E_RATE_LIMIT") {
+				t.Errorf("Expected the code in text line %q", line)
+			}
+			continue
+		}
+		var entry LogEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Failed to unmarshal JSON: %v", err)
+		}
+		if entry.Code != errors.CodeRateLimit || entry.Fields["error"] != err.Error() {
+			t.Errorf("Expected the error's code and message, got %+v", entry)
+		}
+	}
+}
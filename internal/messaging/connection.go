@@ -0,0 +1,38 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+
+	"linkedin-automation-framework/internal/browser"
+	"linkedin-automation-framework/internal/identity"
+)
+
+// ConnectionTemplate is the template name messages to existing connections are tracked under
+const ConnectionTemplate = "connection"
+
+// MessageConnection opens a first-degree connection's profile and messages them from it, which
+// unlike SendMessage does not need an earlier conversation
+func (mm *MessagingManager) MessageConnection(ctx context.Context, page browser.PageDriver, recipient AcceptedConnection, body string) error {
+	if page == nil {
+		return fmt.Errorf("page cannot be nil")
+	}
+
+	page = page.Context(ctx)
+	if err := page.Navigate(identity.NormalizeProfileURL(recipient.ProfileURL)); err != nil {
+		return fmt.Errorf("failed to navigate to profile: %w", err)
+	}
+	if err := page.WaitLoad(); err != nil {
+		return fmt.Errorf("profile page did not load: %w", err)
+	}
+
+	signals, err := mm.readProfileSignals(page)
+	if err != nil {
+		return err
+	}
+	// A missing badge is not proof either way, so only another degree stops the message
+	if signals.Degree != "" && signals.Degree != "1st" {
+		return fmt.Errorf("%s is a %s degree connection, not a first-degree one", recipient.ProfileURL, signals.Degree)
+	}
+	return mm.sendFromProfile(ctx, page, recipient, ConnectionTemplate, "", body)
+}
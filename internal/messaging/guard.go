@@ -0,0 +1,73 @@
+package messaging
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"linkedin-automation-framework/internal/identity"
+)
+
+// Errors of messages the contact guard holds back
+var (
+	// ErrDuplicateMessage is returned for a message the recipient already got word for word
+	ErrDuplicateMessage = errors.New("the same message was already sent to this recipient")
+	// ErrContactedRecently is returned for a message to a recipient messaged within the interval
+	ErrContactedRecently = errors.New("recipient was messaged too recently")
+)
+
+// MessageHistory is a record of sent messages the contact guard checks, such as the storage of
+// another account
+type MessageHistory interface {
+	GetMessageHistory() ([]SentMessage, error)
+}
+
+// contactGuard holds back messages to recipients who got the same text before, or any message
+// within the interval
+type contactGuard struct {
+	interval  time.Duration
+	histories []MessageHistory
+}
+
+// SetContactGuard makes every message first check the messages in storage and in others, such
+// as the storage of the other accounts of a deployment: a recipient who already got the same
+// text is not sent it again, and one messaged within interval is not messaged again until it
+// passed. An interval of 0 holds back duplicates only.
+func (mm *MessagingManager) SetContactGuard(interval time.Duration, others ...MessageHistory) {
+	mm.guard = &contactGuard{interval: interval, histories: append([]MessageHistory{mm.storage}, others...)}
+}
+
+// checkContact returns an error wrapping ErrDuplicateMessage or ErrContactedRecently when the
+// guard holds back content to the recipient at now, and nil without a guard
+func (mm *MessagingManager) checkContact(recipientURL, content string, now time.Time) error {
+	if mm.guard == nil {
+		return nil
+	}
+	key := identity.ProfileKey(recipientURL)
+	var last time.Time
+	for _, history := range mm.guard.histories {
+		if history == nil {
+			continue
+		}
+		messages, err := history.GetMessageHistory()
+		if err != nil {
+			return fmt.Errorf("failed to check earlier messages to %s: %w", recipientURL, err)
+		}
+		for _, message := range messages {
+			if identity.ProfileKey(message.RecipientURL) != key {
+				continue
+			}
+			if message.Content == content {
+				return fmt.Errorf("%w on %s", ErrDuplicateMessage, message.SentAt.Format("2006-01-02"))
+			}
+			if message.SentAt.After(last) {
+				last = message.SentAt
+			}
+		}
+	}
+	if mm.guard.interval > 0 && !last.IsZero() && now.Sub(last) < mm.guard.interval {
+		return fmt.Errorf("%w: last messaged %s, next message allowed after %s", ErrContactedRecently,
+			last.Format("2006-01-02 15:04"), last.Add(mm.guard.interval).Format("2006-01-02 15:04"))
+	}
+	return nil
+}
@@ -6,17 +6,19 @@ import (
 	"strings"
 	"time"
 
-	"github.com/go-rod/rod"
+	"linkedin-automation-framework/internal/browser"
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/selectors"
 )
 
 // MessageSender interface for LinkedIn messaging functionality
 type MessageSender interface {
-	SendMessage(ctx context.Context, page *rod.Page, connection AcceptedConnection, template MessageTemplate) error
-	DetectAcceptedConnections(ctx context.Context, page *rod.Page) ([]AcceptedConnection, error)
+	SendMessage(ctx context.Context, page browser.PageDriver, connection AcceptedConnection, template MessageTemplate) error
+	DetectAcceptedConnections(ctx context.Context, page browser.PageDriver) ([]AcceptedConnection, error)
 	TrackMessage(message SentMessage) error
 	SubstituteVariables(template MessageTemplate, variables map[string]string) (string, error)
-	NavigateToMessaging(ctx context.Context, page *rod.Page) error
-	FindConversation(ctx context.Context, page *rod.Page, connectionName string) (*rod.Element, error)
+	NavigateToMessaging(ctx context.Context, page browser.PageDriver) error
+	FindConversation(ctx context.Context, page browser.PageDriver, connectionName string) (browser.ElementDriver, error)
 }
 
 // AcceptedConnection represents a newly accepted LinkedIn connection
@@ -52,6 +54,8 @@ type MessagingManager struct {
 	storage     StorageInterface
 	rateLimiter RateLimiterInterface
 	stealth     StealthInterface
+	selectors   selectors.Set
+	guard       *contactGuard // Holds back repeated and too frequent messages; nil sends them all
 }
 
 // StorageInterface defines storage operations needed by messaging
@@ -80,9 +84,9 @@ type RateLimiterInterface interface {
 
 // StealthInterface defines stealth operations needed by messaging
 type StealthInterface interface {
-	HumanMouseMove(ctx context.Context, page *rod.Page, target *rod.Element) error
-	HumanType(ctx context.Context, element *rod.Element, text string) error
-	RandomDelay(min, max time.Duration) error
+	MoveMouse(ctx context.Context, page browser.PageDriver, target browser.ElementDriver) error
+	TypeText(ctx context.Context, element browser.ElementDriver, text string) error
+	RandomDelay(ctx context.Context, min, max time.Duration) error
 }
 
 // NewMessagingManager creates a new messaging manager
@@ -91,11 +95,17 @@ func NewMessagingManager(storage StorageInterface, rateLimiter RateLimiterInterf
 		storage:     storage,
 		rateLimiter: rateLimiter,
 		stealth:     stealth,
+		selectors:   selectors.Desktop,
 	}
 }
 
+// SetSelectors switches the selectors used on connection and messaging pages, e.g. to selectors.Mobile
+func (mm *MessagingManager) SetSelectors(set selectors.Set) {
+	mm.selectors = set
+}
+
 // DetectAcceptedConnections detects newly accepted connections
-func (mm *MessagingManager) DetectAcceptedConnections(ctx context.Context, page *rod.Page) ([]AcceptedConnection, error) {
+func (mm *MessagingManager) DetectAcceptedConnections(ctx context.Context, page browser.PageDriver) ([]AcceptedConnection, error) {
 	if page == nil {
 		return nil, fmt.Errorf("page cannot be nil")
 	}
@@ -113,7 +123,7 @@ func (mm *MessagingManager) DetectAcceptedConnections(ctx context.Context, page
 
 	// Add delay for page to fully render
 	if mm.stealth != nil {
-		err = mm.stealth.RandomDelay(2*time.Second, 4*time.Second)
+		err = mm.stealth.RandomDelay(ctx, 2*time.Second, 4*time.Second)
 		if err != nil {
 			return nil, fmt.Errorf("failed to add page load delay: %w", err)
 		}
@@ -128,22 +138,14 @@ func (mm *MessagingManager) DetectAcceptedConnections(ctx context.Context, page
 	// Create a map of sent requests for quick lookup
 	sentRequestsMap := make(map[string]ConnectionRequest)
 	for _, req := range sentRequests {
-		sentRequestsMap[req.ProfileURL] = req
-	}
-
-	// Find connection elements on the page
-	connectionSelectors := []string{
-		".mn-connection-card",
-		".connection-card",
-		"[data-test-id='connection-card']",
-		".mn-connections__card",
+		sentRequestsMap[identity.NormalizeProfileURL(req.ProfileURL)] = req
 	}
 
 	var connections []AcceptedConnection
-	var connectionElements []*rod.Element
+	var connectionElements []browser.ElementDriver
 
 	// Try different selectors to find connection cards
-	for _, selector := range connectionSelectors {
+	for _, selector := range mm.selectors.ConnectionCards {
 		elements, err := page.Elements(selector)
 		if err == nil && len(elements) > 0 {
 			connectionElements = elements
@@ -198,62 +200,40 @@ func (mm *MessagingManager) DetectAcceptedConnections(ctx context.Context, page
 }
 
 // parseConnectionElement extracts connection information from a DOM element
-func (mm *MessagingManager) parseConnectionElement(element *rod.Element) (AcceptedConnection, error) {
+func (mm *MessagingManager) parseConnectionElement(element browser.ElementDriver) (AcceptedConnection, error) {
 	var connection AcceptedConnection
 
 	// Try to extract name
-	nameSelectors := []string{
-		".mn-connection-card__name",
-		".connection-card__name",
-		"[data-test-id='connection-name']",
-		"h3",
-		".name",
-	}
-
-	for _, selector := range nameSelectors {
+	for _, selector := range mm.selectors.ConnectionName {
 		nameElement, err := element.Element(selector)
 		if err == nil && nameElement != nil {
 			name, err := nameElement.Text()
 			if err == nil && name != "" {
-				connection.Name = strings.TrimSpace(name)
+				connection.Name = strings.TrimSpace(identity.StripFormatting(name))
 				break
 			}
 		}
 	}
 
 	// Try to extract title
-	titleSelectors := []string{
-		".mn-connection-card__occupation",
-		".connection-card__title",
-		"[data-test-id='connection-title']",
-		".occupation",
-		".title",
-	}
-
-	for _, selector := range titleSelectors {
+	for _, selector := range mm.selectors.ConnectionTitle {
 		titleElement, err := element.Element(selector)
 		if err == nil && titleElement != nil {
 			title, err := titleElement.Text()
 			if err == nil && title != "" {
-				connection.Title = strings.TrimSpace(title)
+				connection.Title = strings.TrimSpace(identity.StripFormatting(title))
 				break
 			}
 		}
 	}
 
 	// Try to extract profile URL
-	linkSelectors := []string{
-		"a[href*='/in/']",
-		".mn-connection-card__link",
-		".connection-card__link",
-	}
-
-	for _, selector := range linkSelectors {
+	for _, selector := range mm.selectors.ConnectionLink {
 		linkElement, err := element.Element(selector)
 		if err == nil && linkElement != nil {
 			href, err := linkElement.Attribute("href")
 			if err == nil && href != nil && strings.Contains(*href, "/in/") {
-				connection.ProfileURL = *href
+				connection.ProfileURL = identity.NormalizeProfileURL(*href)
 				break
 			}
 		}
@@ -299,7 +279,7 @@ func (mm *MessagingManager) SubstituteVariables(template MessageTemplate, variab
 }
 
 // NavigateToMessaging navigates to LinkedIn messaging interface
-func (mm *MessagingManager) NavigateToMessaging(ctx context.Context, page *rod.Page) error {
+func (mm *MessagingManager) NavigateToMessaging(ctx context.Context, page browser.PageDriver) error {
 	if page == nil {
 		return fmt.Errorf("page cannot be nil")
 	}
@@ -317,7 +297,7 @@ func (mm *MessagingManager) NavigateToMessaging(ctx context.Context, page *rod.P
 
 	// Add delay for page to fully render
 	if mm.stealth != nil {
-		err = mm.stealth.RandomDelay(2*time.Second, 4*time.Second)
+		err = mm.stealth.RandomDelay(ctx, 2*time.Second, 4*time.Second)
 		if err != nil {
 			return fmt.Errorf("failed to add messaging page load delay: %w", err)
 		}
@@ -327,7 +307,7 @@ func (mm *MessagingManager) NavigateToMessaging(ctx context.Context, page *rod.P
 }
 
 // FindConversation finds a conversation with a specific connection
-func (mm *MessagingManager) FindConversation(ctx context.Context, page *rod.Page, connectionName string) (*rod.Element, error) {
+func (mm *MessagingManager) FindConversation(ctx context.Context, page browser.PageDriver, connectionName string) (browser.ElementDriver, error) {
 	if page == nil {
 		return nil, fmt.Errorf("page cannot be nil")
 	}
@@ -336,18 +316,10 @@ func (mm *MessagingManager) FindConversation(ctx context.Context, page *rod.Page
 		return nil, fmt.Errorf("connection name cannot be empty")
 	}
 
-	// Try different selectors for conversation list items
-	conversationSelectors := []string{
-		".msg-conversation-listitem",
-		".conversation-item",
-		"[data-test-id='conversation-item']",
-		".msg-conversations-container li",
-	}
-
-	var conversationElements []*rod.Element
+	var conversationElements []browser.ElementDriver
 
 	// Find conversation elements
-	for _, selector := range conversationSelectors {
+	for _, selector := range mm.selectors.Conversations {
 		elements, err := page.Elements(selector)
 		if err == nil && len(elements) > 0 {
 			conversationElements = elements
@@ -380,7 +352,7 @@ func (mm *MessagingManager) FindConversation(ctx context.Context, page *rod.Page
 }
 
 // SendMessage sends a follow-up message to an accepted connection
-func (mm *MessagingManager) SendMessage(ctx context.Context, page *rod.Page, connection AcceptedConnection, template MessageTemplate) error {
+func (mm *MessagingManager) SendMessage(ctx context.Context, page browser.PageDriver, connection AcceptedConnection, template MessageTemplate) error {
 	// Check rate limiting first
 	if mm.rateLimiter != nil && !mm.rateLimiter.CanSendMessage() {
 		return fmt.Errorf("rate limit exceeded, cannot send message")
@@ -390,8 +362,27 @@ func (mm *MessagingManager) SendMessage(ctx context.Context, page *rod.Page, con
 		return fmt.Errorf("page cannot be nil")
 	}
 
+	// Prepare message content with variable substitution
+	parsed := identity.ParseName(connection.Name)
+	variables := map[string]string{
+		"name":       connection.Name,
+		"first_name": parsed.First,
+		"last_name":  parsed.Last,
+		"salutation": identity.Salutation(connection.Name, "there"),
+		"title":      connection.Title,
+		"company":    connection.Company,
+	}
+
+	messageContent, err := mm.SubstituteVariables(template, variables)
+	if err != nil {
+		return fmt.Errorf("failed to substitute template variables: %w", err)
+	}
+	if err := mm.checkContact(connection.ProfileURL, messageContent, time.Now()); err != nil {
+		return err
+	}
+
 	// Navigate to messaging interface
-	err := mm.NavigateToMessaging(ctx, page)
+	err = mm.NavigateToMessaging(ctx, page)
 	if err != nil {
 		return fmt.Errorf("failed to navigate to messaging: %w", err)
 	}
@@ -404,42 +395,30 @@ func (mm *MessagingManager) SendMessage(ctx context.Context, page *rod.Page, con
 
 	// Click on the conversation to open it
 	if mm.stealth != nil {
-		err = mm.stealth.HumanMouseMove(ctx, page, conversation)
+		err = mm.stealth.MoveMouse(ctx, page, conversation)
 		if err != nil {
 			return fmt.Errorf("failed to move mouse to conversation: %w", err)
 		}
 
-		err = mm.stealth.RandomDelay(500*time.Millisecond, 1500*time.Millisecond)
+		err = mm.stealth.RandomDelay(ctx, 500*time.Millisecond, 1500*time.Millisecond)
 		if err != nil {
 			return fmt.Errorf("failed to add pre-click delay: %w", err)
 		}
 	}
 
-	err = conversation.Click("left", 1)
+	err = conversation.Click()
 	if err != nil {
 		return fmt.Errorf("failed to click conversation: %w", err)
 	}
 
 	// Wait for conversation to load
 	if mm.stealth != nil {
-		err = mm.stealth.RandomDelay(2*time.Second, 4*time.Second)
+		err = mm.stealth.RandomDelay(ctx, 2*time.Second, 4*time.Second)
 		if err != nil {
 			return fmt.Errorf("failed to add conversation load delay: %w", err)
 		}
 	}
 
-	// Prepare message content with variable substitution
-	variables := map[string]string{
-		"name":    connection.Name,
-		"title":   connection.Title,
-		"company": connection.Company,
-	}
-
-	messageContent, err := mm.SubstituteVariables(template, variables)
-	if err != nil {
-		return fmt.Errorf("failed to substitute template variables: %w", err)
-	}
-
 	// Find the message input field
 	messageInput, err := mm.findMessageInput(page)
 	if err != nil {
@@ -448,7 +427,7 @@ func (mm *MessagingManager) SendMessage(ctx context.Context, page *rod.Page, con
 
 	// Type the message using stealth behavior
 	if mm.stealth != nil {
-		err = mm.stealth.HumanType(ctx, messageInput, messageContent)
+		err = mm.stealth.TypeText(ctx, messageInput, messageContent)
 		if err != nil {
 			return fmt.Errorf("failed to type message: %w", err)
 		}
@@ -466,25 +445,25 @@ func (mm *MessagingManager) SendMessage(ctx context.Context, page *rod.Page, con
 	}
 
 	if mm.stealth != nil {
-		err = mm.stealth.HumanMouseMove(ctx, page, sendButton)
+		err = mm.stealth.MoveMouse(ctx, page, sendButton)
 		if err != nil {
 			return fmt.Errorf("failed to move mouse to send button: %w", err)
 		}
 
-		err = mm.stealth.RandomDelay(500*time.Millisecond, 1000*time.Millisecond)
+		err = mm.stealth.RandomDelay(ctx, 500*time.Millisecond, 1000*time.Millisecond)
 		if err != nil {
 			return fmt.Errorf("failed to add pre-send delay: %w", err)
 		}
 	}
 
-	err = sendButton.Click("left", 1)
+	err = sendButton.Click()
 	if err != nil {
 		return fmt.Errorf("failed to click send button: %w", err)
 	}
 
 	// Track the sent message
 	sentMessage := SentMessage{
-		RecipientURL:  connection.ProfileURL,
+		RecipientURL:  identity.NormalizeProfileURL(connection.ProfileURL),
 		RecipientName: connection.Name,
 		Template:      template.Name,
 		Content:       messageContent,
@@ -506,16 +485,8 @@ func (mm *MessagingManager) SendMessage(ctx context.Context, page *rod.Page, con
 }
 
 // findMessageInput finds the message input field
-func (mm *MessagingManager) findMessageInput(page *rod.Page) (*rod.Element, error) {
-	inputSelectors := []string{
-		".msg-form__contenteditable",
-		"[data-test-id='message-input']",
-		".msg-form__msg-content-container div[contenteditable='true']",
-		"div[contenteditable='true'][role='textbox']",
-		".compose-publisher__editor div[contenteditable='true']",
-	}
-
-	for _, selector := range inputSelectors {
+func (mm *MessagingManager) findMessageInput(page browser.PageDriver) (browser.ElementDriver, error) {
+	for _, selector := range mm.selectors.MessageInput {
 		element, err := page.Element(selector)
 		if err == nil && element != nil {
 			visible, err := element.Visible()
@@ -529,16 +500,8 @@ func (mm *MessagingManager) findMessageInput(page *rod.Page) (*rod.Element, erro
 }
 
 // findSendButton finds the send button
-func (mm *MessagingManager) findSendButton(page *rod.Page) (*rod.Element, error) {
-	sendSelectors := []string{
-		".msg-form__send-button",
-		"[data-test-id='send-button']",
-		"button[type='submit'][aria-label*='Send']",
-		".msg-form__send-btn",
-		"button:has-text('Send')",
-	}
-
-	for _, selector := range sendSelectors {
+func (mm *MessagingManager) findSendButton(page browser.PageDriver) (browser.ElementDriver, error) {
+	for _, selector := range mm.selectors.MessageSend {
 		element, err := page.Element(selector)
 		if err == nil && element != nil {
 			visible, err := element.Visible()
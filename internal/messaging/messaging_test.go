@@ -2,13 +2,17 @@ package messaging
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
 	"time"
 
-	"github.com/go-rod/rod"
 	"pgregory.net/rapid"
+
+	"linkedin-automation-framework/internal/browser"
+	"linkedin-automation-framework/internal/browser/browsertest"
+	"linkedin-automation-framework/internal/identity"
 )
 
 // Mock implementations for testing
@@ -56,15 +60,15 @@ func (mrl *mockRateLimiter) GetMessageCount(window time.Duration) int {
 
 type mockStealth struct{}
 
-func (ms *mockStealth) HumanMouseMove(ctx context.Context, page *rod.Page, target *rod.Element) error {
+func (ms *mockStealth) MoveMouse(ctx context.Context, page browser.PageDriver, target browser.ElementDriver) error {
 	return nil
 }
 
-func (ms *mockStealth) HumanType(ctx context.Context, element *rod.Element, text string) error {
-	return nil
+func (ms *mockStealth) TypeText(ctx context.Context, element browser.ElementDriver, text string) error {
+	return element.Input(text)
 }
 
-func (ms *mockStealth) RandomDelay(min, max time.Duration) error {
+func (ms *mockStealth) RandomDelay(ctx context.Context, min, max time.Duration) error {
 	return nil
 }
 
@@ -185,6 +189,41 @@ func TestAcceptedConnectionDetection(t *testing.T) {
 		} else {
 			t.Fatalf("should be able to count accepted connections")
 		}
+
+		// Property: of the cards on the connections page, exactly those we sent a request to are
+		// reported as accepted, in page order
+		sent := make(map[string]bool)
+		for _, req := range sentRequests {
+			sent[identity.NormalizeProfileURL(req.ProfileURL)] = true
+		}
+		strangers := rapid.SliceOfN(genConnectionRequest(), 0, 5).Draw(t, "strangers")
+		cards := append(append([]ConnectionRequest(nil), sentRequests...), strangers...)
+		cards = rapid.Permutation(cards).Draw(t, "cards")
+
+		page := browsertest.NewPage("https://www.linkedin.com/mynetwork/")
+		var want []string
+		for _, card := range cards {
+			page.Append(browsertest.NewElement(mm.selectors.ConnectionCards[0]).Append(
+				browsertest.NewElement(mm.selectors.ConnectionName[0]).SetText(card.ProfileName),
+				browsertest.NewElement(mm.selectors.ConnectionLink[0]).SetAttribute("href", card.ProfileURL),
+			))
+			if url := identity.NormalizeProfileURL(card.ProfileURL); sent[url] {
+				want = append(want, url)
+			}
+		}
+
+		accepted, err := mm.DetectAcceptedConnections(context.Background(), page)
+		if err != nil {
+			t.Fatalf("failed to detect accepted connections: %v", err)
+		}
+		if len(accepted) != len(want) {
+			t.Fatalf("expected %d accepted connections, got %d", len(want), len(accepted))
+		}
+		for i, connection := range accepted {
+			if connection.ProfileURL != want[i] {
+				t.Fatalf("expected %s at %d, got %s", want[i], i, connection.ProfileURL)
+			}
+		}
 	})
 }
 
@@ -298,6 +337,42 @@ func TestMessageSendingToCorrectRecipients(t *testing.T) {
 			t.Fatalf("message template should match provided template: got %s, want %s", 
 				storedMessage.Template, template.Name)
 		}
+
+		// Property: in the inbox, only the recipient's conversation is opened and the personalized
+		// message is typed there and sent
+		others := rapid.IntRange(0, 5).Draw(t, "others")
+		position := rapid.IntRange(0, others).Draw(t, "position")
+		page := browsertest.NewPage("https://www.linkedin.com/messaging/")
+		var conversations []*browsertest.Element
+		for i := 0; i <= others; i++ {
+			name := fmt.Sprintf("Contact %d", i)
+			if i == position {
+				name = connection.Name
+			}
+			conversation := browsertest.NewElement(mm.selectors.Conversations[0]).SetText(name)
+			conversations = append(conversations, conversation)
+			page.Append(conversation)
+		}
+		input := browsertest.NewElement(mm.selectors.MessageInput[0])
+		send := browsertest.NewElement(mm.selectors.MessageSend[0])
+		page.Append(input, send)
+
+		storage.messages = nil
+		greeting := MessageTemplate{Name: "greeting", Body: "Hi {{name}}, thanks for connecting!"}
+		if err := mm.SendMessage(context.Background(), page, connection, greeting); err != nil {
+			t.Fatalf("failed to send message: %v", err)
+		}
+		for i, conversation := range conversations {
+			if clicked := conversation.Clicks() == 1; clicked != (i == position) {
+				t.Fatalf("conversation %d clicked %d times, recipient is at %d", i, conversation.Clicks(), position)
+			}
+		}
+		if want := "Hi " + connection.Name + ", thanks for connecting!"; input.Value() != want || send.Clicks() != 1 {
+			t.Fatalf("expected %q typed and sent once, got %q and %d clicks", want, input.Value(), send.Clicks())
+		}
+		if len(storage.messages) != 1 || storage.messages[0].Content != input.Value() {
+			t.Fatalf("expected the sent message recorded, got %+v", storage.messages)
+		}
 	})
 }
 
@@ -500,4 +575,98 @@ func TestTrackMessageWithNilStorage(t *testing.T) {
 	if !strings.Contains(err.Error(), "storage interface not configured") {
 		t.Fatalf("error should mention storage not configured: %v", err)
 	}
-}
\ No newline at end of file
+}
+
+// TestOpenProfileSignals tests which profile signals count as an Open Profile
+func TestOpenProfileSignals(t *testing.T) {
+	cases := []struct {
+		signals ProfileSignals
+		open    bool
+	}{
+		{ProfileSignals{Degree: parseDegree("· 3rd+"), MessageButton: true, OpenBadge: true}, true},
+		{ProfileSignals{Degree: parseDegree("2nd degree connection"), MessageButton: true, OpenBadge: true}, true},
+		{ProfileSignals{Degree: parseDegree("1st"), MessageButton: true, OpenBadge: true}, false}, // Already connected
+		{ProfileSignals{Degree: "2nd", MessageButton: true}, false},                               // Message needs InMail
+		{ProfileSignals{Degree: "3rd", OpenBadge: true}, false},
+	}
+	for _, tc := range cases {
+		if open := tc.signals.IsOpenProfile(); open != tc.open {
+			t.Errorf("expected open=%v for %+v", tc.open, tc.signals)
+		}
+	}
+}
+
+// TestMessageConnection tests that first-degree connections are messaged from their profile and
+// that other degrees are refused
+func TestMessageConnection(t *testing.T) {
+	store := &mockStorage{}
+	mm := NewMessagingManager(store, nil, &mockStealth{})
+	recipient := AcceptedConnection{ProfileURL: "https://www.linkedin.com/in/jane-doe", Name: "Jane Doe"}
+
+	for _, degree := range []string{"1st", "2nd"} {
+		page := browsertest.NewPage("https://www.linkedin.com/feed/")
+		button := browsertest.NewElement(mm.selectors.ProfileMessage[0])
+		input := browsertest.NewElement(mm.selectors.MessageInput[0])
+		send := browsertest.NewElement(mm.selectors.MessageSend[0])
+		page.Append(browsertest.NewElement(".dist-value").SetText("· "+degree), button, input, send)
+
+		err := mm.MessageConnection(context.Background(), page, recipient, "Hi Jane, catching up?")
+		if degree != "1st" {
+			if err == nil || send.Clicks() != 0 {
+				t.Fatalf("expected a %s degree connection refused, got err=%v and %d sends", degree, err, send.Clicks())
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("failed to message connection: %v", err)
+		}
+		if button.Clicks() != 1 || send.Clicks() != 1 || input.Value() != "Hi Jane, catching up?" {
+			t.Fatalf("expected the profile's composer used once, got %d button and %d send clicks, typed %q",
+				button.Clicks(), send.Clicks(), input.Value())
+		}
+	}
+	if len(store.messages) != 1 || store.messages[0].Template != ConnectionTemplate {
+		t.Fatalf("expected one message tracked as %q, got %+v", ConnectionTemplate, store.messages)
+	}
+}
+
+// TestContactGuard tests that a recipient is not sent a message again, or another one within
+// the interval, whichever account's storage holds the earlier message
+func TestContactGuard(t *testing.T) {
+	now := time.Now()
+	store := &mockStorage{messages: []SentMessage{
+		{RecipientURL: "https://www.linkedin.com/in/jane-doe/", Content: "Hi Jane, catching up?", SentAt: now.AddDate(0, -2, 0)},
+	}}
+	peer := &mockStorage{messages: []SentMessage{
+		{RecipientURL: "https://www.linkedin.com/in/john-roe", Content: "Hello John", SentAt: now.Add(-time.Hour)},
+	}}
+	mm := NewMessagingManager(store, nil, &mockStealth{})
+	mm.SetContactGuard(72*time.Hour, peer)
+	send := func(profileURL, body string) error {
+		page := browsertest.NewPage(profileURL)
+		page.Append(browsertest.NewElement(mm.selectors.ProfileMessage[0]), browsertest.NewElement(mm.selectors.MessageInput[0]),
+			browsertest.NewElement(mm.selectors.MessageSend[0]))
+		return mm.SendOpenProfileMessage(context.Background(), page, AcceptedConnection{ProfileURL: profileURL}, "", body)
+	}
+
+	if err := send("https://www.linkedin.com/in/jane-doe", "Hi Jane, catching up?"); !errors.Is(err, ErrDuplicateMessage) {
+		t.Errorf("expected the repeated message held back, got %v", err)
+	}
+	if err := send("https://www.linkedin.com/in/john-roe", "Hi John, a new note"); !errors.Is(err, ErrContactedRecently) {
+		t.Errorf("expected the message to the recipient another account just messaged held back, got %v", err)
+	}
+	if err := send("https://www.linkedin.com/in/jane-doe", "Hi Jane, how was the conference?"); err != nil {
+		t.Fatalf("expected a new message after the interval sent, got %v", err)
+	}
+	if err := send("https://www.linkedin.com/in/jane-doe", "Hi Jane, one more thing"); !errors.Is(err, ErrContactedRecently) {
+		t.Errorf("expected a second message within the interval held back, got %v", err)
+	}
+
+	mm.SetContactGuard(0)
+	if err := send("https://www.linkedin.com/in/jane-doe", "Hi Jane, one more thing"); err != nil {
+		t.Errorf("expected only duplicates held back without an interval, got %v", err)
+	}
+	if len(store.messages) != 3 {
+		t.Errorf("expected the two messages sent tracked, got %+v", store.messages)
+	}
+}
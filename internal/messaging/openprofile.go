@@ -0,0 +1,216 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"linkedin-automation-framework/internal/browser"
+	"linkedin-automation-framework/internal/identity"
+)
+
+// OpenProfileTemplate is the template name open profile messages are tracked under
+const OpenProfileTemplate = "open_profile"
+
+// ProfileSignals are what a profile page shows about messaging its owner
+type ProfileSignals struct {
+	Degree        string // "1st", "2nd", "3rd" or "" when not shown
+	MessageButton bool   // A Message button is on the top card
+	OpenBadge     bool   // The page says the member has an Open Profile or offers a free message
+}
+
+// IsOpenProfile reports whether the member can be messaged for free without a connection
+func (s ProfileSignals) IsOpenProfile() bool {
+	return s.Degree != "1st" && s.MessageButton && s.OpenBadge
+}
+
+// openProfilePhrases mark an Open Profile on the top card or in the message composer
+var openProfilePhrases = []string{"open profile", "free message", "message for free"}
+
+// DetectOpenProfile opens the profile and reports whether it accepts messages without a connection
+func (mm *MessagingManager) DetectOpenProfile(ctx context.Context, page browser.PageDriver, profileURL string) (bool, error) {
+	if page == nil {
+		return false, fmt.Errorf("page cannot be nil")
+	}
+
+	page = page.Context(ctx)
+	if err := page.Navigate(identity.NormalizeProfileURL(profileURL)); err != nil {
+		return false, fmt.Errorf("failed to navigate to profile: %w", err)
+	}
+	if err := page.WaitLoad(); err != nil {
+		return false, fmt.Errorf("profile page did not load: %w", err)
+	}
+
+	signals, err := mm.readProfileSignals(page)
+	if err != nil {
+		return false, err
+	}
+	return signals.IsOpenProfile(), nil
+}
+
+// readProfileSignals reads the degree badge, the Message button and any Open Profile wording
+func (mm *MessagingManager) readProfileSignals(page browser.PageDriver) (ProfileSignals, error) {
+	var signals ProfileSignals
+
+	for _, selector := range []string{".dist-value", ".distance-badge .visually-hidden", ".pv-top-card__distance-badge"} {
+		if has, element, err := page.Has(selector); err == nil && has {
+			if text, err := element.Text(); err == nil {
+				signals.Degree = parseDegree(text)
+				break
+			}
+		}
+	}
+
+	if mm.findProfileMessageButton(page) != nil {
+		signals.MessageButton = true
+	}
+
+	for _, selector := range []string{".pv-top-card", ".pvs-profile-actions", ".msg-form"} {
+		has, element, err := page.Has(selector)
+		if err != nil || !has {
+			continue
+		}
+		text, err := element.Text()
+		if err != nil {
+			continue
+		}
+		lower := strings.ToLower(text)
+		for _, phrase := range openProfilePhrases {
+			if strings.Contains(lower, phrase) {
+				signals.OpenBadge = true
+			}
+		}
+	}
+
+	return signals, nil
+}
+
+// SendOpenProfileMessage messages an Open Profile member from their profile page, which must be open
+func (mm *MessagingManager) SendOpenProfileMessage(ctx context.Context, page browser.PageDriver, recipient AcceptedConnection, subject, body string) error {
+	return mm.sendFromProfile(ctx, page, recipient, OpenProfileTemplate, subject, body)
+}
+
+// templateKey carries the name of the template a message was rendered from
+type templateKey struct{}
+
+// WithTemplate returns ctx naming the template a message sent from a profile with it was
+// rendered from, which the message is tracked under in place of its kind's template name
+func WithTemplate(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, templateKey{}, name)
+}
+
+// sendFromProfile sends a message through the composer the profile's Message button opens,
+// tracking it under template unless ctx names another
+func (mm *MessagingManager) sendFromProfile(ctx context.Context, page browser.PageDriver, recipient AcceptedConnection, template, subject, body string) error {
+	if mm.rateLimiter != nil && !mm.rateLimiter.CanSendMessage() {
+		return fmt.Errorf("rate limit exceeded, cannot send message")
+	}
+	if page == nil {
+		return fmt.Errorf("page cannot be nil")
+	}
+	if err := mm.checkContact(recipient.ProfileURL, body, time.Now()); err != nil {
+		return err
+	}
+
+	button := mm.findProfileMessageButton(page)
+	if button == nil {
+		return fmt.Errorf("message button not found on profile")
+	}
+	if mm.stealth != nil {
+		if err := mm.stealth.MoveMouse(ctx, page, button); err != nil {
+			return fmt.Errorf("failed to move mouse to message button: %w", err)
+		}
+	}
+	if err := button.Click(); err != nil {
+		return fmt.Errorf("failed to click message button: %w", err)
+	}
+	if mm.stealth != nil {
+		if err := mm.stealth.RandomDelay(ctx, 2*time.Second, 4*time.Second); err != nil {
+			return fmt.Errorf("failed to add composer load delay: %w", err)
+		}
+	}
+
+	if subject != "" {
+		for _, selector := range mm.selectors.MessageSubject {
+			if has, field, err := page.Has(selector); err == nil && has {
+				if err := mm.typeText(ctx, field, subject); err != nil {
+					return fmt.Errorf("failed to type subject: %w", err)
+				}
+				break
+			}
+		}
+	}
+
+	input, err := mm.findMessageInput(page)
+	if err != nil {
+		return fmt.Errorf("failed to find message input field: %w", err)
+	}
+	if err := mm.typeText(ctx, input, body); err != nil {
+		return fmt.Errorf("failed to type message: %w", err)
+	}
+
+	sendButton, err := mm.findSendButton(page)
+	if err != nil {
+		return fmt.Errorf("failed to find send button: %w", err)
+	}
+	if mm.stealth != nil {
+		if err := mm.stealth.RandomDelay(ctx, 500*time.Millisecond, 1000*time.Millisecond); err != nil {
+			return fmt.Errorf("failed to add pre-send delay: %w", err)
+		}
+	}
+	if err := sendButton.Click(); err != nil {
+		return fmt.Errorf("failed to click send button: %w", err)
+	}
+
+	if name, _ := ctx.Value(templateKey{}).(string); name != "" {
+		template = name
+	}
+	err = mm.TrackMessage(SentMessage{
+		RecipientURL:  identity.NormalizeProfileURL(recipient.ProfileURL),
+		RecipientName: recipient.Name,
+		Template:      template,
+		Content:       body,
+		SentAt:        time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to track sent message: %w", err)
+	}
+	if mm.rateLimiter != nil {
+		mm.rateLimiter.RecordMessage()
+	}
+	return nil
+}
+
+// findProfileMessageButton returns the visible Message button on a profile page without waiting for one
+func (mm *MessagingManager) findProfileMessageButton(page browser.PageDriver) browser.ElementDriver {
+	for _, selector := range mm.selectors.ProfileMessage {
+		has, element, err := page.Has(selector)
+		if err != nil || !has {
+			continue
+		}
+		if visible, err := element.Visible(); err == nil && visible {
+			return element
+		}
+	}
+	return nil
+}
+
+// typeText types into a field, with human-like typing when stealth is configured
+func (mm *MessagingManager) typeText(ctx context.Context, field browser.ElementDriver, text string) error {
+	if mm.stealth != nil {
+		return mm.stealth.TypeText(ctx, field, text)
+	}
+	return field.Input(text)
+}
+
+// parseDegree extracts "1st", "2nd" or "3rd" from a degree badge such as "· 2nd" or "3rd+ degree connection"
+func parseDegree(text string) string {
+	lower := strings.ToLower(text)
+	for _, degree := range []string{"1st", "2nd", "3rd"} {
+		if strings.Contains(lower, degree) {
+			return degree
+		}
+	}
+	return ""
+}
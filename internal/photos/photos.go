@@ -0,0 +1,219 @@
+// Package photos downloads prospects' profile photos, for CRM exports, when photos are enabled.
+//
+// Each photo is stored once under the SHA-256 of its content, as <dir>/<first two hex
+// digits>/<hash>.<ext>, and recorded against the profile. Photos older than the retention
+// period are forgotten and files no profile refers to any more are deleted.
+package photos
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// MaxSize is the largest photo downloaded; LinkedIn's are well under 100 KB
+const MaxSize = 5 << 20
+
+// extensions maps the image types LinkedIn serves to file extensions
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+	"image/gif":  ".gif",
+}
+
+// Store records downloaded photos
+type Store interface {
+	SavePhoto(photo storage.Photo) error
+	GetPhotos() ([]storage.Photo, error)
+	DeletePhoto(profileURL string) error
+}
+
+// Fetcher downloads the photos of search results. A nil *Fetcher downloads nothing.
+type Fetcher struct {
+	dir    string
+	client *http.Client
+	store  Store
+}
+
+// NewFetcher creates a fetcher storing photos under dir; a nil client uses one with a 30s timeout
+func NewFetcher(dir string, client *http.Client, store Store) *Fetcher {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Fetcher{dir: dir, client: client, store: store}
+}
+
+// Fetch downloads the photo of every result with a photo URL that has not been downloaded from
+// that URL before, and returns how many it downloaded. A photo that fails is skipped and the
+// first error returned once the rest are done.
+func (f *Fetcher) Fetch(ctx context.Context, results []storage.ProfileResult, now time.Time) (int, error) {
+	if f == nil {
+		return 0, nil
+	}
+	stored, err := f.store.GetPhotos()
+	if err != nil {
+		return 0, err
+	}
+	have := make(map[string]string, len(stored))
+	for _, photo := range stored {
+		have[identity.ProfileKey(photo.ProfileURL)] = photo.SourceURL
+	}
+
+	fetched := 0
+	var firstErr error
+	for _, result := range results {
+		key := identity.ProfileKey(result.URL)
+		if result.PhotoURL == "" || have[key] == result.PhotoURL {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return fetched, err
+		}
+		hash, path, err := f.download(ctx, result.PhotoURL)
+		if err == nil {
+			err = f.store.SavePhoto(storage.Photo{ProfileURL: result.URL, SourceURL: result.PhotoURL, Hash: hash, Path: path, FetchedAt: now})
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("photo of %s: %w", result.URL, err)
+			}
+			continue
+		}
+		have[key] = result.PhotoURL
+		fetched++
+	}
+	return fetched, firstErr
+}
+
+// download saves the image at sourceURL under its content hash, unless that file exists
+func (f *Fetcher) download(ctx context.Context, sourceURL string) (string, string, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+	response, err := f.client.Do(request)
+	if err != nil {
+		return "", "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("download returned %s", response.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, MaxSize+1))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read photo: %w", err)
+	}
+	if len(data) > MaxSize {
+		return "", "", fmt.Errorf("photo is larger than %d bytes", MaxSize)
+	}
+	extension, ok := extensions[http.DetectContentType(data)]
+	if !ok {
+		return "", "", fmt.Errorf("not a JPEG, PNG, WebP or GIF image")
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	path := filepath.Join(f.dir, hash[:2], hash+extension)
+	if _, err := os.Stat(path); err == nil {
+		return hash, path, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", "", fmt.Errorf("failed to create photo directory: %w", err)
+	}
+	// Written aside and renamed, so a file under a hash is always complete
+	temporary := path + ".tmp"
+	if err := os.WriteFile(temporary, data, 0644); err != nil {
+		return "", "", fmt.Errorf("failed to write photo: %w", err)
+	}
+	if err := os.Rename(temporary, path); err != nil {
+		return "", "", fmt.Errorf("failed to save photo: %w", err)
+	}
+	return hash, path, nil
+}
+
+// Prune forgets photos fetched more than retention before now, unless retention is 0, then
+// deletes every file under dir no remaining photo refers to. It returns the number of files
+// deleted.
+func Prune(store Store, dir string, retention time.Duration, now time.Time) (int, error) {
+	photos, err := store.GetPhotos()
+	if err != nil {
+		return 0, err
+	}
+	kept := make(map[string]bool, len(photos))
+	for _, photo := range photos {
+		if retention > 0 && now.Sub(photo.FetchedAt) > retention {
+			if err := store.DeletePhoto(photo.ProfileURL); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		kept[filepath.Clean(photo.Path)] = true
+	}
+	return removeUnreferenced(dir, kept)
+}
+
+// Purge forgets every photo and deletes every file under dir, for when photos are turned off
+func Purge(store Store, dir string) (int, error) {
+	photos, err := store.GetPhotos()
+	if err != nil {
+		return 0, err
+	}
+	for _, photo := range photos {
+		if err := store.DeletePhoto(photo.ProfileURL); err != nil {
+			return 0, err
+		}
+	}
+	return removeUnreferenced(dir, nil)
+}
+
+// removeUnreferenced deletes the photo files under dir that are not in kept
+func removeUnreferenced(dir string, kept map[string]bool) (int, error) {
+	removed := 0
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() || kept[filepath.Clean(path)] {
+			return nil
+		}
+		if !isPhotoFile(path) {
+			return nil // Leave anything else in the directory alone
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		return nil
+	})
+	if err != nil {
+		return removed, fmt.Errorf("failed to prune photos: %w", err)
+	}
+	return removed, nil
+}
+
+// isPhotoFile reports whether path is named like a photo Fetch writes, or one it left half written
+func isPhotoFile(path string) bool {
+	extension := filepath.Ext(strings.TrimSuffix(path, ".tmp"))
+	for _, known := range extensions {
+		if extension == known {
+			return true
+		}
+	}
+	return false
+}
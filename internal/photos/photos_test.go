@@ -0,0 +1,132 @@
+package photos
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/storage"
+)
+
+// png is the 8-byte PNG signature followed by enough bytes to look like an image
+var png = append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...)
+
+// TestFetchStoresPhotosByContent tests that photos are downloaded once, named by their hash and
+// shared between profiles with the same photo
+func TestFetchStoresPhotosByContent(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/missing.jpg" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(png)
+	}))
+	defer server.Close()
+
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "json", Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	dir := t.TempDir()
+	fetcher := NewFetcher(dir, server.Client(), store)
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	results := []storage.ProfileResult{
+		{URL: "https://www.linkedin.com/in/jane-doe/", PhotoURL: server.URL + "/jane.jpg"},
+		{URL: "https://www.linkedin.com/in/john-roe/", PhotoURL: server.URL + "/john.jpg"},
+		{URL: "https://www.linkedin.com/in/no-photo/"},
+	}
+	fetched, err := fetcher.Fetch(context.Background(), results, now)
+	if err != nil || fetched != 2 {
+		t.Fatalf("expected two photos fetched, got %d (%v)", fetched, err)
+	}
+	if fetched, _ := fetcher.Fetch(context.Background(), results, now); fetched != 0 || requests != 2 {
+		t.Errorf("expected photos already fetched to be skipped, got %d fetched and %d requests", fetched, requests)
+	}
+
+	photos, err := store.GetPhotos()
+	if err != nil || len(photos) != 2 {
+		t.Fatalf("expected two recorded photos, got %+v (%v)", photos, err)
+	}
+	if photos[0].Path != photos[1].Path || filepath.Base(photos[0].Path) != photos[0].Hash+".png" {
+		t.Errorf("expected both profiles to share one content-addressed file, got %s and %s", photos[0].Path, photos[1].Path)
+	}
+	if data, err := os.ReadFile(photos[0].Path); err != nil || string(data) != string(png) {
+		t.Errorf("photo file does not hold the downloaded image: %v", err)
+	}
+
+	missing := []storage.ProfileResult{{URL: "https://www.linkedin.com/in/gone/", PhotoURL: server.URL + "/missing.jpg"}}
+	if _, err := fetcher.Fetch(context.Background(), missing, now); err == nil {
+		t.Errorf("expected an error for a photo that cannot be downloaded")
+	}
+	var disabled *Fetcher
+	if fetched, err := disabled.Fetch(context.Background(), results, now); fetched != 0 || err != nil {
+		t.Errorf("expected a nil fetcher to do nothing, got %d (%v)", fetched, err)
+	}
+}
+
+// TestPruneAppliesRetention tests that expired photos are forgotten and their files deleted
+// once no profile refers to them, and that Purge deletes everything
+func TestPruneAppliesRetention(t *testing.T) {
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "json", Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	write := func(name string) string {
+		path := filepath.Join(dir, name[:2], name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, png, 0644); err != nil {
+			t.Fatalf("failed to write photo: %v", err)
+		}
+		return path
+	}
+	old, recent := write("aa11.png"), write("bb22.jpg")
+	write("cc33.webp") // Referenced by nothing
+	notes := filepath.Join(dir, "README.txt")
+	if err := os.WriteFile(notes, []byte("kept"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	for _, photo := range []storage.Photo{
+		{ProfileURL: "https://www.linkedin.com/in/old/", Path: old, FetchedAt: now.Add(-100 * 24 * time.Hour)},
+		{ProfileURL: "https://www.linkedin.com/in/recent/", Path: recent, FetchedAt: now.Add(-time.Hour)},
+	} {
+		if err := store.SavePhoto(photo); err != nil {
+			t.Fatalf("failed to save photo: %v", err)
+		}
+	}
+
+	removed, err := Prune(store, dir, 90*24*time.Hour, now)
+	if err != nil || removed != 2 {
+		t.Fatalf("expected the expired and the unreferenced photo deleted, got %d (%v)", removed, err)
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Errorf("expected the recent photo kept: %v", err)
+	}
+	photos, _ := store.GetPhotos()
+	if len(photos) != 1 || photos[0].Path != recent {
+		t.Errorf("expected only the recent photo recorded, got %+v", photos)
+	}
+
+	if removed, err := Purge(store, dir); err != nil || removed != 1 {
+		t.Errorf("expected purge to delete the last photo, got %d (%v)", removed, err)
+	}
+	if _, err := os.Stat(notes); err != nil {
+		t.Errorf("expected files that are not photos to be left alone: %v", err)
+	}
+	if removed, err := Prune(store, filepath.Join(dir, "missing"), 0, now); err != nil || removed != 0 {
+		t.Errorf("expected a missing directory to prune nothing, got %d (%v)", removed, err)
+	}
+}
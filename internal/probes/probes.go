@@ -0,0 +1,166 @@
+// Package probes serves the liveness and readiness probes of a long-running instance, e.g. for
+// Kubernetes, reporting the status of each component the instance depends on
+package probes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"linkedin-automation-framework/internal/errors"
+)
+
+// DefaultTimeout bounds each check when no timeout is given
+const DefaultTimeout = 5 * time.Second
+
+// State is whether a component, or the instance as a whole, passes its checks
+type State string
+
+const (
+	StateOK      State = "ok"
+	StateFailing State = "failing"
+)
+
+// Check reports whether a component works. It should return once ctx ends.
+type Check func(ctx context.Context) error
+
+// Component is a named check
+type Component struct {
+	Name  string
+	Check Check
+	Live  bool // Also checked by /healthz, for components a restart would fix
+}
+
+// ComponentStatus is the result of one component's check
+type ComponentStatus struct {
+	Name       string      `json:"name"`
+	Status     State       `json:"status"`
+	Error      string      `json:"error,omitempty"`
+	Code       errors.Code `json:"code,omitempty"` // Stable code of the error, see errors.CodeOf
+	DurationMS int64       `json:"duration_ms"`
+}
+
+// Report is the result of a probe, failing if any of its components fails
+type Report struct {
+	Status     State             `json:"status"`
+	Components []ComponentStatus `json:"components"`
+	CheckedAt  time.Time         `json:"checked_at"`
+}
+
+// Run checks the components concurrently, each for at most timeout, and reports them in order
+func Run(ctx context.Context, components []Component, timeout time.Duration) Report {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	report := Report{Status: StateOK, Components: make([]ComponentStatus, len(components)), CheckedAt: time.Now()}
+
+	var wg sync.WaitGroup
+	for i, component := range components {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			started := time.Now()
+			err := component.Check(checkCtx)
+			status := ComponentStatus{Name: component.Name, Status: StateOK, DurationMS: time.Since(started).Milliseconds()}
+			if err != nil {
+				status.Status, status.Error, status.Code = StateFailing, err.Error(), errors.CodeOf(err)
+			}
+			report.Components[i] = status
+		}()
+	}
+	wg.Wait()
+
+	for _, status := range report.Components {
+		if status.Status != StateOK {
+			report.Status = StateFailing
+		}
+	}
+	return report
+}
+
+// Handler serves the probes, each answering with a Report, 200 if it passes and 503 otherwise:
+//
+//	GET /healthz   liveness, the components marked Live
+//	GET /readyz    readiness, every component
+func Handler(components []Component, timeout time.Duration) http.Handler {
+	var live []Component
+	for _, component := range components {
+		if component.Live {
+			live = append(live, component)
+		}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "use GET", http.StatusMethodNotAllowed)
+			return
+		}
+		writeReport(w, Run(r.Context(), live, timeout))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "use GET", http.StatusMethodNotAllowed)
+			return
+		}
+		writeReport(w, Run(r.Context(), components, timeout))
+	})
+	return mux
+}
+
+// Mount serves the probes of handler on mux, ahead of whatever mux serves at "/"
+func Mount(mux *http.ServeMux, handler http.Handler) {
+	mux.Handle("/healthz", handler)
+	mux.Handle("/readyz", handler)
+}
+
+// writeReport encodes report as the response body
+func writeReport(w http.ResponseWriter, report Report) {
+	status := http.StatusOK
+	if report.Status != StateOK {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// Heartbeat records when a loop last made progress, so a probe can tell a stalled loop from a
+// busy one. It is safe for concurrent use.
+type Heartbeat struct {
+	last atomic.Int64
+}
+
+// NewHeartbeat starts a heartbeat as if the loop had just beaten at now, giving it time to start
+func NewHeartbeat(now time.Time) *Heartbeat {
+	h := &Heartbeat{}
+	h.Beat(now)
+	return h
+}
+
+// Beat records that the loop made progress at now
+func (h *Heartbeat) Beat(now time.Time) {
+	h.last.Store(now.UnixNano())
+}
+
+// Last returns when the loop last made progress
+func (h *Heartbeat) Last() time.Time {
+	return time.Unix(0, h.last.Load())
+}
+
+// Check fails once the loop has not beaten for stallAfter
+func (h *Heartbeat) Check(stallAfter time.Duration) Check {
+	return func(ctx context.Context) error {
+		if since := time.Since(h.Last()); since > stallAfter {
+			return fmt.Errorf("no progress for %s, stalled after %s", since.Round(time.Second), stallAfter)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,81 @@
+package probes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/errors"
+)
+
+func probe(t *testing.T, server *httptest.Server, path string) (int, Report) {
+	t.Helper()
+	response, err := http.Get(server.URL + path)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", path, err)
+	}
+	defer response.Body.Close()
+	var report Report
+	if err := json.NewDecoder(response.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode %s: %v", path, err)
+	}
+	return response.StatusCode, report
+}
+
+// TestHandler tests that /healthz checks only the live components, /readyz all of them, and that
+// a failing component fails its probe with its error and code
+func TestHandler(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	session := errors.NewCoded(errors.CodeSessionExpired, "session not restorable")
+	server := httptest.NewServer(Handler([]Component{
+		{Name: "browser", Check: ok, Live: true},
+		{Name: "storage", Check: ok},
+		{Name: "session", Check: func(ctx context.Context) error { return session }},
+	}, time.Second))
+	defer server.Close()
+
+	status, report := probe(t, server, "/healthz")
+	if status != http.StatusOK || report.Status != StateOK || len(report.Components) != 1 || report.Components[0].Name != "browser" {
+		t.Errorf("expected a passing liveness probe of the browser, got %d %+v", status, report)
+	}
+
+	status, report = probe(t, server, "/readyz")
+	if status != http.StatusServiceUnavailable || report.Status != StateFailing || len(report.Components) != 3 {
+		t.Fatalf("expected a failing readiness probe of every component, got %d %+v", status, report)
+	}
+	if storage := report.Components[1]; storage.Name != "storage" || storage.Status != StateOK {
+		t.Errorf("expected storage to pass, got %+v", storage)
+	}
+	failed := report.Components[2]
+	if failed.Status != StateFailing || failed.Error != "session not restorable" || failed.Code != errors.CodeSessionExpired {
+		t.Errorf("expected the session to fail with its code, got %+v", failed)
+	}
+}
+
+// TestRunTimeout tests that a check that hangs fails once its timeout passes
+func TestRunTimeout(t *testing.T) {
+	hang := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	report := Run(context.Background(), []Component{{Name: "browser", Check: hang}}, 20*time.Millisecond)
+	if report.Status != StateFailing || report.Components[0].Error != context.DeadlineExceeded.Error() {
+		t.Errorf("expected the hanging check to time out, got %+v", report)
+	}
+}
+
+// TestHeartbeat tests that a loop counts as stalled once it has not beaten for the limit
+func TestHeartbeat(t *testing.T) {
+	heartbeat := NewHeartbeat(time.Now().Add(-time.Hour))
+	check := heartbeat.Check(30 * time.Minute)
+	if err := check(context.Background()); err == nil {
+		t.Errorf("expected a loop quiet for an hour to be stalled")
+	}
+	heartbeat.Beat(time.Now())
+	if err := check(context.Background()); err != nil {
+		t.Errorf("expected a loop that just beat to pass, got %v", err)
+	}
+}
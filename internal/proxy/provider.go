@@ -0,0 +1,149 @@
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Provider is a residential proxy provider whose gateway takes the country, city and sticky
+// session to use as parameters in the username
+type Provider string
+
+// Supported providers
+const (
+	ProviderBrightData Provider = "brightdata"
+	ProviderSmartproxy Provider = "smartproxy"
+	ProviderOxylabs    Provider = "oxylabs"
+)
+
+// gateways are the providers' residential gateways, used unless a session names another
+var gateways = map[Provider]string{
+	ProviderBrightData: "brd.superproxy.io:33335",
+	ProviderSmartproxy: "gate.smartproxy.com:7000",
+	ProviderOxylabs:    "pr.oxylabs.io:7777",
+}
+
+// maxLifetime is the longest sticky session a provider keeps, where it takes one
+var maxLifetime = map[Provider]time.Duration{
+	ProviderSmartproxy: 24 * time.Hour,
+	ProviderOxylabs:    24 * time.Hour,
+}
+
+// Session is one sticky session at a provider, which keeps its IP for the session's lifetime
+type Session struct {
+	Provider Provider
+	Gateway  string        // host:port, the provider's residential gateway unless set
+	Username string        // Account username, e.g. Bright Data's "brd-customer-hl_1234-zone-residential"
+	Password string        // Account password
+	Country  string        // ISO 3166 country code, e.g. "us"; any country unless set
+	State    string        // e.g. "california"; needs a country
+	City     string        // e.g. "new york"; needs a country
+	ID       string        // Sticky session ID of letters and digits; a new IP per request unless set
+	Lifetime time.Duration // How long the session keeps its IP, in minutes; the provider's default unless set
+}
+
+// Endpoint builds the proxy URL that opens the session
+func (s Session) Endpoint() (Endpoint, error) {
+	gateway, ok := gateways[s.Provider]
+	if !ok {
+		return Endpoint{}, fmt.Errorf("unknown proxy provider %q, use brightdata, smartproxy or oxylabs", s.Provider)
+	}
+	if s.Gateway != "" {
+		gateway = s.Gateway
+	}
+	if _, _, err := net.SplitHostPort(gateway); err != nil {
+		return Endpoint{}, fmt.Errorf("%s gateway must be host:port, got: %s", s.Provider, gateway)
+	}
+	if s.Username == "" || s.Password == "" {
+		return Endpoint{}, fmt.Errorf("%s needs a username and a password", s.Provider)
+	}
+	if s.Country != "" && len(s.Country) != 2 {
+		return Endpoint{}, fmt.Errorf("%s country must be a two-letter code, got: %s", s.Provider, s.Country)
+	}
+	if (s.State != "" || s.City != "") && s.Country == "" {
+		return Endpoint{}, fmt.Errorf("%s state and city need a country", s.Provider)
+	}
+	if strings.IndexFunc(s.ID, func(r rune) bool { return !isAlphanumeric(r) }) >= 0 {
+		return Endpoint{}, fmt.Errorf("%s session ID must be letters and digits, got: %s", s.Provider, s.ID)
+	}
+	if s.Lifetime < 0 || (s.Lifetime > 0 && s.Lifetime > maxLifetime[s.Provider]) {
+		return Endpoint{}, fmt.Errorf("%s does not keep a session for %s", s.Provider, s.Lifetime)
+	}
+
+	parsed := &url.URL{Scheme: "http", Host: gateway, User: url.UserPassword(s.username(), s.Password)}
+	return Endpoint{URL: parsed}, nil
+}
+
+// username appends the session's targeting to the account username the way its provider reads it
+func (s Session) username() string {
+	country := strings.ToLower(s.Country)
+	minutes := strconv.Itoa(int((s.Lifetime + time.Minute - 1) / time.Minute))
+	var params []string
+	add := func(key, value string) {
+		if value != "" {
+			params = append(params, key, value)
+		}
+	}
+
+	var username string
+	switch s.Provider {
+	case ProviderBrightData:
+		// brd-customer-<id>-zone-<zone>-country-us-state-ny-city-newyork-session-<id>
+		username = s.Username
+		add("country", country)
+		add("state", placeName(s.State, ""))
+		add("city", placeName(s.City, ""))
+		add("session", s.ID)
+	case ProviderSmartproxy:
+		// user-<name>-country-us-state-us_new_york-city-new_york-session-<id>-sessionduration-<minutes>
+		username = withPrefix(s.Username, "user-")
+		add("country", country)
+		if s.State != "" {
+			add("state", country+"_"+placeName(s.State, "_"))
+		}
+		add("city", placeName(s.City, "_"))
+		add("session", s.ID)
+		if s.ID != "" && s.Lifetime > 0 {
+			add("sessionduration", minutes)
+		}
+	case ProviderOxylabs:
+		// customer-<name>-cc-US-st-us_new_york-city-new_york-sessid-<id>-sesstime-<minutes>
+		username = withPrefix(s.Username, "customer-")
+		add("cc", strings.ToUpper(country))
+		if s.State != "" {
+			add("st", country+"_"+placeName(s.State, "_"))
+		}
+		add("city", placeName(s.City, "_"))
+		add("sessid", s.ID)
+		if s.ID != "" && s.Lifetime > 0 {
+			add("sesstime", minutes)
+		}
+	}
+	if len(params) == 0 {
+		return username
+	}
+	return username + "-" + strings.Join(params, "-")
+}
+
+// placeName lowercases a state or city and joins its words with sep, "new york" becoming
+// "new_york" or "newyork"
+func placeName(name, sep string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), sep)
+}
+
+// withPrefix prepends prefix to username unless it already starts with it
+func withPrefix(username, prefix string) string {
+	if strings.HasPrefix(username, prefix) {
+		return username
+	}
+	return prefix + username
+}
+
+// isAlphanumeric reports whether r is an ASCII letter or digit
+func isAlphanumeric(r rune) bool {
+	return ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')
+}
@@ -0,0 +1,68 @@
+package proxy
+
+import (
+	"testing"
+	"time"
+)
+
+// TestSessionEndpoint tests that each provider's username carries the targeting the way the
+// provider reads it
+func TestSessionEndpoint(t *testing.T) {
+	tests := map[string]struct {
+		session  Session
+		username string
+		server   string
+	}{
+		"bright data": {
+			session:  Session{Provider: ProviderBrightData, Username: "brd-customer-hl_1234-zone-residential", Password: "p@ss", Country: "US", City: "New York", ID: "acme1"},
+			username: "brd-customer-hl_1234-zone-residential-country-us-city-newyork-session-acme1",
+			server:   "http://brd.superproxy.io:33335",
+		},
+		"smartproxy": {
+			session:  Session{Provider: ProviderSmartproxy, Username: "sp123", Password: "secret", Country: "us", State: "new york", City: "new york", ID: "acme1", Lifetime: 30 * time.Minute},
+			username: "user-sp123-country-us-state-us_new_york-city-new_york-session-acme1-sessionduration-30",
+			server:   "http://gate.smartproxy.com:7000",
+		},
+		"oxylabs": {
+			session:  Session{Provider: ProviderOxylabs, Username: "customer-ox1", Password: "secret", Country: "de", City: "berlin", ID: "acme2", Lifetime: 90 * time.Second},
+			username: "customer-ox1-cc-DE-city-berlin-sessid-acme2-sesstime-2",
+			server:   "http://pr.oxylabs.io:7777",
+		},
+		"custom gateway without targeting": {
+			session:  Session{Provider: ProviderOxylabs, Gateway: "de-pr.oxylabs.io:10000", Username: "ox1", Password: "secret"},
+			username: "customer-ox1",
+			server:   "http://de-pr.oxylabs.io:10000",
+		},
+	}
+	for name, test := range tests {
+		endpoint, err := test.session.Endpoint()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		username, password, _ := endpoint.Credentials()
+		if username != test.username || password != test.session.Password || endpoint.Server() != test.server {
+			t.Errorf("%s: got %s %q, want %s %q", name, endpoint.Server(), username, test.server, test.username)
+		}
+		// The generated URL must survive the round trip through the pool's parser
+		if _, err := Parse(endpoint.URL.String()); err != nil {
+			t.Errorf("%s: generated URL does not parse: %v", name, err)
+		}
+	}
+
+	invalid := map[string]Session{
+		"unknown provider":  {Provider: "acme", Username: "u", Password: "p"},
+		"missing password":  {Provider: ProviderSmartproxy, Username: "u"},
+		"long country":      {Provider: ProviderSmartproxy, Username: "u", Password: "p", Country: "usa"},
+		"city alone":        {Provider: ProviderOxylabs, Username: "u", Password: "p", City: "berlin"},
+		"separator in ID":   {Provider: ProviderBrightData, Username: "u", Password: "p", ID: "acme-1"},
+		"lifetime too long": {Provider: ProviderSmartproxy, Username: "u", Password: "p", ID: "a", Lifetime: 48 * time.Hour},
+		"no lifetime":       {Provider: ProviderBrightData, Username: "u", Password: "p", ID: "a", Lifetime: time.Minute},
+		"bad gateway":       {Provider: ProviderOxylabs, Gateway: "pr.oxylabs.io", Username: "u", Password: "p"},
+	}
+	for name, session := range invalid {
+		if _, err := session.Endpoint(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
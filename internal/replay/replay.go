@@ -0,0 +1,250 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ManifestFile lists the fixtures in a recording directory
+const ManifestFile = "manifest.json"
+
+// Fixture is one page snapshot taken at a workflow checkpoint
+type Fixture struct {
+	Sequence   int       `json:"sequence"`
+	Label      string    `json:"label"` // Checkpoint the snapshot was taken at, e.g. "invite-dialog"
+	URL        string    `json:"url"`
+	File       string    `json:"file"` // HTML file, relative to the recording directory
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// Manifest lists a recording's fixtures in the order they were taken
+type Manifest struct {
+	Fixtures []Fixture `json:"fixtures"`
+}
+
+// LoadManifest reads the manifest of the recording in dir
+func LoadManifest(dir string) (Manifest, error) {
+	var manifest Manifest
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return manifest, fmt.Errorf("failed to read fixture manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("failed to parse fixture manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// Recorder saves the page at every checkpoint of a live run
+type Recorder struct {
+	dir      string
+	mutex    sync.Mutex
+	manifest Manifest
+}
+
+// NewRecorder records into dir, appending to a recording already there
+func NewRecorder(dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+	recorder := &Recorder{dir: dir}
+	if _, err := os.Stat(filepath.Join(dir, ManifestFile)); err == nil {
+		manifest, err := LoadManifest(dir)
+		if err != nil {
+			return nil, err
+		}
+		recorder.manifest = manifest
+	}
+	return recorder, nil
+}
+
+// Checkpoint saves the page's current DOM and URL under label
+func (r *Recorder) Checkpoint(page *rod.Page, label string) error {
+	info, err := page.Info()
+	if err != nil {
+		return fmt.Errorf("failed to read page URL: %w", err)
+	}
+	html, err := page.HTML()
+	if err != nil {
+		return fmt.Errorf("failed to read page HTML: %w", err)
+	}
+	_, err = r.Save(label, info.URL, html, time.Now())
+	return err
+}
+
+// Save writes one snapshot and adds it to the manifest
+func (r *Recorder) Save(label, pageURL, html string, now time.Time) (Fixture, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	fixture := Fixture{
+		Sequence:   len(r.manifest.Fixtures) + 1,
+		Label:      label,
+		URL:        pageURL,
+		RecordedAt: now,
+	}
+	fixture.File = fmt.Sprintf("%04d-%s.html", fixture.Sequence, fileSafe.ReplaceAllString(label, "-"))
+	if err := os.WriteFile(filepath.Join(r.dir, fixture.File), []byte(html), 0644); err != nil {
+		return Fixture{}, fmt.Errorf("failed to write fixture: %w", err)
+	}
+
+	r.manifest.Fixtures = append(r.manifest.Fixtures, fixture)
+	data, err := json.MarshalIndent(r.manifest, "", "  ")
+	if err != nil {
+		return Fixture{}, fmt.Errorf("failed to marshal fixture manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(r.dir, ManifestFile), data, 0644); err != nil {
+		return Fixture{}, fmt.Errorf("failed to write fixture manifest: %w", err)
+	}
+	return fixture, nil
+}
+
+// fileSafe matches characters left out of fixture file names
+var fileSafe = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// Player replays a recording: navigations are answered with the page recorded at their URL, and
+// each checkpoint restores the next snapshot recorded under its label
+type Player struct {
+	dir      string
+	manifest Manifest
+	mutex    sync.Mutex
+	restored map[string]int // Snapshots restored so far, by label
+}
+
+// NewPlayer loads the recording in dir
+func NewPlayer(dir string) (*Player, error) {
+	manifest, err := LoadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Player{dir: dir, manifest: manifest, restored: make(map[string]int)}, nil
+}
+
+// Checkpoint replaces the page's DOM with the next snapshot recorded under label. A run asking for
+// more snapshots than were recorded has taken a different path than the recording.
+func (p *Player) Checkpoint(page *rod.Page, label string) error {
+	p.mutex.Lock()
+	n := p.restored[label]
+	p.restored[label]++
+	p.mutex.Unlock()
+
+	fixture, ok := p.nth(label, n)
+	if !ok {
+		return fmt.Errorf("replay diverged: no snapshot %d for checkpoint %q", n+1, label)
+	}
+
+	html, err := p.read(fixture)
+	if err != nil {
+		return err
+	}
+	return page.SetDocumentContent(StripScripts(html))
+}
+
+// nth returns the n-th fixture recorded under label, counting from 0
+func (p *Player) nth(label string, n int) (Fixture, bool) {
+	for _, fixture := range p.manifest.Fixtures {
+		if fixture.Label != label {
+			continue
+		}
+		if n == 0 {
+			return fixture, true
+		}
+		n--
+	}
+	return Fixture{}, false
+}
+
+// Page returns the first page recorded at pageURL, ignoring the fragment. A URL recorded only with
+// other query parameters matches on its path.
+func (p *Player) Page(pageURL string) (string, bool, error) {
+	want := withoutFragment(pageURL)
+	var pathMatch *Fixture
+	for i, fixture := range p.manifest.Fixtures {
+		recorded := withoutFragment(fixture.URL)
+		if recorded == want {
+			html, err := p.read(fixture)
+			return html, err == nil, err
+		}
+		if pathMatch == nil && withoutQuery(recorded) == withoutQuery(want) {
+			pathMatch = &p.manifest.Fixtures[i]
+		}
+	}
+	if pathMatch == nil {
+		return "", false, nil
+	}
+	html, err := p.read(*pathMatch)
+	return html, err == nil, err
+}
+
+// read loads a fixture's HTML
+func (p *Player) read(fixture Fixture) (string, error) {
+	data, err := os.ReadFile(filepath.Join(p.dir, fixture.File))
+	if err != nil {
+		return "", fmt.Errorf("failed to read fixture: %w", err)
+	}
+	return string(data), nil
+}
+
+// Serve answers every request the browser makes from the recording, so a replay never reaches
+// LinkedIn. Documents without a recorded page get a 404 and are passed to onMiss; every other
+// request is blocked. Stop the returned router when the replay ends.
+func (p *Player) Serve(browser *rod.Browser, onMiss func(url string)) (*rod.HijackRouter, error) {
+	router := browser.HijackRequests()
+	err := router.Add("*", "", func(hijack *rod.Hijack) {
+		if hijack.Request.Type() != proto.NetworkResourceTypeDocument {
+			hijack.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+			return
+		}
+		requested := hijack.Request.URL().String()
+		html, ok, err := p.Page(requested)
+		if err != nil || !ok {
+			if onMiss != nil {
+				onMiss(requested)
+			}
+			hijack.Response.Payload().ResponseCode = http.StatusNotFound
+			hijack.Response.SetHeader("Content-Type", "text/html; charset=utf-8")
+			hijack.Response.SetBody("<html><body>No recorded page for this URL</body></html>")
+			return
+		}
+		hijack.Response.Payload().ResponseCode = http.StatusOK
+		hijack.Response.SetHeader("Content-Type", "text/html; charset=utf-8")
+		hijack.Response.SetBody(StripScripts(html))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to intercept browser requests: %w", err)
+	}
+	go router.Run()
+	return router, nil
+}
+
+// scriptTag matches script elements, which would otherwise rebuild the recorded DOM on replay
+var scriptTag = regexp.MustCompile(`(?is)<script\b[^>]*>.*?</script\s*>`)
+
+// StripScripts removes script elements so a snapshot replays as the static DOM it was recorded as
+func StripScripts(html string) string {
+	return scriptTag.ReplaceAllString(html, "")
+}
+
+func withoutFragment(rawURL string) string {
+	if parsed, err := url.Parse(rawURL); err == nil {
+		parsed.Fragment = ""
+		return parsed.String()
+	}
+	return rawURL
+}
+
+func withoutQuery(rawURL string) string {
+	before, _, _ := strings.Cut(rawURL, "?")
+	return before
+}
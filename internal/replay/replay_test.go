@@ -0,0 +1,78 @@
+package replay
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRecordingRoundTrip tests that saved snapshots are listed in the manifest and found again by URL and label
+func TestRecordingRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir)
+	if err != nil {
+		t.Fatalf("failed to create recorder: %v", err)
+	}
+	now := time.Date(2024, 6, 12, 9, 0, 0, 0, time.UTC)
+	searchURL := "https://www.linkedin.com/search/results/people/?keywords=sre"
+	for _, snapshot := range []struct{ label, url, html string }{
+		{"search-results", searchURL, "<html><body>results</body></html>"},
+		{"invite-dialog", searchURL + "#dialog", "<html><body>dialog 1</body></html>"},
+		{"invite-dialog", searchURL, "<html><body>dialog 2</body></html>"},
+	} {
+		if _, err := recorder.Save(snapshot.label, snapshot.url, snapshot.html, now); err != nil {
+			t.Fatalf("failed to save snapshot: %v", err)
+		}
+	}
+
+	// A second recorder appends to the same recording
+	recorder, err = NewRecorder(dir)
+	if err != nil {
+		t.Fatalf("failed to reopen recording: %v", err)
+	}
+	fixture, err := recorder.Save("profile page", "https://www.linkedin.com/in/ada/", "<html>ada</html>", now)
+	if err != nil {
+		t.Fatalf("failed to save snapshot: %v", err)
+	}
+	if fixture.Sequence != 4 || fixture.File != "0004-profile-page.html" {
+		t.Errorf("unexpected fixture %+v", fixture)
+	}
+
+	player, err := NewPlayer(dir)
+	if err != nil {
+		t.Fatalf("failed to load recording: %v", err)
+	}
+	if len(player.manifest.Fixtures) != 4 {
+		t.Fatalf("expected 4 fixtures, got %d", len(player.manifest.Fixtures))
+	}
+
+	// Navigations get the first page recorded at the URL, or at its path with other parameters
+	if html, ok, err := player.Page(searchURL + "#top"); err != nil || !ok || !strings.Contains(html, "results") {
+		t.Errorf("expected the search results page, got %q %v %v", html, ok, err)
+	}
+	if html, ok, _ := player.Page("https://www.linkedin.com/search/results/people/?keywords=other"); !ok || !strings.Contains(html, "results") {
+		t.Errorf("expected a path match for other keywords, got %q", html)
+	}
+	if _, ok, _ := player.Page("https://www.linkedin.com/feed/"); ok {
+		t.Errorf("expected no page for an unrecorded URL")
+	}
+
+	// Checkpoints restore their label's snapshots in order
+	second, ok := player.nth("invite-dialog", 1)
+	if !ok || second.Sequence != 3 {
+		t.Errorf("expected the second dialog snapshot, got %+v", second)
+	}
+	if _, ok := player.nth("invite-dialog", 2); ok {
+		t.Errorf("expected no third dialog snapshot")
+	}
+}
+
+// TestStripScripts tests that script elements are removed and the rest of the document kept
+func TestStripScripts(t *testing.T) {
+	html := `<html><head><SCRIPT type="module">render()</SCRIPT></head><body><script>
+track();
+</script ><p>kept</p></body></html>`
+	if got := StripScripts(html); got != "<html><head></head><body><p>kept</p></body></html>" {
+		t.Errorf("unexpected stripped document %q", got)
+	}
+}
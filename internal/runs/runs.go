@@ -0,0 +1,270 @@
+package runs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"linkedin-automation-framework/internal/errors"
+	"linkedin-automation-framework/internal/latency"
+)
+
+// Run statuses
+const (
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// Skip reasons recorded by the connect flows, next to connect.SkipReasonEmailRequired,
+// annotations.SnoozeReason and the warning kinds recorded for leads deferred by an invitation limit
+const (
+	SkipAlreadyConnected = "already_connected"
+	SkipLowQuality       = "low_quality"
+	SkipNoConnectButton  = "no_connect_button"
+	SkipSuppressed       = "suppressed"
+)
+
+// Quota kinds
+const (
+	QuotaConnections = "connections"
+	QuotaMessages    = "messages"
+	QuotaSearches    = "searches" // Search result pages loaded
+)
+
+// NewID formats the ID of the day's sequence-th run, e.g. "2024-06-12-3" for the third run on
+// 12 June 2024. IDs are unique per storage, which counts the runs of each day.
+func NewID(day time.Time, sequence int) string {
+	return fmt.Sprintf("%s-%d", day.Format("2006-01-02"), sequence)
+}
+
+// Skip is a target passed over without sending, and why
+type Skip struct {
+	Target string `json:"target"`
+	Reason string `json:"reason"`
+}
+
+// Failure is a target whose action failed
+type Failure struct {
+	Target      string      `json:"target"`
+	Error       string      `json:"error"`
+	Code        errors.Code `json:"code,omitempty"` // Stable code of the error, see errors.CodeOf
+	Fingerprint string      `json:"fingerprint"`    // ID of the ErrorGroup the failure counts toward
+}
+
+// ErrorGroup counts the failures of a run that share a fingerprint
+type ErrorGroup struct {
+	errors.Fingerprint
+	Count   int     `json:"count"`
+	Rate    float64 `json:"rate"`    // Share of the run's attempts that failed this way
+	Example string  `json:"example"` // Error of the group's first failure
+}
+
+// Summary is the machine-readable record of one connect, message or search run
+type Summary struct {
+	RunID           string          `json:"run_id"`
+	Mode            string          `json:"mode"`
+	StartedAt       time.Time       `json:"started_at"`
+	FinishedAt      time.Time       `json:"finished_at"`
+	DurationSeconds float64         `json:"duration_seconds"`
+	Status          string          `json:"status"`
+	Error           string          `json:"error,omitempty"`
+	ErrorCode       errors.Code     `json:"error_code,omitempty"` // Stable code of the run's error, see errors.CodeOf
+	Attempted       int             `json:"attempted"`
+	Sent            int             `json:"sent"`
+	Skipped         []Skip          `json:"skipped"`
+	SkipReasons     map[string]int  `json:"skip_reasons"`
+	Errors          []Failure       `json:"errors"`
+	ErrorGroups     []ErrorGroup    `json:"error_groups"`           // Errors grouped by fingerprint, the most frequent first
+	Found           int             `json:"found,omitempty"`        // Profiles returned by search runs
+	New             int             `json:"new,omitempty"`          // Of those, profiles no earlier run returned
+	Quota           map[string]int  `json:"quota"`                  // Actions counted against rate limits, by kind
+	StealthSeed     int64           `json:"stealth_seed,omitempty"` // Seed of the run's delays and mouse paths
+	Campaign        string          `json:"campaign,omitempty"`     // Name of the campaign a campaign run worked through
+	Latency         []latency.Stats `json:"latency,omitempty"`      // Latencies of the run's page loads and actions, when tracked
+	EgressIPs       []string        `json:"egress_ips,omitempty"`   // IPs the run's requests left from, in order, when looked up
+}
+
+// Recorder collects a run's outcomes as they happen. A nil recorder ignores every call, so
+// flows that are not summarized can share code with those that are.
+type Recorder struct {
+	mutex   sync.Mutex
+	summary Summary
+}
+
+// NewRecorder starts recording the run with the ID
+func NewRecorder(runID, mode string, now time.Time) *Recorder {
+	return &Recorder{summary: Summary{
+		RunID:       runID,
+		Mode:        mode,
+		StartedAt:   now,
+		Skipped:     []Skip{},
+		SkipReasons: make(map[string]int),
+		Errors:      []Failure{},
+		ErrorGroups: []ErrorGroup{},
+		Quota:       make(map[string]int),
+	}}
+}
+
+// Attempt counts a target the run tried to act on
+func (r *Recorder) Attempt() {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.summary.Attempted++
+}
+
+// Sent counts a successful action and the quota it used
+func (r *Recorder) Sent(quota string) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.summary.Sent++
+	if quota != "" {
+		r.summary.Quota[quota]++
+	}
+}
+
+// Skip records a target passed over and why
+func (r *Recorder) Skip(target, reason string) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.summary.Skipped = append(r.summary.Skipped, Skip{Target: target, Reason: reason})
+	r.summary.SkipReasons[reason]++
+}
+
+// Fail records a target whose action failed
+func (r *Recorder) Fail(target string, err error) {
+	if r == nil || err == nil {
+		return
+	}
+	fingerprint := errors.FingerprintOf(err)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.summary.Errors = append(r.summary.Errors, Failure{Target: target, Error: err.Error(), Code: errors.CodeOf(err), Fingerprint: fingerprint.ID})
+	for i := range r.summary.ErrorGroups {
+		if r.summary.ErrorGroups[i].ID == fingerprint.ID {
+			r.summary.ErrorGroups[i].Count++
+			return
+		}
+	}
+	r.summary.ErrorGroups = append(r.summary.ErrorGroups, ErrorGroup{Fingerprint: fingerprint, Count: 1, Example: err.Error()})
+}
+
+// Found counts the profiles a search returned, and how many of them are new
+func (r *Recorder) Found(found, fresh int) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.summary.Found += found
+	r.summary.New += fresh
+}
+
+// UseQuota counts actions of a kind that were not sends, such as search pages
+func (r *Recorder) UseQuota(kind string, n int) {
+	if r == nil || n <= 0 {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.summary.Quota[kind] += n
+}
+
+// UseSeed records the seed the run's stealth randomness was drawn from, to reproduce it
+func (r *Recorder) UseSeed(seed int64) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.summary.StealthSeed = seed
+}
+
+// UseEgressIP records an IP the run's requests left from, such as a new one after a proxy rotation
+func (r *Recorder) UseEgressIP(ip string) {
+	if r == nil || ip == "" {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if n := len(r.summary.EgressIPs); n > 0 && r.summary.EgressIPs[n-1] == ip {
+		return
+	}
+	r.summary.EgressIPs = append(r.summary.EgressIPs, ip)
+}
+
+// UseCampaign records the campaign the run works through, so its invites can be attributed to it
+func (r *Recorder) UseCampaign(name string) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.summary.Campaign = name
+}
+
+// UseLatency records the latencies the run's page loads and actions had at its end
+func (r *Recorder) UseLatency(stats []latency.Stats) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.summary.Latency = stats
+}
+
+// Finish ends the run, failed if err is set, and returns its summary
+func (r *Recorder) Finish(err error, now time.Time) Summary {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	summary := r.summary
+	summary.FinishedAt = now
+	summary.DurationSeconds = now.Sub(summary.StartedAt).Seconds()
+	summary.Status = StatusCompleted
+	if err != nil {
+		summary.Status = StatusFailed
+		summary.Error = err.Error()
+		summary.ErrorCode = errors.CodeOf(err)
+	}
+
+	// Rates are of the attempts, or of the failures when the run failed things it did not count as attempts
+	attempts := max(summary.Attempted, len(summary.Errors))
+	summary.ErrorGroups = append([]ErrorGroup{}, summary.ErrorGroups...)
+	for i := range summary.ErrorGroups {
+		summary.ErrorGroups[i].Rate = float64(summary.ErrorGroups[i].Count) / float64(attempts)
+	}
+	sort.SliceStable(summary.ErrorGroups, func(i, j int) bool {
+		return summary.ErrorGroups[i].Count > summary.ErrorGroups[j].Count
+	})
+	return summary
+}
+
+// Write saves the summary as JSON in dir, named after the run ID and mode, and returns its path
+func Write(dir string, summary Summary) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create runs directory: %w", err)
+	}
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal run summary: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s.json", summary.RunID, summary.Mode)
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write run summary: %w", err)
+	}
+	return path, nil
+}
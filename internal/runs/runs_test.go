@@ -0,0 +1,131 @@
+package runs
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/errors"
+)
+
+// TestRecorderSummary tests that outcomes are counted and the summary is written as JSON
+func TestRecorderSummary(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	recorder := NewRecorder(NewID(start, 3), "connect-only", start)
+
+	recorder.Attempt()
+	recorder.Sent("connections")
+	recorder.Attempt()
+	recorder.Skip("https://www.linkedin.com/in/ada/", "already_connected")
+	recorder.Attempt()
+	recorder.Skip("https://www.linkedin.com/in/alan/", "already_connected")
+	recorder.Attempt()
+	recorder.Fail("https://www.linkedin.com/in/grace/", stderrors.New("connect button not found"))
+	recorder.UseQuota("searches", 2)
+	recorder.UseEgressIP("203.0.113.7")
+	recorder.UseEgressIP("203.0.113.7")
+	recorder.UseEgressIP("198.51.100.4")
+
+	summary := recorder.Finish(nil, start.Add(90*time.Second))
+	if summary.Status != StatusCompleted || summary.DurationSeconds != 90 {
+		t.Errorf("Expected a completed 90s run, got %s after %vs", summary.Status, summary.DurationSeconds)
+	}
+	if summary.Attempted != 4 || summary.Sent != 1 || len(summary.Skipped) != 2 || len(summary.Errors) != 1 {
+		t.Errorf("Unexpected counts: %+v", summary)
+	}
+	if summary.SkipReasons["already_connected"] != 2 {
+		t.Errorf("Expected 2 already_connected skips, got %v", summary.SkipReasons)
+	}
+	if summary.Quota["connections"] != 1 || summary.Quota["searches"] != 2 {
+		t.Errorf("Unexpected quota: %v", summary.Quota)
+	}
+	if len(summary.EgressIPs) != 2 || summary.EgressIPs[1] != "198.51.100.4" {
+		t.Errorf("Expected each egress IP once, in order, got %v", summary.EgressIPs)
+	}
+
+	path, err := Write(t.TempDir(), summary)
+	if err != nil {
+		t.Fatalf("Failed to write summary: %v", err)
+	}
+	if filepath.Base(path) != "2024-03-01-3-connect-only.json" {
+		t.Errorf("Unexpected summary file name %s", filepath.Base(path))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read summary: %v", err)
+	}
+	var written Summary
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatalf("Summary is not valid JSON: %v", err)
+	}
+	if written.RunID != "2024-03-01-3" || written.Sent != 1 || written.Errors[0].Error != "connect button not found" {
+		t.Errorf("Written summary does not match: %+v", written)
+	}
+}
+
+// TestRecorderErrorGroups tests that failures are grouped by fingerprint, the most frequent first,
+// with the share of attempts that failed each way
+func TestRecorderErrorGroups(t *testing.T) {
+	start := time.Now()
+	recorder := NewRecorder("2024-03-01-2", "connect-only", start)
+	missing := func(profile string) error {
+		return fmt.Errorf("failed to click connect for %q: %w", profile,
+			errors.NewError(errors.ErrorTypeTransient, "click_connect_button", "element not found", nil).
+				WithContext(errors.ContextSelector, "button[aria-label*='Connect']"))
+	}
+	for i := 0; i < 10; i++ {
+		recorder.Attempt()
+	}
+	recorder.Fail("https://www.linkedin.com/in/ada/", stderrors.New("lead filter returned 3 errors"))
+	for _, profile := range []string{"Grace", "Alan", "Edsger"} {
+		recorder.Fail("https://www.linkedin.com/in/"+profile+"/", missing(profile))
+	}
+	recorder.Fail("https://www.linkedin.com/in/barbara/", stderrors.New("lead filter returned 1 errors"))
+
+	summary := recorder.Finish(nil, start)
+	if len(summary.ErrorGroups) != 2 {
+		t.Fatalf("Expected 2 error groups, got %+v", summary.ErrorGroups)
+	}
+	top := summary.ErrorGroups[0]
+	if top.Operation != "click_connect_button" || top.Selector != "button[aria-label*='Connect']" || top.Class != "element not found" {
+		t.Errorf("Unexpected top fingerprint: %+v", top.Fingerprint)
+	}
+	if top.Count != 3 || top.Rate != 0.3 || top.Example != missing("Grace").Error() {
+		t.Errorf("Expected 3 of 10 attempts in the top group, got %+v", top)
+	}
+	if other := summary.ErrorGroups[1]; other.Count != 2 || other.Operation != "unnamed" || other.Class != "lead filter returned <n> errors" {
+		t.Errorf("Expected numbers normalized out of the second group, got %+v", other)
+	}
+	if summary.Errors[1].Fingerprint != top.ID || summary.Errors[0].Fingerprint == top.ID {
+		t.Errorf("Expected failures to name their group, got %+v", summary.Errors)
+	}
+}
+
+// TestRecorderFailedRun tests that a run ending in an error is marked failed
+func TestRecorderFailedRun(t *testing.T) {
+	start := time.Now()
+	summary := NewRecorder("2024-03-01-1", "message", start).Finish(stderrors.New("session expired"), start)
+	if summary.Status != StatusFailed || summary.Error != "session expired" || summary.ErrorCode != "" {
+		t.Errorf("Expected a failed run, got %s %q", summary.Status, summary.Error)
+	}
+
+	expired := errors.NewError(errors.ErrorTypeAuthentication, "wait_for_login", "not logged in", nil)
+	if summary := NewRecorder("2024-03-01-2", "message", start).Finish(expired, start); summary.ErrorCode != errors.CodeSessionExpired {
+		t.Errorf("Expected the run's error code, got %q", summary.ErrorCode)
+	}
+}
+
+// TestNilRecorder tests that a nil recorder ignores calls
+func TestNilRecorder(t *testing.T) {
+	var recorder *Recorder
+	recorder.Attempt()
+	recorder.Sent("connections")
+	recorder.Skip("target", "reason")
+	recorder.Fail("target", stderrors.New("failed"))
+	recorder.UseQuota("searches", 1)
+	recorder.UseEgressIP("203.0.113.7")
+}
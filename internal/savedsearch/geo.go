@@ -0,0 +1,191 @@
+package savedsearch
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-rod/rod"
+
+	"linkedin-automation-framework/internal/search"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// ErrLocationNotCached is returned when a location needs the typeahead but no lookup is available
+var ErrLocationNotCached = stderrors.New("location not resolved yet")
+
+// GeoStore caches resolved locations
+type GeoStore interface {
+	GetGeoLocation(query string) (storage.GeoLocation, bool, error)
+	SaveGeoLocation(location storage.GeoLocation) error
+}
+
+// GeoCandidate is one location suggested by LinkedIn's typeahead
+type GeoCandidate struct {
+	ID   string
+	Name string
+}
+
+// GeoLookup asks LinkedIn which locations match a name, best match first
+type GeoLookup interface {
+	LookupGeo(ctx context.Context, location string) ([]GeoCandidate, error)
+}
+
+// GeoResolver turns location names into geo IDs, consulting the built-in table, then the cache, then
+// the typeahead. Typeahead answers are cached so each location is looked up on LinkedIn only once.
+type GeoResolver struct {
+	store  GeoStore
+	lookup GeoLookup // Nil resolves from the table and cache only
+	now    func() time.Time
+}
+
+// NewGeoResolver creates a resolver; lookup may be nil when no browser is available
+func NewGeoResolver(store GeoStore, lookup GeoLookup) *GeoResolver {
+	return &GeoResolver{store: store, lookup: lookup, now: time.Now}
+}
+
+// Resolve returns the geo ID and LinkedIn's name for a location. Numeric IDs are returned unchanged.
+func (r *GeoResolver) Resolve(ctx context.Context, location string) (storage.GeoLocation, error) {
+	query := locationKey(location)
+	if query == "" {
+		return storage.GeoLocation{}, fmt.Errorf("empty location")
+	}
+	if id, err := search.LookupFacetID(search.FacetGeo, location); err == nil {
+		return storage.GeoLocation{Query: query, ID: id, Name: strings.TrimSpace(location)}, nil
+	}
+
+	cached, found, err := r.store.GetGeoLocation(query)
+	if err != nil {
+		return storage.GeoLocation{}, fmt.Errorf("failed to read location cache: %w", err)
+	}
+	if found {
+		return cached, nil
+	}
+
+	if r.lookup == nil {
+		return storage.GeoLocation{}, fmt.Errorf("%w: %q", ErrLocationNotCached, location)
+	}
+	candidates, err := r.lookup.LookupGeo(ctx, location)
+	if err != nil {
+		return storage.GeoLocation{}, fmt.Errorf("failed to look up location %q: %w", location, err)
+	}
+	if len(candidates) == 0 {
+		return storage.GeoLocation{}, fmt.Errorf("LinkedIn knows no location matching %q", location)
+	}
+
+	resolved := storage.GeoLocation{Query: query, ID: candidates[0].ID, Name: candidates[0].Name, ResolvedAt: r.now()}
+	if err := r.store.SaveGeoLocation(resolved); err != nil {
+		return resolved, fmt.Errorf("failed to cache location: %w", err)
+	}
+	return resolved, nil
+}
+
+// ResolveFacets replaces the location names in facets with geo IDs
+func (r *GeoResolver) ResolveFacets(ctx context.Context, facets search.Facets) (search.Facets, error) {
+	ids := make([]string, 0, len(facets.Locations))
+	for _, location := range facets.Locations {
+		resolved, err := r.Resolve(ctx, location)
+		if err != nil {
+			return facets, err
+		}
+		ids = append(ids, resolved.ID)
+	}
+	facets.Locations = ids
+	return facets, nil
+}
+
+// locationKey normalizes a location name for caching, so "Berlin, Germany" and "berlin germany" share an entry
+func locationKey(location string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(location), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// typeaheadScript fetches geo suggestions from LinkedIn's typeahead with the page's session.
+// The endpoint expects the JSESSIONID cookie value echoed in the csrf-token header.
+const typeaheadScript = `async (keywords) => {
+	const match = document.cookie.match(/JSESSIONID="?([^";]+)/);
+	const url = '/voyager/api/typeahead/hitsV2?keywords=' + encodeURIComponent(keywords) +
+		'&origin=OTHER&q=type&type=GEO&queryContext=List(geoVersion-%3E3,' +
+		'bingGeoSubTypeFilters-%3EMARKET_AREA%7CCOUNTRY_REGION%7CADMIN_DIVISION_1%7CCITY)';
+	const response = await fetch(url, {
+		credentials: 'include',
+		headers: {
+			'accept': 'application/json',
+			'csrf-token': match ? match[1] : '',
+			'x-restli-protocol-version': '2.0.0',
+		},
+	});
+	if (!response.ok) {
+		throw new Error('typeahead returned ' + response.status);
+	}
+	return await response.text();
+}`
+
+// PageGeoLookup queries the typeahead from a logged-in LinkedIn page
+type PageGeoLookup struct {
+	page *rod.Page
+}
+
+// NewPageGeoLookup creates a lookup that runs in page
+func NewPageGeoLookup(page *rod.Page) *PageGeoLookup {
+	return &PageGeoLookup{page: page}
+}
+
+// LookupGeo returns the typeahead's geo suggestions for location
+func (l *PageGeoLookup) LookupGeo(ctx context.Context, location string) ([]GeoCandidate, error) {
+	// fetch needs a linkedin.com origin to send the session cookies
+	info, err := l.page.Info()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page URL: %w", err)
+	}
+	if !strings.Contains(info.URL, "linkedin.com") {
+		if err := l.page.Navigate("https://www.linkedin.com/feed/"); err != nil {
+			return nil, fmt.Errorf("failed to open LinkedIn: %w", err)
+		}
+		if err := l.page.WaitLoad(); err != nil {
+			return nil, fmt.Errorf("failed to wait for LinkedIn: %w", err)
+		}
+	}
+
+	result, err := l.page.Context(ctx).Eval(typeaheadScript, location)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGeoTypeahead([]byte(result.Value.Str()))
+}
+
+// ParseGeoTypeahead extracts geo candidates from a typeahead response, skipping hits that are not geo URNs
+func ParseGeoTypeahead(data []byte) ([]GeoCandidate, error) {
+	var response struct {
+		Elements []struct {
+			TargetUrn string `json:"targetUrn"`
+			Text      struct {
+				Text string `json:"text"`
+			} `json:"text"`
+		} `json:"elements"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse typeahead response: %w", err)
+	}
+
+	var candidates []GeoCandidate
+	for _, element := range response.Elements {
+		// URNs look like urn:li:fs_geo:106967730 or urn:li:geo:106967730
+		separator := strings.LastIndex(element.TargetUrn, ":")
+		if separator < 0 || !strings.HasSuffix(element.TargetUrn[:separator], "geo") {
+			continue
+		}
+		id := element.TargetUrn[separator+1:]
+		if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+			continue
+		}
+		candidates = append(candidates, GeoCandidate{ID: id, Name: element.Text.Text})
+	}
+	return candidates, nil
+}
@@ -0,0 +1,98 @@
+package savedsearch
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	"linkedin-automation-framework/internal/search"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// fakeGeoLookup answers from a fixed table and counts lookups
+type fakeGeoLookup struct {
+	answers map[string][]GeoCandidate
+	calls   int
+}
+
+func (l *fakeGeoLookup) LookupGeo(ctx context.Context, location string) ([]GeoCandidate, error) {
+	l.calls++
+	return l.answers[location], nil
+}
+
+// TestGeoResolverCachesTypeaheadAnswers tests that each location is looked up on LinkedIn only once
+func TestGeoResolverCachesTypeaheadAnswers(t *testing.T) {
+	for _, storageType := range []string{"sqlite", "json"} {
+		t.Run(storageType, func(t *testing.T) {
+			store, err := storage.NewStorageManager(storage.StorageConfig{Type: storageType, Path: t.TempDir(), Database: "test.db"})
+			if err != nil {
+				t.Fatalf("failed to create storage: %v", err)
+			}
+			defer store.Close()
+
+			lookup := &fakeGeoLookup{answers: map[string][]GeoCandidate{
+				"Berlin, Germany": {{ID: "106967730", Name: "Berlin, Germany"}, {ID: "90009712", Name: "Berlin Metropolitan Area"}},
+			}}
+			resolver := NewGeoResolver(store, lookup)
+
+			for _, name := range []string{"Berlin, Germany", "berlin germany", "  BERLIN,GERMANY "} {
+				location, err := resolver.Resolve(context.Background(), name)
+				if err != nil {
+					t.Fatalf("failed to resolve %q: %v", name, err)
+				}
+				if location.ID != "106967730" {
+					t.Errorf("expected %q to resolve to the first suggestion, got %s", name, location.ID)
+				}
+			}
+			if lookup.calls != 1 {
+				t.Errorf("expected one typeahead lookup, got %d", lookup.calls)
+			}
+
+			// Built-in names and raw IDs never need the typeahead
+			for name, id := range map[string]string{"Germany": "101282230", "90000084": "90000084"} {
+				location, err := resolver.Resolve(context.Background(), name)
+				if err != nil || location.ID != id {
+					t.Errorf("expected %q to resolve to %s, got %s (%v)", name, id, location.ID, err)
+				}
+			}
+			if lookup.calls != 1 {
+				t.Errorf("expected no further typeahead lookups, got %d", lookup.calls)
+			}
+
+			// Without a browser, only cached and built-in locations resolve
+			offline := NewGeoResolver(store, nil)
+			facets, err := offline.ResolveFacets(context.Background(), search.Facets{Locations: []string{"Berlin, Germany", "Canada"}})
+			if err != nil {
+				t.Fatalf("failed to resolve cached facets: %v", err)
+			}
+			if len(facets.Locations) != 2 || facets.Locations[0] != "106967730" || facets.Locations[1] != "101174742" {
+				t.Errorf("unexpected resolved locations %v", facets.Locations)
+			}
+			if _, err := offline.Resolve(context.Background(), "Lisbon, Portugal"); !stderrors.Is(err, ErrLocationNotCached) {
+				t.Errorf("expected ErrLocationNotCached, got %v", err)
+			}
+		})
+	}
+}
+
+// TestParseGeoTypeahead tests reading geo IDs from a typeahead response
+func TestParseGeoTypeahead(t *testing.T) {
+	response := `{"elements":[
+		{"targetUrn":"urn:li:fs_geo:106967730","text":{"text":"Berlin, Germany"}},
+		{"targetUrn":"urn:li:fs_company:1441","text":{"text":"Not a geo ID"}},
+		{"targetUrn":"urn:li:geo:90009712","text":{"text":"Berlin Metropolitan Area"}},
+		{"text":{"text":"Missing URN"}}
+	]}`
+
+	candidates, err := ParseGeoTypeahead([]byte(response))
+	if err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if len(candidates) != 2 || candidates[0].ID != "106967730" || candidates[1].ID != "90009712" {
+		t.Errorf("unexpected candidates %+v", candidates)
+	}
+
+	if _, err := ParseGeoTypeahead([]byte("<html>")); err == nil {
+		t.Errorf("expected an error for a non-JSON response")
+	}
+}
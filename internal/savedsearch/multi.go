@@ -0,0 +1,174 @@
+package savedsearch
+
+import (
+	"context"
+	"sync"
+
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// Query is one search of a multi-query run
+type Query struct {
+	Name       string // Label used in reports
+	URL        string // People search URL
+	MaxResults int
+}
+
+// QueryResult reports how one query of a multi-query run went
+type QueryResult struct {
+	Query    Query
+	Found    int   // Profiles the search returned
+	Added    int   // Profiles it added to the pool that no earlier query had found
+	Searches int   // Result pages it spent from the search quota
+	Err      error // Why the query failed; the other queries still run
+}
+
+// RunnerFactory opens a runner, typically on its own browser page, that spends searches from quota.
+// The returned function releases the runner.
+type RunnerFactory func(quota Quota) (Runner, func(), error)
+
+// RunQueries runs the queries with at most concurrency at once (in sequence if concurrency is below 2),
+// each worker with its own runner, and merges their results into one pool without duplicate people.
+// The pool keeps query order, so earlier queries win when two find the same person. All queries share
+// quota (nil for unlimited) without any of them planning pages another has already counted on.
+func RunQueries(ctx context.Context, queries []Query, concurrency int, quota Quota, newRunner RunnerFactory) ([]storage.ProfileResult, []QueryResult) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > len(queries) {
+		concurrency = len(queries)
+	}
+
+	reports := make([]QueryResult, len(queries))
+	found := make([][]storage.ProfileResult, len(queries))
+	for i, query := range queries {
+		reports[i].Query = query
+	}
+
+	pool := &quotaPool{quota: quota, reserved: make(map[int]int)}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < concurrency; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+
+			share := &quotaShare{pool: pool, worker: worker}
+			var runnerQuota Quota
+			if quota != nil {
+				runnerQuota = share
+			}
+			runner, release, err := newRunner(runnerQuota)
+			if err != nil {
+				for i := range jobs {
+					reports[i].Err = err
+				}
+				return
+			}
+			defer release()
+
+			for i := range jobs {
+				share.spent = 0
+				if err := ctx.Err(); err != nil {
+					reports[i].Err = err
+					continue
+				}
+				results, err := runner.Run(ctx, queries[i].URL, queries[i].MaxResults)
+				share.release()
+				found[i] = results
+				reports[i].Found = len(results)
+				reports[i].Searches = share.spent
+				reports[i].Err = err
+			}
+		}(worker)
+	}
+
+	for i := range queries {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	seen := identity.NewDeduper()
+	var merged []storage.ProfileResult
+	for i, results := range found {
+		for _, result := range results {
+			if seen.Add(result.URL, result.Name, result.Company) {
+				merged = append(merged, result)
+				reports[i].Added++
+			}
+		}
+	}
+	return merged, reports
+}
+
+// quotaPool shares one quota among concurrent workers. A runner reserves the pages it plans to load,
+// the reservation is held for its worker until the query ends, and other workers are only offered
+// what is left after every reservation.
+type quotaPool struct {
+	quota    Quota
+	reserved map[int]int
+	mutex    sync.Mutex
+}
+
+// quotaShare is one worker's view of the pool
+type quotaShare struct {
+	pool   *quotaPool
+	worker int
+	spent  int // Searches recorded during the current query
+}
+
+// Remaining returns the quota not reserved by other workers
+func (s *quotaShare) Remaining() int {
+	s.pool.mutex.Lock()
+	defer s.pool.mutex.Unlock()
+
+	return s.available()
+}
+
+// Reserve holds up to pages searches for this worker, replacing its earlier reservation, and returns how many it got
+func (s *quotaShare) Reserve(pages int) int {
+	s.pool.mutex.Lock()
+	defer s.pool.mutex.Unlock()
+
+	if available := s.available(); available < pages {
+		pages = available
+	}
+	s.pool.reserved[s.worker] = pages
+	return pages
+}
+
+// available is the quota left after other workers' reservations; the caller holds the mutex
+func (s *quotaShare) available() int {
+	available := s.pool.quota.Remaining()
+	for worker, reserved := range s.pool.reserved {
+		if worker != s.worker {
+			available -= reserved
+		}
+	}
+	if available < 0 {
+		return 0
+	}
+	return available
+}
+
+// RecordSearch spends one search, drawing down this worker's reservation
+func (s *quotaShare) RecordSearch() {
+	s.pool.mutex.Lock()
+	defer s.pool.mutex.Unlock()
+
+	s.pool.quota.RecordSearch()
+	if s.pool.reserved[s.worker] > 0 {
+		s.pool.reserved[s.worker]--
+	}
+	s.spent++
+}
+
+// release returns the unspent reservation once the worker's query has finished
+func (s *quotaShare) release() {
+	s.pool.mutex.Lock()
+	defer s.pool.mutex.Unlock()
+
+	delete(s.pool.reserved, s.worker)
+}
@@ -0,0 +1,123 @@
+package savedsearch
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/search"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// pagingRunner plans like PageRunner: it reserves a page per 10 results, then spends them
+type pagingRunner struct {
+	quota   Quota
+	results map[string][]storage.ProfileResult
+	active  *int
+	peak    *int
+	mutex   *sync.Mutex
+}
+
+func (r *pagingRunner) Run(ctx context.Context, searchURL string, maxResults int) ([]storage.ProfileResult, error) {
+	r.mutex.Lock()
+	*r.active++
+	if *r.active > *r.peak {
+		*r.peak = *r.active
+	}
+	r.mutex.Unlock()
+	defer func() {
+		r.mutex.Lock()
+		*r.active--
+		r.mutex.Unlock()
+	}()
+
+	pages := (maxResults + 9) / 10
+	if r.quota != nil {
+		pages = reserve(r.quota, pages)
+	}
+	for i := 0; i < pages; i++ {
+		time.Sleep(5 * time.Millisecond)
+		if r.quota != nil {
+			r.quota.RecordSearch()
+		}
+	}
+	return r.results[searchURL], nil
+}
+
+// TestRunQueriesMergesWithinQuota tests merging concurrent queries into one pool without overspending the quota
+func TestRunQueriesMergesWithinQuota(t *testing.T) {
+	results := map[string][]storage.ProfileResult{
+		"a": profiles("alice", "bob"),
+		"b": append(profiles("carol"), storage.ProfileResult{URL: "https://www.linkedin.com/in/Bob?trk=x", Name: "bob"}),
+		"c": profiles("dave", "alice"),
+		"d": profiles("erin"),
+	}
+	queries := []Query{
+		{Name: "a", URL: "a", MaxResults: 60},
+		{Name: "b", URL: "b", MaxResults: 60},
+		{Name: "c", URL: "c", MaxResults: 60},
+		{Name: "d", URL: "d", MaxResults: 60},
+	}
+
+	limiter := search.NewRateLimiter(15, time.Hour)
+	var mutex sync.Mutex
+	active, peak, opened := 0, 0, 0
+	factory := func(quota Quota) (Runner, func(), error) {
+		mutex.Lock()
+		opened++
+		mutex.Unlock()
+		return &pagingRunner{quota: quota, results: results, active: &active, peak: &peak, mutex: &mutex}, func() {}, nil
+	}
+
+	pool, reports := RunQueries(context.Background(), queries, 2, limiter, factory)
+
+	var names []string
+	for _, result := range pool {
+		names = append(names, result.Name)
+	}
+	expected := []string{"alice", "bob", "carol", "dave", "erin"}
+	if len(names) != len(expected) {
+		t.Fatalf("expected pool %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("expected pool %v in query order, got %v", expected, names)
+		}
+	}
+
+	spent := 0
+	for _, report := range reports {
+		if report.Err != nil {
+			t.Errorf("query %s failed: %v", report.Query.Name, report.Err)
+		}
+		spent += report.Searches
+	}
+	if spent != 15 || limiter.Remaining() != 0 {
+		t.Errorf("expected the queries to spend exactly the quota of 15, spent %d with %d left", spent, limiter.Remaining())
+	}
+	if reports[0].Added != 2 || reports[1].Added != 1 || reports[2].Added != 1 || reports[3].Added != 1 {
+		t.Errorf("unexpected per-query additions %+v", reports)
+	}
+	if opened != 2 || peak > 2 {
+		t.Errorf("expected 2 runners with at most 2 queries at once, got %d runners and a peak of %d", opened, peak)
+	}
+}
+
+// TestRunQueriesInSequence tests that a concurrency below 2 runs one query at a time without a quota
+func TestRunQueriesInSequence(t *testing.T) {
+	results := map[string][]storage.ProfileResult{"a": profiles("alice"), "b": profiles("bob")}
+	var mutex sync.Mutex
+	active, peak := 0, 0
+	factory := func(quota Quota) (Runner, func(), error) {
+		if quota != nil {
+			t.Errorf("expected no quota")
+		}
+		return &pagingRunner{results: results, active: &active, peak: &peak, mutex: &mutex}, func() {}, nil
+	}
+
+	pool, _ := RunQueries(context.Background(), []Query{{Name: "a", URL: "a"}, {Name: "b", URL: "b"}}, 0, nil, factory)
+	if len(pool) != 2 || peak != 1 {
+		t.Errorf("expected 2 leads from sequential queries, got %d with a peak of %d", len(pool), peak)
+	}
+}
@@ -0,0 +1,182 @@
+package savedsearch
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"linkedin-automation-framework/internal/browser"
+	"linkedin-automation-framework/internal/search"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// SearchURL returns query unchanged if it is already a URL, otherwise a people search for it as keywords
+func SearchURL(query string) string {
+	query = strings.TrimSpace(query)
+	if strings.HasPrefix(query, "http://") || strings.HasPrefix(query, "https://") {
+		return query
+	}
+	return search.PeopleSearchURL + "?keywords=" + url.QueryEscape(query)
+}
+
+// ErrQuotaExhausted is returned when the search quota allows no further result pages
+var ErrQuotaExhausted = stderrors.New("search quota exhausted")
+
+// Quota is the search rate limit; each loaded results page spends one search
+type Quota interface {
+	Remaining() int
+	RecordSearch()
+}
+
+// PageRunner runs searches in a browser page, reading each results page with a search manager
+type PageRunner struct {
+	page     browser.PageDriver
+	searcher *search.SearchManager
+	quota    Quota
+	onPlan   func(searchURL string, plan search.Plan)
+	pause    func(ctx context.Context, position string) error
+}
+
+// NewPageRunner creates a runner that searches in page
+func NewPageRunner(page browser.PageDriver, searcher *search.SearchManager) *PageRunner {
+	return &PageRunner{page: page, searcher: searcher}
+}
+
+// SetQuota makes the runner spend quota on every results page and plan runs within what is left
+func (r *PageRunner) SetQuota(quota Quota) {
+	r.quota = quota
+}
+
+// OnPlan sets a function called with each run's page plan before paginating, e.g. to warn when the quota limits it
+func (r *PageRunner) OnPlan(onPlan func(searchURL string, plan search.Plan)) {
+	r.onPlan = onPlan
+}
+
+// SetCheckpoint sets a function called at each safe point, before a results page is loaded,
+// that may block to pause the run there
+func (r *PageRunner) SetCheckpoint(checkpoint func(ctx context.Context, position string) error) {
+	r.pause = checkpoint
+}
+
+// Run opens the search and pages through its results until maxResults profiles are read, the results
+// end or the planned pages are used. The plan compares LinkedIn's result estimate with the remaining
+// quota after the first page, so a run is cut short up front rather than stopped midway by the limit.
+func (r *PageRunner) Run(ctx context.Context, searchURL string, maxResults int) ([]storage.ProfileResult, error) {
+	remaining := -1
+	if r.quota != nil {
+		remaining = r.quota.Remaining()
+		if reserve(r.quota, 1) == 0 {
+			return nil, ErrQuotaExhausted
+		}
+	}
+
+	if err := r.checkpoint(ctx, fmt.Sprintf("search %s", searchURL)); err != nil {
+		return nil, err
+	}
+	if err := r.page.Navigate(searchURL); err != nil {
+		return nil, fmt.Errorf("failed to open search %s: %w", searchURL, err)
+	}
+	r.recordPage()
+	if err := r.page.WaitLoad(); err != nil {
+		return nil, fmt.Errorf("failed to wait for search %s: %w", searchURL, err)
+	}
+
+	estimate := -1
+	if count, ok := r.searcher.EstimateResults(ctx, r.page); ok {
+		estimate = count
+	}
+	plan := search.PlanPages(estimate, maxResults, remaining)
+	if r.quota != nil && plan.Pages > 1 {
+		// Concurrent runs may have reserved part of the quota since it was read
+		if granted := reserve(r.quota, plan.Pages-1) + 1; granted < plan.Pages {
+			plan.Pages = granted
+			plan.Limited = true
+			plan.Reason = fmt.Sprintf("search quota allows %d of the %d pages needed", granted, plan.Wanted)
+		}
+	}
+	if r.onPlan != nil {
+		r.onPlan(searchURL, plan)
+	}
+
+	var results []storage.ProfileResult
+	for page := 1; page <= plan.Pages; page++ {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		profiles, err := r.searcher.ExtractProfiles(ctx, r.page)
+		if err != nil {
+			return results, err
+		}
+		for _, profile := range profiles {
+			results = append(results, storageResult(profile))
+			if maxResults > 0 && len(results) >= maxResults {
+				return results, nil
+			}
+		}
+
+		if len(profiles) == 0 || page == plan.Pages {
+			return results, nil
+		}
+		if err := r.checkpoint(ctx, fmt.Sprintf("page %d of %d of search %s", page+1, plan.Pages, searchURL)); err != nil {
+			return results, err
+		}
+		// Pagination errors mean there is no further page
+		if r.searcher.HandlePagination(ctx, r.page) != nil {
+			return results, nil
+		}
+		r.recordPage()
+	}
+	return results, nil
+}
+
+// reserve asks the quota for pages and returns how many may be loaded. Quotas shared between
+// concurrent runs hold the grant for this runner; others just report what is left.
+func reserve(quota Quota, pages int) int {
+	if reserver, ok := quota.(interface{ Reserve(pages int) int }); ok {
+		return reserver.Reserve(pages)
+	}
+	if remaining := quota.Remaining(); remaining < pages {
+		return remaining
+	}
+	return pages
+}
+
+// checkpoint waits at a safe point if a checkpoint function is set
+func (r *PageRunner) checkpoint(ctx context.Context, position string) error {
+	if r.pause == nil {
+		return nil
+	}
+	return r.pause(ctx, position)
+}
+
+// recordPage spends one search from the quota
+func (r *PageRunner) recordPage() {
+	if r.quota != nil {
+		r.quota.RecordSearch()
+	}
+}
+
+// storageResult converts an extracted profile to its stored form
+func storageResult(profile search.ProfileResult) storage.ProfileResult {
+	return storage.ProfileResult{
+		URL:       profile.URL,
+		Name:      profile.Name,
+		Title:     profile.Title,
+		Company:   profile.Company,
+		Location:  profile.Location,
+		Mutual:    profile.Mutual,
+		Followers: profile.Followers,
+		PhotoURL:  profile.PhotoURL,
+		Premium:   profile.Premium,
+		Timestamp: profile.Timestamp,
+		Confidence: storage.FieldConfidence{
+			Name:     string(profile.Confidence.Name),
+			Title:    string(profile.Confidence.Title),
+			Company:  string(profile.Confidence.Company),
+			Location: string(profile.Confidence.Location),
+		},
+	}
+}
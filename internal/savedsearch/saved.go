@@ -0,0 +1,217 @@
+package savedsearch
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"linkedin-automation-framework/internal/storage"
+)
+
+// DefaultMaxResults is the number of profiles a saved search reads per run unless it sets its own
+const DefaultMaxResults = 100
+
+// ErrNotFound is returned when no saved search has the requested name
+var ErrNotFound = stderrors.New("saved search not found")
+
+// ErrExists is returned when creating a saved search under a name already in use
+var ErrExists = stderrors.New("saved search already exists")
+
+// namePattern restricts names to something easy to type on the command line
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// Store is the storage needed to manage and run saved searches
+type Store interface {
+	SnapshotStore
+	SaveSavedSearch(search storage.SavedSearch) error
+	GetSavedSearch(name string) (storage.SavedSearch, bool, error)
+	GetSavedSearches() ([]storage.SavedSearch, error)
+	DeleteSavedSearch(name string) error
+}
+
+// Validate checks a saved search's name and settings, normalizing the query to a search URL
+func Validate(search *storage.SavedSearch) error {
+	if !namePattern.MatchString(search.Name) {
+		return fmt.Errorf("invalid saved search name %q: use letters, digits, '.', '_' and '-'", search.Name)
+	}
+	if search.Query == "" {
+		return fmt.Errorf("saved search %s needs a search URL or keywords", search.Name)
+	}
+	if search.MaxResults < 0 {
+		return fmt.Errorf("saved search %s: max results cannot be negative", search.Name)
+	}
+	if search.Interval < 0 {
+		return fmt.Errorf("saved search %s: interval cannot be negative", search.Name)
+	}
+	if search.Interval > 0 && search.Interval < time.Minute {
+		return fmt.Errorf("saved search %s: interval must be at least a minute", search.Name)
+	}
+
+	search.Query = SearchURL(search.Query)
+	if search.MaxResults == 0 {
+		search.MaxResults = DefaultMaxResults
+	}
+	return nil
+}
+
+// Create saves a new search, failing with ErrExists if the name is taken
+func Create(store Store, search storage.SavedSearch, now time.Time) (storage.SavedSearch, error) {
+	if err := Validate(&search); err != nil {
+		return search, err
+	}
+	if _, found, err := store.GetSavedSearch(search.Name); err != nil {
+		return search, err
+	} else if found {
+		return search, fmt.Errorf("%w: %s", ErrExists, search.Name)
+	}
+
+	search.CreatedAt = now
+	search.LastRunAt = time.Time{}
+	search.LastFound, search.LastNew, search.LastError = 0, 0, ""
+	search.Runs, search.TotalNew = 0, 0
+	return search, store.SaveSavedSearch(search)
+}
+
+// Get loads a saved search, failing with ErrNotFound if it does not exist
+func Get(store Store, name string) (storage.SavedSearch, error) {
+	search, found, err := store.GetSavedSearch(name)
+	if err != nil {
+		return search, err
+	}
+	if !found {
+		return search, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return search, nil
+}
+
+// Update applies change to an existing saved search and saves it, keeping its run history
+func Update(store Store, name string, change func(*storage.SavedSearch)) (storage.SavedSearch, error) {
+	search, err := Get(store, name)
+	if err != nil {
+		return search, err
+	}
+	change(&search)
+	search.Name = name
+	if err := Validate(&search); err != nil {
+		return search, err
+	}
+	return search, store.SaveSavedSearch(search)
+}
+
+// Delete removes a saved search, failing with ErrNotFound if it does not exist
+func Delete(store Store, name string) error {
+	if _, err := Get(store, name); err != nil {
+		return err
+	}
+	return store.DeleteSavedSearch(name)
+}
+
+// Run refreshes a saved search by name and records the run's counts or error on it.
+// Runs share the query's snapshot, so a saved search and watch-search on the same URL never report a profile twice.
+func Run(ctx context.Context, store Store, runner Runner, name string, now time.Time) (RunReport, error) {
+	search, err := Get(store, name)
+	if err != nil {
+		return RunReport{}, err
+	}
+
+	report, runErr := Refresh(ctx, store, runner, search.Query, search.MaxResults, now)
+
+	search.LastRunAt = now
+	search.LastError = ""
+	if runErr != nil {
+		search.LastError = runErr.Error()
+	} else {
+		search.LastFound = report.Found
+		search.LastNew = len(report.New)
+		search.TotalNew += len(report.New)
+		search.Runs++
+	}
+	if err := store.SaveSavedSearch(search); err != nil {
+		return report, fmt.Errorf("failed to record run of %s: %w", name, err)
+	}
+	return report, runErr
+}
+
+// Due returns the scheduled searches whose interval has passed since their last run; never-run ones are always due
+func Due(searches []storage.SavedSearch, now time.Time) []storage.SavedSearch {
+	var due []storage.SavedSearch
+	for _, search := range searches {
+		if search.Interval > 0 && !now.Before(search.LastRunAt.Add(search.Interval)) {
+			due = append(due, search)
+		}
+	}
+	return due
+}
+
+// NextDue returns when the earliest scheduled search becomes due, or false if none is scheduled
+func NextDue(searches []storage.SavedSearch) (time.Time, bool) {
+	var next time.Time
+	found := false
+	for _, search := range searches {
+		if search.Interval <= 0 {
+			continue
+		}
+		at := search.LastRunAt.Add(search.Interval)
+		if !found || at.Before(next) {
+			next, found = at, true
+		}
+	}
+	return next, found
+}
+
+// Schedule runs every due saved search, then sleeps until the next one is due, until the context is
+// cancelled. Searches added or changed while it runs are picked up at the next wake-up, which is at
+// most pollInterval away. hold, if set, returns the end of a blackout holding runs back, or zero
+// when there is none; due searches stay queued until it ends. beat, if set, is called whenever
+// the scheduler wakes up and after each run, so a caller can tell it is still making progress.
+func Schedule(ctx context.Context, store Store, runner Runner, pollInterval time.Duration, hold func(time.Time) time.Time, beat func(time.Time), onRun func(string, RunReport, error)) error {
+	if beat == nil {
+		beat = func(time.Time) {}
+	}
+	for {
+		beat(time.Now())
+		searches, err := store.GetSavedSearches()
+		if err != nil {
+			return fmt.Errorf("failed to load saved searches: %w", err)
+		}
+
+		var heldUntil time.Time
+		for _, search := range Due(searches, time.Now()) {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if hold != nil {
+				if heldUntil = hold(time.Now()); !heldUntil.IsZero() {
+					break
+				}
+			}
+			report, err := Run(ctx, store, runner, search.Name, time.Now())
+			beat(time.Now())
+			onRun(search.Name, report, err)
+		}
+
+		wait := pollInterval
+		if !heldUntil.IsZero() {
+			if time.Until(heldUntil) < wait {
+				wait = time.Until(heldUntil)
+			}
+		} else if searches, err = store.GetSavedSearches(); err == nil {
+			if next, ok := NextDue(searches); ok && time.Until(next) < wait {
+				wait = time.Until(next)
+			}
+		}
+		if wait < time.Second {
+			wait = time.Second
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
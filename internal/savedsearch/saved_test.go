@@ -0,0 +1,152 @@
+package savedsearch
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/storage"
+)
+
+// TestSavedSearchLifecycle tests creating, running, updating and deleting a saved search
+func TestSavedSearchLifecycle(t *testing.T) {
+	for _, storageType := range []string{"sqlite", "json"} {
+		t.Run(storageType, func(t *testing.T) {
+			store, err := storage.NewStorageManager(storage.StorageConfig{Type: storageType, Path: t.TempDir(), Database: "test.db"})
+			if err != nil {
+				t.Fatalf("failed to create storage: %v", err)
+			}
+			defer store.Close()
+
+			created := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+			search, err := Create(store, storage.SavedSearch{Name: "sre-berlin", Query: "sre berlin", Interval: 6 * time.Hour}, created)
+			if err != nil {
+				t.Fatalf("failed to create saved search: %v", err)
+			}
+			if search.Query != SearchURL("sre berlin") || search.MaxResults != DefaultMaxResults {
+				t.Errorf("expected normalized query and default max results, got %+v", search)
+			}
+			if _, err := Create(store, storage.SavedSearch{Name: "sre-berlin", Query: "other"}, created); !stderrors.Is(err, ErrExists) {
+				t.Errorf("expected ErrExists, got %v", err)
+			}
+
+			runner := &fakeRunner{runs: [][]storage.ProfileResult{profiles("alice", "bob"), profiles("bob", "carol")}}
+			for i := 0; i < 2; i++ {
+				if _, err := Run(context.Background(), store, runner, "sre-berlin", created.Add(time.Duration(i+1)*time.Hour)); err != nil {
+					t.Fatalf("run %d failed: %v", i+1, err)
+				}
+			}
+
+			search, err = Get(store, "sre-berlin")
+			if err != nil {
+				t.Fatalf("failed to get saved search: %v", err)
+			}
+			if search.Runs != 2 || search.LastFound != 2 || search.LastNew != 1 || search.TotalNew != 3 {
+				t.Errorf("unexpected run metadata %+v", search)
+			}
+			if !search.LastRunAt.Equal(created.Add(2*time.Hour)) || !search.CreatedAt.Equal(created) {
+				t.Errorf("unexpected timestamps %+v", search)
+			}
+
+			search, err = Update(store, "sre-berlin", func(s *storage.SavedSearch) { s.MaxResults = 25 })
+			if err != nil {
+				t.Fatalf("failed to update saved search: %v", err)
+			}
+			if search.MaxResults != 25 || search.Runs != 2 {
+				t.Errorf("update should change settings and keep history, got %+v", search)
+			}
+
+			if err := Delete(store, "sre-berlin"); err != nil {
+				t.Fatalf("failed to delete saved search: %v", err)
+			}
+			if _, err := Get(store, "sre-berlin"); !stderrors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound after delete, got %v", err)
+			}
+			if err := Delete(store, "sre-berlin"); !stderrors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+			}
+		})
+	}
+}
+
+// TestSavedSearchValidation tests that unusable names and settings are rejected
+func TestSavedSearchValidation(t *testing.T) {
+	invalid := []storage.SavedSearch{
+		{Name: "", Query: "go"},
+		{Name: "has space", Query: "go"},
+		{Name: "no-query"},
+		{Name: "negative", Query: "go", MaxResults: -1},
+		{Name: "too-often", Query: "go", Interval: time.Second},
+	}
+	for _, search := range invalid {
+		if err := Validate(&search); err == nil {
+			t.Errorf("expected %+v to be rejected", search)
+		}
+	}
+}
+
+// TestDueSearches tests which scheduled searches are due and when the next one is
+func TestDueSearches(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	searches := []storage.SavedSearch{
+		{Name: "never-run", Interval: time.Hour},
+		{Name: "overdue", Interval: time.Hour, LastRunAt: now.Add(-2 * time.Hour)},
+		{Name: "recent", Interval: 6 * time.Hour, LastRunAt: now.Add(-time.Hour)},
+		{Name: "on-demand", LastRunAt: now.Add(-48 * time.Hour)},
+	}
+
+	due := Due(searches, now)
+	if len(due) != 2 || due[0].Name != "never-run" || due[1].Name != "overdue" {
+		t.Errorf("unexpected due searches %+v", due)
+	}
+
+	next, ok := NextDue(searches[2:])
+	if !ok || !next.Equal(now.Add(5*time.Hour)) {
+		t.Errorf("expected next run at %v, got %v (%v)", now.Add(5*time.Hour), next, ok)
+	}
+	if _, ok := NextDue(searches[3:]); ok {
+		t.Errorf("on-demand searches should never be due")
+	}
+}
+
+// TestScheduleHoldsDuringBlackout tests that due searches stay queued while hold reports a blackout
+func TestScheduleHoldsDuringBlackout(t *testing.T) {
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "json", Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+	if _, err := Create(store, storage.SavedSearch{Name: "sre-berlin", Query: "sre berlin", Interval: time.Hour}, time.Now()); err != nil {
+		t.Fatalf("failed to create saved search: %v", err)
+	}
+
+	runner := &fakeRunner{runs: [][]storage.ProfileResult{profiles("ada")}}
+	held := func(now time.Time) time.Time { return now.Add(time.Hour) }
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = Schedule(ctx, store, runner, time.Minute, held, nil, func(string, RunReport, error) {
+		t.Errorf("expected no run during the blackout")
+	})
+	if !stderrors.Is(err, context.DeadlineExceeded) || runner.call != 0 {
+		t.Fatalf("expected the scheduler to wait out the blackout, got %v after %d runs", err, runner.call)
+	}
+
+	// Once the blackout is over, the queued search runs
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	beats := 0
+	beat := func(time.Time) { beats++ }
+	err = Schedule(ctx, store, runner, time.Minute, func(time.Time) time.Time { return time.Time{} }, beat, func(name string, report RunReport, err error) {
+		if err != nil || name != "sre-berlin" {
+			t.Errorf("unexpected run of %s: %v", name, err)
+		}
+		cancel()
+	})
+	if !stderrors.Is(err, context.Canceled) || runner.call != 1 {
+		t.Errorf("expected the queued search to run once, got %v after %d runs", err, runner.call)
+	}
+	if beats != 2 {
+		t.Errorf("expected a beat on waking up and after the run, got %d", beats)
+	}
+}
@@ -0,0 +1,119 @@
+package savedsearch
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// SnapshotStore is the storage needed to remember what a recurring search has already returned
+type SnapshotStore interface {
+	GetSearchSnapshot(query string) (storage.SearchSnapshot, bool, error)
+	SaveSearchSnapshot(snapshot storage.SearchSnapshot) error
+	SaveSearchResults(results []storage.ProfileResult) error
+}
+
+// Runner executes a people search and returns up to maxResults profiles
+type Runner interface {
+	Run(ctx context.Context, searchURL string, maxResults int) ([]storage.ProfileResult, error)
+}
+
+// RunReport describes one run of a recurring search
+type RunReport struct {
+	Query    string
+	Run      int                     // 1 for the first run of the query
+	Previous time.Time               // When the previous run finished, zero on the first run
+	Found    int                     // Profiles the search returned this run
+	New      []storage.ProfileResult // Profiles not returned by any earlier run
+	Seen     int                     // Profiles returned by any run so far
+}
+
+// Diff returns the results not in the snapshot, keeping the first of any repeated profile.
+// Profiles are compared by URL slug, so links differing only in tracking parameters or case match.
+func Diff(snapshot storage.SearchSnapshot, results []storage.ProfileResult) []storage.ProfileResult {
+	seen := identity.NewDeduper()
+	for _, profileURL := range snapshot.ProfileURLs {
+		seen.Add(profileURL, "", "")
+	}
+
+	var fresh []storage.ProfileResult
+	for _, result := range results {
+		if seen.Add(result.URL, "", "") {
+			fresh = append(fresh, result)
+		}
+	}
+	return fresh
+}
+
+// Refresh runs the search once, saves the profiles no earlier run returned and adds them to the
+// query's snapshot. The snapshot keeps every profile ever returned, so a profile that drops out of
+// the results and comes back later is not reported again.
+func Refresh(ctx context.Context, store SnapshotStore, runner Runner, query string, maxResults int, now time.Time) (RunReport, error) {
+	report := RunReport{Query: query}
+
+	snapshot, found, err := store.GetSearchSnapshot(query)
+	if err != nil {
+		return report, fmt.Errorf("failed to load search snapshot: %w", err)
+	}
+	if !found {
+		snapshot = storage.SearchSnapshot{Query: query}
+	}
+	report.Run = snapshot.Runs + 1
+	report.Previous = snapshot.TakenAt
+
+	results, err := runner.Run(ctx, query, maxResults)
+	if err != nil {
+		return report, fmt.Errorf("search failed: %w", err)
+	}
+	report.Found = len(results)
+	report.New = Diff(snapshot, results)
+
+	if len(report.New) > 0 {
+		if err := store.SaveSearchResults(report.New); err != nil {
+			return report, fmt.Errorf("failed to save new results: %w", err)
+		}
+	}
+
+	for _, result := range report.New {
+		snapshot.ProfileURLs = append(snapshot.ProfileURLs, result.URL)
+	}
+	snapshot.TakenAt = now
+	snapshot.Runs = report.Run
+	if err := store.SaveSearchSnapshot(snapshot); err != nil {
+		return report, fmt.Errorf("failed to save search snapshot: %w", err)
+	}
+	report.Seen = len(snapshot.ProfileURLs)
+
+	return report, nil
+}
+
+// WatchOptions controls a recurring search
+type WatchOptions struct {
+	Query      string        // Search URL
+	MaxResults int           // Profiles read per run
+	Interval   time.Duration // Time between runs; zero runs the search once
+}
+
+// Watch refreshes the search every interval until the context is cancelled, passing each run's
+// report to onRun. A failed run is reported and retried at the next interval rather than ending the watch.
+func Watch(ctx context.Context, store SnapshotStore, runner Runner, options WatchOptions, onRun func(RunReport, error)) error {
+	for {
+		report, err := Refresh(ctx, store, runner, options.Query, options.MaxResults, time.Now())
+		onRun(report, err)
+
+		if options.Interval <= 0 {
+			return err
+		}
+
+		timer := time.NewTimer(options.Interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package savedsearch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/search"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// fakeRunner returns a fixed result list per run
+type fakeRunner struct {
+	runs [][]storage.ProfileResult
+	call int
+}
+
+func (r *fakeRunner) Run(ctx context.Context, searchURL string, maxResults int) ([]storage.ProfileResult, error) {
+	results := r.runs[r.call]
+	r.call++
+	return results, nil
+}
+
+func profiles(slugs ...string) []storage.ProfileResult {
+	var results []storage.ProfileResult
+	for _, slug := range slugs {
+		results = append(results, storage.ProfileResult{URL: "https://www.linkedin.com/in/" + slug + "/", Name: slug})
+	}
+	return results
+}
+
+// TestRefreshReportsOnlyNewProfiles tests that repeated runs report profiles no earlier run returned
+func TestRefreshReportsOnlyNewProfiles(t *testing.T) {
+	for _, storageType := range []string{"sqlite", "json"} {
+		t.Run(storageType, func(t *testing.T) {
+			store, err := storage.NewStorageManager(storage.StorageConfig{Type: storageType, Path: t.TempDir(), Database: "test.db"})
+			if err != nil {
+				t.Fatalf("failed to create storage: %v", err)
+			}
+			defer store.Close()
+
+			runner := &fakeRunner{runs: [][]storage.ProfileResult{
+				profiles("alice", "bob"),
+				append(profiles("bob", "carol"), storage.ProfileResult{URL: "https://de.linkedin.com/in/Alice?trk=x"}),
+				profiles("carol", "dave"),
+			}}
+			query := SearchURL("site reliability")
+			start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+
+			expected := []struct {
+				fresh []string
+				seen  int
+			}{
+				{[]string{"alice", "bob"}, 2},
+				{[]string{"carol"}, 3},
+				{[]string{"dave"}, 4},
+			}
+			for i, want := range expected {
+				report, err := Refresh(context.Background(), store, runner, query, 0, start.Add(time.Duration(i)*time.Hour))
+				if err != nil {
+					t.Fatalf("run %d failed: %v", i+1, err)
+				}
+				if report.Run != i+1 {
+					t.Errorf("run %d reported as run %d", i+1, report.Run)
+				}
+				if len(report.New) != len(want.fresh) {
+					t.Fatalf("run %d: expected %d new profiles, got %d", i+1, len(want.fresh), len(report.New))
+				}
+				for j, name := range want.fresh {
+					if report.New[j].Name != name {
+						t.Errorf("run %d: expected new profile %s, got %s", i+1, name, report.New[j].Name)
+					}
+				}
+				if report.Seen != want.seen {
+					t.Errorf("run %d: expected %d profiles seen, got %d", i+1, want.seen, report.Seen)
+				}
+				if i > 0 && !report.Previous.Equal(start.Add(time.Duration(i-1)*time.Hour)) {
+					t.Errorf("run %d: unexpected previous run time %v", i+1, report.Previous)
+				}
+			}
+
+			results, err := store.GetSearchResults()
+			if err != nil {
+				t.Fatalf("failed to get search results: %v", err)
+			}
+			if len(results) != 4 {
+				t.Errorf("expected 4 saved results, got %d", len(results))
+			}
+		})
+	}
+}
+
+// TestSearchURL tests that keywords become a people search and URLs are kept
+func TestSearchURL(t *testing.T) {
+	if got := SearchURL(" go developer "); got != search.PeopleSearchURL+"?keywords=go+developer" {
+		t.Errorf("unexpected keyword search URL %s", got)
+	}
+	custom := search.PeopleSearchURL + "?keywords=go&network=%5B%22S%22%5D"
+	if got := SearchURL(custom); got != custom {
+		t.Errorf("expected URL unchanged, got %s", got)
+	}
+}
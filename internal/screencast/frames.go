@@ -0,0 +1,88 @@
+package screencast
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// frameWriter saves a recording's frames and lists them for ffmpeg
+type frameWriter struct {
+	dir   string
+	files []string
+	times []time.Time
+	err   error // First frame that failed to save
+	mutex sync.Mutex
+}
+
+// newFrameWriter creates the directory frames are saved in
+func newFrameWriter(dir string) (*frameWriter, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create frame directory: %w", err)
+	}
+	return &frameWriter{dir: dir}, nil
+}
+
+// add saves a JPEG frame painted at at
+func (w *frameWriter) add(jpeg []byte, at time.Time) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	file := fmt.Sprintf("%06d.jpg", len(w.files)+1)
+	if err := os.WriteFile(filepath.Join(w.dir, file), jpeg, 0o644); err != nil {
+		return fmt.Errorf("failed to save frame: %w", err)
+	}
+	if len(w.times) > 0 && at.Before(w.times[len(w.times)-1]) {
+		at = w.times[len(w.times)-1]
+	}
+	w.files = append(w.files, file)
+	w.times = append(w.times, at)
+	return nil
+}
+
+// fail remembers the first error saving a frame, which close reports
+func (w *frameWriter) fail(err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// close writes the frame list, showing the last frame until end
+func (w *frameWriter) close(end time.Time) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.err != nil {
+		return w.err
+	}
+	if len(w.files) == 0 {
+		_ = os.Remove(w.dir) // Nothing to keep
+		return ErrNoFrames
+	}
+
+	var list strings.Builder
+	for i, file := range w.files {
+		next := end
+		if i+1 < len(w.times) {
+			next = w.times[i+1]
+		}
+		duration := next.Sub(w.times[i])
+		if duration <= 0 {
+			duration = time.Millisecond
+		}
+		fmt.Fprintf(&list, "file '%s'\nduration %.3f\n", file, duration.Seconds())
+	}
+	// The concat demuxer ignores the duration of the last file unless it is listed again
+	fmt.Fprintf(&list, "file '%s'\n", w.files[len(w.files)-1])
+
+	if err := os.WriteFile(filepath.Join(w.dir, ListFile), []byte(list.String()), 0o644); err != nil {
+		return fmt.Errorf("failed to write frame list: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Package screencast records videos of browser pages for review, such as demoing how a run
+// behaves or finding out what happened before an account was flagged.
+//
+// Chrome streams a page's frames over the DevTools protocol while it is recorded. They are saved
+// as JPEG files and encoded into a WebM file with ffmpeg once recording stops. Without ffmpeg the
+// frames are kept, together with the list Encode needs to encode them later.
+package screencast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ListFile lists a recording's frames with how long each is shown, in ffmpeg's concat format
+const ListFile = "frames.txt"
+
+// Options configures recordings
+type Options struct {
+	FFmpeg    string // ffmpeg executable; empty keeps the frames unencoded
+	Quality   int    // JPEG quality of the frames, 1 to 100
+	MaxWidth  int    // Frames are scaled down to fit, 0 for no limit
+	MaxHeight int
+}
+
+// Recording is a page being recorded
+type Recording struct {
+	page    *rod.Page
+	output  string
+	frames  *frameWriter
+	options Options
+	cancel  func()
+	done    chan struct{}
+	once    sync.Once
+	err     error
+}
+
+// Start records page into output, a .webm file; its frames are kept in a directory next to it
+// until they are encoded
+func Start(page *rod.Page, output string, options Options) (*Recording, error) {
+	frames, err := newFrameWriter(strings.TrimSuffix(output, filepath.Ext(output)) + ".frames")
+	if err != nil {
+		return nil, err
+	}
+
+	events, cancel := page.WithCancel()
+	r := &Recording{page: page, output: output, frames: frames, options: options, cancel: cancel, done: make(chan struct{})}
+	wait := events.EachEvent(func(e *proto.PageScreencastFrame) {
+		at := time.Now()
+		if e.Metadata != nil && e.Metadata.Timestamp > 0 {
+			at = e.Metadata.Timestamp.Time()
+		}
+		if err := frames.add(e.Data, at); err != nil {
+			frames.fail(err)
+		}
+		// Chrome sends no further frames until the last one is acknowledged
+		_ = proto.PageScreencastFrameAck{SessionID: e.SessionID}.Call(events)
+	})
+	go func() {
+		defer close(r.done)
+		wait()
+	}()
+
+	request := proto.PageStartScreencast{Format: proto.PageStartScreencastFormatJpeg}
+	if options.Quality > 0 {
+		request.Quality = &options.Quality
+	}
+	if options.MaxWidth > 0 {
+		request.MaxWidth = &options.MaxWidth
+	}
+	if options.MaxHeight > 0 {
+		request.MaxHeight = &options.MaxHeight
+	}
+	if err := request.Call(page); err != nil {
+		cancel()
+		<-r.done
+		return nil, fmt.Errorf("failed to start screencast: %w", err)
+	}
+	return r, nil
+}
+
+// Output returns the video file the recording is encoded into
+func (r *Recording) Output() string {
+	return r.output
+}
+
+// Stop stops recording and encodes the video. It returns ErrNoFFmpeg when the frames were kept
+// unencoded. Later calls return what the first one did.
+func (r *Recording) Stop(ctx context.Context) error {
+	r.once.Do(func() {
+		// The page may be gone already, which stops the screencast as well
+		_ = proto.PageStopScreencast{}.Call(r.page)
+		r.cancel()
+		<-r.done
+
+		if r.err = r.frames.close(time.Now()); r.err != nil {
+			return
+		}
+		if r.options.FFmpeg == "" {
+			r.err = ErrNoFFmpeg
+			return
+		}
+		r.err = Encode(ctx, r.options.FFmpeg, r.frames.dir, r.output)
+	})
+	return r.err
+}
+
+// ErrNoFFmpeg is returned when a recording's frames could not be encoded for want of ffmpeg
+var ErrNoFFmpeg = errors.New("ffmpeg not available, frames kept unencoded")
+
+// ErrNoFrames is returned for recordings during which the page never painted
+var ErrNoFrames = errors.New("no frames recorded")
+
+// Encode encodes the frames in dir into a VP8 WebM file at output and deletes them
+func Encode(ctx context.Context, ffmpeg, dir, output string) error {
+	if _, err := os.Stat(filepath.Join(dir, ListFile)); err != nil {
+		if os.IsNotExist(err) {
+			return ErrNoFrames
+		}
+		return fmt.Errorf("failed to read frame list: %w", err)
+	}
+	path, err := exec.LookPath(ffmpeg)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNoFFmpeg, err)
+	}
+	// ffmpeg runs in the frame directory, where the list names the frames
+	output, err = filepath.Abs(output)
+	if err != nil {
+		return fmt.Errorf("failed to resolve video path: %w", err)
+	}
+
+	// Frames arrive only when the page changes, so each is shown until the next one arrived
+	command := exec.CommandContext(ctx, path, "-y", "-loglevel", "error",
+		"-f", "concat", "-safe", "0", "-i", ListFile,
+		"-fps_mode", "vfr", "-c:v", "libvpx", "-b:v", "1M", "-pix_fmt", "yuv420p",
+		"-vf", "pad=ceil(iw/2)*2:ceil(ih/2)*2", output)
+	command.Dir = dir
+	if out, err := command.CombinedOutput(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return os.RemoveAll(dir)
+}
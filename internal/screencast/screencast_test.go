@@ -0,0 +1,116 @@
+package screencast
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMain lets the test binary stand in for ffmpeg: with SCREENCAST_FAKE_FFMPEG set, it checks
+// the frame list it is given and writes the list to the output file as the video
+func TestMain(m *testing.M) {
+	if os.Getenv("SCREENCAST_FAKE_FFMPEG") == "1" {
+		list, err := os.ReadFile(ListFile)
+		if err != nil {
+			os.Stderr.WriteString(err.Error())
+			os.Exit(1)
+		}
+		if err := os.WriteFile(os.Args[len(os.Args)-1], list, 0o644); err != nil {
+			os.Stderr.WriteString(err.Error())
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// TestFrameList tests that each frame is shown until the next one and the last until recording stopped
+func TestFrameList(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "run.frames")
+	frames, err := newFrameWriter(dir)
+	if err != nil {
+		t.Fatalf("failed to create frame writer: %v", err)
+	}
+	start := time.Date(2024, 6, 12, 9, 0, 0, 0, time.UTC)
+	for _, offset := range []time.Duration{0, 500 * time.Millisecond, 400 * time.Millisecond, 2 * time.Second} {
+		if err := frames.add([]byte("jpeg"), start.Add(offset)); err != nil {
+			t.Fatalf("failed to add frame: %v", err)
+		}
+	}
+	if err := frames.close(start.Add(3 * time.Second)); err != nil {
+		t.Fatalf("failed to close frames: %v", err)
+	}
+
+	list, err := os.ReadFile(filepath.Join(dir, ListFile))
+	if err != nil {
+		t.Fatalf("failed to read frame list: %v", err)
+	}
+	// A frame stamped before the one ahead of it is shown for no time instead of going back
+	expected := "file '000001.jpg'\nduration 0.500\n" +
+		"file '000002.jpg'\nduration 0.001\n" +
+		"file '000003.jpg'\nduration 1.500\n" +
+		"file '000004.jpg'\nduration 1.000\n" +
+		"file '000004.jpg'\n"
+	if string(list) != expected {
+		t.Errorf("unexpected frame list:\n%s", list)
+	}
+
+	empty, err := newFrameWriter(filepath.Join(t.TempDir(), "empty.frames"))
+	if err != nil {
+		t.Fatalf("failed to create frame writer: %v", err)
+	}
+	if err := empty.close(start); !errors.Is(err, ErrNoFrames) {
+		t.Errorf("expected ErrNoFrames without frames, got %v", err)
+	}
+	if _, err := os.Stat(empty.dir); !os.IsNotExist(err) {
+		t.Errorf("expected the empty frame directory to be removed, got %v", err)
+	}
+}
+
+// TestEncode tests that frames are handed to ffmpeg and deleted once encoded, and kept without ffmpeg
+func TestEncode(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "run.frames")
+	frames, err := newFrameWriter(dir)
+	if err != nil {
+		t.Fatalf("failed to create frame writer: %v", err)
+	}
+	if err := frames.add([]byte("jpeg"), time.Now()); err != nil {
+		t.Fatalf("failed to add frame: %v", err)
+	}
+	if err := frames.close(time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("failed to close frames: %v", err)
+	}
+	output := filepath.Join(root, "run.webm")
+
+	err = Encode(context.Background(), filepath.Join(root, "no-ffmpeg"), dir, output)
+	if !errors.Is(err, ErrNoFFmpeg) {
+		t.Fatalf("expected ErrNoFFmpeg for a missing executable, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ListFile)); err != nil {
+		t.Fatalf("expected frames kept without ffmpeg: %v", err)
+	}
+
+	t.Setenv("SCREENCAST_FAKE_FFMPEG", "1")
+	if err := Encode(context.Background(), os.Args[0], dir, output); err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	video, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("failed to read video: %v", err)
+	}
+	if !strings.HasPrefix(string(video), "file '000001.jpg'") {
+		t.Errorf("expected ffmpeg to read the frame list, got %q", video)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected frames deleted after encoding, got %v", err)
+	}
+
+	if err := Encode(context.Background(), os.Args[0], dir, output); !errors.Is(err, ErrNoFrames) {
+		t.Errorf("expected ErrNoFrames once frames are gone, got %v", err)
+	}
+}
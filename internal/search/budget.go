@@ -0,0 +1,204 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"linkedin-automation-framework/internal/browser"
+)
+
+const (
+	ResultsPerPage = 10  // Profiles LinkedIn shows per people search page
+	MaxSearchPages = 100 // LinkedIn stops paginating people searches after 100 pages
+)
+
+// resultCountPattern finds the estimate in headings such as "About 1,234 results" or "2.5K results"
+var resultCountPattern = regexp.MustCompile(`(?i)(\d[\d,.\s\x{00a0}\x{202f}]*)\s*([km])?\+?\s*results?`)
+
+// ParseResultCount extracts the result estimate from a results heading
+func ParseResultCount(text string) (int, bool) {
+	matches := resultCountPattern.FindStringSubmatch(text)
+	if matches == nil {
+		return 0, false
+	}
+	return parseCount(matches[1], matches[2])
+}
+
+// countPattern finds the first count in text such as "1,234", "12.5K" or "3 M"
+var countPattern = regexp.MustCompile(`(?i)(\d[\d,.\x{00a0}\x{202f}]*)(?:\s*([km])\b)?`)
+
+// ParseCount extracts the first count in text, such as the 1,234 in "1,234 followers" or the
+// 12.5K of an analytics tile
+func ParseCount(text string) (int, bool) {
+	matches := countPattern.FindStringSubmatch(text)
+	if matches == nil {
+		return 0, false
+	}
+	return parseCount(strings.TrimRight(matches[1], ".,"), matches[2])
+}
+
+// parseCount reads a count such as "1,234" or, with a "k" or "m" suffix, "2.5"
+func parseCount(number, suffix string) (int, bool) {
+	number = strings.TrimSpace(number)
+	if suffix = strings.ToLower(suffix); suffix != "" {
+		// Abbreviated counts use a decimal point: "2.5K"
+		value, err := strconv.ParseFloat(strings.ReplaceAll(number, ",", "."), 64)
+		if err != nil {
+			return 0, false
+		}
+		if suffix == "k" {
+			return int(value * 1000), true
+		}
+		return int(value * 1000000), true
+	}
+
+	// Full counts only use separators for thousands: "1,234", "1.234" or "1 234"
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, number)
+	count, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0, false
+	}
+	return count, true
+}
+
+// EstimateResults reads the result estimate shown above the results, or false if the page shows none
+func (sm *SearchManager) EstimateResults(ctx context.Context, page browser.PageDriver) (int, bool) {
+	if page == nil {
+		return 0, false
+	}
+	for _, selector := range sm.selectors.ResultCount {
+		elements, err := page.Elements(selector)
+		if err != nil {
+			continue
+		}
+		for _, element := range elements {
+			text, err := element.Text()
+			if err != nil {
+				continue
+			}
+			if count, ok := ParseResultCount(text); ok {
+				return count, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// Plan is the number of result pages a search run may load
+type Plan struct {
+	Estimate  int    // Results LinkedIn reports, -1 if unknown
+	Wanted    int    // Pages needed to read the requested results
+	Pages     int    // Pages the run will load
+	Remaining int    // Search quota left before the run, -1 if unlimited
+	Limited   bool   // Whether the quota cut the run short of Wanted
+	Reason    string // Why the run was limited
+}
+
+// PlanPages decides how many pages to load for maxResults profiles (0 for all) given the result
+// estimate (-1 if unknown) and the remaining search quota (-1 for unlimited). A run never plans
+// more pages than the quota allows, so it cannot start a crawl it cannot finish.
+func PlanPages(estimate, maxResults, remaining int) Plan {
+	plan := Plan{Estimate: estimate, Remaining: remaining}
+
+	wanted := MaxSearchPages
+	if maxResults > 0 {
+		wanted = pagesFor(maxResults)
+	}
+	if estimate >= 0 && pagesFor(estimate) < wanted {
+		wanted = pagesFor(estimate)
+	}
+	if wanted > MaxSearchPages {
+		wanted = MaxSearchPages
+	}
+	plan.Wanted = wanted
+	plan.Pages = wanted
+
+	if remaining >= 0 && remaining < wanted {
+		plan.Pages = remaining
+		plan.Limited = true
+		plan.Reason = fmt.Sprintf("search quota allows %d of the %d pages needed", remaining, wanted)
+	}
+	return plan
+}
+
+// pagesFor is the number of pages holding results profiles
+func pagesFor(results int) int {
+	if results <= 0 {
+		return 0
+	}
+	return (results + ResultsPerPage - 1) / ResultsPerPage
+}
+
+// RateLimiter bounds how many result pages are loaded in a sliding time window
+type RateLimiter struct {
+	maxSearches int
+	timeWindow  time.Duration
+	searches    []time.Time
+	used        int // Page loads recorded since the limiter was created
+	mutex       sync.Mutex
+}
+
+// NewRateLimiter creates a limiter allowing maxSearches page loads per timeWindow
+func NewRateLimiter(maxSearches int, timeWindow time.Duration) *RateLimiter {
+	return &RateLimiter{
+		maxSearches: maxSearches,
+		timeWindow:  timeWindow,
+	}
+}
+
+// Remaining returns how many page loads the window still allows
+func (rl *RateLimiter) Remaining() int {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	rl.prune(time.Now())
+	if remaining := rl.maxSearches - len(rl.searches); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// CanSearch checks whether another page may be loaded now
+func (rl *RateLimiter) CanSearch() bool {
+	return rl.Remaining() > 0
+}
+
+// RecordSearch records a page load
+func (rl *RateLimiter) RecordSearch() {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	rl.searches = append(rl.searches, time.Now())
+	rl.used++
+}
+
+// Used returns how many page loads were recorded since the limiter was created, including
+// those that have left the window
+func (rl *RateLimiter) Used() int {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	return rl.used
+}
+
+// prune drops page loads that have left the window
+func (rl *RateLimiter) prune(now time.Time) {
+	cutoff := now.Add(-rl.timeWindow)
+	kept := rl.searches[:0]
+	for _, searchTime := range rl.searches {
+		if searchTime.After(cutoff) {
+			kept = append(kept, searchTime)
+		}
+	}
+	rl.searches = kept
+}
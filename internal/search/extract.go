@@ -0,0 +1,174 @@
+package search
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"linkedin-automation-framework/internal/browser"
+	"linkedin-automation-framework/internal/identity"
+)
+
+// Confidence rates how reliably a profile field was extracted
+type Confidence string
+
+const (
+	ConfidenceHigh   Confidence = "high"   // Found with the field's primary selector
+	ConfidenceMedium Confidence = "medium" // Found with a fallback selector
+	ConfidenceLow    Confidence = "low"    // Derived heuristically, e.g. the company parsed from the headline
+	ConfidenceNone   Confidence = ""       // Not found
+)
+
+// FieldConfidence records how each profile field was extracted
+type FieldConfidence struct {
+	Name     Confidence
+	Title    Confidence
+	Company  Confidence
+	Location Confidence
+}
+
+// ExtractionConfig controls per-field retries while result cards finish rendering
+type ExtractionConfig struct {
+	Attempts   int           // Passes over a field's strategies before giving up, defaults to 3
+	RetryDelay time.Duration // Wait between passes, defaults to 250ms
+}
+
+// fieldStrategy is a group of selectors tried for a field, all rated with the same confidence
+type fieldStrategy struct {
+	selectors  []string
+	confidence Confidence
+}
+
+// noisePatterns match decorations LinkedIn renders inside result text: screen-reader link text,
+// connection degree badges, presence indicators and snippet labels
+var noisePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\bview\s+.*?['’]s?\s+profile`),
+	regexp.MustCompile(`(?i)\bview profile\b`),
+	regexp.MustCompile(`(?i)[·•]?\s*\b(1st|2nd|3rd\+?)\s+degree connection\b`),
+	regexp.MustCompile(`(?i)[·•]\s*(1st|2nd|3rd\+?)`),
+	regexp.MustCompile(`(?i)\bstatus is (online|offline|reachable)\b`),
+	regexp.MustCompile(`(?i)\b(current|past):\s*`),
+}
+
+// headlineCompanyPattern finds the company in headlines such as "Engineer at Acme | Speaker"
+var headlineCompanyPattern = regexp.MustCompile(`(?i)\s(?:at|@)\s+([^|·•,]+)`)
+
+// CleanText removes LinkedIn decorations such as "· 3rd" or "View profile", invisible
+// formatting characters and collapses whitespace
+func CleanText(text string) string {
+	text = identity.StripFormatting(text)
+	// LinkedIn repeats names for screen readers on a separate line; keep the first
+	if line, _, found := strings.Cut(strings.TrimSpace(text), "\n"); found {
+		text = line
+	}
+	for _, pattern := range noisePatterns {
+		text = pattern.ReplaceAllString(text, " ")
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	return strings.Trim(text, " ·•|-")
+}
+
+// CompanyFromHeadline extracts the employer from a headline, or "" if it names none
+func CompanyFromHeadline(headline string) string {
+	matches := headlineCompanyPattern.FindStringSubmatch(headline)
+	if len(matches) < 2 {
+		return ""
+	}
+	return CleanText(matches[1])
+}
+
+// SetExtractionConfig changes the per-field retry behaviour
+func (sm *SearchManager) SetExtractionConfig(config ExtractionConfig) {
+	sm.extraction = config
+}
+
+// strategies splits candidates into the primary selector and its fallbacks
+func strategies(candidates []string) []fieldStrategy {
+	if len(candidates) == 0 {
+		return nil
+	}
+	return []fieldStrategy{
+		{selectors: candidates[:1], confidence: ConfidenceHigh},
+		{selectors: candidates[1:], confidence: ConfidenceMedium},
+	}
+}
+
+// extractField tries each strategy in order, repeating the whole sequence while the field is
+// still missing, and returns the first non-empty cleaned text with its confidence
+func (sm *SearchManager) extractField(container browser.ElementDriver, fieldStrategies []fieldStrategy) (string, Confidence) {
+	attempts := sm.extraction.Attempts
+	if attempts <= 0 {
+		attempts = 3
+	}
+	delay := sm.extraction.RetryDelay
+	if delay <= 0 {
+		delay = 250 * time.Millisecond
+	}
+
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(delay)
+		}
+		for _, strategy := range fieldStrategies {
+			for _, selector := range strategy.selectors {
+				// Elements does not wait, so a missing field costs nothing until the next attempt
+				elements, err := container.Elements(selector)
+				if err != nil {
+					continue
+				}
+				for _, element := range elements {
+					text, err := element.Text()
+					if err != nil {
+						continue
+					}
+					if cleaned := CleanText(text); cleaned != "" {
+						return cleaned, strategy.confidence
+					}
+				}
+			}
+		}
+	}
+
+	return "", ConfidenceNone
+}
+
+// SetPhotos makes ExtractProfiles read each result's profile photo URL. It is off unless photos
+// are enabled, so nothing about the photo is kept otherwise.
+func (sm *SearchManager) SetPhotos(enabled bool) {
+	sm.photos = enabled
+}
+
+// extractPhoto returns the URL of the photo in a result card, or "" for members without one,
+// whose card shows a placeholder served inline or as a ghost image
+func (sm *SearchManager) extractPhoto(card browser.ElementDriver) string {
+	for _, selector := range sm.selectors.ProfilePhoto {
+		elements, err := card.Elements(selector)
+		if err != nil {
+			continue
+		}
+		for _, element := range elements {
+			// Photos below the fold are lazy-loaded from data-delayed-url
+			for _, attribute := range []string{"src", "data-delayed-url"} {
+				value, err := element.Attribute(attribute)
+				if err == nil && value != nil && strings.HasPrefix(*value, "https://") && !strings.Contains(*value, "ghost") {
+					return *value
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// resultCard returns the nearest result card around a profile link, falling back to its parent
+func (sm *SearchManager) resultCard(link browser.ElementDriver) browser.ElementDriver {
+	for _, selector := range sm.selectors.ProfileCard {
+		if card, err := link.Closest(selector); err == nil {
+			return card
+		}
+	}
+	parent, err := link.Parent()
+	if err != nil {
+		return nil
+	}
+	return parent
+}
@@ -0,0 +1,229 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// PeopleSearchURL is LinkedIn's people search results page
+const PeopleSearchURL = "https://www.linkedin.com/search/results/people/"
+
+// FacetKind names a people search filter as it appears in the search URL
+type FacetKind string
+
+const (
+	FacetGeo             FacetKind = "geoUrn"
+	FacetCurrentCompany  FacetKind = "currentCompany"
+	FacetPastCompany     FacetKind = "pastCompany"
+	FacetIndustry        FacetKind = "industry"
+	FacetSchool          FacetKind = "schoolFilter"
+	FacetServiceCategory FacetKind = "serviceCategory"
+	FacetLanguage        FacetKind = "profileLanguage"
+	FacetNetwork         FacetKind = "network"
+)
+
+// CommonFacetIDs maps well-known names to LinkedIn's facet IDs, keyed by lowercase words separated
+// by single spaces. Anything missing can be given by its numeric ID, which is visible in the URL after
+// applying the filter on linkedin.com.
+var CommonFacetIDs = map[FacetKind]map[string]string{
+	FacetGeo: {
+		"united states":          "103644278",
+		"united kingdom":         "101165590",
+		"canada":                 "101174742",
+		"india":                  "102713980",
+		"germany":                "101282230",
+		"france":                 "105015875",
+		"netherlands":            "102890719",
+		"spain":                  "105646813",
+		"ireland":                "104738515",
+		"australia":              "101452733",
+		"singapore":              "102454443",
+		"brazil":                 "106057199",
+		"san francisco bay area": "90000084",
+		"new york city area":     "90000070",
+		"london area":            "90009496",
+	},
+	FacetCurrentCompany: commonCompanies,
+	FacetPastCompany:    commonCompanies,
+	FacetIndustry: {
+		"software development":                "4",
+		"technology information and internet": "6",
+		"it services and it consulting":       "96",
+		"financial services":                  "43",
+		"banking":                             "41",
+		"hospitals and health care":           "14",
+		"business consulting and services":    "11",
+		"staffing and recruiting":             "104",
+		"advertising services":                "80",
+		"accounting":                          "47",
+	},
+	FacetSchool: {
+		"stanford university":                   "1792",
+		"harvard university":                    "1646",
+		"massachusetts institute of technology": "1503",
+		"university of california berkeley":     "2517",
+	},
+	FacetLanguage: {
+		"english":    "en",
+		"german":     "de",
+		"french":     "fr",
+		"spanish":    "es",
+		"portuguese": "pt",
+		"italian":    "it",
+		"dutch":      "nl",
+		"chinese":    "zh",
+		"japanese":   "ja",
+	},
+	FacetNetwork: {
+		"1st": "F",
+		"2nd": "S",
+		"3rd": "O",
+	},
+}
+
+// commonCompanies is shared by the current and past company facets
+var commonCompanies = map[string]string{
+	"google":     "1441",
+	"microsoft":  "1035",
+	"amazon":     "1586",
+	"apple":      "162479",
+	"meta":       "10667",
+	"ibm":        "1009",
+	"oracle":     "1028",
+	"salesforce": "3185",
+	"linkedin":   "1337",
+	"netflix":    "165158",
+}
+
+// Facets describes a people search. Facet values are names from CommonFacetIDs or raw IDs:
+// numeric for locations, companies, industries, schools and service categories, language codes
+// such as "en", and F, S or O for the network degree.
+type Facets struct {
+	Keywords          string
+	Title             string // Free-text title filter
+	Locations         []string
+	CurrentCompanies  []string
+	PastCompanies     []string
+	Industries        []string
+	Schools           []string
+	ServiceCategories []string
+	Languages         []string
+	Network           []string
+}
+
+// LookupFacetID returns the facet ID for a name or raw ID, or an error if the name is unknown
+func LookupFacetID(kind FacetKind, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("empty %s value", kind)
+	}
+	if id, ok := CommonFacetIDs[kind][facetKey(value)]; ok {
+		return id, nil
+	}
+	if isRawFacetID(kind, value) {
+		return value, nil
+	}
+	return "", fmt.Errorf("unknown %s %q: use its numeric ID from a LinkedIn search URL", kind, value)
+}
+
+// BuildSearchURL encodes keywords and facets into a people search URL
+func BuildSearchURL(facets Facets) (string, error) {
+	query := url.Values{}
+	if keywords := strings.TrimSpace(facets.Keywords); keywords != "" {
+		query.Set("keywords", keywords)
+	}
+	if title := strings.TrimSpace(facets.Title); title != "" {
+		query.Set("titleFreeText", title)
+	}
+
+	lists := []struct {
+		kind   FacetKind
+		values []string
+	}{
+		{FacetGeo, facets.Locations},
+		{FacetCurrentCompany, facets.CurrentCompanies},
+		{FacetPastCompany, facets.PastCompanies},
+		{FacetIndustry, facets.Industries},
+		{FacetSchool, facets.Schools},
+		{FacetServiceCategory, facets.ServiceCategories},
+		{FacetLanguage, facets.Languages},
+		{FacetNetwork, facets.Network},
+	}
+	faceted := false
+	for _, list := range lists {
+		if len(list.values) == 0 {
+			continue
+		}
+		ids := make([]string, 0, len(list.values))
+		for _, value := range list.values {
+			id, err := LookupFacetID(list.kind, value)
+			if err != nil {
+				return "", err
+			}
+			ids = append(ids, id)
+		}
+		// LinkedIn expects each facet as a JSON array of quoted IDs
+		encoded, err := json.Marshal(ids)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode %s: %w", list.kind, err)
+		}
+		query.Set(string(list.kind), string(encoded))
+		faceted = true
+	}
+
+	if len(query) == 0 {
+		return "", fmt.Errorf("a search needs keywords, a title or at least one facet")
+	}
+	if faceted {
+		query.Set("origin", "FACETED_SEARCH")
+	}
+	return PeopleSearchURL + "?" + query.Encode(), nil
+}
+
+// Facets converts search criteria to facets, treating the location, company and industry as names or IDs
+func (sc SearchCriteria) Facets() Facets {
+	facets := Facets{
+		Keywords: strings.Join(sc.Keywords, " "),
+		Title:    sc.Title,
+	}
+	if sc.Location != "" {
+		facets.Locations = []string{sc.Location}
+	}
+	if sc.Company != "" {
+		facets.CurrentCompanies = []string{sc.Company}
+	}
+	if sc.Industry != "" {
+		facets.Industries = []string{sc.Industry}
+	}
+	if sc.Connections != "" {
+		facets.Network = []string{sc.Connections}
+	}
+	return facets
+}
+
+// facetKey reduces a name to lowercase words separated by single spaces
+func facetKey(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// isRawFacetID reports whether value already has the shape of an ID for the facet
+func isRawFacetID(kind FacetKind, value string) bool {
+	switch kind {
+	case FacetLanguage:
+		return len(value) == 2 && strings.ToLower(value) == value && unicode.IsLetter(rune(value[0])) && unicode.IsLetter(rune(value[1]))
+	case FacetNetwork:
+		return value == "F" || value == "S" || value == "O"
+	default:
+		for _, r := range value {
+			if r < '0' || r > '9' {
+				return false
+			}
+		}
+		return true
+	}
+}
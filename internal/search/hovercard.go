@@ -0,0 +1,148 @@
+package search
+
+import (
+	"context"
+	"regexp"
+	"time"
+	"unicode/utf8"
+
+	"linkedin-automation-framework/internal/browser"
+	"linkedin-automation-framework/internal/identity"
+)
+
+// defaultHoverWait is how long to wait for a hover card to appear
+const defaultHoverWait = 1500 * time.Millisecond
+
+// hoverPoll is how often the page is checked for a hover card
+const hoverPoll = 100 * time.Millisecond
+
+// followersPattern finds the count in "1,234 followers" or "12K followers"
+var followersPattern = regexp.MustCompile(`(?i)(\d[\d,.\s\x{00a0}\x{202f}]*)\s*([km])?\+?\s*followers?`)
+
+// Hoverer moves the mouse onto an element the way a person would, e.g. stealth.StealthManager
+type Hoverer interface {
+	MoveMouse(ctx context.Context, page browser.PageDriver, target browser.ElementDriver) error
+}
+
+// HoverCard is what a result's hover card adds to the result card
+type HoverCard struct {
+	Headline  string // Full headline; result cards truncate long ones
+	Mutual    int
+	Followers int
+}
+
+// SetHoverCards makes ExtractProfiles rest the mouse on each result's name and read the hover
+// card LinkedIn shows, waiting up to wait for it (1.5s if zero). A nil hoverer turns this off.
+func (sm *SearchManager) SetHoverCards(hoverer Hoverer, wait time.Duration) {
+	sm.hoverer = hoverer
+	sm.hoverWait = wait
+}
+
+// ExtractFollowers extracts the follower count from text such as "1,234 followers" or "12K followers"
+func ExtractFollowers(text string) int {
+	matches := followersPattern.FindStringSubmatch(text)
+	if matches == nil {
+		return 0
+	}
+	count, _ := parseCount(matches[1], matches[2])
+	return count
+}
+
+// readHoverCard hovers over a result's link and reads its hover card. It returns false when no
+// card for the profile appears in time, which leaves the profile as the result card had it.
+func (sm *SearchManager) readHoverCard(ctx context.Context, page browser.PageDriver, link browser.ElementDriver, profileURL string) (HoverCard, bool) {
+	if err := sm.hoverer.MoveMouse(ctx, page, link); err != nil {
+		return HoverCard{}, false
+	}
+
+	wait := sm.hoverWait
+	if wait <= 0 {
+		wait = defaultHoverWait
+	}
+	deadline := time.Now().Add(wait)
+	for {
+		if card := sm.visibleHoverCard(page, profileURL); card != nil {
+			// The card is rendered whole, so fields are read once rather than retried
+			return HoverCard{
+				Headline:  CleanText(browser.FirstText(card, sm.selectors.HoverCardHeadline)),
+				Mutual:    ExtractMutualConnections(CleanText(browser.FirstText(card, sm.selectors.HoverCardMutual))),
+				Followers: ExtractFollowers(CleanText(browser.FirstText(card, sm.selectors.HoverCardFollowers))),
+			}, true
+		}
+		if time.Now().After(deadline) {
+			return HoverCard{}, false
+		}
+
+		timer := time.NewTimer(hoverPoll)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return HoverCard{}, false
+		case <-timer.C:
+		}
+	}
+}
+
+// visibleHoverCard returns the visible hover card for profileURL. A card still showing for the
+// previous result links to that profile instead, so it is passed over.
+func (sm *SearchManager) visibleHoverCard(page browser.PageDriver, profileURL string) browser.ElementDriver {
+	for _, selector := range sm.selectors.HoverCard {
+		cards, err := page.Elements(selector)
+		if err != nil {
+			continue
+		}
+		for _, card := range cards {
+			if visible, err := card.Visible(); err != nil || !visible {
+				continue
+			}
+			if hoverCardLinksTo(card, profileURL) {
+				return card
+			}
+		}
+	}
+	return nil
+}
+
+// inHoverCard reports whether a link is inside a hover card
+func (sm *SearchManager) inHoverCard(link browser.ElementDriver) bool {
+	for _, selector := range sm.selectors.HoverCard {
+		if _, err := link.Closest(selector); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// hoverCardLinksTo reports whether a card links to profileURL, or links to no profile at all
+func hoverCardLinksTo(card browser.ElementDriver, profileURL string) bool {
+	links, err := card.Elements("a[href*='/in/']")
+	if err != nil || len(links) == 0 {
+		return true
+	}
+	for _, link := range links {
+		href, err := link.Attribute("href")
+		if err == nil && href != nil && identity.NormalizeProfileURL(*href) == profileURL {
+			return true
+		}
+	}
+	return false
+}
+
+// applyHoverCard adds a hover card's fields to a profile. The card's headline replaces a shorter
+// one from the result card, which LinkedIn truncates.
+func applyHoverCard(profile *ProfileResult, hover HoverCard) {
+	if utf8.RuneCountInString(hover.Headline) > utf8.RuneCountInString(profile.Title) {
+		profile.Title, profile.Confidence.Title = hover.Headline, ConfidenceHigh
+		if profile.Confidence.Company == ConfidenceLow || profile.Company == "" {
+			if company := CompanyFromHeadline(profile.Title); company != "" {
+				profile.Company, profile.Confidence.Company = company, ConfidenceLow
+			}
+		}
+	}
+	if hover.Mutual > 0 {
+		profile.Mutual = hover.Mutual
+	}
+	if hover.Followers > 0 {
+		profile.Followers = hover.Followers
+	}
+}
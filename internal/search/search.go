@@ -9,14 +9,16 @@ import (
 	"strings"
 	"time"
 
-	"github.com/go-rod/rod"
+	"linkedin-automation-framework/internal/browser"
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/selectors"
 )
 
 // ProfileSearcher interface for LinkedIn profile discovery
 type ProfileSearcher interface {
 	Search(ctx context.Context, criteria SearchCriteria) ([]ProfileResult, error)
-	ExtractProfiles(ctx context.Context, page *rod.Page) ([]ProfileResult, error)
-	HandlePagination(ctx context.Context, page *rod.Page) error
+	ExtractProfiles(ctx context.Context, page browser.PageDriver) ([]ProfileResult, error)
+	HandlePagination(ctx context.Context, page browser.PageDriver) error
 }
 
 // SearchCriteria represents search parameters
@@ -52,13 +54,21 @@ type ProfileResult struct {
 	Company     string
 	Location    string
 	Mutual      int
+	Followers   int // Read from the hover card, when hover cards are enabled
+	PhotoURL    string // Read from the result card, when photos are enabled
 	Premium     bool
 	Timestamp   time.Time
+	Confidence  FieldConfidence // How reliably each field was extracted
 }
 
 // SearchManager implements ProfileSearcher interface
 type SearchManager struct {
-	storage StorageInterface
+	storage    StorageInterface
+	selectors  selectors.Set
+	extraction ExtractionConfig
+	hoverer    Hoverer       // Hovers over each result to read its hover card; nil skips hover cards
+	hoverWait  time.Duration // How long to wait for a hover card
+	photos     bool          // Reads each result's photo URL
 }
 
 // StorageInterface defines storage operations needed by search
@@ -70,10 +80,16 @@ type StorageInterface interface {
 // NewSearchManager creates a new search manager
 func NewSearchManager(storage StorageInterface) *SearchManager {
 	return &SearchManager{
-		storage: storage,
+		storage:   storage,
+		selectors: selectors.Desktop,
 	}
 }
 
+// SetSelectors switches the selectors used to read result pages, e.g. to selectors.Mobile
+func (sm *SearchManager) SetSelectors(set selectors.Set) {
+	sm.selectors = set
+}
+
 // Search performs LinkedIn profile search with given criteria
 func (sm *SearchManager) Search(ctx context.Context, criteria SearchCriteria) ([]ProfileResult, error) {
 	if err := criteria.Validate(); err != nil {
@@ -110,7 +126,7 @@ func (sm *SearchManager) Search(ctx context.Context, criteria SearchCriteria) ([
 }
 
 // ExtractProfiles extracts profile information from a search results page
-func (sm *SearchManager) ExtractProfiles(ctx context.Context, page *rod.Page) ([]ProfileResult, error) {
+func (sm *SearchManager) ExtractProfiles(ctx context.Context, page browser.PageDriver) ([]ProfileResult, error) {
 	if page == nil {
 		return nil, fmt.Errorf("page cannot be nil")
 	}
@@ -123,16 +139,9 @@ func (sm *SearchManager) ExtractProfiles(ctx context.Context, page *rod.Page) ([
 		return nil, fmt.Errorf("failed to wait for page load: %w", err)
 	}
 
+	var profileElements []browser.ElementDriver
 	// Extract profile links - LinkedIn uses various selectors for profile links
-	profileSelectors := []string{
-		"a[href*='/in/']",
-		".search-result__person a",
-		".entity-result__title-text a",
-		".app-aware-link[href*='/in/']",
-	}
-
-	var profileElements []*rod.Element
-	for _, selector := range profileSelectors {
+	for _, selector := range sm.selectors.ProfileLinks {
 		elements, err := page.Elements(selector)
 		if err == nil && len(elements) > 0 {
 			profileElements = elements
@@ -140,11 +149,24 @@ func (sm *SearchManager) ExtractProfiles(ctx context.Context, page *rod.Page) ([
 		}
 	}
 
+	hovered := make(map[string]bool) // Results link to a profile from both the photo and the name
 	for _, element := range profileElements {
+		if sm.inHoverCard(element) {
+			continue // A hover card left open links to a profile that has its own result
+		}
 		profile, err := sm.extractProfileFromElement(element)
 		if err != nil {
 			continue // Skip invalid profiles
 		}
+		if sm.hoverer != nil && !hovered[profile.URL] {
+			hovered[profile.URL] = true
+			if err := ctx.Err(); err != nil {
+				return results, err
+			}
+			if hover, ok := sm.readHoverCard(ctx, page, element, profile.URL); ok {
+				applyHoverCard(&profile, hover)
+			}
+		}
 		results = append(results, profile)
 	}
 
@@ -152,7 +174,7 @@ func (sm *SearchManager) ExtractProfiles(ctx context.Context, page *rod.Page) ([
 }
 
 // extractProfileFromElement extracts profile data from a DOM element
-func (sm *SearchManager) extractProfileFromElement(element *rod.Element) (ProfileResult, error) {
+func (sm *SearchManager) extractProfileFromElement(element browser.ElementDriver) (ProfileResult, error) {
 	profile := ProfileResult{
 		Timestamp: time.Now(),
 	}
@@ -168,55 +190,34 @@ func (sm *SearchManager) extractProfileFromElement(element *rod.Element) (Profil
 		return profile, fmt.Errorf("invalid profile URL: %s", profileURL)
 	}
 	
-	// Clean and validate URL
-	if strings.HasPrefix(profileURL, "/") {
-		profileURL = "https://linkedin.com" + profileURL
-	}
-	profile.URL = profileURL
-
-	// Extract name from the link text or nearby elements
-	name, err := element.Text()
-	if err == nil && strings.TrimSpace(name) != "" {
-		profile.Name = strings.TrimSpace(name)
-	}
-
-	// Try to extract additional information from parent elements
-	parent, err := element.Parent()
-	if err == nil && parent != nil {
-		// Look for title information
-		titleSelectors := []string{
-			".entity-result__primary-subtitle",
-			".search-result__snippets",
-			".subline-level-1",
+	// Canonicalize so the same profile has one URL everywhere
+	profile.URL = identity.NormalizeProfileURL(profileURL)
+
+	// Extract each field from the surrounding result card, trying selectors in order of reliability
+	card := sm.resultCard(element)
+	if card != nil {
+		profile.Name, profile.Confidence.Name = sm.extractField(card, strategies(sm.selectors.ProfileName))
+		profile.Title, profile.Confidence.Title = sm.extractField(card, strategies(sm.selectors.ProfileTitle))
+		profile.Company, profile.Confidence.Company = sm.extractField(card, strategies(sm.selectors.ProfileCompany))
+		profile.Location, profile.Confidence.Location = sm.extractField(card, strategies(sm.selectors.ProfileLocation))
+		if sm.photos {
+			profile.PhotoURL = sm.extractPhoto(card)
 		}
-		
-		for _, selector := range titleSelectors {
-			titleElement, err := parent.Element(selector)
-			if err == nil {
-				title, err := titleElement.Text()
-				if err == nil && strings.TrimSpace(title) != "" {
-					profile.Title = strings.TrimSpace(title)
-					break
-				}
+	}
+
+	// The link text usually holds the name, decorated with badges and screen-reader text
+	if profile.Name == "" {
+		if text, err := element.Text(); err == nil {
+			if name := CleanText(text); name != "" {
+				profile.Name, profile.Confidence.Name = name, ConfidenceLow
 			}
 		}
+	}
 
-		// Look for company information
-		companySelectors := []string{
-			".entity-result__secondary-subtitle",
-			".search-result__snippets .t-14",
-			".subline-level-2",
-		}
-		
-		for _, selector := range companySelectors {
-			companyElement, err := parent.Element(selector)
-			if err == nil {
-				company, err := companyElement.Text()
-				if err == nil && strings.TrimSpace(company) != "" {
-					profile.Company = strings.TrimSpace(company)
-					break
-				}
-			}
+	// Headlines such as "Engineer at Acme" name the employer when no company element exists
+	if profile.Company == "" {
+		if company := CompanyFromHeadline(profile.Title); company != "" {
+			profile.Company, profile.Confidence.Company = company, ConfidenceLow
 		}
 	}
 
@@ -224,21 +225,14 @@ func (sm *SearchManager) extractProfileFromElement(element *rod.Element) (Profil
 }
 
 // HandlePagination handles automatic pagination through search results
-func (sm *SearchManager) HandlePagination(ctx context.Context, page *rod.Page) error {
+func (sm *SearchManager) HandlePagination(ctx context.Context, page browser.PageDriver) error {
 	if page == nil {
 		return fmt.Errorf("page cannot be nil")
 	}
 
+	var nextButton browser.ElementDriver
 	// Look for pagination elements
-	paginationSelectors := []string{
-		"button[aria-label='Next']",
-		".artdeco-pagination__button--next",
-		"a[aria-label='Next']",
-		".pv-s-profile-actions--next",
-	}
-
-	var nextButton *rod.Element
-	for _, selector := range paginationSelectors {
+	for _, selector := range sm.selectors.NextPage {
 		element, err := page.Element(selector)
 		if err == nil {
 			nextButton = element
@@ -263,7 +257,9 @@ func (sm *SearchManager) HandlePagination(ctx context.Context, page *rod.Page) e
 	}
 
 	// Click the next button
-	nextButton.MustClick()
+	if err := nextButton.Click(); err != nil {
+		return fmt.Errorf("failed to click next button: %w", err)
+	}
 
 	// Wait for the new page to load
 	err = page.WaitLoad()
@@ -274,43 +270,32 @@ func (sm *SearchManager) HandlePagination(ctx context.Context, page *rod.Page) e
 	return nil
 }
 
-// deduplicateResults removes duplicate profiles based on URL
+// deduplicateResults removes profiles already stored or repeated within the new results. A profile
+// is a duplicate when its URL is a variant of a seen one (trailing slash, query string, regional
+// subdomain or locale suffix) or when its normalized name and company match a seen profile.
 func (sm *SearchManager) deduplicateResults(newResults []ProfileResult) ([]ProfileResult, error) {
+	seen := identity.NewDeduper()
+
 	// Get existing results from storage
 	existingResults, err := sm.storage.GetSearchResults()
 	if err != nil {
 		// If we can't get existing results, just deduplicate within new results
-		return sm.deduplicateWithinResults(newResults), nil
+		return sm.deduplicateWithinResults(seen, newResults), nil
 	}
 
-	// Create a map of existing URLs
-	existingURLs := make(map[string]bool)
 	for _, result := range existingResults {
-		existingURLs[result.URL] = true
-	}
-
-	// First deduplicate within new results, then filter against existing
-	deduplicatedNew := sm.deduplicateWithinResults(newResults)
-	
-	// Filter out duplicates from existing results
-	var deduplicatedResults []ProfileResult
-	for _, result := range deduplicatedNew {
-		if !existingURLs[result.URL] {
-			deduplicatedResults = append(deduplicatedResults, result)
-		}
+		seen.Add(result.URL, result.Name, result.Company)
 	}
 
-	return deduplicatedResults, nil
+	return sm.deduplicateWithinResults(seen, newResults), nil
 }
 
-// deduplicateWithinResults removes duplicates within a single slice of results
-func (sm *SearchManager) deduplicateWithinResults(results []ProfileResult) []ProfileResult {
-	seenURLs := make(map[string]bool)
+// deduplicateWithinResults keeps the first of each person not already in seen
+func (sm *SearchManager) deduplicateWithinResults(seen *identity.Deduper, results []ProfileResult) []ProfileResult {
 	var deduplicatedResults []ProfileResult
 	
 	for _, result := range results {
-		if !seenURLs[result.URL] {
-			seenURLs[result.URL] = true
+		if seen.Add(result.URL, result.Name, result.Company) {
 			deduplicatedResults = append(deduplicatedResults, result)
 		}
 	}
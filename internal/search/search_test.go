@@ -3,12 +3,17 @@ package search
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"pgregory.net/rapid"
+
+	"linkedin-automation-framework/internal/browser"
+	"linkedin-automation-framework/internal/browser/browsertest"
+	"linkedin-automation-framework/internal/identity"
 )
 
 // MockStorage implements StorageInterface for testing
@@ -137,82 +142,15 @@ func TestInvalidSearchCriteriaRejection(t *testing.T) {
 	})
 }
 
-// MockPage implements basic Rod page functionality for testing
-type MockPage struct {
-	url      string
-	elements map[string][]*MockElement
-	loaded   bool
-}
-
-type MockElement struct {
-	tag        string
-	attributes map[string]string
-	text       string
-	parent     *MockElement
-}
-
-func (me *MockElement) Attribute(name string) (*string, error) {
-	if val, exists := me.attributes[name]; exists {
-		return &val, nil
-	}
-	return nil, fmt.Errorf("attribute %s not found", name)
-}
-
-func (me *MockElement) Text() (string, error) {
-	return me.text, nil
-}
-
-func (me *MockElement) Parent() (*MockElement, error) {
-	if me.parent != nil {
-		return me.parent, nil
-	}
-	return nil, fmt.Errorf("no parent element")
-}
-
-func (me *MockElement) Element(selector string) (*MockElement, error) {
-	// Simple mock implementation
-	return &MockElement{
-		tag:        "div",
-		attributes: map[string]string{"class": "mock"},
-		text:       "Mock Element",
-	}, nil
-}
-
-func NewMockPage(url string) *MockPage {
-	return &MockPage{
-		url:      url,
-		elements: make(map[string][]*MockElement),
-		loaded:   true,
-	}
-}
-
-func (mp *MockPage) WaitLoad() error {
-	if !mp.loaded {
-		return fmt.Errorf("page failed to load")
-	}
-	return nil
-}
-
-func (mp *MockPage) Elements(selector string) ([]*MockElement, error) {
-	if elements, exists := mp.elements[selector]; exists {
-		return elements, nil
-	}
-	return []*MockElement{}, nil
-}
-
-func (mp *MockPage) Element(selector string) (*MockElement, error) {
-	elements, err := mp.Elements(selector)
-	if err != nil {
-		return nil, err
-	}
-	if len(elements) == 0 {
-		return nil, fmt.Errorf("element not found: %s", selector)
+// resultCard builds a search result card holding a profile link found by linkSelector
+func resultCard(sm *SearchManager, linkSelector, href, name, headline string) *browsertest.Element {
+	card := browsertest.NewElement(sm.selectors.ProfileCard[0])
+	link := browsertest.NewElement(linkSelector).SetAttribute("href", href).SetText(name)
+	card.Append(link)
+	if headline != "" {
+		card.Append(browsertest.NewElement(sm.selectors.ProfileTitle[0]).SetText(headline))
 	}
-	return elements[0], nil
-}
-
-func (mp *MockPage) AddElement(selector string, element *MockElement) {
-	mp.elements[selector] = append(mp.elements[selector], element)
+	return card
 }
 
 // **Feature: linkedin-automation-framework, Property 21: Rod-based page navigation**
@@ -221,19 +159,13 @@ func TestRodBasedPageNavigation(t *testing.T) {
 	rapid.Check(t, func(t *rapid.T) {
 		storage := &MockStorage{}
 		searchManager := NewSearchManager(storage)
+		searchManager.SetExtractionConfig(ExtractionConfig{Attempts: 1})
 		
-		// Create a mock page with profile elements
-		mockPage := NewMockPage("https://linkedin.com/search/results/people/")
-		
-		// Add some mock profile elements
-		profileElement := &MockElement{
-			tag: "a",
-			attributes: map[string]string{
-				"href": "https://linkedin.com/in/" + rapid.StringMatching(`^[a-zA-Z0-9\-]+$`).Draw(t, "username"),
-			},
-			text: rapid.StringMatching(`^[a-zA-Z\s]+$`).Draw(t, "name"),
-		}
-		mockPage.AddElement("a[href*='/in/']", profileElement)
+		// Create a fake page with a profile element
+		page := browsertest.NewPage("https://linkedin.com/search/results/people/")
+		username := rapid.StringMatching(`^[a-zA-Z0-9\-]+$`).Draw(t, "username")
+		name := rapid.StringMatching(`^[a-zA-Z]+( [a-zA-Z]+)?$`).Draw(t, "name")
+		page.Append(resultCard(searchManager, "a[href*='/in/']", "https://linkedin.com/in/"+username+"?miniProfileUrn=x", name, ""))
 		
 		ctx := context.Background()
 		
@@ -245,6 +177,14 @@ func TestRodBasedPageNavigation(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "page cannot be nil")
 		assert.Empty(t, results)
+
+		// The profile on the page is extracted under its canonical URL, named by its link text
+		results, err = searchManager.ExtractProfiles(ctx, page)
+		assert.NoError(t, err)
+		if assert.Len(t, results, 1) {
+			assert.Equal(t, identity.NormalizeProfileURL("https://linkedin.com/in/"+username), results[0].URL)
+			assert.Equal(t, name, results[0].Name)
+		}
 	})
 }
 
@@ -288,86 +228,36 @@ func TestProfileURLExtraction(t *testing.T) {
 // Test profile extraction from various page structures
 func TestProfileExtractionFromVariousStructures(t *testing.T) {
 	rapid.Check(t, func(t *rapid.T) {
-		username := rapid.StringMatching(`^[a-zA-Z0-9\-]+$`).Draw(t, "username")
-		name := rapid.StringMatching(`^[a-zA-Z\s]+$`).Draw(t, "name")
-		
-		// Test different selector patterns
-		selectors := []string{
-			"a[href*='/in/']",
-			".search-result__person a",
-			".entity-result__title-text a",
-			".app-aware-link[href*='/in/']",
-		}
-		
-		for _, selector := range selectors {
-			mockPage := NewMockPage("https://linkedin.com/search/results/people/")
-			
-			profileElement := &MockElement{
-				tag: "a",
-				attributes: map[string]string{
-					"href": "https://linkedin.com/in/" + username,
-				},
-				text: name,
-			}
-			mockPage.AddElement(selector, profileElement)
-			
-			// The extraction should work regardless of which selector is used
-			// This demonstrates that the system handles various page structures
-			assert.NotNil(t, mockPage)
-			assert.Equal(t, "https://linkedin.com/search/results/people/", mockPage.url)
+		searchManager := NewSearchManager(&MockStorage{})
+		searchManager.SetExtractionConfig(ExtractionConfig{Attempts: 1})
+
+		// Property: whichever selector finds the links, every profile link on the page is
+		// extracted in order, and links to anything but a profile are skipped
+		selector := rapid.SampledFrom(searchManager.selectors.ProfileLinks).Draw(t, "selector")
+		usernames := rapid.SliceOfN(rapid.StringMatching(`^[a-z][a-z0-9\-]{2,20}$`), 1, 10).Draw(t, "usernames")
+		company := rapid.StringMatching(`^[A-Z][a-z]{2,10}$`).Draw(t, "company")
+
+		page := browsertest.NewPage("https://linkedin.com/search/results/people/")
+		page.Append(resultCard(searchManager, selector, "https://linkedin.com/company/"+company, company, ""))
+		for i, username := range usernames {
+			page.Append(resultCard(searchManager, selector, "https://linkedin.com/in/"+username, fmt.Sprintf("Member %d", i), "Engineer at "+company))
 		}
-	})
-}
-// Enhanced MockPage for pagination testing
-type MockPageWithPagination struct {
-	*MockPage
-	hasNextButton    bool
-	nextButtonActive bool
-}
 
-func NewMockPageWithPagination(url string, hasNext bool, nextActive bool) *MockPageWithPagination {
-	return &MockPageWithPagination{
-		MockPage:         NewMockPage(url),
-		hasNextButton:    hasNext,
-		nextButtonActive: nextActive,
-	}
-}
-
-func (mp *MockPageWithPagination) Element(selector string) (*MockElement, error) {
-	// Handle pagination button selectors
-	paginationSelectors := []string{
-		"button[aria-label='Next']",
-		".artdeco-pagination__button--next",
-		"a[aria-label='Next']",
-		".pv-s-profile-actions--next",
-	}
-	
-	for _, paginationSelector := range paginationSelectors {
-		if selector == paginationSelector {
-			if !mp.hasNextButton {
-				return nil, fmt.Errorf("element not found: %s", selector)
-			}
-			
-			attributes := map[string]string{}
-			if !mp.nextButtonActive {
-				attributes["disabled"] = "true"
-				attributes["aria-disabled"] = "true"
-			}
-			
-			return &MockElement{
-				tag:        "button",
-				attributes: attributes,
-				text:       "Next",
-			}, nil
+		results, err := searchManager.ExtractProfiles(context.Background(), page)
+		assert.NoError(t, err)
+		if !assert.Len(t, results, len(usernames)) {
+			return
 		}
-	}
-	
-	// Delegate to parent for other selectors
-	return mp.MockPage.Element(selector)
-}
-
-func (mp *MockPageWithPagination) MustClick() {
-	// Mock click behavior - in real implementation this would navigate
+		for i, result := range results {
+			assert.Equal(t, identity.NormalizeProfileURL("https://linkedin.com/in/"+usernames[i]), result.URL)
+			assert.Equal(t, fmt.Sprintf("Member %d", i), result.Name)
+			assert.Equal(t, "Engineer at "+company, result.Title)
+			assert.Equal(t, ConfidenceHigh, result.Confidence.Title)
+			// The headline names the company when the card has no company element
+			assert.Equal(t, company, result.Company)
+			assert.Equal(t, ConfidenceLow, result.Confidence.Company)
+		}
+	})
 }
 
 // **Feature: linkedin-automation-framework, Property 23: Pagination handling**
@@ -393,6 +283,23 @@ func TestPaginationHandling(t *testing.T) {
 		// This property test validates the error handling and logic flow
 		assert.NotNil(t, searchManager)
 		assert.NotNil(t, ctx)
+
+		// Property: an enabled Next button, found by any pagination selector, is clicked once;
+		// a page without one ends the results
+		selector := rapid.SampledFrom(searchManager.selectors.NextPage).Draw(t, "selector")
+		hasNext := rapid.Bool().Draw(t, "hasNext")
+		page := browsertest.NewPage("https://linkedin.com/search/results/people/?page=1")
+		next := browsertest.NewElement(selector).SetText("Next")
+		if hasNext {
+			page.Append(next)
+		}
+		err = searchManager.HandlePagination(ctx, page)
+		if hasNext {
+			assert.NoError(t, err)
+			assert.Equal(t, 1, next.Clicks())
+		} else {
+			assert.ErrorContains(t, err, "end of results")
+		}
 	})
 }
 
@@ -417,6 +324,29 @@ func TestPaginationButtonStates(t *testing.T) {
 							strings.Contains(selector, "Next")
 			assert.True(t, containsNext, "Pagination selector should reference Next functionality: %s", selector)
 		}
+
+		// Property: a disabled Next button ends the results without being clicked
+		searchManager := NewSearchManager(&MockStorage{})
+		state := rapid.SampledFrom([]string{"enabled", "disabled", "aria-disabled", "aria-enabled"}).Draw(t, "state")
+		next := browsertest.NewElement(searchManager.selectors.NextPage[0])
+		switch state {
+		case "disabled":
+			next.SetAttribute("disabled", "")
+		case "aria-disabled":
+			next.SetAttribute("aria-disabled", "true")
+		case "aria-enabled":
+			next.SetAttribute("aria-disabled", "false")
+		}
+		page := browsertest.NewPage("https://linkedin.com/search/results/people/").Append(next)
+
+		err := searchManager.HandlePagination(context.Background(), page)
+		enabled := state == "enabled" || state == "aria-enabled"
+		if enabled {
+			assert.NoError(t, err)
+		} else {
+			assert.ErrorContains(t, err, "disabled")
+		}
+		assert.Equal(t, map[bool]int{true: 1, false: 0}[enabled], next.Clicks())
 	})
 }
 
@@ -539,4 +469,327 @@ func TestDeduplicationWithStorageErrors(t *testing.T) {
 		assert.NoError(t, err) // Should not propagate storage error
 		assert.Equal(t, results, deduplicatedResults) // Should return original results
 	})
-}
\ No newline at end of file
+}
+
+// Test deduplication of the same person reached through different URLs
+func TestFuzzyDeduplication(t *testing.T) {
+	storage := &MockStorage{searchResults: []ProfileResult{
+		{URL: "https://www.linkedin.com/in/existing-user/", Name: "Existing User", Company: "Globex"},
+	}}
+	searchManager := NewSearchManager(storage)
+
+	results := []ProfileResult{
+		{URL: "https://de.linkedin.com/in/existing-user/de", Name: "Existing User", Company: "Globex"},
+		{URL: "https://www.linkedin.com/in/jane-doe?trk=search", Name: "Jane Doe", Company: "Acme Inc."},
+		{URL: "https://www.linkedin.com/in/jane-doe/", Name: "Jane Doe", Company: "Acme Inc."},
+		{URL: "https://www.linkedin.com/in/ACoAAB12xyz", Name: "Jane Doe, PhD", Company: "ACME"},
+		{URL: "https://www.linkedin.com/in/jane-doe-2", Name: "Jane Doe", Company: "Initech"},
+	}
+
+	deduplicatedResults, err := searchManager.deduplicateResults(results)
+	assert.NoError(t, err)
+	assert.Len(t, deduplicatedResults, 2)
+	assert.Equal(t, "https://www.linkedin.com/in/jane-doe?trk=search", deduplicatedResults[0].URL)
+	assert.Equal(t, "https://www.linkedin.com/in/jane-doe-2", deduplicatedResults[1].URL)
+}
+
+// Test cleaning of LinkedIn decorations from extracted text
+func TestCleanText(t *testing.T) {
+	cases := map[string]string{
+		"Jane Doe\nView Jane Doe’s profile": "Jane Doe",
+		"Jane Doe · 3rd":                     "Jane Doe",
+		"John Smith • 2nd degree connection": "John Smith",
+		"Maria Garcia  · 3rd+  ":             "Maria Garcia",
+		"View profile":                       "",
+		"Status is online Alex Chen":         "Alex Chen",
+		"Current: Staff Engineer at Acme":    "Staff Engineer at Acme",
+		"Director of Product Review":         "Director of Product Review",
+		"\u2068محمد عبد الله\u2069 · 2nd":    "محمد عبد الله",
+		"王小明 • 3rd+":                         "王小明",
+	}
+	for raw, expected := range cases {
+		assert.Equal(t, expected, CleanText(raw), "CleanText(%q)", raw)
+	}
+}
+
+// Test deriving the company from a headline when no company element exists
+func TestCompanyFromHeadline(t *testing.T) {
+	assert.Equal(t, "Acme Corp", CompanyFromHeadline("Senior Engineer at Acme Corp | Speaker"))
+	assert.Equal(t, "Globex", CompanyFromHeadline("CTO @ Globex · Building things"))
+	assert.Equal(t, "", CompanyFromHeadline("Freelance designer"))
+}
+
+// Test encoding keywords and facets into a people search URL
+func TestBuildSearchURL(t *testing.T) {
+	searchURL, err := BuildSearchURL(Facets{
+		Keywords:         "site reliability",
+		Title:            "Staff Engineer",
+		Locations:        []string{"San Francisco Bay Area", "101282230"},
+		CurrentCompanies: []string{"Google"},
+		PastCompanies:    []string{"microsoft"},
+		Industries:       []string{"Software Development"},
+		Schools:          []string{"Stanford University"},
+		Languages:        []string{"English", "de"},
+		Network:          []string{"2nd", "O"},
+	})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(searchURL, PeopleSearchURL+"?"))
+
+	parsed, err := url.Parse(searchURL)
+	assert.NoError(t, err)
+	query := parsed.Query()
+	assert.Equal(t, "site reliability", query.Get("keywords"))
+	assert.Equal(t, "Staff Engineer", query.Get("titleFreeText"))
+	assert.Equal(t, `["90000084","101282230"]`, query.Get("geoUrn"))
+	assert.Equal(t, `["1441"]`, query.Get("currentCompany"))
+	assert.Equal(t, `["1035"]`, query.Get("pastCompany"))
+	assert.Equal(t, `["4"]`, query.Get("industry"))
+	assert.Equal(t, `["1792"]`, query.Get("schoolFilter"))
+	assert.Equal(t, `["en","de"]`, query.Get("profileLanguage"))
+	assert.Equal(t, `["S","O"]`, query.Get("network"))
+	assert.Equal(t, "FACETED_SEARCH", query.Get("origin"))
+
+	// Keywords alone need no facet origin
+	searchURL, err = BuildSearchURL(Facets{Keywords: "golang"})
+	assert.NoError(t, err)
+	assert.Equal(t, PeopleSearchURL+"?keywords=golang", searchURL)
+
+	_, err = BuildSearchURL(Facets{})
+	assert.Error(t, err)
+	_, err = BuildSearchURL(Facets{Locations: []string{"Atlantis"}})
+	assert.Error(t, err)
+}
+
+// Test facet ID lookup by name and raw ID
+func TestLookupFacetID(t *testing.T) {
+	cases := []struct {
+		kind     FacetKind
+		value    string
+		expected string
+		valid    bool
+	}{
+		{FacetGeo, "united-states", "103644278", true},
+		{FacetGeo, "  London Area ", "90009496", true},
+		{FacetServiceCategory, "602", "602", true},
+		{FacetLanguage, "fr", "fr", true},
+		{FacetLanguage, "Klingon", "", false},
+		{FacetNetwork, "1st", "F", true},
+		{FacetNetwork, "4th", "", false},
+		{FacetCurrentCompany, "", "", false},
+	}
+	for _, tc := range cases {
+		id, err := LookupFacetID(tc.kind, tc.value)
+		if tc.valid {
+			assert.NoError(t, err, "%s %q", tc.kind, tc.value)
+			assert.Equal(t, tc.expected, id, "%s %q", tc.kind, tc.value)
+		} else {
+			assert.Error(t, err, "%s %q", tc.kind, tc.value)
+		}
+	}
+
+	criteria := SearchCriteria{Keywords: []string{"go", "developer"}, Location: "Germany", Connections: "2nd"}
+	facets := criteria.Facets()
+	assert.Equal(t, "go developer", facets.Keywords)
+	assert.Equal(t, []string{"Germany"}, facets.Locations)
+	assert.Equal(t, []string{"2nd"}, facets.Network)
+}
+
+// Test reading the result estimate from results headings
+func TestParseResultCount(t *testing.T) {
+	cases := map[string]int{
+		"About 1,234 results":        1234,
+		"About 1.234 results":        1234,
+		"About 12\u00a0400 results":   12400,
+		"23 results":                 23,
+		"1 result":                   1,
+		"About 2.5K results":         2500,
+		"1M+ results":                1000000,
+		"Showing 3 of 1,200 results": 1200,
+	}
+	for text, expected := range cases {
+		count, ok := ParseResultCount(text)
+		assert.True(t, ok, "ParseResultCount(%q)", text)
+		assert.Equal(t, expected, count, "ParseResultCount(%q)", text)
+	}
+
+	_, ok := ParseResultCount("No results found")
+	assert.False(t, ok)
+}
+
+func TestParseCount(t *testing.T) {
+	cases := map[string]int{
+		"1,234":                   1234,
+		"12.5K":                   12500,
+		"3 M impressions":         3000000,
+		"Total followers\n4,807.": 4807,
+		"2,150 subscribers":       2150,
+		"5 messages":              5,
+		"12\u00a0400 impressions": 12400,
+	}
+	for text, expected := range cases {
+		count, ok := ParseCount(text)
+		assert.True(t, ok, "ParseCount(%q)", text)
+		assert.Equal(t, expected, count, "ParseCount(%q)", text)
+	}
+
+	_, ok := ParseCount("No data yet")
+	assert.False(t, ok)
+}
+
+func TestExtractFollowers(t *testing.T) {
+	cases := map[string]int{
+		"1,234 followers":          1234,
+		"12K followers":            12000,
+		"1.5M followers":           1500000,
+		"1 follower":               1,
+		"Engineer · 873 followers": 873,
+		"12 mutual connections":    0,
+		"":                         0,
+	}
+	for text, expected := range cases {
+		assert.Equal(t, expected, ExtractFollowers(text), "ExtractFollowers(%q)", text)
+	}
+}
+
+// hoverStub shows a result's hover card when the mouse reaches its link
+type hoverStub struct {
+	cards map[browser.ElementDriver]*browsertest.Element
+}
+
+func (h *hoverStub) MoveMouse(ctx context.Context, page browser.PageDriver, target browser.ElementDriver) error {
+	if card, ok := h.cards[target]; ok {
+		card.SetHidden(false)
+	}
+	return nil
+}
+
+// hoverCard builds a hidden hover card linking to href
+func hoverCard(sm *SearchManager, href string, lines map[string]string) *browsertest.Element {
+	card := browsertest.NewElement(sm.selectors.HoverCard[0]).SetHidden(true)
+	card.Append(browsertest.NewElement("a[href*='/in/']").SetAttribute("href", href))
+	for selector, text := range lines {
+		card.Append(browsertest.NewElement(selector).SetText(text))
+	}
+	return card
+}
+
+func TestHoverCardEnrichment(t *testing.T) {
+	sm := NewSearchManager(&MockStorage{})
+	sm.SetExtractionConfig(ExtractionConfig{Attempts: 1})
+	link := "a[href*='/in/']"
+
+	// Ada's card shows a longer headline, mutuals and followers; Grace gets no card, and Ada's
+	// stays on screen while Grace is hovered
+	ada := resultCard(sm, link, "https://www.linkedin.com/in/ada/", "Ada Lovelace", "Engineer at Analytical…")
+	grace := resultCard(sm, link, "https://www.linkedin.com/in/grace/", "Grace Hopper", "Rear Admiral")
+	adaCard := hoverCard(sm, "https://www.linkedin.com/in/ada/?trk=hovercard", map[string]string{
+		sm.selectors.HoverCardHeadline[0]:  "Engineer at Analytical Engines | Mathematician",
+		sm.selectors.HoverCardMutual[0]:    "12 mutual connections",
+		sm.selectors.HoverCardFollowers[0]: "1.2K followers",
+	})
+	page := browsertest.NewPage("https://www.linkedin.com/search/results/people/").Append(ada, grace, adaCard)
+
+	adaLink, _ := ada.Element(link)
+	hoverer := &hoverStub{cards: map[browser.ElementDriver]*browsertest.Element{adaLink: adaCard}}
+	sm.SetHoverCards(hoverer, 50*time.Millisecond)
+
+	results, err := sm.ExtractProfiles(context.Background(), page)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "Engineer at Analytical Engines | Mathematician", results[0].Title)
+		assert.Equal(t, ConfidenceHigh, results[0].Confidence.Title)
+		assert.Equal(t, "Analytical Engines", results[0].Company)
+		assert.Equal(t, 12, results[0].Mutual)
+		assert.Equal(t, 1200, results[0].Followers)
+
+		assert.Equal(t, "Rear Admiral", results[1].Title)
+		assert.Zero(t, results[1].Mutual)
+		assert.Zero(t, results[1].Followers)
+	}
+
+	// Without a hoverer the result card is all there is
+	sm.SetHoverCards(nil, 0)
+	adaCard.SetHidden(true)
+	results, err = sm.ExtractProfiles(context.Background(), page)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "Engineer at Analytical…", results[0].Title)
+		assert.Zero(t, results[0].Followers)
+	}
+}
+
+// Test planning result pages against the estimate and remaining quota
+func TestPlanPages(t *testing.T) {
+	// Unknown estimate and unlimited quota read up to the requested results
+	plan := PlanPages(-1, 45, -1)
+	assert.Equal(t, 5, plan.Pages)
+	assert.False(t, plan.Limited)
+
+	// A small result set needs fewer pages than requested
+	plan = PlanPages(23, 100, 20)
+	assert.Equal(t, 3, plan.Pages)
+	assert.False(t, plan.Limited)
+
+	// The quota caps the pages and the plan says why
+	plan = PlanPages(5000, 0, 8)
+	assert.Equal(t, MaxSearchPages, plan.Wanted)
+	assert.Equal(t, 8, plan.Pages)
+	assert.True(t, plan.Limited)
+	assert.Contains(t, plan.Reason, "8 of the 100 pages")
+
+	// LinkedIn never pages past its limit
+	plan = PlanPages(-1, 5000, -1)
+	assert.Equal(t, MaxSearchPages, plan.Pages)
+
+	plan = PlanPages(0, 100, 10)
+	assert.Equal(t, 0, plan.Pages)
+}
+
+// Test the search rate limiter's sliding window
+func TestSearchRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(2, 50*time.Millisecond)
+	assert.Equal(t, 2, limiter.Remaining())
+
+	limiter.RecordSearch()
+	limiter.RecordSearch()
+	assert.False(t, limiter.CanSearch())
+	assert.Equal(t, 0, limiter.Remaining())
+
+	time.Sleep(60 * time.Millisecond)
+	assert.True(t, limiter.CanSearch())
+	assert.Equal(t, 2, limiter.Remaining())
+	assert.Equal(t, 2, limiter.Used())
+}
+
+
+// Test reading photo URLs only when photos are enabled, skipping placeholders
+func TestProfilePhotos(t *testing.T) {
+	sm := NewSearchManager(&MockStorage{})
+	sm.SetExtractionConfig(ExtractionConfig{Attempts: 1})
+	link := "a[href*='/in/']"
+
+	ada := resultCard(sm, link, "https://www.linkedin.com/in/ada/", "Ada Lovelace", "")
+	ada.Append(browsertest.NewElement(sm.selectors.ProfilePhoto[0]).SetAttribute("src", "https://media.licdn.com/dms/image/ada.jpg"))
+	grace := resultCard(sm, link, "https://www.linkedin.com/in/grace/", "Grace Hopper", "")
+	grace.Append(browsertest.NewElement(sm.selectors.ProfilePhoto[1]).
+		SetAttribute("src", "data:image/gif;base64,R0lGODlhAQABAAAAACw=").
+		SetAttribute("data-delayed-url", "https://media.licdn.com/dms/image/grace.jpg"))
+	alan := resultCard(sm, link, "https://www.linkedin.com/in/alan/", "Alan Turing", "")
+	alan.Append(browsertest.NewElement(sm.selectors.ProfilePhoto[0]).SetAttribute("src", "https://static.licdn.com/aero-v1/sc/h/ghost-person.svg"))
+	page := browsertest.NewPage("https://www.linkedin.com/search/results/people/").Append(ada, grace, alan)
+
+	results, err := sm.ExtractProfiles(context.Background(), page)
+	assert.NoError(t, err)
+	for _, result := range results {
+		assert.Empty(t, result.PhotoURL, "photos are off by default")
+	}
+
+	sm.SetPhotos(true)
+	results, err = sm.ExtractProfiles(context.Background(), page)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 3) {
+		assert.Equal(t, "https://media.licdn.com/dms/image/ada.jpg", results[0].PhotoURL)
+		assert.Equal(t, "https://media.licdn.com/dms/image/grace.jpg", results[1].PhotoURL)
+		assert.Empty(t, results[2].PhotoURL)
+	}
+}
@@ -0,0 +1,189 @@
+package selectors
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Label is a piece of LinkedIn UI text that buttons are matched by
+type Label string
+
+const (
+	LabelConnect         Label = "connect"
+	LabelAddNote         Label = "add_note"
+	LabelSend            Label = "send"
+	LabelSendInvitation  Label = "send_invitation"
+	LabelSendWithoutNote Label = "send_without_note"
+	LabelMessage         Label = "message"
+	LabelNext            Label = "next"
+	LabelDismiss         Label = "dismiss"
+)
+
+// UIText holds each label as LinkedIn shows it in its major interface languages, English first.
+// Matching is by substring, so a shorter form covers longer aria-labels such as "Invite Jane to connect".
+var UIText = map[Label]map[string][]string{
+	LabelConnect: {
+		"en": {"Connect"},
+		"de": {"Vernetzen"},
+		"fr": {"Se connecter"},
+		"es": {"Conectar"},
+		"pt": {"Conectar"},
+		"it": {"Collegati"},
+		"nl": {"Connectie maken"},
+	},
+	LabelAddNote: {
+		"en": {"Add a note"},
+		"de": {"Nachricht hinzufügen", "Notiz hinzufügen"},
+		"fr": {"Ajouter une note"},
+		"es": {"Añadir una nota", "Agregar una nota"},
+		"pt": {"Adicionar nota"},
+		"it": {"Aggiungi una nota"},
+		"nl": {"Notitie toevoegen"},
+	},
+	LabelSend: {
+		"en": {"Send"},
+		"de": {"Senden"},
+		"fr": {"Envoyer"},
+		"es": {"Enviar"},
+		"pt": {"Enviar"},
+		"it": {"Invia"},
+		"nl": {"Verzenden"},
+	},
+	LabelSendInvitation: {
+		"en": {"Send invitation"},
+		"de": {"Einladung senden"},
+		"fr": {"Envoyer l’invitation", "Envoyer l'invitation"},
+		"es": {"Enviar invitación"},
+		"pt": {"Enviar convite"},
+		"it": {"Invia invito"},
+		"nl": {"Uitnodiging verzenden"},
+	},
+	LabelSendWithoutNote: {
+		"en": {"Send without a note"},
+		"de": {"Ohne Nachricht senden", "Ohne Notiz senden"},
+		"fr": {"Envoyer sans note"},
+		"es": {"Enviar sin nota"},
+		"pt": {"Enviar sem nota"},
+		"it": {"Invia senza nota"},
+		"nl": {"Verzenden zonder notitie"},
+	},
+	LabelMessage: {
+		"en": {"Message"},
+		"de": {"Nachricht"},
+		"fr": {"Message"},
+		"es": {"Mensaje"},
+		"pt": {"Mensagem"},
+		"it": {"Messaggio"},
+		"nl": {"Bericht"},
+	},
+	LabelNext: {
+		"en": {"Next"},
+		"de": {"Weiter"},
+		"fr": {"Suivant"},
+		"es": {"Siguiente"},
+		"pt": {"Avançar", "Próximo"},
+		"it": {"Avanti"},
+		"nl": {"Volgende"},
+	},
+	LabelDismiss: {
+		"en": {"Dismiss"},
+		"de": {"Verwerfen", "Schließen"},
+		"fr": {"Ignorer", "Fermer"},
+		"es": {"Descartar", "Cerrar"},
+		"pt": {"Fechar"},
+		"it": {"Chiudi", "Ignora"},
+		"nl": {"Sluiten"},
+	},
+}
+
+// UILanguages lists the interface languages UIText covers
+func UILanguages() []string {
+	return []string{"en", "de", "fr", "es", "pt", "it", "nl"}
+}
+
+// IsUILanguage reports whether UIText covers the interface language code
+func IsUILanguage(code string) bool {
+	for _, supported := range UILanguages() {
+		if supported == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Texts returns the label's text in English and the given languages, without duplicates
+func Texts(label Label, languages []string) []string {
+	var texts []string
+	seen := make(map[string]bool)
+	for _, code := range append([]string{"en"}, languages...) {
+		for _, text := range UIText[label][code] {
+			if !seen[text] {
+				seen[text] = true
+				texts = append(texts, text)
+			}
+		}
+	}
+	return texts
+}
+
+// Matches reports whether text, such as a button's text or aria-label, shows the label in
+// English or one of the languages
+func Matches(label Label, text string, languages []string) bool {
+	lower := strings.ToLower(strings.ReplaceAll(text, "’", "'"))
+	for _, candidate := range Texts(label, languages) {
+		if strings.Contains(lower, strings.ToLower(strings.ReplaceAll(candidate, "’", "'"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// quoted finds the quoted values in a selector, e.g. Connect in button[aria-label*="Connect"]
+var quoted = regexp.MustCompile(`(["'])([^"']+)(["'])`)
+
+// Localize adds, after each candidate that quotes a label's English text, the same candidate
+// with that text in each of the languages. Other candidates are kept as they are.
+func Localize(candidates []string, languages []string) []string {
+	var localized []string
+	seen := make(map[string]bool)
+	add := func(candidate string) {
+		if !seen[candidate] {
+			seen[candidate] = true
+			localized = append(localized, candidate)
+		}
+	}
+	for _, candidate := range candidates {
+		add(candidate)
+		for _, match := range quoted.FindAllStringSubmatchIndex(candidate, -1) {
+			english := candidate[match[4]:match[5]]
+			for _, texts := range UIText {
+				if len(texts["en"]) == 0 || texts["en"][0] != english {
+					continue
+				}
+				for _, code := range languages {
+					for _, text := range texts[code] {
+						if text == english || strings.ContainsAny(text, `"'`) {
+							continue
+						}
+						add(candidate[:match[4]] + text + candidate[match[5]:])
+					}
+				}
+			}
+		}
+	}
+	return localized
+}
+
+// Localized returns a copy of the set whose button candidates also match the labels in the
+// interface languages, and whose Languages are English plus those languages
+func (s Set) Localized(languages ...string) Set {
+	s.Languages = append([]string{"en"}, languages...)
+	s.NextPage = Localize(s.NextPage, languages)
+	s.ConnectButton = Localize(s.ConnectButton, languages)
+	s.AddNote = Localize(s.AddNote, languages)
+	s.SendInvite = Localize(s.SendInvite, languages)
+	s.SendWithoutNote = Localize(s.SendWithoutNote, languages)
+	s.ProfileMessage = Localize(s.ProfileMessage, languages)
+	s.MessageSend = Localize(s.MessageSend, languages)
+	return s
+}
@@ -0,0 +1,582 @@
+package selectors
+
+import "fmt"
+
+// Layout identifies which LinkedIn page layout a session is served
+type Layout string
+
+const (
+	LayoutDesktop Layout = "desktop" // Full desktop web layout
+	LayoutMobile  Layout = "mobile"  // Mobile web layout served to phone user agents
+)
+
+// Set lists candidate CSS selectors for each element the automation interacts with;
+// candidates are tried in order and the first match wins
+type Set struct {
+	// Search results; field candidates are looked up inside the result card
+	ProfileLinks    []string
+	ProfileCard     []string
+	ProfileName     []string
+	ProfileTitle    []string
+	ProfileCompany  []string
+	ProfileLocation []string
+	ProfilePhoto    []string // The result's profile photo, an img read only when photos are enabled
+	NextPage        []string
+	ResultCount     []string // Heading with the "About 1,200 results" estimate
+
+	// Hover card LinkedIn shows while the mouse rests on a result's name
+	HoverCard          []string
+	HoverCardHeadline  []string
+	HoverCardMutual    []string // "12 mutual connections"
+	HoverCardFollowers []string // "1,234 followers"
+
+	// Profile page and invitation modal
+	ConnectButton   []string
+	AddNote         []string // Button opening the note field of the invitation modal
+	InviteNote      []string
+	InviteEmail     []string // Email field shown when the member only accepts invites from people who know their address
+	SendInvite      []string
+	SendWithoutNote []string // Button sending the invitation blank
+
+	// Connections list
+	ConnectionCards []string
+	ConnectionName  []string
+	ConnectionTitle []string
+	ConnectionLink  []string
+
+	// Messaging
+	Conversations  []string
+	ProfileMessage []string // Message button on a profile page
+	MessageSubject []string // Subject field of a message to someone outside the network
+	MessageInput   []string
+	MessageSend    []string
+
+	// Company search results and company pages
+	CompanyLinks     []string // Links to company pages among company search results
+	CompanyAbout     []string // Overview of a company's About page, listing website, industry, size and headquarters
+	CompanyFollowers []string // Top card of a company page with the "1,234 followers" count
+
+	// Who viewed your profile; private viewers' cards have no profile link
+	ViewerCards    []string
+	ViewerHeadline []string
+	ViewerTime     []string // When the visit was, e.g. "Viewed 3h ago"
+
+	// A post's page, its comments and the list of its reactions
+	PostText        []string
+	PostAuthor      []string // Link to the profile of the post's author
+	CommentCards    []string
+	CommentHeadline []string // Headline of the comment's author
+	MoreComments    []string // Button loading earlier comments
+	ReactionsCount  []string // Reaction count that opens the list of reactions
+	ReactorCards    []string
+	ReactorHeadline []string
+	MoreReactors    []string // Button loading more of the list of reactions
+
+	// Content search results, each a post whose card carries its activity URN in data-urn
+	ContentCards          []string
+	ContentAuthorHeadline []string
+	MoreContent           []string // Button loading more results
+
+	// The account's creator analytics and its newsletter's page
+	AnalyticsFollowers    []string // Total followers on the audience analytics page
+	AnalyticsImpressions  []string // Impressions on the content analytics page
+	NewsletterSubscribers []string // e.g. "2,150 subscribers"
+	// Interface languages the button candidates and text heuristics match, see Localized
+	Languages []string
+}
+
+// Desktop contains the selectors for LinkedIn's desktop web layout
+var Desktop = Set{
+	ProfileLinks: []string{
+		"a[href*='/in/']",
+		".search-result__person a",
+		".entity-result__title-text a",
+		".app-aware-link[href*='/in/']",
+	},
+	ProfileCard: []string{
+		".reusable-search__result-container",
+		".entity-result",
+		".search-result",
+	},
+	ProfileName: []string{
+		".entity-result__title-text a span[aria-hidden='true']",
+		".entity-result__title-text a",
+		".actor-name",
+		".search-result__title",
+	},
+	ProfileTitle: []string{
+		".entity-result__primary-subtitle",
+		".search-result__snippets",
+		".subline-level-1",
+	},
+	ProfileCompany: []string{
+		".entity-result__secondary-subtitle",
+		".search-result__snippets .t-14",
+		".subline-level-2",
+	},
+	ProfileLocation: []string{
+		".entity-result__location",
+		".search-result__location",
+		"[data-test-id='result-location']",
+	},
+	ProfilePhoto: []string{
+		"img.presence-entity__image",
+		".entity-result__universal-image img",
+		"img.EntityPhoto-circle-3",
+		".search-result__image img",
+	},
+	NextPage: []string{
+		"button[aria-label='Next']",
+		".artdeco-pagination__button--next",
+		"a[aria-label='Next']",
+		".pv-s-profile-actions--next",
+	},
+	ResultCount: []string{
+		".search-results-container h2",
+		".search-results__total",
+		"[data-test-id='search-results-count']",
+	},
+	HoverCard: []string{
+		".artdeco-hoverable-content--visible .entity-hovercard",
+		".entity-hovercard",
+		"[data-test-id='hovercard']",
+	},
+	HoverCardHeadline: []string{
+		".entity-hovercard__headline",
+		".artdeco-entity-lockup__subtitle",
+	},
+	HoverCardMutual: []string{
+		".entity-hovercard__shared-connections",
+		".artdeco-entity-lockup__caption",
+	},
+	HoverCardFollowers: []string{
+		".entity-hovercard__follower-count",
+		".artdeco-entity-lockup__metadata",
+	},
+	ConnectButton: []string{
+		`button[aria-label*="Connect"]`,
+		`button[data-control-name="connect"]`,
+		`button:has-text("Connect")`,
+		`.pv-s-profile-actions button:has-text("Connect")`,
+		`button[data-test-id="connect-cta"]`,
+		`.artdeco-button--primary:has-text("Connect")`,
+	},
+	AddNote: []string{
+		`button[aria-label*="Add a note"]`,
+		`button:has-text("Add a note")`,
+		`button[data-control-name="add_note"]`,
+	},
+	InviteNote: []string{
+		`textarea[name="message"]`,
+		`textarea[aria-label*="message"]`,
+		`textarea[placeholder*="message"]`,
+		`.send-invite__custom-message textarea`,
+		`#custom-message`,
+	},
+	InviteEmail: []string{
+		`input[name="email"]`,
+		`#email`,
+		`.send-invite input[type="email"]`,
+		`.artdeco-modal input[type="email"]`,
+	},
+	SendInvite: []string{
+		`button[aria-label*="Send"]`,
+		`button:has-text("Send invitation")`,
+		`button:has-text("Send")`,
+		`.send-invite__actions button[type="submit"]`,
+		`button[data-control-name="send_invite"]`,
+	},
+	SendWithoutNote: []string{
+		`button[aria-label*="Send without a note"]`,
+		`button:has-text("Send without a note")`,
+	},
+	ConnectionCards: []string{
+		".mn-connection-card",
+		".connection-card",
+		"[data-test-id='connection-card']",
+		".mn-connections__card",
+	},
+	ConnectionName: []string{
+		".mn-connection-card__name",
+		".connection-card__name",
+		"[data-test-id='connection-name']",
+		"h3",
+		".name",
+	},
+	ConnectionTitle: []string{
+		".mn-connection-card__occupation",
+		".connection-card__title",
+		"[data-test-id='connection-title']",
+		".occupation",
+		".title",
+	},
+	ConnectionLink: []string{
+		"a[href*='/in/']",
+		".mn-connection-card__link",
+		".connection-card__link",
+	},
+	Conversations: []string{
+		".msg-conversation-listitem",
+		".conversation-item",
+		"[data-test-id='conversation-item']",
+		".msg-conversations-container li",
+	},
+	ProfileMessage: []string{
+		`.pv-top-card button[aria-label^="Message"]`,
+		`.pvs-profile-actions button[aria-label^="Message"]`,
+		`button:has-text("Message")`,
+	},
+	MessageSubject: []string{
+		`input[name="subject"]`,
+		`.msg-form__subject`,
+		`input[placeholder*="Subject"]`,
+	},
+	MessageInput: []string{
+		".msg-form__contenteditable",
+		"[data-test-id='message-input']",
+		".msg-form__msg-content-container div[contenteditable='true']",
+		"div[contenteditable='true'][role='textbox']",
+		".compose-publisher__editor div[contenteditable='true']",
+	},
+	MessageSend: []string{
+		".msg-form__send-button",
+		"[data-test-id='send-button']",
+		"button[type='submit'][aria-label*='Send']",
+		".msg-form__send-btn",
+		"button:has-text('Send')",
+	},
+	CompanyLinks: []string{
+		".entity-result__title-text a[href*='/company/']",
+		".search-result__title a[href*='/company/']",
+		"a.app-aware-link[href*='/company/']",
+	},
+	CompanyAbout: []string{
+		".org-page-details-module__card-spacing",
+		"section.org-about-module",
+		".org-grid__content-height-enforcer section.artdeco-card",
+	},
+	CompanyFollowers: []string{
+		".org-top-card-summary-info-list",
+		".org-top-card-summary__info-item",
+		".org-top-card",
+	},
+	ViewerCards: []string{
+		".member-analytics-addon-entity-list__item",
+		"li.pv-profile-view-card",
+		".profile-views-list li",
+	},
+	ViewerHeadline: []string{
+		".artdeco-entity-lockup__subtitle",
+		".pv-profile-view-card__headline",
+	},
+	ViewerTime: []string{
+		".artdeco-entity-lockup__caption",
+		".pv-profile-view-card__timestamp",
+		"time",
+	},
+	PostText: []string{
+		".feed-shared-update-v2__description .update-components-text",
+		".update-components-text",
+		".feed-shared-text",
+	},
+	PostAuthor: []string{
+		".update-components-actor__meta-link",
+		".update-components-actor__container a[href*='/in/']",
+		".feed-shared-actor__container-link",
+	},
+	CommentCards: []string{
+		"article.comments-comment-entity",
+		".comments-comment-item",
+	},
+	CommentHeadline: []string{
+		".comments-comment-meta__description-subtitle",
+		".comments-post-meta__headline",
+	},
+	MoreComments: []string{
+		"button.comments-comments-list__load-more-comments-button",
+		"button[aria-label*='Load more comments']",
+	},
+	ReactionsCount: []string{
+		"button.social-details-social-counts__count-value",
+		".social-details-social-counts__reactions-count",
+		"button[aria-label*='reactions']",
+	},
+	ReactorCards: []string{
+		".social-details-reactors-tab-body-list-item",
+		".artdeco-modal li.artdeco-list__item",
+	},
+	ReactorHeadline: []string{
+		".artdeco-entity-lockup__caption",
+		".artdeco-entity-lockup__subtitle",
+	},
+	MoreReactors: []string{
+		".social-details-reactors-modal button.scaffold-finite-scroll__load-button",
+		".artdeco-modal button[aria-label*='Show more results']",
+	},
+	ContentCards: []string{
+		".reusable-search__result-container div[data-urn^='urn:li:activity']",
+		"div.feed-shared-update-v2[data-urn]",
+	},
+	ContentAuthorHeadline: []string{
+		".update-components-actor__description",
+		".feed-shared-actor__description",
+	},
+	MoreContent: []string{
+		"button.scaffold-finite-scroll__load-button",
+		"button[aria-label*='Show more results']",
+	},
+	AnalyticsFollowers: []string{
+		".member-analytics-addon-summary__list-item-value",
+		"section[data-test-id='audience-summary'] .text-heading-xlarge",
+		".analytics-summary__total",
+	},
+	AnalyticsImpressions: []string{
+		".member-analytics-addon-metric__value",
+		"section[data-test-id='content-summary'] .text-heading-xlarge",
+		".analytics-summary__total",
+	},
+	NewsletterSubscribers: []string{
+		".newsletter-series__subscriber-count",
+		".series-header__subscriber-count",
+	},
+	Languages: []string{"en"},
+}
+
+// Mobile contains the selectors for LinkedIn's mobile web layout, which uses
+// compact list items, bottom sheets instead of modals and a full-screen composer
+var Mobile = Set{
+	ProfileLinks: []string{
+		".search-results-list li a[href*='/in/']",
+		".entity-result__content a[href*='/in/']",
+		"a.app-aware-link[href*='/in/']",
+		"a[href*='/in/']",
+	},
+	ProfileCard: []string{
+		".search-results-list li",
+		".entity-result",
+	},
+	ProfileName: []string{
+		".entity-result__title-text span[aria-hidden='true']",
+		".search-result__name",
+		".entity-result__title-text",
+	},
+	ProfileTitle: []string{
+		".entity-result__primary-subtitle",
+		".search-result__headline",
+		".t-12.t-black--light",
+	},
+	ProfileCompany: []string{
+		".entity-result__secondary-subtitle",
+		".search-result__subline",
+	},
+	ProfileLocation: []string{
+		".entity-result__location",
+		".search-result__location",
+	},
+	ProfilePhoto: []string{
+		".entity-result__universal-image img",
+		".search-result__image img",
+	},
+	NextPage: []string{
+		"button.search-results__load-more",
+		"button:has-text('Show more results')",
+		"button[aria-label='Next']",
+	},
+	ResultCount: []string{
+		".search-results__total",
+		".search-results-container h2",
+	},
+	// Phones show no hover cards; these match the card if a tablet-sized layout renders one
+	HoverCard: []string{
+		".entity-hovercard",
+	},
+	HoverCardHeadline: []string{
+		".entity-hovercard__headline",
+	},
+	HoverCardMutual: []string{
+		".entity-hovercard__shared-connections",
+	},
+	HoverCardFollowers: []string{
+		".entity-hovercard__follower-count",
+	},
+	ConnectButton: []string{
+		`button[aria-label*="Connect"]`,
+		`.pv-top-card-v2-ctas button:has-text("Connect")`,
+		`.member-profile-actions button:has-text("Connect")`,
+		`button:has-text("Connect")`,
+	},
+	AddNote: []string{
+		`.bottom-sheet button[aria-label*="Add a note"]`,
+		`.bottom-sheet button:has-text("Add a note")`,
+		`button[aria-label*="Add a note"]`,
+	},
+	InviteNote: []string{
+		`.bottom-sheet textarea`,
+		`textarea[name="message"]`,
+		`textarea[aria-label*="message"]`,
+		`#custom-message`,
+	},
+	InviteEmail: []string{
+		`.bottom-sheet input[type="email"]`,
+		`input[name="email"]`,
+		`#email`,
+	},
+	SendInvite: []string{
+		`.bottom-sheet button[aria-label*="Send"]`,
+		`.bottom-sheet button:has-text("Send")`,
+		`button[aria-label*="Send"]`,
+		`button:has-text("Send")`,
+	},
+	SendWithoutNote: []string{
+		`.bottom-sheet button[aria-label*="Send without a note"]`,
+		`.bottom-sheet button:has-text("Send without a note")`,
+		`button[aria-label*="Send without a note"]`,
+	},
+	ConnectionCards: []string{
+		".mn-connection-card",
+		".connections-list li",
+		"[data-test-id='connection-card']",
+	},
+	ConnectionName: []string{
+		".mn-connection-card__name",
+		".connection-item__name",
+		"h3",
+	},
+	ConnectionTitle: []string{
+		".mn-connection-card__occupation",
+		".connection-item__headline",
+		".occupation",
+	},
+	ConnectionLink: []string{
+		"a[href*='/in/']",
+	},
+	Conversations: []string{
+		".msg-conversation-listitem",
+		".conversations-list li",
+		"[data-test-id='conversation-item']",
+	},
+	ProfileMessage: []string{
+		`.pv-top-card-v2-ctas button[aria-label^="Message"]`,
+		`.member-profile-actions button:has-text("Message")`,
+		`button:has-text("Message")`,
+	},
+	MessageSubject: []string{
+		`.compose-form input[name="subject"]`,
+		`input[name="subject"]`,
+	},
+	MessageInput: []string{
+		".msg-form__contenteditable",
+		".compose-form textarea",
+		"div[contenteditable='true'][role='textbox']",
+		"textarea[name='message']",
+	},
+	MessageSend: []string{
+		".msg-form__send-button",
+		".compose-form button[type='submit']",
+		"button[aria-label*='Send']",
+		"button:has-text('Send')",
+	},
+	CompanyLinks: []string{
+		".search-results-list li a[href*='/company/']",
+		".entity-result__content a[href*='/company/']",
+		"a[href*='/company/']",
+	},
+	CompanyAbout: []string{
+		"section.org-about-module",
+		".org-about-us-organization-description",
+		".company-about",
+	},
+	CompanyFollowers: []string{
+		".org-top-card-summary-info-list",
+		".company-top-card",
+		".org-top-card",
+	},
+	ViewerCards: []string{
+		".profile-views-list li",
+		".member-analytics-addon-entity-list__item",
+	},
+	ViewerHeadline: []string{
+		".profile-view-card__headline",
+		".artdeco-entity-lockup__subtitle",
+	},
+	ViewerTime: []string{
+		".profile-view-card__timestamp",
+		".artdeco-entity-lockup__caption",
+		"time",
+	},
+	PostText: []string{
+		".feed-item-content .update-components-text",
+		".update-components-text",
+		".feed-shared-text",
+	},
+	PostAuthor: []string{
+		".feed-item-header a[href*='/in/']",
+		".update-components-actor__meta-link",
+	},
+	CommentCards: []string{
+		".comments-list li.comment-item",
+		"article.comments-comment-entity",
+		".comments-comment-item",
+	},
+	CommentHeadline: []string{
+		".comment-item__headline",
+		".comments-post-meta__headline",
+	},
+	MoreComments: []string{
+		".comments-list button.load-more",
+		"button.comments-comments-list__load-more-comments-button",
+	},
+	ReactionsCount: []string{
+		".social-counts__reactions",
+		"button.social-details-social-counts__count-value",
+	},
+	ReactorCards: []string{
+		".reactions-list li",
+		".social-details-reactors-tab-body-list-item",
+	},
+	ReactorHeadline: []string{
+		".reactions-list__headline",
+		".artdeco-entity-lockup__caption",
+	},
+	MoreReactors: []string{
+		".reactions-list button.load-more",
+		"button.scaffold-finite-scroll__load-button",
+	},
+	ContentCards: []string{
+		".search-results-list div[data-urn^='urn:li:activity']",
+		"div.feed-item[data-urn]",
+	},
+	ContentAuthorHeadline: []string{
+		".feed-item-header .actor-description",
+		".update-components-actor__description",
+	},
+	MoreContent: []string{
+		".search-results-list button.load-more",
+		"button.scaffold-finite-scroll__load-button",
+	},
+	AnalyticsFollowers: []string{
+		".analytics-summary .total-followers",
+		".member-analytics-addon-summary__list-item-value",
+	},
+	AnalyticsImpressions: []string{
+		".analytics-summary .total-impressions",
+		".member-analytics-addon-metric__value",
+	},
+	NewsletterSubscribers: []string{
+		".newsletter-header .subscriber-count",
+		".series-header__subscriber-count",
+	},
+	Languages: []string{"en"},
+}
+
+// For returns the selector set for a layout
+func For(layout Layout) (Set, error) {
+	switch layout {
+	case "", LayoutDesktop:
+		return Desktop, nil
+	case LayoutMobile:
+		return Mobile, nil
+	default:
+		return Set{}, fmt.Errorf("unknown layout %q", layout)
+	}
+}
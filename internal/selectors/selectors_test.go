@@ -0,0 +1,66 @@
+package selectors
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestSelectorSetsAreComplete tests that every layout defines candidates for every element
+func TestSelectorSetsAreComplete(t *testing.T) {
+	for _, layout := range []Layout{LayoutDesktop, LayoutMobile} {
+		set, err := For(layout)
+		if err != nil {
+			t.Fatalf("failed to get %s selectors: %v", layout, err)
+		}
+
+		value := reflect.ValueOf(set)
+		for i := 0; i < value.NumField(); i++ {
+			if value.Field(i).Len() == 0 {
+				t.Errorf("%s layout has no selectors for %s", layout, value.Type().Field(i).Name)
+			}
+		}
+	}
+
+	if _, err := For("tablet"); err == nil {
+		t.Errorf("expected error for unknown layout")
+	}
+}
+
+// TestLocalizedSelectors tests that button candidates and text matching follow the interface languages
+func TestLocalizedSelectors(t *testing.T) {
+	set := Desktop.Localized("de", "fr")
+
+	found := false
+	for _, candidate := range set.ConnectButton {
+		if candidate == `button[aria-label*="Vernetzen"]` {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a German Connect candidate, got %v", set.ConnectButton)
+	}
+	if len(Desktop.ConnectButton) != len(Desktop.Localized().ConnectButton) {
+		t.Errorf("expected no extra candidates without interface languages")
+	}
+
+	localized := Localize([]string{"button[type='submit'][aria-label*='Send']", ".msg-form__send-btn"}, []string{"es", "pt"})
+	expected := []string{"button[type='submit'][aria-label*='Send']", "button[type='submit'][aria-label*='Enviar']", ".msg-form__send-btn"}
+	if len(localized) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, localized)
+	}
+	for i := range expected {
+		if localized[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected, localized)
+		}
+	}
+
+	if !Matches(LabelConnect, "Jana einladen, sich zu vernetzen", set.Languages) {
+		t.Errorf("expected the German aria-label to match Connect")
+	}
+	if Matches(LabelConnect, "Jana einladen, sich zu vernetzen", Desktop.Languages) {
+		t.Errorf("expected German text not to match without German in the languages")
+	}
+	if !Matches(LabelSendInvitation, "Envoyer l’invitation", set.Languages) {
+		t.Errorf("expected the typographic apostrophe to match")
+	}
+}
@@ -0,0 +1,268 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// DefaultHealthCheckURL is a lightweight page that redirects to login or checkpoint when the session is lost
+const DefaultHealthCheckURL = "https://www.linkedin.com/feed/"
+
+// DefaultHealthCheckInterval keeps detection of a lost session well within a minute
+const DefaultHealthCheckInterval = 30 * time.Second
+
+// Status is the authentication state observed by a health check
+type Status string
+
+const (
+	StatusHealthy    Status = "healthy"    // Authenticated page loaded normally
+	StatusLoggedOut  Status = "logged_out" // Redirected to login or the auth wall
+	StatusCheckpoint Status = "checkpoint" // Redirected to a security checkpoint or challenge
+)
+
+// EventType identifies what the monitor observed
+type EventType string
+
+const (
+	EventSessionLost     EventType = "session_lost"     // Session became unusable; workers are paused
+	EventSessionRestored EventType = "session_restored" // Session is usable again; workers are resumed
+	EventProbeFailed     EventType = "probe_failed"     // The check itself failed, e.g. a network error
+)
+
+// Event is raised by the monitor when the session state changes or a check fails
+type Event struct {
+	Type   EventType
+	Status Status
+	URL    string // Page the probe ended on
+	Err    error
+	Time   time.Time
+}
+
+// Probe checks whether the browser session is still authenticated
+type Probe interface {
+	Check(ctx context.Context) (Status, string, error)
+}
+
+// ClassifyURL maps the URL a probe ended on to a session status
+func ClassifyURL(url string) Status {
+	lower := strings.ToLower(url)
+	switch {
+	case strings.Contains(lower, "/checkpoint/"),
+		strings.Contains(lower, "/challenge"):
+		return StatusCheckpoint
+	case strings.Contains(lower, "/login"),
+		strings.Contains(lower, "/uas/"),
+		strings.Contains(lower, "/authwall"),
+		strings.Contains(lower, "/signup"):
+		return StatusLoggedOut
+	default:
+		return StatusHealthy
+	}
+}
+
+// PageProbe loads a cheap authenticated page in a dedicated tab and inspects where it lands
+type PageProbe struct {
+	page    *rod.Page
+	url     string
+	timeout time.Duration
+}
+
+// NewPageProbe creates a probe using its own page so it never disturbs pages used by workers
+func NewPageProbe(page *rod.Page, url string) *PageProbe {
+	if url == "" {
+		url = DefaultHealthCheckURL
+	}
+	return &PageProbe{
+		page:    page,
+		url:     url,
+		timeout: 20 * time.Second,
+	}
+}
+
+// Check navigates to the health check URL and classifies the final URL after redirects
+func (p *PageProbe) Check(ctx context.Context) (Status, string, error) {
+	page := p.page.Context(ctx).Timeout(p.timeout)
+
+	if err := page.Navigate(p.url); err != nil {
+		return "", "", fmt.Errorf("failed to load health check page: %w", err)
+	}
+	if err := page.WaitLoad(); err != nil {
+		return "", "", fmt.Errorf("health check page did not finish loading: %w", err)
+	}
+
+	info, err := page.Info()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read health check page URL: %w", err)
+	}
+	return ClassifyURL(info.URL), info.URL, nil
+}
+
+// Gate lets workers block while the session is unusable
+type Gate struct {
+	mutex  sync.Mutex
+	paused bool
+	reason string
+	resume chan struct{}
+}
+
+// NewGate creates an open gate
+func NewGate() *Gate {
+	return &Gate{resume: make(chan struct{})}
+}
+
+// Pause closes the gate; Wait blocks until Resume is called
+func (g *Gate) Pause(reason string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if !g.paused {
+		g.paused = true
+		g.resume = make(chan struct{})
+	}
+	g.reason = reason
+}
+
+// Resume opens the gate and releases every waiting worker
+func (g *Gate) Resume() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.paused {
+		g.paused = false
+		g.reason = ""
+		close(g.resume)
+	}
+}
+
+// Paused reports whether the gate is closed and why
+func (g *Gate) Paused() (bool, string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return g.paused, g.reason
+}
+
+// Wait returns immediately while the gate is open, otherwise blocks until it is resumed or ctx ends
+func (g *Gate) Wait(ctx context.Context) error {
+	g.mutex.Lock()
+	paused, resume := g.paused, g.resume
+	g.mutex.Unlock()
+
+	if !paused {
+		return nil
+	}
+
+	select {
+	case <-resume:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// MonitorConfig contains health monitor settings
+type MonitorConfig struct {
+	Interval time.Duration // Time between checks, defaults to DefaultHealthCheckInterval
+}
+
+// Monitor periodically probes the session, pausing the gate and raising events on logout or checkpoint
+type Monitor struct {
+	probe    Probe
+	gate     *Gate
+	interval time.Duration
+	onEvent  func(Event)
+	status   Status
+	mutex    sync.Mutex
+}
+
+// NewMonitor creates a monitor; onEvent may be nil
+func NewMonitor(config MonitorConfig, probe Probe, gate *Gate, onEvent func(Event)) (*Monitor, error) {
+	if probe == nil {
+		return nil, fmt.Errorf("probe cannot be nil")
+	}
+	if gate == nil {
+		return nil, fmt.Errorf("gate cannot be nil")
+	}
+	if config.Interval < 0 {
+		return nil, fmt.Errorf("health check interval cannot be negative")
+	}
+
+	interval := config.Interval
+	if interval == 0 {
+		interval = DefaultHealthCheckInterval
+	}
+	if onEvent == nil {
+		onEvent = func(Event) {}
+	}
+
+	return &Monitor{
+		probe:    probe,
+		gate:     gate,
+		interval: interval,
+		onEvent:  onEvent,
+		status:   StatusHealthy,
+	}, nil
+}
+
+// Run checks the session immediately and then every interval until ctx is cancelled
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.CheckOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckOnce runs a single probe and applies its result to the gate
+func (m *Monitor) CheckOnce(ctx context.Context) Status {
+	status, url, err := m.probe.Check(ctx)
+
+	m.mutex.Lock()
+	previous := m.status
+	if err == nil {
+		m.status = status
+	}
+	m.mutex.Unlock()
+
+	if err != nil {
+		if ctx.Err() == nil {
+			// A failed probe says nothing about the session, so workers keep running
+			m.onEvent(Event{Type: EventProbeFailed, Status: previous, URL: url, Err: err, Time: time.Now()})
+		}
+		return previous
+	}
+
+	switch {
+	case status != StatusHealthy && previous == StatusHealthy:
+		m.gate.Pause(string(status))
+		m.onEvent(Event{Type: EventSessionLost, Status: status, URL: url, Time: time.Now()})
+	case status == StatusHealthy && previous != StatusHealthy:
+		m.gate.Resume()
+		m.onEvent(Event{Type: EventSessionRestored, Status: status, URL: url, Time: time.Now()})
+	case status != StatusHealthy:
+		// Still unusable, possibly moved from logout to checkpoint
+		m.gate.Pause(string(status))
+	}
+
+	return status
+}
+
+// Status returns the most recent session status observed
+func (m *Monitor) Status() Status {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.status
+}
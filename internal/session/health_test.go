@@ -0,0 +1,168 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedProbe returns a fixed sequence of results, repeating the last one
+type scriptedProbe struct {
+	mutex   sync.Mutex
+	results []Status
+	errs    []error
+	calls   int
+}
+
+func (p *scriptedProbe) Check(ctx context.Context) (Status, string, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	i := p.calls
+	if i >= len(p.results) {
+		i = len(p.results) - 1
+	}
+	p.calls++
+
+	var err error
+	if i < len(p.errs) {
+		err = p.errs[i]
+	}
+	return p.results[i], "https://www.linkedin.com/feed/", err
+}
+
+// TestClassifyURL tests mapping of redirect targets to session statuses
+func TestClassifyURL(t *testing.T) {
+	cases := map[string]Status{
+		"https://www.linkedin.com/feed/":                             StatusHealthy,
+		"https://www.linkedin.com/in/jane-doe/":                      StatusHealthy,
+		"https://www.linkedin.com/login?session_redirect=%2Ffeed%2F": StatusLoggedOut,
+		"https://www.linkedin.com/uas/login?trk=guest_homepage":      StatusLoggedOut,
+		"https://www.linkedin.com/authwall?trk=bf&originalReferer=":  StatusLoggedOut,
+		"https://www.linkedin.com/checkpoint/challenge/AgG1x":        StatusCheckpoint,
+		"https://www.linkedin.com/checkpoint/lg/login-submit":        StatusCheckpoint,
+	}
+	for url, expected := range cases {
+		if status := ClassifyURL(url); status != expected {
+			t.Errorf("ClassifyURL(%q) = %q, expected %q", url, status, expected)
+		}
+	}
+}
+
+// TestMonitorPausesAndResumesGate tests that losing the session pauses workers and raises events
+func TestMonitorPausesAndResumesGate(t *testing.T) {
+	probe := &scriptedProbe{
+		results: []Status{StatusHealthy, StatusHealthy, StatusCheckpoint, StatusCheckpoint, StatusHealthy},
+		errs:    []error{nil, fmt.Errorf("net::ERR_CONNECTION_RESET")},
+	}
+	gate := NewGate()
+
+	var events []Event
+	monitor, err := NewMonitor(MonitorConfig{Interval: time.Millisecond}, probe, gate, func(e Event) {
+		events = append(events, e)
+	})
+	if err != nil {
+		t.Fatalf("failed to create monitor: %v", err)
+	}
+
+	monitor.CheckOnce(context.Background())
+	if paused, _ := gate.Paused(); paused {
+		t.Fatalf("gate should stay open while healthy")
+	}
+
+	monitor.CheckOnce(context.Background())
+	if paused, _ := gate.Paused(); paused || len(events) != 1 || events[0].Type != EventProbeFailed {
+		t.Fatalf("probe failure should raise an event without pausing, got %+v", events)
+	}
+
+	monitor.CheckOnce(context.Background())
+	paused, reason := gate.Paused()
+	if !paused || reason != string(StatusCheckpoint) {
+		t.Fatalf("expected gate paused for checkpoint, got paused=%v reason=%q", paused, reason)
+	}
+	if len(events) != 2 || events[1].Type != EventSessionLost {
+		t.Fatalf("expected session lost event, got %+v", events)
+	}
+
+	monitor.CheckOnce(context.Background())
+	if len(events) != 2 {
+		t.Fatalf("repeated checkpoint should not raise another event, got %+v", events)
+	}
+
+	monitor.CheckOnce(context.Background())
+	if paused, _ := gate.Paused(); paused {
+		t.Fatalf("gate should reopen once the session is restored")
+	}
+	if len(events) != 3 || events[2].Type != EventSessionRestored || monitor.Status() != StatusHealthy {
+		t.Fatalf("expected session restored event, got %+v", events)
+	}
+}
+
+// TestGateWaitBlocksWhilePaused tests that workers block on a paused gate until resumed or cancelled
+func TestGateWaitBlocksWhilePaused(t *testing.T) {
+	gate := NewGate()
+	if err := gate.Wait(context.Background()); err != nil {
+		t.Fatalf("open gate should not block: %v", err)
+	}
+
+	gate.Pause(string(StatusLoggedOut))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := gate.Wait(ctx); err == nil {
+		t.Fatalf("expected paused gate to block until the context expired")
+	}
+
+	released := make(chan error, 1)
+	go func() {
+		released <- gate.Wait(context.Background())
+	}()
+	gate.Resume()
+
+	select {
+	case err := <-released:
+		if err != nil {
+			t.Fatalf("unexpected wait error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("resume did not release waiting worker")
+	}
+}
+
+// TestMonitorRunDetectsLogout tests that the running monitor detects a logout within a few intervals
+func TestMonitorRunDetectsLogout(t *testing.T) {
+	probe := &scriptedProbe{results: []Status{StatusHealthy, StatusLoggedOut}}
+	gate := NewGate()
+
+	lost := make(chan Event, 1)
+	monitor, err := NewMonitor(MonitorConfig{Interval: 5 * time.Millisecond}, probe, gate, func(e Event) {
+		if e.Type == EventSessionLost {
+			lost <- e
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to create monitor: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	select {
+	case e := <-lost:
+		if e.Status != StatusLoggedOut {
+			t.Fatalf("expected logged out status, got %q", e.Status)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("monitor did not detect logout")
+	}
+	if paused, _ := gate.Paused(); !paused {
+		t.Fatalf("expected workers to be paused after logout")
+	}
+
+	if _, err := NewMonitor(MonitorConfig{}, nil, gate, nil); err == nil {
+		t.Fatalf("expected error for nil probe")
+	}
+}
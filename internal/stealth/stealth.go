@@ -2,25 +2,30 @@ package stealth
 
 import (
 	"context"
+	crand "crypto/rand"
+	"encoding/binary"
 	"fmt"
 	"math"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/input"
 	"github.com/go-rod/rod/lib/proto"
+
+	"linkedin-automation-framework/internal/browser"
 )
 
 // StealthBehavior interface for human-like behavior simulation
 type StealthBehavior interface {
 	HumanMouseMove(ctx context.Context, page *rod.Page, target *rod.Element) error
 	HumanType(ctx context.Context, element *rod.Element, text string) error
-	RandomDelay(min, max time.Duration) error
+	RandomDelay(ctx context.Context, min, max time.Duration) error
 	ScrollNaturally(ctx context.Context, page *rod.Page) error
 	ConfigureFingerprint(browser *rod.Browser) error
 	IdleBehavior(ctx context.Context, page *rod.Page) error
-	EnforceCooldown(lastAction time.Time, cooldownPeriod time.Duration) error
+	EnforceCooldown(ctx context.Context, lastAction time.Time, cooldownPeriod time.Duration) error
 	IsWithinBusinessHours(t time.Time) bool
 	ShouldRateLimit(actionCount int, timeWindow time.Duration, maxActions int) bool
 }
@@ -39,6 +44,7 @@ type StealthConfig struct {
 	CooldownPeriod  time.Duration
 	MaxActionsPerWindow int
 	RateLimitWindow time.Duration
+	Seed            int64 // Seeds delays, paths and typing so a run can be reproduced; 0 picks a random seed
 }
 
 // FingerprintConfig contains browser fingerprint settings
@@ -53,14 +59,89 @@ type FingerprintConfig struct {
 type StealthManager struct {
 	config      StealthConfig
 	fingerprint FingerprintConfig
+	seed        int64
+	tracer      *Tracer // Records every primitive's choices when set
+	pacer       Pacer   // Stretches delays and cooldowns when set
+	rngMutex    sync.Mutex
+	rng         *rand.Rand // Every random choice comes from here, so the seed determines them all
 }
 
-// NewStealthManager creates a new stealth manager
+// NewStealthManager creates a new stealth manager. Its randomness is seeded with config.Seed, or
+// from crypto/rand when that is 0.
 func NewStealthManager(config StealthConfig, fingerprint FingerprintConfig) *StealthManager {
+	seed := config.Seed
+	if seed == 0 {
+		seed = randomSeed()
+	}
 	return &StealthManager{
 		config:      config,
 		fingerprint: fingerprint,
+		seed:        seed,
+		rng:         rand.New(rand.NewSource(seed)),
+	}
+}
+
+// randomSeed returns a non-zero seed from crypto/rand
+func randomSeed() int64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		return time.Now().UnixNano()
 	}
+	if seed := int64(binary.LittleEndian.Uint64(b[:]) >> 1); seed != 0 {
+		return seed
+	}
+	return 1
+}
+
+// Seed returns the seed in use. Setting it as StealthConfig.Seed repeats the same delays, mouse
+// paths and typing for the same sequence of calls.
+func (sm *StealthManager) Seed() int64 {
+	return sm.seed
+}
+
+// SetTracer records every later primitive call to tracer; nil stops tracing
+func (sm *StealthManager) SetTracer(tracer *Tracer) {
+	sm.tracer = tracer
+}
+
+// Pacer slows a session down, e.g. while LinkedIn answers slowly
+type Pacer interface {
+	Stretch() float64 // Factor delays and cooldowns are multiplied by, 1 for none
+}
+
+// SetPacer stretches every later delay and cooldown by pacer; nil stops stretching. Random
+// choices are made as without a pacer, so a seed still repeats a run.
+func (sm *StealthManager) SetPacer(pacer Pacer) {
+	sm.pacer = pacer
+}
+
+// paced stretches d by the pacer's factor
+func (sm *StealthManager) paced(d time.Duration) time.Duration {
+	if sm.pacer == nil {
+		return d
+	}
+	if stretch := sm.pacer.Stretch(); stretch > 1 {
+		return time.Duration(float64(d) * stretch)
+	}
+	return d
+}
+
+func (sm *StealthManager) float64() float64 {
+	sm.rngMutex.Lock()
+	defer sm.rngMutex.Unlock()
+	return sm.rng.Float64()
+}
+
+func (sm *StealthManager) intn(n int) int {
+	sm.rngMutex.Lock()
+	defer sm.rngMutex.Unlock()
+	return sm.rng.Intn(n)
+}
+
+func (sm *StealthManager) int63n(n int64) int64 {
+	sm.rngMutex.Lock()
+	defer sm.rngMutex.Unlock()
+	return sm.rng.Int63n(n)
 }
 
 // Point represents a 2D coordinate
@@ -70,31 +151,33 @@ type Point struct {
 
 // HumanMouseMove implements human-like mouse movement with Bézier curves and micro-corrections
 func (sm *StealthManager) HumanMouseMove(ctx context.Context, page *rod.Page, target *rod.Element) error {
+	return sm.MoveMouse(ctx, browser.NewPageDriver(page), browser.NewElementDriver(target))
+}
+
+// MoveMouse is HumanMouseMove for a page driver
+func (sm *StealthManager) MoveMouse(ctx context.Context, page browser.PageDriver, target browser.ElementDriver) error {
 	// Get target element position
-	box, err := target.Shape()
+	targetX, targetY, err := target.Center()
 	if err != nil {
-		return fmt.Errorf("failed to get target element shape: %w", err)
-	}
-
-	if len(box.Quads) == 0 {
-		return fmt.Errorf("target element has no visible area")
+		return fmt.Errorf("failed to locate target element: %w", err)
 	}
-
-	// Calculate target center with slight randomization
-	quad := box.Quads[0]
-	targetX := (quad[0] + quad[2] + quad[4] + quad[6]) / 4
-	targetY := (quad[1] + quad[3] + quad[5] + quad[7]) / 4
 	
 	// Add small random offset to make movement more natural
-	targetX += (rand.Float64() - 0.5) * 10
-	targetY += (rand.Float64() - 0.5) * 10
+	targetX += (sm.float64() - 0.5) * 10
+	targetY += (sm.float64() - 0.5) * 10
 
 	// Get current mouse position (Rod doesn't provide this directly, so we'll use a reasonable default)
 	start := Point{X: 100, Y: 100} // Default starting position
 	end := Point{X: targetX, Y: targetY}
 
-	// Generate Bézier curve path with overshoot and micro-corrections
-	path := sm.generateBezierPath(start, end)
+	// Generate Bézier curve path with overshoot and micro-corrections, in a pooled buffer
+	buffer := pathPool.Get().(*[]Point)
+	path := sm.AppendBezierPath((*buffer)[:0], start, end)
+	defer func() {
+		*buffer = path
+		pathPool.Put(buffer)
+	}()
+	sm.tracer.Record(TraceEvent{Op: TraceMouse, Points: len(path), Pixels: math.Hypot(end.X-start.X, end.Y-start.Y)})
 
 	// Move along the path with human-like timing
 	for i, point := range path {
@@ -102,55 +185,81 @@ func (sm *StealthManager) HumanMouseMove(ctx context.Context, page *rod.Page, ta
 			return err
 		}
 
-		// Move to point using proto.Point
-		err := page.Mouse.MoveTo(proto.Point{X: point.X, Y: point.Y})
+		err := page.MouseMoveTo(point.X, point.Y)
 		if err != nil {
 			return fmt.Errorf("failed to move mouse to point: %w", err)
 		}
 
 		// Add micro-delays between movements
 		if i < len(path)-1 {
-			delay := time.Duration(rand.Intn(5)+1) * time.Millisecond
-			time.Sleep(delay)
+			delay := time.Duration(sm.intn(5)+1) * time.Millisecond
+			if err := Sleep(ctx, delay); err != nil {
+				return err
+			}
 		}
 	}
 
 	return nil
 }
 
-// generateBezierPath creates a human-like mouse movement path using Bézier curves
-func (sm *StealthManager) generateBezierPath(start, end Point) []Point {
-	distance := math.Sqrt(math.Pow(end.X-start.X, 2) + math.Pow(end.Y-start.Y, 2))
-	steps := int(distance / 5) // Adjust step size based on distance
-	if steps < 10 {
-		steps = 10
+// Bounds on the points in one mouse path
+const (
+	minPathSteps = 10
+	maxPathSteps = 100
+)
+
+// pathPool holds path buffers between mouse movements, so a multi-hour session does not allocate
+// a new path for every move
+var pathPool = sync.Pool{
+	New: func() interface{} {
+		path := make([]Point, 0, maxPathSteps)
+		return &path
+	},
+}
+
+// pathSteps returns the number of points in a path from start to end, one per 5px of distance
+func pathSteps(start, end Point) int {
+	steps := int(math.Hypot(end.X-start.X, end.Y-start.Y) / 5)
+	if steps < minPathSteps {
+		steps = minPathSteps
 	}
-	if steps > 100 {
-		steps = 100
+	if steps > maxPathSteps {
+		steps = maxPathSteps
 	}
+	return steps
+}
+
+// generateBezierPath creates a human-like mouse movement path using Bézier curves
+func (sm *StealthManager) generateBezierPath(start, end Point) []Point {
+	return sm.AppendBezierPath(make([]Point, 0, pathSteps(start, end)), start, end)
+}
+
+// AppendBezierPath appends a path from start to end to dst and returns the extended slice.
+// Passing an earlier path truncated to dst[:0] reuses its buffer without allocating.
+func (sm *StealthManager) AppendBezierPath(dst []Point, start, end Point) []Point {
+	steps := pathSteps(start, end)
 
 	// Create control points for Bézier curve with some randomness
-	cp1X := start.X + (end.X-start.X)*0.25 + (rand.Float64()-0.5)*50
-	cp1Y := start.Y + (end.Y-start.Y)*0.25 + (rand.Float64()-0.5)*50
-	cp2X := start.X + (end.X-start.X)*0.75 + (rand.Float64()-0.5)*50
-	cp2Y := start.Y + (end.Y-start.Y)*0.75 + (rand.Float64()-0.5)*50
+	cp1X := start.X + (end.X-start.X)*0.25 + (sm.float64()-0.5)*50
+	cp1Y := start.Y + (end.Y-start.Y)*0.25 + (sm.float64()-0.5)*50
+	cp2X := start.X + (end.X-start.X)*0.75 + (sm.float64()-0.5)*50
+	cp2Y := start.Y + (end.Y-start.Y)*0.75 + (sm.float64()-0.5)*50
 
 	cp1 := Point{X: cp1X, Y: cp1Y}
 	cp2 := Point{X: cp2X, Y: cp2Y}
 
-	path := make([]Point, steps)
 	for i := 0; i < steps; i++ {
 		t := float64(i) / float64(steps-1)
 		point := sm.cubicBezier(start, cp1, cp2, end, t)
 		
 		// Add micro-corrections (small random variations)
-		point.X += (rand.Float64() - 0.5) * 2
-		point.Y += (rand.Float64() - 0.5) * 2
+		point.X += (sm.float64() - 0.5) * 2
+		point.Y += (sm.float64() - 0.5) * 2
 		
-		path[i] = point
+		dst = append(dst, point)
 	}
 
-	return path
+	return dst
 }
 
 // cubicBezier calculates a point on a cubic Bézier curve
@@ -169,12 +278,24 @@ func (sm *StealthManager) cubicBezier(p0, p1, p2, p3 Point, t float64) Point {
 
 // HumanType implements human typing simulation with realistic delays and mistakes
 func (sm *StealthManager) HumanType(ctx context.Context, element *rod.Element, text string) error {
+	return sm.TypeText(ctx, browser.NewElementDriver(element), text)
+}
+
+// TypeText is HumanType for an element driver
+func (sm *StealthManager) TypeText(ctx context.Context, element browser.ElementDriver, text string) error {
 	// Clear existing text first
 	err := element.SelectAllText()
 	if err != nil {
 		return fmt.Errorf("failed to select existing text: %w", err)
 	}
 
+	// The trace gets the typing as far as it went, even if cancelled
+	var event *TraceEvent
+	if sm.tracer != nil {
+		event = &TraceEvent{Op: TraceType}
+		defer func() { sm.tracer.Record(*event) }()
+	}
+
 	// Type each character with human-like delays
 	for i, char := range text {
 		if err := ctx.Err(); err != nil {
@@ -182,30 +303,33 @@ func (sm *StealthManager) HumanType(ctx context.Context, element *rod.Element, t
 		}
 
 		// Simulate occasional typing mistakes (5% chance)
-		if rand.Float64() < 0.05 && i > 0 {
+		if sm.float64() < 0.05 && i > 0 {
 			// Type a wrong character, then backspace and correct
-			wrongChar := rune('a' + rand.Intn(26))
+			wrongChar := rune('a' + sm.intn(26))
+			if event != nil {
+				event.Mistakes++
+			}
 			err := element.Input(string(wrongChar))
 			if err != nil {
 				return fmt.Errorf("failed to input wrong character: %w", err)
 			}
 			
 			// Delay before correction
-			delay := time.Duration(rand.Intn(200)+100) * time.Millisecond
-			time.Sleep(delay)
+			delay := time.Duration(sm.intn(200)+100) * time.Millisecond
+			if err := Sleep(ctx, delay); err != nil {
+				return err
+			}
 			
 			// Backspace
-			keyActions, err := element.KeyActions()
-			if err != nil {
-				return fmt.Errorf("failed to get key actions: %w", err)
-			}
-			err = keyActions.Press(input.Backspace).Do()
+			err = element.Type(input.Backspace)
 			if err != nil {
 				return fmt.Errorf("failed to press backspace: %w", err)
 			}
 			
 			// Small delay before typing correct character
-			time.Sleep(time.Duration(rand.Intn(100)+50) * time.Millisecond)
+			if err := Sleep(ctx, time.Duration(sm.intn(100)+50)*time.Millisecond); err != nil {
+				return err
+			}
 		}
 
 		// Type the actual character
@@ -225,28 +349,48 @@ func (sm *StealthManager) HumanType(ctx context.Context, element *rod.Element, t
 				maxDelay = 200 * time.Millisecond
 			}
 			
-			delay := minDelay + time.Duration(rand.Int63n(int64(maxDelay-minDelay)))
-			time.Sleep(delay)
+			delay := minDelay + time.Duration(sm.int63n(int64(maxDelay-minDelay)))
+			if event != nil {
+				event.Keys = append(event.Keys, delay.Milliseconds())
+			}
+			if err := Sleep(ctx, delay); err != nil {
+				return err
+			}
 		}
 	}
 
 	return nil
 }
 
-// RandomDelay implements randomized timing for interactions
-func (sm *StealthManager) RandomDelay(min, max time.Duration) error {
+// Sleep waits for d, returning ctx's error as soon as ctx is cancelled
+func Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// RandomDelay implements randomized timing for interactions; it returns early with ctx's error
+// when ctx is cancelled
+func (sm *StealthManager) RandomDelay(ctx context.Context, min, max time.Duration) error {
 	if min > max {
 		min, max = max, min
 	}
 	
-	if min == max {
-		time.Sleep(min)
-		return nil
+	delay := min
+	if min != max {
+		delay = min + time.Duration(sm.int63n(int64(max-min)))
 	}
-	
-	delay := min + time.Duration(rand.Int63n(int64(max-min)))
-	time.Sleep(delay)
-	return nil
+	delay = sm.paced(delay)
+	sm.tracer.Record(TraceEvent{Op: TraceDelay, Ms: delay.Milliseconds()})
+	return Sleep(ctx, delay)
 }
 
 // ConfigureFingerprint implements browser fingerprint configuration
@@ -336,31 +480,37 @@ func (sm *StealthManager) ConfigureFingerprint(browser *rod.Browser) error {
 
 // ScrollNaturally implements natural scrolling behavior
 func (sm *StealthManager) ScrollNaturally(ctx context.Context, page *rod.Page) error {
-	// Random scroll direction and distance
-	scrollDown := rand.Float64() < 0.7 // 70% chance to scroll down
-	scrollDistance := rand.Intn(300) + 100 // 100-400 pixels
+	return sm.Scroll(ctx, browser.NewPageDriver(page))
+}
 
-	if !scrollDown {
-		scrollDistance = -scrollDistance
-	}
+// Scroll is ScrollNaturally for a page driver. The distance is kept within the page as measured
+// from its current scroll position, so nothing is scrolled at the very top and bottom of a page.
+func (sm *StealthManager) Scroll(ctx context.Context, page browser.PageDriver) error {
+	scrollDistance := sm.scrollDistance(sm.viewport(page))
 
 	// Perform scroll with multiple small movements for naturalness
-	steps := rand.Intn(5) + 3 // 3-7 steps
-	stepSize := scrollDistance / steps
+	steps := sm.intn(5) + 3 // 3-7 steps
+	stepSize := scrollDistance / float64(steps)
+	sm.tracer.Record(TraceEvent{Op: TraceScroll, Pixels: scrollDistance, Steps: steps})
+	if scrollDistance == 0 {
+		return nil
+	}
 
 	for i := 0; i < steps; i++ {
 		if err := ctx.Err(); err != nil {
 			return err
 		}
 
-		err := page.Mouse.Scroll(0, float64(stepSize), steps)
+		err := page.MouseScroll(0, stepSize, steps)
 		if err != nil {
 			return fmt.Errorf("failed to scroll: %w", err)
 		}
 
 		// Small delay between scroll steps
-		delay := time.Duration(rand.Intn(50)+20) * time.Millisecond
-		time.Sleep(delay)
+		delay := time.Duration(sm.intn(50)+20) * time.Millisecond
+		if err := Sleep(ctx, delay); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -368,37 +518,47 @@ func (sm *StealthManager) ScrollNaturally(ctx context.Context, page *rod.Page) e
 
 // IdleBehavior implements mouse hovering and idle movement simulation
 func (sm *StealthManager) IdleBehavior(ctx context.Context, page *rod.Page) error {
+	return sm.Idle(ctx, browser.NewPageDriver(page))
+}
+
+// Idle is IdleBehavior for a page driver. Moves stay inside the page's viewport.
+func (sm *StealthManager) Idle(ctx context.Context, page browser.PageDriver) error {
+	viewport := sm.viewport(page)
+
 	// Perform 2-5 small random movements
-	movements := rand.Intn(4) + 2
+	movements := sm.intn(4) + 2
+	sm.tracer.Record(TraceEvent{Op: TraceIdle, Steps: movements})
 	
 	for i := 0; i < movements; i++ {
 		if err := ctx.Err(); err != nil {
 			return err
 		}
 
-		// Generate random position within reasonable viewport bounds
-		newX := rand.Float64() * 800 + 100 // 100-900 range
-		newY := rand.Float64() * 600 + 100 // 100-700 range
-
-		err := page.Mouse.MoveTo(proto.Point{X: newX, Y: newY})
+		point := sm.idlePoint(viewport)
+		err := page.MouseMoveTo(point.X, point.Y)
 		if err != nil {
 			continue // Skip if movement fails
 		}
 
 		// Random pause between movements
-		delay := time.Duration(rand.Intn(1000)+500) * time.Millisecond
-		time.Sleep(delay)
+		delay := time.Duration(sm.intn(1000)+500) * time.Millisecond
+		if err := Sleep(ctx, delay); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// EnforceCooldown implements cooldown period enforcement
-func (sm *StealthManager) EnforceCooldown(lastAction time.Time, cooldownPeriod time.Duration) error {
+// EnforceCooldown implements cooldown period enforcement; it returns early with ctx's error when
+// ctx is cancelled
+func (sm *StealthManager) EnforceCooldown(ctx context.Context, lastAction time.Time, cooldownPeriod time.Duration) error {
+	cooldownPeriod = sm.paced(cooldownPeriod)
 	elapsed := time.Since(lastAction)
 	if elapsed < cooldownPeriod {
 		remaining := cooldownPeriod - elapsed
-		time.Sleep(remaining)
+		sm.tracer.Record(TraceEvent{Op: TraceCooldown, Ms: remaining.Milliseconds()})
+		return Sleep(ctx, remaining)
 	}
 	return nil
 }
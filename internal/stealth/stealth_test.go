@@ -1,11 +1,15 @@
 package stealth
 
 import (
+	"context"
+	"errors"
 	"math"
 	"testing"
 	"time"
 
 	"pgregory.net/rapid"
+
+	"linkedin-automation-framework/internal/browser/browsertest"
 )
 
 // **Feature: linkedin-automation-framework, Property 6: Human-like mouse movement patterns**
@@ -126,7 +130,7 @@ func TestRandomizedInteractionTiming(t *testing.T) {
 		delays := make([]time.Duration, 10)
 		for i := 0; i < 10; i++ {
 			start := time.Now()
-			err := sm.RandomDelay(minDelay, maxDelay)
+			err := sm.RandomDelay(context.Background(), minDelay, maxDelay)
 			if err != nil {
 				t.Fatalf("RandomDelay failed: %v", err)
 			}
@@ -333,17 +337,16 @@ func TestIdleBehaviorSimulation(t *testing.T) {
 			t.Fatalf("Number of idle movements out of expected range: %d", numMovements)
 		}
 
-		// Property 2: Movement positions should be within reasonable viewport bounds
-		// Test that generated positions are within expected ranges (100-900 for X, 100-700 for Y)
+		// Property 2: Movement positions should be within the viewport
+		viewport := Viewport{Width: defaultViewportW, Height: defaultViewportH}
 		for i := 0; i < 10; i++ {
-			x := rapid.Float64Range(100, 900).Draw(t, "idleX")
-			y := rapid.Float64Range(100, 700).Draw(t, "idleY")
+			point := sm.idlePoint(viewport)
 
-			if x < 100 || x > 900 {
-				t.Fatalf("Idle X position out of bounds: %f", x)
+			if point.X < 0 || point.X > viewport.Width {
+				t.Fatalf("Idle X position out of bounds: %f", point.X)
 			}
-			if y < 100 || y > 700 {
-				t.Fatalf("Idle Y position out of bounds: %f", y)
+			if point.Y < 0 || point.Y > viewport.Height {
+				t.Fatalf("Idle Y position out of bounds: %f", point.Y)
 			}
 		}
 
@@ -510,7 +513,7 @@ func TestCooldownPeriodEnforcement(t *testing.T) {
 		// Test with action that just happened
 		recentAction := time.Now()
 		start := time.Now()
-		err := sm.EnforceCooldown(recentAction, cooldownPeriod)
+		err := sm.EnforceCooldown(context.Background(), recentAction, cooldownPeriod)
 		elapsed := time.Since(start)
 
 		if err != nil {
@@ -527,7 +530,7 @@ func TestCooldownPeriodEnforcement(t *testing.T) {
 		// Test with action that happened longer ago than cooldown period
 		oldAction := time.Now().Add(-cooldownPeriod - 100*time.Millisecond)
 		start = time.Now()
-		err = sm.EnforceCooldown(oldAction, cooldownPeriod)
+		err = sm.EnforceCooldown(context.Background(), oldAction, cooldownPeriod)
 		elapsed = time.Since(start)
 
 		if err != nil {
@@ -541,7 +544,7 @@ func TestCooldownPeriodEnforcement(t *testing.T) {
 
 		// Property 4: Cooldown with zero period should not delay
 		start = time.Now()
-		err = sm.EnforceCooldown(time.Now(), 0)
+		err = sm.EnforceCooldown(context.Background(), time.Now(), 0)
 		elapsed = time.Since(start)
 
 		if err != nil {
@@ -562,6 +565,85 @@ func TestCooldownPeriodEnforcement(t *testing.T) {
 	})
 }
 
+// TestSleepsHonorCancellation tests that cancelling the context ends a wait within milliseconds,
+// however long the wait was
+func TestSleepsHonorCancellation(t *testing.T) {
+	sm := NewStealthManager(StealthConfig{
+		TypingMinDelay: time.Minute,
+		TypingMaxDelay: 2 * time.Minute,
+	}, FingerprintConfig{})
+	field := browsertest.NewElement("input")
+
+	waits := map[string]func(ctx context.Context) error{
+		"EnforceCooldown": func(ctx context.Context) error {
+			return sm.EnforceCooldown(ctx, time.Now(), 5*time.Minute)
+		},
+		"RandomDelay": func(ctx context.Context) error {
+			return sm.RandomDelay(ctx, time.Minute, 5*time.Minute)
+		},
+		"TypeText": func(ctx context.Context) error {
+			return sm.TypeText(ctx, field, "hello")
+		},
+	}
+
+	for name, wait := range waits {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(20*time.Millisecond, cancel)
+
+			start := time.Now()
+			err := wait(ctx)
+			elapsed := time.Since(start)
+
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected context.Canceled, got %v", err)
+			}
+			if elapsed > time.Second {
+				t.Fatalf("wait ignored cancellation for %v", elapsed)
+			}
+		})
+	}
+}
+
+// TestSeededRandomnessIsReproducible tests that managers given the same seed make the same
+// random choices
+func TestSeededRandomnessIsReproducible(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		seed := rapid.Int64Range(1, math.MaxInt64).Draw(t, "seed")
+		start := Point{X: rapid.Float64Range(0, 1000).Draw(t, "startX"), Y: rapid.Float64Range(0, 1000).Draw(t, "startY")}
+		end := Point{X: rapid.Float64Range(0, 1000).Draw(t, "endX"), Y: rapid.Float64Range(0, 1000).Draw(t, "endY")}
+
+		first := NewStealthManager(StealthConfig{Seed: seed}, FingerprintConfig{})
+		second := NewStealthManager(StealthConfig{Seed: seed}, FingerprintConfig{})
+		if first.Seed() != seed {
+			t.Fatalf("Seed() = %d, want %d", first.Seed(), seed)
+		}
+
+		// The same calls on the same seed choose the same paths and delays
+		for i := 0; i < 3; i++ {
+			firstPath := first.generateBezierPath(start, end)
+			secondPath := second.generateBezierPath(start, end)
+			if len(firstPath) != len(secondPath) {
+				t.Fatalf("path %d lengths differ: %d and %d", i, len(firstPath), len(secondPath))
+			}
+			for j := range firstPath {
+				if firstPath[j] != secondPath[j] {
+					t.Fatalf("path %d differs at point %d: %v and %v", i, j, firstPath[j], secondPath[j])
+				}
+			}
+			if a, b := first.int63n(int64(time.Second)), second.int63n(int64(time.Second)); a != b {
+				t.Fatalf("delay %d differs: %v and %v", i, time.Duration(a), time.Duration(b))
+			}
+		}
+	})
+
+	// Without a seed, each manager picks its own and reports it
+	sm := NewStealthManager(StealthConfig{}, FingerprintConfig{})
+	if sm.Seed() == 0 {
+		t.Fatal("expected a random non-zero seed when none is configured")
+	}
+}
+
 // **Feature: linkedin-automation-framework, Property 9: Human typing simulation**
 // **Validates: Requirements 2.4**
 func TestHumanTypingSimulation(t *testing.T) {
@@ -634,4 +716,64 @@ func TestHumanTypingSimulation(t *testing.T) {
 			t.Fatalf("Zero TypingMaxDelay not stored correctly: got %v, want 0", smZero.config.TypingMaxDelay)
 		}
 	})
-}
\ No newline at end of file
+}
+// TestAppendBezierPathReusesBuffer tests that a path appended to a large enough buffer does not
+// allocate, and that the reused buffer holds only the new path
+func TestAppendBezierPathReusesBuffer(t *testing.T) {
+	sm := NewStealthManager(StealthConfig{}, FingerprintConfig{})
+	start, end := Point{X: 100, Y: 100}, Point{X: 900, Y: 600}
+	buffer := make([]Point, 0, maxPathSteps)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		buffer = sm.AppendBezierPath(buffer[:0], start, end)
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocations when reusing a buffer, got %v", allocs)
+	}
+	if len(buffer) != pathSteps(start, end) {
+		t.Errorf("expected %d points, got %d", pathSteps(start, end), len(buffer))
+	}
+
+	// A short move after a long one keeps only its own points
+	buffer = sm.AppendBezierPath(buffer[:0], start, Point{X: 110, Y: 100})
+	if len(buffer) != minPathSteps {
+		t.Errorf("expected %d points, got %d", minPathSteps, len(buffer))
+	}
+}
+
+// benchmarkMoves are the start and end points of typical mouse moves across a 1920x1080 viewport
+var benchmarkMoves = [][2]Point{
+	{{X: 100, Y: 100}, {X: 1500, Y: 800}},
+	{{X: 960, Y: 540}, {X: 980, Y: 560}},
+	{{X: 300, Y: 900}, {X: 1200, Y: 200}},
+}
+
+func BenchmarkGenerateBezierPath(b *testing.B) {
+	sm := NewStealthManager(StealthConfig{}, FingerprintConfig{})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		move := benchmarkMoves[i%len(benchmarkMoves)]
+		_ = sm.generateBezierPath(move[0], move[1])
+	}
+}
+
+func BenchmarkAppendBezierPathReused(b *testing.B) {
+	sm := NewStealthManager(StealthConfig{}, FingerprintConfig{})
+	buffer := make([]Point, 0, maxPathSteps)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		move := benchmarkMoves[i%len(benchmarkMoves)]
+		buffer = sm.AppendBezierPath(buffer[:0], move[0], move[1])
+	}
+}
+
+func BenchmarkAppendBezierPathPooled(b *testing.B) {
+	sm := NewStealthManager(StealthConfig{}, FingerprintConfig{})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		move := benchmarkMoves[i%len(benchmarkMoves)]
+		buffer := pathPool.Get().(*[]Point)
+		*buffer = sm.AppendBezierPath((*buffer)[:0], move[0], move[1])
+		pathPool.Put(buffer)
+	}
+}
@@ -0,0 +1,118 @@
+package stealth
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Trace operations, one per stealth primitive
+const (
+	TraceDelay    = "delay"    // RandomDelay
+	TraceCooldown = "cooldown" // EnforceCooldown
+	TraceMouse    = "mouse"    // MoveMouse
+	TraceType     = "type"     // TypeText
+	TraceScroll   = "scroll"   // ScrollNaturally
+	TraceIdle     = "idle"     // IdleBehavior
+)
+
+// TraceEvent is what one stealth primitive chose. Fields that do not apply to the operation are
+// left out of the trace file.
+type TraceEvent struct {
+	At       int64   `json:"at"`                 // Milliseconds since tracing started
+	Op       string  `json:"op"`                 // One of the Trace operations
+	Ms       int64   `json:"ms,omitempty"`       // Delay or cooldown waited
+	Points   int     `json:"points,omitempty"`   // Mouse path length
+	Pixels   float64 `json:"px,omitempty"`       // Mouse distance to the target, or scroll distance (negative is up)
+	Steps    int     `json:"steps,omitempty"`    // Scroll steps or idle movements
+	Keys     []int64 `json:"keys,omitempty"`     // Pause after each keystroke, in milliseconds
+	Mistakes int     `json:"mistakes,omitempty"` // Typos typed and corrected
+}
+
+// Tracer writes every stealth primitive call to a trace as one line of JSON, for offline analysis
+// of timing and movement. A nil tracer ignores every call.
+type Tracer struct {
+	mutex   sync.Mutex
+	writer  *bufio.Writer
+	encoder *json.Encoder
+	closer  io.Closer
+	start   time.Time
+	now     func() time.Time
+}
+
+// NewTracer writes a trace to w
+func NewTracer(w io.Writer) *Tracer {
+	writer := bufio.NewWriter(w)
+	return &Tracer{
+		writer:  writer,
+		encoder: json.NewEncoder(writer),
+		start:   time.Now(),
+		now:     time.Now,
+	}
+}
+
+// OpenTrace creates the trace file at path, replacing an earlier one
+func OpenTrace(path string) (*Tracer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create trace directory: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace file: %w", err)
+	}
+	tracer := NewTracer(file)
+	tracer.closer = file
+	return tracer, nil
+}
+
+// Record writes an event, stamped with the time since tracing started. Tracing never fails the
+// traced action, so a write error only stops later events from being written.
+func (t *Tracer) Record(event TraceEvent) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.encoder == nil {
+		return
+	}
+	event.At = t.now().Sub(t.start).Milliseconds()
+	if err := t.encoder.Encode(event); err != nil {
+		t.encoder = nil
+	}
+}
+
+// Close flushes the trace and closes its file
+func (t *Tracer) Close() error {
+	if t == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	err := t.writer.Flush()
+	if t.closer != nil {
+		if closeErr := t.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// ReadTrace reads the events of a trace in the order they were recorded
+func ReadTrace(r io.Reader) ([]TraceEvent, error) {
+	var events []TraceEvent
+	decoder := json.NewDecoder(r)
+	for decoder.More() {
+		var event TraceEvent
+		if err := decoder.Decode(&event); err != nil {
+			return nil, fmt.Errorf("failed to read trace event %d: %w", len(events)+1, err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -0,0 +1,146 @@
+package stealth
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/browser/browsertest"
+)
+
+// TestTracerRecordsPrimitives tests that each traced primitive writes one event with the choices
+// it made, in call order
+func TestTracerRecordsPrimitives(t *testing.T) {
+	var buffer bytes.Buffer
+	tracer := NewTracer(&buffer)
+	sm := NewStealthManager(StealthConfig{
+		TypingMinDelay: time.Millisecond,
+		TypingMaxDelay: 2 * time.Millisecond,
+		Seed:           7,
+	}, FingerprintConfig{})
+	sm.SetTracer(tracer)
+
+	ctx := context.Background()
+	page := browsertest.NewPage("https://www.linkedin.com/feed/")
+	button := browsertest.NewElement("button").SetPosition(400, 300)
+	field := browsertest.NewElement("input")
+	page.Append(button, field)
+
+	if err := sm.RandomDelay(ctx, time.Millisecond, 3*time.Millisecond); err != nil {
+		t.Fatalf("RandomDelay failed: %v", err)
+	}
+	if err := sm.MoveMouse(ctx, page, button); err != nil {
+		t.Fatalf("MoveMouse failed: %v", err)
+	}
+	if err := sm.TypeText(ctx, field, "hello"); err != nil {
+		t.Fatalf("TypeText failed: %v", err)
+	}
+	if err := sm.EnforceCooldown(ctx, time.Now(), 5*time.Millisecond); err != nil {
+		t.Fatalf("EnforceCooldown failed: %v", err)
+	}
+	if err := tracer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	events, err := ReadTrace(&buffer)
+	if err != nil {
+		t.Fatalf("ReadTrace failed: %v", err)
+	}
+	ops := []string{TraceDelay, TraceMouse, TraceType, TraceCooldown}
+	if len(events) != len(ops) {
+		t.Fatalf("expected %d events, got %d: %+v", len(ops), len(events), events)
+	}
+	for i, op := range ops {
+		if events[i].Op != op {
+			t.Errorf("event %d: expected %s, got %s", i, op, events[i].Op)
+		}
+		if i > 0 && events[i].At < events[i-1].At {
+			t.Errorf("event %d recorded before event %d", i, i-1)
+		}
+	}
+
+	if delay := events[0].Ms; delay < 1 || delay > 3 {
+		t.Errorf("delay outside 1-3ms: %d", delay)
+	}
+	_, _, moves := page.Mouse()
+	if events[1].Points != moves || events[1].Pixels <= 0 {
+		t.Errorf("mouse event %+v does not match %d moves", events[1], moves)
+	}
+	if len(events[2].Keys) != len("hello")-1 {
+		t.Errorf("expected a pause after each keystroke but the last, got %v", events[2].Keys)
+	}
+	if events[3].Ms <= 0 || events[3].Ms > 5 {
+		t.Errorf("cooldown outside 0-5ms: %d", events[3].Ms)
+	}
+}
+
+// fixedPacer stretches by a fixed factor
+type fixedPacer float64
+
+func (p fixedPacer) Stretch() float64 { return float64(p) }
+
+// TestPacerStretchesDelays tests that a pacer stretches the delays the seed chooses, and that a
+// factor under 1 never shortens them
+func TestPacerStretchesDelays(t *testing.T) {
+	delays := func(pacer Pacer) []int64 {
+		var buffer bytes.Buffer
+		sm := NewStealthManager(StealthConfig{Seed: 11}, FingerprintConfig{})
+		sm.SetTracer(NewTracer(&buffer))
+		sm.SetPacer(pacer)
+		for i := 0; i < 3; i++ {
+			if err := sm.RandomDelay(context.Background(), 2*time.Millisecond, 6*time.Millisecond); err != nil {
+				t.Fatalf("RandomDelay failed: %v", err)
+			}
+		}
+		events, err := ReadTrace(&buffer)
+		if err != nil {
+			t.Fatalf("ReadTrace failed: %v", err)
+		}
+		var ms []int64
+		for _, event := range events {
+			ms = append(ms, event.Ms)
+		}
+		return ms
+	}
+
+	plain, stretched, slowed := delays(nil), delays(fixedPacer(2)), delays(fixedPacer(0.5))
+	for i := range plain {
+		if stretched[i] < 2*plain[i] || stretched[i] > 2*plain[i]+1 {
+			t.Errorf("delay %d: expected %dms stretched to about %dms, got %dms", i, plain[i], 2*plain[i], stretched[i])
+		}
+		if slowed[i] != plain[i] {
+			t.Errorf("delay %d: a factor under 1 changed %dms to %dms", i, plain[i], slowed[i])
+		}
+	}
+}
+
+// TestTracerIsOptional tests that a nil tracer ignores calls and that OpenTrace writes a file
+func TestTracerIsOptional(t *testing.T) {
+	var tracer *Tracer
+	tracer.Record(TraceEvent{Op: TraceDelay})
+	if err := tracer.Close(); err != nil {
+		t.Fatalf("nil tracer Close returned %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "traces", "run.jsonl")
+	tracer, err := OpenTrace(path)
+	if err != nil {
+		t.Fatalf("OpenTrace failed: %v", err)
+	}
+	tracer.now = func() time.Time { return tracer.start }
+	tracer.Record(TraceEvent{Op: TraceScroll, Pixels: -120, Steps: 4})
+	if err := tracer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read trace: %v", err)
+	}
+	if want := `{"at":0,"op":"scroll","px":-120,"steps":4}` + "\n"; string(data) != want {
+		t.Errorf("expected compact line %q, got %q", want, data)
+	}
+}
@@ -0,0 +1,79 @@
+package stealth
+
+import (
+	"linkedin-automation-framework/internal/browser"
+)
+
+// Default viewport assumed when the page cannot be measured and no viewport is configured
+const (
+	defaultViewportW = 1280
+	defaultViewportH = 720
+)
+
+// idleMargin keeps idle moves this fraction of the viewport away from its edges
+const idleMargin = 0.1
+
+// viewportScript measures the visible part of the page and how far it can scroll
+const viewportScript = `() => ({
+	width: window.innerWidth,
+	height: window.innerHeight,
+	scrollY: window.scrollY,
+	maxScrollY: Math.max(0, document.documentElement.scrollHeight - window.innerHeight)
+})`
+
+// Viewport is the visible part of a page, in CSS pixels
+type Viewport struct {
+	Width      float64
+	Height     float64
+	ScrollY    float64 // How far the page is scrolled down
+	MaxScrollY float64 // How far the page can scroll down
+}
+
+// viewport measures the page, falling back to the configured viewport, which cannot scroll
+func (sm *StealthManager) viewport(page browser.PageDriver) Viewport {
+	fallback := Viewport{Width: defaultViewportW, Height: defaultViewportH}
+	if sm.fingerprint.ViewportW > 0 && sm.fingerprint.ViewportH > 0 {
+		fallback.Width, fallback.Height = float64(sm.fingerprint.ViewportW), float64(sm.fingerprint.ViewportH)
+	}
+
+	result, err := page.Eval(viewportScript)
+	if err != nil {
+		return fallback
+	}
+	measured := Viewport{
+		Width:      result.Get("width").Num(),
+		Height:     result.Get("height").Num(),
+		ScrollY:    result.Get("scrollY").Num(),
+		MaxScrollY: result.Get("maxScrollY").Num(),
+	}
+	if measured.Width <= 0 || measured.Height <= 0 {
+		return fallback
+	}
+	return measured
+}
+
+// idlePoint picks a point inside the viewport, away from its edges
+func (sm *StealthManager) idlePoint(viewport Viewport) Point {
+	return Point{
+		X: viewport.Width * (idleMargin + sm.float64()*(1-2*idleMargin)),
+		Y: viewport.Height * (idleMargin + sm.float64()*(1-2*idleMargin)),
+	}
+}
+
+// scrollDistance picks a scroll of 100-400 pixels, down 70% of the time, that stays within the
+// page. It scrolls the other way when the page cannot go further, and returns 0 when the page
+// cannot scroll at all.
+func (sm *StealthManager) scrollDistance(viewport Viewport) float64 {
+	down := sm.float64() < 0.7
+	distance := float64(sm.intn(300) + 100)
+
+	below := viewport.MaxScrollY - viewport.ScrollY
+	above := viewport.ScrollY
+	if down && below <= 0 || !down && above <= 0 {
+		down = !down
+	}
+	if down {
+		return min(distance, max(below, 0))
+	}
+	return -min(distance, max(above, 0))
+}
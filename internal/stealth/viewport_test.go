@@ -0,0 +1,72 @@
+package stealth
+
+import (
+	"context"
+	"testing"
+
+	"pgregory.net/rapid"
+
+	"linkedin-automation-framework/internal/browser/browsertest"
+)
+
+// TestIdleAndScrollStayInViewport tests that idle moves land inside the viewport and scrolls stay
+// within the page, whatever its size and scroll position
+func TestIdleAndScrollStayInViewport(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		sm := NewStealthManager(StealthConfig{Seed: rapid.Int64Range(1, 1<<62).Draw(t, "seed")}, FingerprintConfig{})
+		maxScrollY := rapid.SampledFrom([]float64{0, 50, 3000}).Draw(t, "maxScrollY")
+		viewport := Viewport{
+			Width:      rapid.Float64Range(320, 2560).Draw(t, "width"),
+			Height:     rapid.Float64Range(240, 1440).Draw(t, "height"),
+			ScrollY:    rapid.Float64Range(0, maxScrollY).Draw(t, "scrollY"),
+			MaxScrollY: maxScrollY,
+		}
+
+		for i := 0; i < 10; i++ {
+			point := sm.idlePoint(viewport)
+			if point.X < viewport.Width*idleMargin || point.X > viewport.Width*(1-idleMargin) ||
+				point.Y < viewport.Height*idleMargin || point.Y > viewport.Height*(1-idleMargin) {
+				t.Fatalf("idle point %v outside viewport %vx%v", point, viewport.Width, viewport.Height)
+			}
+
+			distance := sm.scrollDistance(viewport)
+			after := viewport.ScrollY + distance
+			if after < 0 || after > viewport.MaxScrollY {
+				t.Fatalf("scroll of %v from %v leaves the page (max %v)", distance, viewport.ScrollY, viewport.MaxScrollY)
+			}
+			if distance < -400 || distance > 400 {
+				t.Fatalf("scroll of %v exceeds 400 pixels", distance)
+			}
+			if maxScrollY == 0 && distance != 0 {
+				t.Fatalf("scrolled %v on a page that cannot scroll", distance)
+			}
+		}
+	})
+}
+
+// TestViewportMeasuresPage tests that scrolling uses the page's measured viewport, and the
+// configured one when the page cannot be measured
+func TestViewportMeasuresPage(t *testing.T) {
+	sm := NewStealthManager(StealthConfig{Seed: 1}, FingerprintConfig{ViewportW: 800, ViewportH: 600})
+
+	page := browsertest.NewPage("https://www.linkedin.com/feed/")
+	if got := sm.viewport(page); got != (Viewport{Width: 800, Height: 600}) {
+		t.Fatalf("expected the configured viewport as fallback, got %+v", got)
+	}
+
+	// At the bottom of the page every scroll goes up
+	page.OnEval(viewportScript, func(args ...interface{}) (interface{}, error) {
+		return map[string]float64{"width": 390, "height": 844, "scrollY": 1200, "maxScrollY": 1200}, nil
+	})
+	if got := sm.viewport(page); got != (Viewport{Width: 390, Height: 844, ScrollY: 1200, MaxScrollY: 1200}) {
+		t.Fatalf("expected the measured viewport, got %+v", got)
+	}
+	for i := 0; i < 5; i++ {
+		if err := sm.Scroll(context.Background(), page); err != nil {
+			t.Fatalf("Scroll failed: %v", err)
+		}
+		if _, y := page.Scrolled(); y >= 0 {
+			t.Fatalf("scroll %d went down from the bottom of the page: %v", i, y)
+		}
+	}
+}
@@ -6,20 +6,91 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
 	_ "modernc.org/sqlite"
+
+	"linkedin-automation-framework/internal/identity"
 )
 
 // Storage interface for persistent data management
 type Storage interface {
 	SaveConnectionRequest(request ConnectionRequest) error
 	GetSentRequests() ([]ConnectionRequest, error)
+	UpdateConnectionRequestStatus(profileURL, status string) error
 	SaveMessage(message SentMessage) error
 	GetMessageHistory() ([]SentMessage, error)
 	SaveSearchResults(results []ProfileResult) error
 	GetSearchResults() ([]ProfileResult, error)
+	SaveConnections(connections []Connection) error
+	GetConnections() ([]Connection, error)
+	GetSearchSnapshot(query string) (SearchSnapshot, bool, error)
+	SaveSearchSnapshot(snapshot SearchSnapshot) error
+	SaveSavedSearch(search SavedSearch) error
+	GetSavedSearch(name string) (SavedSearch, bool, error)
+	GetSavedSearches() ([]SavedSearch, error)
+	DeleteSavedSearch(name string) error
+	GetGeoLocation(query string) (GeoLocation, bool, error)
+	SaveGeoLocation(location GeoLocation) error
+	SaveAccountEvent(event AccountEvent) error
+	GetAccountEvents(account string, since time.Time) ([]AccountEvent, error)
+	SaveDeferredLeads(leads []DeferredLead) error
+	GetDeferredLeads() ([]DeferredLead, error)
+	DeleteDeferredLead(profileURL string) error
+	SaveLeadSkip(skip LeadSkip) error
+	GetLeadSkips() ([]LeadSkip, error)
+	SaveRunSummary(summary RunSummary) error
+	GetRunSummaries() ([]RunSummary, error)
+	NextRunSequence(day string) (int, error)
+	GetRunRecords(runID string) (RunRecords, error)
+	SaveErrorFingerprints(fingerprints []ErrorFingerprint) error
+	GetErrorFingerprints() ([]ErrorFingerprint, error)
+	RecordProxyOutcome(outcome ProxyOutcome) error
+	GetProxyStats() ([]ProxyStats, error)
+	SaveBlackout(blackout Blackout) error
+	GetBlackouts() ([]Blackout, error)
+	DeleteBlackouts() error
+	QueueLeads(leads []QueuedLead) (int, error)
+	GetQueuedLeads() ([]QueuedLead, error)
+	DeleteQueuedLead(campaign, profileURL string) error
+	SaveAPIToken(token APIToken) error
+	GetAPITokens() ([]APIToken, error)
+	SaveDraft(draft Draft) error
+	GetDrafts() ([]Draft, error)
+	SavePhoto(photo Photo) error
+	GetPhotos() ([]Photo, error)
+	DeletePhoto(profileURL string) error
+	SaveCompany(company Company) error
+	GetCompany(key string) (Company, bool, error)
+	GetCompanies() ([]Company, error)
+	SaveReply(reply Reply) error
+	GetReplies() ([]Reply, error)
+	SaveSuppression(suppression Suppression) error
+	GetSuppressions() ([]Suppression, error)
+	DeleteSuppression(profileURL string) error
+	SaveLeadNote(note LeadNote) error
+	GetLeadNotes() ([]LeadNote, error)
+	SaveLeadAttribute(attribute LeadAttribute) error
+	GetLeadAttributes() ([]LeadAttribute, error)
+	DeleteLeadAttribute(profileURL, key string) error
+	SaveProfileView(view ProfileView) error
+	GetProfileViews() ([]ProfileView, error)
+	SaveAudienceSnapshot(snapshot AudienceSnapshot) error
+	GetAudienceSnapshots() ([]AudienceSnapshot, error)
+	EnqueueJobs(jobs []Job) (int, error)
+	LeaseJob(queue, key, lease string, now time.Time, visibility time.Duration) (Job, bool, error)
+	AckJob(id int64, lease string) (bool, error)
+	RetryJob(id int64, lease string, attempts int, availableAt time.Time, lastError string) (bool, error)
+	DeadLetterJob(id int64, lease string, letter DeadLetter) (bool, error)
+	GetJobs(queue string) ([]Job, error)
+	GetDeadLetters() ([]DeadLetter, error)
+	UpdateDeadLetterPayload(id int64, payload string) (bool, error)
+	RequeueDeadLetters(ids []int64, now time.Time) (int, error)
+	DeleteDeadLetters(ids []int64) (int, error)
+	CheckReadWrite() error
 	Close() error
 }
 
@@ -29,7 +100,9 @@ type ConnectionRequest struct {
 	ProfileName string
 	Note        string
 	SentAt      time.Time
-	Status      string // pending, accepted, declined
+	Status      string // pending, accepted, declined, disappeared, expired
+	RunID       string // Run that sent the request
+	Template    string // Template the note was rendered from, empty for an invite without one
 }
 
 // SentMessage represents a sent message
@@ -39,6 +112,7 @@ type SentMessage struct {
 	Content      string
 	SentAt       time.Time
 	Response     string
+	RunID        string // Run that sent the message
 }
 
 // ProfileResult represents a discovered profile
@@ -49,8 +123,308 @@ type ProfileResult struct {
 	Company     string
 	Location    string
 	Mutual      int
+	Followers   int
 	Premium     bool
 	Timestamp   time.Time
+	Confidence  FieldConfidence
+	RunID       string // Run that last found the profile
+	PhotoURL    string // Profile photo shown on the result card, when photos are enabled
+}
+
+// FieldConfidence records how reliably each profile field was extracted: "high", "medium", "low" or empty
+type FieldConfidence struct {
+	Name     string
+	Title    string
+	Company  string
+	Location string
+}
+
+// Connection represents a first-degree connection imported from LinkedIn's data export
+type Connection struct {
+	ProfileURL  string
+	FirstName   string
+	LastName    string
+	Email       string
+	Company     string
+	Position    string
+	ConnectedOn time.Time
+	ImportedAt  time.Time
+	Tracked     bool     // Whether the connection came from a request sent by this tool
+	Tags        []string // Labels given with the connections tag command, kept across imports
+}
+
+// SearchSnapshot records every profile a recurring search has returned so later runs can report only new ones
+type SearchSnapshot struct {
+	Query       string   // Search URL identifying the search
+	ProfileURLs []string // Canonical URLs of all profiles seen so far
+	TakenAt     time.Time
+	Runs        int
+}
+
+// SavedSearch is a named search that can be run on demand or on a schedule
+type SavedSearch struct {
+	Name       string
+	Query      string        // People search URL
+	MaxResults int           // Profiles read per run
+	Interval   time.Duration // Time between scheduled runs, zero for on-demand only
+	CreatedAt  time.Time
+	LastRunAt  time.Time // Zero until the first run
+	LastFound  int       // Profiles the last run returned
+	LastNew    int       // Profiles the last run returned for the first time
+	LastError  string    // Why the last run failed, empty if it succeeded
+	Runs       int
+	TotalNew   int // New profiles across all runs
+}
+
+// GeoLocation caches the LinkedIn geo ID a location name resolved to
+type GeoLocation struct {
+	Query      string // Normalized location name as searched
+	ID         string // Numeric geo ID used in the geoUrn search facet
+	Name       string // LinkedIn's display name for the location
+	ResolvedAt time.Time
+}
+
+// AccountEvent records something that happened to a LinkedIn account, such as a challenge or a logout
+type AccountEvent struct {
+	Account string
+	Type    string // e.g. "challenge", "logout", "warning"
+	Detail  string // Page URL or message that raised the event
+	At      time.Time
+	RunID   string // Run during which the event happened
+}
+
+// DeferredLead is a lead set aside until RetryAfter, e.g. after the weekly invitation limit
+type DeferredLead struct {
+	ProfileURL string
+	Name       string
+	Reason     string
+	DeferredAt time.Time
+	RetryAfter time.Time
+	RunID      string // Run that deferred the lead
+}
+
+// LeadSkip records why a lead was passed over for good, e.g. because its invite needs an email address
+type LeadSkip struct {
+	ProfileURL string
+	Reason     string
+	SkippedAt  time.Time
+	RunID      string // Run that skipped the lead
+}
+
+// RunSummary is the outcome of one connect, message or search run, with its full JSON summary in Document
+type RunSummary struct {
+	RunID      string
+	Mode       string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Status     string
+	Attempted  int
+	Sent       int
+	Skipped    int
+	Failed     int
+	Document   string
+}
+
+// ErrorFingerprint counts the errors of one run that failed the same way, see errors.Fingerprint
+type ErrorFingerprint struct {
+	RunID       string
+	Fingerprint string
+	Operation   string
+	Selector    string
+	Class       string
+	Count       int
+	Rate        float64 // Share of the run's attempts that failed this way
+	Example     string  // Error of the first failure
+}
+
+// ProxyOutcome is how one use of a proxy went
+type ProxyOutcome struct {
+	Proxy          string // Proxy URL without its password
+	Error          string // Empty on success
+	At             time.Time
+	UnhealthyUntil time.Time // When a failed proxy rejoins the rotation
+}
+
+// ProxyStats is the health of a proxy over every run
+type ProxyStats struct {
+	Proxy          string
+	Successes      int
+	Failures       int
+	LastError      string
+	LastFailureAt  time.Time // Zero if it never failed
+	UnhealthyUntil time.Time // Out of rotation until then; zero or past once healthy
+	LastUsedAt     time.Time
+}
+
+// RunRecords is everything stored during one run
+type RunRecords struct {
+	RunID    string
+	Summary  *RunSummary // Nil while the run is in progress or if it crashed
+	Requests []ConnectionRequest
+	Messages []SentMessage
+	Results  []ProfileResult
+	Events   []AccountEvent
+	Deferred []DeferredLead
+	Skips    []LeadSkip
+	Errors   []ErrorFingerprint // The most frequent first
+}
+
+// Blackout is an ad-hoc pause during which no actions run, added from the command line
+type Blackout struct {
+	Start     time.Time
+	End       time.Time
+	Reason    string
+	CreatedAt time.Time
+}
+
+// QueuedLead is a lead handed in from outside, e.g. through the commands endpoint, waiting
+// for a campaign run to take it
+type QueuedLead struct {
+	ProfileURL string
+	Campaign   string // Campaign whose next run takes the lead; "" for the next run of any campaign
+	Source     string // Who queued the lead, e.g. the name of the API token used
+	QueuedAt   time.Time
+}
+
+// APIToken is an issued API token. Only a hash of its secret is stored.
+type APIToken struct {
+	Name          string
+	Hash          string // Hex SHA-256 of the secret
+	Scope         string // "read", "review" or "control"
+	RatePerMinute int    // Requests allowed per minute; 0 uses the configured default
+	CreatedAt     time.Time
+	RevokedAt     time.Time // Zero while the token is valid
+}
+
+// Draft is an invite or message a campaign holds until a reviewer approves it
+type Draft struct {
+	ID         string // Derived from the campaign, step and profile, so a lead's send is held once
+	Campaign   string
+	StepID     string
+	Kind       string // "invite", "message" or "open_profile"
+	ProfileURL string
+	LeadName   string
+	Subject    string // Open Profile messages only
+	Text       string // The note or message to send, as rendered or as edited by the reviewer
+	Status     string // "pending", "approved", "rejected" or "sent"
+	Reviewer   string
+	Reason     string // Why the reviewer rejected it
+	CreatedAt  time.Time
+	ReviewedAt time.Time // Zero until reviewed
+	SentAt     time.Time // Zero until sent
+}
+
+// Photo is a downloaded profile photo. Files are named by the hash of their content, so
+// profiles sharing a photo share the file.
+type Photo struct {
+	ProfileURL string
+	SourceURL  string // Where the photo was downloaded from
+	Hash       string // SHA-256 of the content, hex
+	Path       string
+	FetchedAt  time.Time
+}
+
+// Company is the firmographic data read from a company's LinkedIn page. Leads are linked to it
+// by the normalized company name their profile shows.
+type Company struct {
+	Key          string // identity.NormalizeCompany of the name that was looked up
+	URL          string // Company page; empty when no company of that name was found
+	Name         string // Name as the company page shows it
+	Size         string // Employee range as LinkedIn shows it, e.g. "51-200 employees"
+	Industry     string
+	Headquarters string
+	Website      string
+	Followers    int
+	FetchedAt    time.Time
+	Keywords     []string  // Configured keywords found on Website
+	ScannedAt    time.Time // When Website was last scanned for keywords; zero if never
+}
+
+// Reply is a lead answering, recorded by hand or with the Pipedrive integration
+type Reply struct {
+	ProfileURL string
+	Excerpt    string
+	RepliedAt  time.Time
+}
+
+// Suppression is a prospect no account may contact, such as one who opted out
+type Suppression struct {
+	ProfileURL string
+	Reason     string
+	Account    string // Account that added it, empty when added by hand
+	AddedAt    time.Time
+}
+
+// LeadNote is a free-text note a user attached to a lead
+type LeadNote struct {
+	ProfileURL string
+	Text       string
+	Author     string
+	CreatedAt  time.Time
+}
+
+// LeadAttribute is a custom key/value a user set on a lead
+type LeadAttribute struct {
+	ProfileURL string
+	Key        string
+	Value      string
+	Author     string
+	UpdatedAt  time.Time
+}
+
+// ProfileView is the latest visit of someone who viewed the account's profile, and what the
+// reciprocity workflow made of it
+type ProfileView struct {
+	ProfileURL string
+	Name       string
+	Headline   string
+	ViewedAt   time.Time // When LinkedIn says the visit was, to the hour or day it rounds to
+	SeenAt     time.Time // When the visit was first read
+	Outcome    string    // e.g. "queued", or why the viewer was not
+}
+
+// AudienceSnapshot is the size of the account's audience at one time, as its analytics pages
+// showed it
+type AudienceSnapshot struct {
+	TakenAt     time.Time
+	Followers   int
+	Impressions int // Post impressions over the 7 days before TakenAt
+	Subscribers int // Newsletter subscribers, 0 when no newsletter is tracked
+}
+
+// Job is a unit of work in a durable queue. A key is enqueued once per queue; a worker leases
+// the job, and the job is deleted when the worker acknowledges it. A lease not acknowledged by
+// LeasedUntil, e.g. because the process crashed, lapses and the job is leased again.
+type Job struct {
+	ID          int64
+	Queue       string
+	Key         string // Unique within the queue, e.g. a lead's profile URL
+	Payload     string // JSON the worker decodes
+	Attempts    int    // Times the job was leased
+	EnqueuedAt  time.Time
+	AvailableAt time.Time // Not leased before, e.g. while backing off after a failure
+	Lease       string    // Token of the latest lease, empty when the job is not leased
+	LeasedUntil time.Time // When the latest lease lapses; zero if none
+	LastError   string    // Error of the latest failed attempt
+}
+
+// Available reports whether the job can be leased at now: it is due and holds no live lease
+func (j Job) Available(now time.Time) bool {
+	return !j.AvailableAt.After(now) && (j.Lease == "" || !j.LeasedUntil.After(now))
+}
+
+// DeadLetter is a job that failed as many times as its queue allows, kept for review
+type DeadLetter struct {
+	ID          int64
+	Queue       string
+	Key         string
+	Payload     string
+	Attempts    int
+	Error       string // Error of the last attempt
+	Fingerprint string // ID of the last error's fingerprint
+	RunID       string // Run of the last attempt, whose screenshot gallery shows it
+	EnqueuedAt  time.Time
+	FailedAt    time.Time
 }
 
 // StorageConfig contains storage configuration
@@ -65,6 +439,7 @@ type StorageManager struct {
 	config  StorageConfig
 	db      *sql.DB
 	jsonMux sync.RWMutex
+	runID   string // Stamped on rows saved without a run ID
 }
 
 // NewStorageManager creates a new storage manager
@@ -87,6 +462,19 @@ func NewStorageManager(config StorageConfig) (*StorageManager, error) {
 	return sm, nil
 }
 
+// SetRunID sets the run ID stamped on rows saved without one; set it before the run saves anything
+func (sm *StorageManager) SetRunID(runID string) {
+	sm.runID = runID
+}
+
+// stampRunID returns runID, or the current run's ID when it is empty
+func (sm *StorageManager) stampRunID(runID string) string {
+	if runID == "" {
+		return sm.runID
+	}
+	return runID
+}
+
 // initSQLite initializes SQLite database
 func (sm *StorageManager) initSQLite() error {
 	dbPath := filepath.Join(sm.config.Path, sm.config.Database)
@@ -128,17 +516,327 @@ func (sm *StorageManager) initSQLite() error {
 		premium BOOLEAN,
 		timestamp DATETIME NOT NULL
 	);
+
+	CREATE TABLE IF NOT EXISTS connections (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		profile_url TEXT NOT NULL UNIQUE,
+		first_name TEXT,
+		last_name TEXT,
+		email TEXT,
+		company TEXT,
+		position TEXT,
+		connected_on DATETIME,
+		imported_at DATETIME NOT NULL,
+		tracked BOOLEAN NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS saved_searches (
+		name TEXT PRIMARY KEY,
+		query TEXT NOT NULL,
+		max_results INTEGER NOT NULL,
+		interval_ns INTEGER NOT NULL,
+		created_at DATETIME NOT NULL,
+		last_run_at DATETIME,
+		last_found INTEGER NOT NULL DEFAULT 0,
+		last_new INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '',
+		runs INTEGER NOT NULL DEFAULT 0,
+		total_new INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS geo_locations (
+		query TEXT PRIMARY KEY,
+		geo_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		resolved_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS account_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		account TEXT NOT NULL,
+		type TEXT NOT NULL,
+		detail TEXT NOT NULL DEFAULT '',
+		at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS deferred_leads (
+		profile_url TEXT PRIMARY KEY,
+		name TEXT NOT NULL DEFAULT '',
+		reason TEXT NOT NULL DEFAULT '',
+		deferred_at DATETIME NOT NULL,
+		retry_after DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS lead_skips (
+		profile_url TEXT PRIMARY KEY,
+		reason TEXT NOT NULL,
+		skipped_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS search_snapshots (
+		query TEXT PRIMARY KEY,
+		profile_urls TEXT NOT NULL,
+		taken_at DATETIME NOT NULL,
+		runs INTEGER NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS run_summaries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		mode TEXT NOT NULL,
+		started_at DATETIME NOT NULL,
+		finished_at DATETIME NOT NULL,
+		status TEXT NOT NULL,
+		attempted INTEGER NOT NULL,
+		sent INTEGER NOT NULL,
+		skipped INTEGER NOT NULL,
+		failed INTEGER NOT NULL,
+		document TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS error_fingerprints (
+		run_id TEXT NOT NULL,
+		fingerprint TEXT NOT NULL,
+		operation TEXT NOT NULL,
+		selector TEXT NOT NULL DEFAULT '',
+		class TEXT NOT NULL,
+		count INTEGER NOT NULL,
+		rate REAL NOT NULL,
+		example TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (run_id, fingerprint)
+	);
+
+	CREATE TABLE IF NOT EXISTS proxy_stats (
+		proxy TEXT PRIMARY KEY,
+		successes INTEGER NOT NULL DEFAULT 0,
+		failures INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '',
+		last_failure_at DATETIME,
+		unhealthy_until DATETIME,
+		last_used_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS health_checks (
+		id INTEGER PRIMARY KEY,
+		token TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS run_sequences (
+		day TEXT PRIMARY KEY,
+		last INTEGER NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS blackouts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		start_at DATETIME NOT NULL,
+		end_at DATETIME NOT NULL,
+		reason TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS api_tokens (
+		name TEXT PRIMARY KEY,
+		hash TEXT NOT NULL,
+		scope TEXT NOT NULL,
+		rate_per_minute INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL,
+		revoked_at DATETIME
+	);
+
+	CREATE TABLE IF NOT EXISTS drafts (
+		id TEXT PRIMARY KEY,
+		campaign TEXT NOT NULL,
+		step_id TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		profile_url TEXT NOT NULL,
+		lead_name TEXT NOT NULL DEFAULT '',
+		subject TEXT NOT NULL DEFAULT '',
+		text TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL,
+		reviewer TEXT NOT NULL DEFAULT '',
+		reason TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL,
+		reviewed_at DATETIME,
+		sent_at DATETIME
+	);
+
+	CREATE TABLE IF NOT EXISTS queued_leads (
+		profile_url TEXT NOT NULL,
+		campaign TEXT NOT NULL,
+		source TEXT NOT NULL DEFAULT '',
+		queued_at DATETIME NOT NULL,
+		PRIMARY KEY (profile_url, campaign)
+	);
+
+	CREATE TABLE IF NOT EXISTS photos (
+		profile_url TEXT PRIMARY KEY,
+		source_url TEXT NOT NULL,
+		hash TEXT NOT NULL,
+		path TEXT NOT NULL,
+		fetched_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS companies (
+		key TEXT PRIMARY KEY,
+		url TEXT NOT NULL DEFAULT '',
+		name TEXT NOT NULL DEFAULT '',
+		size TEXT NOT NULL DEFAULT '',
+		industry TEXT NOT NULL DEFAULT '',
+		headquarters TEXT NOT NULL DEFAULT '',
+		website TEXT NOT NULL DEFAULT '',
+		followers INTEGER NOT NULL DEFAULT 0,
+		fetched_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS replies (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		profile_url TEXT NOT NULL,
+		excerpt TEXT NOT NULL DEFAULT '',
+		replied_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS suppressions (
+		profile_url TEXT PRIMARY KEY,
+		reason TEXT NOT NULL DEFAULT '',
+		account TEXT NOT NULL DEFAULT '',
+		added_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS lead_notes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		profile_url TEXT NOT NULL,
+		text TEXT NOT NULL,
+		author TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS lead_attributes (
+		profile_url TEXT NOT NULL,
+		key TEXT NOT NULL,
+		value TEXT NOT NULL,
+		author TEXT NOT NULL DEFAULT '',
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (profile_url, key)
+	);
+
+	CREATE TABLE IF NOT EXISTS profile_views (
+		profile_url TEXT PRIMARY KEY,
+		name TEXT NOT NULL DEFAULT '',
+		headline TEXT NOT NULL DEFAULT '',
+		viewed_at DATETIME NOT NULL,
+		seen_at DATETIME NOT NULL,
+		outcome TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE TABLE IF NOT EXISTS audience_snapshots (
+		taken_at DATETIME PRIMARY KEY,
+		followers INTEGER NOT NULL DEFAULT 0,
+		impressions INTEGER NOT NULL DEFAULT 0,
+		subscribers INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS jobs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		queue TEXT NOT NULL,
+		key TEXT NOT NULL,
+		payload TEXT NOT NULL DEFAULT '',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		enqueued_at DATETIME NOT NULL,
+		available_at DATETIME NOT NULL,
+		lease TEXT NOT NULL DEFAULT '',
+		leased_until DATETIME,
+		last_error TEXT NOT NULL DEFAULT '',
+		UNIQUE(queue, key)
+	);
+
+	CREATE TABLE IF NOT EXISTS dead_letters (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		queue TEXT NOT NULL,
+		key TEXT NOT NULL,
+		payload TEXT NOT NULL DEFAULT '',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		error TEXT NOT NULL DEFAULT '',
+		fingerprint TEXT NOT NULL DEFAULT '',
+		enqueued_at DATETIME NOT NULL,
+		failed_at DATETIME NOT NULL
+	);
 	`
 
 	if _, err := db.Exec(schema); err != nil {
 		return fmt.Errorf("failed to create schema: %w", err)
 	}
 
+	// Columns added after the original schema
+	for _, column := range []string{"name_confidence", "title_confidence", "company_confidence", "location_confidence"} {
+		if err := sm.addColumnIfMissing("search_results", column, "TEXT NOT NULL DEFAULT ''"); err != nil {
+			return err
+		}
+	}
+	if err := sm.addColumnIfMissing("search_results", "followers", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := sm.addColumnIfMissing("search_results", "photo_url", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := sm.addColumnIfMissing("connections", "tracked", "BOOLEAN NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := sm.addColumnIfMissing("connections", "tags", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := sm.addColumnIfMissing("companies", "keywords", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := sm.addColumnIfMissing("companies", "scanned_at", "DATETIME"); err != nil {
+		return err
+	}
+	if err := sm.addColumnIfMissing("connection_requests", "template", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	for _, table := range []string{"connection_requests", "sent_messages", "search_results", "account_events", "deferred_leads", "lead_skips", "run_summaries", "dead_letters"} {
+		if err := sm.addColumnIfMissing(table, "run_id", "TEXT NOT NULL DEFAULT ''"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addColumnIfMissing adds a column to a table created by an earlier version of the schema
+func (sm *StorageManager) addColumnIfMissing(table, column, definition string) error {
+	rows, err := sm.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid          int
+			name, kind   string
+			notNull, pk  int
+			defaultValue sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &kind, &notNull, &defaultValue, &pk); err != nil {
+			return fmt.Errorf("failed to inspect table %s: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+
+	if _, err := sm.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
+	}
 	return nil
 }
 
-// SaveConnectionRequest saves a connection request
+// SaveConnectionRequest saves a connection request under its canonical profile URL
 func (sm *StorageManager) SaveConnectionRequest(request ConnectionRequest) error {
+	request.ProfileURL = identity.NormalizeProfileURL(request.ProfileURL)
+	request.RunID = sm.stampRunID(request.RunID)
 	if sm.config.Type == "sqlite" {
 		return sm.saveConnectionRequestSQLite(request)
 	}
@@ -146,9 +844,9 @@ func (sm *StorageManager) SaveConnectionRequest(request ConnectionRequest) error
 }
 
 func (sm *StorageManager) saveConnectionRequestSQLite(request ConnectionRequest) error {
-	query := `INSERT INTO connection_requests (profile_url, profile_name, note, sent_at, status) 
-	          VALUES (?, ?, ?, ?, ?)`
-	_, err := sm.db.Exec(query, request.ProfileURL, request.ProfileName, request.Note, request.SentAt, request.Status)
+	query := `INSERT INTO connection_requests (profile_url, profile_name, note, sent_at, status, run_id, template) 
+	          VALUES (?, ?, ?, ?, ?, ?, ?)`
+	_, err := sm.db.Exec(query, request.ProfileURL, request.ProfileName, request.Note, request.SentAt, request.Status, request.RunID, request.Template)
 	if err != nil {
 		return fmt.Errorf("failed to save connection request: %w", err)
 	}
@@ -177,7 +875,7 @@ func (sm *StorageManager) GetSentRequests() ([]ConnectionRequest, error) {
 }
 
 func (sm *StorageManager) getSentRequestsSQLite() ([]ConnectionRequest, error) {
-	query := `SELECT profile_url, profile_name, note, sent_at, status FROM connection_requests ORDER BY sent_at DESC`
+	query := `SELECT profile_url, profile_name, note, sent_at, status, run_id, template FROM connection_requests ORDER BY sent_at DESC`
 	rows, err := sm.db.Query(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query connection requests: %w", err)
@@ -187,7 +885,7 @@ func (sm *StorageManager) getSentRequestsSQLite() ([]ConnectionRequest, error) {
 	var requests []ConnectionRequest
 	for rows.Next() {
 		var req ConnectionRequest
-		if err := rows.Scan(&req.ProfileURL, &req.ProfileName, &req.Note, &req.SentAt, &req.Status); err != nil {
+		if err := rows.Scan(&req.ProfileURL, &req.ProfileName, &req.Note, &req.SentAt, &req.Status, &req.RunID, &req.Template); err != nil {
 			return nil, fmt.Errorf("failed to scan connection request: %w", err)
 		}
 		requests = append(requests, req)
@@ -196,6 +894,40 @@ func (sm *StorageManager) getSentRequestsSQLite() ([]ConnectionRequest, error) {
 	return requests, nil
 }
 
+// UpdateConnectionRequestStatus sets the status of every request sent to the profile; profileURL
+// must be the URL as stored, which is canonical for requests saved by SaveConnectionRequest
+func (sm *StorageManager) UpdateConnectionRequestStatus(profileURL, status string) error {
+	if sm.config.Type == "sqlite" {
+		return sm.updateConnectionRequestStatusSQLite(profileURL, status)
+	}
+	return sm.updateConnectionRequestStatusJSON(profileURL, status)
+}
+
+func (sm *StorageManager) updateConnectionRequestStatusSQLite(profileURL, status string) error {
+	query := `UPDATE connection_requests SET status = ? WHERE profile_url = ?`
+	if _, err := sm.db.Exec(query, status, profileURL); err != nil {
+		return fmt.Errorf("failed to update connection request: %w", err)
+	}
+	return nil
+}
+
+func (sm *StorageManager) updateConnectionRequestStatusJSON(profileURL, status string) error {
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	requests, err := sm.loadConnectionRequestsJSON()
+	if err != nil {
+		return err
+	}
+
+	for i := range requests {
+		if requests[i].ProfileURL == profileURL {
+			requests[i].Status = status
+		}
+	}
+	return sm.writeConnectionRequestsJSON(requests)
+}
+
 func (sm *StorageManager) loadConnectionRequestsJSON() ([]ConnectionRequest, error) {
 	filePath := filepath.Join(sm.config.Path, "connection_requests.json")
 	data, err := os.ReadFile(filePath)
@@ -228,8 +960,10 @@ func (sm *StorageManager) writeConnectionRequestsJSON(requests []ConnectionReque
 	return nil
 }
 
-// SaveMessage saves a sent message
+// SaveMessage saves a sent message under the recipient's canonical profile URL
 func (sm *StorageManager) SaveMessage(message SentMessage) error {
+	message.RecipientURL = identity.NormalizeProfileURL(message.RecipientURL)
+	message.RunID = sm.stampRunID(message.RunID)
 	if sm.config.Type == "sqlite" {
 		return sm.saveMessageSQLite(message)
 	}
@@ -237,9 +971,9 @@ func (sm *StorageManager) SaveMessage(message SentMessage) error {
 }
 
 func (sm *StorageManager) saveMessageSQLite(message SentMessage) error {
-	query := `INSERT INTO sent_messages (recipient_url, template, content, sent_at, response) 
-	          VALUES (?, ?, ?, ?, ?)`
-	_, err := sm.db.Exec(query, message.RecipientURL, message.Template, message.Content, message.SentAt, message.Response)
+	query := `INSERT INTO sent_messages (recipient_url, template, content, sent_at, response, run_id) 
+	          VALUES (?, ?, ?, ?, ?, ?)`
+	_, err := sm.db.Exec(query, message.RecipientURL, message.Template, message.Content, message.SentAt, message.Response, message.RunID)
 	if err != nil {
 		return fmt.Errorf("failed to save message: %w", err)
 	}
@@ -268,7 +1002,7 @@ func (sm *StorageManager) GetMessageHistory() ([]SentMessage, error) {
 }
 
 func (sm *StorageManager) getMessageHistorySQLite() ([]SentMessage, error) {
-	query := `SELECT recipient_url, template, content, sent_at, response FROM sent_messages ORDER BY sent_at DESC`
+	query := `SELECT recipient_url, template, content, sent_at, response, run_id FROM sent_messages ORDER BY sent_at DESC`
 	rows, err := sm.db.Query(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query messages: %w", err)
@@ -278,7 +1012,7 @@ func (sm *StorageManager) getMessageHistorySQLite() ([]SentMessage, error) {
 	var messages []SentMessage
 	for rows.Next() {
 		var msg SentMessage
-		if err := rows.Scan(&msg.RecipientURL, &msg.Template, &msg.Content, &msg.SentAt, &msg.Response); err != nil {
+		if err := rows.Scan(&msg.RecipientURL, &msg.Template, &msg.Content, &msg.SentAt, &msg.Response, &msg.RunID); err != nil {
 			return nil, fmt.Errorf("failed to scan message: %w", err)
 		}
 		messages = append(messages, msg)
@@ -319,8 +1053,16 @@ func (sm *StorageManager) writeMessagesJSON(messages []SentMessage) error {
 	return nil
 }
 
-// SaveSearchResults saves search results
+// SaveSearchResults saves search results under their canonical profile URLs
 func (sm *StorageManager) SaveSearchResults(results []ProfileResult) error {
+	normalized := make([]ProfileResult, len(results))
+	for i, result := range results {
+		result.URL = identity.NormalizeProfileURL(result.URL)
+		result.RunID = sm.stampRunID(result.RunID)
+		normalized[i] = result
+	}
+	results = normalized
+
 	if sm.config.Type == "sqlite" {
 		return sm.saveSearchResultsSQLite(results)
 	}
@@ -335,8 +1077,9 @@ func (sm *StorageManager) saveSearchResultsSQLite(results []ProfileResult) error
 	defer tx.Rollback()
 
 	stmt, err := tx.Prepare(`INSERT OR REPLACE INTO search_results 
-		(url, name, title, company, location, mutual, premium, timestamp) 
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+		(url, name, title, company, location, mutual, premium, timestamp,
+		 name_confidence, title_confidence, company_confidence, location_confidence, run_id, followers, photo_url) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %w", err)
 	}
@@ -344,7 +1087,9 @@ func (sm *StorageManager) saveSearchResultsSQLite(results []ProfileResult) error
 
 	for _, result := range results {
 		_, err := stmt.Exec(result.URL, result.Name, result.Title, result.Company,
-			result.Location, result.Mutual, result.Premium, result.Timestamp)
+			result.Location, result.Mutual, result.Premium, result.Timestamp,
+			result.Confidence.Name, result.Confidence.Title, result.Confidence.Company, result.Confidence.Location, result.RunID,
+			result.Followers, result.PhotoURL)
 		if err != nil {
 			return fmt.Errorf("failed to save search result: %w", err)
 		}
@@ -392,7 +1137,8 @@ func (sm *StorageManager) GetSearchResults() ([]ProfileResult, error) {
 }
 
 func (sm *StorageManager) getSearchResultsSQLite() ([]ProfileResult, error) {
-	query := `SELECT url, name, title, company, location, mutual, premium, timestamp 
+	query := `SELECT url, name, title, company, location, mutual, premium, timestamp,
+	                 name_confidence, title_confidence, company_confidence, location_confidence, run_id, followers, photo_url 
 	          FROM search_results ORDER BY timestamp DESC`
 	rows, err := sm.db.Query(query)
 	if err != nil {
@@ -404,7 +1150,9 @@ func (sm *StorageManager) getSearchResultsSQLite() ([]ProfileResult, error) {
 	for rows.Next() {
 		var result ProfileResult
 		if err := rows.Scan(&result.URL, &result.Name, &result.Title, &result.Company,
-			&result.Location, &result.Mutual, &result.Premium, &result.Timestamp); err != nil {
+			&result.Location, &result.Mutual, &result.Premium, &result.Timestamp,
+			&result.Confidence.Name, &result.Confidence.Title, &result.Confidence.Company, &result.Confidence.Location, &result.RunID,
+			&result.Followers, &result.PhotoURL); err != nil {
 			return nil, fmt.Errorf("failed to scan search result: %w", err)
 		}
 		results = append(results, result)
@@ -445,6 +1193,3157 @@ func (sm *StorageManager) writeSearchResultsJSON(results []ProfileResult) error
 	return nil
 }
 
+// SaveConnections saves imported connections, replacing earlier imports of the same profile
+func (sm *StorageManager) SaveConnections(connections []Connection) error {
+	normalized := make([]Connection, len(connections))
+	for i, connection := range connections {
+		connection.ProfileURL = identity.NormalizeProfileURL(connection.ProfileURL)
+		normalized[i] = connection
+	}
+	connections = normalized
+
+	if sm.config.Type == "sqlite" {
+		return sm.saveConnectionsSQLite(connections)
+	}
+	return sm.saveConnectionsJSON(connections)
+}
+
+func (sm *StorageManager) saveConnectionsSQLite(connections []Connection) error {
+	tx, err := sm.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT OR REPLACE INTO connections 
+		(profile_url, first_name, last_name, email, company, position, connected_on, imported_at, tracked, tags) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, connection := range connections {
+		tags := ""
+		if len(connection.Tags) > 0 {
+			encoded, err := json.Marshal(connection.Tags)
+			if err != nil {
+				return fmt.Errorf("failed to encode connection tags: %w", err)
+			}
+			tags = string(encoded)
+		}
+		_, err := stmt.Exec(connection.ProfileURL, connection.FirstName, connection.LastName, connection.Email,
+			connection.Company, connection.Position, connection.ConnectedOn, connection.ImportedAt, connection.Tracked, tags)
+		if err != nil {
+			return fmt.Errorf("failed to save connection: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (sm *StorageManager) saveConnectionsJSON(connections []Connection) error {
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	existing, err := sm.loadConnectionsJSON()
+	if err != nil {
+		existing = []Connection{}
+	}
+
+	// Deduplicate by profile URL, keeping the latest import
+	urlMap := make(map[string]Connection)
+	for _, c := range existing {
+		urlMap[c.ProfileURL] = c
+	}
+	for _, c := range connections {
+		urlMap[c.ProfileURL] = c
+	}
+
+	merged := make([]Connection, 0, len(urlMap))
+	for _, c := range urlMap {
+		merged = append(merged, c)
+	}
+
+	return sm.writeConnectionsJSON(merged)
+}
+
+// GetConnections retrieves all imported connections
+func (sm *StorageManager) GetConnections() ([]Connection, error) {
+	if sm.config.Type == "sqlite" {
+		return sm.getConnectionsSQLite()
+	}
+	return sm.loadConnectionsJSON()
+}
+
+func (sm *StorageManager) getConnectionsSQLite() ([]Connection, error) {
+	query := `SELECT profile_url, first_name, last_name, email, company, position, connected_on, imported_at, tracked, tags 
+	          FROM connections ORDER BY connected_on DESC`
+	rows, err := sm.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query connections: %w", err)
+	}
+	defer rows.Close()
+
+	var connections []Connection
+	for rows.Next() {
+		var c Connection
+		var tags string
+		if err := rows.Scan(&c.ProfileURL, &c.FirstName, &c.LastName, &c.Email,
+			&c.Company, &c.Position, &c.ConnectedOn, &c.ImportedAt, &c.Tracked, &tags); err != nil {
+			return nil, fmt.Errorf("failed to scan connection: %w", err)
+		}
+		if tags != "" {
+			if err := json.Unmarshal([]byte(tags), &c.Tags); err != nil {
+				return nil, fmt.Errorf("failed to decode tags of connection %s: %w", c.ProfileURL, err)
+			}
+		}
+		connections = append(connections, c)
+	}
+
+	return connections, nil
+}
+
+func (sm *StorageManager) loadConnectionsJSON() ([]Connection, error) {
+	filePath := filepath.Join(sm.config.Path, "connections.json")
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Connection{}, nil
+		}
+		return nil, fmt.Errorf("failed to read connections: %w", err)
+	}
+
+	var connections []Connection
+	if err := json.Unmarshal(data, &connections); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal connections: %w", err)
+	}
+
+	return connections, nil
+}
+
+func (sm *StorageManager) writeConnectionsJSON(connections []Connection) error {
+	filePath := filepath.Join(sm.config.Path, "connections.json")
+	data, err := json.MarshalIndent(connections, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal connections: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write connections: %w", err)
+	}
+
+	return nil
+}
+
+// GetSearchSnapshot retrieves the snapshot for a search and whether one exists
+func (sm *StorageManager) GetSearchSnapshot(query string) (SearchSnapshot, bool, error) {
+	if sm.config.Type == "sqlite" {
+		return sm.getSearchSnapshotSQLite(query)
+	}
+
+	sm.jsonMux.RLock()
+	defer sm.jsonMux.RUnlock()
+
+	snapshots, err := sm.loadSearchSnapshotsJSON()
+	if err != nil {
+		return SearchSnapshot{}, false, err
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.Query == query {
+			return snapshot, true, nil
+		}
+	}
+	return SearchSnapshot{}, false, nil
+}
+
+func (sm *StorageManager) getSearchSnapshotSQLite(query string) (SearchSnapshot, bool, error) {
+	snapshot := SearchSnapshot{Query: query}
+	var profileURLs string
+	err := sm.db.QueryRow(`SELECT profile_urls, taken_at, runs FROM search_snapshots WHERE query = ?`, query).
+		Scan(&profileURLs, &snapshot.TakenAt, &snapshot.Runs)
+	if err == sql.ErrNoRows {
+		return SearchSnapshot{}, false, nil
+	}
+	if err != nil {
+		return SearchSnapshot{}, false, fmt.Errorf("failed to query search snapshot: %w", err)
+	}
+	if err := json.Unmarshal([]byte(profileURLs), &snapshot.ProfileURLs); err != nil {
+		return SearchSnapshot{}, false, fmt.Errorf("failed to unmarshal search snapshot: %w", err)
+	}
+	return snapshot, true, nil
+}
+
+// SaveSearchSnapshot saves a search snapshot, replacing the previous one for the same query
+func (sm *StorageManager) SaveSearchSnapshot(snapshot SearchSnapshot) error {
+	profileURLs := make([]string, len(snapshot.ProfileURLs))
+	for i, profileURL := range snapshot.ProfileURLs {
+		profileURLs[i] = identity.NormalizeProfileURL(profileURL)
+	}
+	snapshot.ProfileURLs = profileURLs
+
+	if sm.config.Type == "sqlite" {
+		return sm.saveSearchSnapshotSQLite(snapshot)
+	}
+	return sm.saveSearchSnapshotJSON(snapshot)
+}
+
+func (sm *StorageManager) saveSearchSnapshotSQLite(snapshot SearchSnapshot) error {
+	profileURLs, err := json.Marshal(snapshot.ProfileURLs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal search snapshot: %w", err)
+	}
+
+	_, err = sm.db.Exec(`INSERT OR REPLACE INTO search_snapshots (query, profile_urls, taken_at, runs) VALUES (?, ?, ?, ?)`,
+		snapshot.Query, string(profileURLs), snapshot.TakenAt, snapshot.Runs)
+	if err != nil {
+		return fmt.Errorf("failed to save search snapshot: %w", err)
+	}
+	return nil
+}
+
+func (sm *StorageManager) saveSearchSnapshotJSON(snapshot SearchSnapshot) error {
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	snapshots, err := sm.loadSearchSnapshotsJSON()
+	if err != nil {
+		snapshots = []SearchSnapshot{}
+	}
+
+	replaced := false
+	for i := range snapshots {
+		if snapshots[i].Query == snapshot.Query {
+			snapshots[i] = snapshot
+			replaced = true
+		}
+	}
+	if !replaced {
+		snapshots = append(snapshots, snapshot)
+	}
+
+	filePath := filepath.Join(sm.config.Path, "search_snapshots.json")
+	data, err := json.MarshalIndent(snapshots, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal search snapshots: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write search snapshots: %w", err)
+	}
+
+	return nil
+}
+
+func (sm *StorageManager) loadSearchSnapshotsJSON() ([]SearchSnapshot, error) {
+	filePath := filepath.Join(sm.config.Path, "search_snapshots.json")
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []SearchSnapshot{}, nil
+		}
+		return nil, fmt.Errorf("failed to read search snapshots: %w", err)
+	}
+
+	var snapshots []SearchSnapshot
+	if err := json.Unmarshal(data, &snapshots); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal search snapshots: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+// SaveSavedSearch creates a saved search or replaces the one with the same name
+func (sm *StorageManager) SaveSavedSearch(search SavedSearch) error {
+	if sm.config.Type == "sqlite" {
+		_, err := sm.db.Exec(`INSERT OR REPLACE INTO saved_searches 
+			(name, query, max_results, interval_ns, created_at, last_run_at, last_found, last_new, last_error, runs, total_new) 
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			search.Name, search.Query, search.MaxResults, int64(search.Interval), search.CreatedAt, search.LastRunAt,
+			search.LastFound, search.LastNew, search.LastError, search.Runs, search.TotalNew)
+		if err != nil {
+			return fmt.Errorf("failed to save saved search: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	searches, err := sm.loadSavedSearchesJSON()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range searches {
+		if searches[i].Name == search.Name {
+			searches[i] = search
+			replaced = true
+		}
+	}
+	if !replaced {
+		searches = append(searches, search)
+	}
+	return sm.writeSavedSearchesJSON(searches)
+}
+
+// GetSavedSearch retrieves a saved search by name and whether it exists
+func (sm *StorageManager) GetSavedSearch(name string) (SavedSearch, bool, error) {
+	searches, err := sm.GetSavedSearches()
+	if err != nil {
+		return SavedSearch{}, false, err
+	}
+	for _, search := range searches {
+		if search.Name == name {
+			return search, true, nil
+		}
+	}
+	return SavedSearch{}, false, nil
+}
+
+// GetSavedSearches retrieves all saved searches ordered by name
+func (sm *StorageManager) GetSavedSearches() ([]SavedSearch, error) {
+	if sm.config.Type == "sqlite" {
+		return sm.getSavedSearchesSQLite()
+	}
+
+	sm.jsonMux.RLock()
+	defer sm.jsonMux.RUnlock()
+
+	searches, err := sm.loadSavedSearchesJSON()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(searches, func(i, j int) bool { return searches[i].Name < searches[j].Name })
+	return searches, nil
+}
+
+func (sm *StorageManager) getSavedSearchesSQLite() ([]SavedSearch, error) {
+	query := `SELECT name, query, max_results, interval_ns, created_at, last_run_at, last_found, last_new, last_error, runs, total_new 
+	          FROM saved_searches ORDER BY name`
+	rows, err := sm.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query saved searches: %w", err)
+	}
+	defer rows.Close()
+
+	var searches []SavedSearch
+	for rows.Next() {
+		var search SavedSearch
+		var interval int64
+		if err := rows.Scan(&search.Name, &search.Query, &search.MaxResults, &interval, &search.CreatedAt, &search.LastRunAt,
+			&search.LastFound, &search.LastNew, &search.LastError, &search.Runs, &search.TotalNew); err != nil {
+			return nil, fmt.Errorf("failed to scan saved search: %w", err)
+		}
+		search.Interval = time.Duration(interval)
+		searches = append(searches, search)
+	}
+
+	return searches, nil
+}
+
+// DeleteSavedSearch removes a saved search; deleting a missing one is not an error
+func (sm *StorageManager) DeleteSavedSearch(name string) error {
+	if sm.config.Type == "sqlite" {
+		if _, err := sm.db.Exec(`DELETE FROM saved_searches WHERE name = ?`, name); err != nil {
+			return fmt.Errorf("failed to delete saved search: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	searches, err := sm.loadSavedSearchesJSON()
+	if err != nil {
+		return err
+	}
+	kept := searches[:0]
+	for _, search := range searches {
+		if search.Name != name {
+			kept = append(kept, search)
+		}
+	}
+	return sm.writeSavedSearchesJSON(kept)
+}
+
+func (sm *StorageManager) loadSavedSearchesJSON() ([]SavedSearch, error) {
+	filePath := filepath.Join(sm.config.Path, "saved_searches.json")
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []SavedSearch{}, nil
+		}
+		return nil, fmt.Errorf("failed to read saved searches: %w", err)
+	}
+
+	var searches []SavedSearch
+	if err := json.Unmarshal(data, &searches); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal saved searches: %w", err)
+	}
+
+	return searches, nil
+}
+
+func (sm *StorageManager) writeSavedSearchesJSON(searches []SavedSearch) error {
+	filePath := filepath.Join(sm.config.Path, "saved_searches.json")
+	data, err := json.MarshalIndent(searches, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal saved searches: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write saved searches: %w", err)
+	}
+
+	return nil
+}
+
+// GetGeoLocation retrieves the cached geo ID for a location name and whether one exists
+func (sm *StorageManager) GetGeoLocation(query string) (GeoLocation, bool, error) {
+	if sm.config.Type == "sqlite" {
+		location := GeoLocation{Query: query}
+		err := sm.db.QueryRow(`SELECT geo_id, name, resolved_at FROM geo_locations WHERE query = ?`, query).
+			Scan(&location.ID, &location.Name, &location.ResolvedAt)
+		if err == sql.ErrNoRows {
+			return GeoLocation{}, false, nil
+		}
+		if err != nil {
+			return GeoLocation{}, false, fmt.Errorf("failed to query geo location: %w", err)
+		}
+		return location, true, nil
+	}
+
+	sm.jsonMux.RLock()
+	defer sm.jsonMux.RUnlock()
+
+	locations, err := sm.loadGeoLocationsJSON()
+	if err != nil {
+		return GeoLocation{}, false, err
+	}
+	for _, location := range locations {
+		if location.Query == query {
+			return location, true, nil
+		}
+	}
+	return GeoLocation{}, false, nil
+}
+
+// SaveGeoLocation caches a resolved location, replacing an earlier entry for the same name
+func (sm *StorageManager) SaveGeoLocation(location GeoLocation) error {
+	if sm.config.Type == "sqlite" {
+		_, err := sm.db.Exec(`INSERT OR REPLACE INTO geo_locations (query, geo_id, name, resolved_at) VALUES (?, ?, ?, ?)`,
+			location.Query, location.ID, location.Name, location.ResolvedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save geo location: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	locations, err := sm.loadGeoLocationsJSON()
+	if err != nil {
+		locations = []GeoLocation{}
+	}
+	replaced := false
+	for i := range locations {
+		if locations[i].Query == location.Query {
+			locations[i] = location
+			replaced = true
+		}
+	}
+	if !replaced {
+		locations = append(locations, location)
+	}
+
+	filePath := filepath.Join(sm.config.Path, "geo_locations.json")
+	data, err := json.MarshalIndent(locations, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal geo locations: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write geo locations: %w", err)
+	}
+
+	return nil
+}
+
+func (sm *StorageManager) loadGeoLocationsJSON() ([]GeoLocation, error) {
+	filePath := filepath.Join(sm.config.Path, "geo_locations.json")
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []GeoLocation{}, nil
+		}
+		return nil, fmt.Errorf("failed to read geo locations: %w", err)
+	}
+
+	var locations []GeoLocation
+	if err := json.Unmarshal(data, &locations); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal geo locations: %w", err)
+	}
+
+	return locations, nil
+}
+
+// SaveAccountEvent appends an event to the account's history
+func (sm *StorageManager) SaveAccountEvent(event AccountEvent) error {
+	event.RunID = sm.stampRunID(event.RunID)
+	if sm.config.Type == "sqlite" {
+		_, err := sm.db.Exec(`INSERT INTO account_events (account, type, detail, at, run_id) VALUES (?, ?, ?, ?, ?)`,
+			event.Account, event.Type, event.Detail, event.At, event.RunID)
+		if err != nil {
+			return fmt.Errorf("failed to save account event: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	events, err := sm.loadAccountEventsJSON()
+	if err != nil {
+		events = []AccountEvent{}
+	}
+	events = append(events, event)
+
+	filePath := filepath.Join(sm.config.Path, "account_events.json")
+	data, err := json.MarshalIndent(events, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal account events: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write account events: %w", err)
+	}
+
+	return nil
+}
+
+// GetAccountEvents retrieves the account's events at or after since, oldest first
+func (sm *StorageManager) GetAccountEvents(account string, since time.Time) ([]AccountEvent, error) {
+	if sm.config.Type == "sqlite" {
+		return sm.getAccountEventsSQLite(account, since)
+	}
+
+	sm.jsonMux.RLock()
+	defer sm.jsonMux.RUnlock()
+
+	events, err := sm.loadAccountEventsJSON()
+	if err != nil {
+		return nil, err
+	}
+	var matching []AccountEvent
+	for _, event := range events {
+		if event.Account == account && !event.At.Before(since) {
+			matching = append(matching, event)
+		}
+	}
+	sort.SliceStable(matching, func(i, j int) bool { return matching[i].At.Before(matching[j].At) })
+	return matching, nil
+}
+
+func (sm *StorageManager) getAccountEventsSQLite(account string, since time.Time) ([]AccountEvent, error) {
+	// Times are compared in Go since stored timestamps keep their zone and do not sort as text
+	rows, err := sm.db.Query(`SELECT type, detail, at, run_id FROM account_events WHERE account = ? ORDER BY id`, account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []AccountEvent
+	for rows.Next() {
+		event := AccountEvent{Account: account}
+		if err := rows.Scan(&event.Type, &event.Detail, &event.At, &event.RunID); err != nil {
+			return nil, fmt.Errorf("failed to scan account event: %w", err)
+		}
+		if !event.At.Before(since) {
+			events = append(events, event)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read account events: %w", err)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At.Before(events[j].At) })
+	return events, nil
+}
+
+func (sm *StorageManager) loadAccountEventsJSON() ([]AccountEvent, error) {
+	filePath := filepath.Join(sm.config.Path, "account_events.json")
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []AccountEvent{}, nil
+		}
+		return nil, fmt.Errorf("failed to read account events: %w", err)
+	}
+
+	var events []AccountEvent
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal account events: %w", err)
+	}
+
+	return events, nil
+}
+
+// SaveDeferredLeads sets leads aside, replacing any earlier deferral of the same profile
+func (sm *StorageManager) SaveDeferredLeads(leads []DeferredLead) error {
+	for i := range leads {
+		leads[i].ProfileURL = identity.NormalizeProfileURL(leads[i].ProfileURL)
+		leads[i].RunID = sm.stampRunID(leads[i].RunID)
+	}
+
+	if sm.config.Type == "sqlite" {
+		tx, err := sm.db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		for _, lead := range leads {
+			_, err := tx.Exec(`INSERT OR REPLACE INTO deferred_leads (profile_url, name, reason, deferred_at, retry_after, run_id) VALUES (?, ?, ?, ?, ?, ?)`,
+				lead.ProfileURL, lead.Name, lead.Reason, lead.DeferredAt, lead.RetryAfter, lead.RunID)
+			if err != nil {
+				return fmt.Errorf("failed to save deferred lead: %w", err)
+			}
+		}
+		return tx.Commit()
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	existing, err := sm.loadDeferredLeadsJSON()
+	if err != nil {
+		existing = []DeferredLead{}
+	}
+	index := make(map[string]int, len(existing))
+	for i, lead := range existing {
+		index[lead.ProfileURL] = i
+	}
+	for _, lead := range leads {
+		if i, ok := index[lead.ProfileURL]; ok {
+			existing[i] = lead
+			continue
+		}
+		index[lead.ProfileURL] = len(existing)
+		existing = append(existing, lead)
+	}
+
+	filePath := filepath.Join(sm.config.Path, "deferred_leads.json")
+	data, err := json.MarshalIndent(existing, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal deferred leads: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write deferred leads: %w", err)
+	}
+
+	return nil
+}
+
+// GetDeferredLeads retrieves every deferred lead, including those whose retry time has passed
+func (sm *StorageManager) GetDeferredLeads() ([]DeferredLead, error) {
+	if sm.config.Type == "sqlite" {
+		rows, err := sm.db.Query(`SELECT profile_url, name, reason, deferred_at, retry_after, run_id FROM deferred_leads ORDER BY deferred_at`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query deferred leads: %w", err)
+		}
+		defer rows.Close()
+
+		var leads []DeferredLead
+		for rows.Next() {
+			var lead DeferredLead
+			if err := rows.Scan(&lead.ProfileURL, &lead.Name, &lead.Reason, &lead.DeferredAt, &lead.RetryAfter, &lead.RunID); err != nil {
+				return nil, fmt.Errorf("failed to scan deferred lead: %w", err)
+			}
+			leads = append(leads, lead)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read deferred leads: %w", err)
+		}
+		return leads, nil
+	}
+
+	sm.jsonMux.RLock()
+	defer sm.jsonMux.RUnlock()
+
+	return sm.loadDeferredLeadsJSON()
+}
+
+// DeleteDeferredLead puts a deferred lead back in line, e.g. when a snooze is lifted early
+func (sm *StorageManager) DeleteDeferredLead(profileURL string) error {
+	profileURL = identity.NormalizeProfileURL(profileURL)
+	if sm.config.Type == "sqlite" {
+		if _, err := sm.db.Exec(`DELETE FROM deferred_leads WHERE profile_url = ?`, profileURL); err != nil {
+			return fmt.Errorf("failed to delete deferred lead: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	leads, err := sm.loadDeferredLeadsJSON()
+	if err != nil {
+		return err
+	}
+	kept := leads[:0]
+	for _, lead := range leads {
+		if lead.ProfileURL != profileURL {
+			kept = append(kept, lead)
+		}
+	}
+	data, err := json.MarshalIndent(kept, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal deferred leads: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(sm.config.Path, "deferred_leads.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write deferred leads: %w", err)
+	}
+	return nil
+}
+
+func (sm *StorageManager) loadDeferredLeadsJSON() ([]DeferredLead, error) {
+	filePath := filepath.Join(sm.config.Path, "deferred_leads.json")
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []DeferredLead{}, nil
+		}
+		return nil, fmt.Errorf("failed to read deferred leads: %w", err)
+	}
+
+	var leads []DeferredLead
+	if err := json.Unmarshal(data, &leads); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal deferred leads: %w", err)
+	}
+
+	return leads, nil
+}
+
+// SaveLeadSkip records why a lead was skipped, replacing an earlier reason for the same profile
+func (sm *StorageManager) SaveLeadSkip(skip LeadSkip) error {
+	skip.ProfileURL = identity.NormalizeProfileURL(skip.ProfileURL)
+	skip.RunID = sm.stampRunID(skip.RunID)
+
+	if sm.config.Type == "sqlite" {
+		_, err := sm.db.Exec(`INSERT OR REPLACE INTO lead_skips (profile_url, reason, skipped_at, run_id) VALUES (?, ?, ?, ?)`,
+			skip.ProfileURL, skip.Reason, skip.SkippedAt, skip.RunID)
+		if err != nil {
+			return fmt.Errorf("failed to save lead skip: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	skips, err := sm.loadLeadSkipsJSON()
+	if err != nil {
+		skips = []LeadSkip{}
+	}
+	replaced := false
+	for i := range skips {
+		if skips[i].ProfileURL == skip.ProfileURL {
+			skips[i] = skip
+			replaced = true
+		}
+	}
+	if !replaced {
+		skips = append(skips, skip)
+	}
+
+	filePath := filepath.Join(sm.config.Path, "lead_skips.json")
+	data, err := json.MarshalIndent(skips, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal lead skips: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write lead skips: %w", err)
+	}
+
+	return nil
+}
+
+// GetLeadSkips retrieves every recorded lead skip
+func (sm *StorageManager) GetLeadSkips() ([]LeadSkip, error) {
+	if sm.config.Type == "sqlite" {
+		rows, err := sm.db.Query(`SELECT profile_url, reason, skipped_at, run_id FROM lead_skips ORDER BY skipped_at`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query lead skips: %w", err)
+		}
+		defer rows.Close()
+
+		var skips []LeadSkip
+		for rows.Next() {
+			var skip LeadSkip
+			if err := rows.Scan(&skip.ProfileURL, &skip.Reason, &skip.SkippedAt, &skip.RunID); err != nil {
+				return nil, fmt.Errorf("failed to scan lead skip: %w", err)
+			}
+			skips = append(skips, skip)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read lead skips: %w", err)
+		}
+		return skips, nil
+	}
+
+	sm.jsonMux.RLock()
+	defer sm.jsonMux.RUnlock()
+
+	return sm.loadLeadSkipsJSON()
+}
+
+func (sm *StorageManager) loadLeadSkipsJSON() ([]LeadSkip, error) {
+	filePath := filepath.Join(sm.config.Path, "lead_skips.json")
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []LeadSkip{}, nil
+		}
+		return nil, fmt.Errorf("failed to read lead skips: %w", err)
+	}
+
+	var skips []LeadSkip
+	if err := json.Unmarshal(data, &skips); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal lead skips: %w", err)
+	}
+
+	return skips, nil
+}
+
+// SaveRunSummary records a finished run
+func (sm *StorageManager) SaveRunSummary(summary RunSummary) error {
+	summary.RunID = sm.stampRunID(summary.RunID)
+	if sm.config.Type == "sqlite" {
+		_, err := sm.db.Exec(`INSERT INTO run_summaries (run_id, mode, started_at, finished_at, status, attempted, sent, skipped, failed, document)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			summary.RunID, summary.Mode, summary.StartedAt, summary.FinishedAt, summary.Status,
+			summary.Attempted, summary.Sent, summary.Skipped, summary.Failed, summary.Document)
+		if err != nil {
+			return fmt.Errorf("failed to save run summary: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	summaries, err := sm.loadRunSummariesJSON()
+	if err != nil {
+		summaries = []RunSummary{}
+	}
+	summaries = append(summaries, summary)
+
+	filePath := filepath.Join(sm.config.Path, "run_summaries.json")
+	data, err := json.MarshalIndent(summaries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal run summaries: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write run summaries: %w", err)
+	}
+
+	return nil
+}
+
+// GetRunSummaries retrieves every recorded run, oldest first
+func (sm *StorageManager) GetRunSummaries() ([]RunSummary, error) {
+	if sm.config.Type == "sqlite" {
+		rows, err := sm.db.Query(`SELECT run_id, mode, started_at, finished_at, status, attempted, sent, skipped, failed, document
+			FROM run_summaries ORDER BY started_at, id`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query run summaries: %w", err)
+		}
+		defer rows.Close()
+
+		var summaries []RunSummary
+		for rows.Next() {
+			var summary RunSummary
+			if err := rows.Scan(&summary.RunID, &summary.Mode, &summary.StartedAt, &summary.FinishedAt, &summary.Status,
+				&summary.Attempted, &summary.Sent, &summary.Skipped, &summary.Failed, &summary.Document); err != nil {
+				return nil, fmt.Errorf("failed to scan run summary: %w", err)
+			}
+			summaries = append(summaries, summary)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read run summaries: %w", err)
+		}
+		return summaries, nil
+	}
+
+	sm.jsonMux.RLock()
+	defer sm.jsonMux.RUnlock()
+
+	return sm.loadRunSummariesJSON()
+}
+
+func (sm *StorageManager) loadRunSummariesJSON() ([]RunSummary, error) {
+	filePath := filepath.Join(sm.config.Path, "run_summaries.json")
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []RunSummary{}, nil
+		}
+		return nil, fmt.Errorf("failed to read run summaries: %w", err)
+	}
+
+	var summaries []RunSummary
+	if err := json.Unmarshal(data, &summaries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal run summaries: %w", err)
+	}
+
+	return summaries, nil
+}
+
+// SaveErrorFingerprints records the error fingerprints of a run, replacing the counts saved
+// earlier for the same run and fingerprint
+func (sm *StorageManager) SaveErrorFingerprints(fingerprints []ErrorFingerprint) error {
+	for i := range fingerprints {
+		fingerprints[i].RunID = sm.stampRunID(fingerprints[i].RunID)
+	}
+	if sm.config.Type == "sqlite" {
+		for _, fingerprint := range fingerprints {
+			_, err := sm.db.Exec(`INSERT OR REPLACE INTO error_fingerprints (run_id, fingerprint, operation, selector, class, count, rate, example)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+				fingerprint.RunID, fingerprint.Fingerprint, fingerprint.Operation, fingerprint.Selector, fingerprint.Class,
+				fingerprint.Count, fingerprint.Rate, fingerprint.Example)
+			if err != nil {
+				return fmt.Errorf("failed to save error fingerprint: %w", err)
+			}
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	stored, err := sm.loadErrorFingerprintsJSON()
+	if err != nil {
+		stored = []ErrorFingerprint{}
+	}
+	for _, fingerprint := range fingerprints {
+		replaced := false
+		for i := range stored {
+			if stored[i].RunID == fingerprint.RunID && stored[i].Fingerprint == fingerprint.Fingerprint {
+				stored[i] = fingerprint
+				replaced = true
+			}
+		}
+		if !replaced {
+			stored = append(stored, fingerprint)
+		}
+	}
+
+	filePath := filepath.Join(sm.config.Path, "error_fingerprints.json")
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal error fingerprints: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write error fingerprints: %w", err)
+	}
+
+	return nil
+}
+
+// GetErrorFingerprints retrieves the error fingerprints of every run, the most frequent of each
+// run first
+func (sm *StorageManager) GetErrorFingerprints() ([]ErrorFingerprint, error) {
+	if sm.config.Type == "sqlite" {
+		rows, err := sm.db.Query(`SELECT run_id, fingerprint, operation, selector, class, count, rate, example
+			FROM error_fingerprints ORDER BY run_id, count DESC, fingerprint`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query error fingerprints: %w", err)
+		}
+		defer rows.Close()
+
+		var fingerprints []ErrorFingerprint
+		for rows.Next() {
+			var fingerprint ErrorFingerprint
+			if err := rows.Scan(&fingerprint.RunID, &fingerprint.Fingerprint, &fingerprint.Operation, &fingerprint.Selector,
+				&fingerprint.Class, &fingerprint.Count, &fingerprint.Rate, &fingerprint.Example); err != nil {
+				return nil, fmt.Errorf("failed to scan error fingerprint: %w", err)
+			}
+			fingerprints = append(fingerprints, fingerprint)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read error fingerprints: %w", err)
+		}
+		return fingerprints, nil
+	}
+
+	sm.jsonMux.RLock()
+	defer sm.jsonMux.RUnlock()
+
+	fingerprints, err := sm.loadErrorFingerprintsJSON()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(fingerprints, func(i, j int) bool {
+		if fingerprints[i].RunID != fingerprints[j].RunID {
+			return fingerprints[i].RunID < fingerprints[j].RunID
+		}
+		return fingerprints[i].Count > fingerprints[j].Count
+	})
+	return fingerprints, nil
+}
+
+func (sm *StorageManager) loadErrorFingerprintsJSON() ([]ErrorFingerprint, error) {
+	filePath := filepath.Join(sm.config.Path, "error_fingerprints.json")
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []ErrorFingerprint{}, nil
+		}
+		return nil, fmt.Errorf("failed to read error fingerprints: %w", err)
+	}
+
+	var fingerprints []ErrorFingerprint
+	if err := json.Unmarshal(data, &fingerprints); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal error fingerprints: %w", err)
+	}
+
+	return fingerprints, nil
+}
+
+// RecordProxyOutcome counts a success or failure of a proxy. A failure also records its error and
+// when the proxy rejoins the rotation.
+func (sm *StorageManager) RecordProxyOutcome(outcome ProxyOutcome) error {
+	if sm.config.Type == "sqlite" {
+		var err error
+		if outcome.Error == "" {
+			_, err = sm.db.Exec(`INSERT INTO proxy_stats (proxy, successes, last_used_at) VALUES (?, 1, ?)
+				ON CONFLICT(proxy) DO UPDATE SET successes = successes + 1, last_used_at = excluded.last_used_at`,
+				outcome.Proxy, outcome.At)
+		} else {
+			_, err = sm.db.Exec(`INSERT INTO proxy_stats (proxy, failures, last_error, last_failure_at, unhealthy_until, last_used_at)
+				VALUES (?, 1, ?, ?, ?, ?)
+				ON CONFLICT(proxy) DO UPDATE SET failures = failures + 1, last_error = excluded.last_error,
+					last_failure_at = excluded.last_failure_at, unhealthy_until = excluded.unhealthy_until,
+					last_used_at = excluded.last_used_at`,
+				outcome.Proxy, outcome.Error, outcome.At, outcome.UnhealthyUntil, outcome.At)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to record proxy outcome: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	stats, err := sm.loadProxyStatsJSON()
+	if err != nil {
+		stats = []ProxyStats{}
+	}
+	index := -1
+	for i := range stats {
+		if stats[i].Proxy == outcome.Proxy {
+			index = i
+		}
+	}
+	if index < 0 {
+		stats = append(stats, ProxyStats{Proxy: outcome.Proxy})
+		index = len(stats) - 1
+	}
+	stat := &stats[index]
+	stat.LastUsedAt = outcome.At
+	if outcome.Error == "" {
+		stat.Successes++
+	} else {
+		stat.Failures++
+		stat.LastError, stat.LastFailureAt, stat.UnhealthyUntil = outcome.Error, outcome.At, outcome.UnhealthyUntil
+	}
+
+	filePath := filepath.Join(sm.config.Path, "proxy_stats.json")
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal proxy stats: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write proxy stats: %w", err)
+	}
+	return nil
+}
+
+// GetProxyStats retrieves the health of every proxy used so far, by proxy
+func (sm *StorageManager) GetProxyStats() ([]ProxyStats, error) {
+	if sm.config.Type == "sqlite" {
+		rows, err := sm.db.Query(`SELECT proxy, successes, failures, last_error, last_failure_at, unhealthy_until, last_used_at
+			FROM proxy_stats ORDER BY proxy`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query proxy stats: %w", err)
+		}
+		defer rows.Close()
+
+		var stats []ProxyStats
+		for rows.Next() {
+			var stat ProxyStats
+			var lastFailureAt, unhealthyUntil sql.NullTime
+			if err := rows.Scan(&stat.Proxy, &stat.Successes, &stat.Failures, &stat.LastError, &lastFailureAt, &unhealthyUntil, &stat.LastUsedAt); err != nil {
+				return nil, fmt.Errorf("failed to scan proxy stats: %w", err)
+			}
+			stat.LastFailureAt, stat.UnhealthyUntil = lastFailureAt.Time, unhealthyUntil.Time
+			stats = append(stats, stat)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read proxy stats: %w", err)
+		}
+		return stats, nil
+	}
+
+	sm.jsonMux.RLock()
+	defer sm.jsonMux.RUnlock()
+
+	stats, err := sm.loadProxyStatsJSON()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Proxy < stats[j].Proxy })
+	return stats, nil
+}
+
+func (sm *StorageManager) loadProxyStatsJSON() ([]ProxyStats, error) {
+	filePath := filepath.Join(sm.config.Path, "proxy_stats.json")
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []ProxyStats{}, nil
+		}
+		return nil, fmt.Errorf("failed to read proxy stats: %w", err)
+	}
+
+	var stats []ProxyStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal proxy stats: %w", err)
+	}
+	return stats, nil
+}
+
+// NextRunSequence counts another run on day, formatted "2006-01-02", and returns its number from 1
+func (sm *StorageManager) NextRunSequence(day string) (int, error) {
+	if sm.config.Type == "sqlite" {
+		var sequence int
+		err := sm.db.QueryRow(`INSERT INTO run_sequences (day, last) VALUES (?, 1)
+			ON CONFLICT(day) DO UPDATE SET last = last + 1 RETURNING last`, day).Scan(&sequence)
+		if err != nil {
+			return 0, fmt.Errorf("failed to count run: %w", err)
+		}
+		return sequence, nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	filePath := filepath.Join(sm.config.Path, "run_sequences.json")
+	sequences := make(map[string]int)
+	data, err := os.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, fmt.Errorf("failed to read run sequences: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &sequences); err != nil {
+			return 0, fmt.Errorf("failed to unmarshal run sequences: %w", err)
+		}
+	}
+	sequences[day]++
+
+	data, err = json.MarshalIndent(sequences, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal run sequences: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return 0, fmt.Errorf("failed to write run sequences: %w", err)
+	}
+
+	return sequences[day], nil
+}
+
+// GetRunRecords retrieves the summary and every request, message, search result, account event,
+// deferral and skip saved during the run
+func (sm *StorageManager) GetRunRecords(runID string) (RunRecords, error) {
+	records := RunRecords{RunID: runID}
+
+	summaries, err := sm.GetRunSummaries()
+	if err != nil {
+		return records, err
+	}
+	for i := range summaries {
+		if summaries[i].RunID == runID {
+			records.Summary = &summaries[i]
+		}
+	}
+
+	requests, err := sm.GetSentRequests()
+	if err != nil {
+		return records, err
+	}
+	for _, request := range requests {
+		if request.RunID == runID {
+			records.Requests = append(records.Requests, request)
+		}
+	}
+
+	messages, err := sm.GetMessageHistory()
+	if err != nil {
+		return records, err
+	}
+	for _, message := range messages {
+		if message.RunID == runID {
+			records.Messages = append(records.Messages, message)
+		}
+	}
+
+	results, err := sm.GetSearchResults()
+	if err != nil {
+		return records, err
+	}
+	for _, result := range results {
+		if result.RunID == runID {
+			records.Results = append(records.Results, result)
+		}
+	}
+
+	events, err := sm.getAllAccountEvents()
+	if err != nil {
+		return records, err
+	}
+	for _, event := range events {
+		if event.RunID == runID {
+			records.Events = append(records.Events, event)
+		}
+	}
+
+	deferred, err := sm.GetDeferredLeads()
+	if err != nil {
+		return records, err
+	}
+	for _, lead := range deferred {
+		if lead.RunID == runID {
+			records.Deferred = append(records.Deferred, lead)
+		}
+	}
+
+	skips, err := sm.GetLeadSkips()
+	if err != nil {
+		return records, err
+	}
+	for _, skip := range skips {
+		if skip.RunID == runID {
+			records.Skips = append(records.Skips, skip)
+		}
+	}
+
+	fingerprints, err := sm.GetErrorFingerprints()
+	if err != nil {
+		return records, err
+	}
+	for _, fingerprint := range fingerprints {
+		if fingerprint.RunID == runID {
+			records.Errors = append(records.Errors, fingerprint)
+		}
+	}
+
+	return records, nil
+}
+
+// getAllAccountEvents retrieves the events of every account, in the order they were saved
+func (sm *StorageManager) getAllAccountEvents() ([]AccountEvent, error) {
+	if sm.config.Type != "sqlite" {
+		sm.jsonMux.RLock()
+		defer sm.jsonMux.RUnlock()
+		return sm.loadAccountEventsJSON()
+	}
+
+	rows, err := sm.db.Query(`SELECT account, type, detail, at, run_id FROM account_events ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []AccountEvent
+	for rows.Next() {
+		var event AccountEvent
+		if err := rows.Scan(&event.Account, &event.Type, &event.Detail, &event.At, &event.RunID); err != nil {
+			return nil, fmt.Errorf("failed to scan account event: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read account events: %w", err)
+	}
+	return events, nil
+}
+
+// SaveBlackout records an ad-hoc pause
+func (sm *StorageManager) SaveBlackout(blackout Blackout) error {
+	if sm.config.Type == "sqlite" {
+		_, err := sm.db.Exec(`INSERT INTO blackouts (start_at, end_at, reason, created_at) VALUES (?, ?, ?, ?)`,
+			blackout.Start, blackout.End, blackout.Reason, blackout.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save blackout: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	blackouts, err := sm.loadBlackoutsJSON()
+	if err != nil {
+		blackouts = []Blackout{}
+	}
+	blackouts = append(blackouts, blackout)
+	sort.SliceStable(blackouts, func(i, j int) bool { return blackouts[i].Start.Before(blackouts[j].Start) })
+	return sm.writeBlackoutsJSON(blackouts)
+}
+
+// GetBlackouts retrieves every ad-hoc pause, earliest start first
+func (sm *StorageManager) GetBlackouts() ([]Blackout, error) {
+	if sm.config.Type == "sqlite" {
+		rows, err := sm.db.Query(`SELECT start_at, end_at, reason, created_at FROM blackouts ORDER BY start_at, id`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query blackouts: %w", err)
+		}
+		defer rows.Close()
+
+		var blackouts []Blackout
+		for rows.Next() {
+			var blackout Blackout
+			if err := rows.Scan(&blackout.Start, &blackout.End, &blackout.Reason, &blackout.CreatedAt); err != nil {
+				return nil, fmt.Errorf("failed to scan blackout: %w", err)
+			}
+			blackouts = append(blackouts, blackout)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read blackouts: %w", err)
+		}
+		return blackouts, nil
+	}
+
+	sm.jsonMux.RLock()
+	defer sm.jsonMux.RUnlock()
+
+	return sm.loadBlackoutsJSON()
+}
+
+// DeleteBlackouts removes every ad-hoc pause
+func (sm *StorageManager) DeleteBlackouts() error {
+	if sm.config.Type == "sqlite" {
+		if _, err := sm.db.Exec(`DELETE FROM blackouts`); err != nil {
+			return fmt.Errorf("failed to delete blackouts: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	return sm.writeBlackoutsJSON([]Blackout{})
+}
+
+func (sm *StorageManager) loadBlackoutsJSON() ([]Blackout, error) {
+	filePath := filepath.Join(sm.config.Path, "blackouts.json")
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Blackout{}, nil
+		}
+		return nil, fmt.Errorf("failed to read blackouts: %w", err)
+	}
+
+	var blackouts []Blackout
+	if err := json.Unmarshal(data, &blackouts); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal blackouts: %w", err)
+	}
+
+	return blackouts, nil
+}
+
+func (sm *StorageManager) writeBlackoutsJSON(blackouts []Blackout) error {
+	filePath := filepath.Join(sm.config.Path, "blackouts.json")
+	data, err := json.MarshalIndent(blackouts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal blackouts: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write blackouts: %w", err)
+	}
+
+	return nil
+}
+
+// QueueLeads queues leads for campaign runs and returns how many were added. A lead already
+// queued for the same campaign keeps its place.
+func (sm *StorageManager) QueueLeads(leads []QueuedLead) (int, error) {
+	for i := range leads {
+		leads[i].ProfileURL = identity.NormalizeProfileURL(leads[i].ProfileURL)
+	}
+
+	if sm.config.Type == "sqlite" {
+		tx, err := sm.db.Begin()
+		if err != nil {
+			return 0, fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		added := 0
+		for _, lead := range leads {
+			result, err := tx.Exec(`INSERT OR IGNORE INTO queued_leads (profile_url, campaign, source, queued_at) VALUES (?, ?, ?, ?)`,
+				lead.ProfileURL, lead.Campaign, lead.Source, lead.QueuedAt)
+			if err != nil {
+				return 0, fmt.Errorf("failed to queue lead: %w", err)
+			}
+			if rows, _ := result.RowsAffected(); rows > 0 {
+				added++
+			}
+		}
+		return added, tx.Commit()
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	existing, err := sm.loadQueuedLeadsJSON()
+	if err != nil {
+		return 0, err
+	}
+	queued := make(map[[2]string]bool, len(existing))
+	for _, lead := range existing {
+		queued[[2]string{lead.ProfileURL, lead.Campaign}] = true
+	}
+	added := 0
+	for _, lead := range leads {
+		if key := [2]string{lead.ProfileURL, lead.Campaign}; !queued[key] {
+			queued[key] = true
+			existing = append(existing, lead)
+			added++
+		}
+	}
+	return added, sm.writeQueuedLeadsJSON(existing)
+}
+
+// GetQueuedLeads retrieves every queued lead, oldest first
+func (sm *StorageManager) GetQueuedLeads() ([]QueuedLead, error) {
+	if sm.config.Type == "sqlite" {
+		rows, err := sm.db.Query(`SELECT profile_url, campaign, source, queued_at FROM queued_leads ORDER BY queued_at`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query queued leads: %w", err)
+		}
+		defer rows.Close()
+
+		var leads []QueuedLead
+		for rows.Next() {
+			var lead QueuedLead
+			if err := rows.Scan(&lead.ProfileURL, &lead.Campaign, &lead.Source, &lead.QueuedAt); err != nil {
+				return nil, fmt.Errorf("failed to scan queued lead: %w", err)
+			}
+			leads = append(leads, lead)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read queued leads: %w", err)
+		}
+		// Times are sorted in Go since stored timestamps keep their zone and do not sort as text
+		sort.SliceStable(leads, func(i, j int) bool { return leads[i].QueuedAt.Before(leads[j].QueuedAt) })
+		return leads, nil
+	}
+
+	sm.jsonMux.RLock()
+	defer sm.jsonMux.RUnlock()
+
+	leads, err := sm.loadQueuedLeadsJSON()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(leads, func(i, j int) bool { return leads[i].QueuedAt.Before(leads[j].QueuedAt) })
+	return leads, nil
+}
+
+// DeleteQueuedLead removes a lead from a campaign's queue once a run has taken it
+func (sm *StorageManager) DeleteQueuedLead(campaign, profileURL string) error {
+	profileURL = identity.NormalizeProfileURL(profileURL)
+	if sm.config.Type == "sqlite" {
+		if _, err := sm.db.Exec(`DELETE FROM queued_leads WHERE profile_url = ? AND campaign = ?`, profileURL, campaign); err != nil {
+			return fmt.Errorf("failed to delete queued lead: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	leads, err := sm.loadQueuedLeadsJSON()
+	if err != nil {
+		return err
+	}
+	kept := leads[:0]
+	for _, lead := range leads {
+		if lead.ProfileURL != profileURL || lead.Campaign != campaign {
+			kept = append(kept, lead)
+		}
+	}
+	return sm.writeQueuedLeadsJSON(kept)
+}
+
+func (sm *StorageManager) loadQueuedLeadsJSON() ([]QueuedLead, error) {
+	filePath := filepath.Join(sm.config.Path, "queued_leads.json")
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []QueuedLead{}, nil
+		}
+		return nil, fmt.Errorf("failed to read queued leads: %w", err)
+	}
+
+	var leads []QueuedLead
+	if err := json.Unmarshal(data, &leads); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal queued leads: %w", err)
+	}
+
+	return leads, nil
+}
+
+func (sm *StorageManager) writeQueuedLeadsJSON(leads []QueuedLead) error {
+	filePath := filepath.Join(sm.config.Path, "queued_leads.json")
+	data, err := json.MarshalIndent(leads, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal queued leads: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write queued leads: %w", err)
+	}
+
+	return nil
+}
+
+// SaveAPIToken saves a token, replacing any earlier token of the same name
+func (sm *StorageManager) SaveAPIToken(token APIToken) error {
+	if sm.config.Type == "sqlite" {
+		var revokedAt any
+		if !token.RevokedAt.IsZero() {
+			revokedAt = token.RevokedAt
+		}
+		_, err := sm.db.Exec(`INSERT OR REPLACE INTO api_tokens (name, hash, scope, rate_per_minute, created_at, revoked_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			token.Name, token.Hash, token.Scope, token.RatePerMinute, token.CreatedAt, revokedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save API token: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	tokens, err := sm.loadAPITokensJSON()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range tokens {
+		if tokens[i].Name == token.Name {
+			tokens[i], replaced = token, true
+		}
+	}
+	if !replaced {
+		tokens = append(tokens, token)
+	}
+
+	filePath := filepath.Join(sm.config.Path, "api_tokens.json")
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal API tokens: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write API tokens: %w", err)
+	}
+	return nil
+}
+
+// GetAPITokens retrieves every issued token, revoked ones included, by name
+func (sm *StorageManager) GetAPITokens() ([]APIToken, error) {
+	if sm.config.Type == "sqlite" {
+		rows, err := sm.db.Query(`SELECT name, hash, scope, rate_per_minute, created_at, revoked_at FROM api_tokens ORDER BY name`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query API tokens: %w", err)
+		}
+		defer rows.Close()
+
+		var tokens []APIToken
+		for rows.Next() {
+			var token APIToken
+			var revokedAt sql.NullTime
+			if err := rows.Scan(&token.Name, &token.Hash, &token.Scope, &token.RatePerMinute, &token.CreatedAt, &revokedAt); err != nil {
+				return nil, fmt.Errorf("failed to scan API token: %w", err)
+			}
+			token.RevokedAt = revokedAt.Time
+			tokens = append(tokens, token)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read API tokens: %w", err)
+		}
+		return tokens, nil
+	}
+
+	sm.jsonMux.RLock()
+	defer sm.jsonMux.RUnlock()
+
+	tokens, err := sm.loadAPITokensJSON()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].Name < tokens[j].Name })
+	return tokens, nil
+}
+
+func (sm *StorageManager) loadAPITokensJSON() ([]APIToken, error) {
+	filePath := filepath.Join(sm.config.Path, "api_tokens.json")
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []APIToken{}, nil
+		}
+		return nil, fmt.Errorf("failed to read API tokens: %w", err)
+	}
+
+	var tokens []APIToken
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal API tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// SaveDraft saves a draft, replacing any earlier version with the same ID
+func (sm *StorageManager) SaveDraft(draft Draft) error {
+	if sm.config.Type == "sqlite" {
+		var reviewedAt, sentAt any
+		if !draft.ReviewedAt.IsZero() {
+			reviewedAt = draft.ReviewedAt
+		}
+		if !draft.SentAt.IsZero() {
+			sentAt = draft.SentAt
+		}
+		_, err := sm.db.Exec(`INSERT OR REPLACE INTO drafts (id, campaign, step_id, kind, profile_url, lead_name, subject, text, status, reviewer, reason, created_at, reviewed_at, sent_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			draft.ID, draft.Campaign, draft.StepID, draft.Kind, draft.ProfileURL, draft.LeadName, draft.Subject, draft.Text,
+			draft.Status, draft.Reviewer, draft.Reason, draft.CreatedAt, reviewedAt, sentAt)
+		if err != nil {
+			return fmt.Errorf("failed to save draft: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	drafts, err := sm.loadDraftsJSON()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range drafts {
+		if drafts[i].ID == draft.ID {
+			drafts[i], replaced = draft, true
+		}
+	}
+	if !replaced {
+		drafts = append(drafts, draft)
+	}
+
+	filePath := filepath.Join(sm.config.Path, "drafts.json")
+	data, err := json.MarshalIndent(drafts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal drafts: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write drafts: %w", err)
+	}
+	return nil
+}
+
+// GetDrafts retrieves every draft, oldest first
+func (sm *StorageManager) GetDrafts() ([]Draft, error) {
+	if sm.config.Type == "sqlite" {
+		rows, err := sm.db.Query(`SELECT id, campaign, step_id, kind, profile_url, lead_name, subject, text, status, reviewer, reason, created_at, reviewed_at, sent_at
+			FROM drafts ORDER BY created_at, id`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query drafts: %w", err)
+		}
+		defer rows.Close()
+
+		var drafts []Draft
+		for rows.Next() {
+			var draft Draft
+			var reviewedAt, sentAt sql.NullTime
+			if err := rows.Scan(&draft.ID, &draft.Campaign, &draft.StepID, &draft.Kind, &draft.ProfileURL, &draft.LeadName, &draft.Subject, &draft.Text,
+				&draft.Status, &draft.Reviewer, &draft.Reason, &draft.CreatedAt, &reviewedAt, &sentAt); err != nil {
+				return nil, fmt.Errorf("failed to scan draft: %w", err)
+			}
+			draft.ReviewedAt, draft.SentAt = reviewedAt.Time, sentAt.Time
+			drafts = append(drafts, draft)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read drafts: %w", err)
+		}
+		return drafts, nil
+	}
+
+	sm.jsonMux.RLock()
+	defer sm.jsonMux.RUnlock()
+
+	drafts, err := sm.loadDraftsJSON()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(drafts, func(i, j int) bool {
+		if !drafts[i].CreatedAt.Equal(drafts[j].CreatedAt) {
+			return drafts[i].CreatedAt.Before(drafts[j].CreatedAt)
+		}
+		return drafts[i].ID < drafts[j].ID
+	})
+	return drafts, nil
+}
+
+func (sm *StorageManager) loadDraftsJSON() ([]Draft, error) {
+	filePath := filepath.Join(sm.config.Path, "drafts.json")
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Draft{}, nil
+		}
+		return nil, fmt.Errorf("failed to read drafts: %w", err)
+	}
+
+	var drafts []Draft
+	if err := json.Unmarshal(data, &drafts); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal drafts: %w", err)
+	}
+
+	return drafts, nil
+}
+
+// SavePhoto records a profile's downloaded photo, replacing the one recorded before
+func (sm *StorageManager) SavePhoto(photo Photo) error {
+	photo.ProfileURL = identity.NormalizeProfileURL(photo.ProfileURL)
+	if sm.config.Type == "sqlite" {
+		_, err := sm.db.Exec(`INSERT OR REPLACE INTO photos (profile_url, source_url, hash, path, fetched_at) VALUES (?, ?, ?, ?, ?)`,
+			photo.ProfileURL, photo.SourceURL, photo.Hash, photo.Path, photo.FetchedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save photo: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	photos, err := sm.loadPhotosJSON()
+	if err != nil {
+		return err
+	}
+	kept := photos[:0]
+	for _, existing := range photos {
+		if existing.ProfileURL != photo.ProfileURL {
+			kept = append(kept, existing)
+		}
+	}
+	return sm.writePhotosJSON(append(kept, photo))
+}
+
+// GetPhotos retrieves every recorded photo, oldest first
+func (sm *StorageManager) GetPhotos() ([]Photo, error) {
+	if sm.config.Type == "sqlite" {
+		rows, err := sm.db.Query(`SELECT profile_url, source_url, hash, path, fetched_at FROM photos ORDER BY fetched_at, profile_url`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query photos: %w", err)
+		}
+		defer rows.Close()
+
+		var photos []Photo
+		for rows.Next() {
+			var photo Photo
+			if err := rows.Scan(&photo.ProfileURL, &photo.SourceURL, &photo.Hash, &photo.Path, &photo.FetchedAt); err != nil {
+				return nil, fmt.Errorf("failed to scan photo: %w", err)
+			}
+			photos = append(photos, photo)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read photos: %w", err)
+		}
+		return photos, nil
+	}
+
+	sm.jsonMux.RLock()
+	defer sm.jsonMux.RUnlock()
+
+	photos, err := sm.loadPhotosJSON()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(photos, func(i, j int) bool {
+		if !photos[i].FetchedAt.Equal(photos[j].FetchedAt) {
+			return photos[i].FetchedAt.Before(photos[j].FetchedAt)
+		}
+		return photos[i].ProfileURL < photos[j].ProfileURL
+	})
+	return photos, nil
+}
+
+// DeletePhoto forgets a profile's photo; the file itself is left to the caller
+func (sm *StorageManager) DeletePhoto(profileURL string) error {
+	profileURL = identity.NormalizeProfileURL(profileURL)
+	if sm.config.Type == "sqlite" {
+		if _, err := sm.db.Exec(`DELETE FROM photos WHERE profile_url = ?`, profileURL); err != nil {
+			return fmt.Errorf("failed to delete photo: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	photos, err := sm.loadPhotosJSON()
+	if err != nil {
+		return err
+	}
+	kept := photos[:0]
+	for _, photo := range photos {
+		if photo.ProfileURL != profileURL {
+			kept = append(kept, photo)
+		}
+	}
+	return sm.writePhotosJSON(kept)
+}
+
+func (sm *StorageManager) loadPhotosJSON() ([]Photo, error) {
+	filePath := filepath.Join(sm.config.Path, "photos.json")
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Photo{}, nil
+		}
+		return nil, fmt.Errorf("failed to read photos: %w", err)
+	}
+
+	var photos []Photo
+	if err := json.Unmarshal(data, &photos); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal photos: %w", err)
+	}
+	return photos, nil
+}
+
+func (sm *StorageManager) writePhotosJSON(photos []Photo) error {
+	filePath := filepath.Join(sm.config.Path, "photos.json")
+	data, err := json.MarshalIndent(photos, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal photos: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write photos: %w", err)
+	}
+	return nil
+}
+
+// SaveCompany records a company's data, replacing what was recorded for the same key
+func (sm *StorageManager) SaveCompany(company Company) error {
+	if sm.config.Type == "sqlite" {
+		keywords := ""
+		if len(company.Keywords) > 0 {
+			encoded, err := json.Marshal(company.Keywords)
+			if err != nil {
+				return fmt.Errorf("failed to encode company keywords: %w", err)
+			}
+			keywords = string(encoded)
+		}
+		var scannedAt any
+		if !company.ScannedAt.IsZero() {
+			scannedAt = company.ScannedAt
+		}
+		_, err := sm.db.Exec(`INSERT OR REPLACE INTO companies (key, url, name, size, industry, headquarters, website, followers, fetched_at, keywords, scanned_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			company.Key, company.URL, company.Name, company.Size, company.Industry, company.Headquarters, company.Website, company.Followers, company.FetchedAt, keywords, scannedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save company: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	companies, err := sm.loadCompaniesJSON()
+	if err != nil {
+		return err
+	}
+	kept := companies[:0]
+	for _, existing := range companies {
+		if existing.Key != company.Key {
+			kept = append(kept, existing)
+		}
+	}
+	return sm.writeCompaniesJSON(append(kept, company))
+}
+
+// GetCompany retrieves the company recorded under a normalized name
+func (sm *StorageManager) GetCompany(key string) (Company, bool, error) {
+	if sm.config.Type == "sqlite" {
+		company, err := scanCompany(sm.db.QueryRow(`SELECT `+companyColumns+` FROM companies WHERE key = ?`, key))
+		if err == sql.ErrNoRows {
+			return Company{}, false, nil
+		}
+		if err != nil {
+			return Company{}, false, fmt.Errorf("failed to query company: %w", err)
+		}
+		return company, true, nil
+	}
+
+	sm.jsonMux.RLock()
+	defer sm.jsonMux.RUnlock()
+
+	companies, err := sm.loadCompaniesJSON()
+	if err != nil {
+		return Company{}, false, err
+	}
+	for _, company := range companies {
+		if company.Key == key {
+			return company, true, nil
+		}
+	}
+	return Company{}, false, nil
+}
+
+// GetCompanies retrieves every recorded company, by key
+func (sm *StorageManager) GetCompanies() ([]Company, error) {
+	if sm.config.Type == "sqlite" {
+		rows, err := sm.db.Query(`SELECT ` + companyColumns + ` FROM companies ORDER BY key`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query companies: %w", err)
+		}
+		defer rows.Close()
+
+		var companies []Company
+		for rows.Next() {
+			company, err := scanCompany(rows)
+			if err != nil {
+				return nil, fmt.Errorf("failed to scan company: %w", err)
+			}
+			companies = append(companies, company)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read companies: %w", err)
+		}
+		return companies, nil
+	}
+
+	sm.jsonMux.RLock()
+	defer sm.jsonMux.RUnlock()
+
+	companies, err := sm.loadCompaniesJSON()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(companies, func(i, j int) bool {
+		return companies[i].Key < companies[j].Key
+	})
+	return companies, nil
+}
+
+// companyColumns are the companies columns scanCompany reads, in order
+const companyColumns = `key, url, name, size, industry, headquarters, website, followers, fetched_at, keywords, scanned_at`
+
+// scanCompany reads a company selected with companyColumns
+func scanCompany(row interface{ Scan(dest ...any) error }) (Company, error) {
+	var company Company
+	var keywords string
+	var scannedAt sql.NullTime
+	if err := row.Scan(&company.Key, &company.URL, &company.Name, &company.Size, &company.Industry, &company.Headquarters,
+		&company.Website, &company.Followers, &company.FetchedAt, &keywords, &scannedAt); err != nil {
+		return Company{}, err
+	}
+	if keywords != "" {
+		if err := json.Unmarshal([]byte(keywords), &company.Keywords); err != nil {
+			return Company{}, fmt.Errorf("failed to decode keywords of company %s: %w", company.Key, err)
+		}
+	}
+	company.ScannedAt = scannedAt.Time
+	return company, nil
+}
+
+func (sm *StorageManager) loadCompaniesJSON() ([]Company, error) {
+	filePath := filepath.Join(sm.config.Path, "companies.json")
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Company{}, nil
+		}
+		return nil, fmt.Errorf("failed to read companies: %w", err)
+	}
+
+	var companies []Company
+	if err := json.Unmarshal(data, &companies); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal companies: %w", err)
+	}
+	return companies, nil
+}
+
+func (sm *StorageManager) writeCompaniesJSON(companies []Company) error {
+	filePath := filepath.Join(sm.config.Path, "companies.json")
+	data, err := json.MarshalIndent(companies, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal companies: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write companies: %w", err)
+	}
+	return nil
+}
+
+// CheckReadWrite writes a token to storage and reads it back, failing if storage cannot be
+// written or returns something else
+func (sm *StorageManager) CheckReadWrite() error {
+	token := strconv.FormatInt(time.Now().UnixNano(), 10)
+	var read string
+	if sm.config.Type == "sqlite" {
+		if _, err := sm.db.Exec(`INSERT OR REPLACE INTO health_checks (id, token) VALUES (1, ?)`, token); err != nil {
+			return fmt.Errorf("failed to write health check: %w", err)
+		}
+		if err := sm.db.QueryRow(`SELECT token FROM health_checks WHERE id = 1`).Scan(&read); err != nil {
+			return fmt.Errorf("failed to read health check: %w", err)
+		}
+	} else {
+		sm.jsonMux.Lock()
+		defer sm.jsonMux.Unlock()
+
+		filePath := filepath.Join(sm.config.Path, "health_check.json")
+		data, err := json.Marshal(token)
+		if err != nil {
+			return fmt.Errorf("failed to marshal health check: %w", err)
+		}
+		if err := os.WriteFile(filePath, data, 0644); err != nil {
+			return fmt.Errorf("failed to write health check: %w", err)
+		}
+		if data, err = os.ReadFile(filePath); err != nil {
+			return fmt.Errorf("failed to read health check: %w", err)
+		}
+		if err := json.Unmarshal(data, &read); err != nil {
+			return fmt.Errorf("failed to unmarshal health check: %w", err)
+		}
+	}
+	if read != token {
+		return fmt.Errorf("health check read back %q, wrote %q", read, token)
+	}
+	return nil
+}
+
+// SaveReply records a lead's reply
+func (sm *StorageManager) SaveReply(reply Reply) error {
+	if sm.config.Type == "sqlite" {
+		_, err := sm.db.Exec(`INSERT INTO replies (profile_url, excerpt, replied_at) VALUES (?, ?, ?)`,
+			reply.ProfileURL, reply.Excerpt, reply.RepliedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save reply: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	replies, err := sm.loadRepliesJSON()
+	if err != nil {
+		replies = []Reply{}
+	}
+	replies = append(replies, reply)
+	sort.SliceStable(replies, func(i, j int) bool { return replies[i].RepliedAt.Before(replies[j].RepliedAt) })
+
+	data, err := json.MarshalIndent(replies, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal replies: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(sm.config.Path, "replies.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write replies: %w", err)
+	}
+	return nil
+}
+
+// GetReplies retrieves every recorded reply, earliest first
+func (sm *StorageManager) GetReplies() ([]Reply, error) {
+	if sm.config.Type == "sqlite" {
+		rows, err := sm.db.Query(`SELECT profile_url, excerpt, replied_at FROM replies ORDER BY replied_at, id`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query replies: %w", err)
+		}
+		defer rows.Close()
+
+		var replies []Reply
+		for rows.Next() {
+			var reply Reply
+			if err := rows.Scan(&reply.ProfileURL, &reply.Excerpt, &reply.RepliedAt); err != nil {
+				return nil, fmt.Errorf("failed to scan reply: %w", err)
+			}
+			replies = append(replies, reply)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read replies: %w", err)
+		}
+		return replies, nil
+	}
+
+	sm.jsonMux.RLock()
+	defer sm.jsonMux.RUnlock()
+
+	return sm.loadRepliesJSON()
+}
+
+func (sm *StorageManager) loadRepliesJSON() ([]Reply, error) {
+	data, err := os.ReadFile(filepath.Join(sm.config.Path, "replies.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Reply{}, nil
+		}
+		return nil, fmt.Errorf("failed to read replies: %w", err)
+	}
+
+	var replies []Reply
+	if err := json.Unmarshal(data, &replies); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal replies: %w", err)
+	}
+	return replies, nil
+}
+
+// SaveSuppression adds a prospect to the suppression list, replacing an earlier entry for them
+func (sm *StorageManager) SaveSuppression(suppression Suppression) error {
+	suppression.ProfileURL = identity.NormalizeProfileURL(suppression.ProfileURL)
+	if sm.config.Type == "sqlite" {
+		_, err := sm.db.Exec(`INSERT OR REPLACE INTO suppressions (profile_url, reason, account, added_at) VALUES (?, ?, ?, ?)`,
+			suppression.ProfileURL, suppression.Reason, suppression.Account, suppression.AddedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save suppression: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	suppressions, err := sm.loadSuppressionsJSON()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range suppressions {
+		if suppressions[i].ProfileURL == suppression.ProfileURL {
+			suppressions[i], replaced = suppression, true
+		}
+	}
+	if !replaced {
+		suppressions = append(suppressions, suppression)
+	}
+	return sm.writeSuppressionsJSON(suppressions)
+}
+
+// GetSuppressions retrieves the suppression list, earliest added first
+func (sm *StorageManager) GetSuppressions() ([]Suppression, error) {
+	var suppressions []Suppression
+	if sm.config.Type == "sqlite" {
+		rows, err := sm.db.Query(`SELECT profile_url, reason, account, added_at FROM suppressions`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query suppressions: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var suppression Suppression
+			if err := rows.Scan(&suppression.ProfileURL, &suppression.Reason, &suppression.Account, &suppression.AddedAt); err != nil {
+				return nil, fmt.Errorf("failed to scan suppression: %w", err)
+			}
+			suppressions = append(suppressions, suppression)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read suppressions: %w", err)
+		}
+	} else {
+		sm.jsonMux.RLock()
+		defer sm.jsonMux.RUnlock()
+
+		var err error
+		if suppressions, err = sm.loadSuppressionsJSON(); err != nil {
+			return nil, err
+		}
+	}
+	// Times are sorted in Go since stored timestamps keep their zone and do not sort as text
+	sort.SliceStable(suppressions, func(i, j int) bool {
+		if !suppressions[i].AddedAt.Equal(suppressions[j].AddedAt) {
+			return suppressions[i].AddedAt.Before(suppressions[j].AddedAt)
+		}
+		return suppressions[i].ProfileURL < suppressions[j].ProfileURL
+	})
+	return suppressions, nil
+}
+
+// DeleteSuppression takes a prospect off the suppression list
+func (sm *StorageManager) DeleteSuppression(profileURL string) error {
+	profileURL = identity.NormalizeProfileURL(profileURL)
+	if sm.config.Type == "sqlite" {
+		if _, err := sm.db.Exec(`DELETE FROM suppressions WHERE profile_url = ?`, profileURL); err != nil {
+			return fmt.Errorf("failed to delete suppression: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	suppressions, err := sm.loadSuppressionsJSON()
+	if err != nil {
+		return err
+	}
+	kept := suppressions[:0]
+	for _, suppression := range suppressions {
+		if suppression.ProfileURL != profileURL {
+			kept = append(kept, suppression)
+		}
+	}
+	return sm.writeSuppressionsJSON(kept)
+}
+
+func (sm *StorageManager) loadSuppressionsJSON() ([]Suppression, error) {
+	data, err := os.ReadFile(filepath.Join(sm.config.Path, "suppressions.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Suppression{}, nil
+		}
+		return nil, fmt.Errorf("failed to read suppressions: %w", err)
+	}
+
+	var suppressions []Suppression
+	if err := json.Unmarshal(data, &suppressions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal suppressions: %w", err)
+	}
+	return suppressions, nil
+}
+
+func (sm *StorageManager) writeSuppressionsJSON(suppressions []Suppression) error {
+	data, err := json.MarshalIndent(suppressions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal suppressions: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(sm.config.Path, "suppressions.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write suppressions: %w", err)
+	}
+	return nil
+}
+
+// SaveLeadNote attaches a note to a lead
+func (sm *StorageManager) SaveLeadNote(note LeadNote) error {
+	note.ProfileURL = identity.NormalizeProfileURL(note.ProfileURL)
+	if sm.config.Type == "sqlite" {
+		_, err := sm.db.Exec(`INSERT INTO lead_notes (profile_url, text, author, created_at) VALUES (?, ?, ?, ?)`,
+			note.ProfileURL, note.Text, note.Author, note.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save lead note: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	notes, err := sm.loadLeadNotesJSON()
+	if err != nil {
+		return err
+	}
+	notes = append(notes, note)
+
+	data, err := json.MarshalIndent(notes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal lead notes: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(sm.config.Path, "lead_notes.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write lead notes: %w", err)
+	}
+	return nil
+}
+
+// GetLeadNotes retrieves every lead note, oldest first
+func (sm *StorageManager) GetLeadNotes() ([]LeadNote, error) {
+	var notes []LeadNote
+	if sm.config.Type == "sqlite" {
+		rows, err := sm.db.Query(`SELECT profile_url, text, author, created_at FROM lead_notes ORDER BY id`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query lead notes: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var note LeadNote
+			if err := rows.Scan(&note.ProfileURL, &note.Text, &note.Author, &note.CreatedAt); err != nil {
+				return nil, fmt.Errorf("failed to scan lead note: %w", err)
+			}
+			notes = append(notes, note)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read lead notes: %w", err)
+		}
+	} else {
+		sm.jsonMux.RLock()
+		defer sm.jsonMux.RUnlock()
+
+		var err error
+		if notes, err = sm.loadLeadNotesJSON(); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(notes, func(i, j int) bool { return notes[i].CreatedAt.Before(notes[j].CreatedAt) })
+	return notes, nil
+}
+
+func (sm *StorageManager) loadLeadNotesJSON() ([]LeadNote, error) {
+	data, err := os.ReadFile(filepath.Join(sm.config.Path, "lead_notes.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []LeadNote{}, nil
+		}
+		return nil, fmt.Errorf("failed to read lead notes: %w", err)
+	}
+
+	var notes []LeadNote
+	if err := json.Unmarshal(data, &notes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal lead notes: %w", err)
+	}
+	return notes, nil
+}
+
+// SaveLeadAttribute sets a custom attribute on a lead, replacing its earlier value
+func (sm *StorageManager) SaveLeadAttribute(attribute LeadAttribute) error {
+	attribute.ProfileURL = identity.NormalizeProfileURL(attribute.ProfileURL)
+	if sm.config.Type == "sqlite" {
+		_, err := sm.db.Exec(`INSERT OR REPLACE INTO lead_attributes (profile_url, key, value, author, updated_at) VALUES (?, ?, ?, ?, ?)`,
+			attribute.ProfileURL, attribute.Key, attribute.Value, attribute.Author, attribute.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save lead attribute: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	attributes, err := sm.loadLeadAttributesJSON()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range attributes {
+		if attributes[i].ProfileURL == attribute.ProfileURL && attributes[i].Key == attribute.Key {
+			attributes[i], replaced = attribute, true
+		}
+	}
+	if !replaced {
+		attributes = append(attributes, attribute)
+	}
+	return sm.writeLeadAttributesJSON(attributes)
+}
+
+// GetLeadAttributes retrieves every custom lead attribute, by profile and key
+func (sm *StorageManager) GetLeadAttributes() ([]LeadAttribute, error) {
+	if sm.config.Type == "sqlite" {
+		rows, err := sm.db.Query(`SELECT profile_url, key, value, author, updated_at FROM lead_attributes ORDER BY profile_url, key`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query lead attributes: %w", err)
+		}
+		defer rows.Close()
+
+		var attributes []LeadAttribute
+		for rows.Next() {
+			var attribute LeadAttribute
+			if err := rows.Scan(&attribute.ProfileURL, &attribute.Key, &attribute.Value, &attribute.Author, &attribute.UpdatedAt); err != nil {
+				return nil, fmt.Errorf("failed to scan lead attribute: %w", err)
+			}
+			attributes = append(attributes, attribute)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read lead attributes: %w", err)
+		}
+		return attributes, nil
+	}
+
+	sm.jsonMux.RLock()
+	defer sm.jsonMux.RUnlock()
+
+	attributes, err := sm.loadLeadAttributesJSON()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(attributes, func(i, j int) bool {
+		if attributes[i].ProfileURL != attributes[j].ProfileURL {
+			return attributes[i].ProfileURL < attributes[j].ProfileURL
+		}
+		return attributes[i].Key < attributes[j].Key
+	})
+	return attributes, nil
+}
+
+// DeleteLeadAttribute removes a custom attribute from a lead
+func (sm *StorageManager) DeleteLeadAttribute(profileURL, key string) error {
+	profileURL = identity.NormalizeProfileURL(profileURL)
+	if sm.config.Type == "sqlite" {
+		if _, err := sm.db.Exec(`DELETE FROM lead_attributes WHERE profile_url = ? AND key = ?`, profileURL, key); err != nil {
+			return fmt.Errorf("failed to delete lead attribute: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	attributes, err := sm.loadLeadAttributesJSON()
+	if err != nil {
+		return err
+	}
+	kept := attributes[:0]
+	for _, attribute := range attributes {
+		if attribute.ProfileURL != profileURL || attribute.Key != key {
+			kept = append(kept, attribute)
+		}
+	}
+	return sm.writeLeadAttributesJSON(kept)
+}
+
+func (sm *StorageManager) loadLeadAttributesJSON() ([]LeadAttribute, error) {
+	data, err := os.ReadFile(filepath.Join(sm.config.Path, "lead_attributes.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []LeadAttribute{}, nil
+		}
+		return nil, fmt.Errorf("failed to read lead attributes: %w", err)
+	}
+
+	var attributes []LeadAttribute
+	if err := json.Unmarshal(data, &attributes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal lead attributes: %w", err)
+	}
+	return attributes, nil
+}
+
+func (sm *StorageManager) writeLeadAttributesJSON(attributes []LeadAttribute) error {
+	data, err := json.MarshalIndent(attributes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal lead attributes: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(sm.config.Path, "lead_attributes.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write lead attributes: %w", err)
+	}
+	return nil
+}
+
+// SaveProfileView records a visit to the account's profile, replacing the viewer's earlier one
+func (sm *StorageManager) SaveProfileView(view ProfileView) error {
+	view.ProfileURL = identity.NormalizeProfileURL(view.ProfileURL)
+	if sm.config.Type == "sqlite" {
+		_, err := sm.db.Exec(`INSERT OR REPLACE INTO profile_views (profile_url, name, headline, viewed_at, seen_at, outcome) VALUES (?, ?, ?, ?, ?, ?)`,
+			view.ProfileURL, view.Name, view.Headline, view.ViewedAt, view.SeenAt, view.Outcome)
+		if err != nil {
+			return fmt.Errorf("failed to save profile view: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	views, err := sm.loadProfileViewsJSON()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range views {
+		if views[i].ProfileURL == view.ProfileURL {
+			views[i], replaced = view, true
+		}
+	}
+	if !replaced {
+		views = append(views, view)
+	}
+	data, err := json.MarshalIndent(views, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal profile views: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(sm.config.Path, "profile_views.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write profile views: %w", err)
+	}
+	return nil
+}
+
+// GetProfileViews retrieves the latest visit of every viewer, most recent first
+func (sm *StorageManager) GetProfileViews() ([]ProfileView, error) {
+	var views []ProfileView
+	if sm.config.Type == "sqlite" {
+		rows, err := sm.db.Query(`SELECT profile_url, name, headline, viewed_at, seen_at, outcome FROM profile_views`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query profile views: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var view ProfileView
+			if err := rows.Scan(&view.ProfileURL, &view.Name, &view.Headline, &view.ViewedAt, &view.SeenAt, &view.Outcome); err != nil {
+				return nil, fmt.Errorf("failed to scan profile view: %w", err)
+			}
+			views = append(views, view)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read profile views: %w", err)
+		}
+	} else {
+		sm.jsonMux.RLock()
+		defer sm.jsonMux.RUnlock()
+
+		var err error
+		if views, err = sm.loadProfileViewsJSON(); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(views, func(i, j int) bool {
+		if !views[i].ViewedAt.Equal(views[j].ViewedAt) {
+			return views[i].ViewedAt.After(views[j].ViewedAt)
+		}
+		return views[i].ProfileURL < views[j].ProfileURL
+	})
+	return views, nil
+}
+
+func (sm *StorageManager) loadProfileViewsJSON() ([]ProfileView, error) {
+	data, err := os.ReadFile(filepath.Join(sm.config.Path, "profile_views.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []ProfileView{}, nil
+		}
+		return nil, fmt.Errorf("failed to read profile views: %w", err)
+	}
+
+	var views []ProfileView
+	if err := json.Unmarshal(data, &views); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal profile views: %w", err)
+	}
+	return views, nil
+}
+
+// SaveAudienceSnapshot records the size of the account's audience
+func (sm *StorageManager) SaveAudienceSnapshot(snapshot AudienceSnapshot) error {
+	if sm.config.Type == "sqlite" {
+		_, err := sm.db.Exec(`INSERT OR REPLACE INTO audience_snapshots (taken_at, followers, impressions, subscribers) VALUES (?, ?, ?, ?)`,
+			snapshot.TakenAt, snapshot.Followers, snapshot.Impressions, snapshot.Subscribers)
+		if err != nil {
+			return fmt.Errorf("failed to save audience snapshot: %w", err)
+		}
+		return nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	snapshots, err := sm.loadAudienceSnapshotsJSON()
+	if err != nil {
+		return err
+	}
+	snapshots = append(snapshots, snapshot)
+	data, err := json.MarshalIndent(snapshots, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal audience snapshots: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(sm.config.Path, "audience_snapshots.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write audience snapshots: %w", err)
+	}
+	return nil
+}
+
+// GetAudienceSnapshots retrieves every audience snapshot, oldest first
+func (sm *StorageManager) GetAudienceSnapshots() ([]AudienceSnapshot, error) {
+	var snapshots []AudienceSnapshot
+	if sm.config.Type == "sqlite" {
+		rows, err := sm.db.Query(`SELECT taken_at, followers, impressions, subscribers FROM audience_snapshots`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query audience snapshots: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var snapshot AudienceSnapshot
+			if err := rows.Scan(&snapshot.TakenAt, &snapshot.Followers, &snapshot.Impressions, &snapshot.Subscribers); err != nil {
+				return nil, fmt.Errorf("failed to scan audience snapshot: %w", err)
+			}
+			snapshots = append(snapshots, snapshot)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read audience snapshots: %w", err)
+		}
+	} else {
+		sm.jsonMux.RLock()
+		defer sm.jsonMux.RUnlock()
+
+		var err error
+		if snapshots, err = sm.loadAudienceSnapshotsJSON(); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool { return snapshots[i].TakenAt.Before(snapshots[j].TakenAt) })
+	return snapshots, nil
+}
+
+func (sm *StorageManager) loadAudienceSnapshotsJSON() ([]AudienceSnapshot, error) {
+	data, err := os.ReadFile(filepath.Join(sm.config.Path, "audience_snapshots.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []AudienceSnapshot{}, nil
+		}
+		return nil, fmt.Errorf("failed to read audience snapshots: %w", err)
+	}
+
+	var snapshots []AudienceSnapshot
+	if err := json.Unmarshal(data, &snapshots); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal audience snapshots: %w", err)
+	}
+	return snapshots, nil
+}
+
+// EnqueueJobs adds jobs to their queues and returns how many were added. A key already in its
+// queue keeps its job, leased or not, and a key dead-lettered from it stays out until the dead
+// letter is gone.
+func (sm *StorageManager) EnqueueJobs(jobs []Job) (int, error) {
+	if sm.config.Type == "sqlite" {
+		tx, err := sm.db.Begin()
+		if err != nil {
+			return 0, fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		added := 0
+		for _, job := range jobs {
+			result, err := tx.Exec(`INSERT OR IGNORE INTO jobs (queue, key, payload, enqueued_at, available_at)
+				SELECT ?, ?, ?, ?, ? WHERE NOT EXISTS (SELECT 1 FROM dead_letters WHERE queue = ? AND key = ?)`,
+				job.Queue, job.Key, job.Payload, job.EnqueuedAt, job.AvailableAt, job.Queue, job.Key)
+			if err != nil {
+				return 0, fmt.Errorf("failed to enqueue job: %w", err)
+			}
+			if rows, _ := result.RowsAffected(); rows > 0 {
+				added++
+			}
+		}
+		return added, tx.Commit()
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	existing, err := sm.loadJobsJSON()
+	if err != nil {
+		return 0, err
+	}
+	letters, err := sm.loadDeadLettersJSON()
+	if err != nil {
+		return 0, err
+	}
+	queued := make(map[[2]string]bool, len(existing)+len(letters))
+	var lastID int64
+	for _, job := range existing {
+		queued[[2]string{job.Queue, job.Key}] = true
+		lastID = max(lastID, job.ID)
+	}
+	for _, letter := range letters {
+		queued[[2]string{letter.Queue, letter.Key}] = true
+	}
+	added := 0
+	for _, job := range jobs {
+		if key := [2]string{job.Queue, job.Key}; !queued[key] {
+			queued[key] = true
+			lastID++
+			existing = append(existing, Job{ID: lastID, Queue: job.Queue, Key: job.Key, Payload: job.Payload, EnqueuedAt: job.EnqueuedAt, AvailableAt: job.AvailableAt})
+			added++
+		}
+	}
+	return added, sm.writeJobsJSON(existing)
+}
+
+// LeaseJob leases the queue's job for key, or its oldest available job when key is empty, under
+// the lease token until now plus visibility. It reports false when no such job is available:
+// there is none, it is not due yet or another lease on it is live.
+func (sm *StorageManager) LeaseJob(queue, key, lease string, now time.Time, visibility time.Duration) (Job, bool, error) {
+	leasedUntil := now.Add(visibility)
+	if sm.config.Type == "sqlite" {
+		jobs, err := sm.queryJobsSQLite(queue, key)
+		if err != nil {
+			return Job{}, false, err
+		}
+		for _, job := range jobs {
+			if !job.Available(now) {
+				continue
+			}
+			// The job is only taken if no other worker leased it since it was read
+			result, err := sm.db.Exec(`UPDATE jobs SET lease = ?, leased_until = ?, attempts = attempts + 1 WHERE id = ? AND lease = ? AND attempts = ?`,
+				lease, leasedUntil, job.ID, job.Lease, job.Attempts)
+			if err != nil {
+				return Job{}, false, fmt.Errorf("failed to lease job: %w", err)
+			}
+			if rows, _ := result.RowsAffected(); rows == 0 {
+				continue
+			}
+			job.Lease, job.LeasedUntil, job.Attempts = lease, leasedUntil, job.Attempts+1
+			return job, true, nil
+		}
+		return Job{}, false, nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	jobs, err := sm.loadJobsJSON()
+	if err != nil {
+		return Job{}, false, err
+	}
+	sortJobs(jobs)
+	for i, job := range jobs {
+		if job.Queue != queue || (key != "" && job.Key != key) || !job.Available(now) {
+			continue
+		}
+		jobs[i].Lease, jobs[i].LeasedUntil, jobs[i].Attempts = lease, leasedUntil, job.Attempts+1
+		if err := sm.writeJobsJSON(jobs); err != nil {
+			return Job{}, false, err
+		}
+		return jobs[i], true, nil
+	}
+	return Job{}, false, nil
+}
+
+// AckJob deletes a job its worker is done with. It reports false when the lease is no longer
+// the job's, since it lapsed and another worker leased the job.
+func (sm *StorageManager) AckJob(id int64, lease string) (bool, error) {
+	if sm.config.Type == "sqlite" {
+		result, err := sm.db.Exec(`DELETE FROM jobs WHERE id = ? AND lease = ?`, id, lease)
+		if err != nil {
+			return false, fmt.Errorf("failed to acknowledge job: %w", err)
+		}
+		rows, _ := result.RowsAffected()
+		return rows > 0, nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	jobs, err := sm.loadJobsJSON()
+	if err != nil {
+		return false, err
+	}
+	kept, found := withoutJob(jobs, id, lease)
+	if !found {
+		return false, nil
+	}
+	return true, sm.writeJobsJSON(kept)
+}
+
+// RetryJob releases a leased job, with its attempts set to attempts, to be leased again from
+// availableAt. It reports false when the lease is no longer the job's.
+func (sm *StorageManager) RetryJob(id int64, lease string, attempts int, availableAt time.Time, lastError string) (bool, error) {
+	if sm.config.Type == "sqlite" {
+		result, err := sm.db.Exec(`UPDATE jobs SET lease = '', leased_until = NULL, attempts = ?, available_at = ?, last_error = ? WHERE id = ? AND lease = ?`,
+			attempts, availableAt, lastError, id, lease)
+		if err != nil {
+			return false, fmt.Errorf("failed to release job: %w", err)
+		}
+		rows, _ := result.RowsAffected()
+		return rows > 0, nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	jobs, err := sm.loadJobsJSON()
+	if err != nil {
+		return false, err
+	}
+	for i, job := range jobs {
+		if job.ID == id && job.Lease == lease {
+			jobs[i].Lease, jobs[i].LeasedUntil, jobs[i].Attempts, jobs[i].AvailableAt, jobs[i].LastError = "", time.Time{}, attempts, availableAt, lastError
+			return true, sm.writeJobsJSON(jobs)
+		}
+	}
+	return false, nil
+}
+
+// DeadLetterJob moves a job that failed for good out of its queue and into the dead letters, in
+// one step. It reports false, keeping both as they are, when the lease is no longer the job's.
+func (sm *StorageManager) DeadLetterJob(id int64, lease string, letter DeadLetter) (bool, error) {
+	letter.RunID = sm.stampRunID(letter.RunID)
+	if sm.config.Type == "sqlite" {
+		tx, err := sm.db.Begin()
+		if err != nil {
+			return false, fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		result, err := tx.Exec(`DELETE FROM jobs WHERE id = ? AND lease = ?`, id, lease)
+		if err != nil {
+			return false, fmt.Errorf("failed to remove dead job: %w", err)
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return false, nil
+		}
+		if _, err := tx.Exec(`INSERT INTO dead_letters (queue, key, payload, attempts, error, fingerprint, run_id, enqueued_at, failed_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			letter.Queue, letter.Key, letter.Payload, letter.Attempts, letter.Error, letter.Fingerprint, letter.RunID, letter.EnqueuedAt, letter.FailedAt); err != nil {
+			return false, fmt.Errorf("failed to save dead letter: %w", err)
+		}
+		return true, tx.Commit()
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	jobs, err := sm.loadJobsJSON()
+	if err != nil {
+		return false, err
+	}
+	kept, found := withoutJob(jobs, id, lease)
+	if !found {
+		return false, nil
+	}
+	letters, err := sm.loadDeadLettersJSON()
+	if err != nil {
+		return false, err
+	}
+	for _, existing := range letters {
+		letter.ID = max(letter.ID, existing.ID)
+	}
+	letter.ID++
+	// The letter is written first: a job left behind by a failed second write is only retried
+	if err := sm.writeDeadLettersJSON(append(letters, letter)); err != nil {
+		return false, err
+	}
+	return true, sm.writeJobsJSON(kept)
+}
+
+// GetJobs retrieves the jobs of a queue, or of every queue when queue is empty, oldest first
+func (sm *StorageManager) GetJobs(queue string) ([]Job, error) {
+	if sm.config.Type == "sqlite" {
+		return sm.queryJobsSQLite(queue, "")
+	}
+
+	sm.jsonMux.RLock()
+	defer sm.jsonMux.RUnlock()
+
+	jobs, err := sm.loadJobsJSON()
+	if err != nil {
+		return nil, err
+	}
+	kept := jobs[:0]
+	for _, job := range jobs {
+		if queue == "" || job.Queue == queue {
+			kept = append(kept, job)
+		}
+	}
+	sortJobs(kept)
+	return kept, nil
+}
+
+// queryJobsSQLite selects the jobs of a queue, or of every queue, and of a key when one is given,
+// oldest first
+func (sm *StorageManager) queryJobsSQLite(queue, key string) ([]Job, error) {
+	rows, err := sm.db.Query(`SELECT id, queue, key, payload, attempts, enqueued_at, available_at, lease, leased_until, last_error FROM jobs
+		WHERE (? = '' OR queue = ?) AND (? = '' OR key = ?)`, queue, queue, key, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []Job
+	for rows.Next() {
+		var job Job
+		var leasedUntil sql.NullTime
+		if err := rows.Scan(&job.ID, &job.Queue, &job.Key, &job.Payload, &job.Attempts, &job.EnqueuedAt, &job.AvailableAt,
+			&job.Lease, &leasedUntil, &job.LastError); err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		job.LeasedUntil = leasedUntil.Time
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read jobs: %w", err)
+	}
+	sortJobs(jobs)
+	return jobs, nil
+}
+
+// sortJobs puts jobs in the order they were enqueued. Times are sorted in Go since stored
+// timestamps keep their zone and do not sort as text.
+func sortJobs(jobs []Job) {
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if !jobs[i].EnqueuedAt.Equal(jobs[j].EnqueuedAt) {
+			return jobs[i].EnqueuedAt.Before(jobs[j].EnqueuedAt)
+		}
+		return jobs[i].ID < jobs[j].ID
+	})
+}
+
+// withoutJob returns jobs without the one with id under lease, and whether it was there
+func withoutJob(jobs []Job, id int64, lease string) ([]Job, bool) {
+	kept, found := jobs[:0], false
+	for _, job := range jobs {
+		if job.ID == id && job.Lease == lease {
+			found = true
+			continue
+		}
+		kept = append(kept, job)
+	}
+	return kept, found
+}
+
+// GetDeadLetters retrieves every dead letter, oldest first
+func (sm *StorageManager) GetDeadLetters() ([]DeadLetter, error) {
+	var letters []DeadLetter
+	if sm.config.Type == "sqlite" {
+		rows, err := sm.db.Query(`SELECT id, queue, key, payload, attempts, error, fingerprint, run_id, enqueued_at, failed_at FROM dead_letters`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query dead letters: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var letter DeadLetter
+			if err := rows.Scan(&letter.ID, &letter.Queue, &letter.Key, &letter.Payload, &letter.Attempts, &letter.Error,
+				&letter.Fingerprint, &letter.RunID, &letter.EnqueuedAt, &letter.FailedAt); err != nil {
+				return nil, fmt.Errorf("failed to scan dead letter: %w", err)
+			}
+			letters = append(letters, letter)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read dead letters: %w", err)
+		}
+	} else {
+		sm.jsonMux.RLock()
+		defer sm.jsonMux.RUnlock()
+
+		var err error
+		if letters, err = sm.loadDeadLettersJSON(); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(letters, func(i, j int) bool { return letters[i].FailedAt.Before(letters[j].FailedAt) })
+	return letters, nil
+}
+
+// UpdateDeadLetterPayload replaces a dead letter's payload, e.g. with parameters a reviewer
+// corrected, and reports whether the letter exists
+func (sm *StorageManager) UpdateDeadLetterPayload(id int64, payload string) (bool, error) {
+	if sm.config.Type == "sqlite" {
+		result, err := sm.db.Exec(`UPDATE dead_letters SET payload = ? WHERE id = ?`, payload, id)
+		if err != nil {
+			return false, fmt.Errorf("failed to update dead letter: %w", err)
+		}
+		rows, _ := result.RowsAffected()
+		return rows > 0, nil
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	letters, err := sm.loadDeadLettersJSON()
+	if err != nil {
+		return false, err
+	}
+	for i := range letters {
+		if letters[i].ID == id {
+			letters[i].Payload = payload
+			return true, sm.writeDeadLettersJSON(letters)
+		}
+	}
+	return false, nil
+}
+
+// RequeueDeadLetters moves the dead letters with the given IDs back into their queues as new
+// jobs, available from now with no attempts made, and returns how many were moved. A key queued
+// again since keeps its job, and the letter is dropped.
+func (sm *StorageManager) RequeueDeadLetters(ids []int64, now time.Time) (int, error) {
+	if sm.config.Type == "sqlite" {
+		tx, err := sm.db.Begin()
+		if err != nil {
+			return 0, fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		moved := 0
+		for _, id := range ids {
+			if _, err := tx.Exec(`INSERT OR IGNORE INTO jobs (queue, key, payload, enqueued_at, available_at, last_error)
+				SELECT queue, key, payload, ?, ?, error FROM dead_letters WHERE id = ?`, now, now, id); err != nil {
+				return 0, fmt.Errorf("failed to requeue dead letter: %w", err)
+			}
+			result, err := tx.Exec(`DELETE FROM dead_letters WHERE id = ?`, id)
+			if err != nil {
+				return 0, fmt.Errorf("failed to remove dead letter: %w", err)
+			}
+			if rows, _ := result.RowsAffected(); rows > 0 {
+				moved++
+			}
+		}
+		return moved, tx.Commit()
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	letters, err := sm.loadDeadLettersJSON()
+	if err != nil {
+		return 0, err
+	}
+	jobs, err := sm.loadJobsJSON()
+	if err != nil {
+		return 0, err
+	}
+	requeue := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		requeue[id] = true
+	}
+	queued := make(map[[2]string]bool, len(jobs))
+	var lastID int64
+	for _, job := range jobs {
+		queued[[2]string{job.Queue, job.Key}] = true
+		lastID = max(lastID, job.ID)
+	}
+	kept, moved := letters[:0], 0
+	for _, letter := range letters {
+		if !requeue[letter.ID] {
+			kept = append(kept, letter)
+			continue
+		}
+		moved++
+		if key := [2]string{letter.Queue, letter.Key}; !queued[key] {
+			queued[key] = true
+			lastID++
+			jobs = append(jobs, Job{ID: lastID, Queue: letter.Queue, Key: letter.Key, Payload: letter.Payload, EnqueuedAt: now, AvailableAt: now, LastError: letter.Error})
+		}
+	}
+	if moved == 0 {
+		return 0, nil
+	}
+	// The jobs are written first: a letter left behind by a failed second write is only requeued twice
+	if err := sm.writeJobsJSON(jobs); err != nil {
+		return 0, err
+	}
+	return moved, sm.writeDeadLettersJSON(kept)
+}
+
+// DeleteDeadLetters discards the dead letters with the given IDs and returns how many there were
+func (sm *StorageManager) DeleteDeadLetters(ids []int64) (int, error) {
+	if sm.config.Type == "sqlite" {
+		tx, err := sm.db.Begin()
+		if err != nil {
+			return 0, fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		deleted := 0
+		for _, id := range ids {
+			result, err := tx.Exec(`DELETE FROM dead_letters WHERE id = ?`, id)
+			if err != nil {
+				return 0, fmt.Errorf("failed to delete dead letter: %w", err)
+			}
+			if rows, _ := result.RowsAffected(); rows > 0 {
+				deleted++
+			}
+		}
+		return deleted, tx.Commit()
+	}
+
+	sm.jsonMux.Lock()
+	defer sm.jsonMux.Unlock()
+
+	letters, err := sm.loadDeadLettersJSON()
+	if err != nil {
+		return 0, err
+	}
+	discard := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		discard[id] = true
+	}
+	kept := letters[:0]
+	for _, letter := range letters {
+		if !discard[letter.ID] {
+			kept = append(kept, letter)
+		}
+	}
+	deleted := len(letters) - len(kept)
+	if deleted == 0 {
+		return 0, nil
+	}
+	return deleted, sm.writeDeadLettersJSON(kept)
+}
+
+func (sm *StorageManager) loadJobsJSON() ([]Job, error) {
+	data, err := os.ReadFile(filepath.Join(sm.config.Path, "jobs.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Job{}, nil
+		}
+		return nil, fmt.Errorf("failed to read jobs: %w", err)
+	}
+
+	var jobs []Job
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+func (sm *StorageManager) writeJobsJSON(jobs []Job) error {
+	data, err := json.MarshalIndent(jobs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal jobs: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(sm.config.Path, "jobs.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write jobs: %w", err)
+	}
+	return nil
+}
+
+func (sm *StorageManager) loadDeadLettersJSON() ([]DeadLetter, error) {
+	data, err := os.ReadFile(filepath.Join(sm.config.Path, "dead_letters.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []DeadLetter{}, nil
+		}
+		return nil, fmt.Errorf("failed to read dead letters: %w", err)
+	}
+
+	var letters []DeadLetter
+	if err := json.Unmarshal(data, &letters); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal dead letters: %w", err)
+	}
+	return letters, nil
+}
+
+func (sm *StorageManager) writeDeadLettersJSON(letters []DeadLetter) error {
+	data, err := json.MarshalIndent(letters, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letters: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(sm.config.Path, "dead_letters.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write dead letters: %w", err)
+	}
+	return nil
+}
+
 // Close closes the storage manager
 func (sm *StorageManager) Close() error {
 	if sm.db != nil {
@@ -0,0 +1,226 @@
+// Package suppression keeps the prospects no account of a deployment may contact, such as the
+// ones who opted out. The list lives in a file synced between machines, in a storage every
+// account shares, or both, and is read again on every check so an entry one account adds holds
+// for the others right away.
+package suppression
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// ErrSuppressed is returned for a prospect on the suppression list
+var ErrSuppressed = errors.New("prospect is on the suppression list")
+
+// Store keeps the shared suppression list
+type Store interface {
+	SaveSuppression(suppression storage.Suppression) error
+	GetSuppressions() ([]storage.Suppression, error)
+	DeleteSuppression(profileURL string) error
+}
+
+// List is the suppression list of a deployment. A nil *List suppresses nobody.
+type List struct {
+	file  string
+	store Store
+
+	mu          sync.Mutex
+	fileModTime time.Time
+	fileSize    int64
+	fileEntries []storage.Suppression
+}
+
+// New returns the list kept in file and store, either of which may be empty; nil when both are
+func New(file string, store Store) *List {
+	if file == "" && store == nil {
+		return nil
+	}
+	return &List{file: file, store: store}
+}
+
+// Check returns an error wrapping ErrSuppressed, with the reason it was added for, when the
+// profile is on the list
+func (l *List) Check(profileURL string) error {
+	entry, found, err := l.Lookup(profileURL)
+	if err != nil {
+		return fmt.Errorf("failed to check the suppression list: %w", err)
+	}
+	if !found {
+		return nil
+	}
+	if entry.Reason != "" {
+		return fmt.Errorf("%w: %s", ErrSuppressed, entry.Reason)
+	}
+	return ErrSuppressed
+}
+
+// Lookup returns the entry of a profile, compared by its identity key
+func (l *List) Lookup(profileURL string) (storage.Suppression, bool, error) {
+	if l == nil {
+		return storage.Suppression{}, false, nil
+	}
+	entries, err := l.Entries()
+	if err != nil {
+		return storage.Suppression{}, false, err
+	}
+	key := identity.ProfileKey(profileURL)
+	for _, entry := range entries {
+		if identity.ProfileKey(entry.ProfileURL) == key {
+			return entry, true, nil
+		}
+	}
+	return storage.Suppression{}, false, nil
+}
+
+// Entries returns the list: the shared storage's entries, then the file's
+func (l *List) Entries() ([]storage.Suppression, error) {
+	if l == nil {
+		return nil, nil
+	}
+	var entries []storage.Suppression
+	if l.store != nil {
+		stored, err := l.store.GetSuppressions()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, stored...)
+	}
+	fileEntries, err := l.readFile()
+	if err != nil {
+		return nil, err
+	}
+	return append(entries, fileEntries...), nil
+}
+
+// Add puts a prospect on the list: in the shared storage when there is one, otherwise at the
+// end of the file
+func (l *List) Add(entry storage.Suppression) error {
+	if l == nil {
+		return fmt.Errorf("no suppression list is configured")
+	}
+	if l.store != nil {
+		return l.store.SaveSuppression(entry)
+	}
+	file, err := os.OpenFile(l.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open suppression file: %w", err)
+	}
+	line := identity.NormalizeProfileURL(entry.ProfileURL)
+	if entry.Reason != "" {
+		line += " " + entry.Reason
+	}
+	if _, err := fmt.Fprintln(file, line); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write suppression file: %w", err)
+	}
+	return file.Close()
+}
+
+// Remove takes a prospect off the shared storage and the file, returning how many entries were
+// removed
+func (l *List) Remove(profileURL string) (int, error) {
+	if l == nil {
+		return 0, fmt.Errorf("no suppression list is configured")
+	}
+	key := identity.ProfileKey(profileURL)
+	removed := 0
+	if l.store != nil {
+		stored, err := l.store.GetSuppressions()
+		if err != nil {
+			return 0, err
+		}
+		for _, entry := range stored {
+			if identity.ProfileKey(entry.ProfileURL) != key {
+				continue
+			}
+			if err := l.store.DeleteSuppression(entry.ProfileURL); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+	}
+	if l.file == "" {
+		return removed, nil
+	}
+
+	data, err := os.ReadFile(l.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return removed, nil
+		}
+		return removed, fmt.Errorf("failed to read suppression file: %w", err)
+	}
+	var kept []string
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if entry, ok := parseLine(line); ok && identity.ProfileKey(entry.ProfileURL) == key {
+			removed++
+			continue
+		}
+		kept = append(kept, line)
+	}
+	if err := os.WriteFile(l.file, []byte(strings.Join(kept, "")), 0644); err != nil {
+		return removed, fmt.Errorf("failed to write suppression file: %w", err)
+	}
+	return removed, nil
+}
+
+// readFile returns the file's entries, reading it again only once it changed; none when there
+// is no file yet
+func (l *List) readFile() ([]storage.Suppression, error) {
+	if l.file == "" {
+		return nil, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	info, err := os.Stat(l.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			l.fileEntries, l.fileModTime, l.fileSize = nil, time.Time{}, 0
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read suppression file: %w", err)
+	}
+	if info.ModTime().Equal(l.fileModTime) && info.Size() == l.fileSize {
+		return l.fileEntries, nil
+	}
+
+	file, err := os.Open(l.file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suppression file: %w", err)
+	}
+	defer file.Close()
+
+	var entries []storage.Suppression
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if entry, ok := parseLine(scanner.Text()); ok {
+			entry.AddedAt = info.ModTime()
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read suppression file: %w", err)
+	}
+	l.fileEntries, l.fileModTime, l.fileSize = entries, info.ModTime(), info.Size()
+	return entries, nil
+}
+
+// parseLine reads a line of the suppression file: a profile URL, optionally followed by the
+// reason. Blank lines and lines starting with # are skipped.
+func parseLine(line string) (storage.Suppression, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return storage.Suppression{}, false
+	}
+	profileURL := strings.Fields(line)[0]
+	return storage.Suppression{ProfileURL: profileURL, Reason: strings.TrimSpace(line[len(profileURL):])}, true
+}
@@ -0,0 +1,73 @@
+package suppression
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/storage"
+)
+
+// TestList tests that prospects in the shared storage and the synced file are suppressed by
+// any variant of their URL, that edits to the file are picked up and that removal clears both
+func TestList(t *testing.T) {
+	dir := t.TempDir()
+	shared, err := storage.NewStorageManager(storage.StorageConfig{Type: "sqlite", Path: dir, Database: "suppression.db"})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer shared.Close()
+	file := filepath.Join(dir, "suppression.txt")
+	if err := os.WriteFile(file, []byte("# Opted out by email\nhttps://www.linkedin.com/in/john-roe   asked by email\n\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	// Another account of the deployment opens the same list
+	list, other := New(file, shared), New(file, shared)
+	if err := other.Add(storage.Suppression{ProfileURL: "https://linkedin.com/in/Jane-Doe/", Reason: "opted out", Account: "acme", AddedAt: time.Now()}); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+	if err := list.Check("https://www.linkedin.com/in/jane-doe?trk=x"); !errors.Is(err, ErrSuppressed) || err.Error() != ErrSuppressed.Error()+": opted out" {
+		t.Errorf("expected jane suppressed with the reason another account gave, got %v", err)
+	}
+	if entry, found, _ := list.Lookup("https://www.linkedin.com/in/john-roe/"); !found || entry.Reason != "asked by email" {
+		t.Errorf("expected john suppressed by the file, got %+v %v", entry, found)
+	}
+	if err := list.Check("https://www.linkedin.com/in/ann"); err != nil {
+		t.Errorf("expected ann not suppressed, got %v", err)
+	}
+
+	// The file is synced from elsewhere
+	if err := os.WriteFile(file, []byte("https://www.linkedin.com/in/john-roe\nhttps://www.linkedin.com/in/ann\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	os.Chtimes(file, time.Now().Add(time.Minute), time.Now().Add(time.Minute))
+	if err := list.Check("https://www.linkedin.com/in/ann/"); !errors.Is(err, ErrSuppressed) {
+		t.Errorf("expected ann suppressed once the file changed, got %v", err)
+	}
+
+	if removed, err := list.Remove("https://www.linkedin.com/in/JANE-DOE"); err != nil || removed != 1 {
+		t.Errorf("expected jane removed from the storage, got %d (%v)", removed, err)
+	}
+	if removed, err := list.Remove("https://www.linkedin.com/in/ann"); err != nil || removed != 1 {
+		t.Errorf("expected ann removed from the file, got %d (%v)", removed, err)
+	}
+	if entries, err := list.Entries(); err != nil || len(entries) != 1 || entries[0].ProfileURL != "https://www.linkedin.com/in/john-roe" {
+		t.Errorf("expected only john left, got %+v (%v)", entries, err)
+	}
+
+	fileOnly := New(filepath.Join(dir, "new.txt"), nil)
+	if err := fileOnly.Add(storage.Suppression{ProfileURL: "https://www.linkedin.com/in/ann", Reason: "unsubscribed"}); err != nil {
+		t.Fatalf("failed to add to the file: %v", err)
+	}
+	if entry, found, _ := fileOnly.Lookup("https://www.linkedin.com/in/ann"); !found || entry.Reason != "unsubscribed" {
+		t.Errorf("expected ann appended to the file, got %+v %v", entry, found)
+	}
+
+	var none *List
+	if err := none.Check("https://www.linkedin.com/in/jane-doe"); err != nil || New("", nil) != nil {
+		t.Errorf("expected no list to suppress nobody, got %v", err)
+	}
+}
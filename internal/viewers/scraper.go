@@ -0,0 +1,85 @@
+package viewers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"linkedin-automation-framework/internal/browser"
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/search"
+	"linkedin-automation-framework/internal/selectors"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// PageURL is LinkedIn's "Who viewed your profile" page
+const PageURL = "https://www.linkedin.com/me/profile-views/"
+
+// Scraper reads the visits listed on the account's "Who viewed your profile" page
+type Scraper struct {
+	page      browser.PageDriver
+	selectors selectors.Set
+}
+
+// NewScraper creates a scraper for the "Who viewed your profile" page, reading each viewer card
+// with set's selectors into a visit
+func NewScraper(page browser.PageDriver, set selectors.Set) *Scraper {
+	return &Scraper{page: page, selectors: set}
+}
+
+// Visits opens the page and returns the visits of viewers who show their profile, with their
+// time worked out from their age at now. Private viewers and cards without an age are left out.
+func (s *Scraper) Visits(ctx context.Context, now time.Time) ([]storage.ProfileView, error) {
+	page := s.page.Context(ctx)
+	if err := page.Navigate(PageURL); err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", PageURL, err)
+	}
+	if err := page.WaitLoad(); err != nil {
+		return nil, fmt.Errorf("failed to wait for %s to load: %w", PageURL, err)
+	}
+
+	var cards []browser.ElementDriver
+	for _, selector := range s.selectors.ViewerCards {
+		if found, err := page.Elements(selector); err == nil && len(found) > 0 {
+			cards = found
+			break
+		}
+	}
+	var visits []storage.ProfileView
+	for _, card := range cards {
+		if visit, ok := s.visit(card, now); ok {
+			visits = append(visits, visit)
+		}
+	}
+	return visits, nil
+}
+
+// visit reads one viewer card
+func (s *Scraper) visit(card browser.ElementDriver, now time.Time) (storage.ProfileView, bool) {
+	var visit storage.ProfileView
+	for _, selector := range s.selectors.ProfileLinks {
+		link, err := card.Element(selector)
+		if err != nil {
+			continue
+		}
+		href, err := link.Attribute("href")
+		if err != nil || href == nil || !search.IsValidLinkedInProfileURL(identity.NormalizeProfileURL(*href)) {
+			continue
+		}
+		visit.ProfileURL = identity.NormalizeProfileURL(*href)
+		if text, err := link.Text(); err == nil {
+			visit.Name = search.CleanText(text)
+		}
+		break
+	}
+	if visit.ProfileURL == "" {
+		return storage.ProfileView{}, false
+	}
+	visit.Headline = search.CleanText(browser.FirstText(card, s.selectors.ViewerHeadline))
+	viewedAt, ok := ParseViewedAgo(browser.FirstText(card, s.selectors.ViewerTime), now)
+	if !ok {
+		return storage.ProfileView{}, false
+	}
+	visit.ViewedAt = viewedAt
+	return visit, true
+}
@@ -0,0 +1,189 @@
+// Package viewers turns visits to the account's profile into leads: people who viewed the profile
+// recently and match the lead filter are queued for a campaign as high-priority leads, so the
+// invite goes out while the visit is fresh.
+package viewers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"linkedin-automation-framework/internal/annotations"
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/leadfilter"
+	"linkedin-automation-framework/internal/search"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// Signal is the inbound signal a queued viewer carries in its signal attribute
+const Signal = "viewed_profile"
+
+// Source is who queued the viewers, as recorded with the queued leads and their attributes
+const Source = "profile-viewers"
+
+// DefaultWithin is how recent a visit must be to be reciprocated when Options sets no window
+const DefaultWithin = 24 * time.Hour
+
+// Outcomes recorded for a visit
+const (
+	OutcomeQueued      = "queued"
+	OutcomeStale       = "stale"        // The visit was longer ago than the window
+	OutcomeNotMatching = "not_matching" // The lead filter turned the viewer down
+)
+
+// Store keeps visits, the leads queued for campaigns and the viewers' attributes
+type Store interface {
+	annotations.Store
+	SaveProfileView(view storage.ProfileView) error
+	GetProfileViews() ([]storage.ProfileView, error)
+	SaveSearchResults(results []storage.ProfileResult) error
+	QueueLeads(leads []storage.QueuedLead) (int, error)
+}
+
+// Options controls which visits Reciprocate queues
+type Options struct {
+	Campaign string        // Campaign whose next run takes the viewers; "" for any campaign
+	Within   time.Duration // How recent a visit must be, DefaultWithin if 0
+	// Exclude returns why a viewer must not be queued, e.g. "already_connected", or "" to go ahead
+	Exclude func(view storage.ProfileView) string
+}
+
+// Report counts what Reciprocate did with the visits read
+type Report struct {
+	Visits   int            // Visits read from the page
+	Known    int            // Visits recorded by an earlier check
+	Queued   []string       // Profile URLs queued
+	Passed   map[string]int // Viewers not queued, by outcome
+	Failures int            // Viewers the lead filter could not evaluate
+}
+
+// Reciprocate records the visits not seen before and queues the viewers who visited within the
+// window and pass the lead filter, marked with the viewed_profile signal so campaigns take them
+// first. A visit counts as new when it is more than the window after the viewer's last one.
+func Reciprocate(ctx context.Context, store Store, filter leadfilter.Filter, visits []storage.ProfileView, options Options, now time.Time) (Report, error) {
+	within := options.Within
+	if within <= 0 {
+		within = DefaultWithin
+	}
+	known, err := store.GetProfileViews()
+	if err != nil {
+		return Report{}, err
+	}
+	last := make(map[string]storage.ProfileView, len(known))
+	for _, view := range known {
+		last[identity.ProfileKey(view.ProfileURL)] = view
+	}
+
+	report := Report{Visits: len(visits), Passed: make(map[string]int)}
+	for _, view := range visits {
+		view.ProfileURL = identity.NormalizeProfileURL(view.ProfileURL)
+		key := identity.ProfileKey(view.ProfileURL)
+		if previous, found := last[key]; found && view.ViewedAt.Sub(previous.ViewedAt) <= within {
+			report.Known++
+			continue
+		}
+		view.SeenAt = now
+		outcome, err := decide(ctx, filter, view, options.Exclude, now.Sub(view.ViewedAt) > within)
+		if err != nil {
+			// The visit is not recorded, so the next check tries it again
+			report.Failures++
+			continue
+		}
+		view.Outcome = outcome
+		if outcome == OutcomeQueued {
+			if err := queue(store, view, options.Campaign, now); err != nil {
+				return report, err
+			}
+			report.Queued = append(report.Queued, view.ProfileURL)
+		} else {
+			report.Passed[outcome]++
+		}
+		if err := store.SaveProfileView(view); err != nil {
+			return report, err
+		}
+		last[key] = view
+	}
+	return report, nil
+}
+
+// decide returns what becomes of a new visit
+func decide(ctx context.Context, filter leadfilter.Filter, view storage.ProfileView, exclude func(storage.ProfileView) string, stale bool) (string, error) {
+	if stale {
+		return OutcomeStale, nil
+	}
+	if exclude != nil {
+		if reason := exclude(view); reason != "" {
+			return reason, nil
+		}
+	}
+	decision, err := filter.Evaluate(ctx, leadfilter.Profile{
+		URL:     view.ProfileURL,
+		Name:    view.Name,
+		Title:   view.Headline,
+		Company: search.CompanyFromHeadline(view.Headline),
+	})
+	if err != nil {
+		return "", err
+	}
+	if !decision.Accept {
+		return OutcomeNotMatching, nil
+	}
+	return OutcomeQueued, nil
+}
+
+// queue stores the viewer as a lead, queues it for the campaign and adds the viewed_profile
+// signal to the lead's signals
+func queue(store Store, view storage.ProfileView, campaignName string, now time.Time) error {
+	result := storage.ProfileResult{
+		URL:       view.ProfileURL,
+		Name:      view.Name,
+		Title:     view.Headline,
+		Company:   search.CompanyFromHeadline(view.Headline),
+		Timestamp: now,
+	}
+	if err := store.SaveSearchResults([]storage.ProfileResult{result}); err != nil {
+		return fmt.Errorf("failed to save viewer: %w", err)
+	}
+	lead := storage.QueuedLead{ProfileURL: view.ProfileURL, Campaign: campaignName, Source: Source, QueuedAt: now}
+	if _, err := store.QueueLeads([]storage.QueuedLead{lead}); err != nil {
+		return fmt.Errorf("failed to queue viewer: %w", err)
+	}
+
+	return annotations.AddSignal(store, view.ProfileURL, Signal, Source, now)
+}
+
+// agoPattern reads the age of a visit such as "Viewed 3h ago", "5 days ago" or "2w"
+var agoPattern = regexp.MustCompile(`(?i)(\d+)\s*(mo|months?|m|mins?|minutes?|h|hrs?|hours?|d|days?|w|wks?|weeks?|y|yrs?|years?)\b`)
+
+// ParseViewedAgo returns when a visit LinkedIn describes by its age was, reporting false for text
+// without an age. "Just now" is now.
+func ParseViewedAgo(text string, now time.Time) (time.Time, bool) {
+	if strings.Contains(strings.ToLower(text), "just now") {
+		return now, true
+	}
+	match := agoPattern.FindStringSubmatch(text)
+	if match == nil {
+		return time.Time{}, false
+	}
+	n, err := strconv.Atoi(match[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	switch unit := strings.ToLower(match[2]); {
+	case strings.HasPrefix(unit, "mo"):
+		return now.AddDate(0, -n, 0), true
+	case strings.HasPrefix(unit, "m"):
+		return now.Add(-time.Duration(n) * time.Minute), true
+	case strings.HasPrefix(unit, "h"):
+		return now.Add(-time.Duration(n) * time.Hour), true
+	case strings.HasPrefix(unit, "d"):
+		return now.AddDate(0, 0, -n), true
+	case strings.HasPrefix(unit, "w"):
+		return now.AddDate(0, 0, -7*n), true
+	default:
+		return now.AddDate(-n, 0, 0), true
+	}
+}
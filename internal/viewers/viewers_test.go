@@ -0,0 +1,131 @@
+package viewers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"linkedin-automation-framework/internal/annotations"
+	"linkedin-automation-framework/internal/browser/browsertest"
+	"linkedin-automation-framework/internal/leadfilter"
+	"linkedin-automation-framework/internal/selectors"
+	"linkedin-automation-framework/internal/storage"
+)
+
+// viewerCard builds a "Who viewed your profile" card; an empty href makes a private viewer
+func viewerCard(href, name, headline, age string) *browsertest.Element {
+	card := browsertest.NewElement(selectors.Desktop.ViewerCards[0])
+	if href != "" {
+		card.Append(browsertest.NewElement("a[href*='/in/']").SetAttribute("href", href).SetText(name))
+	} else {
+		card.Append(browsertest.NewElement("span").SetText(name))
+	}
+	card.Append(browsertest.NewElement(selectors.Desktop.ViewerHeadline[0]).SetText(headline))
+	card.Append(browsertest.NewElement(selectors.Desktop.ViewerTime[0]).SetText(age))
+	return card
+}
+
+// TestScraper tests that visits are read with their time and that private viewers are left out
+func TestScraper(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	page := browsertest.NewPage("https://www.linkedin.com/feed/")
+	page.Route(PageURL, func(page *browsertest.Page) {
+		page.Append(
+			viewerCard("https://www.linkedin.com/in/jane-doe?miniProfileUrn=x", "Jane Doe", "CTO at Acme", "Viewed 3h ago"),
+			viewerCard("", "Someone at Globex", "Engineer", "Viewed 1h ago"),
+			viewerCard("/in/john-roe/", "John Roe", "Recruiter", "Viewed 2d ago"),
+		)
+	})
+
+	visits, err := NewScraper(page, selectors.Desktop).Visits(context.Background(), now)
+	if err != nil {
+		t.Fatalf("failed to read visits: %v", err)
+	}
+	if len(visits) != 2 {
+		t.Fatalf("expected jane's and john's visits, got %+v", visits)
+	}
+	if visits[0].ProfileURL != "https://www.linkedin.com/in/jane-doe/" || visits[0].Name != "Jane Doe" || visits[0].Headline != "CTO at Acme" || !visits[0].ViewedAt.Equal(now.Add(-3*time.Hour)) {
+		t.Errorf("unexpected visit %+v", visits[0])
+	}
+	if !visits[1].ViewedAt.Equal(now.AddDate(0, 0, -2)) {
+		t.Errorf("expected john's visit two days ago, got %v", visits[1].ViewedAt)
+	}
+}
+
+// TestReciprocate tests that recent matching viewers are queued once with the viewed_profile
+// signal, and that stale, excluded and non-matching visits are recorded but not queued
+func TestReciprocate(t *testing.T) {
+	store, err := storage.NewStorageManager(storage.StorageConfig{Type: "sqlite", Path: t.TempDir(), Database: "test.db"})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	if err := annotations.SetAttributes(store, "https://www.linkedin.com/in/jane", map[string]string{"signal": "liked_post"}, "cli", now); err != nil {
+		t.Fatalf("failed to set attribute: %v", err)
+	}
+	visits := []storage.ProfileView{
+		{ProfileURL: "https://www.linkedin.com/in/jane", Name: "Jane Doe", Headline: "Engineering Manager at Acme", ViewedAt: now.Add(-3 * time.Hour)},
+		{ProfileURL: "https://www.linkedin.com/in/john", Name: "John Roe", Headline: "Student", ViewedAt: now.Add(-time.Hour)},
+		{ProfileURL: "https://www.linkedin.com/in/ann", Name: "Ann Lee", Headline: "Engineering Manager at Globex", ViewedAt: now.AddDate(0, 0, -3)},
+		{ProfileURL: "https://www.linkedin.com/in/bob", Name: "Bob Ray", Headline: "Engineering Manager at Initech", ViewedAt: now.Add(-time.Hour)},
+	}
+	options := Options{
+		Campaign: "q4-outreach",
+		Exclude: func(view storage.ProfileView) string {
+			if view.Name == "Bob Ray" {
+				return "already_connected"
+			}
+			return ""
+		},
+	}
+	filter := leadfilter.NewKeywordFilter([]string{"manager"}, leadfilter.DefaultMinScore)
+
+	report, err := Reciprocate(context.Background(), store, filter, visits, options, now)
+	if err != nil {
+		t.Fatalf("failed to reciprocate: %v", err)
+	}
+	if len(report.Queued) != 1 || report.Passed[OutcomeNotMatching] != 1 || report.Passed[OutcomeStale] != 1 || report.Passed["already_connected"] != 1 {
+		t.Fatalf("expected jane queued and the others passed over, got %+v", report)
+	}
+	queued, err := store.GetQueuedLeads()
+	if err != nil || len(queued) != 1 || queued[0].Campaign != "q4-outreach" || queued[0].Source != Source {
+		t.Fatalf("expected jane queued for the campaign, got %+v (%v)", queued, err)
+	}
+	jane, _ := annotations.For(store, "https://www.linkedin.com/in/jane")
+	if jane.Attributes["signal"] != "liked_post,viewed_profile" {
+		t.Errorf("expected the viewed_profile signal added to jane's, got %q", jane.Attributes["signal"])
+	}
+
+	// An hour later the page still lists the same visits, one of them a new visit by ann
+	visits[2].ViewedAt = now.Add(30 * time.Minute)
+	report, err = Reciprocate(context.Background(), store, filter, visits, options, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("failed to reciprocate again: %v", err)
+	}
+	if report.Known != 3 || len(report.Queued) != 1 || report.Queued[0] != "https://www.linkedin.com/in/ann/" {
+		t.Errorf("expected only ann's new visit queued, got %+v", report)
+	}
+}
+
+// TestParseViewedAgo tests reading the age of a visit
+func TestParseViewedAgo(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"Viewed 3h ago":   now.Add(-3 * time.Hour),
+		"Viewed 45m ago":  now.Add(-45 * time.Minute),
+		"5 days ago":      now.AddDate(0, 0, -5),
+		"2w":              now.AddDate(0, 0, -14),
+		"Viewed 2mo ago":  now.AddDate(0, -2, 0),
+		"Viewed just now": now,
+	}
+	for text, expected := range cases {
+		if viewedAt, ok := ParseViewedAgo(text, now); !ok || !viewedAt.Equal(expected) {
+			t.Errorf("ParseViewedAgo(%q) = %v, %v, expected %v", text, viewedAt, ok, expected)
+		}
+	}
+	if _, ok := ParseViewedAgo("Engineer at Acme", now); ok {
+		t.Error("expected text without an age to be refused")
+	}
+}
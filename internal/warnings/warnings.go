@@ -0,0 +1,196 @@
+package warnings
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+
+	"linkedin-automation-framework/internal/health"
+)
+
+// Kind is a LinkedIn warning surface
+type Kind string
+
+const (
+	KindTemporaryRestriction Kind = "temporary_restriction" // Page saying the account is temporarily restricted
+	KindAccountRestricted    Kind = "account_restricted"    // "We've restricted your account" page
+	KindInviteLimit          Kind = "invite_limit"          // Modal saying the weekly invitation limit is reached
+	KindOutOfInvitations     Kind = "out_of_invitations"    // "You're out of invitations" toast
+)
+
+// Restriction reports whether the surface means the account itself is restricted
+func (k Kind) Restriction() bool {
+	return k == KindTemporaryRestriction || k == KindAccountRestricted
+}
+
+// EventType is the account health event the surface is recorded as; restrictions trip the kill-switch
+func (k Kind) EventType() string {
+	if k.Restriction() {
+		return health.EventRestricted
+	}
+	return health.EventWarning
+}
+
+// Surface recognizes one warning. It matches when the URL contains one of URLContains, or when an
+// element matching one of Containers has text containing one of Phrases (or any text if Phrases is empty).
+type Surface struct {
+	Kind        Kind
+	URLContains []string
+	Containers  []string
+	Phrases     []string // Lower-case, with plain apostrophes
+}
+
+// DefaultSurfaces returns detectors for LinkedIn's current warning pages, modals and toasts
+func DefaultSurfaces() []Surface {
+	return []Surface{
+		{
+			Kind:        KindTemporaryRestriction,
+			URLContains: []string{"/checkpoint/rp/"},
+			Containers:  []string{"h1", "h2", "main"},
+			Phrases:     []string{"temporarily restricted", "temporarily limited your account"},
+		},
+		{
+			Kind:       KindAccountRestricted,
+			Containers: []string{"h1", "h2", "main"},
+			Phrases:    []string{"we've restricted your account", "your account has been restricted", "your account is restricted"},
+		},
+		{
+			Kind:       KindInviteLimit,
+			Containers: []string{".artdeco-modal", "[role='dialog']", ".ip-fuse-limit-alert"},
+			Phrases:    []string{"weekly invitation limit", "reached the weekly limit", "invitation limit"},
+		},
+		{
+			Kind:       KindOutOfInvitations,
+			Containers: []string{".artdeco-toast-item", "[role='alert']"},
+			Phrases:    []string{"you're out of invitations", "out of invitations"},
+		},
+	}
+}
+
+// Warning is a surface seen on a page
+type Warning struct {
+	Kind Kind
+	URL  string
+	Text string // Text of the element that matched, empty for URL matches
+	At   time.Time
+}
+
+// Detail describes the warning for logs and account events
+func (w Warning) Detail() string {
+	if w.Text != "" {
+		return fmt.Sprintf("%s: %s", w.Kind, truncate(w.Text, 200))
+	}
+	return fmt.Sprintf("%s at %s", w.Kind, w.URL)
+}
+
+// Page is what detection needs from a browser page
+type Page interface {
+	URL() (string, error)
+	Texts(selector string) ([]string, error) // Text of every element matching selector, without waiting
+}
+
+// Detect returns the first surface present on the page, or nil
+func Detect(page Page, surfaces []Surface) (*Warning, error) {
+	url, err := page.URL()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page URL: %w", err)
+	}
+	lowerURL := strings.ToLower(url)
+
+	for _, surface := range surfaces {
+		for _, part := range surface.URLContains {
+			if strings.Contains(lowerURL, part) {
+				return &Warning{Kind: surface.Kind, URL: url, At: time.Now()}, nil
+			}
+		}
+		for _, container := range surface.Containers {
+			texts, err := page.Texts(container)
+			if err != nil {
+				continue
+			}
+			for _, text := range texts {
+				if matches(normalize(text), surface.Phrases) {
+					return &Warning{Kind: surface.Kind, URL: url, Text: strings.TrimSpace(text), At: time.Now()}, nil
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// ClassifyURL returns the kind of a warning recognized from the URL alone, e.g. where a lost session
+// redirected to, or "" if none matches
+func ClassifyURL(url string, surfaces []Surface) Kind {
+	lower := strings.ToLower(url)
+	for _, surface := range surfaces {
+		for _, part := range surface.URLContains {
+			if strings.Contains(lower, part) {
+				return surface.Kind
+			}
+		}
+	}
+	return ""
+}
+
+// matches reports whether text contains one of phrases, or is non-empty when there are none
+func matches(text string, phrases []string) bool {
+	if len(phrases) == 0 {
+		return strings.TrimSpace(text) != ""
+	}
+	for _, phrase := range phrases {
+		if strings.Contains(text, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize lower-cases text and replaces typographic apostrophes
+func normalize(text string) string {
+	return strings.NewReplacer("’", "'", "‘", "'").Replace(strings.ToLower(text))
+}
+
+// truncate shortens text to at most max runes
+func truncate(text string, max int) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	if len(runes) <= max {
+		return string(runes)
+	}
+	return string(runes[:max]) + "..."
+}
+
+// rodPage reads a Rod page for detection
+type rodPage struct {
+	page *rod.Page
+}
+
+// NewPage adapts a Rod page for Detect
+func NewPage(page *rod.Page) Page {
+	return &rodPage{page: page}
+}
+
+func (p *rodPage) URL() (string, error) {
+	info, err := p.page.Info()
+	if err != nil {
+		return "", err
+	}
+	return info.URL, nil
+}
+
+func (p *rodPage) Texts(selector string) ([]string, error) {
+	elements, err := p.page.Elements(selector)
+	if err != nil {
+		return nil, err
+	}
+	texts := make([]string, 0, len(elements))
+	for _, element := range elements {
+		text, err := element.Text()
+		if err != nil {
+			continue
+		}
+		texts = append(texts, text)
+	}
+	return texts, nil
+}
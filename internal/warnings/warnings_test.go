@@ -0,0 +1,96 @@
+package warnings
+
+import (
+	"testing"
+
+	"linkedin-automation-framework/internal/health"
+)
+
+// fakePage serves fixed element texts per selector
+type fakePage struct {
+	url   string
+	texts map[string][]string
+}
+
+func (p *fakePage) URL() (string, error) { return p.url, nil }
+
+func (p *fakePage) Texts(selector string) ([]string, error) { return p.texts[selector], nil }
+
+// TestDetectWarningSurfaces tests that each surface is classified into its kind
+func TestDetectWarningSurfaces(t *testing.T) {
+	surfaces := DefaultSurfaces()
+	cases := []struct {
+		name string
+		page *fakePage
+		kind Kind
+	}{
+		{
+			name: "temporary restriction page",
+			page: &fakePage{url: "https://www.linkedin.com/checkpoint/rp/temporary-restriction"},
+			kind: KindTemporaryRestriction,
+		},
+		{
+			name: "restricted account heading with a typographic apostrophe",
+			page: &fakePage{url: "https://www.linkedin.com/feed/", texts: map[string][]string{"h1": {"We’ve restricted your account"}}},
+			kind: KindAccountRestricted,
+		},
+		{
+			name: "invite limit modal",
+			page: &fakePage{url: "https://www.linkedin.com/in/someone/", texts: map[string][]string{
+				".artdeco-modal": {"You've reached the weekly invitation limit"},
+			}},
+			kind: KindInviteLimit,
+		},
+		{
+			name: "out of invitations toast",
+			page: &fakePage{url: "https://www.linkedin.com/search/results/people/", texts: map[string][]string{
+				".artdeco-toast-item": {"You're out of invitations for now"},
+			}},
+			kind: KindOutOfInvitations,
+		},
+		{
+			name: "unrelated modal",
+			page: &fakePage{url: "https://www.linkedin.com/in/someone/", texts: map[string][]string{
+				".artdeco-modal": {"Add a note to your invitation?"},
+				"main":           {"A post about restricted stock units"},
+			}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			warning, err := Detect(tc.page, surfaces)
+			if err != nil {
+				t.Fatalf("detection failed: %v", err)
+			}
+			if tc.kind == "" {
+				if warning != nil {
+					t.Fatalf("expected no warning, got %+v", warning)
+				}
+				return
+			}
+			if warning == nil || warning.Kind != tc.kind {
+				t.Fatalf("expected %s, got %+v", tc.kind, warning)
+			}
+			if warning.Detail() == "" {
+				t.Errorf("expected a detail for %s", tc.kind)
+			}
+		})
+	}
+}
+
+// TestKindEventTypes tests that restrictions are recorded for the kill-switch and limits as warnings
+func TestKindEventTypes(t *testing.T) {
+	if KindAccountRestricted.EventType() != health.EventRestricted || KindTemporaryRestriction.EventType() != health.EventRestricted {
+		t.Errorf("expected restrictions to be recorded as %s", health.EventRestricted)
+	}
+	if KindInviteLimit.EventType() != health.EventWarning || KindOutOfInvitations.EventType() != health.EventWarning {
+		t.Errorf("expected invitation limits to be recorded as %s", health.EventWarning)
+	}
+	if ClassifyURL("https://www.linkedin.com/checkpoint/rp/x", DefaultSurfaces()) != KindTemporaryRestriction {
+		t.Errorf("expected the restriction URL to be classified")
+	}
+	if ClassifyURL("https://www.linkedin.com/checkpoint/challenge/x", DefaultSurfaces()) != "" {
+		t.Errorf("expected a plain challenge not to be classified as a warning")
+	}
+}
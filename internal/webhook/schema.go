@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// Schema returns the JSON schema of an event's body: the Envelope for typed events and the
+// Automation payload for EventAutomation
+func Schema(event string) (json.RawMessage, bool) {
+	data, err := schemaFiles.ReadFile("schemas/" + event + ".json")
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// SchemaHandler serves the schemas:
+//
+//	GET /schema          every event's schema, keyed by event
+//	GET /schema/{event}  one event's schema
+func SchemaHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "use GET", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/schema+json")
+
+		event := strings.Trim(strings.TrimPrefix(r.URL.Path, "/schema"), "/")
+		if event != "" {
+			schema, ok := Schema(event)
+			if !ok {
+				http.Error(w, "unknown event "+event, http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(schema)
+			return
+		}
+		all := make(map[string]json.RawMessage)
+		for _, name := range Events() {
+			all[name], _ = Schema(name)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"version": Version, "events": all})
+	})
+}
@@ -0,0 +1,241 @@
+// Package webhook delivers events to external automation tools such as Zapier or Make.
+//
+// Every event is posted as an Envelope whose data follows the event's JSON schema. Endpoints
+// subscribed to EventAutomation instead receive every event as one flat Automation payload,
+// so a single generic trigger can be mapped without knowing each event's shape. The schemas
+// are published by Handler and change only together with Version.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// Version is the payload version; a breaking schema change increments it
+const Version = 1
+
+// Events
+const (
+	EventRunFinished        = "run.finished"        // A connect, message, search or campaign run ended
+	EventConnectionAccepted = "connection.accepted" // Reconciling found a sent request accepted
+	EventAccountEvent       = "account.event"       // A challenge, warning, logout or restriction was recorded
+	EventAutomation         = "automation"          // Catch-all: every event above as a flat Automation payload
+)
+
+// Events lists every event an endpoint can subscribe to
+func Events() []string {
+	return []string{EventRunFinished, EventConnectionAccepted, EventAccountEvent, EventAutomation}
+}
+
+// Envelope is the body posted for a typed event
+type Envelope struct {
+	ID         string    `json:"id"`
+	Event      string    `json:"event"`
+	Version    int       `json:"version"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Account    string    `json:"account"`
+	Data       Payload   `json:"data"`
+}
+
+// Automation is the flat body posted to EventAutomation subscribers. Every field is always
+// present and, except Version, a string that is empty when the event has no such value.
+type Automation struct {
+	ID          string `json:"id"`
+	Event       string `json:"event"` // Always EventAutomation
+	Version     int    `json:"version"`
+	SourceEvent string `json:"source_event"` // The typed event this stands for
+	OccurredAt  string `json:"occurred_at"`  // RFC 3339
+	Account     string `json:"account"`
+	Summary     string `json:"summary"` // One human-readable line, e.g. for a Slack message
+	ProfileURL  string `json:"profile_url"`
+	Name        string `json:"name"`
+	Campaign    string `json:"campaign"`
+	RunID       string `json:"run_id"`
+	Status      string `json:"status"`
+}
+
+// Payload is the data of a typed event
+type Payload interface {
+	Event() string
+	automation() Automation
+}
+
+// RunFinished is the data of EventRunFinished
+type RunFinished struct {
+	RunID      string    `json:"run_id"`
+	Mode       string    `json:"mode"`
+	Status     string    `json:"status"`   // "completed", "partial" or "failed"
+	Campaign   string    `json:"campaign"` // Empty unless a campaign run
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Attempted  int       `json:"attempted"`
+	Sent       int       `json:"sent"`
+	Skipped    int       `json:"skipped"`
+	Failed     int       `json:"failed"`
+}
+
+// Event returns EventRunFinished
+func (RunFinished) Event() string { return EventRunFinished }
+
+func (p RunFinished) automation() Automation {
+	return Automation{
+		Summary: fmt.Sprintf("Run %s (%s) %s: %d sent, %d skipped, %d failed",
+			p.RunID, p.Mode, p.Status, p.Sent, p.Skipped, p.Failed),
+		Campaign: p.Campaign,
+		RunID:    p.RunID,
+		Status:   p.Status,
+	}
+}
+
+// ConnectionAccepted is the data of EventConnectionAccepted
+type ConnectionAccepted struct {
+	ProfileURL string `json:"profile_url"`
+	Name       string `json:"name"`
+	RunID      string `json:"run_id"`   // Run that sent the request
+	Campaign   string `json:"campaign"` // Campaign of that run, empty if none
+}
+
+// Event returns EventConnectionAccepted
+func (ConnectionAccepted) Event() string { return EventConnectionAccepted }
+
+func (p ConnectionAccepted) automation() Automation {
+	return Automation{
+		Summary:    fmt.Sprintf("%s accepted the connection request", nameOr(p.Name, p.ProfileURL)),
+		ProfileURL: p.ProfileURL,
+		Name:       p.Name,
+		Campaign:   p.Campaign,
+		RunID:      p.RunID,
+		Status:     "accepted",
+	}
+}
+
+// AccountEvent is the data of EventAccountEvent
+type AccountEvent struct {
+	Type   string `json:"type"` // e.g. "challenge", "warning", "logout"
+	Detail string `json:"detail"`
+	RunID  string `json:"run_id"`
+}
+
+// Event returns EventAccountEvent
+func (AccountEvent) Event() string { return EventAccountEvent }
+
+func (p AccountEvent) automation() Automation {
+	summary := "Account event: " + p.Type
+	if p.Detail != "" {
+		summary += " (" + p.Detail + ")"
+	}
+	return Automation{Summary: summary, RunID: p.RunID, Status: p.Type}
+}
+
+// Endpoint is a URL events are posted to. An empty Events list subscribes to every typed
+// event; EventAutomation subscribes to the flat payload of every event.
+type Endpoint struct {
+	URL    string
+	Events []string
+}
+
+// Sender posts events to the configured endpoints. A nil Sender sends nothing.
+type Sender struct {
+	account   string
+	endpoints []Endpoint
+	http      *http.Client
+}
+
+// NewSender creates a sender for the account's events; a nil httpClient uses one with a 10s
+// timeout. It returns nil when there are no endpoints.
+func NewSender(account string, endpoints []Endpoint, httpClient *http.Client) *Sender {
+	if len(endpoints) == 0 {
+		return nil
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Sender{account: account, endpoints: endpoints, http: httpClient}
+}
+
+// Send posts the event to every endpoint subscribed to it, returning the failed deliveries
+func (s *Sender) Send(ctx context.Context, at time.Time, payload Payload) error {
+	if s == nil {
+		return nil
+	}
+	id := newID()
+	envelope := Envelope{ID: id, Event: payload.Event(), Version: Version, OccurredAt: at.UTC(), Account: s.account, Data: payload}
+	generic := payload.automation()
+	generic.ID, generic.Event, generic.Version = id, EventAutomation, Version
+	generic.SourceEvent, generic.OccurredAt, generic.Account = payload.Event(), at.UTC().Format(time.RFC3339), s.account
+
+	var errs []error
+	for _, endpoint := range s.endpoints {
+		if len(endpoint.Events) == 0 || slices.Contains(endpoint.Events, payload.Event()) {
+			errs = append(errs, s.post(ctx, endpoint.URL, payload.Event(), envelope))
+		}
+		if slices.Contains(endpoint.Events, EventAutomation) {
+			errs = append(errs, s.post(ctx, endpoint.URL, EventAutomation, generic))
+		}
+	}
+	return stderrors.Join(errs...)
+}
+
+// post delivers one body
+func (s *Sender) post(ctx context.Context, url, event string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s webhook: %w", event, err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to build %s webhook for %s: %w", event, url, err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Webhook-Event", event)
+
+	response, err := s.http.Do(request)
+	if err != nil {
+		return fmt.Errorf("%s webhook to %s failed: %w", event, url, err)
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 1<<16))
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("%s webhook to %s returned %s", event, url, response.Status)
+	}
+	return nil
+}
+
+// Redact hides the path, query and credentials of a webhook URL, which catch-hook services such
+// as Zapier and Make use as the secret; the scheme and host are kept to tell endpoints apart
+func Redact(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return "(unparsable webhook URL)"
+	}
+	redacted := parsed.Scheme + "://" + parsed.Host
+	if parsed.User != nil || parsed.Path != "" || parsed.RawQuery != "" || parsed.Fragment != "" {
+		redacted += "/***"
+	}
+	return redacted
+}
+
+// newID returns a random event ID, shared by an event's typed and automation bodies
+func newID() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return "evt_" + hex.EncodeToString(buf)
+}
+
+// nameOr returns name, or fallback when it is empty
+func nameOr(name, fallback string) string {
+	if name != "" {
+		return name
+	}
+	return fallback
+}
@@ -0,0 +1,167 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestSchemasMatchPayloads tests that each schema requires exactly the fields the payload
+// marshals, so a payload change cannot drift from the published schema
+func TestSchemasMatchPayloads(t *testing.T) {
+	at := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	payloads := []Payload{
+		RunFinished{RunID: "run-1", Mode: "campaign", Status: "completed", Campaign: "spring", StartedAt: at, FinishedAt: at, Sent: 3},
+		ConnectionAccepted{ProfileURL: "https://www.linkedin.com/in/jane-doe", Name: "Jane Doe", RunID: "run-1"},
+		AccountEvent{Type: "challenge", Detail: "captcha"},
+	}
+	for _, payload := range payloads {
+		envelope := Envelope{ID: "evt_1", Event: payload.Event(), Version: Version, OccurredAt: at, Account: "default", Data: payload}
+		var body map[string]any
+		data, _ := json.Marshal(envelope)
+		json.Unmarshal(data, &body)
+		schema := loadSchema(t, payload.Event())
+		assertFields(t, payload.Event(), schema, body)
+		assertFields(t, payload.Event()+" data", schema.Properties["data"], body["data"].(map[string]any))
+	}
+
+	generic := payloads[0].automation()
+	var body map[string]any
+	data, _ := json.Marshal(generic)
+	json.Unmarshal(data, &body)
+	assertFields(t, EventAutomation, loadSchema(t, EventAutomation), body)
+
+	for _, event := range Events() {
+		if _, ok := Schema(event); !ok {
+			t.Errorf("no schema published for %s", event)
+		}
+	}
+}
+
+type schemaDoc struct {
+	Required   []string             `json:"required"`
+	Properties map[string]schemaDoc `json:"properties"`
+}
+
+func loadSchema(t *testing.T, event string) schemaDoc {
+	t.Helper()
+	raw, ok := Schema(event)
+	if !ok {
+		t.Fatalf("no schema for %s", event)
+	}
+	var schema schemaDoc
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		t.Fatalf("schema for %s is not valid JSON: %v", event, err)
+	}
+	return schema
+}
+
+func assertFields(t *testing.T, name string, schema schemaDoc, body map[string]any) {
+	t.Helper()
+	var fields []string
+	for field := range body {
+		fields = append(fields, field)
+	}
+	required := slices.Clone(schema.Required)
+	sort.Strings(fields)
+	sort.Strings(required)
+	if !slices.Equal(fields, required) {
+		t.Errorf("%s: payload fields %v differ from schema %v", name, fields, required)
+	}
+}
+
+// TestSenderRoutesEvents tests that typed subscribers get the envelope, automation subscribers
+// the flat payload, and that a failed delivery is reported without stopping the others
+func TestSenderRoutesEvents(t *testing.T) {
+	var mutex sync.Mutex
+	received := map[string][]map[string]any{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		mutex.Lock()
+		received[r.URL.Path] = append(received[r.URL.Path], body)
+		mutex.Unlock()
+	}))
+	defer server.Close()
+
+	sender := NewSender("default", []Endpoint{
+		{URL: server.URL + "/all"},
+		{URL: server.URL + "/runs", Events: []string{EventRunFinished}},
+		{URL: server.URL + "/zapier", Events: []string{EventAutomation}},
+		{URL: server.URL + "/broken", Events: []string{EventConnectionAccepted}},
+	}, server.Client())
+
+	at := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	if err := sender.Send(context.Background(), at, RunFinished{RunID: "run-1", Mode: "connect", Status: "completed", Sent: 4}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	err := sender.Send(context.Background(), at, ConnectionAccepted{ProfileURL: "https://www.linkedin.com/in/jane-doe", Name: "Jane Doe"})
+	if err == nil || !strings.Contains(err.Error(), "/broken") {
+		t.Errorf("expected the broken endpoint to be reported, got %v", err)
+	}
+
+	if len(received["/all"]) != 2 || len(received["/runs"]) != 1 || len(received["/zapier"]) != 2 {
+		t.Fatalf("unexpected deliveries: %v", received)
+	}
+	if run := received["/runs"][0]; run["event"] != EventRunFinished || run["data"].(map[string]any)["sent"] != float64(4) {
+		t.Errorf("unexpected run envelope %v", run)
+	}
+	zap := received["/zapier"][1]
+	if zap["event"] != EventAutomation || zap["source_event"] != EventConnectionAccepted ||
+		zap["summary"] != "Jane Doe accepted the connection request" || zap["campaign"] != "" || zap["occurred_at"] != "2024-05-01T09:30:00Z" {
+		t.Errorf("unexpected automation payload %v", zap)
+	}
+	if received["/all"][1]["id"] != zap["id"] {
+		t.Errorf("expected one ID per event across bodies, got %v and %v", received["/all"][1]["id"], zap["id"])
+	}
+
+	if NewSender("default", nil, nil) != nil {
+		t.Error("expected no sender without endpoints")
+	}
+	var none *Sender
+	if err := none.Send(context.Background(), at, AccountEvent{Type: "logout"}); err != nil {
+		t.Errorf("expected a nil sender to send nothing, got %v", err)
+	}
+}
+
+// TestSchemaHandler tests the /schema index and single-event routes
+func TestSchemaHandler(t *testing.T) {
+	server := httptest.NewServer(SchemaHandler())
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/schema")
+	if err != nil {
+		t.Fatalf("GET /schema failed: %v", err)
+	}
+	var index struct {
+		Version int                        `json:"version"`
+		Events  map[string]json.RawMessage `json:"events"`
+	}
+	json.NewDecoder(response.Body).Decode(&index)
+	response.Body.Close()
+	if index.Version != Version || len(index.Events) != len(Events()) {
+		t.Errorf("unexpected schema index: version %d, %d events", index.Version, len(index.Events))
+	}
+
+	for path, status := range map[string]int{"/schema/run.finished": http.StatusOK, "/schema/nope": http.StatusNotFound} {
+		response, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		response.Body.Close()
+		if response.StatusCode != status {
+			t.Errorf("GET %s: expected %d, got %d", path, status, response.StatusCode)
+		}
+	}
+}
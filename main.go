@@ -2,23 +2,77 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
+	stderrors "errors"
 	"flag"
 	"fmt"
 	"log"
+	"maps"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/proto"
+	"linkedin-automation-framework/internal/annotations"
+	"linkedin-automation-framework/internal/apitoken"
+	"linkedin-automation-framework/internal/approval"
+	"linkedin-automation-framework/internal/audience"
+	"linkedin-automation-framework/internal/blackout"
 	"linkedin-automation-framework/internal/browser"
+	"linkedin-automation-framework/internal/commands"
+	"linkedin-automation-framework/internal/companies"
+	"linkedin-automation-framework/internal/campaign"
 	"linkedin-automation-framework/internal/config"
+	"linkedin-automation-framework/internal/connect"
+	"linkedin-automation-framework/internal/content"
+	"linkedin-automation-framework/internal/connections"
+	"linkedin-automation-framework/internal/control"
+	"linkedin-automation-framework/internal/daemon"
+	"linkedin-automation-framework/internal/deadletters"
+	"linkedin-automation-framework/internal/emails"
+	"linkedin-automation-framework/internal/errors"
+	"linkedin-automation-framework/internal/funnel"
+	"linkedin-automation-framework/internal/gallery"
+	"linkedin-automation-framework/internal/health"
+	"linkedin-automation-framework/internal/engagers"
+	"linkedin-automation-framework/internal/identity"
+	"linkedin-automation-framework/internal/jobqueue"
+	"linkedin-automation-framework/internal/latency"
+	"linkedin-automation-framework/internal/integrations/pipedrive"
+	"linkedin-automation-framework/internal/integrations/salesforce"
+	"linkedin-automation-framework/internal/leadfilter"
+	"linkedin-automation-framework/internal/locale"
 	"linkedin-automation-framework/internal/logger"
+	"linkedin-automation-framework/internal/replay"
+	"linkedin-automation-framework/internal/messaging"
+	"linkedin-automation-framework/internal/photos"
+	"linkedin-automation-framework/internal/probes"
+	"linkedin-automation-framework/internal/proxy"
+	"linkedin-automation-framework/internal/runs"
+	"linkedin-automation-framework/internal/savedsearch"
+	"linkedin-automation-framework/internal/screencast"
+	"linkedin-automation-framework/internal/search"
+	"linkedin-automation-framework/internal/selectors"
+	"linkedin-automation-framework/internal/session"
 	"linkedin-automation-framework/internal/stealth"
 	"linkedin-automation-framework/internal/storage"
+	"linkedin-automation-framework/internal/suppression"
+	"linkedin-automation-framework/internal/viewers"
+	"linkedin-automation-framework/internal/warnings"
+	"linkedin-automation-framework/internal/webhook"
 )
 
 // Application represents the main application with all dependencies
@@ -28,8 +82,58 @@ type Application struct {
 	browserManager *browser.Manager
 	stealthManager *stealth.StealthManager
 	storage        *storage.StorageManager
+	leadFilter     leadfilter.Filter
+	network        *connections.Network
+	suppression    *suppression.List // Prospects no account of the deployment may contact; nil without a list
+	searchLimiter  *search.RateLimiter
+	controller     *control.Controller
+	blackouts      *blackout.Calendar // Configured blackouts and ad-hoc pauses during which no actions run
+	replayRouter   *rod.HijackRouter  // Serves recorded pages during a replay; nil otherwise
+	stealthTracer  *stealth.Tracer    // Writes the stealth trace; nil unless tracing
+	halt           context.CancelFunc // Stops the whole run when a kill-switch trips
+	campaignPath   string
+	watch          savedsearch.WatchOptions
+	searchName     string
+	location       string
+	companyOptions companies.Options                   // Which companies enrich-companies mode looks up
+	viewerOptions  viewers.Options                     // Which visits profile-viewers mode queues
+	viewerInterval time.Duration                       // Time between profile-viewers checks, 0 checks once
+	postURLs       []string                            // Posts harvest-engagers mode reads
+	engagerOptions engagers.Options                    // How harvest-engagers mode feeds a post's engagers
+	engagerLoads   int                                 // How many times harvest-engagers mode loads more comments and reactions
+	contentQueries []string                            // Hashtags or keywords content-search mode searches posts for
+	contentPosted  string                              // How recent content-search mode's posts must be, "" for any time
+	contentOptions content.Options                     // How content-search mode feeds the posts' authors
+	contentLoads   int                                 // How many times content-search mode loads more results
+	newsletterURL  string                              // Newsletter whose subscribers track-audience mode counts, "" for none
+	answers        promptAnswers                       // Answers to interactive prompts given as flags
+	runID          string                              // Identifies this invocation in logs, storage rows and run summaries
+	summary        *runs.Recorder                      // Outcomes of the current connect, message or search run; nil otherwise
+	webhooks       *webhook.Sender                     // Posts events to the configured webhooks; nil without any
+	photoFetcher   *photos.Fetcher                     // Downloads the photos of new search results; nil unless search.photos is enabled
+	memory         atomic.Pointer[browser.MemoryStats] // Last memory sample of a long-running mode; nil before one
+	lastFailure    atomic.Pointer[daemon.Failure]      // Last scheduled search that failed; nil before one
+	latency        *latency.Tracker                    // Holds page loads and actions to the latency objectives; nil unless tracked
+	schedulerBeat  *probes.Heartbeat                   // Beats as the search scheduler makes progress; nil unless scheduling
+	proxies        *proxy.Pool                         // Rotates the browser through proxy.pool; nil without a pool or during a replay
 }
 
+// promptAnswers answers the interactive prompts ahead of time, so modes can run unattended
+type promptAnswers struct {
+	yes            bool   // Confirm every prompt, and wait for a logged-in session instead of ENTER
+	maxConnections int    // Connection requests to send in connect-only mode; 0 asks
+	keywords       string // Search keywords in connect-only mode; empty asks
+}
+
+// loginWaitTimeout bounds how long an unattended run waits for a logged-in session
+const loginWaitTimeout = 5 * time.Minute
+
+// Business hours actions keep to under respect_business_hours, in local time
+const (
+	businessStart = 9  // 9 AM
+	businessEnd   = 17 // 5 PM
+)
+
 // SimpleRateLimiter provides basic rate limiting for demo purposes
 type SimpleRateLimiter struct {
 	connectionsPerHour int
@@ -63,26 +167,40 @@ const (
 	ModeFullDemo   OperationMode = "full-demo" // Educational full workflow demonstration
 	ModeManualLogin OperationMode = "manual-login" // Manual login then automation demo
 	ModeConnectOnly OperationMode = "connect-only" // Focus only on connection requests
+	ModeCampaign   OperationMode = "campaign"     // Run a campaign file over stored leads
+	ModeExportConnections OperationMode = "export-connections" // Download LinkedIn's connections export and import it
+	ModeWatchSearch OperationMode = "watch-search" // Re-run a search and save only profiles it has not returned before
+	ModeRunSearch  OperationMode = "run-search"       // Run one saved search by name
+	ModeSearchScheduler OperationMode = "search-scheduler" // Run saved searches whenever their interval comes round
+	ModeResolveLocation OperationMode = "resolve-location" // Look up and cache the geo ID of a location name
+	ModeDaemon     OperationMode = "daemon"           // Run the search scheduler in the background, managed over a unix socket
+	ModeEnrichCompanies OperationMode = "enrich-companies" // Look up the companies of stored leads and cache their firmographics
+	ModeProfileViewers OperationMode = "profile-viewers" // Queue recent profile viewers the lead filter accepts as high-priority leads
+	ModeHarvestEngagers OperationMode = "harvest-engagers" // Feed the people who reacted to or commented on posts as warm leads
+	ModeContentSearch OperationMode = "content-search" // Feed the authors of recent posts for a hashtag or keyword as leads
+	ModeTrackAudience OperationMode = "track-audience" // Record the account's followers, impressions and newsletter subscribers
 )
 
 
 
 func main() {
-	// Parse command line flags
-	var (
-		configPath = flag.String("config", "config.yaml", "Path to configuration file")
-		mode       = flag.String("mode", "demo", "Operation mode: demo, search, connect, message, interactive, full-demo, manual-login, connect-only")
-		headless   = flag.Bool("headless", false, "Run browser in headless mode")
-		verbose    = flag.Bool("verbose", false, "Enable verbose logging")
-		version    = flag.Bool("version", false, "Show version information")
-	)
-	flag.Parse()
-
-	if *version {
-		fmt.Println("LinkedIn Automation Framework v1.0.0")
-		fmt.Println("Built with Rod browser automation library")
-		fmt.Println("For educational and technical evaluation purposes only")
-		return
+	root := newRootCommand()
+	root.SetArgs(legacyArgs(os.Args[1:]))
+	if err := root.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		// The status tells whatever runs the tool how the run failed, see errors.ExitCode
+		os.Exit(errors.ExitCode(err))
+	}
+}
+
+// startApplication starts the browser application and runs the mode, letting setup pass the
+// command's flags to the application before it runs
+func startApplication(opts *cliOptions, mode OperationMode, setup func(*Application)) error {
+	if opts.answers.maxConnections < 0 || opts.answers.maxConnections > 10 {
+		return fmt.Errorf("--max-connections must be between 1 and 10, got %d", opts.answers.maxConnections)
+	}
+	if opts.recordDir != "" && opts.replayDir != "" {
+		return fmt.Errorf("--record and --replay cannot be used together")
 	}
 
 	// Create application context with cancellation
@@ -93,24 +211,118 @@ func main() {
 	setupGracefulShutdown(cancel)
 
 	// Initialize application
-	app, err := initializeApplication(ctx, *configPath, *headless, *verbose)
+	app, err := initializeApplication(ctx, opts)
 	if err != nil {
-		log.Fatalf("Failed to initialize application: %v", err)
+		return fmt.Errorf("failed to initialize application: %w", err)
 	}
 	defer app.cleanup()
+	app.halt = cancel
+	app.answers = opts.answers
+	if setup != nil {
+		setup(app)
+	}
+	if scheduledModes[mode] {
+		app.schedulerBeat = probes.NewHeartbeat(time.Now())
+	}
+	app.startControl(ctx)
 
 	app.logger.Info(ctx, "LinkedIn Automation Framework starting",
 		logger.F("version", "1.0.0"),
-		logger.F("mode", *mode),
-		logger.F("config", *configPath))
+		logger.F("mode", string(mode)),
+		logger.F("config", opts.configPath),
+		logger.F("tenant", app.config.Tenant))
 
 	// Run the application based on the selected mode
-	if err := app.run(ctx, OperationMode(*mode)); err != nil {
-		app.logger.Error(ctx, "Application error", logger.F("error", err.Error()))
-		os.Exit(1)
+	if err := app.run(ctx, mode); err != nil {
+		app.logger.Error(ctx, "Application error", logger.F("error", err))
+		return err
 	}
 
 	app.logger.Info(ctx, "Application completed successfully")
+	return nil
+}
+
+// confirm asks a yes/no question, answered yes without asking under --yes
+func (app *Application) confirm(question string) bool {
+	if app.answers.yes {
+		fmt.Println(question + "yes (--yes)")
+		return true
+	}
+	fmt.Print(question)
+	var input string
+	fmt.Scanln(&input)
+	input = strings.ToLower(input)
+	return input == "y" || input == "yes"
+}
+
+// waitForLogin waits for ENTER once the user has logged in, then saves the session. Under --yes
+// it restores the saved session instead and waits until the page has left LinkedIn's login pages.
+func (app *Application) waitForLogin(ctx context.Context, page *rod.Page, prompt string) error {
+	// A replay starts from the recorded logged-in page; there is no session to wait for
+	if app.replayRouter != nil {
+		fmt.Println("🎞️  Replaying a recorded run, skipping login")
+		app.checkpoint(ctx, page, "logged-in")
+		return nil
+	}
+
+	if !app.answers.yes {
+		fmt.Print(prompt)
+		var input string
+		fmt.Scanln(&input)
+		// Saved for later unattended runs
+		if err := app.browserManager.SaveCookies(app.config.Browser.CookiePath); err != nil {
+			fmt.Printf("⚠️  Could not save the session: %v\n", err)
+		}
+		app.checkpoint(ctx, page, "logged-in")
+		return nil
+	}
+
+	if err := app.browserManager.LoadCookies(app.config.Browser.CookiePath); err == nil {
+		fmt.Println("🍪 Restored the saved session (--yes)")
+		if err := page.Navigate(session.DefaultHealthCheckURL); err != nil {
+			return fmt.Errorf("navigation failed: %w", err)
+		}
+		if err := browser.WaitLoad(ctx, page, app.timeouts().Navigation); err != nil {
+			return fmt.Errorf("page load failed: %w", err)
+		}
+	} else {
+		fmt.Printf("⚠️  Could not restore the saved session: %v\n", err)
+	}
+
+	fmt.Printf("⏳ Waiting up to %s for a logged-in session (--yes)...\n", loginWaitTimeout)
+	deadline := time.Now().Add(loginWaitTimeout)
+	for {
+		if info, err := page.Info(); err == nil && session.ClassifyURL(info.URL) == session.StatusHealthy {
+			app.checkpoint(ctx, page, "logged-in")
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return loginWaitError(page, fmt.Sprintf("not logged in after %s; log in once without --yes to save a session to %s", loginWaitTimeout, app.config.Browser.CookiePath))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// loginWaitError reports a --yes login that never reached LinkedIn: E_CHALLENGE if the page is
+// held at a checkpoint, E_SESSION_EXPIRED otherwise
+func loginWaitError(page *rod.Page, message string) error {
+	err := errors.NewError(errors.ErrorTypeAuthentication, "wait_for_login", message, nil)
+	if info, infoErr := page.Info(); infoErr == nil && session.ClassifyURL(info.URL) == session.StatusCheckpoint {
+		err.WithCode(errors.CodeChallenge)
+	}
+	return err
+}
+
+// checkpoint records or replays the page under label when --record or --replay is given. A failed
+// checkpoint is logged rather than stopping the run.
+func (app *Application) checkpoint(ctx context.Context, page *rod.Page, label string) {
+	if err := app.browserManager.Checkpoint(page, label); err != nil {
+		app.logger.Warn(ctx, "Checkpoint failed", logger.F("checkpoint", label), logger.F("error", err))
+	}
 }
 
 // setupGracefulShutdown sets up signal handling for graceful shutdown
@@ -126,21 +338,40 @@ func setupGracefulShutdown(cancel context.CancelFunc) {
 }
 
 // initializeApplication initializes all application components with dependency injection
-func initializeApplication(ctx context.Context, configPath string, headless, verbose bool) (*Application, error) {
+func initializeApplication(ctx context.Context, opts *cliOptions) (*Application, error) {
 	// Load configuration with environment overrides
 	configManager := config.NewManager()
-	cfg, err := configManager.LoadWithEnvOverrides(configPath)
+	cfg, err := configManager.LoadWithEnvOverrides(opts.configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
 
 	// Override configuration with command line flags
-	if headless {
+	if opts.headless {
 		cfg.Browser.Headless = true
 	}
-	if verbose {
+	if opts.verbose {
 		cfg.Logging.Level = "debug"
 	}
+	if opts.seed != 0 {
+		cfg.Stealth.Seed = opts.seed
+	}
+	if opts.traceFile != "" {
+		cfg.Stealth.TraceFile = opts.traceFile
+	}
+	if opts.video {
+		cfg.Video.Enabled = true
+	}
+
+	// A replay starts from empty storage inside the recording, so it never touches real data
+	if opts.replayDir != "" {
+		replayStorage := filepath.Join(opts.replayDir, "storage")
+		if err := os.RemoveAll(replayStorage); err != nil {
+			return nil, fmt.Errorf("failed to reset replay storage: %w", err)
+		}
+		cfg.Storage.Path = replayStorage
+		cfg.Storage.RunsDir = filepath.Join(opts.replayDir, "runs")
+	}
 
 	// Initialize logger
 	logLevel := logger.InfoLevel
@@ -171,14 +402,52 @@ func initializeApplication(ctx context.Context, configPath string, headless, ver
 		return nil, fmt.Errorf("failed to initialize storage: %w", err)
 	}
 
+	// Load known connections so nobody already in the network is invited again
+	network, err := connections.LoadNetwork(storageImpl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load connections: %w", err)
+	}
+
+	// Initialize lead filter
+	leadFilter, err := leadfilter.NewFilter(leadfilter.FilterConfig{
+		Script:   cfg.Filter.Script,
+		Keywords: cfg.Filter.Keywords,
+		MinScore: cfg.Filter.MinScore,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize lead filter: %w", err)
+	}
+
+	// Route the browser through the first healthy proxy of the pool, if any
+	proxies, err := newProxyPool(cfg, storageImpl, opts.replayDir != "")
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize browser manager
 	browserConfig := browser.BrowserConfig{
-		Headless:   cfg.Browser.Headless,
-		UserAgent:  cfg.Browser.UserAgent,
-		ViewportW:  cfg.Browser.ViewportW,
-		ViewportH:  cfg.Browser.ViewportH,
-		Flags:      cfg.Browser.Flags,
-		CookiePath: cfg.Browser.CookiePath,
+		Headless:             cfg.Browser.Headless,
+		HeadlessMode:         cfg.Browser.HeadlessMode,
+		Device:               cfg.Browser.Device,
+		UserAgent:            cfg.Browser.UserAgent,
+		ViewportW:            cfg.Browser.ViewportW,
+		ViewportH:            cfg.Browser.ViewportH,
+		Flags:                cfg.Browser.Flags,
+		DisabledStealthFlags: cfg.Browser.DisabledStealthFlags,
+		CookiePath:           cfg.Browser.CookiePath,
+		CookieDomain:         cfg.Browser.CookieDomain,
+		DownloadDir:          cfg.Browser.DownloadDir,
+		WebRTC:               cfg.Browser.WebRTC,
+		DNSOverHTTPS:         cfg.Browser.DNSOverHTTPS,
+		Locale:               localeSettings(cfg.Browser.Locale),
+	}
+	if proxies != nil {
+		endpoint, err := proxies.Next(time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to pick a proxy: %w", err)
+		}
+		browserConfig.Proxy = endpoint.URL.String()
+		appLogger.Info(ctx, "Routing the browser through a proxy", logger.F("proxy", endpoint.String()))
 	}
 	browserManager := browser.NewManager(browserConfig)
 
@@ -187,6 +456,29 @@ func initializeApplication(ctx context.Context, configPath string, headless, ver
 		return nil, fmt.Errorf("failed to initialize browser: %w", err)
 	}
 
+	// Record or replay the page at every workflow checkpoint
+	var replayRouter *rod.HijackRouter
+	if opts.recordDir != "" {
+		recorder, err := replay.NewRecorder(opts.recordDir)
+		if err != nil {
+			return nil, err
+		}
+		browserManager.SetCheckpointer(recorder)
+	}
+	if opts.replayDir != "" {
+		player, err := replay.NewPlayer(opts.replayDir)
+		if err != nil {
+			return nil, err
+		}
+		replayRouter, err = player.Serve(browserManager.Browser(), func(url string) {
+			appLogger.Warn(ctx, "No recorded page for URL, the replay has left the recording", logger.F("url", url))
+		})
+		if err != nil {
+			return nil, err
+		}
+		browserManager.SetCheckpointer(player)
+	}
+
 	// Initialize stealth manager
 	stealthConfig := stealth.StealthConfig{
 		MinDelay:            cfg.Stealth.MinDelay,
@@ -196,23 +488,39 @@ func initializeApplication(ctx context.Context, configPath string, headless, ver
 		ScrollMinDelay:      cfg.Stealth.ScrollMinDelay,
 		ScrollMaxDelay:      cfg.Stealth.ScrollMaxDelay,
 		BusinessHours:       cfg.Stealth.BusinessHours,
-		BusinessStart:       9,  // 9 AM
-		BusinessEnd:         17, // 5 PM
+		BusinessStart:       businessStart,
+		BusinessEnd:         businessEnd,
 		CooldownPeriod:      cfg.Stealth.CooldownPeriod,
 		MaxActionsPerWindow: cfg.RateLimit.ConnectionsPerHour,
 		RateLimitWindow:     time.Hour,
+		Seed:                cfg.Stealth.Seed,
 	}
+	device := browserManager.Device()
 	fingerprintConfig := stealth.FingerprintConfig{
-		UserAgent:     cfg.Browser.UserAgent,
+		UserAgent:     device.UserAgent,
 		ViewportW:     cfg.Browser.ViewportW,
 		ViewportH:     cfg.Browser.ViewportH,
 		MaskWebDriver: true,
 	}
+	if device.Mobile {
+		// Mobile metrics are applied by the browser manager; a plain viewport override would reset them
+		fingerprintConfig.ViewportW, fingerprintConfig.ViewportH = 0, 0
+	}
 	stealthManager := stealth.NewStealthManager(stealthConfig, fingerprintConfig)
+	appLogger.Info(ctx, "Stealth randomness seeded", logger.F("seed", stealthManager.Seed()))
+	var stealthTracer *stealth.Tracer
+	if cfg.Stealth.TraceFile != "" {
+		stealthTracer, err = stealth.OpenTrace(cfg.Stealth.TraceFile)
+		if err != nil {
+			return nil, err
+		}
+		stealthManager.SetTracer(stealthTracer)
+		appLogger.Info(ctx, "Tracing stealth actions", logger.F("trace", cfg.Stealth.TraceFile))
+	}
 
 	// Configure browser fingerprint
 	if err := stealthManager.ConfigureFingerprint(browserManager.Browser()); err != nil {
-		appLogger.Warn(ctx, "Failed to configure browser fingerprint", logger.F("error", err.Error()))
+		appLogger.Warn(ctx, "Failed to configure browser fingerprint", logger.F("error", err))
 	}
 
 	// Note: In a production implementation, proper type adapters would be needed
@@ -226,11 +534,265 @@ func initializeApplication(ctx context.Context, configPath string, headless, ver
 		browserManager: browserManager,
 		stealthManager: stealthManager,
 		storage:        storageImpl,
+		leadFilter:     leadFilter,
+		network:        network,
+		suppression:    newSuppressionList(cfg),
+		searchLimiter:  search.NewRateLimiter(cfg.RateLimit.SearchesPerHour, time.Hour),
+		controller:     control.NewController(),
+		blackouts:      newBlackoutCalendar(cfg, storageImpl),
+		replayRouter:   replayRouter,
+		stealthTracer:  stealthTracer,
+		webhooks:       newWebhookSender(cfg),
+		photoFetcher:   newPhotoFetcher(cfg, storageImpl),
+		proxies:        proxies,
 	}, nil
 }
 
+// newProxyPool creates the pool of proxy.pool and the provider sessions, keeping the proxies that failed in earlier runs
+// out of rotation until their cooldown ends and the account on the proxy bound to it. Returns nil
+// without a pool, and during a replay, whose recorded pages need no network.
+func newProxyPool(cfg *config.Config, storageImpl *storage.StorageManager, replaying bool) (*proxy.Pool, error) {
+	urls := append(slices.Clone(cfg.Proxy.Pool), cfg.Proxy.Sessions...)
+	if len(urls) == 0 || replaying {
+		return nil, nil
+	}
+	endpoints := make([]proxy.Endpoint, len(urls))
+	for i, raw := range urls {
+		endpoint, err := proxy.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		endpoints[i] = endpoint
+	}
+	stats, err := storageImpl.GetProxyStats()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load proxy stats: %w", err)
+	}
+	unhealthyUntil := make(map[string]time.Time, len(stats))
+	for _, stat := range stats {
+		unhealthyUntil[stat.Proxy] = stat.UnhealthyUntil
+	}
+	pool := proxy.NewPool(endpoints, cfg.Proxy.Cooldown)
+	pool.Restore(unhealthyUntil)
+	if binding, ok := proxyBinding(cfg); ok && binding.Proxy != "" {
+		endpoint, err := proxy.Parse(binding.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		pool.Pin(endpoint.String())
+	}
+	return pool, nil
+}
+
+// localeSettings converts browser.locale into what the browser's pages claim
+func localeSettings(cfg config.LocaleConfig) locale.Settings {
+	settings := locale.Settings{Timezone: cfg.Timezone, AcceptLanguage: cfg.AcceptLanguage}
+	if cfg.Latitude != 0 || cfg.Longitude != 0 {
+		settings.Geolocation = &locale.Position{Latitude: cfg.Latitude, Longitude: cfg.Longitude, Accuracy: cfg.Accuracy}
+	}
+	return settings
+}
+
+// proxyBinding returns the proxy binding of the account the run acts as, health.account
+func proxyBinding(cfg *config.Config) (config.ProxyBindingConfig, bool) {
+	for _, binding := range cfg.Proxy.Bindings {
+		if binding.Account == cfg.Health.Account {
+			return binding, true
+		}
+	}
+	return config.ProxyBindingConfig{}, false
+}
+
+// newPhotoFetcher creates the fetcher for search result photos, nil unless they are enabled
+func newPhotoFetcher(cfg *config.Config, storageImpl *storage.StorageManager) *photos.Fetcher {
+	if !cfg.Search.Photos.Enabled {
+		return nil
+	}
+	return photos.NewFetcher(cfg.Search.Photos.Dir, nil, storageImpl)
+}
+
+// newWebhookSender creates the sender for the configured webhooks, nil without any
+func newWebhookSender(cfg *config.Config) *webhook.Sender {
+	endpoints := make([]webhook.Endpoint, len(cfg.Webhooks))
+	for i, hook := range cfg.Webhooks {
+		endpoints[i] = webhook.Endpoint{URL: hook.URL, Events: hook.Events}
+	}
+	return webhook.NewSender(cfg.Health.Account, endpoints, nil)
+}
+
+// notifyAccepted posts a connection.accepted event for each request reconciling found accepted
+func notifyAccepted(ctx context.Context, sender *webhook.Sender, storageImpl *storage.StorageManager, accepted []storage.ConnectionRequest) error {
+	if sender == nil || len(accepted) == 0 {
+		return nil
+	}
+	campaigns, err := runCampaigns(storageImpl)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, request := range accepted {
+		errs = append(errs, sender.Send(ctx, time.Now(), webhook.ConnectionAccepted{
+			ProfileURL: request.ProfileURL,
+			Name:       request.ProfileName,
+			RunID:      request.RunID,
+			Campaign:   campaigns[request.RunID],
+		}))
+	}
+	return stderrors.Join(errs...)
+}
+
+// newBlackoutCalendar combines the configured blackouts, already checked by config validation,
+// with the ad-hoc pauses in storage
+func newBlackoutCalendar(cfg *config.Config, store blackout.Store) *blackout.Calendar {
+	var windows []blackout.Window
+	for _, entry := range cfg.Blackouts {
+		if window, err := blackout.ParseWindow(entry.Start, entry.End, entry.Reason, time.Local); err == nil {
+			windows = append(windows, window)
+		}
+	}
+	return blackout.NewCalendar(windows, store)
+}
+
 // run executes the application based on the selected operation mode
 func (app *Application) run(ctx context.Context, mode OperationMode) error {
+	if err := app.startRun(ctx, mode, time.Now()); err != nil {
+		return err
+	}
+	app.latency = app.newLatencyTracker(ctx)
+
+	// Schedulers run until stopped, so only other runs have their retries budgeted, and are
+	// stopped when their proxy dies; a scheduler moves to the next proxy between two searches
+	hookCtx := ctx
+	ctx, fail := context.WithCancelCause(ctx)
+	defer fail(nil)
+	stop := fail
+	if scheduledModes[mode] {
+		stop = nil
+	} else {
+		ctx = errors.WithRetryBudget(ctx, app.newRetryBudget(ctx, fail))
+	}
+	app.browserManager.SetPageHook(app.pageHook(hookCtx, stop))
+
+	// The demo never touches the account; everything else stays off while a kill-switch holds
+	if mode != ModeDemo {
+		app.enforceKillSwitches(ctx)
+		if err := health.Check(app.storage, app.config.Health.Account); err != nil {
+			return fmt.Errorf("%w (re-enable with: health enable)", err)
+		}
+		// Schedulers hold their queue through a blackout instead of refusing to start, and a
+		// replay sends nothing, so it runs whatever the calendar says
+		if !scheduledModes[mode] && app.replayRouter == nil {
+			if err := app.checkBlackout(time.Now()); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !summarizedModes[mode] {
+		app.checkEgress(ctx)
+		app.checkLocale(ctx)
+		return app.finishProxyRun(ctx, mode, runFailure(ctx, app.runMode(ctx, mode)))
+	}
+	app.summary = runs.NewRecorder(app.runID, string(mode), time.Now())
+	app.summary.UseSeed(app.stealthManager.Seed())
+	app.checkEgress(ctx)
+	app.checkLocale(ctx)
+	err := app.finishProxyRun(ctx, mode, runFailure(ctx, app.runMode(ctx, mode)))
+	app.finishRunSummary(ctx, err)
+	return err
+}
+
+// newRetryBudget limits the retries of the run to rate_limit.retry_budget. Once a retry is
+// refused, the run is cancelled with a diagnostic of what was retried, since an operation that
+// keeps failing usually means a selector or page changed. Returns nil without a limit.
+func (app *Application) newRetryBudget(ctx context.Context, fail context.CancelCauseFunc) *errors.RetryBudget {
+	if app.config.RateLimit.RetryBudget < 0 {
+		return nil
+	}
+	return errors.NewRetryBudget(app.config.RateLimit.RetryBudget, func(report errors.BudgetReport) {
+		app.logger.Error(ctx, "Retry budget exhausted, failing the run", logger.F("report", report.String()))
+		fmt.Printf("\n❌ Retry budget exhausted (%d retries), failing the run. Retried operations:\n", report.Max)
+		for _, operation := range report.Operations {
+			fmt.Printf("   • %s: %d retries, last error: %s\n", operation.Operation, operation.Retries, operation.LastError)
+		}
+		fail(fmt.Errorf("%w: %s", errors.ErrRetryBudgetExhausted, report))
+	})
+}
+
+// runFailure returns the retry budget's diagnostic or the proxy's error in place of err when an
+// exhausted budget or a dead proxy stopped the run, since the mode itself only sees its
+// operations fail or get cancelled
+func runFailure(ctx context.Context, err error) error {
+	if cause := context.Cause(ctx); stderrors.Is(cause, errors.ErrRetryBudgetExhausted) || stderrors.Is(cause, proxy.ErrFailed) {
+		return cause
+	}
+	return err
+}
+
+// startRun numbers this invocation among the day's runs and tags every later log line and
+// storage row with the resulting run ID
+func (app *Application) startRun(ctx context.Context, mode OperationMode, now time.Time) error {
+	sequence, err := app.storage.NextRunSequence(now.Format("2006-01-02"))
+	if err != nil {
+		return fmt.Errorf("failed to assign a run ID: %w", err)
+	}
+	app.runID = runs.NewID(now, sequence)
+	app.logger.SetRunID(app.runID)
+	app.storage.SetRunID(app.runID)
+
+	app.logger.Info(ctx, "Run started", logger.F("mode", string(mode)))
+	fmt.Printf("🆔 Run %s (look it up with: runs show %s)\n", app.runID, app.runID)
+	return nil
+}
+
+// scheduledModes run actions on a schedule, queueing them through blackouts
+var scheduledModes = map[OperationMode]bool{
+	ModeSearchScheduler: true,
+	ModeDaemon:          true,
+}
+
+// checkBlackout refuses to start a run inside a blackout
+func (app *Application) checkBlackout(now time.Time) error {
+	window, active, err := app.blackouts.Active(now)
+	if err != nil {
+		return fmt.Errorf("failed to check blackouts: %w", err)
+	}
+	if active {
+		return fmt.Errorf("actions are paused until %s (%s); see: pause list", window.End.Format("2006-01-02 15:04"), blackoutReason(window))
+	}
+	return nil
+}
+
+// blackoutReason describes why a window pauses actions
+func blackoutReason(window blackout.Window) string {
+	if window.Reason != "" {
+		return window.Reason
+	}
+	if window.AdHoc {
+		return "ad-hoc pause"
+	}
+	return "blackout"
+}
+
+// summarizedModes are the connect, message and search runs that write a run summary
+var summarizedModes = map[OperationMode]bool{
+	ModeSearch:          true,
+	ModeConnect:         true,
+	ModeMessage:         true,
+	ModeManualLogin:     true,
+	ModeConnectOnly:     true,
+	ModeCampaign:        true,
+	ModeWatchSearch:     true,
+	ModeRunSearch:       true,
+	ModeSearchScheduler: true,
+	ModeDaemon:          true,
+	ModeProfileViewers:  true,
+	ModeHarvestEngagers: true,
+	ModeContentSearch:   true,
+}
+
+// runMode runs the operation mode's flow
+func (app *Application) runMode(ctx context.Context, mode OperationMode) error {
 	switch mode {
 	case ModeDemo:
 		return app.runDemo(ctx)
@@ -248,11 +810,160 @@ func (app *Application) run(ctx context.Context, mode OperationMode) error {
 		return app.runManualLogin(ctx)
 	case ModeConnectOnly:
 		return app.runConnectOnly(ctx)
+	case ModeCampaign:
+		return app.runCampaign(ctx)
+	case ModeExportConnections:
+		return app.runExportConnections(ctx)
+	case ModeWatchSearch:
+		return app.runWatchSearch(ctx)
+	case ModeRunSearch:
+		return app.runSavedSearch(ctx)
+	case ModeSearchScheduler:
+		return app.runSearchScheduler(ctx)
+	case ModeResolveLocation:
+		return app.runResolveLocation(ctx)
+	case ModeDaemon:
+		return app.runDaemon(ctx)
+	case ModeEnrichCompanies:
+		return app.runEnrichCompanies(ctx)
+	case ModeProfileViewers:
+		return app.runProfileViewers(ctx)
+	case ModeHarvestEngagers:
+		return app.runHarvestEngagers(ctx)
+	case ModeContentSearch:
+		return app.runContentSearch(ctx)
+	case ModeTrackAudience:
+		return app.runTrackAudience(ctx)
 	default:
 		return fmt.Errorf("unsupported operation mode: %s", mode)
 	}
 }
 
+// topErrorGroups is how many error groups a run's printed summary lists
+const topErrorGroups = 5
+
+// finishRunSummary ends the current run's summary, writes it as JSON to the runs directory and
+// records it in storage, then prints its totals
+func (app *Application) finishRunSummary(ctx context.Context, err error) {
+	app.summary.UseQuota(runs.QuotaSearches, app.searchLimiter.Used())
+	if app.latency != nil {
+		app.summary.UseLatency(app.latency.Status().Kinds)
+	}
+	summary := app.summary.Finish(err, time.Now())
+
+	path, writeErr := runs.Write(app.config.Storage.RunsDir, summary)
+	if writeErr != nil {
+		app.logger.Warn(ctx, "Failed to write run summary", logger.F("error", writeErr))
+	}
+	document, _ := json.Marshal(summary)
+	record := storage.RunSummary{
+		RunID:      summary.RunID,
+		Mode:       summary.Mode,
+		StartedAt:  summary.StartedAt,
+		FinishedAt: summary.FinishedAt,
+		Status:     summary.Status,
+		Attempted:  summary.Attempted,
+		Sent:       summary.Sent,
+		Skipped:    len(summary.Skipped),
+		Failed:     len(summary.Errors),
+		Document:   string(document),
+	}
+	if saveErr := app.storage.SaveRunSummary(record); saveErr != nil {
+		app.logger.Warn(ctx, "Failed to save run summary", logger.F("error", saveErr))
+	}
+	fingerprints := make([]storage.ErrorFingerprint, len(summary.ErrorGroups))
+	for i, group := range summary.ErrorGroups {
+		fingerprints[i] = storage.ErrorFingerprint{
+			RunID:       summary.RunID,
+			Fingerprint: group.ID,
+			Operation:   group.Operation,
+			Selector:    group.Selector,
+			Class:       group.Class,
+			Count:       group.Count,
+			Rate:        group.Rate,
+			Example:     group.Example,
+		}
+	}
+	if saveErr := app.storage.SaveErrorFingerprints(fingerprints); saveErr != nil {
+		app.logger.Warn(ctx, "Failed to save error fingerprints", logger.F("error", saveErr))
+	}
+	if sendErr := app.webhooks.Send(context.WithoutCancel(ctx), summary.FinishedAt, webhook.RunFinished{
+		RunID:      summary.RunID,
+		Mode:       summary.Mode,
+		Status:     summary.Status,
+		Campaign:   summary.Campaign,
+		StartedAt:  summary.StartedAt,
+		FinishedAt: summary.FinishedAt,
+		Attempted:  summary.Attempted,
+		Sent:       summary.Sent,
+		Skipped:    len(summary.Skipped),
+		Failed:     len(summary.Errors),
+	}); sendErr != nil {
+		app.logger.Warn(ctx, "Failed to deliver run webhook", logger.F("error", sendErr))
+	}
+
+	app.logger.Info(ctx, "Run finished",
+		logger.F("mode", summary.Mode),
+		logger.F("status", summary.Status),
+		logger.F("attempted", summary.Attempted),
+		logger.F("sent", summary.Sent),
+		logger.F("skipped", len(summary.Skipped)),
+		logger.F("errors", len(summary.Errors)),
+		logger.F("summary", path))
+	fmt.Printf("\n📊 Run summary (%s, %.0fs)\n", summary.Status, summary.DurationSeconds)
+	fmt.Printf("   • Attempted: %d\n", summary.Attempted)
+	fmt.Printf("   • Sent: %d\n", summary.Sent)
+	fmt.Printf("   • Skipped: %d\n", len(summary.Skipped))
+	for _, reason := range sortedKeys(summary.SkipReasons) {
+		fmt.Printf("      - %s: %d\n", reason, summary.SkipReasons[reason])
+	}
+	fmt.Printf("   • Errors: %d\n", len(summary.Errors))
+	for i, group := range summary.ErrorGroups {
+		if i == topErrorGroups {
+			fmt.Printf("      - %d more kinds of error in the summary\n", len(summary.ErrorGroups)-i)
+			break
+		}
+		fmt.Printf("      - %s failing %.0f%% of the time (%dx)\n", group.Fingerprint, group.Rate*100, group.Count)
+	}
+	if summary.Found > 0 {
+		fmt.Printf("   • Profiles found: %d (%d new)\n", summary.Found, summary.New)
+	}
+	for _, kind := range sortedKeys(summary.Quota) {
+		fmt.Printf("   • Quota used, %s: %d\n", kind, summary.Quota[kind])
+	}
+	for _, stats := range summary.Latency {
+		if stats.Total > 0 {
+			fmt.Printf("   • Latency, %s: %s\n", stats.Kind, formatLatency(stats))
+		}
+	}
+	if summary.StealthSeed != 0 {
+		fmt.Printf("   • Stealth seed: %d (repeat with --seed %d)\n", summary.StealthSeed, summary.StealthSeed)
+	}
+	if path != "" {
+		fmt.Printf("   📄 %s\n", path)
+	}
+}
+
+// selectorError records that operation failed on selector, so the run summary groups the failure
+// with others on the same element
+func selectorError(operation, selector, message string, cause error) *errors.LinkedInError {
+	err := errors.NewError(errors.ErrorTypeTransient, operation, message, cause)
+	if selector != "" {
+		err.WithContext(errors.ContextSelector, selector)
+	}
+	return err
+}
+
+// sortedKeys returns the map's keys in order
+func sortedKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // runDemo runs a comprehensive demonstration of all framework capabilities
 func (app *Application) runDemo(ctx context.Context) error {
 	app.logger.Info(ctx, "🚀 Starting comprehensive LinkedIn Automation Framework demonstration")
@@ -261,24 +972,24 @@ func (app *Application) runDemo(ctx context.Context) error {
 	fmt.Println("Watch the browser window to see human-like automation in action!")
 
 	// Create a new page
-	page, err := app.browserManager.NewPage()
+	page, err := app.browserManager.OpenPage(ctx, "demo")
 	if err != nil {
 		return fmt.Errorf("failed to create page: %w", err)
 	}
-	defer page.Close()
+	defer app.browserManager.ClosePage(page)
 
 	// 1. Demonstrate Browser Management
 	fmt.Println("📱 1. Browser Management Capabilities")
 	app.logger.Info(ctx, "Demonstrating browser initialization and configuration")
 	fmt.Printf("   ✓ Browser initialized: %s mode\n", map[bool]string{true: "headless", false: "visible"}[app.config.Browser.Headless])
 	fmt.Printf("   ✓ Viewport: %dx%d\n", app.config.Browser.ViewportW, app.config.Browser.ViewportH)
-	fmt.Printf("   ✓ User Agent: %s\n", app.config.Browser.UserAgent[:50]+"...")
+	fmt.Printf("   ✓ User Agent: %.50s...\n", app.config.Browser.UserAgent)
 
 	// 2. Demonstrate Navigation
 	fmt.Println("\n🌐 2. Navigation & Page Management")
 	app.logger.Info(ctx, "Demonstrating browser navigation...")
 	if err := page.Navigate("https://www.linkedin.com"); err != nil {
-		app.logger.Warn(ctx, "Navigation failed", logger.F("error", err.Error()))
+		app.logger.Warn(ctx, "Navigation failed", logger.F("error", err))
 		// Try alternative site for demo
 		fmt.Println("   ⚠️  LinkedIn navigation failed, using example.com for demo")
 		if err := page.Navigate("https://example.com"); err != nil {
@@ -288,7 +999,9 @@ func (app *Application) runDemo(ctx context.Context) error {
 	fmt.Println("   ✓ Successfully navigated to target page")
 	
 	// Wait for page load
-	page.MustWaitLoad()
+	if err := app.waitSettled(ctx, page, browser.SettleDOMStable); err != nil {
+		return fmt.Errorf("page load failed: %w", err)
+	}
 	fmt.Println("   ✓ Page fully loaded")
 
 	// 3. Demonstrate Stealth Behaviors
@@ -297,8 +1010,8 @@ func (app *Application) runDemo(ctx context.Context) error {
 	// Random delays
 	app.logger.Info(ctx, "Demonstrating randomized timing...")
 	fmt.Println("   🕐 Applying random delays (human-like timing)...")
-	if err := app.stealthManager.RandomDelay(app.config.Stealth.MinDelay, app.config.Stealth.MaxDelay); err != nil {
-		app.logger.Warn(ctx, "Random delay failed", logger.F("error", err.Error()))
+	if err := app.stealthManager.RandomDelay(ctx, app.config.Stealth.MinDelay, app.config.Stealth.MaxDelay); err != nil {
+		app.logger.Warn(ctx, "Random delay failed", logger.F("error", err))
 	} else {
 		fmt.Println("   ✓ Random delay applied successfully")
 	}
@@ -307,7 +1020,7 @@ func (app *Application) runDemo(ctx context.Context) error {
 	app.logger.Info(ctx, "Demonstrating idle behavior simulation...")
 	fmt.Println("   🖱️  Simulating idle mouse movements...")
 	if err := app.stealthManager.IdleBehavior(ctx, page); err != nil {
-		app.logger.Warn(ctx, "Idle behavior failed", logger.F("error", err.Error()))
+		app.logger.Warn(ctx, "Idle behavior failed", logger.F("error", err))
 		fmt.Println("   ⚠️  Idle behavior simulation failed")
 	} else {
 		fmt.Println("   ✓ Idle mouse movements completed")
@@ -317,7 +1030,7 @@ func (app *Application) runDemo(ctx context.Context) error {
 	app.logger.Info(ctx, "Demonstrating natural scrolling...")
 	fmt.Println("   📜 Performing natural scrolling patterns...")
 	if err := app.stealthManager.ScrollNaturally(ctx, page); err != nil {
-		app.logger.Warn(ctx, "Natural scrolling failed", logger.F("error", err.Error()))
+		app.logger.Warn(ctx, "Natural scrolling failed", logger.F("error", err))
 		fmt.Println("   ⚠️  Natural scrolling failed")
 	} else {
 		fmt.Println("   ✓ Natural scrolling completed")
@@ -367,8 +1080,8 @@ func (app *Application) runDemo(ctx context.Context) error {
 	
 	// One more delay to show timing
 	fmt.Println("   ⏳ Applying final human-like delay...")
-	if err := app.stealthManager.RandomDelay(1*time.Second, 3*time.Second); err != nil {
-		app.logger.Warn(ctx, "Final delay failed", logger.F("error", err.Error()))
+	if err := app.stealthManager.RandomDelay(ctx, 1*time.Second, 3*time.Second); err != nil {
+		app.logger.Warn(ctx, "Final delay failed", logger.F("error", err))
 	}
 
 	// Summary
@@ -404,11 +1117,11 @@ func (app *Application) runDemo(ctx context.Context) error {
 func (app *Application) runSearch(ctx context.Context) error {
 	app.logger.Info(ctx, "Starting search mode")
 
-	page, err := app.browserManager.NewPage()
+	page, err := app.browserManager.OpenPage(ctx, "search")
 	if err != nil {
 		return fmt.Errorf("failed to create page: %w", err)
 	}
-	defer page.Close()
+	defer app.browserManager.ClosePage(page)
 
 	// Navigate to LinkedIn
 	if err := page.Navigate("https://www.linkedin.com"); err != nil {
@@ -425,11 +1138,11 @@ func (app *Application) runSearch(ctx context.Context) error {
 func (app *Application) runConnect(ctx context.Context) error {
 	app.logger.Info(ctx, "Starting connect mode")
 
-	page, err := app.browserManager.NewPage()
+	page, err := app.browserManager.OpenPage(ctx, "connect")
 	if err != nil {
 		return fmt.Errorf("failed to create page: %w", err)
 	}
-	defer page.Close()
+	defer app.browserManager.ClosePage(page)
 
 	// Navigate to LinkedIn
 	if err := page.Navigate("https://www.linkedin.com"); err != nil {
@@ -446,11 +1159,11 @@ func (app *Application) runConnect(ctx context.Context) error {
 func (app *Application) runMessage(ctx context.Context) error {
 	app.logger.Info(ctx, "Starting message mode")
 
-	page, err := app.browserManager.NewPage()
+	page, err := app.browserManager.OpenPage(ctx, "message")
 	if err != nil {
 		return fmt.Errorf("failed to create page: %w", err)
 	}
-	defer page.Close()
+	defer app.browserManager.ClosePage(page)
 
 	// Navigate to LinkedIn
 	if err := page.Navigate("https://www.linkedin.com"); err != nil {
@@ -521,11 +1234,11 @@ func (app *Application) runFullDemo(ctx context.Context) error {
 	app.logger.Info(ctx, "🚀 Starting FULL workflow demonstration (EDUCATIONAL ONLY)")
 	
 	// Create a new page
-	page, err := app.browserManager.NewPage()
+	page, err := app.browserManager.OpenPage(ctx, "full-demo")
 	if err != nil {
 		return fmt.Errorf("failed to create page: %w", err)
 	}
-	defer page.Close()
+	defer app.browserManager.ClosePage(page)
 
 	// 1. Navigation
 	fmt.Println("🌐 Step 1: Navigating to LinkedIn...")
@@ -533,7 +1246,9 @@ func (app *Application) runFullDemo(ctx context.Context) error {
 	if err := page.Navigate("https://www.linkedin.com/login"); err != nil {
 		return fmt.Errorf("navigation failed: %w", err)
 	}
-	page.MustWaitLoad()
+	if err := app.waitSettled(ctx, page, browser.SettleDOMStable); err != nil {
+		return fmt.Errorf("page load failed: %w", err)
+	}
 	fmt.Println("   ✓ Successfully navigated to LinkedIn login page")
 
 	// 2. Authentication Demonstration
@@ -543,7 +1258,7 @@ func (app *Application) runFullDemo(ctx context.Context) error {
 	
 	// Find email field
 	fmt.Println("   🔍 Locating email input field...")
-	emailField, err := page.Timeout(10 * time.Second).Element("#username")
+	emailField, err := page.Timeout(app.timeouts().Login).Element("#username")
 	if err != nil {
 		fmt.Printf("   ❌ Could not find email field: %v\n", err)
 		fmt.Println("   ℹ️  This is expected - LinkedIn has anti-automation measures")
@@ -560,7 +1275,7 @@ func (app *Application) runFullDemo(ctx context.Context) error {
 	
 	// Find password field
 	fmt.Println("   🔍 Locating password input field...")
-	passwordField, err := page.Timeout(5 * time.Second).Element("#password")
+	passwordField, err := page.Timeout(app.timeouts().Login).Element("#password")
 	if err != nil {
 		fmt.Printf("   ❌ Could not find password field: %v\n", err)
 		return app.runSafeDemo(ctx, page)
@@ -576,11 +1291,11 @@ func (app *Application) runFullDemo(ctx context.Context) error {
 	
 	// Human-like delay before clicking
 	fmt.Println("   ⏳ Applying human-like delay before login...")
-	app.stealthManager.RandomDelay(2*time.Second, 4*time.Second)
+	app.stealthManager.RandomDelay(ctx, 2*time.Second, 4*time.Second)
 	
 	// Find and click login button
 	fmt.Println("   🖱️  Locating and clicking login button...")
-	loginButton, err := page.Timeout(5 * time.Second).Element("button[type='submit']")
+	loginButton, err := page.Timeout(app.timeouts().Login).Element("button[type='submit']")
 	if err != nil {
 		fmt.Printf("   ❌ Could not find login button: %v\n", err)
 		return app.runSafeDemo(ctx, page)
@@ -593,7 +1308,7 @@ func (app *Application) runFullDemo(ctx context.Context) error {
 	}
 	
 	// Use safe click with error handling
-	if err := loginButton.Click(proto.InputMouseButtonLeft, 1); err != nil {
+	if err := browser.Click(ctx, loginButton, app.timeouts().Element); err != nil {
 		fmt.Printf("   ⚠️  Login button click failed: %v\n", err)
 		return app.runSafeDemo(ctx, page)
 	}
@@ -646,12 +1361,26 @@ func (app *Application) runSafeDemo(ctx context.Context, page *rod.Page) error {
 
 // cleanup performs graceful cleanup of all resources
 func (app *Application) cleanup() {
+	if app.leadFilter != nil {
+		app.leadFilter.Close()
+	}
+
 	if app.storage != nil {
 		if err := app.storage.Close(); err != nil {
 			log.Printf("Error closing storage: %v", err)
 		}
 	}
 	
+	if app.replayRouter != nil {
+		if err := app.replayRouter.Stop(); err != nil {
+			log.Printf("Error stopping replay: %v", err)
+		}
+	}
+
+	if err := app.stealthTracer.Close(); err != nil {
+		log.Printf("Error closing stealth trace: %v", err)
+	}
+
 	if app.browserManager != nil {
 		if err := app.browserManager.Close(); err != nil {
 			log.Printf("Error closing browser: %v", err)
@@ -694,11 +1423,18 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 	app.logger.Info(ctx, "🚀 Starting COMPREHENSIVE manual login + automation demo")
 
 	// Create a new page
-	page, err := app.browserManager.NewPage()
+	page, err := app.browserManager.OpenPage(ctx, "manual-login")
 	if err != nil {
 		return fmt.Errorf("failed to create page: %w", err)
 	}
-	defer page.Close()
+	defer app.browserManager.ClosePage(page)
+
+	// A browser LinkedIn remembered after an earlier verification is not challenged again
+	if err := app.browserManager.LoadTrustedDevice(app.config.Browser.TrustedDevicePath); err == nil {
+		fmt.Println("🔑 Restored trusted device cookies from an earlier verification")
+	} else if !stderrors.Is(err, browser.ErrNoTrustedDevice) {
+		fmt.Printf("⚠️  Could not restore trusted device cookies: %v\n", err)
+	}
 
 	// Navigate to LinkedIn
 	fmt.Println("🌐 Phase 1: Opening LinkedIn Login Page")
@@ -706,7 +1442,9 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 	if err := page.Navigate("https://www.linkedin.com/login"); err != nil {
 		return fmt.Errorf("navigation failed: %w", err)
 	}
-	page.MustWaitLoad()
+	if err := app.waitSettled(ctx, page, browser.SettleDOMStable); err != nil {
+		return fmt.Errorf("page load failed: %w", err)
+	}
 	fmt.Println("   ✅ LinkedIn login page loaded successfully")
 	fmt.Println("   📱 Browser window should now be visible")
 
@@ -714,7 +1452,7 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 	fmt.Println("\n👤 Phase 2: Manual Authentication (YOUR TURN!)")
 	fmt.Println("   🔐 Please complete login in the browser window:")
 	fmt.Println("      • Enter your email and password")
-	fmt.Println("      • Complete any 2FA challenges")
+	fmt.Println("      • Complete any 2FA challenges (\"Remember this browser\" skips them next time)")
 	fmt.Println("      • Solve any CAPTCHA if presented")
 	fmt.Println("      • Navigate to your LinkedIn feed/homepage")
 	fmt.Println("      • Ensure you're fully logged in")
@@ -722,9 +1460,9 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 	fmt.Println("   ⏳ Take your time - no rush!")
 	
 	// Wait for user input
-	fmt.Print("\n🎬 Press ENTER when logged in and ready for the automation show: ")
-	var input string
-	fmt.Scanln(&input)
+	if err := app.waitForLogin(ctx, page, "\n🎬 Press ENTER when logged in and ready for the automation show: "); err != nil {
+		return err
+	}
 
 	// Enhanced login verification
 	fmt.Println("\n🔍 Phase 3: Login Verification & Session Analysis")
@@ -809,7 +1547,7 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 		} else {
 			fmt.Printf("   ✅ Scroll sequence %d completed\n", i+1)
 		}
-		app.stealthManager.RandomDelay(1*time.Second, 3*time.Second)
+		app.stealthManager.RandomDelay(ctx, 1*time.Second, 3*time.Second)
 	}
 
 	// Demo 2: Sophisticated Mouse Behavior
@@ -827,7 +1565,7 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 		} else {
 			fmt.Printf("   ✅ Mouse pattern %d completed\n", i+1)
 		}
-		app.stealthManager.RandomDelay(500*time.Millisecond, 2*time.Second)
+		app.stealthManager.RandomDelay(ctx, 500*time.Millisecond, 2*time.Second)
 	}
 
 	// Demo 3: Human Timing Analysis
@@ -858,7 +1596,7 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 	
 	searchQueries := []string{"software engineer", "data scientist", "product manager", "UX designer"}
 	
-	if searchBox, err := page.Timeout(5 * time.Second).Element("input[placeholder*='Search']"); err == nil {
+	if searchBox, err := page.Timeout(app.timeouts().Element).Element("input[placeholder*='Search']"); err == nil {
 		fmt.Println("   ✅ Search interface located successfully")
 		
 		for i, query := range searchQueries {
@@ -867,8 +1605,7 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 			// Human-like click
 			fmt.Println("      🖱️  Performing human-like click on search box...")
 			if err := app.stealthManager.HumanMouseMove(ctx, page, searchBox); err == nil {
-				// Use safe click with error handling instead of MustClick
-				if err := searchBox.Click(proto.InputMouseButtonLeft, 1); err != nil {
+				if err := browser.Click(ctx, searchBox, app.timeouts().Element); err != nil {
 					fmt.Printf("      ⚠️  Click failed: %v\n", err)
 					continue
 				}
@@ -880,7 +1617,7 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 					
 					// Pause to "read" suggestions
 					fmt.Println("      👀 Pausing to 'read' search suggestions...")
-					app.stealthManager.RandomDelay(2*time.Second, 4*time.Second)
+					app.stealthManager.RandomDelay(ctx, 2*time.Second, 4*time.Second)
 					
 					// Clear search with safe methods
 					fmt.Println("      🧹 Clearing search with human-like selection...")
@@ -899,7 +1636,7 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 			}
 			
 			if i < len(searchQueries)-1 {
-				app.stealthManager.RandomDelay(1*time.Second, 3*time.Second)
+				app.stealthManager.RandomDelay(ctx, 1*time.Second, 3*time.Second)
 			}
 		}
 	} else {
@@ -924,7 +1661,7 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 				
 				// Simulate reading/thinking time
 				fmt.Println("      🤔 Simulating decision-making pause...")
-				app.stealthManager.RandomDelay(1*time.Second, 2500*time.Millisecond)
+				app.stealthManager.RandomDelay(ctx, 1*time.Second, 2500*time.Millisecond)
 			} else {
 				fmt.Printf("      ⚠️  Hover failed: %v\n", err)
 			}
@@ -976,6 +1713,11 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 	} else {
 		fmt.Println("      ✅ Session cookies saved successfully")
 	}
+	if err := app.browserManager.SaveTrustedDevice(app.config.Browser.TrustedDevicePath); err == nil {
+		fmt.Println("      🔑 Trusted device cookies saved for future logins")
+	} else if !stderrors.Is(err, browser.ErrNoTrustedDevice) {
+		fmt.Printf("      ⚠️  Trusted device saving failed: %v\n", err)
+	}
 	
 	fmt.Println("   🔍 Analyzing cookie security attributes...")
 	secureCount := 0
@@ -1000,8 +1742,8 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 	
 	if userAgent, err := page.Eval("() => navigator.userAgent"); err == nil {
 		userAgentStr := userAgent.Value.String()
-		if len(userAgentStr) > 80 {
-			fmt.Printf("      🌐 User Agent: %s...\n", userAgentStr[:80])
+		if utf8.RuneCountInString(userAgentStr) > 80 {
+			fmt.Printf("      🌐 User Agent: %.80s...\n", userAgentStr)
 		} else {
 			fmt.Printf("      🌐 User Agent: %s\n", userAgentStr)
 		}
@@ -1064,7 +1806,7 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 		app.stealthManager.ScrollNaturally(ctx, page)
 		fmt.Println("      📊 Scroll-triggered network activity simulated")
 		
-		app.stealthManager.RandomDelay(2*time.Second, 4*time.Second)
+		app.stealthManager.RandomDelay(ctx, 2*time.Second, 4*time.Second)
 		fmt.Printf("      ✅ Network burst %d completed\n", i+1)
 	}
 
@@ -1096,7 +1838,7 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 		fmt.Printf("      ⚡ Action %d/5: Simulating rate-limited operation...\n", i+1)
 		
 		// Simulate an action that would be rate limited
-		app.stealthManager.RandomDelay(
+		app.stealthManager.RandomDelay(ctx,
 			app.config.Stealth.MinDelay,
 			app.config.Stealth.MaxDelay,
 		)
@@ -1144,12 +1886,13 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 	if err := page.Navigate(searchURL); err != nil {
 		fmt.Printf("   ⚠️  Search navigation failed: %v\n", err)
 	} else {
-		page.MustWaitLoad()
-		fmt.Println("   ✅ Search page loaded successfully")
-		
-		// Wait for search results to load
+		// Results arrive over XHR after the load, so wait for the network to go quiet
 		fmt.Println("   ⏳ Waiting for search results to load...")
-		time.Sleep(3 * time.Second)
+		if err := app.waitSettled(ctx, page, browser.SettleNetworkIdle); err != nil {
+			fmt.Printf("   ⚠️  Search page did not load: %v\n", err)
+		} else {
+			fmt.Println("   ✅ Search page loaded successfully")
+		}
 		
 		// Try to extract profile information
 		fmt.Println("   📊 Analyzing search results...")
@@ -1183,7 +1926,7 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 				fmt.Printf("      ✅ Profile %d analysis complete\n", i+1)
 				
 				// Human-like delay between profile analysis
-				app.stealthManager.RandomDelay(500*time.Millisecond, 1500*time.Millisecond)
+				app.stealthManager.RandomDelay(ctx, 500*time.Millisecond, 1500*time.Millisecond)
 			}
 		} else {
 			fmt.Println("   ℹ️  No profile results found (may require login or different search)")
@@ -1197,11 +1940,7 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 	fmt.Println("   ⚠️  Only proceed if you want to actually connect with people")
 	
 	// Ask user for confirmation
-	fmt.Print("\n🔄 Do you want to send REAL connection requests? (y/N): ")
-	var confirmInput string
-	fmt.Scanln(&confirmInput)
-	
-	if strings.ToLower(confirmInput) == "y" || strings.ToLower(confirmInput) == "yes" {
+	if app.confirm("\n🔄 Do you want to send REAL connection requests? (y/N): ") {
 		fmt.Println("   ✅ User confirmed - proceeding with REAL connection requests")
 		
 		// Step 1: Navigate back to search results if not already there
@@ -1210,13 +1949,17 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 		if err := page.Navigate(searchURL); err != nil {
 			fmt.Printf("      ⚠️  Search navigation failed: %v\n", err)
 		} else {
-			page.WaitLoad()
+			if err := browser.WaitLoad(ctx, page, app.timeouts().Navigation); err != nil {
+				fmt.Printf("      ⚠️  Search results did not load: %v\n", err)
+			}
+			app.checkpoint(ctx, page, "search-results")
 			fmt.Println("      ✅ Search results loaded")
 			
 			// Step 2: Find profiles with Connect buttons
 			fmt.Println("   🎯 Step 2: Finding profiles with Connect buttons...")
 			
 			if profiles, err := page.Elements(".reusable-search__result-container"); err == nil {
+				profiles = app.prioritizeCards(ctx, profiles)
 				connectableProfiles := 0
 				maxConnections := 2 // Limit to 2 connections for safety
 				
@@ -1226,24 +1969,28 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 					}
 					
 					fmt.Printf("      👤 Analyzing profile %d for connection opportunity...\n", i+1)
+					app.summary.Attempt()
+					target := profileCardURL(profile)
 					
 					// Look for Connect button with multiple selectors
 					var connectBtn *rod.Element
 					var connectBtnErr error
+					var connectSelector string
 					
 					// Try multiple Connect button selectors (LinkedIn changes these frequently)
-					connectSelectors := []string{
+					connectSelectors := selectors.Localize([]string{
 						"button[aria-label*='Connect']",
 						"button[data-control-name='srp_profile_actions_connect']", 
 						"button:contains('Connect')",
 						"button[aria-label*='Invite']",
 						".search-result__actions button:first-child",
-					}
+					}, app.config.Browser.UILanguages)
 					
 					for _, selector := range connectSelectors {
 						if btn, err := profile.Element(selector); err == nil {
 							connectBtn = btn
 							connectBtnErr = nil
+							connectSelector = selector
 							break
 						} else {
 							connectBtnErr = err
@@ -1282,37 +2029,49 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 							}
 						}
 						
-						// Quality assessment criteria
-						qualityScore := 0
-						qualityReasons := []string{}
-						
-						if profileName != "there" && profileName != "" {
-							qualityScore++
-							qualityReasons = append(qualityReasons, "✓ Has name")
-						}
-						
-						if strings.Contains(strings.ToLower(profileTitle), "engineer") || 
-						   strings.Contains(strings.ToLower(profileTitle), "developer") ||
-						   strings.Contains(strings.ToLower(profileTitle), "software") {
-							qualityScore++
-							qualityReasons = append(qualityReasons, "✓ Relevant title")
+						// Quality assessment via the configured lead filter
+						candidate := leadfilter.Profile{Title: profileTitle, Company: profileCompany}
+						if profileName != "there" {
+							candidate.Name = profileName
 						}
-						
-						if profileCompany != "" {
-							qualityScore++
-							qualityReasons = append(qualityReasons, "✓ Has company")
+						candidate = app.withCompany(ctx, candidate)
+						decision, err := app.leadFilter.Evaluate(ctx, candidate)
+						if err != nil {
+							fmt.Printf("         ⚠️  Lead filter failed: %v - skipping connection\n", err)
+							app.summary.Fail(target, err)
+							continue
 						}
 						
-						fmt.Printf("         📊 Profile quality score: %d/3\n", qualityScore)
-						for _, reason := range qualityReasons {
-							fmt.Printf("            %s\n", reason)
+						fmt.Printf("         📊 Profile quality score: %.1f\n", decision.Score)
+						if decision.Reason != "" {
+							fmt.Printf("            %s\n", decision.Reason)
 						}
 						
-						// Only proceed if quality score is acceptable
-						if qualityScore >= 2 {
+						// Only proceed if the filter accepts the profile
+						if decision.Accept {
 							fmt.Printf("         ✅ Profile quality acceptable - proceeding with connection\n")
 						} else {
 							fmt.Printf("         ⚠️  Profile quality too low - skipping connection\n")
+							app.summary.Skip(target, runs.SkipLowQuality)
+							continue
+						}
+						
+						// Skip people no account of the deployment may contact
+						if app.suppressed(target) {
+							fmt.Printf("         ⏭️  On the suppression list - skipping connection\n")
+							continue
+						}
+
+						// Skip people snoozed by hand until a later date
+						if app.snoozed(target) {
+							fmt.Printf("         ⏭️  Snoozed - skipping connection\n")
+							continue
+						}
+
+						// Skip people already connected through another account or a manual connect
+						if connected, reason := app.network.InNetwork(target, candidate.Name, profileCompany); connected {
+							fmt.Printf("         ⏭️  Already in network (%s) - skipping connection\n", reason)
+							app.summary.Skip(target, runs.SkipAlreadyConnected)
 							continue
 						}
 						
@@ -1337,13 +2096,14 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 						
 						// Click the Connect button
 						fmt.Println("         🎯 Clicking Connect button...")
-						if err := connectBtn.Click(proto.InputMouseButtonLeft, 1); err != nil {
+						if err := browser.Click(ctx, connectBtn, app.timeouts().Element); err != nil {
 							fmt.Printf("         ❌ Connect button click failed: %v\n", err)
 							fmt.Println("         🔍 Trying alternative click method...")
 							
 							// Try JavaScript click as fallback
 							if _, err := connectBtn.Eval("() => this.click()"); err != nil {
 								fmt.Printf("         ❌ JavaScript click also failed: %v\n", err)
+								app.summary.Fail(target, selectorError("click_connect_button", connectSelector, "failed to click connect", err))
 								continue
 							}
 						}
@@ -1381,20 +2141,32 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 							}
 						}
 						
+						app.checkpoint(ctx, page, "invite-dialog")
+						
+						// The dialog may be LinkedIn's invitation limit instead of the invite form
+						if app.stopForInviteLimit(ctx, page, profiles[i:]) {
+							break
+						}
+						if app.skipEmailRequiredInvite(ctx, page, profile) {
+							continue
+						}
+						
 						if !dialogFound {
 							fmt.Println("         ⚠️  No connection dialog found - connection may have been sent directly")
 							connectableProfiles++
+							app.summary.Sent(runs.QuotaConnections)
 						} else {
 							// Look for "Add a note" button with multiple selectors
 							fmt.Println("         📝 Looking for 'Add a note' option...")
 							
-							addNoteSelectors := []string{
+							addNoteSelectors := selectors.Localize([]string{
 								"button[aria-label*='Add a note']",
 								"button:contains('Add a note')",
 								".send-invite__custom-message button",
 								"button[data-control-name='add_note']",
-							}
+							}, app.config.Browser.UILanguages)
 							
+							noteSent := false
 							var addNoteBtn *rod.Element
 							for _, selector := range addNoteSelectors {
 								if btn, err := page.Element(selector); err == nil {
@@ -1408,11 +2180,12 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 								fmt.Println("         📝 Adding personalized message...")
 								
 								// Click "Add a note"
-								if err := addNoteBtn.Click(proto.InputMouseButtonLeft, 1); err != nil {
+								if err := browser.Click(ctx, addNoteBtn, app.timeouts().Element); err != nil {
 									fmt.Printf("         ⚠️  Add note button click failed: %v\n", err)
 								} else {
 									// Wait for note textarea with multiple selectors
-									time.Sleep(2 * time.Second)
+									app.waitSettled(ctx, page, browser.SettleDOMStable)
+									app.checkpoint(ctx, page, "note-form")
 									
 									textareaSelectors := []string{
 										"textarea[name='message']",
@@ -1439,8 +2212,12 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 										// Type with human-like behavior
 										if err := app.stealthManager.HumanType(ctx, noteTextarea, personalizedNote); err != nil {
 											fmt.Printf("         ⚠️  Note typing failed: %v\n", err)
+											// A half-typed note must not go out with the blank invite
+											_ = noteTextarea.SelectAllText()
+											_ = noteTextarea.Input("")
 										} else {
 											fmt.Println("         ✅ Personalized note entered")
+											noteSent = true
 										}
 									} else {
 										fmt.Println("         ⚠️  Note textarea not found")
@@ -1452,16 +2229,22 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 							fmt.Println("         📤 Looking for Send button...")
 							
 							// Look for Send button with multiple selectors
-							sendSelectors := []string{
+							sendSelectors := selectors.Localize([]string{
 								"button[aria-label*='Send']",
 								"button:contains('Send')",
 								"button[data-control-name='send']",
 								".send-invite__actions button[type='submit']",
 								"button[aria-label*='Send invitation']",
-							}
+							}, app.config.Browser.UILanguages)
 							
 							var sendBtn *rod.Element
+							if !noteSent {
+								sendBtn = app.sendWithoutNoteButton(ctx, page, profileName)
+							}
 							for _, selector := range sendSelectors {
+								if sendBtn != nil {
+									break
+								}
 								if btn, err := page.Element(selector); err == nil {
 									sendBtn = btn
 									fmt.Printf("         ✅ Send button found with selector: %s\n", selector)
@@ -1472,23 +2255,38 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 							if sendBtn != nil {
 								// Human-like delay before sending
 								fmt.Println("         🤔 Taking a moment to review the request...")
-								app.stealthManager.RandomDelay(2*time.Second, 4*time.Second)
+								app.stealthManager.RandomDelay(ctx, 2*time.Second, 4*time.Second)
 								
 								// Click Send
 								fmt.Println("         🎯 Clicking Send button...")
-								if err := sendBtn.Click(proto.InputMouseButtonLeft, 1); err != nil {
+								sent := false
+								if err := browser.Click(ctx, sendBtn, app.timeouts().Element); err != nil {
 									fmt.Printf("         ❌ Send button click failed: %v\n", err)
 									
 									// Try JavaScript click as fallback
 									if _, err := sendBtn.Eval("() => this.click()"); err != nil {
 										fmt.Printf("         ❌ JavaScript Send click also failed: %v\n", err)
+										app.summary.Fail(target, selectorError("click_send_button", "", "failed to click send", err))
 									} else {
 										fmt.Printf("         🎉 Connection request sent to %s! (via JavaScript)\n", profileName)
 										connectableProfiles++
+										sent = true
 									}
 								} else {
 									fmt.Printf("         🎉 Connection request sent to %s!\n", profileName)
 									connectableProfiles++
+									sent = true
+								}
+								
+								// LinkedIn answers an invite it will not send with a limit modal or toast
+								if app.stopForInviteLimit(ctx, page, profiles[i:]) {
+									if sent {
+										connectableProfiles--
+									}
+									break
+								}
+								if sent {
+									app.summary.Sent(runs.QuotaConnections)
 								}
 								
 								if connectableProfiles > 0 {
@@ -1498,10 +2296,11 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 									
 									// Rate limiting delay
 									fmt.Println("         ⏱️  Applying rate limiting delay...")
-									app.stealthManager.RandomDelay(10*time.Second, 20*time.Second)
+									app.stealthManager.RandomDelay(ctx, 10*time.Second, 20*time.Second)
 								}
 							} else {
 								fmt.Println("         ⚠️  Send button not found")
+								app.summary.Fail(target, selectorError("find_send_button", strings.Join(sendSelectors, ", "), "send button not found", nil).WithCode(errors.CodeSelectorMiss))
 								fmt.Println("         🔍 Available buttons in dialog:")
 								
 								// Debug: list all buttons in the dialog
@@ -1529,7 +2328,7 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 						
 						for _, selector := range closeSelectors {
 							if closeBtn, err := page.Element(selector); err == nil {
-								closeBtn.Click(proto.InputMouseButtonLeft, 1)
+								browser.Click(ctx, closeBtn, app.timeouts().Element)
 								fmt.Println("         ✅ Dialog closed")
 								break
 							}
@@ -1537,6 +2336,7 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 						
 					} else {
 						fmt.Printf("         ℹ️  No Connect button found on profile %d\n", i+1)
+						app.summary.Skip(target, runs.SkipNoConnectButton)
 						fmt.Printf("         🔍 Debug - Connect button search failed: %v\n", connectBtnErr)
 						
 						// Debug: Show what buttons are available in this profile
@@ -1557,15 +2357,12 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 					}
 					
 					// Small delay between profile analysis
-					app.stealthManager.RandomDelay(1*time.Second, 3*time.Second)
+					app.stealthManager.RandomDelay(ctx, 1*time.Second, 3*time.Second)
 				}
 				
-				fmt.Printf("\n   🎉 Connection Request Automation Summary\n")
+				// The results themselves are written to the run summary when the run finishes
+				fmt.Printf("\n   🎉 Connection Request Automation Complete\n")
 				fmt.Printf("   ═══════════════════════════════════════\n")
-				fmt.Printf("   📊 Total connection requests sent: %d/%d\n", connectableProfiles, maxConnections)
-				fmt.Printf("   ⏱️  Rate limit: %d connections/hour\n", app.config.RateLimit.ConnectionsPerHour)
-				fmt.Printf("   🕐 Remaining quota: %d connections\n", app.config.RateLimit.ConnectionsPerHour-connectableProfiles)
-				fmt.Printf("   🎯 Success rate: %.1f%%\n", float64(connectableProfiles)/float64(maxConnections)*100)
 				fmt.Printf("   ⚠️  Remember: Use connection requests responsibly!\n")
 				
 				if connectableProfiles > 0 {
@@ -1640,13 +2437,13 @@ func (app *Application) runManualLogin(ctx context.Context) error {
 	app.stealthManager.ScrollNaturally(ctx, page)
 	
 	fmt.Println("      2️⃣  Profile evaluation with natural timing...")
-	app.stealthManager.RandomDelay(2*time.Second, 4*time.Second)
+	app.stealthManager.RandomDelay(ctx, 2*time.Second, 4*time.Second)
 	
 	fmt.Println("      3️⃣  Connection request with stealth behaviors...")
 	app.stealthManager.IdleBehavior(ctx, page)
 	
 	fmt.Println("      4️⃣  Rate limiting and cooldown enforcement...")
-	app.stealthManager.RandomDelay(1*time.Second, 3*time.Second)
+	app.stealthManager.RandomDelay(ctx, 1*time.Second, 3*time.Second)
 	
 	fmt.Println("      5️⃣  Message follow-up with human patterns...")
 	app.stealthManager.ScrollNaturally(ctx, page)
@@ -1755,44 +2552,51 @@ func (app *Application) runConnectOnly(ctx context.Context) error {
 	app.logger.Info(ctx, "🚀 Starting connection-only automation mode")
 
 	// Create a new page
-	page, err := app.browserManager.NewPage()
+	page, err := app.browserManager.OpenPage(ctx, "connect-only")
 	if err != nil {
 		return fmt.Errorf("failed to create page: %w", err)
 	}
-	defer page.Close()
+	defer app.browserManager.ClosePage(page)
 
 	// Navigate to LinkedIn
 	fmt.Println("🌐 Opening LinkedIn login page...")
 	if err := page.Navigate("https://www.linkedin.com/login"); err != nil {
 		return fmt.Errorf("navigation failed: %w", err)
 	}
-	page.WaitLoad()
+	if err := browser.WaitLoad(ctx, page, app.timeouts().Navigation); err != nil {
+		return fmt.Errorf("page load failed: %w", err)
+	}
 	fmt.Println("   ✅ LinkedIn login page loaded")
 
 	// Wait for manual login
 	fmt.Println("\n👤 Please login manually in the browser window...")
-	fmt.Print("🔄 Press ENTER when logged in and ready to start connecting: ")
-	var input string
-	fmt.Scanln(&input)
+	if err := app.waitForLogin(ctx, page, "🔄 Press ENTER when logged in and ready to start connecting: "); err != nil {
+		return err
+	}
 
 	// Get connection preferences from user
 	fmt.Println("\n⚙️  Connection Request Configuration")
 	fmt.Println("   Let's configure your connection request preferences...")
 	
-	fmt.Print("   🔢 How many connection requests to send? (1-10, default 3): ")
-	var maxConnectionsInput string
-	fmt.Scanln(&maxConnectionsInput)
-	
 	maxConnections := 3 // default
-	if maxConnectionsInput != "" {
-		if parsed, err := strconv.Atoi(maxConnectionsInput); err == nil && parsed >= 1 && parsed <= 10 {
-			maxConnections = parsed
+	if app.answers.maxConnections > 0 {
+		maxConnections = app.answers.maxConnections
+	} else if !app.answers.yes {
+		fmt.Print("   🔢 How many connection requests to send? (1-10, default 3): ")
+		var maxConnectionsInput string
+		fmt.Scanln(&maxConnectionsInput)
+		if maxConnectionsInput != "" {
+			if parsed, err := strconv.Atoi(maxConnectionsInput); err == nil && parsed >= 1 && parsed <= 10 {
+				maxConnections = parsed
+			}
 		}
 	}
 	
-	fmt.Print("   🔍 Search keywords (default 'software engineer'): ")
-	var searchKeywords string
-	fmt.Scanln(&searchKeywords)
+	searchKeywords := app.answers.keywords
+	if searchKeywords == "" && !app.answers.yes {
+		fmt.Print("   🔍 Search keywords (default 'software engineer'): ")
+		fmt.Scanln(&searchKeywords)
+	}
 	
 	if searchKeywords == "" {
 		searchKeywords = "software engineer"
@@ -1808,7 +2612,10 @@ func (app *Application) runConnectOnly(ctx context.Context) error {
 	if err := page.Navigate(searchURL); err != nil {
 		return fmt.Errorf("search navigation failed: %w", err)
 	}
-	page.WaitLoad()
+	if err := browser.WaitLoad(ctx, page, app.timeouts().Navigation); err != nil {
+		return fmt.Errorf("search page load failed: %w", err)
+	}
+	app.checkpoint(ctx, page, "search-results")
 	fmt.Println("   ✅ Search results loaded")
 
 	// Start connection automation
@@ -1816,20 +2623,24 @@ func (app *Application) runConnectOnly(ctx context.Context) error {
 	fmt.Println("   ═══════════════════════════════════════════════════")
 	
 	if profiles, err := page.Elements(".reusable-search__result-container"); err == nil {
+		// The leads that matter most get the run's requests first
+		profiles = app.prioritizeCards(ctx, profiles)
 		connectableProfiles := 0
 		attemptedProfiles := 0
 		
-		for _, profile := range profiles {
+		for i, profile := range profiles {
 			if connectableProfiles >= maxConnections {
 				break
 			}
 			
 			attemptedProfiles++
+			app.summary.Attempt()
+			target := profileCardURL(profile)
 			fmt.Printf("\n   👤 Profile %d/%d Analysis\n", attemptedProfiles, len(profiles))
 			fmt.Println("   ─────────────────────────")
 			
 			// Profile quality assessment (same as in manual-login mode)
-			if connectBtn, err := profile.Element("button[aria-label*='Connect']"); err == nil {
+			if connectBtn := app.findLocalized(profile, "button[aria-label*='Connect']"); connectBtn != nil {
 				fmt.Println("      ✅ Connect button available")
 				
 				// Extract and assess profile
@@ -1851,80 +2662,121 @@ func (app *Application) runConnectOnly(ctx context.Context) error {
 					}
 				}
 				
-				// Quality assessment
-				qualityScore := 0
-				if profileName != "Professional" && profileName != "" {
-					qualityScore++
+				// Quality assessment via the configured lead filter
+				candidate := leadfilter.Profile{Title: profileTitle, Company: profileCompany}
+				if profileName != "Professional" {
+					candidate.Name = profileName
+				}
+				candidate = app.withCompany(ctx, candidate)
+				decision, err := app.leadFilter.Evaluate(ctx, candidate)
+				if err != nil {
+					fmt.Printf("      ⚠️  Lead filter failed: %v\n", err)
+					app.summary.Fail(target, err)
+					continue
 				}
-				if strings.Contains(strings.ToLower(profileTitle), "engineer") || 
-				   strings.Contains(strings.ToLower(profileTitle), "developer") ||
-				   strings.Contains(strings.ToLower(profileTitle), "software") {
-					qualityScore++
+				
+				fmt.Printf("      📊 Quality Score: %.1f\n", decision.Score)
+				
+				if app.suppressed(target) {
+					fmt.Println("      ⏭️  On the suppression list - skipping")
+					continue
 				}
-				if profileCompany != "" {
-					qualityScore++
+
+				if app.snoozed(target) {
+					fmt.Println("      ⏭️  Snoozed - skipping")
+					continue
 				}
 				
-				fmt.Printf("      📊 Quality Score: %d/3\n", qualityScore)
+				if connected, reason := app.network.InNetwork(target, candidate.Name, profileCompany); connected {
+					fmt.Printf("      ⏭️  Already in network (%s) - skipping\n", reason)
+					app.summary.Skip(target, runs.SkipAlreadyConnected)
+					continue
+				}
 				
-				if qualityScore >= 2 {
+				if decision.Accept {
 					fmt.Println("      ✅ Quality acceptable - sending connection request")
 					
 					// Send connection request with same logic as manual-login mode
 					if err := app.stealthManager.HumanMouseMove(ctx, page, connectBtn); err == nil {
-						if err := connectBtn.Click(proto.InputMouseButtonLeft, 1); err == nil {
+						if err := browser.Click(ctx, connectBtn, app.timeouts().Element); err == nil {
 							fmt.Printf("      🤝 Connection request initiated for %s\n", profileName)
 							
-							// Handle dialog and send personalized note
-							time.Sleep(2 * time.Second)
+							// Handle dialog and send personalized note, once it has rendered
+							app.waitSettled(ctx, page, browser.SettleDOMStable)
+							app.checkpoint(ctx, page, "invite-dialog")
+							if app.skipEmailRequiredInvite(ctx, page, profile) {
+								continue
+							}
 							
-							if addNoteBtn, err := page.Element("button[aria-label*='Add a note']"); err == nil {
-								addNoteBtn.Click(proto.InputMouseButtonLeft, 1)
+							noteSent := false
+							if addNoteBtn := app.findLocalized(page, "button[aria-label*='Add a note']"); addNoteBtn != nil {
+								browser.Click(ctx, addNoteBtn, app.timeouts().Element)
 								time.Sleep(1 * time.Second)
+								app.checkpoint(ctx, page, "note-form")
 								
 								if noteTextarea, err := page.Element("textarea[name='message']"); err == nil {
 									personalizedNote := fmt.Sprintf("Hi %s! I found your profile while searching for %s professionals. I'd love to connect and share insights about our industry.", profileName, searchKeywords)
 									
 									if err := app.stealthManager.HumanType(ctx, noteTextarea, personalizedNote); err == nil {
 										fmt.Println("      📝 Personalized note added")
+										noteSent = true
+									} else {
+										_ = noteTextarea.SelectAllText()
+										_ = noteTextarea.Input("")
 									}
 								}
 							}
 							
-							// Send the request
-							if sendBtn, err := page.Element("button[aria-label*='Send']"); err == nil {
-								app.stealthManager.RandomDelay(2*time.Second, 4*time.Second)
-								if err := sendBtn.Click(proto.InputMouseButtonLeft, 1); err == nil {
+							// Send the request, blank if the note could not be added
+							var sendBtn *rod.Element
+							if !noteSent {
+								sendBtn = app.sendWithoutNoteButton(ctx, page, profileName)
+							}
+							if sendBtn == nil {
+								sendBtn = app.findLocalized(page, "button[aria-label*='Send']")
+							}
+							if sendBtn != nil {
+								app.stealthManager.RandomDelay(ctx, 2*time.Second, 4*time.Second)
+								if err := browser.Click(ctx, sendBtn, app.timeouts().Element); err == nil {
 									fmt.Printf("      🎉 Connection request sent to %s!\n", profileName)
 									connectableProfiles++
+									if app.stopForInviteLimit(ctx, page, profiles[i:]) {
+										connectableProfiles--
+										break
+									}
+									app.summary.Sent(runs.QuotaConnections)
 									
 									// Rate limiting delay
 									fmt.Println("      ⏱️  Applying safety delay...")
-									app.stealthManager.RandomDelay(15*time.Second, 25*time.Second)
+									app.stealthManager.RandomDelay(ctx, 15*time.Second, 25*time.Second)
+								} else {
+									app.summary.Fail(target, selectorError("click_send_button", "", "failed to click send", err))
 								}
+							} else {
+								app.summary.Fail(target, selectorError("find_send_button", "button[aria-label*='Send']", "send button not found", nil).WithCode(errors.CodeSelectorMiss))
 							}
+						} else {
+							app.summary.Fail(target, selectorError("click_connect_button", "button[aria-label*='Connect']", "failed to click connect", err))
 						}
+					} else {
+						app.summary.Fail(target, selectorError("move_to_connect_button", "button[aria-label*='Connect']", "failed to reach connect button", err))
 					}
 				} else {
 					fmt.Println("      ⚠️  Quality too low - skipping")
+					app.summary.Skip(target, runs.SkipLowQuality)
 				}
 			} else {
 				fmt.Println("      ℹ️  No Connect button (already connected or premium required)")
+				app.summary.Skip(target, runs.SkipNoConnectButton)
 			}
 			
 			// Small delay between profiles
-			app.stealthManager.RandomDelay(2*time.Second, 5*time.Second)
+			app.stealthManager.RandomDelay(ctx, 2*time.Second, 5*time.Second)
 		}
 		
-		// Final summary
+		// The results themselves are written to the run summary when the run finishes
 		fmt.Printf("\n🎊 Connection Automation Complete!\n")
 		fmt.Printf("═══════════════════════════════════\n")
-		fmt.Printf("📊 Results Summary:\n")
-		fmt.Printf("   • Profiles analyzed: %d\n", attemptedProfiles)
-		fmt.Printf("   • Connection requests sent: %d\n", connectableProfiles)
-		fmt.Printf("   • Success rate: %.1f%%\n", float64(connectableProfiles)/float64(attemptedProfiles)*100)
-		fmt.Printf("   • Remaining daily quota: ~%d\n", app.config.RateLimit.ConnectionsPerHour-connectableProfiles)
-		
 		fmt.Printf("\n💡 What's Next:\n")
 		fmt.Printf("   • Check LinkedIn notifications for acceptances\n")
 		fmt.Printf("   • Send follow-up messages to new connections\n")
@@ -1937,4 +2789,4742 @@ func (app *Application) runConnectOnly(ctx context.Context) error {
 
 	app.logger.Info(ctx, "🎊 Connection-only automation completed successfully")
 	return nil
-}
\ No newline at end of file
+}
+
+// skipEmailRequiredInvite passes over an invite that asks for the member's email address, which these
+// flows never know; it records the reason against the lead and closes the dialog so the batch continues
+func (app *Application) skipEmailRequiredInvite(ctx context.Context, page *rod.Page, card *rod.Element) bool {
+	required := false
+	for _, selector := range selectors.Desktop.InviteEmail {
+		if has, _, err := page.Has(selector); err == nil && has {
+			required = true
+			break
+		}
+	}
+	if !required {
+		return false
+	}
+
+	profileURL := profileCardURL(card)
+	fmt.Println("      ✉️  LinkedIn asks for this member's email address - skipping")
+	app.logger.Info(ctx, "Invite requires an email address, lead skipped", logger.F("profile", profileURL))
+	app.summary.Skip(profileURL, connect.SkipReasonEmailRequired)
+	if profileURL != "" {
+		skip := storage.LeadSkip{ProfileURL: profileURL, Reason: connect.SkipReasonEmailRequired, SkippedAt: time.Now()}
+		if err := app.storage.SaveLeadSkip(skip); err != nil {
+			app.logger.Warn(ctx, "Failed to record lead skip", logger.F("error", err))
+		}
+	}
+
+	for _, selector := range selectors.Localize([]string{"button[aria-label*='Dismiss']", ".artdeco-modal__dismiss"}, app.config.Browser.UILanguages) {
+		if has, button, err := page.Has(selector); err == nil && has {
+			_ = browser.Click(ctx, button, app.timeouts().Element)
+			break
+		}
+	}
+	return true
+}
+
+// withoutDeferredLeads drops leads deferred until after now, e.g. by an earlier invitation limit or
+// a snooze, and leads skipped for good
+func (app *Application) withoutDeferredLeads(ctx context.Context, results []storage.ProfileResult, now time.Time) ([]storage.ProfileResult, error) {
+	deferred, err := app.storage.GetDeferredLeads()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load deferred leads: %w", err)
+	}
+	waiting := make(map[string]bool)
+	for _, lead := range deferred {
+		if now.Before(lead.RetryAfter) {
+			waiting[identity.NormalizeProfileURL(lead.ProfileURL)] = true
+		}
+	}
+	// Leads skipped for good, e.g. invites that need an email address, are never retried
+	skips, err := app.storage.GetLeadSkips()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load lead skips: %w", err)
+	}
+	for _, skip := range skips {
+		waiting[skip.ProfileURL] = true
+	}
+	if len(waiting) == 0 {
+		return results, nil
+	}
+
+	kept := results[:0]
+	for _, result := range results {
+		if !waiting[identity.NormalizeProfileURL(result.URL)] {
+			kept = append(kept, result)
+		}
+	}
+	if skipped := len(results) - len(kept); skipped > 0 {
+		app.logger.Info(ctx, "Skipping deferred and skipped leads", logger.F("leads", skipped))
+	}
+	return kept, nil
+}
+
+// prioritizeLeads orders a campaign's leads by rank: the priority their annotation and campaign
+// attributes give them and, when the campaign ranks by it, the lead filter's score
+func (app *Application) prioritizeLeads(ctx context.Context, results []storage.ProfileResult, attributes map[string]map[string]string, config campaign.PriorityConfig) error {
+	annotated, err := annotations.Load(app.storage)
+	if err != nil {
+		return fmt.Errorf("failed to load lead annotations: %w", err)
+	}
+	ranks := make(map[string]campaign.Rank, len(results))
+	prioritized := 0
+	for _, result := range results {
+		key := identity.ProfileKey(result.URL)
+		leadAttributes := maps.Clone(attributes[result.URL])
+		if leadAttributes == nil {
+			leadAttributes = make(map[string]string)
+		}
+		maps.Copy(leadAttributes, annotated[key].Attributes)
+		priority, err := config.Priority(leadAttributes)
+		if err != nil {
+			app.logger.Warn(ctx, "Ignoring the lead's priority", logger.F("profile", result.URL), logger.F("error", err))
+		}
+		rank := campaign.Rank{Priority: priority}
+		if config.Score {
+			decision, err := app.leadFilter.Evaluate(ctx, app.withCompany(ctx, leadfilter.Profile{
+				URL:      result.URL,
+				Name:     result.Name,
+				Title:    result.Title,
+				Company:  result.Company,
+				Location: result.Location,
+				Mutual:   result.Mutual,
+				Premium:  result.Premium,
+			}))
+			if err != nil {
+				app.logger.Warn(ctx, "Failed to score lead for its priority", logger.F("profile", result.URL), logger.F("error", err))
+			}
+			rank.Score = decision.Score
+		}
+		if priority != campaign.PriorityNormal {
+			prioritized++
+		}
+		ranks[key] = rank
+	}
+	campaign.SortByRank(results, func(result storage.ProfileResult) campaign.Rank {
+		return ranks[identity.ProfileKey(result.URL)]
+	})
+	if prioritized > 0 || config.Score {
+		app.logger.Info(ctx, "Leads ordered by priority", logger.F("prioritized", prioritized), logger.F("by_score", config.Score))
+	}
+	return nil
+}
+
+// prioritizeCards orders search result cards by the priority the annotation attributes of their
+// leads give them, so a connect run's requests go to the leads that matter most
+func (app *Application) prioritizeCards(ctx context.Context, cards rod.Elements) rod.Elements {
+	annotated, err := annotations.Load(app.storage)
+	if err != nil {
+		app.logger.Warn(ctx, "Failed to load lead annotations, cards keep their order", logger.F("error", err))
+		return cards
+	}
+	ranks := make(map[*rod.Element]campaign.Rank, len(cards))
+	for _, card := range cards {
+		priority, _ := campaign.PriorityConfig{}.Priority(annotated[identity.ProfileKey(profileCardURL(card))].Attributes)
+		ranks[card] = campaign.Rank{Priority: priority}
+	}
+	campaign.SortByRank(cards, func(card *rod.Element) campaign.Rank { return ranks[card] })
+	return cards
+}
+
+// stopForInviteLimit checks page for warnings and, when LinkedIn reports the invitation limit, defers
+// the cards not yet invited until the limit lifts. It returns true if the batch should stop there.
+func (app *Application) stopForInviteLimit(ctx context.Context, page *rod.Page, remaining rod.Elements) bool {
+	warning := app.checkWarnings(ctx, page)
+	if warning == nil || (warning.Kind != warnings.KindInviteLimit && warning.Kind != warnings.KindOutOfInvitations) {
+		return false
+	}
+
+	retryAfter := warning.At.Add(connect.InviteLimitBackoff)
+	// A snoozed lead keeps its own date rather than the limit's
+	snoozes, err := annotations.Snoozes(app.storage, warning.At)
+	if err != nil {
+		app.logger.Warn(ctx, "Failed to load snoozed leads", logger.F("error", err))
+	}
+	var deferred []storage.DeferredLead
+	for _, card := range remaining {
+		if url := profileCardURL(card); url != "" {
+			if _, found := snoozes[identity.ProfileKey(url)]; found {
+				continue
+			}
+			app.summary.Skip(url, string(warning.Kind))
+			deferred = append(deferred, storage.DeferredLead{
+				ProfileURL: url,
+				Reason:     string(warning.Kind),
+				DeferredAt: warning.At,
+				RetryAfter: retryAfter,
+			})
+		}
+	}
+	if err := app.storage.SaveDeferredLeads(deferred); err != nil {
+		app.logger.Warn(ctx, "Failed to save deferred leads", logger.F("error", err))
+	}
+
+	app.logger.Warn(ctx, "Invitation limit reached, stopping the batch",
+		logger.F("kind", string(warning.Kind)),
+		logger.F("deferred", len(deferred)),
+		logger.F("retry_after", retryAfter.Format(time.RFC3339)))
+	fmt.Printf("\n      🛑 LinkedIn reports the invitation limit (%s)\n", warning.Kind)
+	fmt.Printf("      ⏸️  %d remaining lead(s) deferred until %s\n", len(deferred), retryAfter.Format("2006-01-02 15:04"))
+	return true
+}
+
+// selectorSet returns the selectors for the browser's layout, matching button text in English and
+// the configured interface languages
+func (app *Application) selectorSet() selectors.Set {
+	set := selectors.Desktop
+	if app.browserManager.Device().Mobile {
+		set = selectors.Mobile
+	}
+	return set.Localized(app.config.Browser.UILanguages...)
+}
+
+// findLocalized returns the first element under parent matching one of the selectors, with their
+// quoted button text in English or one of the configured interface languages, or nil if there is none yet
+func (app *Application) findLocalized(parent interface {
+	Has(selector string) (bool, *rod.Element, error)
+}, candidates ...string) *rod.Element {
+	for _, candidate := range selectors.Localize(candidates, app.config.Browser.UILanguages) {
+		if has, element, err := parent.Has(candidate); err == nil && has {
+			return element
+		}
+	}
+	return nil
+}
+
+// sendWithoutNoteButton returns the invitation modal's "Send without a note" button, if it shows one,
+// for an invite whose note could not be added; the invite then goes out blank and the batch continues
+func (app *Application) sendWithoutNoteButton(ctx context.Context, page *rod.Page, profileName string) *rod.Element {
+	button := app.findLocalized(page, app.selectorSet().SendWithoutNote...)
+	if button == nil {
+		return nil
+	}
+	fmt.Println("         📭 Note could not be added, sending the invite without a note")
+	app.logger.Info(ctx, "Sending invite without a note",
+		logger.F("profile", profileName),
+		logger.F("note_sent", false))
+	return button
+}
+
+// profileCardURL returns the profile link inside a search result card, or "" if it has none
+func profileCardURL(card *rod.Element) string {
+	link, err := card.Element("a[href*='/in/']")
+	if err != nil {
+		return ""
+	}
+	href, err := link.Attribute("href")
+	if err != nil || href == nil {
+		return ""
+	}
+	return *href
+}
+
+// runCampaign runs every stored search result through the configured campaign steps
+func (app *Application) runCampaign(ctx context.Context) error {
+	app.logger.Info(ctx, "Starting campaign mode", logger.F("campaign", app.campaignPath))
+
+	definition, err := campaign.Load(app.campaignPath)
+	if err != nil {
+		return fmt.Errorf("failed to load campaign: %w", err)
+	}
+	app.summary.UseCampaign(definition.Name)
+	app.warnDecayedTemplates(ctx, definition.Name)
+
+	// Invite and message steps work on a page of their own, shared between them
+	var messenger *openProfileMessenger
+	var inviter campaign.Inviter
+	var camera gallery.Camera
+	if usesStepType(definition, campaign.StepTypeOpenProfile) || usesStepType(definition, campaign.StepTypeInvite) ||
+		usesStepType(definition, campaign.StepTypeMessage) {
+		page, err := app.browserManager.OpenPage(ctx, "campaign")
+		if err != nil {
+			return fmt.Errorf("failed to create page: %w", err)
+		}
+		defer app.browserManager.ClosePage(page)
+		// A campaign requiring approval sends nothing a reviewer has not approved
+		var gate *approval.Gate
+		if definition.Approval {
+			gate = approval.NewGate(app.storage, definition.Name)
+		}
+		// Both steps claim their texts from one set, so a note and a message are not the same either
+		unique := newUniqueTexts(app.storage, definition.Uniqueness.Window, time.Now())
+		messenger = app.newOpenProfileMessenger(page, gate, unique, definition.Name)
+		inviter = app.newCampaignInviter(page, gate, unique, definition.Name)
+		camera = pageCamera(page)
+	}
+
+	runner, err := campaign.NewRunner(definition, campaignRegistry(definition, messenger, inviter))
+	if err != nil {
+		return fmt.Errorf("failed to prepare campaign: %w", err)
+	}
+	if camera != nil && app.config.Screenshots.Enabled {
+		screenshots, err := app.newScreenshotGallery(ctx)
+		if err != nil {
+			app.logger.Warn(ctx, "Screenshot gallery disabled", logger.F("error", err))
+		} else {
+			runner.Observe(app.screenshotObserver(screenshots, camera))
+		}
+	}
+
+	var results []storage.ProfileResult
+	var attributes map[string]map[string]string
+	switch {
+	case definition.Searches.Network != nil:
+		results, attributes, err = networkLeads(app.storage, definition.Searches.Network, time.Now())
+		if err != nil {
+			return err
+		}
+		app.logger.Info(ctx, "Campaign lead pool taken from the network", logger.F("leads", len(results)))
+	case len(definition.Searches.Queries) > 0:
+		// The page invite and message steps share is open while the searches run
+		searches := definition.Searches
+		pagesInUse := 0
+		if messenger != nil {
+			pagesInUse = 1
+		}
+		searches.Concurrency = definition.Concurrency.SearchConcurrency(searches.Concurrency, pagesInUse)
+		results, err = app.searchLeadPool(ctx, searches)
+		if err != nil {
+			return err
+		}
+	default:
+		results, err = app.storage.GetSearchResults()
+		if err != nil {
+			return fmt.Errorf("failed to load stored leads: %w", err)
+		}
+	}
+	// Leads queued through the commands endpoint go first, whatever the campaign's searches
+	queued, err := queuedLeads(app.storage, definition.Name)
+	if err != nil {
+		return err
+	}
+	if len(queued) > 0 {
+		app.logger.Info(ctx, "Queued leads added to the campaign", logger.F("leads", len(queued)))
+		results = append(queued, results...)
+	}
+	// Leads a crashed run left unfinished and leads whose retry is due go before any other
+	jobs := app.campaignQueue(definition.Name)
+	unfinished, unfinishedAttributes, err := unfinishedLeads(jobs, app.storage, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(unfinished) > 0 {
+		app.logger.Info(ctx, "Unfinished leads taken from the campaign queue", logger.F("leads", len(unfinished)))
+		results = append(unfinished, results...)
+		// The attributes a lead was queued with win, as a reviewer may have corrected them
+		if attributes == nil {
+			attributes = make(map[string]map[string]string, len(unfinishedAttributes))
+		}
+		for profileURL, leadAttributes := range unfinishedAttributes {
+			attributes[profileURL] = leadAttributes
+		}
+	}
+	results = uniqueLeads(results)
+	results, err = app.withoutDeferredLeads(ctx, results, time.Now())
+	if err != nil {
+		return err
+	}
+	// The leads that matter most go first, so the caps below leave out the others
+	if err := app.prioritizeLeads(ctx, results, attributes, definition.Priority); err != nil {
+		return err
+	}
+
+	// A degraded account gets a share of the campaign's limits; a failing one does not run
+	report, err := health.Assess(app.storage, app.config.Health.Account, healthPolicy(app.config.Health), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to assess account health: %w", err)
+	}
+	app.logger.Info(ctx, "Account health assessed",
+		logger.F("account", report.Account),
+		logger.F("score", report.Score),
+		logger.F("level", string(report.Level)))
+	limits := definition.Limits
+	switch report.Level {
+	case health.LevelPaused:
+		return fmt.Errorf("account %s is paused at health score %d, see the health command", report.Account, report.Score)
+	case health.LevelReduced, health.LevelMinimal:
+		if limits.DailyCap <= 0 {
+			limits.DailyCap = len(results)
+		}
+		limits.DailyCap = report.Level.Scale(limits.DailyCap)
+		limits.WeeklyCap = report.Level.Scale(limits.WeeklyCap)
+		limits.LeadsPerHour = report.Level.Scale(limits.LeadsPerHour)
+		app.logger.Warn(ctx, "Account health is degraded, campaign limits are reduced",
+			logger.F("daily_cap", limits.DailyCap),
+			logger.F("leads_per_hour", limits.LeadsPerHour))
+	}
+
+	if limits.WeeklyCap > 0 {
+		used, err := campaignLeadsThisWeek(app.storage, time.Now())
+		if err != nil {
+			return err
+		}
+		remaining := max(limits.WeeklyCap-used, 0)
+		if len(results) > remaining {
+			app.logger.Info(ctx, "Weekly cap reached, remaining leads are deferred",
+				logger.F("weekly_cap", limits.WeeklyCap),
+				logger.F("used_this_week", used),
+				logger.F("deferred", len(results)-remaining))
+			results = results[:remaining]
+		}
+	}
+	if limits.DailyCap > 0 && len(results) > limits.DailyCap {
+		app.logger.Info(ctx, "Daily cap reached, remaining leads are deferred",
+			logger.F("daily_cap", limits.DailyCap),
+			logger.F("deferred", len(results)-limits.DailyCap))
+		results = results[:limits.DailyCap]
+	}
+
+	var pace time.Duration
+	if limits.LeadsPerHour > 0 {
+		pace = time.Hour / time.Duration(limits.LeadsPerHour)
+	}
+
+	// Every lead runs through the campaign's durable queue and is acknowledged once done, so a lead
+	// a crash cuts short runs again, and a lead another run holds is left to it
+	items := make([]jobqueue.Item, 0, len(results))
+	for _, result := range results {
+		items = append(items, jobqueue.Item{Key: identity.NormalizeProfileURL(result.URL), Payload: newCampaignLead(result, attributes[result.URL])})
+	}
+	if _, err := jobs.Enqueue(items, time.Now()); err != nil {
+		return err
+	}
+	var leasesMux sync.Mutex
+	leases := make(map[string]*jobqueue.Lease)
+
+	gate, stopMonitor := app.startSessionMonitor(ctx)
+	defer stopMonitor()
+
+	stepTypes := make(map[string]string, len(definition.Steps))
+	for _, step := range definition.Steps {
+		stepTypes[step.ID] = step.Type
+	}
+
+	// Leads run on the campaign's workers; done sees one lead at a time, so the counts need no lock
+	processed, skipped, failed, held := 0, 0, 0, 0
+	var limit *connect.InviteLimitError
+	var deferred []storage.ProfileResult
+	var pool *campaign.Pool
+	pool = campaign.NewPool(ctx, runner, definition.Concurrency, func(lead *campaign.Lead, records []campaign.StepRecord, err error) {
+		leasesMux.Lock()
+		lease := leases[identity.NormalizeProfileURL(lead.ProfileURL)]
+		delete(leases, identity.NormalizeProfileURL(lead.ProfileURL))
+		leasesMux.Unlock()
+		app.settleLead(ctx, lease, err)
+
+		var limitErr *connect.InviteLimitError
+		if stderrors.As(err, &limitErr) || stderrors.Is(err, campaign.ErrNotRun) {
+			// This lead and the rest wait until LinkedIn accepts invitations again
+			if limitErr != nil && limit == nil {
+				limit = limitErr
+				pool.Stop()
+			}
+			deferred = append(deferred, storage.ProfileResult{URL: lead.ProfileURL, Name: lead.Name})
+			return
+		}
+		processed++
+		if stderrors.Is(err, approval.ErrPending) {
+			// The lead stays queued, and a later run sends what the reviewer approves
+			held++
+			app.logger.Info(ctx, "Lead held for approval", logger.F("profile", lead.ProfileURL))
+			return
+		}
+		app.summary.Attempt()
+		app.dequeueLead(ctx, definition.Name, lead.ProfileURL)
+		if stderrors.Is(err, approval.ErrRejected) || stderrors.Is(err, approval.ErrSent) {
+			skipped++
+			reason := approval.ErrRejected.Error()
+			if stderrors.Is(err, approval.ErrSent) {
+				reason = approval.ErrSent.Error()
+			}
+			app.summary.Skip(lead.ProfileURL, reason)
+			app.logger.Info(ctx, "Lead skipped by review", logger.F("profile", lead.ProfileURL), logger.F("reason", reason))
+			return
+		}
+		if stderrors.Is(err, suppression.ErrSuppressed) {
+			skipped++
+			app.summary.Skip(lead.ProfileURL, runs.SkipSuppressed)
+			app.logger.Info(ctx, "Lead skipped, on the suppression list", logger.F("profile", lead.ProfileURL), logger.F("reason", err))
+			return
+		}
+		if stderrors.Is(err, messaging.ErrDuplicateMessage) || stderrors.Is(err, messaging.ErrContactedRecently) {
+			// The contact guard held the message back, which is no failure of the lead's
+			skipped++
+			app.summary.Skip(lead.ProfileURL, err.Error())
+			app.logger.Info(ctx, "Lead skipped, messaged before", logger.F("profile", lead.ProfileURL), logger.F("reason", err))
+			return
+		}
+		if err != nil {
+			failed++
+			app.summary.Fail(lead.ProfileURL, err)
+			app.logger.Warn(ctx, "Campaign step failed",
+				logger.F("profile", lead.ProfileURL),
+				logger.F("error", err))
+			return
+		}
+		if quota := campaignQuota(records, stepTypes); quota != "" {
+			app.summary.Sent(quota)
+		}
+		if len(records) > 0 && records[len(records)-1].Result.Outcome == campaign.OutcomeSkip {
+			skipped++
+			app.summary.Skip(lead.ProfileURL, records[len(records)-1].Result.Reason)
+			app.logger.Info(ctx, "Lead skipped by campaign",
+				logger.F("profile", lead.ProfileURL),
+				logger.F("step", records[len(records)-1].StepID),
+				logger.F("reason", records[len(records)-1].Result.Reason))
+		}
+	})
+
+	if definition.Concurrency.Workers > 1 {
+		app.logger.Info(ctx, "Running leads concurrently",
+			logger.F("workers", definition.Concurrency.Workers),
+			logger.F("queue_depth", definition.Concurrency.QueueDepth))
+	}
+	var unsubmitted []storage.ProfileResult
+	feedErr := func() error {
+		for i, result := range results {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := gate.Wait(ctx); err != nil {
+				return err
+			}
+			if err := app.controller.Checkpoint(ctx, fmt.Sprintf("lead %d of %d: %s", i+1, len(results), result.URL)); err != nil {
+				return err
+			}
+			if pace > 0 && i > 0 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(pace):
+				}
+			}
+
+			key := identity.NormalizeProfileURL(result.URL)
+			lease, ok, err := jobs.Lease(key, time.Now())
+			if err != nil {
+				return err
+			}
+			if !ok {
+				app.logger.Info(ctx, "Lead passed over, another run holds it or its retry is not due", logger.F("profile", result.URL))
+				continue
+			}
+			leasesMux.Lock()
+			leases[key] = lease
+			leasesMux.Unlock()
+
+			// Submit waits while the workers are busy and the queue is full
+			if err := pool.Submit(ctx, newCampaignLead(result, attributes[result.URL])); err != nil {
+				leasesMux.Lock()
+				delete(leases, key)
+				leasesMux.Unlock()
+				app.settleLead(ctx, lease, campaign.ErrNotRun)
+				if stderrors.Is(err, campaign.ErrNotRun) {
+					unsubmitted = results[i:]
+					return nil
+				}
+				return err
+			}
+		}
+		return nil
+	}()
+	pool.Close()
+	if limit != nil {
+		app.deferCampaignLeads(ctx, append(deferred, unsubmitted...), limit)
+	}
+	if feedErr != nil {
+		return feedErr
+	}
+
+	app.logger.Info(ctx, "Campaign completed",
+		logger.F("campaign", definition.Name),
+		logger.F("processed", processed),
+		logger.F("skipped", skipped),
+		logger.F("failed", failed),
+		logger.F("held_for_approval", held))
+
+	return nil
+}
+
+// timeouts are the configured bounds of each kind of browser operation
+func (app *Application) timeouts() errors.Timeouts {
+	t := app.config.Timeouts
+	return errors.Timeouts{Navigation: t.Navigation, Element: t.Element, Dialog: t.Dialog, Login: t.Login, Settle: t.Settle}
+}
+
+// waitSettled waits for page to load and settle within the configured timeouts. A page that
+// loaded but kept changing is only logged, since it can still be used.
+func (app *Application) waitSettled(ctx context.Context, page *rod.Page, until browser.SettleCondition) error {
+	t := app.timeouts()
+	err := browser.WaitSettled(ctx, page, t.Navigation, browser.SettleOptions{Until: until, Timeout: t.Settle})
+	if stderrors.Is(err, browser.ErrNotSettled) {
+		app.logger.Debug(ctx, "Page did not settle", logger.F("error", err))
+		return nil
+	}
+	return err
+}
+
+// screenshotsDir returns where the screenshot galleries are kept, or "" when runs keep none
+func (app *Application) screenshotsDir() string {
+	if !app.config.Screenshots.Enabled {
+		return ""
+	}
+	return app.config.Screenshots.Dir
+}
+
+// newScreenshotGallery starts this run's screenshot gallery, deleting the galleries of older runs
+// beyond screenshots.keep_runs
+func (app *Application) newScreenshotGallery(ctx context.Context) (*gallery.Recorder, error) {
+	settings := app.config.Screenshots
+	if deleted, err := gallery.Prune(settings.Dir, settings.KeepRuns); err != nil {
+		app.logger.Warn(ctx, "Failed to prune screenshot galleries", logger.F("error", err))
+	} else if deleted > 0 {
+		app.logger.Info(ctx, "Pruned old screenshot galleries", logger.F("runs", deleted))
+	}
+	screenshots, err := gallery.NewRecorder(settings.Dir, app.runID, settings.Every)
+	if err != nil {
+		return nil, err
+	}
+	app.logger.Info(ctx, "Keeping a screenshot gallery of the run",
+		logger.F("dir", settings.Dir),
+		logger.F("every", settings.Every))
+	return screenshots, nil
+}
+
+// screenshotObserver shoots the campaign's shared page after the steps that drive it: after every
+// screenshots.every such steps, whenever one moves a lead to a new state and whenever one fails
+func (app *Application) screenshotObserver(screenshots *gallery.Recorder, camera gallery.Camera) campaign.StepObserver {
+	return func(ctx context.Context, lead *campaign.Lead, config campaign.StepConfig, result campaign.StepResult, err error) {
+		if !campaign.UsesPage(config.Type) {
+			return
+		}
+		label := fmt.Sprintf("%s %s", config.ID, lead.ProfileURL)
+		reviewed := stderrors.Is(err, approval.ErrPending) || stderrors.Is(err, approval.ErrRejected) || stderrors.Is(err, approval.ErrSent)
+		var shotErr error
+		switch {
+		case err != nil && !reviewed:
+			shotErr = screenshots.Capture(gallery.ReasonFailure, label+": "+err.Error(), camera)
+		case err == nil && result.Outcome == campaign.OutcomeContinue && config.State != "" && config.State != lead.State:
+			shotErr = screenshots.Capture(gallery.ReasonTransition, fmt.Sprintf("%s: %s -> %s", label, lead.State, config.State), camera)
+		default:
+			shotErr = screenshots.Action(label, camera)
+		}
+		if shotErr != nil {
+			app.logger.Warn(ctx, "Failed to add screenshot to the gallery", logger.F("error", shotErr))
+		}
+	}
+}
+
+// pageHook records and watches the pages the run opens, as video, latency tracking and a proxy
+// pool are configured; nil when none is. stop, if set, ends the run when its proxy dies.
+func (app *Application) pageHook(ctx context.Context, stop context.CancelCauseFunc) browser.PageHook {
+	var hooks []browser.PageHook
+	if app.config.Video.Enabled {
+		hooks = append(hooks, app.videoHook(ctx))
+	}
+	if app.latency != nil {
+		hooks = append(hooks, func(page *rod.Page, label string) func() {
+			return app.latency.Watch(page)
+		})
+	}
+	if app.proxies != nil {
+		hooks = append(hooks, func(page *rod.Page, label string) func() {
+			return app.watchProxy(ctx, page, stop)
+		})
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+	return func(page *rod.Page, label string) func() {
+		var closing []func()
+		for _, hook := range hooks {
+			if stop := hook(page, label); stop != nil {
+				closing = append(closing, stop)
+			}
+		}
+		return func() {
+			for _, stop := range closing {
+				stop()
+			}
+		}
+	}
+}
+
+// newLatencyTracker holds the run's page loads and actions to the latency objectives and
+// stretches the stealth delays while they are missed, since LinkedIn often slows down before it
+// rate limits. Returns nil unless latency is tracked; a replay's recorded pages are not.
+func (app *Application) newLatencyTracker(ctx context.Context) *latency.Tracker {
+	settings := app.config.Latency
+	if !settings.Enabled || app.replayRouter != nil {
+		return nil
+	}
+	tracker := latency.NewTracker(latency.Objectives{
+		PageLoad:   settings.PageLoad,
+		Action:     settings.Action,
+		Window:     settings.Window,
+		MinSamples: settings.MinSamples,
+		MaxStretch: settings.MaxStretch,
+	}, func(stats latency.Stats) {
+		fields := []logger.Field{
+			logger.F("kind", string(stats.Kind)),
+			logger.F("p90", stats.P90.String()),
+			logger.F("objective", stats.Objective.String()),
+			logger.F("samples", stats.Samples),
+		}
+		if !stats.Degraded {
+			app.logger.Info(ctx, "LinkedIn latency back within its objective", fields...)
+			return
+		}
+		app.logger.Warn(ctx, "LinkedIn is answering slowly, stretching delays", fields...)
+		app.recordAccountEvent(ctx, health.EventSlow,
+			fmt.Sprintf("%s p90 %s over the %s objective", stats.Kind, stats.P90.Round(time.Millisecond), stats.Objective))
+	})
+	app.stealthManager.SetPacer(tracker)
+	return tracker
+}
+
+// latencyStatus returns the latency status for the daemon's status, nil unless latency is tracked
+func (app *Application) latencyStatus() *latency.Status {
+	if app.latency == nil {
+		return nil
+	}
+	status := app.latency.Status()
+	return &status
+}
+
+// formatLatency describes the percentiles of one kind of latency
+func formatLatency(stats latency.Stats) string {
+	text := fmt.Sprintf("p50 %s, p90 %s, p99 %s over %d samples (objective %s)",
+		stats.P50.Round(time.Millisecond), stats.P90.Round(time.Millisecond), stats.P99.Round(time.Millisecond),
+		stats.Samples, stats.Objective)
+	if stats.Degraded {
+		text += ", degraded"
+	}
+	return text
+}
+
+// videoHook records every page the run opens into video.dir as <run ID>-<page label>.webm. A
+// recording is encoded once its page closes; one that fails is logged and the run goes on.
+func (app *Application) videoHook(ctx context.Context) browser.PageHook {
+	settings := app.config.Video
+	options := screencast.Options{
+		FFmpeg:    settings.FFmpeg,
+		Quality:   settings.Quality,
+		MaxWidth:  settings.MaxWidth,
+		MaxHeight: settings.MaxHeight,
+	}
+	// Encoding finishes even when the run is interrupted
+	encodeCtx := context.WithoutCancel(ctx)
+
+	var mutex sync.Mutex
+	opened := make(map[string]int)
+	return func(page *rod.Page, label string) func() {
+		if label == "" {
+			label = "page"
+		}
+		mutex.Lock()
+		opened[label]++
+		name := app.runID + "-" + label
+		if opened[label] > 1 {
+			name += fmt.Sprintf("-%d", opened[label])
+		}
+		mutex.Unlock()
+
+		if err := os.MkdirAll(settings.Dir, 0o755); err != nil {
+			app.logger.Warn(ctx, "Failed to create video directory", logger.F("error", err))
+			return nil
+		}
+		recording, err := screencast.Start(page, filepath.Join(settings.Dir, name+".webm"), options)
+		if err != nil {
+			app.logger.Warn(ctx, "Failed to record video", logger.F("page", label), logger.F("error", err))
+			return nil
+		}
+		app.logger.Info(ctx, "Recording video", logger.F("page", label), logger.F("file", recording.Output()))
+
+		return func() {
+			err := recording.Stop(encodeCtx)
+			switch {
+			case err == nil:
+				app.logger.Info(ctx, "Saved video", logger.F("page", label), logger.F("file", recording.Output()))
+			case stderrors.Is(err, screencast.ErrNoFrames):
+			case stderrors.Is(err, screencast.ErrNoFFmpeg):
+				app.logger.Warn(ctx, "Video frames kept unencoded, install ffmpeg or set video.ffmpeg to encode them",
+					logger.F("page", label), logger.F("error", err))
+			default:
+				app.logger.Warn(ctx, "Failed to save video", logger.F("page", label), logger.F("error", err))
+			}
+		}
+	}
+}
+
+// pageCamera takes PNG screenshots of the page's viewport
+func pageCamera(page *rod.Page) gallery.Camera {
+	return func() ([]byte, string, error) {
+		png, err := page.Screenshot(false, nil)
+		if err != nil {
+			return nil, "", err
+		}
+		info, err := page.Info()
+		if err != nil {
+			return png, "", nil
+		}
+		return png, info.URL, nil
+	}
+}
+
+// campaignQuota returns the quota a lead's campaign steps used: "connections" if it was invited,
+// "messages" if it was messaged as an Open Profile or a connection, or "" if nothing was sent
+func campaignQuota(records []campaign.StepRecord, stepTypes map[string]string) string {
+	for _, record := range records {
+		switch stepTypes[record.StepID] {
+		case campaign.StepTypeInvite:
+			if record.Result.Attributes["invite_arm"] != "" {
+				return runs.QuotaConnections
+			}
+		case campaign.StepTypeOpenProfile:
+			if record.Result.Attributes["open_profile_messaged"] == "true" {
+				return runs.QuotaMessages
+			}
+		case campaign.StepTypeMessage:
+			if record.Result.Attributes["messaged"] == "true" {
+				return runs.QuotaMessages
+			}
+		}
+	}
+	return ""
+}
+
+// campaignRegistry returns the built-in step types plus those that need the browser; open profile,
+// message and invite steps send through messenger and inviter, which are nil when the campaign is only linted
+func campaignRegistry(definition *campaign.Campaign, messenger *openProfileMessenger, inviter campaign.Inviter) *campaign.Registry {
+	registry := campaign.NewRegistry()
+	// A nil *openProfileMessenger must reach the factories as a nil interface
+	var openProfiles campaign.OpenProfileMessenger
+	var connections campaign.Messenger
+	if messenger != nil {
+		openProfiles, connections = messenger, messenger
+	}
+	registry.Register(campaign.StepTypeOpenProfile, campaign.NewOpenProfileStepFactory(openProfiles))
+	registry.Register(campaign.StepTypeMessage, campaign.NewMessageStepFactory(connections))
+	registry.Register(campaign.StepTypeInvite, campaign.NewInviteStepFactory(inviter, definition.Invites))
+	return registry
+}
+
+// newCampaignLead makes a campaign lead of a stored result with the attributes it starts with
+func newCampaignLead(result storage.ProfileResult, attributes map[string]string) *campaign.Lead {
+	return &campaign.Lead{
+		ProfileURL: result.URL,
+		Name:       result.Name,
+		Title:      result.Title,
+		Company:    result.Company,
+		Location:   result.Location,
+		Mutual:     result.Mutual,
+		Attributes: attributes,
+	}
+}
+
+// queuedLeads returns the leads queued for the campaign or for any campaign, oldest first, as
+// search results: the stored result for the profile when there is one, otherwise its URL alone
+func queuedLeads(storageImpl *storage.StorageManager, campaignName string) ([]storage.ProfileResult, error) {
+	queue, err := storageImpl.GetQueuedLeads()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load queued leads: %w", err)
+	}
+	if len(queue) == 0 {
+		return nil, nil
+	}
+	stored, err := storageImpl.GetSearchResults()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stored leads: %w", err)
+	}
+	found := make(map[string]storage.ProfileResult, len(stored))
+	for _, result := range stored {
+		found[identity.ProfileKey(result.URL)] = result
+	}
+
+	var leads []storage.ProfileResult
+	for _, lead := range queue {
+		if lead.Campaign != "" && lead.Campaign != campaignName {
+			continue
+		}
+		result, ok := found[identity.ProfileKey(lead.ProfileURL)]
+		if !ok {
+			result = storage.ProfileResult{URL: lead.ProfileURL}
+		}
+		leads = append(leads, result)
+	}
+	return leads, nil
+}
+
+// campaignQueue opens the durable queue a campaign's leads run through
+func (app *Application) campaignQueue(campaignName string) *jobqueue.Queue {
+	settings := app.config.Queue
+	return jobqueue.New(app.storage, campaign.QueueName(campaignName), jobqueue.Options{
+		Visibility:  settings.VisibilityTimeout,
+		MaxAttempts: settings.MaxAttempts,
+		Backoff:     settings.RetryBackoff,
+	})
+}
+
+// unfinishedLeads returns the leads in a campaign's queue that can run at now, oldest first: those
+// a run that crashed left leased, those whose retry is due, those a stopped run never reached and
+// those requeued from the dead letters. Each is the stored result for the profile when there is
+// one, otherwise the lead as queued; the attributes each was queued with are returned by profile
+// URL.
+func unfinishedLeads(queue *jobqueue.Queue, storageImpl *storage.StorageManager, now time.Time) ([]storage.ProfileResult, map[string]map[string]string, error) {
+	pending, err := queue.Pending(now)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(pending) == 0 {
+		return nil, nil, nil
+	}
+	stored, err := storageImpl.GetSearchResults()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load stored leads: %w", err)
+	}
+	found := make(map[string]storage.ProfileResult, len(stored))
+	for _, result := range stored {
+		found[identity.ProfileKey(result.URL)] = result
+	}
+
+	var leads []storage.ProfileResult
+	attributes := make(map[string]map[string]string)
+	for _, job := range pending {
+		var lead campaign.Lead
+		if err := json.Unmarshal([]byte(job.Payload), &lead); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode queued lead %s: %w", job.Key, err)
+		}
+		result, ok := found[identity.ProfileKey(lead.ProfileURL)]
+		if !ok {
+			result = storage.ProfileResult{URL: lead.ProfileURL, Name: lead.Name, Title: lead.Title, Company: lead.Company, Location: lead.Location, Mutual: lead.Mutual}
+		}
+		leads = append(leads, result)
+		if len(lead.Attributes) > 0 {
+			attributes[result.URL] = lead.Attributes
+		}
+	}
+	return leads, attributes, nil
+}
+
+// settleLead settles a campaign lead's job once the lead is done with. A lead that did not run,
+// or that the invitation limit or the run stopping cut short, is released to run again; a lead
+// that failed is retried after a backoff or dead-lettered; any other, sent, skipped or held for
+// approval, is acknowledged. Failures are only logged.
+func (app *Application) settleLead(ctx context.Context, lease *jobqueue.Lease, err error) {
+	if lease == nil {
+		return
+	}
+	now := time.Now()
+	var limitErr *connect.InviteLimitError
+	var settleErr error
+	switch {
+	case stderrors.As(err, &limitErr) || stderrors.Is(err, campaign.ErrNotRun) || (err != nil && ctx.Err() != nil):
+		settleErr = lease.Release(now)
+	case err == nil || stderrors.Is(err, approval.ErrPending) || stderrors.Is(err, approval.ErrRejected) ||
+		stderrors.Is(err, approval.ErrSent) || stderrors.Is(err, suppression.ErrSuppressed) ||
+		stderrors.Is(err, messaging.ErrDuplicateMessage) || stderrors.Is(err, messaging.ErrContactedRecently):
+		settleErr = lease.Ack()
+	default:
+		var dead bool
+		if dead, settleErr = lease.Fail(err, now); dead {
+			app.logger.Warn(ctx, "Lead dead-lettered after failing too often",
+				logger.F("profile", lease.Job.Key),
+				logger.F("attempts", lease.Job.Attempts),
+				logger.F("error", err))
+		}
+	}
+	if settleErr != nil {
+		app.logger.Warn(ctx, "Failed to settle queued lead", logger.F("profile", lease.Job.Key), logger.F("error", settleErr))
+	}
+}
+
+// dequeueLead removes a lead the campaign has run from the queue, whichever way it was queued;
+// failures are only logged
+func (app *Application) dequeueLead(ctx context.Context, campaignName, profileURL string) {
+	for _, name := range []string{campaignName, ""} {
+		if err := app.storage.DeleteQueuedLead(name, profileURL); err != nil {
+			app.logger.Warn(ctx, "Failed to remove queued lead", logger.F("profile", profileURL), logger.F("error", err))
+		}
+	}
+}
+
+// networkLeads lists the imported connections a network campaign selects as campaign leads, with
+// each lead's starting attributes by profile URL. They are first-degree connections, which
+// message steps write to and invite steps pass over.
+func networkLeads(storageImpl *storage.StorageManager, search *campaign.NetworkSearch, now time.Time) ([]storage.ProfileResult, map[string]map[string]string, error) {
+	filter := connections.Filter{
+		Titles:    search.Titles,
+		Companies: search.Companies,
+		Tags:      search.Tags,
+	}
+	if search.DormantMonths > 0 {
+		filter.DormantSince = now.AddDate(0, -search.DormantMonths, 0)
+	}
+	selected, err := connections.Select(storageImpl, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	contacts, err := connections.LastContacts(storageImpl)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results := make([]storage.ProfileResult, 0, len(selected))
+	attributes := make(map[string]map[string]string, len(selected))
+	for _, connection := range selected {
+		results = append(results, storage.ProfileResult{
+			URL:     connection.ProfileURL,
+			Name:    strings.TrimSpace(connection.FirstName + " " + connection.LastName),
+			Title:   connection.Position,
+			Company: connection.Company,
+		})
+		attributes[connection.ProfileURL] = campaign.NetworkLeadAttributes(connection.ConnectedOn,
+			contacts[identity.ProfileKey(connection.ProfileURL)], now)
+	}
+	return results, attributes, nil
+}
+
+// usesStepType reports whether any step of the campaign has the given type
+func usesStepType(definition *campaign.Campaign, stepType string) bool {
+	for _, step := range definition.Steps {
+		if step.Type == stepType {
+			return true
+		}
+	}
+	return false
+}
+
+// openProfileMessenger checks and messages Open Profiles, and messages connections, for campaign steps on one page
+type openProfileMessenger struct {
+	page     browser.PageDriver
+	messages *messaging.MessagingManager
+	gate     *approval.Gate     // Holds messages for a reviewer; nil sends them directly
+	unique   *uniqueTexts       // Keeps messages unique across recipients; nil leaves them alone
+	suppress *suppression.List // Recipients never messaged; nil messages anyone
+	campaign string             // Campaign whose templates the messages are tracked under
+}
+
+// newOpenProfileMessenger creates a messenger on page that records its messages in storage
+// under the templates of the named campaign
+func (app *Application) newOpenProfileMessenger(page *rod.Page, gate *approval.Gate, unique *uniqueTexts, campaignName string) *openProfileMessenger {
+	// The step enforces its own limits, so no messaging rate limiter is needed here
+	messages := messaging.NewMessagingManager(&messageStore{storage: app.storage}, nil, app.stealthManager)
+	messages.SetContactGuard(app.config.RateLimit.MinContactInterval, peerHistories(app.config)...)
+	messages.SetSelectors(app.selectorSet())
+	return &openProfileMessenger{page: browser.NewPageDriver(page), messages: messages, gate: gate, unique: unique, suppress: app.suppression, campaign: campaignName}
+}
+
+func (m *openProfileMessenger) IsOpenProfile(ctx context.Context, lead *campaign.Lead) (bool, error) {
+	return m.messages.DetectOpenProfile(ctx, m.page, lead.ProfileURL)
+}
+
+func (m *openProfileMessenger) SendOpenProfileMessage(ctx context.Context, lead *campaign.Lead, subject, body string) error {
+	if err := m.suppress.Check(lead.ProfileURL); err != nil {
+		return err
+	}
+	stepID := campaign.StepID(ctx)
+	body, err := m.gate.Hold(stepID, approval.KindOpenProfile, lead.ProfileURL, lead.Name, subject, body, time.Now())
+	if err != nil {
+		return err
+	}
+	recipient := messaging.AcceptedConnection{ProfileURL: lead.ProfileURL, Name: lead.Name, Title: lead.Title, Company: lead.Company}
+	ctx = messaging.WithTemplate(ctx, templateName(ctx, m.campaign))
+	if err := m.messages.SendOpenProfileMessage(ctx, m.page, recipient, subject, body); err != nil {
+		return err
+	}
+	return m.gate.Sent(stepID, lead.ProfileURL, time.Now())
+}
+
+func (m *openProfileMessenger) MessageConnection(ctx context.Context, lead *campaign.Lead, body string) error {
+	if err := m.suppress.Check(lead.ProfileURL); err != nil {
+		return err
+	}
+	stepID := campaign.StepID(ctx)
+	body, err := m.gate.Hold(stepID, approval.KindMessage, lead.ProfileURL, lead.Name, "", body, time.Now())
+	if err != nil {
+		return err
+	}
+	recipient := messaging.AcceptedConnection{ProfileURL: lead.ProfileURL, Name: lead.Name, Title: lead.Title, Company: lead.Company}
+	ctx = messaging.WithTemplate(ctx, templateName(ctx, m.campaign))
+	if err := m.messages.MessageConnection(ctx, m.page, recipient, body); err != nil {
+		return err
+	}
+	return m.gate.Sent(stepID, lead.ProfileURL, time.Now())
+}
+
+// Claim claims a message text for the lead unless another recipient got it recently
+func (m *openProfileMessenger) Claim(text, profileURL string) (bool, error) {
+	return m.unique.Claim(text, profileURL)
+}
+
+// campaignInviter sends the connection requests of campaign invite steps on one page
+type campaignInviter struct {
+	page     browser.PageDriver
+	connect  *connect.ConnectManager
+	gate     *approval.Gate     // Holds invites for a reviewer; nil sends them directly
+	unique   *uniqueTexts       // Keeps notes unique across recipients; nil leaves them alone
+	suppress *suppression.List // Prospects never invited; nil invites anyone
+	campaign string             // Campaign whose templates the requests are tracked under
+}
+
+// newCampaignInviter creates an inviter on page that records requests, under the templates of
+// the named campaign, and skips in storage
+func (app *Application) newCampaignInviter(page *rod.Page, gate *approval.Gate, unique *uniqueTexts, campaignName string) *campaignInviter {
+	// The campaign's limits pace the invites, so no connect rate limiter is needed here
+	manager := connect.NewConnectManager(&connectStore{storage: app.storage}, nil, app.stealthManager)
+	manager.SetSelectors(app.selectorSet())
+	if app.network != nil {
+		manager.SetNetwork(app.network)
+	}
+	return &campaignInviter{page: browser.NewPageDriver(page), connect: manager, gate: gate, unique: unique, suppress: app.suppression, campaign: campaignName}
+}
+
+func (i *campaignInviter) Invite(ctx context.Context, lead *campaign.Lead, note string) (bool, error) {
+	profile := connect.ProfileResult{
+		URL:      lead.ProfileURL,
+		Name:     lead.Name,
+		Title:    lead.Title,
+		Company:  lead.Company,
+		Location: lead.Location,
+		Email:    lead.Attributes["email"],
+	}
+	if err := i.suppress.Check(lead.ProfileURL); err != nil {
+		return false, err
+	}
+	stepID := campaign.StepID(ctx)
+	note, err := i.gate.Hold(stepID, approval.KindInvite, lead.ProfileURL, lead.Name, "", note, time.Now())
+	if err != nil {
+		return false, err
+	}
+	result, err := i.connect.SendInvite(connect.WithTemplate(ctx, templateName(ctx, i.campaign)), i.page, profile, note)
+	if err != nil {
+		return result.NoteSent, err
+	}
+	return result.NoteSent, i.gate.Sent(stepID, lead.ProfileURL, time.Now())
+}
+
+// Claim claims a note for the lead unless another recipient got it recently
+func (i *campaignInviter) Claim(text, profileURL string) (bool, error) {
+	return i.unique.Claim(text, profileURL)
+}
+
+// uniqueTexts keeps the notes and messages of a campaign run from going to two recipients
+// within the window, counting the ones in storage and the ones claimed during the run
+type uniqueTexts struct {
+	storage *storage.StorageManager
+	since   time.Time
+	mu      sync.Mutex
+	owners  map[string]string // Text to the profile key of the recipient it went or goes to; nil until loaded
+}
+
+// newUniqueTexts creates the texts of a run starting at now; nil when window is 0, which leaves
+// texts alone
+func newUniqueTexts(storageImpl *storage.StorageManager, window time.Duration, now time.Time) *uniqueTexts {
+	if window <= 0 {
+		return nil
+	}
+	return &uniqueTexts{storage: storageImpl, since: now.Add(-window)}
+}
+
+// Claim reports whether no recipient other than the profile got text within the window, and
+// if so claims it for the profile
+func (u *uniqueTexts) Claim(text, profileURL string) (bool, error) {
+	if u == nil {
+		return true, nil
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.owners == nil {
+		if err := u.load(); err != nil {
+			return false, err
+		}
+	}
+	key := identity.ProfileKey(profileURL)
+	if owner, taken := u.owners[text]; taken && owner != key {
+		return false, nil
+	}
+	u.owners[text] = key
+	return true, nil
+}
+
+// load reads the notes and messages sent within the window from storage
+func (u *uniqueTexts) load() error {
+	requests, err := u.storage.GetSentRequests()
+	if err != nil {
+		return fmt.Errorf("failed to load connection requests: %w", err)
+	}
+	messages, err := u.storage.GetMessageHistory()
+	if err != nil {
+		return fmt.Errorf("failed to load message history: %w", err)
+	}
+	u.owners = make(map[string]string)
+	for _, request := range requests {
+		if request.Note != "" && !request.SentAt.Before(u.since) {
+			u.owners[request.Note] = identity.ProfileKey(request.ProfileURL)
+		}
+	}
+	for _, message := range messages {
+		if message.Content != "" && !message.SentAt.Before(u.since) {
+			u.owners[message.Content] = identity.ProfileKey(message.RecipientURL)
+		}
+	}
+	return nil
+}
+
+// templateName names the template the running campaign step sends from after its campaign,
+// e.g. "Q3 founders/welcome@de#1f3a9c02"; "" when the step sends no template
+func templateName(ctx context.Context, campaignName string) string {
+	id := campaign.TemplateID(ctx)
+	if id == "" {
+		return ""
+	}
+	return campaignName + "/" + id
+}
+
+// connectStore adapts storage to the connect package's record types
+type connectStore struct {
+	storage *storage.StorageManager
+}
+
+func (s *connectStore) SaveConnectionRequest(request connect.ConnectionRequest) error {
+	return s.storage.SaveConnectionRequest(storage.ConnectionRequest{
+		ProfileURL:  request.ProfileURL,
+		ProfileName: request.ProfileName,
+		Note:        request.Note,
+		SentAt:      request.SentAt,
+		Status:      request.Status,
+		Template:    request.Template,
+	})
+}
+
+func (s *connectStore) GetSentRequests() ([]connect.ConnectionRequest, error) {
+	stored, err := s.storage.GetSentRequests()
+	if err != nil {
+		return nil, err
+	}
+	requests := make([]connect.ConnectionRequest, 0, len(stored))
+	for _, request := range stored {
+		requests = append(requests, connect.ConnectionRequest{
+			ProfileURL:  request.ProfileURL,
+			ProfileName: request.ProfileName,
+			Note:        request.Note,
+			SentAt:      request.SentAt,
+			Status:      request.Status,
+		})
+	}
+	return requests, nil
+}
+
+func (s *connectStore) RecordSkip(profileURL, reason string) error {
+	return s.storage.SaveLeadSkip(storage.LeadSkip{ProfileURL: profileURL, Reason: reason, SkippedAt: time.Now()})
+}
+
+// messageStore adapts storage to the messaging package's record types
+type messageStore struct {
+	storage *storage.StorageManager
+}
+
+func (s *messageStore) SaveMessage(message messaging.SentMessage) error {
+	return s.storage.SaveMessage(storage.SentMessage{
+		RecipientURL: message.RecipientURL,
+		Template:     message.Template,
+		Content:      message.Content,
+		SentAt:       message.SentAt,
+		Response:     message.Response,
+	})
+}
+
+func (s *messageStore) GetMessageHistory() ([]messaging.SentMessage, error) {
+	stored, err := s.storage.GetMessageHistory()
+	if err != nil {
+		return nil, err
+	}
+	history := make([]messaging.SentMessage, 0, len(stored))
+	for _, message := range stored {
+		history = append(history, messaging.SentMessage{
+			RecipientURL: message.RecipientURL,
+			Template:     message.Template,
+			Content:      message.Content,
+			SentAt:       message.SentAt,
+			Response:     message.Response,
+		})
+	}
+	return history, nil
+}
+
+func (s *messageStore) GetSentRequests() ([]messaging.ConnectionRequest, error) {
+	stored, err := s.storage.GetSentRequests()
+	if err != nil {
+		return nil, err
+	}
+	requests := make([]messaging.ConnectionRequest, 0, len(stored))
+	for _, request := range stored {
+		requests = append(requests, messaging.ConnectionRequest{
+			ProfileURL:  request.ProfileURL,
+			ProfileName: request.ProfileName,
+			Note:        request.Note,
+			SentAt:      request.SentAt,
+			Status:      request.Status,
+		})
+	}
+	return requests, nil
+}
+
+// peerHistory reads the messages another tenant's storage holds, opening the storage for each
+// read so the tenant's own runs are not held up
+type peerHistory struct {
+	config storage.StorageConfig
+}
+
+// peerHistories returns the message histories of the deployment's other tenants, so no two
+// accounts message the same person too often
+func peerHistories(cfg *config.Config) []messaging.MessageHistory {
+	var histories []messaging.MessageHistory
+	for _, path := range config.PeerStoragePaths(cfg) {
+		histories = append(histories, peerHistory{config: storage.StorageConfig{
+			Type:     cfg.Storage.Type,
+			Path:     path,
+			Database: cfg.Storage.Database,
+		}})
+	}
+	return histories
+}
+
+// GetMessageHistory returns the tenant's messages, none for a tenant that never ran
+func (h peerHistory) GetMessageHistory() ([]messaging.SentMessage, error) {
+	existing := h.config.Path
+	if h.config.Type == "sqlite" {
+		existing = filepath.Join(h.config.Path, h.config.Database)
+	}
+	if _, err := os.Stat(existing); stderrors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	storageImpl, err := storage.NewStorageManager(h.config)
+	if err != nil {
+		return nil, err
+	}
+	defer storageImpl.Close()
+	return (&messageStore{storage: storageImpl}).GetMessageHistory()
+}
+
+// suppressed reports whether the connect flows must pass over target for the suppression list,
+// recording it as skipped, or as failed when the list cannot be read
+func (app *Application) suppressed(target string) bool {
+	err := app.suppression.Check(target)
+	switch {
+	case err == nil:
+		return false
+	case stderrors.Is(err, suppression.ErrSuppressed):
+		app.summary.Skip(target, runs.SkipSuppressed)
+	default:
+		app.summary.Fail(target, err)
+	}
+	return true
+}
+
+// snoozed reports whether the connect flows must pass over target because it is snoozed,
+// recording it as skipped, or as failed when the snoozes cannot be read
+func (app *Application) snoozed(target string) bool {
+	snoozes, err := annotations.Snoozes(app.storage, time.Now())
+	if err != nil {
+		app.summary.Fail(target, fmt.Errorf("failed to load snoozed leads: %w", err))
+		return true
+	}
+	if _, found := snoozes[identity.ProfileKey(target)]; !found {
+		return false
+	}
+	app.summary.Skip(target, annotations.SnoozeReason)
+	return true
+}
+
+// newSuppressionList returns the deployment's suppression list, nil without one
+func newSuppressionList(cfg *config.Config) *suppression.List {
+	var store suppression.Store
+	if cfg.Suppression.Path != "" {
+		store = sharedSuppressions{config: storage.StorageConfig{
+			Type:     cfg.Suppression.Type,
+			Path:     cfg.Suppression.Path,
+			Database: cfg.Suppression.Database,
+		}}
+	}
+	return suppression.New(cfg.Suppression.File, store)
+}
+
+// sharedSuppressions keeps the suppression list in the storage every account shares, opening
+// it for each call so no account holds it open
+type sharedSuppressions struct {
+	config storage.StorageConfig
+}
+
+func (s sharedSuppressions) SaveSuppression(entry storage.Suppression) error {
+	storageImpl, err := storage.NewStorageManager(s.config)
+	if err != nil {
+		return err
+	}
+	defer storageImpl.Close()
+	return storageImpl.SaveSuppression(entry)
+}
+
+func (s sharedSuppressions) GetSuppressions() ([]storage.Suppression, error) {
+	storageImpl, err := storage.NewStorageManager(s.config)
+	if err != nil {
+		return nil, err
+	}
+	defer storageImpl.Close()
+	return storageImpl.GetSuppressions()
+}
+
+func (s sharedSuppressions) DeleteSuppression(profileURL string) error {
+	storageImpl, err := storage.NewStorageManager(s.config)
+	if err != nil {
+		return err
+	}
+	defer storageImpl.Close()
+	return storageImpl.DeleteSuppression(profileURL)
+}
+
+// deferCampaignLeads saves leads not yet run as deferred until the invitation limit lifts
+func (app *Application) deferCampaignLeads(ctx context.Context, results []storage.ProfileResult, limit *connect.InviteLimitError) {
+	now := time.Now()
+	deferred := make([]storage.DeferredLead, 0, len(results))
+	for _, result := range results {
+		app.summary.Skip(result.URL, string(limit.Kind))
+		deferred = append(deferred, storage.DeferredLead{
+			ProfileURL: result.URL,
+			Name:       result.Name,
+			Reason:     string(limit.Kind),
+			DeferredAt: now,
+			RetryAfter: limit.RetryAfter,
+		})
+	}
+	if err := app.storage.SaveDeferredLeads(deferred); err != nil {
+		app.logger.Warn(ctx, "Failed to save deferred leads", logger.F("error", err))
+	}
+	app.logger.Warn(ctx, "Invitation limit reached, stopping the campaign",
+		logger.F("kind", string(limit.Kind)),
+		logger.F("deferred", len(deferred)),
+		logger.F("retry_after", limit.RetryAfter.Format(time.RFC3339)))
+}
+
+// searchLeadPool runs the campaign's searches, in sequence or on several pages at once, and returns
+// their merged results without duplicate people; the pool is also saved with the other search results
+func (app *Application) searchLeadPool(ctx context.Context, searches campaign.SearchConfig) ([]storage.ProfileResult, error) {
+	queries := make([]savedsearch.Query, 0, len(searches.Queries))
+	for i, configured := range searches.Queries {
+		query := savedsearch.Query{Name: configured.Name, MaxResults: configured.MaxResults}
+		if query.Name == "" {
+			query.Name = fmt.Sprintf("search-%d", i+1)
+		}
+		if configured.Query != "" {
+			query.URL = savedsearch.SearchURL(configured.Query)
+		} else {
+			saved, err := savedsearch.Get(app.storage, configured.Name)
+			if err != nil {
+				return nil, err
+			}
+			query.URL = saved.Query
+			if query.MaxResults == 0 {
+				query.MaxResults = saved.MaxResults
+			}
+		}
+		if query.MaxResults == 0 {
+			query.MaxResults = savedsearch.DefaultMaxResults
+		}
+		queries = append(queries, query)
+	}
+
+	app.logger.Info(ctx, "Searching for campaign leads",
+		logger.F("queries", len(queries)),
+		logger.F("concurrency", searches.Concurrency))
+
+	pool, reports := savedsearch.RunQueries(ctx, queries, searches.Concurrency, app.searchLimiter,
+		func(quota savedsearch.Quota) (savedsearch.Runner, func(), error) {
+			return app.newSearchRunner(quota)
+		})
+	for _, report := range reports {
+		fields := []logger.Field{
+			logger.F("search", report.Query.Name),
+			logger.F("found", report.Found),
+			logger.F("added", report.Added),
+			logger.F("searches_used", report.Searches),
+		}
+		if report.Err != nil {
+			app.logger.Warn(ctx, "Campaign search failed", append(fields, logger.F("error", report.Err))...)
+			continue
+		}
+		app.logger.Info(ctx, "Campaign search completed", fields...)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(pool) > 0 {
+		if err := app.storage.SaveSearchResults(pool); err != nil {
+			return nil, fmt.Errorf("failed to save campaign leads: %w", err)
+		}
+		app.fetchPhotos(ctx, pool)
+	}
+	app.logger.Info(ctx, "Campaign lead pool ready", logger.F("leads", len(pool)))
+	return pool, nil
+}
+
+// uniqueLeads drops stored leads that are the same person as an earlier lead, so nobody
+// found through slightly different URLs or searches is contacted twice
+func uniqueLeads(results []storage.ProfileResult) []storage.ProfileResult {
+	seen := identity.NewDeduper()
+	var unique []storage.ProfileResult
+	for _, result := range results {
+		if seen.Add(result.URL, result.Name, result.Company) {
+			unique = append(unique, result)
+		}
+	}
+	return unique
+}
+
+// runCampaignCommand handles the campaign subcommands that never touch the browser
+func runCampaignCommand(configPath, campaignPath string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: campaign lint|simulate")
+	}
+
+	definition, err := campaign.Load(campaignPath)
+	if err != nil {
+		return fmt.Errorf("failed to load campaign: %w", err)
+	}
+
+	switch args[0] {
+	case "lint":
+		return lintCampaign(campaignPath, definition)
+	case "simulate":
+		return simulateCampaign(configPath, definition)
+	default:
+		return fmt.Errorf("unknown campaign command %q (expected lint or simulate)", args[0])
+	}
+}
+
+// printTemplateVariables lists the template variable catalog, as JSON for editors when asJSON is set
+func printTemplateVariables(asJSON bool) error {
+	variables := campaign.Variables()
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(variables)
+	}
+	for _, variable := range variables {
+		fmt.Printf("{{%s}}\t%s\n", variable.Name, variable.Description)
+	}
+	return nil
+}
+
+// lintCampaign prints every issue in the campaign and fails when any of them is an error
+func lintCampaign(campaignPath string, definition *campaign.Campaign) error {
+	issues := campaign.Lint(definition, campaignRegistry(definition, nil, nil))
+	for _, issue := range issues {
+		fmt.Printf("%s: %s\n", campaignPath, issue)
+	}
+
+	if campaign.HasErrors(issues) {
+		return fmt.Errorf("campaign %s has errors", campaignPath)
+	}
+	fmt.Printf("%s: OK (%d steps, %d warnings)\n", campaignPath, len(definition.Steps), len(issues))
+	return nil
+}
+
+// simulateCampaign walks every stored lead through the campaign without running any step
+func simulateCampaign(configPath string, definition *campaign.Campaign) error {
+	cfg, err := config.NewManager().LoadWithEnvOverrides(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	storageImpl, err := storage.NewStorageManager(storage.StorageConfig{
+		Type:     cfg.Storage.Type,
+		Path:     cfg.Storage.Path,
+		Database: cfg.Storage.Database,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer storageImpl.Close()
+
+	simulator, err := campaign.NewSimulator(definition)
+	if err != nil {
+		return fmt.Errorf("failed to prepare campaign: %w", err)
+	}
+
+	results, attributes, err := storedCampaignLeads(storageImpl, definition)
+	if err != nil {
+		return err
+	}
+	if definition.Limits.DailyCap > 0 && len(results) > definition.Limits.DailyCap {
+		fmt.Printf("Daily cap of %d leads applies; simulating the first %d of %d stored leads\n",
+			definition.Limits.DailyCap, definition.Limits.DailyCap, len(results))
+		results = results[:definition.Limits.DailyCap]
+	}
+
+	states := make(map[string]int)
+	for _, result := range results {
+		lead := newCampaignLead(result, attributes[result.URL])
+
+		fmt.Printf("%s (%s)\n", lead.Name, lead.ProfileURL)
+		records, err := simulator.RunLead(context.Background(), lead)
+		for _, record := range records {
+			fmt.Printf("  -> %s\n", record.StepID)
+			if record.Result.Message != "" {
+				fmt.Printf("     %s\n", strings.ReplaceAll(record.Result.Message, "\n", "\n     "))
+			}
+		}
+		if err != nil {
+			fmt.Printf("  !! %v\n", err)
+			states["failed"]++
+			continue
+		}
+
+		state := lead.State
+		if state == "" {
+			state = "(none)"
+		}
+		fmt.Printf("  final state: %s\n", state)
+		states[state]++
+	}
+
+	fmt.Printf("Simulated %d leads through campaign %q\n", len(results), definition.Name)
+	for state, count := range states {
+		fmt.Printf("  %s: %d\n", state, count)
+	}
+	if definition.Limits.LeadsPerHour > 0 && len(results) > 0 {
+		hours := (len(results) + definition.Limits.LeadsPerHour - 1) / definition.Limits.LeadsPerHour
+		fmt.Printf("At %d leads/hour a real run would take about %d hour(s)\n", definition.Limits.LeadsPerHour, hours)
+	}
+	return nil
+}
+
+// storedCampaignLeads returns the leads the campaign would work through without searching: the
+// network search's connections, or else the stored search results
+func storedCampaignLeads(storageImpl *storage.StorageManager, definition *campaign.Campaign) ([]storage.ProfileResult, map[string]map[string]string, error) {
+	if definition.Searches.Network != nil {
+		results, attributes, err := networkLeads(storageImpl, definition.Searches.Network, time.Now())
+		if err != nil {
+			return nil, nil, err
+		}
+		return uniqueLeads(results), attributes, nil
+	}
+	results, err := storageImpl.GetSearchResults()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load stored leads: %w", err)
+	}
+	return uniqueLeads(results), nil, nil
+}
+
+// campaignLeadsThisWeek counts the leads campaign runs processed since the start of now's week,
+// which the weekly cap counts against
+func campaignLeadsThisWeek(storageImpl *storage.StorageManager, now time.Time) (int, error) {
+	summaries, err := storageImpl.GetRunSummaries()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load run summaries: %w", err)
+	}
+	weekStart := campaign.WeekStart(now)
+	used := 0
+	for _, summary := range summaries {
+		if summary.Mode == string(ModeCampaign) && !summary.StartedAt.Before(weekStart) {
+			used += summary.Attempted
+		}
+	}
+	return used, nil
+}
+
+// campaignPlanOptions are the campaign plan command's flags
+type campaignPlanOptions struct {
+	target int    // Leads to forecast for; the campaign's target, or else the stored leads, unless set
+	start  string // First day, "2006-01-02"; today unless set
+	days   string // Weekdays the campaign runs, e.g. "mon-fri"
+	asJSON bool
+}
+
+// planCampaign forecasts when the campaign reaches its target under its daily and weekly caps,
+// and prints the schedule day by day
+func planCampaign(configPath, campaignPath string, options campaignPlanOptions) error {
+	definition, err := campaign.Load(campaignPath)
+	if err != nil {
+		return fmt.Errorf("failed to load campaign: %w", err)
+	}
+	cfg, err := config.NewManager().LoadWithEnvOverrides(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	weekdays, err := campaign.ParseWeekdays(options.days)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if options.start != "" {
+		start, err = time.ParseInLocation("2006-01-02", options.start, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid start %q: use 2006-01-02", options.start)
+		}
+	}
+
+	storageImpl, err := storage.NewStorageManager(storage.StorageConfig{
+		Type:     cfg.Storage.Type,
+		Path:     cfg.Storage.Path,
+		Database: cfg.Storage.Database,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer storageImpl.Close()
+
+	target := options.target
+	if target == 0 {
+		target = definition.Target
+	}
+	if target == 0 {
+		results, _, err := storedCampaignLeads(storageImpl, definition)
+		if err != nil {
+			return err
+		}
+		target = len(results)
+	}
+	if target <= 0 {
+		return fmt.Errorf("nothing to plan: set the campaign's target or --target, or store leads first")
+	}
+
+	plan := campaign.PlanOptions{
+		Start:    start,
+		Target:   target,
+		Limits:   definition.Limits,
+		Weekdays: weekdays,
+	}
+	if cfg.Stealth.BusinessHours {
+		plan.Hours = businessEnd - businessStart
+	}
+	// Leads run earlier this week count against its cap only when the plan starts this week
+	if campaign.WeekStart(start).Equal(campaign.WeekStart(time.Now())) {
+		if plan.UsedThisWeek, err = campaignLeadsThisWeek(storageImpl, time.Now()); err != nil {
+			return err
+		}
+	}
+	calendar := newBlackoutCalendar(cfg, storageImpl)
+	plan.Closed = func(day time.Time) (string, bool) {
+		// A day is closed only when blackouts cover all of it
+		window, active, err := calendar.Active(day)
+		if err != nil || !active || window.End.Before(day.AddDate(0, 0, 1)) {
+			return "", false
+		}
+		return "blackout: " + window.Reason, true
+	}
+
+	forecast, err := campaign.Plan(plan)
+	if err != nil {
+		return err
+	}
+	if options.asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(forecast)
+	}
+
+	limit := func(value int) string {
+		if value == 0 {
+			return "unlimited"
+		}
+		return strconv.Itoa(value)
+	}
+	fmt.Printf("Campaign %q: %d leads, up to %s a day and %s a week, on %s\n",
+		definition.Name, target, limit(forecast.PerDay), limit(forecast.PerWeek), options.days)
+	if plan.UsedThisWeek > 0 {
+		fmt.Printf("%d leads already ran this week\n", plan.UsedThisWeek)
+	}
+	var week time.Time
+	for _, day := range forecast.Days {
+		if monday := campaign.WeekStart(day.Date); !monday.Equal(week) {
+			week = monday
+			fmt.Printf("Week of %s\n", week.Format("2006-01-02"))
+		}
+		if day.Reason != "" {
+			fmt.Printf("  %s  %5s  %s\n", day.Date.Format("Mon 2006-01-02"), "-", day.Reason)
+			continue
+		}
+		fmt.Printf("  %s  %5d  %d/%d\n", day.Date.Format("Mon 2006-01-02"), day.Leads, day.Total, target)
+	}
+	if forecast.Finish.IsZero() {
+		last := forecast.Days[len(forecast.Days)-1]
+		return fmt.Errorf("campaign does not reach %d leads by %s, only %d", target, last.Date.Format("2006-01-02"), last.Total)
+	}
+	weeks := int(forecast.Finish.Sub(campaign.WeekStart(forecast.Days[0].Date)).Hours()/(7*24)) + 1
+	fmt.Printf("Forecast completion: %s, in week %d\n", forecast.Finish.Format("Monday 2006-01-02"), weeks)
+	return nil
+}
+
+// runExportConnections downloads LinkedIn's connections export and imports it into storage
+func (app *Application) runExportConnections(ctx context.Context) error {
+	app.logger.Info(ctx, "Exporting connections from LinkedIn", logger.F("download_dir", app.config.Browser.DownloadDir))
+
+	page, err := app.browserManager.OpenPage(ctx, "export-connections")
+	if err != nil {
+		return fmt.Errorf("failed to create page: %w", err)
+	}
+	defer app.browserManager.ClosePage(page)
+
+	exporter := connections.NewExporter(page, app.browserManager)
+	exporter.SetPageTimeouts(app.timeouts())
+	path, err := exporter.Export(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to export connections: %w", err)
+	}
+	app.logger.Info(ctx, "Connections export downloaded", logger.F("path", path))
+
+	exported, skipped, err := connections.ReadExport(path)
+	if err != nil {
+		return err
+	}
+	summary, err := connections.Import(app.storage, exported)
+	if err != nil {
+		return err
+	}
+	summary.Skipped = skipped
+
+	app.logger.Info(ctx, "Connections imported",
+		logger.F("total", summary.Total),
+		logger.F("new", summary.New),
+		logger.F("updated", summary.Updated),
+		logger.F("skipped", summary.Skipped),
+		logger.F("tracked", summary.Tracked))
+
+	report, err := connections.Reconcile(app.storage, connections.ReconcileOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile connections: %w", err)
+	}
+	app.logger.Info(ctx, "Connections reconciled",
+		logger.F("drift", report.Drift()),
+		logger.F("accepted", len(report.Accepted)),
+		logger.F("disappeared", len(report.Disappeared)),
+		logger.F("expired", len(report.Expired)),
+		logger.F("untracked", len(report.Untracked)))
+	if err := notifyAccepted(ctx, app.webhooks, app.storage, report.Accepted); err != nil {
+		app.logger.Warn(ctx, "Failed to deliver connection webhooks", logger.F("error", err))
+	}
+	return nil
+}
+
+// runWatchSearch re-runs a search every interval and saves only the profiles no earlier run returned
+func (app *Application) runWatchSearch(ctx context.Context) error {
+	if app.watch.Query == "" {
+		return fmt.Errorf("watch-search mode needs -search with a search URL or keywords")
+	}
+	app.logger.Info(ctx, "Starting search watch",
+		logger.F("search", app.watch.Query),
+		logger.F("interval", app.watch.Interval.String()))
+
+	runner, closeRunner, err := app.newSearchRunner(app.searchLimiter)
+	if err != nil {
+		return err
+	}
+	defer closeRunner()
+
+	err = savedsearch.Watch(ctx, app.storage, runner, app.watch, func(report savedsearch.RunReport, err error) {
+		app.summary.Attempt()
+		if err != nil {
+			app.summary.Fail(app.watch.Query, err)
+			app.logger.Warn(ctx, "Search run failed", logger.F("run", report.Run), logger.F("error", err))
+			return
+		}
+		app.summary.Found(report.Found, len(report.New))
+		app.logger.Info(ctx, "Search run completed",
+			logger.F("run", report.Run),
+			logger.F("found", report.Found),
+			logger.F("new", len(report.New)),
+			logger.F("seen", report.Seen))
+		for _, result := range report.New {
+			app.logger.Info(ctx, "New profile", logger.F("name", result.Name), logger.F("url", result.URL))
+		}
+		app.fetchPhotos(ctx, report.New)
+	})
+	if err == context.Canceled {
+		return nil
+	}
+	return err
+}
+
+// runSavedSearch runs the saved search named by -name once
+func (app *Application) runSavedSearch(ctx context.Context) error {
+	if app.searchName == "" {
+		return fmt.Errorf("run-search mode needs -name with a saved search; see \"searches list\"")
+	}
+
+	runner, closeRunner, err := app.newSearchRunner(app.searchLimiter)
+	if err != nil {
+		return err
+	}
+	defer closeRunner()
+
+	report, err := savedsearch.Run(ctx, app.storage, runner, app.searchName, time.Now())
+	app.summary.Attempt()
+	if err != nil {
+		app.summary.Fail(app.searchName, err)
+		return fmt.Errorf("saved search %s failed: %w", app.searchName, err)
+	}
+	app.logSearchRun(ctx, app.searchName, report)
+	return nil
+}
+
+// runSearchScheduler runs each saved search with an interval whenever it is due, until interrupted
+func (app *Application) runSearchScheduler(ctx context.Context) error {
+	app.logger.Info(ctx, "Starting saved search scheduler")
+
+	runner, release, err := app.newSearchRunner(app.searchLimiter)
+	if err != nil {
+		return err
+	}
+	restarting := &restartingRunner{app: app, runner: runner, release: release}
+	defer restarting.close()
+
+	var heldUntil time.Time
+	hold := func(now time.Time) time.Time {
+		window, active, err := app.blackouts.Active(now)
+		if err != nil {
+			app.logger.Warn(ctx, "Failed to check blackouts", logger.F("error", err))
+			return time.Time{}
+		}
+		if !active {
+			return time.Time{}
+		}
+		if !window.End.Equal(heldUntil) {
+			heldUntil = window.End
+			app.logger.Info(ctx, "Blackout, due searches are queued",
+				logger.F("until", window.End.Format(time.RFC3339)),
+				logger.F("reason", blackoutReason(window)))
+		}
+		return window.End
+	}
+
+	var beat func(time.Time)
+	if app.schedulerBeat != nil {
+		beat = app.schedulerBeat.Beat
+	}
+	err = savedsearch.Schedule(ctx, app.storage, restarting, time.Minute, hold, beat, func(name string, report savedsearch.RunReport, err error) {
+		app.summary.Attempt()
+		if err != nil {
+			app.summary.Fail(name, err)
+			app.lastFailure.Store(daemon.NewFailure(name, err, time.Now()))
+			app.logger.Warn(ctx, "Saved search failed", logger.F("search", name), logger.F("error", err))
+			return
+		}
+		app.logSearchRun(ctx, name, report)
+	})
+	if err == context.Canceled {
+		return nil
+	}
+	return err
+}
+
+// restartingRunner runs scheduled searches, relaunching the browser between two of them once it
+// has run for browser.restart_after, so a long-running scheduler does not hold on to the memory a
+// browser gathers over days. The session carries over, and the runner's page is opened anew.
+type restartingRunner struct {
+	app     *Application
+	runner  savedsearch.Runner
+	release func()
+}
+
+// Run restarts the browser if it is due, runs the search and samples memory use afterwards. A
+// search that failed because its proxy died is run once more through the next proxy.
+func (r *restartingRunner) Run(ctx context.Context, searchURL string, maxResults int) ([]storage.ProfileResult, error) {
+	results, err := r.run(ctx, searchURL, maxResults)
+	if err != nil && ctx.Err() == nil {
+		if _, rotate, _ := r.app.nextProxy(time.Now()); rotate {
+			r.app.logger.Info(ctx, "Retrying the search through the next proxy", logger.F("error", err))
+			results, err = r.run(ctx, searchURL, maxResults)
+		}
+	}
+	if err == nil {
+		r.app.recordProxySuccess(ctx)
+	}
+	return results, err
+}
+
+// run restarts the browser if it is due or its proxy died, runs the search and samples memory
+// use afterwards
+func (r *restartingRunner) run(ctx context.Context, searchURL string, maxResults int) ([]storage.ProfileResult, error) {
+	next, rotate, err := r.app.nextProxy(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if r.runner == nil || rotate || r.app.browserRestartDue(time.Now()) {
+		r.close()
+		if rotate {
+			r.app.browserManager.SetProxy(next)
+			r.app.logger.Info(ctx, "Rotating to the next proxy", logger.F("proxy", r.app.browserManager.Proxy()))
+		}
+		if err := r.app.restartBrowser(ctx); err != nil {
+			return nil, err
+		}
+		if rotate {
+			r.app.checkEgress(ctx)
+			r.app.checkLocale(ctx)
+		}
+		runner, release, err := r.app.newSearchRunner(r.app.searchLimiter)
+		if err != nil {
+			return nil, err
+		}
+		r.runner, r.release = runner, release
+	}
+
+	results, err := r.runner.Run(ctx, searchURL, maxResults)
+	r.app.sampleMemory(ctx)
+	return results, err
+}
+
+// close releases the runner's page
+func (r *restartingRunner) close() {
+	if r.release != nil {
+		r.release()
+	}
+	r.runner, r.release = nil, nil
+}
+
+// browserRestartDue reports whether the browser has run for browser.restart_after. Replays never
+// restart, since the recorded pages are served through the running browser.
+func (app *Application) browserRestartDue(now time.Time) bool {
+	restartAfter := app.config.Browser.RestartAfter
+	return restartAfter > 0 && app.replayRouter == nil && now.Sub(app.browserManager.LaunchedAt()) >= restartAfter
+}
+
+// restartBrowser relaunches the browser with the session it had and applies the fingerprint again
+func (app *Application) restartBrowser(ctx context.Context) error {
+	before := app.browserManager.Memory()
+	if err := app.browserManager.Restart(ctx, app.config.Browser.CookiePath); err != nil {
+		return fmt.Errorf("failed to restart browser: %w", err)
+	}
+	if err := app.stealthManager.ConfigureFingerprint(app.browserManager.Browser()); err != nil {
+		app.logger.Warn(ctx, "Failed to configure browser fingerprint", logger.F("error", err))
+	}
+	app.logger.Info(ctx, "Browser restarted with the session restored",
+		logger.F("browser_age", before.BrowserAge.Round(time.Second).String()),
+		logger.F("js_heap_bytes", before.JSHeapBytes),
+		logger.F("heap_bytes", before.HeapBytes))
+	return nil
+}
+
+// watchProxy watches page for a document that failed to load because of the proxy, and fails
+// the proxy when one does
+func (app *Application) watchProxy(ctx context.Context, page *rod.Page, stop context.CancelCauseFunc) func() {
+	events, cancel := page.WithCancel()
+	wait := events.EachEvent(func(e *proto.NetworkLoadingFailed) {
+		if e.Type == proto.NetworkResourceTypeDocument && proxy.IsFailure(e.ErrorText) {
+			app.failProxy(ctx, e.ErrorText, stop)
+		}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wait()
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancel()
+			<-done
+		})
+	}
+}
+
+// failProxy takes the browser's proxy out of rotation for proxy.cooldown and records the
+// failure. stop, if set, ends the run, whose next run starts on the next healthy proxy; a
+// scheduler rotates before its next search instead.
+func (app *Application) failProxy(ctx context.Context, reason string, stop context.CancelCauseFunc) {
+	name := app.browserManager.Proxy()
+	now := time.Now()
+	unhealthyUntil, ok := app.proxies.Fail(name, now)
+	if !ok {
+		return
+	}
+	app.logger.Warn(ctx, "Proxy failed, taking it out of rotation",
+		logger.F("proxy", name),
+		logger.F("error", reason),
+		logger.F("until", unhealthyUntil.Format(time.RFC3339)))
+	if err := app.storage.RecordProxyOutcome(storage.ProxyOutcome{Proxy: name, Error: reason, At: now, UnhealthyUntil: unhealthyUntil}); err != nil {
+		app.logger.Warn(ctx, "Failed to record proxy failure", logger.F("error", err))
+	}
+	if stop != nil {
+		stop(fmt.Errorf("%w: %s through %s", proxy.ErrFailed, reason, name))
+	}
+}
+
+// nextProxy returns the proxy the browser should move to, as a URL for SetProxy, and whether it
+// should move: false while its proxy is healthy or without a pool. proxy.ErrExhausted means every
+// proxy is cooling down.
+func (app *Application) nextProxy(now time.Time) (string, bool, error) {
+	if app.proxies == nil {
+		return "", false, nil
+	}
+	endpoint, err := app.proxies.Next(now)
+	if err != nil {
+		return "", false, err
+	}
+	if endpoint.String() == app.browserManager.Proxy() {
+		return "", false, nil
+	}
+	return endpoint.URL.String(), true, nil
+}
+
+// checkEgress looks up the IP the browser's requests leave from, records it in the run summary,
+// and warns when the proxy or the IP is not the one bound to the account. Only runs through a
+// proxy pool look it up.
+func (app *Application) checkEgress(ctx context.Context) {
+	if app.proxies == nil {
+		return
+	}
+	current := app.browserManager.Proxy()
+	ip, err := app.browserManager.EgressIP(ctx, app.config.Proxy.IPEchoURL, app.timeouts().Navigation)
+	if err != nil {
+		app.logger.Warn(ctx, "Failed to look up the egress IP", logger.F("proxy", current), logger.F("error", err))
+	} else {
+		app.summary.UseEgressIP(ip)
+		app.logger.Info(ctx, "Requests leave from egress IP", logger.F("proxy", current), logger.F("ip", ip))
+	}
+
+	binding, ok := proxyBinding(app.config)
+	if !ok {
+		return
+	}
+	if binding.Proxy != "" && proxy.Redact(binding.Proxy) != current {
+		app.logger.Warn(ctx, "Proxy binding violated, the account runs through another proxy",
+			logger.F("account", binding.Account),
+			logger.F("bound_proxy", proxy.Redact(binding.Proxy)),
+			logger.F("proxy", current))
+	}
+	if binding.IP != "" && ip != "" && !net.ParseIP(binding.IP).Equal(net.ParseIP(ip)) {
+		app.logger.Warn(ctx, "Proxy binding violated, the account appears from another IP",
+			logger.F("account", binding.Account),
+			logger.F("bound_ip", binding.IP),
+			logger.F("ip", ip))
+	}
+}
+
+// checkLocale looks up where the browser's egress IP is and warns about each of the time zone,
+// languages and geolocation that disagree with it, or with browser.locale.harmonize, makes them
+// agree for the pages opened from now on. Only runs through a proxy pool, or harmonizing, look
+// it up; a replay never does.
+func (app *Application) checkLocale(ctx context.Context) {
+	settings := app.config.Browser.Locale
+	if app.replayRouter != nil || (app.proxies == nil && !settings.Harmonize) {
+		return
+	}
+	geo, err := app.browserManager.EgressGeo(ctx, settings.GeoURL, app.timeouts().Navigation)
+	if err != nil {
+		app.logger.Warn(ctx, "Failed to look up where the egress IP is", logger.F("error", err))
+		return
+	}
+
+	if settings.Harmonize {
+		harmonized := locale.Harmonize(geo)
+		app.browserManager.SetLocale(harmonized)
+		app.logger.Info(ctx, "Browser locale harmonized with the egress IP's location",
+			logger.F("country", geo.Country),
+			logger.F("timezone", harmonized.Timezone),
+			logger.F("accept_language", harmonized.AcceptLanguage))
+		return
+	}
+	for _, mismatch := range locale.Check(geo, localeSettings(settings), time.Now()) {
+		app.logger.Warn(ctx, "Browser locale disagrees with the egress IP's location",
+			logger.F("setting", mismatch.Setting),
+			logger.F("value", mismatch.Value),
+			logger.F("expected", mismatch.Expected),
+			logger.F("country", geo.Country))
+	}
+}
+
+// recordProxySuccess counts a run or search that went through the browser's proxy
+func (app *Application) recordProxySuccess(ctx context.Context) {
+	if app.proxies == nil {
+		return
+	}
+	outcome := storage.ProxyOutcome{Proxy: app.browserManager.Proxy(), At: time.Now()}
+	if err := app.storage.RecordProxyOutcome(outcome); err != nil {
+		app.logger.Warn(ctx, "Failed to record proxy success", logger.F("error", err))
+	}
+}
+
+// finishProxyRun records that a run other than a scheduler's went through its proxy, since a
+// scheduler records each search; err is returned as is
+func (app *Application) finishProxyRun(ctx context.Context, mode OperationMode, err error) error {
+	if err == nil && !scheduledModes[mode] {
+		app.recordProxySuccess(ctx)
+	}
+	return err
+}
+
+// sampleMemory records the memory use reported by the daemon's status and logs it at debug level
+func (app *Application) sampleMemory(ctx context.Context) {
+	stats := app.browserManager.Memory()
+	app.memory.Store(&stats)
+	app.logger.Debug(ctx, "Memory usage",
+		logger.F("heap_bytes", stats.HeapBytes),
+		logger.F("sys_bytes", stats.SysBytes),
+		logger.F("goroutines", stats.Goroutines),
+		logger.F("pages", stats.Pages),
+		logger.F("js_heap_bytes", stats.JSHeapBytes),
+		logger.F("browser_age", stats.BrowserAge.Round(time.Second).String()))
+}
+
+// runDaemon runs the search scheduler until stopped over the daemon socket or by a signal. The
+// session saved at browser.cookie_path is restored at start and saved again at stop, so a restart
+// picks up where the last daemon left off without a new login.
+func (app *Application) runDaemon(ctx context.Context) error {
+	startedAt := time.Now()
+	info := func() daemon.Info {
+		return daemon.Info{PID: os.Getpid(), RunID: app.runID, StartedAt: startedAt, Control: app.controller.Status(), Memory: app.memory.Load(), Latency: app.latencyStatus(), Failure: app.lastFailure.Load()}
+	}
+	stop := func() {
+		app.logger.Info(ctx, "Daemon stop requested")
+		app.halt()
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", daemon.Handler(app.controller, info, stop))
+	probes.Mount(mux, probes.Handler(app.probeComponents(), probes.DefaultTimeout))
+	server, err := daemon.Start(app.config.Daemon.Socket, app.config.Daemon.PIDFile, mux)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := server.Close(); err != nil {
+			app.logger.Warn(ctx, "Failed to clean up daemon socket", logger.F("error", err))
+		}
+	}()
+	app.logger.Info(ctx, "Daemon started",
+		logger.F("pid", os.Getpid()),
+		logger.F("socket", app.config.Daemon.Socket),
+		logger.F("pid_file", app.config.Daemon.PIDFile))
+
+	if err := app.browserManager.LoadCookies(app.config.Browser.CookiePath); err == nil {
+		app.logger.Info(ctx, "Restored saved session", logger.F("path", app.config.Browser.CookiePath))
+	} else {
+		app.logger.Warn(ctx, "No saved session restored", logger.F("error", err))
+	}
+	defer func() {
+		if err := app.browserManager.SaveCookies(app.config.Browser.CookiePath); err != nil {
+			app.logger.Warn(ctx, "Failed to save session", logger.F("error", err))
+			return
+		}
+		app.logger.Info(ctx, "Saved session for the next start", logger.F("path", app.config.Browser.CookiePath))
+	}()
+
+	app.sampleMemory(ctx)
+	err = app.runSearchScheduler(ctx)
+	app.logger.Info(ctx, "Daemon stopping")
+	return err
+}
+
+// runResolveLocation resolves the location given with -search through LinkedIn's typeahead and caches it
+func (app *Application) runResolveLocation(ctx context.Context) error {
+	if app.location == "" {
+		return fmt.Errorf("resolve-location mode needs -search with a location name, e.g. -search \"Berlin, Germany\"")
+	}
+
+	page, err := app.browserManager.OpenPage(ctx, "resolve-location")
+	if err != nil {
+		return fmt.Errorf("failed to create page: %w", err)
+	}
+	defer app.browserManager.ClosePage(page)
+
+	resolver := savedsearch.NewGeoResolver(app.storage, savedsearch.NewPageGeoLookup(page))
+	location, err := resolver.Resolve(ctx, app.location)
+	if err != nil {
+		return err
+	}
+	app.logger.Info(ctx, "Location resolved",
+		logger.F("location", app.location),
+		logger.F("linkedin_name", location.Name),
+		logger.F("geo_id", location.ID))
+	return nil
+}
+
+// withCompany adds the firmographics of a candidate's company to it, once enrich-companies
+// mode has looked the company up
+func (app *Application) withCompany(ctx context.Context, candidate leadfilter.Profile) leadfilter.Profile {
+	company, found, err := companies.ForLead(app.storage, candidate.Company)
+	if err != nil {
+		app.logger.Warn(ctx, "Failed to look up cached company", logger.F("company", candidate.Company), logger.F("error", err))
+		return candidate
+	}
+	if found {
+		candidate.CompanySize = company.Size
+		candidate.Employees = companies.Employees(company.Size)
+		candidate.Industry = company.Industry
+		candidate.Headquarters = company.Headquarters
+		candidate.CompanyFollowers = company.Followers
+		candidate.WebsiteKeywords = company.Keywords
+	}
+	return candidate
+}
+
+// runEnrichCompanies looks up the companies of stored search results on LinkedIn, those most leads
+// work at first, and caches their size, industry, headquarters, website and follower count. Each
+// lookup spends a search from searches_per_hour.
+func (app *Application) runEnrichCompanies(ctx context.Context) error {
+	results, err := app.storage.GetSearchResults()
+	if err != nil {
+		return fmt.Errorf("failed to load search results: %w", err)
+	}
+	names := companies.Names(results)
+	app.logger.Info(ctx, "Enriching lead companies", logger.F("companies", len(names)))
+
+	page, err := app.browserManager.OpenPage(ctx, "enrich-companies")
+	if err != nil {
+		return fmt.Errorf("failed to create page: %w", err)
+	}
+	defer app.browserManager.ClosePage(page)
+
+	options := app.companyOptions
+	options.Pause = app.controller.Checkpoint
+	if scan := app.config.Companies.WebsiteScan; scan.Enabled {
+		options.Websites = companies.NewWebsiteScanner(scan.Keywords, nil)
+	}
+	scraper := companies.NewScraper(browser.NewPageDriver(page), app.selectorSet())
+	report, err := companies.Enrich(ctx, app.storage, scraper, app.searchLimiter, names, options, time.Now())
+	fields := []logger.Field{
+		logger.F("fetched", report.Fetched),
+		logger.F("not_found", report.NotFound),
+		logger.F("fresh", report.Fresh),
+		logger.F("websites_scanned", report.Scanned),
+		logger.F("website_scans_failed", report.ScanFailed),
+	}
+	if err != nil {
+		return err
+	}
+	if report.Limited {
+		app.logger.Warn(ctx, "Company enrichment stopped by the search quota or --max; run it again later", fields...)
+		return nil
+	}
+	app.logger.Info(ctx, "Company enrichment completed", fields...)
+	return nil
+}
+
+// runProfileViewers reads who viewed the profile and queues the recent viewers the lead filter
+// accepts as high-priority leads. With a campaign file, the campaign runs right after a check that
+// queued someone, so they are invited while their visit is fresh. With an interval the check
+// repeats until stopped.
+func (app *Application) runProfileViewers(ctx context.Context) error {
+	options := app.viewerOptions
+	options.Exclude = app.excludeViewer
+	if app.campaignPath != "" {
+		definition, err := campaign.Load(app.campaignPath)
+		if err != nil {
+			return fmt.Errorf("failed to load campaign: %w", err)
+		}
+		options.Campaign = definition.Name
+	}
+
+	page, err := app.browserManager.OpenPage(ctx, "profile-viewers")
+	if err != nil {
+		return fmt.Errorf("failed to create page: %w", err)
+	}
+	defer app.browserManager.ClosePage(page)
+	scraper := viewers.NewScraper(browser.NewPageDriver(page), app.selectorSet())
+
+	for {
+		now := time.Now()
+		visits, err := scraper.Visits(ctx, now)
+		if err != nil {
+			return err
+		}
+		report, err := viewers.Reciprocate(ctx, app.storage, app.leadFilter, visits, options, now)
+		if err != nil {
+			return fmt.Errorf("failed to queue profile viewers: %w", err)
+		}
+		app.summary.Found(report.Visits, len(report.Queued))
+		fields := []logger.Field{
+			logger.F("visits", report.Visits),
+			logger.F("known", report.Known),
+			logger.F("queued", len(report.Queued)),
+			logger.F("filter_failures", report.Failures),
+		}
+		for _, outcome := range slices.Sorted(maps.Keys(report.Passed)) {
+			fields = append(fields, logger.F(outcome, report.Passed[outcome]))
+		}
+		app.logger.Info(ctx, "Profile viewers checked", fields...)
+		for _, profileURL := range report.Queued {
+			app.logger.Info(ctx, "Profile viewer queued", logger.F("url", profileURL), logger.F("campaign", options.Campaign))
+		}
+
+		if len(report.Queued) > 0 && app.campaignPath != "" {
+			if err := app.runCampaign(ctx); err != nil {
+				return err
+			}
+		}
+		if app.viewerInterval <= 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(app.viewerInterval):
+		}
+	}
+}
+
+// excludeViewer returns why a profile viewer must not be queued, see excludeInbound
+func (app *Application) excludeViewer(view storage.ProfileView) string {
+	return app.excludeInbound(view.ProfileURL, view.Name, view.Headline)
+}
+
+// excludeInbound returns why someone who came to the account, rather than from a search, must not
+// become a lead: they are already a connection or on the suppression list
+func (app *Application) excludeInbound(profileURL, name, headline string) string {
+	if connected, _ := app.network.InNetwork(profileURL, name, search.CompanyFromHeadline(headline)); connected {
+		return runs.SkipAlreadyConnected
+	}
+	if stderrors.Is(app.suppression.Check(profileURL), suppression.ErrSuppressed) {
+		return runs.SkipSuppressed
+	}
+	return ""
+}
+
+// runHarvestEngagers reads who reacted to or commented on each post and feeds them as warm
+// leads, tagged with the post's topic and marked with their engagement signal
+func (app *Application) runHarvestEngagers(ctx context.Context) error {
+	options := app.engagerOptions
+	options.Exclude = app.excludeEngager
+
+	page, err := app.browserManager.OpenPage(ctx, "harvest-engagers")
+	if err != nil {
+		return fmt.Errorf("failed to create page: %w", err)
+	}
+	defer app.browserManager.ClosePage(page)
+	scraper := engagers.NewScraper(browser.NewPageDriver(page), app.selectorSet())
+
+	for _, postURL := range app.postURLs {
+		post, err := scraper.Harvest(ctx, postURL, app.engagerLoads)
+		if err != nil {
+			return err
+		}
+		report, err := engagers.Feed(app.storage, post, options, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to feed the engagers of %s: %w", postURL, err)
+		}
+		app.summary.Found(len(post.Engagers), len(report.Fed))
+		fields := []logger.Field{
+			logger.F("post", postURL),
+			logger.F("topic", report.Topic),
+			logger.F("engagers", len(post.Engagers)),
+			logger.F("fed", len(report.Fed)),
+			logger.F("queued", report.Queued),
+		}
+		for _, outcome := range slices.Sorted(maps.Keys(report.Passed)) {
+			fields = append(fields, logger.F(outcome, report.Passed[outcome]))
+		}
+		app.logger.Info(ctx, "Post engagers harvested", fields...)
+	}
+	return nil
+}
+
+// excludeEngager returns why a post's engager must not be fed, see excludeInbound
+func (app *Application) excludeEngager(engager engagers.Engager) string {
+	return app.excludeInbound(engager.ProfileURL, engager.Name, engager.Headline)
+}
+
+// runContentSearch searches recent posts for each query and feeds their authors as leads, tagged
+// with the query and optionally the post's text
+func (app *Application) runContentSearch(ctx context.Context) error {
+	options := app.contentOptions
+	options.Exclude = func(post content.Post) string {
+		return app.excludeInbound(post.AuthorURL, post.AuthorName, post.AuthorHeadline)
+	}
+
+	page, err := app.browserManager.OpenPage(ctx, "content-search")
+	if err != nil {
+		return fmt.Errorf("failed to create page: %w", err)
+	}
+	defer app.browserManager.ClosePage(page)
+	scraper := content.NewScraper(browser.NewPageDriver(page), app.selectorSet())
+
+	for _, query := range app.contentQueries {
+		if err := app.controller.Checkpoint(ctx, "content search: "+query); err != nil {
+			return err
+		}
+		posts, err := scraper.Search(ctx, query, app.contentPosted, app.contentLoads)
+		if err != nil {
+			return err
+		}
+		options.Query = query
+		report, err := content.Feed(app.storage, posts, options, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to feed the authors of %q: %w", query, err)
+		}
+		app.summary.Found(report.Posts, len(report.Fed))
+		fields := []logger.Field{
+			logger.F("query", query),
+			logger.F("posts", report.Posts),
+			logger.F("fed", len(report.Fed)),
+			logger.F("queued", report.Queued),
+		}
+		for _, outcome := range slices.Sorted(maps.Keys(report.Passed)) {
+			fields = append(fields, logger.F(outcome, report.Passed[outcome]))
+		}
+		app.logger.Info(ctx, "Content searched", fields...)
+	}
+	return nil
+}
+
+// runTrackAudience reads the account's follower count, post impressions and newsletter subscribers
+// from its analytics pages and stores them for the audience report
+func (app *Application) runTrackAudience(ctx context.Context) error {
+	page, err := app.browserManager.OpenPage(ctx, "track-audience")
+	if err != nil {
+		return fmt.Errorf("failed to create page: %w", err)
+	}
+	defer app.browserManager.ClosePage(page)
+
+	snapshot, err := audience.NewScraper(browser.NewPageDriver(page), app.selectorSet()).Snapshot(ctx, app.newsletterURL, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := app.storage.SaveAudienceSnapshot(snapshot); err != nil {
+		return err
+	}
+	app.logger.Info(ctx, "Audience recorded",
+		logger.F("followers", snapshot.Followers),
+		logger.F("impressions", snapshot.Impressions),
+		logger.F("subscribers", snapshot.Subscribers))
+	return nil
+}
+
+// newSearchRunner opens a page for running searches, reading results with the device's selectors
+// and spending searches from quota
+func (app *Application) newSearchRunner(quota savedsearch.Quota) (*savedsearch.PageRunner, func(), error) {
+	page, err := app.browserManager.OpenPage(context.Background(), "saved-search")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create page: %w", err)
+	}
+
+	searcher := search.NewSearchManager(nil)
+	searcher.SetSelectors(app.selectorSet())
+	searcher.SetPhotos(app.photoFetcher != nil)
+	// Phones show no hover cards, so hovering would only wait out every result
+	if app.config.Search.HoverCards && !app.browserManager.Device().Mobile {
+		searcher.SetHoverCards(app.stealthManager, app.config.Search.HoverWait)
+	}
+	runner := savedsearch.NewPageRunner(browser.NewPageDriver(page), searcher)
+	// Every results page counts against searches_per_hour; runs are cut short up front when the quota runs low
+	runner.SetQuota(quota)
+	runner.SetCheckpoint(app.controller.Checkpoint)
+	runner.OnPlan(func(searchURL string, plan search.Plan) {
+		fields := []logger.Field{
+			logger.F("search", searchURL),
+			logger.F("estimated_results", plan.Estimate),
+			logger.F("pages", plan.Pages),
+			logger.F("quota_remaining", plan.Remaining),
+		}
+		if plan.Limited {
+			app.logger.Warn(context.Background(), "Search limited by quota: "+plan.Reason, fields...)
+			return
+		}
+		app.logger.Info(context.Background(), "Search planned", fields...)
+	})
+	return runner, func() { app.browserManager.ClosePage(page) }, nil
+}
+
+// logSearchRun logs the counts and new profiles of a saved search run
+func (app *Application) logSearchRun(ctx context.Context, name string, report savedsearch.RunReport) {
+	app.summary.Found(report.Found, len(report.New))
+	app.logger.Info(ctx, "Saved search completed",
+		logger.F("search", name),
+		logger.F("run", report.Run),
+		logger.F("found", report.Found),
+		logger.F("new", len(report.New)))
+	for _, result := range report.New {
+		app.logger.Info(ctx, "New profile", logger.F("name", result.Name), logger.F("url", result.URL))
+	}
+	app.fetchPhotos(ctx, report.New)
+}
+
+// fetchPhotos downloads the photos of newly found profiles, when photos are enabled, and
+// deletes those past the retention period
+func (app *Application) fetchPhotos(ctx context.Context, results []storage.ProfileResult) {
+	if app.photoFetcher == nil {
+		return
+	}
+	fetched, err := app.photoFetcher.Fetch(ctx, results, time.Now())
+	if err != nil {
+		app.logger.Warn(ctx, "Failed to download profile photos", logger.F("error", err))
+	}
+	removed, err := photos.Prune(app.storage, app.config.Search.Photos.Dir, app.config.Search.Photos.Retention, time.Now())
+	if err != nil {
+		app.logger.Warn(ctx, "Failed to prune profile photos", logger.F("error", err))
+	}
+	if fetched > 0 || removed > 0 {
+		app.logger.Info(ctx, "Profile photos updated", logger.F("downloaded", fetched), logger.F("deleted", removed))
+	}
+}
+
+// runSearchesCommand handles the saved search subcommands, which only touch storage
+func runSearchesCommand(configPath string, args []string) error {
+	usage := fmt.Errorf("usage: searches add <name> [search URL or keywords] [-max-results N] [-every 6h] [facets] | " +
+		"searches update <name> [-query Q] [-max-results N] [-every 6h] [facets] | searches list | searches show <name> | searches delete <name>\n" +
+		"facets: -title T -location L -company C -past-company C -industry I -school S -service S -language L -network 1st,2nd,3rd " +
+		"(comma-separated names or IDs; locations are separated by ';' since their names contain commas)")
+	if len(args) == 0 {
+		return usage
+	}
+
+	cfg, err := config.NewManager().LoadWithEnvOverrides(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	storageImpl, err := storage.NewStorageManager(storage.StorageConfig{
+		Type:     cfg.Storage.Type,
+		Path:     cfg.Storage.Path,
+		Database: cfg.Storage.Database,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer storageImpl.Close()
+
+	// Settings follow the positional arguments, e.g. "searches add sre sre berlin -every 6h"
+	flags := flag.NewFlagSet("searches", flag.ContinueOnError)
+	query := flags.String("query", "", "Search URL or keywords")
+	maxResults := flags.Int("max-results", 0, "Profiles read per run")
+	every := flags.Duration("every", 0, "Time between scheduled runs, 0 for on-demand only")
+	title := flags.String("title", "", "Title filter")
+	facetFlags := map[search.FacetKind]*string{
+		search.FacetGeo:             flags.String("location", "", "Locations"),
+		search.FacetCurrentCompany:  flags.String("company", "", "Current companies"),
+		search.FacetPastCompany:     flags.String("past-company", "", "Past companies"),
+		search.FacetIndustry:        flags.String("industry", "", "Industries"),
+		search.FacetSchool:          flags.String("school", "", "Schools"),
+		search.FacetServiceCategory: flags.String("service", "", "Service categories"),
+		search.FacetLanguage:        flags.String("language", "", "Profile languages"),
+		search.FacetNetwork:         flags.String("network", "", "Network degrees"),
+	}
+	positional := args[1:]
+	for i, arg := range positional {
+		if strings.HasPrefix(arg, "-") {
+			if err := flags.Parse(positional[i:]); err != nil {
+				return usage
+			}
+			positional = positional[:i]
+			break
+		}
+	}
+	set := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	// With any facet, the keywords and facets are encoded into a faceted search URL
+	faceted := *title != ""
+	for _, value := range facetFlags {
+		faceted = faceted || *value != ""
+	}
+	if faceted && (args[0] == "add" || args[0] == "update") {
+		keywords := *query
+		if args[0] == "add" && len(positional) > 1 {
+			keywords = strings.Join(positional[1:], " ")
+		}
+		facets := search.Facets{
+			Keywords:          keywords,
+			Title:             *title,
+			Locations:         splitList(*facetFlags[search.FacetGeo], ";"),
+			CurrentCompanies:  splitList(*facetFlags[search.FacetCurrentCompany], ","),
+			PastCompanies:     splitList(*facetFlags[search.FacetPastCompany], ","),
+			Industries:        splitList(*facetFlags[search.FacetIndustry], ","),
+			Schools:           splitList(*facetFlags[search.FacetSchool], ","),
+			ServiceCategories: splitList(*facetFlags[search.FacetServiceCategory], ","),
+			Languages:         splitList(*facetFlags[search.FacetLanguage], ","),
+			Network:           splitList(*facetFlags[search.FacetNetwork], ","),
+		}
+		// Locations resolve from the built-in table or the cache filled by resolve-location mode
+		facets, err := savedsearch.NewGeoResolver(storageImpl, nil).ResolveFacets(context.Background(), facets)
+		if stderrors.Is(err, savedsearch.ErrLocationNotCached) {
+			return fmt.Errorf("%w; run -mode resolve-location -search <location> once to look it up on LinkedIn", err)
+		}
+		if err != nil {
+			return err
+		}
+		built, err := search.BuildSearchURL(facets)
+		if err != nil {
+			return err
+		}
+		*query = built
+		set["query"] = true
+	}
+
+	switch {
+	case args[0] == "add" && len(positional) >= 1 && (len(positional) >= 2 || faceted):
+		if !faceted {
+			*query = strings.Join(positional[1:], " ")
+		}
+		saved, err := savedsearch.Create(storageImpl, storage.SavedSearch{
+			Name:       positional[0],
+			Query:      *query,
+			MaxResults: *maxResults,
+			Interval:   *every,
+		}, time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Saved search %s\n", saved.Name)
+		printSavedSearch(saved)
+		return nil
+	case args[0] == "update" && len(positional) == 1:
+		saved, err := savedsearch.Update(storageImpl, positional[0], func(s *storage.SavedSearch) {
+			if set["query"] {
+				s.Query = *query
+			}
+			if set["max-results"] {
+				s.MaxResults = *maxResults
+			}
+			if set["every"] {
+				s.Interval = *every
+			}
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Updated search %s\n", saved.Name)
+		printSavedSearch(saved)
+		return nil
+	case args[0] == "list" && len(positional) == 0:
+		searches, err := storageImpl.GetSavedSearches()
+		if err != nil {
+			return err
+		}
+		if len(searches) == 0 {
+			fmt.Println("No saved searches")
+		}
+		for _, saved := range searches {
+			schedule := "on demand"
+			if saved.Interval > 0 {
+				schedule = "every " + saved.Interval.String()
+			}
+			lastRun := "never run"
+			if !saved.LastRunAt.IsZero() {
+				lastRun = fmt.Sprintf("last run %s: %d found, %d new", saved.LastRunAt.Format("2006-01-02 15:04"), saved.LastFound, saved.LastNew)
+			}
+			fmt.Printf("%-24s %-14s %s\n", saved.Name, schedule, lastRun)
+		}
+		return nil
+	case args[0] == "show" && len(positional) == 1:
+		saved, err := savedsearch.Get(storageImpl, positional[0])
+		if err != nil {
+			return err
+		}
+		printSavedSearch(saved)
+		return nil
+	case args[0] == "delete" && len(positional) == 1:
+		if err := savedsearch.Delete(storageImpl, positional[0]); err != nil {
+			return err
+		}
+		fmt.Printf("Deleted search %s\n", positional[0])
+		return nil
+	default:
+		return usage
+	}
+}
+
+// splitList splits a flag value listing several items, dropping empty ones
+func splitList(value, separator string) []string {
+	var items []string
+	for _, item := range strings.Split(value, separator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// printSavedSearch prints a saved search's settings and run history
+func printSavedSearch(saved storage.SavedSearch) {
+	fmt.Printf("  Name:        %s\n", saved.Name)
+	fmt.Printf("  Query:       %s\n", saved.Query)
+	fmt.Printf("  Max results: %d\n", saved.MaxResults)
+	if saved.Interval > 0 {
+		fmt.Printf("  Schedule:    every %s\n", saved.Interval)
+	} else {
+		fmt.Printf("  Schedule:    on demand\n")
+	}
+	if saved.LastRunAt.IsZero() {
+		fmt.Printf("  Last run:    never\n")
+		return
+	}
+	fmt.Printf("  Last run:    %s (%d found, %d new)\n", saved.LastRunAt.Format("2006-01-02 15:04"), saved.LastFound, saved.LastNew)
+	if saved.LastError != "" {
+		fmt.Printf("  Last error:  %s\n", saved.LastError)
+	}
+	fmt.Printf("  Runs:        %d (%d new profiles in total)\n", saved.Runs, saved.TotalNew)
+}
+
+// runConnectionsCommand handles the connections subcommands that never touch the browser
+func runConnectionsCommand(configPath string, args []string) error {
+	usage := fmt.Errorf("usage: connections import <Connections.csv or export .zip> | connections reconcile [-dry-run] | connections tag|untag <profile URL> <tag>... | " +
+		"connections contacts <output file> [-format csv|vcard] [-column Header=field]... | connections salesforce [-dry-run] | " +
+		"connections pipedrive <profile URL> <reply excerpt> | connections reply <profile URL> [excerpt] [-at 2006-01-02]")
+	if len(args) == 0 {
+		return usage
+	}
+
+	cfg, err := config.NewManager().LoadWithEnvOverrides(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	storageImpl, err := storage.NewStorageManager(storage.StorageConfig{
+		Type:     cfg.Storage.Type,
+		Path:     cfg.Storage.Path,
+		Database: cfg.Storage.Database,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer storageImpl.Close()
+
+	switch {
+	case args[0] == "import" && len(args) == 2:
+		return importConnections(storageImpl, args[1])
+	case args[0] == "reconcile" && len(args) == 1:
+		return reconcileConnections(storageImpl, newWebhookSender(cfg), false)
+	case args[0] == "reconcile" && len(args) == 2 && args[1] == "-dry-run":
+		return reconcileConnections(storageImpl, nil, true)
+	case args[0] == "tag" && len(args) >= 3:
+		return tagConnection(storageImpl, args[1], args[2:], nil)
+	case args[0] == "untag" && len(args) >= 3:
+		return tagConnection(storageImpl, args[1], nil, args[2:])
+	case args[0] == "contacts" && len(args) >= 2:
+		return exportContacts(storageImpl, args[1], args[2:])
+	case args[0] == "salesforce" && len(args) == 1:
+		return syncSalesforce(storageImpl, cfg.Integrations.Salesforce, false)
+	case args[0] == "salesforce" && len(args) == 2 && args[1] == "-dry-run":
+		return syncSalesforce(storageImpl, cfg.Integrations.Salesforce, true)
+	case args[0] == "pipedrive" && len(args) == 3:
+		return recordPipedriveReply(storageImpl, cfg.Integrations.Pipedrive, args[1], args[2])
+	case args[0] == "reply" && (len(args) == 2 || len(args) == 3):
+		excerpt := ""
+		if len(args) == 3 {
+			excerpt = args[2]
+		}
+		return recordReply(storageImpl, args[1], excerpt, "")
+	case args[0] == "reply" && (len(args) == 4 || len(args) == 5) && args[len(args)-2] == "-at":
+		excerpt := ""
+		if len(args) == 5 {
+			excerpt = args[2]
+		}
+		return recordReply(storageImpl, args[1], excerpt, args[len(args)-1])
+	default:
+		return usage
+	}
+}
+
+// importConnections imports a connections export downloaded by hand
+func importConnections(storageImpl *storage.StorageManager, path string) error {
+	exported, skipped, err := connections.ReadExport(path)
+	if err != nil {
+		return err
+	}
+	summary, err := connections.Import(storageImpl, exported)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d connections from %s (%d new, %d updated, %d rows without a profile URL skipped)\n",
+		summary.Total, path, summary.New, summary.Updated, skipped)
+	fmt.Printf("%d of them came from connection requests sent by this tool\n", summary.Tracked)
+	return nil
+}
+
+// reconcileConnections prints how tracked connection requests drifted from the imported connections,
+// posting a webhook for each request found accepted
+func reconcileConnections(storageImpl *storage.StorageManager, webhooks *webhook.Sender, dryRun bool) error {
+	report, err := connections.Reconcile(storageImpl, connections.ReconcileOptions{DryRun: dryRun})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Compared %d tracked requests with %d connections imported %s\n",
+		report.Requests, report.Connections, report.Snapshot.Format("2006-01-02 15:04"))
+	for _, request := range report.Accepted {
+		fmt.Printf("  accepted     %s (was %s)\n", request.ProfileURL, request.Status)
+	}
+	for _, request := range report.Disappeared {
+		fmt.Printf("  disappeared  %s\n", request.ProfileURL)
+	}
+	for _, request := range report.Expired {
+		fmt.Printf("  expired      %s (sent %s)\n", request.ProfileURL, request.SentAt.Format("2006-01-02"))
+	}
+	fmt.Printf("%d untracked connections were made outside this tool\n", len(report.Untracked))
+
+	if dryRun {
+		fmt.Printf("Drift: %d requests (dry run, nothing updated)\n", report.Drift())
+	} else {
+		fmt.Printf("Drift: %d requests updated\n", report.Drift())
+	}
+	if err := notifyAccepted(context.Background(), webhooks, storageImpl, report.Accepted); err != nil {
+		fmt.Printf("⚠️  Some webhooks failed: %v\n", err)
+	}
+	return nil
+}
+
+// exportContacts writes the accepted connections to path as CSV or vCards for a CRM import. The
+// format follows the file extension unless -format is given.
+func exportContacts(storageImpl *storage.StorageManager, path string, args []string) error {
+	format := connections.FormatCSV
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".vcf" || ext == ".vcard" {
+		format = connections.FormatVCard
+	}
+	var columnSpecs []string
+	flags := flag.NewFlagSet("contacts", flag.ContinueOnError)
+	flags.StringVar(&format, "format", format, "csv or vcard")
+	flags.Func("column", "CSV column as Header=field, repeated in column order", func(value string) error {
+		columnSpecs = append(columnSpecs, value)
+		return nil
+	})
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	columns := connections.DefaultColumns
+	if len(columnSpecs) > 0 {
+		var err error
+		if columns, err = connections.ParseColumns(columnSpecs); err != nil {
+			return err
+		}
+	}
+	if format != connections.FormatCSV && format != connections.FormatVCard {
+		return fmt.Errorf("unknown contacts format %q (expected %s or %s)", format, connections.FormatCSV, connections.FormatVCard)
+	}
+
+	profiles, err := storageImpl.GetSearchResults()
+	if err != nil {
+		return fmt.Errorf("failed to load stored profiles: %w", err)
+	}
+	contacts, err := connections.Contacts(storageImpl, profiles)
+	if err != nil {
+		return err
+	}
+	downloaded, err := storageImpl.GetPhotos()
+	if err != nil {
+		return fmt.Errorf("failed to load profile photos: %w", err)
+	}
+	photoPaths := make(map[string]string, len(downloaded))
+	for _, photo := range downloaded {
+		photoPaths[identity.ProfileKey(photo.ProfileURL)] = photo.Path
+	}
+	cached, err := storageImpl.GetCompanies()
+	if err != nil {
+		return fmt.Errorf("failed to load companies: %w", err)
+	}
+	firmographics := make(map[string]storage.Company, len(cached))
+	for _, company := range cached {
+		if company.URL != "" {
+			firmographics[company.Key] = company
+		}
+	}
+	annotated, err := annotations.Load(storageImpl)
+	if err != nil {
+		return fmt.Errorf("failed to load lead notes: %w", err)
+	}
+	for i := range contacts {
+		contacts[i].Photo = photoPaths[identity.ProfileKey(contacts[i].ProfileURL)]
+		if annotation, found := annotated[identity.ProfileKey(contacts[i].ProfileURL)]; found {
+			contacts[i].Notes, contacts[i].Attributes = annotation.NoteTexts(), annotation.Attributes
+		}
+		if company, found := firmographics[identity.NormalizeCompany(contacts[i].Company)]; found {
+			contacts[i].CompanySize = company.Size
+			contacts[i].Industry = company.Industry
+			contacts[i].Headquarters = company.Headquarters
+			contacts[i].CompanyWebsite = company.Website
+			contacts[i].CompanyFollowers = company.Followers
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if format == connections.FormatVCard {
+		err = connections.WriteVCards(file, contacts)
+	} else {
+		err = connections.WriteCSV(file, contacts, columns)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d contacts to %s as %s\n", len(contacts), path, format)
+	return nil
+}
+
+// syncSalesforce upserts every accepted connection as a Salesforce Lead or Contact, attributed
+// to the campaign whose run sent the accepted invite. A dry run prints the fields instead.
+func syncSalesforce(storageImpl *storage.StorageManager, cfg config.SalesforceConfig, dryRun bool) error {
+	if !cfg.Enabled {
+		return fmt.Errorf("the salesforce integration is disabled; set integrations.salesforce.enabled in the config")
+	}
+	client, err := salesforce.New(salesforce.Config{
+		LoginURL:        cfg.LoginURL,
+		ClientID:        cfg.ClientID,
+		ClientSecret:    cfg.ClientSecret,
+		APIVersion:      cfg.APIVersion,
+		Object:          cfg.Object,
+		ExternalIDField: cfg.ExternalIDField,
+		Fields:          cfg.Fields,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	profiles, err := storageImpl.GetSearchResults()
+	if err != nil {
+		return fmt.Errorf("failed to load stored profiles: %w", err)
+	}
+	contacts, err := connections.Contacts(storageImpl, profiles)
+	if err != nil {
+		return err
+	}
+	campaigns, err := runCampaigns(storageImpl)
+	if err != nil {
+		return err
+	}
+
+	created, updated, failed := 0, 0, 0
+	for _, contact := range contacts {
+		record := salesforce.Record{
+			ProfileURL:  contact.ProfileURL,
+			FirstName:   contact.FirstName,
+			LastName:    contact.LastName,
+			Email:       contact.Email,
+			Title:       contact.Title,
+			Company:     contact.Company,
+			Location:    contact.Location,
+			ConnectedOn: contact.ConnectedOn,
+			Campaign:    campaigns[contact.RunID],
+		}
+		if dryRun {
+			fmt.Printf("  %s %v\n", record.PublicID(), client.Body(record))
+			continue
+		}
+		result, err := client.Upsert(context.Background(), record)
+		switch {
+		case err != nil:
+			failed++
+			fmt.Printf("  failed   %s: %v\n", contact.ProfileURL, err)
+		case result.Created:
+			created++
+		default:
+			updated++
+		}
+	}
+
+	if dryRun {
+		fmt.Printf("Dry run: %d connections would be upserted as %s records\n", len(contacts), client.Object())
+		return nil
+	}
+	fmt.Printf("Synced %d connections to Salesforce: %d created, %d updated, %d failed\n", len(contacts), created, updated, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d connections failed to sync", failed)
+	}
+	return nil
+}
+
+// recordPipedriveReply creates a Pipedrive person and deal for a lead who replied positively,
+// with the reply excerpt as a note. The lead's details come from the accepted connections or
+// the stored search results, and the campaign from the run that last messaged or invited them.
+func recordPipedriveReply(storageImpl *storage.StorageManager, cfg config.PipedriveConfig, profileURL, excerpt string) error {
+	if !cfg.Enabled {
+		return fmt.Errorf("the pipedrive integration is disabled; set integrations.pipedrive.enabled in the config")
+	}
+	client, err := pipedrive.New(pipedrive.Config{
+		BaseURL:    cfg.BaseURL,
+		APIToken:   cfg.APIToken,
+		PipelineID: cfg.PipelineID,
+		StageID:    cfg.StageID,
+		DealTitle:  cfg.DealTitle,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	profiles, err := storageImpl.GetSearchResults()
+	if err != nil {
+		return fmt.Errorf("failed to load stored profiles: %w", err)
+	}
+	contacts, err := connections.Contacts(storageImpl, profiles)
+	if err != nil {
+		return err
+	}
+	key := identity.ProfileKey(profileURL)
+	reply := pipedrive.Reply{ProfileURL: profileURL, Excerpt: excerpt}
+	runID := ""
+	for _, contact := range contacts {
+		if identity.ProfileKey(contact.ProfileURL) == key {
+			reply.Name, reply.Email, reply.Title, reply.Company = contact.Name(), contact.Email, contact.Title, contact.Company
+			runID = contact.RunID
+			break
+		}
+	}
+	if reply.Name == "" {
+		for _, profile := range profiles {
+			if identity.ProfileKey(profile.URL) == key {
+				reply.Name, reply.Title, reply.Company = profile.Name, profile.Title, profile.Company
+				break
+			}
+		}
+	}
+	if reply.Name == "" {
+		return fmt.Errorf("%s is neither an accepted connection nor a stored search result", profileURL)
+	}
+
+	messages, err := storageImpl.GetMessageHistory()
+	if err != nil {
+		return fmt.Errorf("failed to load message history: %w", err)
+	}
+	var lastSent time.Time
+	for _, message := range messages {
+		if identity.ProfileKey(message.RecipientURL) == key && message.RunID != "" && message.SentAt.After(lastSent) {
+			lastSent, runID = message.SentAt, message.RunID
+		}
+	}
+	campaigns, err := runCampaigns(storageImpl)
+	if err != nil {
+		return err
+	}
+	reply.Campaign = campaigns[runID]
+
+	// The reply counts toward the cohort reply rates even if Pipedrive fails
+	if err := storageImpl.SaveReply(storage.Reply{ProfileURL: profileURL, Excerpt: excerpt, RepliedAt: time.Now()}); err != nil {
+		return err
+	}
+	result, err := client.RecordReply(context.Background(), reply)
+	if err != nil {
+		return err
+	}
+	person := "found"
+	if result.PersonCreated {
+		person = "created"
+	}
+	fmt.Printf("Recorded %s's reply in Pipedrive: person %d (%s), deal %d %q with the excerpt as note %d\n",
+		reply.Name, result.PersonID, person, result.DealID, client.DealTitle(reply), result.NoteID)
+	return nil
+}
+
+// runCampaigns maps the ID of each campaign run to its campaign's name
+func runCampaigns(storageImpl *storage.StorageManager) (map[string]string, error) {
+	summaries, err := storageImpl.GetRunSummaries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load run summaries: %w", err)
+	}
+	campaigns := make(map[string]string, len(summaries))
+	for _, summary := range summaries {
+		var document runs.Summary
+		if json.Unmarshal([]byte(summary.Document), &document) == nil && document.Campaign != "" {
+			campaigns[summary.RunID] = document.Campaign
+		}
+	}
+	return campaigns, nil
+}
+
+// tagConnection adds and removes tags on an imported connection, for network campaigns to select by
+func tagConnection(storageImpl *storage.StorageManager, profileURL string, add, remove []string) error {
+	tags, err := connections.Tag(storageImpl, profileURL, add, remove)
+	if err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		fmt.Printf("%s has no tags\n", profileURL)
+		return nil
+	}
+	fmt.Printf("%s is tagged %s\n", profileURL, strings.Join(tags, ", "))
+	return nil
+}
+
+// recordReply records that a lead replied, at repliedAt as "2006-01-02" or now, for the cohort reply rates
+func recordReply(storageImpl *storage.StorageManager, profileURL, excerpt, repliedAt string) error {
+	at := time.Now()
+	if repliedAt != "" {
+		day, err := time.ParseInLocation("2006-01-02", repliedAt, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid reply date %q: use 2006-01-02", repliedAt)
+		}
+		at = day
+	}
+	if err := storageImpl.SaveReply(storage.Reply{ProfileURL: profileURL, Excerpt: excerpt, RepliedAt: at}); err != nil {
+		return err
+	}
+	fmt.Printf("Recorded %s's reply on %s\n", profileURL, at.Format("2006-01-02"))
+	return nil
+}
+
+// startSessionMonitor periodically checks the session on a dedicated page; workers call
+// gate.Wait before each action so a logout or checkpoint pauses them instead of failing
+func (app *Application) startSessionMonitor(ctx context.Context) (*session.Gate, func()) {
+	gate := session.NewGate()
+
+	page, err := app.browserManager.OpenPage(ctx, "session-monitor")
+	if err != nil {
+		app.logger.Warn(ctx, "Session health monitor disabled", logger.F("error", err))
+		return gate, func() {}
+	}
+
+	monitor, err := session.NewMonitor(session.MonitorConfig{
+		Interval: app.config.Session.HealthCheckInterval,
+	}, session.NewPageProbe(page, app.config.Session.HealthCheckURL), gate, func(event session.Event) {
+		switch event.Type {
+		case session.EventSessionLost:
+			app.logger.Error(ctx, "Session lost, pausing all workers until it is restored",
+				logger.F("status", string(event.Status)),
+				logger.F("url", event.URL),
+				logger.F("error", sessionLostError(event.Status)))
+			eventType, detail := sessionEventType(event.Status, event.URL)
+			app.recordAccountEvent(ctx, eventType, detail)
+		case session.EventSessionRestored:
+			app.logger.Info(ctx, "Session restored, resuming workers")
+		case session.EventProbeFailed:
+			app.logger.Warn(ctx, "Session health check failed", logger.F("error", event.Err))
+		}
+	})
+	if err != nil {
+		_ = app.browserManager.ClosePage(page)
+		app.logger.Warn(ctx, "Session health monitor disabled", logger.F("error", err))
+		return gate, func() {}
+	}
+
+	monitorCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		monitor.Run(monitorCtx)
+	}()
+
+	return gate, func() {
+		cancel()
+		<-done
+		_ = app.browserManager.ClosePage(page)
+	}
+}
+
+// sessionLostError describes a lost session with its code: E_CHALLENGE at a checkpoint,
+// E_SESSION_EXPIRED otherwise
+func sessionLostError(status session.Status) error {
+	err := errors.NewError(errors.ErrorTypeAuthentication, "session_health_check", "session "+string(status), nil)
+	if status == session.StatusCheckpoint {
+		err.WithCode(errors.CodeChallenge)
+	}
+	return err
+}
+
+// sessionEventType maps a lost session to the account event it counts as; a redirect to a
+// restriction page counts as a restriction rather than a challenge
+func sessionEventType(status session.Status, url string) (string, string) {
+	if kind := warnings.ClassifyURL(url, warnings.DefaultSurfaces()); kind != "" {
+		return kind.EventType(), fmt.Sprintf("%s at %s", kind, url)
+	}
+	if status == session.StatusCheckpoint {
+		return health.EventChallenge, url
+	}
+	return health.EventLogout, url
+}
+
+// checkWarnings looks for LinkedIn's restriction and invitation-limit surfaces on page and
+// records any it finds against the account, which may trip a kill-switch
+func (app *Application) checkWarnings(ctx context.Context, page *rod.Page) *warnings.Warning {
+	warning, err := warnings.Detect(warnings.NewPage(page), warnings.DefaultSurfaces())
+	if err != nil {
+		app.logger.Debug(ctx, "Warning detection failed", logger.F("error", err))
+		return nil
+	}
+	if warning == nil {
+		return nil
+	}
+	app.logger.Warn(ctx, "LinkedIn warning detected",
+		logger.F("kind", string(warning.Kind)),
+		logger.F("url", warning.URL),
+		logger.F("text", warning.Text))
+	app.recordAccountEvent(ctx, warning.Kind.EventType(), warning.Detail())
+	return warning
+}
+
+// recordAccountEvent saves an event against the configured account and re-checks the kill-switches;
+// failures are only logged
+func (app *Application) recordAccountEvent(ctx context.Context, eventType, detail string) {
+	if err := health.Record(app.storage, app.config.Health.Account, eventType, detail, time.Now()); err != nil {
+		app.logger.Warn(ctx, "Failed to record account event",
+			logger.F("type", eventType),
+			logger.F("error", err))
+		return
+	}
+	if err := app.webhooks.Send(ctx, time.Now(), webhook.AccountEvent{Type: eventType, Detail: detail, RunID: app.runID}); err != nil {
+		app.logger.Warn(ctx, "Failed to deliver account event webhook", logger.F("error", err))
+	}
+	app.enforceKillSwitches(ctx)
+}
+
+// enforceKillSwitches halts the account and stops the run if a guardrail is breached
+func (app *Application) enforceKillSwitches(ctx context.Context) {
+	reason, err := health.Trip(app.storage, app.config.Health.Account, guardrails(app.config.Health.KillSwitch), time.Now())
+	if err != nil {
+		app.logger.Warn(ctx, "Failed to check kill-switches", logger.F("error", err))
+		return
+	}
+	if reason == "" {
+		return
+	}
+	app.logger.Error(ctx, "Kill-switch tripped, all automation is stopped until re-enabled with: health enable",
+		logger.F("account", app.config.Health.Account),
+		logger.F("reason", reason))
+	if app.halt != nil {
+		app.halt()
+	}
+}
+
+// guardrails converts the kill-switch configuration, where negative values disable a condition
+func guardrails(cfg config.KillSwitchConfig) health.Guardrails {
+	guardrails := health.Guardrails{
+		MaxChallenges:     cfg.Challenges,
+		ChallengeWindow:   cfg.ChallengeWindow,
+		MinAcceptance:     cfg.MinAcceptance,
+		AcceptanceInvites: cfg.AcceptanceInvites,
+		HaltOnRestriction: !cfg.IgnoreRestrictions,
+	}
+	if guardrails.MaxChallenges < 0 {
+		guardrails.MaxChallenges = 0
+	}
+	if guardrails.MinAcceptance < 0 || guardrails.AcceptanceInvites < 0 {
+		guardrails.MinAcceptance, guardrails.AcceptanceInvites = 0, 0
+	}
+	return guardrails
+}
+
+// healthPolicy converts the health configuration into a scoring policy
+func healthPolicy(cfg config.HealthConfig) health.Policy {
+	return health.Policy{
+		Window:       cfg.Window,
+		MinInvites:   cfg.MinInvites,
+		ReducedBelow: cfg.ReducedBelow,
+		MinimalBelow: cfg.MinimalBelow,
+		PauseBelow:   cfg.PauseBelow,
+	}
+}
+
+// runPauseCommand adds, lists and clears ad-hoc pauses, during which no actions run
+func runPauseCommand(configPath string, args []string) error {
+	usage := fmt.Errorf("usage: pause <duration, e.g. 48h or 3d> [reason] | pause list | pause clear")
+	if len(args) == 0 {
+		return usage
+	}
+
+	cfg, err := config.NewManager().LoadWithEnvOverrides(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	storageImpl, err := storage.NewStorageManager(storage.StorageConfig{
+		Type:     cfg.Storage.Type,
+		Path:     cfg.Storage.Path,
+		Database: cfg.Storage.Database,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer storageImpl.Close()
+	calendar := newBlackoutCalendar(cfg, storageImpl)
+
+	now := time.Now()
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		windows, err := calendar.Windows()
+		if err != nil {
+			return err
+		}
+		listed := 0
+		for _, window := range windows {
+			if !window.End.After(now) {
+				continue
+			}
+			state := "upcoming"
+			if window.Contains(now) {
+				state = "ACTIVE"
+			}
+			source := "config"
+			if window.AdHoc {
+				source = "pause"
+			}
+			fmt.Printf("%-8s %s to %s  %-6s %s\n", state, window.Start.Format("2006-01-02 15:04"), window.End.Format("2006-01-02 15:04"), source, window.Reason)
+			listed++
+		}
+		if listed == 0 {
+			fmt.Println("No current or upcoming blackouts")
+		}
+		return nil
+	case args[0] == "clear" && len(args) == 1:
+		if err := storageImpl.DeleteBlackouts(); err != nil {
+			return err
+		}
+		fmt.Println("Ad-hoc pauses cleared; configured blackouts still apply")
+		return nil
+	default:
+		duration, err := parsePauseDuration(args[0])
+		if err != nil {
+			return fmt.Errorf("%v\n%v", err, usage)
+		}
+		window, err := calendar.Pause(now, duration, strings.Join(args[1:], " "))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Actions paused until %s; schedulers queue them until then\n", window.End.Format("2006-01-02 15:04"))
+		return nil
+	}
+}
+
+// parsePauseDuration parses a Go duration such as 48h, or a number of days such as 3d
+func parsePauseDuration(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid pause duration %q", value)
+	}
+	return duration, nil
+}
+
+// runHealthCommand prints the account's health score or records an event seen by hand
+func runHealthCommand(configPath string, args []string) error {
+	usage := fmt.Errorf("usage: health | health record <challenge|warning|logout|restricted> [detail] | health enable [note]")
+	if len(args) > 0 && args[0] != "enable" && (args[0] != "record" || len(args) < 2) {
+		return usage
+	}
+
+	cfg, err := config.NewManager().LoadWithEnvOverrides(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	storageImpl, err := storage.NewStorageManager(storage.StorageConfig{
+		Type:     cfg.Storage.Type,
+		Path:     cfg.Storage.Path,
+		Database: cfg.Storage.Database,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer storageImpl.Close()
+
+	account := cfg.Health.Account
+	switch {
+	case len(args) > 0 && args[0] == "enable":
+		halt, err := health.Status(storageImpl, account)
+		if err != nil {
+			return err
+		}
+		if !halt.Halted {
+			return fmt.Errorf("account %s is not halted", account)
+		}
+		if err := health.Enable(storageImpl, account, strings.Join(args[1:], " "), time.Now()); err != nil {
+			return err
+		}
+		fmt.Printf("Re-enabled %s, halted since %s: %s\n", account, halt.At.Format(time.RFC3339), halt.Reason)
+	case len(args) > 0:
+		switch args[1] {
+		case health.EventChallenge, health.EventWarning, health.EventLogout, health.EventRestricted:
+		default:
+			return usage
+		}
+		if err := health.Record(storageImpl, account, args[1], strings.Join(args[2:], " "), time.Now()); err != nil {
+			return err
+		}
+		fmt.Printf("Recorded %s for %s\n", args[1], account)
+		reason, err := health.Trip(storageImpl, account, guardrails(cfg.Health.KillSwitch), time.Now())
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			fmt.Printf("Kill-switch tripped: %s\n", reason)
+		}
+	}
+
+	report, err := health.Assess(storageImpl, account, healthPolicy(cfg.Health), time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Account %s: score %d, %s (limits x%.2f)\n", report.Account, report.Score, report.Level, report.Level.Factor())
+	fmt.Printf("Last %s: %d/%d invites accepted, %d challenges, %d warnings, %d logouts\n",
+		cfg.Health.Window, report.Signals.Accepted, report.Signals.Invites,
+		report.Signals.Challenges, report.Signals.Warnings, report.Signals.Logouts)
+	for _, penalty := range report.Penalties {
+		fmt.Printf("  -%d %s: %s\n", penalty.Points, penalty.Signal, penalty.Detail)
+	}
+	halt, err := health.Status(storageImpl, account)
+	if err != nil {
+		return err
+	}
+	if halt.Halted {
+		fmt.Printf("HALTED since %s: %s (re-enable with: health enable)\n", halt.At.Format(time.RFC3339), halt.Reason)
+	}
+	return nil
+}
+
+// runDaemonCommand reports on or stops the daemon over its socket
+func runDaemonCommand(configPath string, args []string, timeout time.Duration) error {
+	usage := fmt.Errorf("usage: daemon status | daemon stop")
+	if len(args) != 1 {
+		return usage
+	}
+
+	cfg, err := config.NewManager().LoadWithEnvOverrides(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	client := daemon.NewClient(cfg.Daemon.Socket)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	switch args[0] {
+	case "status":
+		info, err := client.Info(ctx)
+		if stderrors.Is(err, daemon.ErrNotRunning) {
+			if pid, pidErr := daemon.ReadPIDFile(cfg.Daemon.PIDFile); pidErr == nil {
+				return fmt.Errorf("daemon is not running (stale PID file %s names pid %d)", cfg.Daemon.PIDFile, pid)
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Daemon running with pid %d\n", info.PID)
+		fmt.Printf("Run: %s\n", info.RunID)
+		fmt.Printf("Started: %s (up %s)\n", info.StartedAt.Format(time.RFC3339), time.Since(info.StartedAt).Round(time.Second))
+		fmt.Printf("State: %s\n", info.Control.State)
+		if info.Control.Reason != "" {
+			fmt.Printf("Reason: %s\n", info.Control.Reason)
+		}
+		if memory := info.Memory; memory != nil {
+			fmt.Printf("Memory: %s heap, %s JavaScript heap across %d page(s), %d goroutines, browser up %s (sampled %s ago)\n",
+				formatBytes(memory.HeapBytes), formatBytes(memory.JSHeapBytes), memory.Pages, memory.Goroutines,
+				memory.BrowserAge.Round(time.Second), time.Since(memory.SampledAt).Round(time.Second))
+		}
+		if status := info.Latency; status != nil {
+			for _, stats := range status.Kinds {
+				fmt.Printf("Latency, %s: %s\n", stats.Kind, formatLatency(stats))
+			}
+			if status.Stretch > 1 {
+				fmt.Printf("Delays stretched %.1fx while LinkedIn answers slowly\n", status.Stretch)
+			}
+		}
+		if failure := info.Failure; failure != nil {
+			code := failure.Code
+			if code == "" {
+				code = "no code"
+			}
+			fmt.Printf("Last failure: %s at %s (%s): %s\n", failure.Search, failure.At.Format(time.RFC3339), code, failure.Error)
+		}
+		return nil
+	case "stop":
+		info, err := client.Stop(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Stopping daemon with pid %d...\n", info.PID)
+		if err := client.WaitStopped(ctx); err != nil {
+			return err
+		}
+		fmt.Println("Daemon stopped")
+		return nil
+	default:
+		return usage
+	}
+}
+
+// formatBytes formats a byte count in MiB
+func formatBytes(bytes uint64) string {
+	return fmt.Sprintf("%.1f MiB", float64(bytes)/(1<<20))
+}
+
+// cohortReportOptions are the report cohorts command's flags
+type cohortReportOptions struct {
+	weeks    int    // Weeks each cohort is followed for
+	csvPath  string // Where the CSV goes; "-" for stdout
+	htmlPath string // Where the HTML report goes
+}
+
+// reportCohorts groups the sent invites into weekly cohorts and prints their acceptance and reply
+// rates over the following weeks, or writes them as CSV and an HTML report with charts
+func reportCohorts(configPath string, options cohortReportOptions) error {
+	cfg, err := config.NewManager().LoadWithEnvOverrides(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	storageImpl, err := storage.NewStorageManager(storage.StorageConfig{
+		Type:     cfg.Storage.Type,
+		Path:     cfg.Storage.Path,
+		Database: cfg.Storage.Database,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer storageImpl.Close()
+
+	requests, err := storageImpl.GetSentRequests()
+	if err != nil {
+		return fmt.Errorf("failed to load connection requests: %w", err)
+	}
+	imported, err := storageImpl.GetConnections()
+	if err != nil {
+		return fmt.Errorf("failed to load imported connections: %w", err)
+	}
+	replies, err := storageImpl.GetReplies()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	cohorts := funnel.Build(requests, imported, replies, now, options.weeks)
+	if len(cohorts) == 0 {
+		fmt.Println("No invites sent yet")
+		return nil
+	}
+
+	if options.csvPath == "-" {
+		return funnel.WriteCSV(os.Stdout, cohorts)
+	}
+	written := false
+	for _, output := range []struct {
+		path  string
+		write func(*os.File) error
+	}{
+		{options.csvPath, func(file *os.File) error { return funnel.WriteCSV(file, cohorts) }},
+		{options.htmlPath, func(file *os.File) error { return funnel.WriteHTML(file, cohorts, now) }},
+	} {
+		if output.path == "" {
+			continue
+		}
+		file, err := os.Create(output.path)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", output.path, err)
+		}
+		if err := output.write(file); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to write %s: %w", output.path, err)
+		}
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("failed to write %s: %w", output.path, err)
+		}
+		fmt.Printf("Wrote %d cohorts to %s\n", len(cohorts), output.path)
+		written = true
+	}
+	if written {
+		return nil
+	}
+
+	fmt.Println("Accepted / replied by the end of each week after sending")
+	for _, cohort := range cohorts {
+		fmt.Printf("%s  %4d invites", cohort.Week.Format("2006-01-02"), cohort.Invites)
+		for week := range cohort.Accepted {
+			fmt.Printf("  W+%d %3.0f%%/%3.0f%%", week, cohort.AcceptanceRate(week)*100, cohort.ReplyRate(week)*100)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// audienceReportOptions are the report audience command's flags
+type audienceReportOptions struct {
+	weeks    int    // Weeks the report covers
+	csvPath  string // Where the CSV goes; "-" for stdout
+	htmlPath string // Where the HTML report goes
+}
+
+// reportAudience prints the account's audience by week beside the outreach sent, and how the two
+// correlate, or writes them as CSV and an HTML report with charts
+func reportAudience(configPath string, options audienceReportOptions) error {
+	cfg, err := config.NewManager().LoadWithEnvOverrides(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	storageImpl, err := storage.NewStorageManager(storage.StorageConfig{
+		Type:     cfg.Storage.Type,
+		Path:     cfg.Storage.Path,
+		Database: cfg.Storage.Database,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer storageImpl.Close()
+
+	snapshots, err := storageImpl.GetAudienceSnapshots()
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		fmt.Println("No audience recorded yet; run \"audience track\"")
+		return nil
+	}
+	requests, err := storageImpl.GetSentRequests()
+	if err != nil {
+		return fmt.Errorf("failed to load connection requests: %w", err)
+	}
+	messages, err := storageImpl.GetMessageHistory()
+	if err != nil {
+		return fmt.Errorf("failed to load message history: %w", err)
+	}
+	now := time.Now()
+	growth := funnel.BuildGrowth(snapshots, requests, messages, now, options.weeks)
+
+	if options.csvPath == "-" {
+		return funnel.WriteGrowthCSV(os.Stdout, growth)
+	}
+	written := false
+	for _, output := range []struct {
+		path  string
+		write func(*os.File) error
+	}{
+		{options.csvPath, func(file *os.File) error { return funnel.WriteGrowthCSV(file, growth) }},
+		{options.htmlPath, func(file *os.File) error { return funnel.WriteGrowthHTML(file, growth, now) }},
+	} {
+		if output.path == "" {
+			continue
+		}
+		file, err := os.Create(output.path)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", output.path, err)
+		}
+		if err := output.write(file); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to write %s: %w", output.path, err)
+		}
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("failed to write %s: %w", output.path, err)
+		}
+		fmt.Printf("Wrote %d weeks to %s\n", len(growth), output.path)
+		written = true
+	}
+	if written {
+		return nil
+	}
+
+	fmt.Printf("%-10s  %9s  %6s  %11s  %11s  %7s  %8s\n", "WEEK", "FOLLOWERS", "GAIN", "IMPRESSIONS", "SUBSCRIBERS", "INVITES", "MESSAGES")
+	for _, week := range growth {
+		followers, gain, impressions, subscribers := "-", "-", "-", "-"
+		if week.Tracked {
+			followers, impressions, subscribers = strconv.Itoa(week.Followers), strconv.Itoa(week.Impressions), strconv.Itoa(week.Subscribers)
+		}
+		if week.GainKnown {
+			gain = fmt.Sprintf("%+d", week.FollowerGain)
+		}
+		fmt.Printf("%-10s  %9s  %6s  %11s  %11s  %7d  %8d\n", week.Week.Format("2006-01-02"), followers, gain, impressions, subscribers, week.Invites, week.Messages)
+	}
+	fmt.Println()
+	fmt.Println(funnel.DescribeCorrelation(growth))
+	return nil
+}
+
+// templateReportOptions are the report templates command's flags
+type templateReportOptions struct {
+	decay  funnel.DecayOptions
+	asJSON bool // Print the comparisons as JSON
+}
+
+// reportTemplates compares each template's recent acceptance and reply rates with its baseline,
+// flagging the templates whose rates dropped significantly
+func reportTemplates(configPath string, options templateReportOptions) error {
+	cfg, err := config.NewManager().LoadWithEnvOverrides(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	storageImpl, err := storage.NewStorageManager(storage.StorageConfig{
+		Type:     cfg.Storage.Type,
+		Path:     cfg.Storage.Path,
+		Database: cfg.Storage.Database,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer storageImpl.Close()
+
+	decays, err := templateDecay(storageImpl, time.Now(), options.decay)
+	if err != nil {
+		return err
+	}
+	if options.asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(decays)
+	}
+	if len(decays) == 0 {
+		fmt.Println("No settled invites or messages sent from a template yet")
+		return nil
+	}
+
+	fmt.Printf("%-48s %-10s %16s %16s\n", "TEMPLATE", "METRIC", "BASELINE", "RECENT")
+	for _, decay := range decays {
+		fmt.Printf("%-48s %-10s %7.1f%% of %4d %7.1f%% of %4d\n", decay.Template, decay.Metric,
+			decay.Baseline.Rate()*100, decay.Baseline.Sent, decay.Recent.Rate()*100, decay.Recent.Sent)
+	}
+	decayed := funnel.Decayed(decays, "")
+	if len(decayed) == 0 {
+		fmt.Println("\nNo template's rates dropped significantly below its baseline")
+		return nil
+	}
+	fmt.Println()
+	for _, decay := range decayed {
+		fmt.Printf("⚠️  %s\n", decay)
+	}
+	return nil
+}
+
+// templateDecay compares the rates of every template sent from in storage with its baseline at now
+func templateDecay(storageImpl *storage.StorageManager, now time.Time, options funnel.DecayOptions) ([]funnel.TemplateDecay, error) {
+	requests, err := storageImpl.GetSentRequests()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load connection requests: %w", err)
+	}
+	imported, err := storageImpl.GetConnections()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load imported connections: %w", err)
+	}
+	messages, err := storageImpl.GetMessageHistory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load message history: %w", err)
+	}
+	replies, err := storageImpl.GetReplies()
+	if err != nil {
+		return nil, err
+	}
+	return funnel.Decay(requests, imported, messages, replies, now, options), nil
+}
+
+// warnDecayedTemplates logs a warning for each of the campaign's templates whose acceptance or
+// reply rate dropped significantly below its baseline, suggesting a new variant
+func (app *Application) warnDecayedTemplates(ctx context.Context, campaignName string) {
+	decays, err := templateDecay(app.storage, time.Now(), funnel.DecayOptions{})
+	if err != nil {
+		app.logger.Warn(ctx, "Failed to check template performance", logger.F("error", err))
+		return
+	}
+	for _, decay := range funnel.Decayed(decays, campaignName+"/") {
+		app.logger.Warn(ctx, "Template performance decayed, consider rotating in a new variant",
+			logger.F("template", decay.Template), logger.F("metric", decay.Metric),
+			logger.F("baseline_rate", decay.Baseline.Rate()), logger.F("recent_rate", decay.Recent.Rate()),
+			logger.F("recent_sends", decay.Recent.Sent))
+	}
+}
+
+// runRunsCommand lists past runs, shows everything one run stored or totals the ways runs failed
+func runRunsCommand(configPath string, args []string) error {
+	usage := fmt.Errorf("usage: runs list | runs show <run-id> | runs errors")
+	if len(args) == 0 || (args[0] != "list" && args[0] != "show" && args[0] != "errors") || (args[0] == "show" && len(args) != 2) {
+		return usage
+	}
+
+	cfg, err := config.NewManager().LoadWithEnvOverrides(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	storageImpl, err := storage.NewStorageManager(storage.StorageConfig{
+		Type:     cfg.Storage.Type,
+		Path:     cfg.Storage.Path,
+		Database: cfg.Storage.Database,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer storageImpl.Close()
+
+	if args[0] == "list" {
+		summaries, err := storageImpl.GetRunSummaries()
+		if err != nil {
+			return err
+		}
+		if len(summaries) == 0 {
+			fmt.Println("No runs recorded")
+		}
+		for _, summary := range summaries {
+			fmt.Printf("%-14s %-16s %-9s %s  attempted %d, sent %d, skipped %d, failed %d\n",
+				summary.RunID, summary.Mode, summary.Status, summary.StartedAt.Format(time.RFC3339),
+				summary.Attempted, summary.Sent, summary.Skipped, summary.Failed)
+		}
+		return nil
+	}
+
+	if args[0] == "errors" {
+		fingerprints, err := storageImpl.GetErrorFingerprints()
+		if err != nil {
+			return err
+		}
+		if len(fingerprints) == 0 {
+			fmt.Println("No errors recorded")
+		}
+		for _, total := range totalErrorFingerprints(fingerprints) {
+			fmt.Printf("%6dx in %d runs  %s\n         e.g. %s\n", total.Count, total.Runs,
+				errors.Fingerprint{Operation: total.Operation, Selector: total.Selector, Class: total.Class}, total.Example)
+		}
+		return nil
+	}
+
+	records, err := storageImpl.GetRunRecords(args[1])
+	if err != nil {
+		return err
+	}
+	if records.Summary == nil && len(records.Requests)+len(records.Messages)+len(records.Results)+
+		len(records.Events)+len(records.Deferred)+len(records.Skips) == 0 {
+		return fmt.Errorf("no records for run %s", args[1])
+	}
+
+	fmt.Printf("Run %s\n", records.RunID)
+	if summary := records.Summary; summary != nil {
+		fmt.Printf("  %s, %s, %s to %s\n", summary.Mode, summary.Status,
+			summary.StartedAt.Format(time.RFC3339), summary.FinishedAt.Format(time.RFC3339))
+		fmt.Printf("  attempted %d, sent %d, skipped %d, failed %d\n", summary.Attempted, summary.Sent, summary.Skipped, summary.Failed)
+	} else {
+		fmt.Println("  no summary; the run is still going or ended without finishing")
+	}
+	for _, request := range records.Requests {
+		fmt.Printf("  invite   %s %s %s\n", request.SentAt.Format(time.RFC3339), request.ProfileURL, request.Status)
+	}
+	for _, message := range records.Messages {
+		fmt.Printf("  message  %s %s\n", message.SentAt.Format(time.RFC3339), message.RecipientURL)
+	}
+	for _, result := range records.Results {
+		fmt.Printf("  found    %s %s\n", result.Timestamp.Format(time.RFC3339), result.URL)
+	}
+	for _, event := range records.Events {
+		fmt.Printf("  event    %s %s %s %s\n", event.At.Format(time.RFC3339), event.Account, event.Type, event.Detail)
+	}
+	for _, lead := range records.Deferred {
+		fmt.Printf("  deferred %s %s %s until %s\n", lead.DeferredAt.Format(time.RFC3339), lead.ProfileURL, lead.Reason, lead.RetryAfter.Format(time.RFC3339))
+	}
+	for _, skip := range records.Skips {
+		fmt.Printf("  skipped  %s %s %s\n", skip.SkippedAt.Format(time.RFC3339), skip.ProfileURL, skip.Reason)
+	}
+	for _, fingerprint := range records.Errors {
+		fmt.Printf("  error    %dx (%.0f%%) %s\n", fingerprint.Count, fingerprint.Rate*100,
+			errors.Fingerprint{Operation: fingerprint.Operation, Selector: fingerprint.Selector, Class: fingerprint.Class})
+	}
+	if shots, err := gallery.Shots(cfg.Screenshots.Dir, records.RunID); err == nil {
+		for _, shot := range shots {
+			fmt.Printf("  shot     %s %s %s %s\n", shot.TakenAt.Format(time.RFC3339), filepath.Join(cfg.Screenshots.Dir, records.RunID, shot.File), shot.Reason, shot.Label)
+		}
+	}
+	return nil
+}
+
+// errorTotal is an error fingerprint totaled across the runs it failed
+type errorTotal struct {
+	storage.ErrorFingerprint
+	Runs int
+}
+
+// totalErrorFingerprints totals each fingerprint across runs, the most frequent first
+func totalErrorFingerprints(fingerprints []storage.ErrorFingerprint) []errorTotal {
+	var totals []errorTotal
+	index := make(map[string]int)
+	for _, fingerprint := range fingerprints {
+		i, ok := index[fingerprint.Fingerprint]
+		if !ok {
+			i = len(totals)
+			index[fingerprint.Fingerprint] = i
+			totals = append(totals, errorTotal{ErrorFingerprint: fingerprint})
+			totals[i].Count = 0
+		}
+		totals[i].Count += fingerprint.Count
+		totals[i].Runs++
+	}
+	sort.SliceStable(totals, func(i, j int) bool { return totals[i].Count > totals[j].Count })
+	return totals
+}
+
+// startControl serves the pause/resume control channel for the rest of the run if an address is configured
+func (app *Application) startControl(ctx context.Context) {
+	address := app.config.Control.Address
+	if address == "" {
+		return
+	}
+
+	// Configured tokens have full control; issued ones are looked up in storage on each request
+	tokens := make([]storage.APIToken, len(app.config.Control.Tokens))
+	for i, token := range app.config.Control.Tokens {
+		tokens[i] = storage.APIToken{Name: token.Name, Hash: apitoken.Hash(token.Token), Scope: apitoken.ScopeControl}
+	}
+	account := app.config.Health.Account
+	mux := http.NewServeMux()
+	mux.Handle("/", control.Handler(app.controller))
+	probes.Mount(mux, probes.Handler(app.probeComponents(), probes.DefaultTimeout))
+	mux.Handle("/commands/", commands.Handler(app.storage, app.controller, commands.Options{
+		Account: account,
+		Auth:    apitoken.NewAuthenticator(tokens, app.storage, app.config.Control.TokenRatePerMinute),
+		Health: func() (int, string, error) {
+			report, err := health.Assess(app.storage, account, healthPolicy(app.config.Health), time.Now())
+			return report.Score, string(report.Level), err
+		},
+		Drafts:      app.storage,
+		Annotations: app.storage,
+		Screenshots: app.screenshotsDir(),
+		DeadLetters: app.storage,
+	}))
+
+	var tlsConfig *tls.Config
+	if settings := app.config.Control.TLS; settings.CertFile != "" {
+		var err error
+		tlsConfig, err = control.ServerTLS(settings.CertFile, settings.KeyFile, settings.ClientCAFile)
+		if err != nil {
+			app.logger.Warn(ctx, "Control channel not started", logger.F("error", err))
+			return
+		}
+	}
+
+	go func() {
+		if err := control.ServeHandler(ctx, address, mux, tlsConfig); err != nil {
+			app.logger.Warn(ctx, "Control channel stopped", logger.F("error", err))
+		}
+	}()
+	app.logger.Info(ctx, "Control channel listening",
+		logger.F("address", address),
+		logger.F("tls", tlsConfig != nil),
+		logger.F("client_certificates", app.config.Control.TLS.ClientCAFile != ""),
+		logger.F("command_tokens", len(tokens)))
+}
+
+// probeComponents are what /healthz and /readyz check. A dead browser or a stalled scheduler
+// fails liveness, since a restart fixes them; storage and the session only fail readiness.
+func (app *Application) probeComponents() []probes.Component {
+	components := []probes.Component{
+		{Name: "browser", Check: app.browserManager.Ping, Live: true},
+		{Name: "storage", Check: func(ctx context.Context) error { return app.storage.CheckReadWrite() }},
+	}
+	// A replay serves recorded pages and holds no session to check
+	if app.replayRouter == nil {
+		components = append(components, probes.Component{Name: "session", Check: app.browserManager.CheckSession})
+	}
+	if app.schedulerBeat != nil {
+		components = append(components, probes.Component{Name: "scheduler", Check: app.schedulerBeat.Check(app.config.Daemon.StallAfter), Live: true})
+	}
+	return components
+}
+
+// runControlCommand pauses, resumes or reports on a running instance
+func runControlCommand(configPath string, args []string) error {
+	usage := fmt.Errorf("usage: control pause [reason] | control resume | control status")
+	if len(args) == 0 {
+		return usage
+	}
+
+	cfg, err := config.NewManager().LoadWithEnvOverrides(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if cfg.Control.Address == "" {
+		return fmt.Errorf("control.address is not configured")
+	}
+	var tlsConfig *tls.Config
+	if settings := cfg.Control.TLS; settings.CertFile != "" || settings.CAFile != "" || settings.ClientCertFile != "" {
+		tlsConfig, err = control.ClientTLS(settings.CAFile, settings.ClientCertFile, settings.ClientKeyFile)
+		if err != nil {
+			return err
+		}
+	}
+	client := control.NewClient(cfg.Control.Address, tlsConfig)
+
+	ctx := context.Background()
+	var status control.Status
+	switch {
+	case args[0] == "pause":
+		status, err = client.Pause(ctx, strings.Join(args[1:], " "))
+	case args[0] == "resume" && len(args) == 1:
+		status, err = client.Resume(ctx)
+	case args[0] == "status" && len(args) == 1:
+		status, err = client.Status(ctx)
+	default:
+		return usage
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("State: %s\n", status.State)
+	if status.Reason != "" {
+		fmt.Printf("Reason: %s\n", status.Reason)
+	}
+	if status.PausedAt != nil {
+		fmt.Printf("Paused at: %s\n", status.PausedAt.Format(time.RFC3339))
+	}
+	if status.Position != "" {
+		fmt.Printf("Position: %s\n", status.Position)
+	}
+	return nil
+}
+
+// runTokensCommand issues, lists and revokes the API tokens the commands endpoint accepts
+func runTokensCommand(configPath string, args []string) error {
+	usage := fmt.Errorf("usage: tokens issue <name> <read|control> <requests per minute> | tokens list | tokens revoke <name>")
+	if len(args) == 0 {
+		return usage
+	}
+
+	cfg, err := config.NewManager().LoadWithEnvOverrides(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	storageImpl, err := storage.NewStorageManager(storage.StorageConfig{
+		Type:     cfg.Storage.Type,
+		Path:     cfg.Storage.Path,
+		Database: cfg.Storage.Database,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer storageImpl.Close()
+
+	switch {
+	case args[0] == "issue" && len(args) == 4:
+		rate, err := strconv.Atoi(args[3])
+		if err != nil || rate < 0 {
+			return fmt.Errorf("requests per minute must be a non-negative number, got: %s", args[3])
+		}
+		secret, err := apitoken.Issue(storageImpl, args[1], args[2], rate, time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Issued %s token %s. It is shown only once:\n%s\n", args[2], args[1], secret)
+		return nil
+	case args[0] == "revoke" && len(args) == 2:
+		if err := apitoken.Revoke(storageImpl, args[1], time.Now()); err != nil {
+			return err
+		}
+		fmt.Printf("Revoked token %s\n", args[1])
+		return nil
+	case args[0] == "list" && len(args) == 1:
+		tokens, err := storageImpl.GetAPITokens()
+		if err != nil {
+			return err
+		}
+		for _, token := range cfg.Control.Tokens {
+			fmt.Printf("%-20s %-8s configured\n", token.Name, apitoken.ScopeControl)
+		}
+		for _, token := range tokens {
+			rate := "default rate"
+			if token.RatePerMinute > 0 {
+				rate = fmt.Sprintf("%d/min", token.RatePerMinute)
+			}
+			state := "issued " + token.CreatedAt.Format(time.RFC3339)
+			if !token.RevokedAt.IsZero() {
+				state = "revoked " + token.RevokedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%-20s %-8s %-12s %s\n", token.Name, token.Scope, rate, state)
+		}
+		return nil
+	default:
+		return usage
+	}
+}
+
+// runDraftsCommand lists the drafts campaigns hold for approval and approves or rejects them
+func runDraftsCommand(configPath string, args []string) error {
+	usage := fmt.Errorf("usage: drafts list | drafts approve <id> [edited text] | drafts reject <id> [reason]")
+	if len(args) == 0 {
+		return usage
+	}
+
+	cfg, err := config.NewManager().LoadWithEnvOverrides(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	storageImpl, err := storage.NewStorageManager(storage.StorageConfig{
+		Type:     cfg.Storage.Type,
+		Path:     cfg.Storage.Path,
+		Database: cfg.Storage.Database,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer storageImpl.Close()
+
+	reviewer := cliUser()
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		pending, err := approval.Pending(storageImpl)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			fmt.Println("No drafts waiting for approval")
+			return nil
+		}
+		annotated, err := annotations.Load(storageImpl)
+		if err != nil {
+			return err
+		}
+		for _, draft := range pending {
+			fmt.Printf("%s  %s/%s  %s  %s %s\n", draft.ID, draft.Campaign, draft.StepID, draft.Kind, draft.LeadName, draft.ProfileURL)
+			if draft.Subject != "" {
+				fmt.Printf("    Subject: %s\n", draft.Subject)
+			}
+			text := draft.Text
+			if text == "" {
+				text = "(no note)"
+			}
+			fmt.Printf("    %s\n", strings.ReplaceAll(text, "\n", "\n    "))
+			printAnnotation(annotated[identity.ProfileKey(draft.ProfileURL)], "    ")
+		}
+		return nil
+	case args[0] == "approve" && (len(args) == 2 || len(args) == 3):
+		text := ""
+		if len(args) == 3 {
+			text = args[2]
+		}
+		draft, err := approval.Approve(storageImpl, args[1], reviewer, text, time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Approved %s for %s; the next %s run sends it\n", draft.ID, draft.ProfileURL, draft.Campaign)
+		return nil
+	case args[0] == "reject" && (len(args) == 2 || len(args) == 3):
+		reason := ""
+		if len(args) == 3 {
+			reason = args[2]
+		}
+		draft, err := approval.Reject(storageImpl, args[1], reviewer, reason, time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Rejected %s for %s\n", draft.ID, draft.ProfileURL)
+		return nil
+	default:
+		return usage
+	}
+}
+
+// runDeadLettersCommand reviews the campaign leads their queue gave up on: list shows those filter
+// selects, set corrects one's attributes, and requeue and discard act on the IDs given or, without
+// any, on those filter selects
+func runDeadLettersCommand(configPath string, args []string, filter deadletters.Filter) error {
+	usage := fmt.Errorf("usage: dead-letters list | dead-letters set <id> key=value... | dead-letters requeue|discard [id...]")
+	if len(args) == 0 {
+		return usage
+	}
+
+	cfg, err := config.NewManager().LoadWithEnvOverrides(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	storageImpl, err := storage.NewStorageManager(storage.StorageConfig{
+		Type:     cfg.Storage.Type,
+		Path:     cfg.Storage.Path,
+		Database: cfg.Storage.Database,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer storageImpl.Close()
+
+	ids := make([]int64, 0, len(args))
+	parseIDs := func(values []string) error {
+		for _, value := range values {
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid dead letter ID %q", value)
+			}
+			ids = append(ids, id)
+		}
+		return nil
+	}
+
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		letters, err := deadletters.List(storageImpl, filter, cfg.Screenshots.Dir)
+		if err != nil {
+			return err
+		}
+		if len(letters) == 0 {
+			fmt.Println("No dead letters")
+			return nil
+		}
+		fingerprints := make(map[string]int)
+		for _, letter := range letters {
+			fingerprints[letter.Fingerprint]++
+			fmt.Printf("%d  %s  %s %s\n", letter.ID, letter.Campaign, letter.Lead.Name, letter.Lead.ProfileURL)
+			fmt.Printf("    Failed %d times, last on %s: %s\n", letter.Attempts, letter.FailedAt.Local().Format("2006-01-02 15:04"), letter.Error)
+			fmt.Printf("    Fingerprint: %s\n", letter.Fingerprint)
+			if len(letter.Lead.Attributes) > 0 {
+				var attributes []string
+				for _, key := range slices.Sorted(maps.Keys(letter.Lead.Attributes)) {
+					attributes = append(attributes, key+"="+letter.Lead.Attributes[key])
+				}
+				fmt.Printf("    Attributes: %s\n", strings.Join(attributes, ", "))
+			}
+			for _, shot := range letter.Screenshots {
+				fmt.Printf("    Screenshot: %s\n", filepath.Join(cfg.Screenshots.Dir, letter.RunID, shot.File))
+			}
+		}
+		fmt.Println()
+		fmt.Println("By fingerprint:")
+		for _, fingerprint := range slices.Sorted(maps.Keys(fingerprints)) {
+			fmt.Printf("    %s  %d\n", fingerprint, fingerprints[fingerprint])
+		}
+		return nil
+	case args[0] == "set" && len(args) >= 3:
+		if err := parseIDs(args[1:2]); err != nil {
+			return err
+		}
+		attributes, err := annotations.ParseAttributes(args[2:])
+		if err != nil {
+			return err
+		}
+		letter, err := deadletters.SetAttributes(storageImpl, ids[0], attributes)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Updated the attributes of dead letter %d for %s\n", letter.ID, letter.Lead.ProfileURL)
+		return nil
+	case args[0] == "requeue" || args[0] == "discard":
+		if err := parseIDs(args[1:]); err != nil {
+			return err
+		}
+		filter.IDs = ids
+		if args[0] == "requeue" {
+			requeued, err := deadletters.Requeue(storageImpl, filter, time.Now())
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Requeued %d dead letters; each campaign's next run takes them first\n", requeued)
+			return nil
+		}
+		discarded, err := deadletters.Discard(storageImpl, filter)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Discarded %d dead letters\n", discarded)
+		return nil
+	default:
+		return usage
+	}
+}
+
+// runSuppressionCommand manages the suppression list every account of the deployment honors
+func runSuppressionCommand(configPath string, args []string) error {
+	usage := fmt.Errorf("usage: suppression list | suppression add <profile-url> [reason] | suppression remove <profile-url>")
+	if len(args) == 0 {
+		return usage
+	}
+
+	cfg, err := config.NewManager().LoadWithEnvOverrides(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	list := newSuppressionList(cfg)
+	if list == nil {
+		return fmt.Errorf("no suppression list is configured: set suppression.file or suppression.path")
+	}
+
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		entries, err := list.Entries()
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			fmt.Println("The suppression list is empty")
+			return nil
+		}
+		for _, entry := range entries {
+			line := entry.ProfileURL
+			if entry.Reason != "" {
+				line += "  " + entry.Reason
+			}
+			if entry.Account != "" {
+				line += fmt.Sprintf("  (by %s on %s)", entry.Account, entry.AddedAt.Format("2006-01-02"))
+			}
+			fmt.Println(line)
+		}
+		return nil
+	case args[0] == "add" && (len(args) == 2 || len(args) == 3):
+		entry := storage.Suppression{ProfileURL: args[1], Account: cfg.Health.Account, AddedAt: time.Now()}
+		if len(args) == 3 {
+			entry.Reason = args[2]
+		}
+		if err := list.Add(entry); err != nil {
+			return err
+		}
+		fmt.Printf("Suppressed %s for every account\n", args[1])
+		return nil
+	case args[0] == "remove" && len(args) == 2:
+		removed, err := list.Remove(args[1])
+		if err != nil {
+			return err
+		}
+		if removed == 0 {
+			return fmt.Errorf("%s is not on the suppression list", args[1])
+		}
+		fmt.Printf("Removed %s from the suppression list\n", args[1])
+		return nil
+	default:
+		return usage
+	}
+}
+
+// cliUser names the user running a command, as recorded as a reviewer or author
+func cliUser() string {
+	if user := os.Getenv("USER"); user != "" {
+		return "cli:" + user
+	}
+	return "cli"
+}
+
+// printAnnotation prints a lead's notes and attributes, each line indented
+func printAnnotation(annotation annotations.Annotation, indent string) {
+	for _, note := range annotation.Notes {
+		fmt.Printf("%sNote (%s, %s): %s\n", indent, note.Author, note.CreatedAt.Format("2006-01-02"), strings.ReplaceAll(note.Text, "\n", "\n"+indent+"  "))
+	}
+	keys := slices.Sorted(maps.Keys(annotation.Attributes))
+	for _, key := range keys {
+		fmt.Printf("%s%s: %s\n", indent, key, annotation.Attributes[key])
+	}
+}
+
+// runLeadsCommand attaches notes and custom attributes to leads, snoozes them and shows them
+func runLeadsCommand(configPath string, args []string) error {
+	usage := fmt.Errorf("usage: leads show [profile-url] | leads note <profile-url> <text> | leads set <profile-url> key=value... | leads snooze <profile-url> <until YYYY-MM-DD> | leads unsnooze <profile-url>")
+	if len(args) == 0 {
+		return usage
+	}
+
+	cfg, err := config.NewManager().LoadWithEnvOverrides(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	storageImpl, err := storage.NewStorageManager(storage.StorageConfig{
+		Type:     cfg.Storage.Type,
+		Path:     cfg.Storage.Path,
+		Database: cfg.Storage.Database,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer storageImpl.Close()
+
+	switch {
+	case args[0] == "show" && len(args) <= 2:
+		annotated, err := annotations.Load(storageImpl)
+		if err != nil {
+			return err
+		}
+		snoozes, err := annotations.Snoozes(storageImpl, time.Now())
+		if err != nil {
+			return err
+		}
+		for key, snooze := range snoozes {
+			if _, found := annotated[key]; !found {
+				annotated[key] = annotations.Annotation{ProfileURL: snooze.ProfileURL}
+			}
+		}
+		if len(args) == 2 {
+			annotation, found := annotated[identity.ProfileKey(args[1])]
+			if !found {
+				fmt.Printf("No notes, attributes or snooze on %s\n", args[1])
+				return nil
+			}
+			annotated = map[string]annotations.Annotation{identity.ProfileKey(args[1]): annotation}
+		}
+		if len(annotated) == 0 {
+			fmt.Println("No lead has notes, attributes or a snooze")
+			return nil
+		}
+		for _, key := range slices.Sorted(maps.Keys(annotated)) {
+			fmt.Println(annotated[key].ProfileURL)
+			if snooze, found := snoozes[key]; found {
+				fmt.Printf("    Snoozed until %s\n", snooze.RetryAfter.Format("2006-01-02"))
+			}
+			printAnnotation(annotated[key], "    ")
+		}
+		return nil
+	case args[0] == "note" && len(args) == 3:
+		if err := annotations.AddNote(storageImpl, args[1], args[2], cliUser(), time.Now()); err != nil {
+			return err
+		}
+		fmt.Printf("Added a note to %s\n", args[1])
+		return nil
+	case args[0] == "set" && len(args) >= 3:
+		attributes, err := annotations.ParseAttributes(args[2:])
+		if err != nil {
+			return err
+		}
+		if err := annotations.SetAttributes(storageImpl, args[1], attributes, cliUser(), time.Now()); err != nil {
+			return err
+		}
+		fmt.Printf("Updated the attributes of %s\n", args[1])
+		return nil
+	case args[0] == "snooze" && len(args) == 3:
+		until, err := time.ParseInLocation("2006-01-02", args[2], time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --until date %q, expected YYYY-MM-DD", args[2])
+		}
+		if err := annotations.Snooze(storageImpl, args[1], until, time.Now()); err != nil {
+			return err
+		}
+		fmt.Printf("Snoozed %s until %s\n", args[1], args[2])
+		return nil
+	case args[0] == "unsnooze" && len(args) == 2:
+		lifted, err := annotations.Unsnooze(storageImpl, args[1])
+		if err != nil {
+			return err
+		}
+		if !lifted {
+			fmt.Printf("%s is not snoozed\n", args[1])
+			return nil
+		}
+		fmt.Printf("Lifted the snooze on %s\n", args[1])
+		return nil
+	default:
+		return usage
+	}
+}
+
+// runPhotosCommand handles the photos subcommands: prune applies search.photos.retention and
+// purge deletes every downloaded photo, e.g. after turning photos off
+func runPhotosCommand(configPath string, args []string) error {
+	if len(args) != 1 || (args[0] != "prune" && args[0] != "purge") {
+		return fmt.Errorf("usage: photos prune | photos purge")
+	}
+
+	cfg, err := config.NewManager().LoadWithEnvOverrides(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	storageImpl, err := storage.NewStorageManager(storage.StorageConfig{
+		Type:     cfg.Storage.Type,
+		Path:     cfg.Storage.Path,
+		Database: cfg.Storage.Database,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer storageImpl.Close()
+
+	if args[0] == "purge" {
+		removed, err := photos.Purge(storageImpl, cfg.Search.Photos.Dir)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Deleted all %d photos from %s\n", removed, cfg.Search.Photos.Dir)
+		return nil
+	}
+	removed, err := photos.Prune(storageImpl, cfg.Search.Photos.Dir, cfg.Search.Photos.Retention, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Deleted %d photos past the %.0f-day retention or no longer used\n", removed, cfg.Search.Photos.Retention.Hours()/24)
+	return nil
+}
+
+// runEmailsCommand writes guessed work email addresses of stored leads to a CSV file
+func runEmailsCommand(configPath string, args []string) error {
+	if len(args) != 2 || args[0] != "guess" {
+		return fmt.Errorf("usage: emails guess <file.csv>")
+	}
+	path := args[1]
+
+	cfg, err := config.NewManager().LoadWithEnvOverrides(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if !cfg.EmailGuess.Enabled {
+		return fmt.Errorf("email guessing is off; set email_guess.enabled: true or EMAIL_GUESS=true to use it")
+	}
+
+	storageImpl, err := storage.NewStorageManager(storage.StorageConfig{
+		Type:     cfg.Storage.Type,
+		Path:     cfg.Storage.Path,
+		Database: cfg.Storage.Database,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer storageImpl.Close()
+
+	results, err := storageImpl.GetSearchResults()
+	if err != nil {
+		return fmt.Errorf("failed to load search results: %w", err)
+	}
+	imported, err := storageImpl.GetConnections()
+	if err != nil {
+		return fmt.Errorf("failed to load imported connections: %w", err)
+	}
+
+	// Addresses from the connections export are kept, and show each domain's pattern
+	var known []emails.Known
+	knownEmails := make(map[string]string)
+	for _, connection := range imported {
+		if connection.Email != "" {
+			known = append(known, emails.Known{First: connection.FirstName, Last: connection.LastName, Email: connection.Email})
+			knownEmails[identity.ProfileKey(connection.ProfileURL)] = connection.Email
+		}
+	}
+
+	var leads []emails.Lead
+	seen := make(map[string]bool)
+	for _, result := range results {
+		key := identity.ProfileKey(result.URL)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		name := identity.ParseName(result.Name)
+		lead := emails.Lead{ProfileURL: result.URL, FirstName: name.First, LastName: name.Last, Company: result.Company, KnownEmail: knownEmails[key]}
+		company, found, err := companies.ForLead(storageImpl, result.Company)
+		if err != nil {
+			return err
+		}
+		if found {
+			lead.Domain = emails.Domain(company.Website)
+		}
+		leads = append(leads, lead)
+	}
+
+	rows, report, err := emails.GuessLeads(context.Background(), emails.NewVerifier(nil), leads, known, cfg.EmailGuess.PerLead)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	err = emails.WriteCSV(file, rows)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	fmt.Printf("Wrote %d addresses to %s: %d leads guessed, %d known\n", len(rows), path, report.Guessed, report.Known)
+	if skipped := report.NoDomain + report.NoMail + report.Unusable; skipped > 0 {
+		fmt.Printf("Skipped %d leads: %d without a company website (run \"companies enrich\"), %d whose domain accepts no mail, %d with names that cannot be spelled in an address\n",
+			skipped, report.NoDomain, report.NoMail, report.Unusable)
+	}
+	return nil
+}
+
+// runViewersCommand lists the profile visits profile-viewers mode recorded, most recent first
+func runViewersCommand(configPath string, args []string) error {
+	if len(args) != 1 || args[0] != "list" {
+		return fmt.Errorf("usage: viewers list")
+	}
+
+	cfg, err := config.NewManager().LoadWithEnvOverrides(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	storageImpl, err := storage.NewStorageManager(storage.StorageConfig{
+		Type:     cfg.Storage.Type,
+		Path:     cfg.Storage.Path,
+		Database: cfg.Storage.Database,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer storageImpl.Close()
+
+	views, err := storageImpl.GetProfileViews()
+	if err != nil {
+		return err
+	}
+	if len(views) == 0 {
+		fmt.Println("No profile visits recorded yet; run \"viewers check\"")
+		return nil
+	}
+	for _, view := range views {
+		fmt.Printf("%s  %s  viewed %s  %s\n", view.Name, view.ProfileURL, view.ViewedAt.Local().Format("2006-01-02 15:04"), view.Outcome)
+		if view.Headline != "" {
+			fmt.Printf("    %s\n", view.Headline)
+		}
+	}
+	return nil
+}
+
+// runCompaniesCommand lists the companies looked up by enrich-companies mode
+func runCompaniesCommand(configPath string, args []string) error {
+	if len(args) != 1 || args[0] != "list" {
+		return fmt.Errorf("usage: companies list")
+	}
+
+	cfg, err := config.NewManager().LoadWithEnvOverrides(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	storageImpl, err := storage.NewStorageManager(storage.StorageConfig{
+		Type:     cfg.Storage.Type,
+		Path:     cfg.Storage.Path,
+		Database: cfg.Storage.Database,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer storageImpl.Close()
+
+	cached, err := storageImpl.GetCompanies()
+	if err != nil {
+		return err
+	}
+	if len(cached) == 0 {
+		fmt.Println("No companies looked up yet; run \"companies enrich\"")
+		return nil
+	}
+	results, err := storageImpl.GetSearchResults()
+	if err != nil {
+		return fmt.Errorf("failed to load search results: %w", err)
+	}
+	leads := make(map[string]int)
+	for _, result := range results {
+		leads[identity.NormalizeCompany(result.Company)]++
+	}
+
+	for _, company := range cached {
+		checked := company.FetchedAt.Format("2006-01-02")
+		if company.URL == "" {
+			fmt.Printf("%s  not found on LinkedIn (checked %s)\n", company.Key, checked)
+			continue
+		}
+		fmt.Printf("%s  %d leads  checked %s\n", company.Name, leads[company.Key], checked)
+		fmt.Printf("    %s\n", company.URL)
+		for _, field := range []struct{ label, value string }{
+			{"Size", company.Size},
+			{"Industry", company.Industry},
+			{"Headquarters", company.Headquarters},
+			{"Website", company.Website},
+		} {
+			if field.value != "" {
+				fmt.Printf("    %s: %s\n", field.label, field.value)
+			}
+		}
+		if company.Followers > 0 {
+			fmt.Printf("    Followers: %d\n", company.Followers)
+		}
+		if !company.ScannedAt.IsZero() {
+			found := strings.Join(company.Keywords, ", ")
+			if found == "" {
+				found = "none"
+			}
+			fmt.Printf("    Website keywords: %s (scanned %s)\n", found, company.ScannedAt.Format("2006-01-02"))
+		}
+	}
+	return nil
+}